@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/tokamak-network/trh-sdk/commands"
 	"github.com/tokamak-network/trh-sdk/flags"
@@ -24,7 +25,7 @@ func Run() {
 		Commands: []*cli.Command{
 			{
 				Name:  "backup-manager",
-				Usage: "Manage L2 backups and restores (EFS)",
+				Usage: "Manage L2 backups and restores (EFS, or docker volumes for local deployments)",
 				Description: `Examples:
     trh-sdk backup-manager --status
     trh-sdk backup-manager --snapshot
@@ -32,9 +33,106 @@ func Run() {
     trh-sdk backup-manager --restore
     trh-sdk backup-manager --config --daily 03:00 --keep 35
     trh-sdk backup-manager --attach --efs-id fs-1234567890abcdef0 --pvc op-geth,op-node --sts op-geth,op-node
+    trh-sdk backup-manager --config --copy-region us-west-2 --copy-keep 30
+    trh-sdk backup-manager --config --gfs --rule monthly:keep=730,cold=60,tag.Compliance=audit
+
+Local deployments archive their docker volumes into backups/<id> with checksums, the chain ID and the
+genesis hash. The containers are stopped while the volumes are copied.
+    trh-sdk backup-manager --snapshot
+    trh-sdk backup-manager --list
+    trh-sdk backup-manager --restore --backup-id 20260501-100000
+
+Prove that a recovery point boots by restoring it into a scratch namespace:
+    trh-sdk backup-manager verify --recovery-point arn:aws:backup:...
+
+Fail over to the secondary region the recovery points are copied to:
+    trh-sdk backup-manager failover --region us-west-2
     `,
 				Flags:  flags.BackupManagerFlags,
 				Action: commands.ActionBackupManager(),
+				Commands: []*cli.Command{
+					{
+						Name:  "verify",
+						Usage: "Restore a recovery point into a scratch namespace and check that the chain boots and derives from it",
+						Description: `Restores the recovery point into a new EFS and runs copies of op-geth and op-node on it in a
+temporary namespace, with p2p and sequencing turned off. The restored head must be a block of the live
+chain with the same state root, its state must be readable, and op-node must advance its safe head to
+canonical blocks. The namespace and EFS are deleted afterwards and the report is written to
+backup-verifications/<namespace>.json.`,
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "recovery-point", Usage: "EFS recovery point ARN to verify", Required: true},
+							&cli.DurationFlag{Name: "timeout", Value: thanos.DefaultBackupVerifyDerivationTimeout, Usage: "How long op-node may take to resume derivation"},
+							&cli.BoolFlag{Name: "keep-resources", Usage: "Keep the scratch namespace and EFS for inspection"},
+						},
+						Action: commands.ActionBackupManagerVerify(),
+					},
+					{
+						Name:  "failover",
+						Usage: "Bring the chain up in the secondary region from a copied recovery point",
+						Description: `Disaster recovery runbook for when the chain region is lost. Needs recovery point copies in the
+secondary region (backup-manager --config --copy-region) and a standby EKS cluster there with the EFS CSI
+driver. The copy is restored into a new EFS with mount targets in the cluster VPC, the chain and bridge
+Helm releases are installed on the standby cluster against it, and the optional DNS record is pointed at
+the new ingress. settings.json is updated with the new region and L2 RPC URL, and the report is written
+to backup-failovers/<region>-<unix time>.json.`,
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "region", Usage: "Secondary region to fail over to", Required: true},
+							&cli.StringFlag{Name: "recovery-point", Usage: "Recovery point ARN in the secondary region (default: latest copy)"},
+							&cli.StringFlag{Name: "cluster", Usage: "Standby EKS cluster name (default: the chain namespace)"},
+							&cli.StringFlag{Name: "hosted-zone-id", Usage: "Route 53 hosted zone of --dns-name"},
+							&cli.StringFlag{Name: "dns-name", Usage: "DNS name to point at the new L2 RPC ingress (CNAME)"},
+						},
+						Action: commands.ActionBackupManagerFailover(),
+					},
+				},
+			},
+			{
+				Name:  "snapshot",
+				Usage: "Export and import op-geth datadir snapshots to bootstrap new nodes",
+				Description: `A snapshot is a directory with the gzipped op-geth datadir and manifest.json, which records the
+block number and hash, state root, genesis hash, client version and the sha256 of the archive. It is
+written to a local directory or an S3-compatible store (s3://bucket/prefix, --endpoint-url for stores
+other than AWS S3). The p2p key of the node is not included.
+
+Examples:
+    trh-sdk snapshot export --to /mnt/snapshots
+    trh-sdk snapshot export --to s3://my-bucket/snapshots --endpoint-url https://minio.example.com
+    trh-sdk snapshot import --from s3://my-bucket/snapshots/20260501-100000
+    trh-sdk snapshot import --from /mnt/snapshots/20260501-100000 --pvc replica-op-geth --namespace replica`,
+				Commands: []*cli.Command{
+					{
+						Name:  "export",
+						Usage: "Archive the op-geth datadir of the deployment with a manifest of its head",
+						Description: `op-node and then op-geth are stopped (docker containers, or StatefulSets scaled to zero on
+Kubernetes) so the archive is consistent with the recorded head, and started again afterwards. The
+snapshot is written to a new <destination>/<timestamp> directory. On Kubernetes the chain is down for
+the whole archive, so the export asks for confirmation with the expected downtime unless --yes is set.`,
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "to", Usage: "Directory or s3://bucket/prefix the snapshot directory is created in", Required: true},
+							&cli.StringFlag{Name: "endpoint-url", Usage: "Endpoint of an S3-compatible store"},
+							&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "Scale down the sequencer without confirmation"},
+						},
+						Action: commands.ActionSnapshotExport(),
+					},
+					{
+						Name:  "import",
+						Usage: "Seed an op-geth datadir from a snapshot",
+						Description: `Without --volume or --pvc the op-geth datadir of the deployment is replaced while the chain is
+stopped, and op-geth must serve the snapshot block once it is back. --volume seeds another docker
+volume and --pvc another claim, for a new replica. The checksum is verified while the archive is
+extracted, and the datadir is emptied again when it does not match.`,
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "from", Usage: "Snapshot directory or s3:// URL printed by the export", Required: true},
+							&cli.StringFlag{Name: "endpoint-url", Usage: "Endpoint of an S3-compatible store"},
+							&cli.StringFlag{Name: "volume", Usage: "Docker volume to seed instead of the op-geth volume of the deployment"},
+							&cli.StringFlag{Name: "pvc", Usage: "Persistent volume claim to seed instead of the op-geth claim of the deployment"},
+							&cli.StringFlag{Name: "namespace", Usage: "Namespace of --pvc (default: the chain namespace)"},
+							&cli.StringFlag{Name: "sub-path", Usage: "Directory of the datadir in --pvc"},
+							&cli.BoolFlag{Name: "allow-other-chain", Usage: "Import a snapshot of another chain ID"},
+						},
+						Action: commands.ActionSnapshotImport(),
+					},
+				},
 			},
 			{
 				Name:   "deploy-contracts",
@@ -82,6 +180,47 @@ Examples:
   trh-sdk destroy
 `,
 			},
+			{
+				Name:  "cost",
+				Usage: "Estimate the monthly AWS cost of a deployment",
+				Commands: []*cli.Command{
+					{
+						Name:  "estimate",
+						Usage: "Estimate the monthly AWS cost from the terraform plan and Helm values",
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "plan", Usage: "Terraform plan file (binary or 'terraform show -json' output). Default: tokamak-thanos-stack/terraform/thanos-stack/tfplan"},
+							&cli.StringFlag{Name: "values", Usage: "thanos-stack Helm values file. Default: tokamak-thanos-stack/terraform/thanos-stack/thanos-stack-values.yaml"},
+							&cli.StringFlag{Name: "price-table", Usage: fmt.Sprintf("Price table JSON overriding the bundled one. Default: ./%s if present", thanos.CostPriceTableFileName)},
+							&cli.StringFlag{Name: "preset", Usage: fmt.Sprintf("Preset to estimate (%s). Default: preset in settings.json", strings.Join(constants.ValidPresets, ", "))},
+							&cli.StringFlag{Name: "network", Usage: "Network to estimate (testnet, mainnet). Default: network in settings.json"},
+							&cli.StringFlag{Name: "compare", Usage: "Comma-separated presets to compare against, or 'all'"},
+							&cli.BoolFlag{Name: "json", Usage: "Print the cost report as JSON"},
+						},
+						Action: commands.ActionCostEstimate(),
+						Description: `Estimate the monthly AWS cost of a planned deployment
+
+Examples:
+  # Estimate from the generated terraform plan and Helm values
+  cd tokamak-thanos-stack/terraform/thanos-stack && terraform plan -out tfplan && cd -
+  trh-sdk cost estimate
+
+  # Compare all presets
+  trh-sdk cost estimate --compare all
+
+  # Estimate a specific preset with an updated price table as JSON
+  trh-sdk cost estimate --preset defi --price-table ./prices.json --json
+  `,
+					},
+					{
+						Name:  "export-prices",
+						Usage: "Write the bundled price table so it can be updated",
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "output", Usage: fmt.Sprintf("Output path. Default: ./%s", thanos.CostPriceTableFileName)},
+						},
+						Action: commands.ActionCostExportPrices(),
+					},
+				},
+			},
 			{
 				Name:  "install",
 				Usage: fmt.Sprintf("Install plugins(allowed: %s)", strings.Join(constants.SupportedPluginsList, ", ")),
@@ -158,32 +297,177 @@ Examples:
 Examples:
   # Get information about the running chain
   trh-sdk info
+  `,
+			},
+			{
+				Name:   "health",
+				Usage:  "Check the sync status of the rollup",
+				Action: commands.ActionHealth(),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "json", Usage: "Print the health report as JSON"},
+					&cli.StringFlag{Name: "l1-rpc-url", Usage: "L1 RPC URL (default: L1 RPC in settings.json)"},
+					&cli.StringFlag{Name: "l2-rpc-url", Usage: "L2 RPC URL (default: resolved from the deployment)"},
+					&cli.StringFlag{Name: "op-node-url", Usage: "op-node RPC URL (default: localhost for local deployments, a port-forward on AWS)"},
+					&cli.DurationFlag{Name: "max-unsafe-age", Usage: "Warn when the unsafe head is older than this (default: 1m)"},
+					&cli.DurationFlag{Name: "max-safe-lag", Usage: "Warn when the safe head lags the unsafe head by more than this (default: from the L1 channel duration)"},
+					&cli.DurationFlag{Name: "max-finalized-lag", Usage: "Warn when the finalized head lags the unsafe head by more than this"},
+					&cli.DurationFlag{Name: "max-batch-age", Usage: "Warn when the last batch is older than this (default: from the L1 channel duration)"},
+					&cli.DurationFlag{Name: "max-output-age", Usage: "Warn when the last output root or dispute game is older than this"},
+					&cli.UintFlag{Name: "max-l1-drift", Usage: "Warn when op-node is more L1 blocks behind the L1 RPC (default: 10)"},
+					&cli.UintFlag{Name: "max-l2-drift", Usage: "Warn when the L2 RPC is more blocks behind the unsafe head (default: 10)"},
+					&cli.UintFlag{Name: "min-peers", Usage: "Minimum op-node and op-geth peers (default: 0, P2P is optional)"},
+				},
+				Description: `Check the sync status of the rollup with op-node optimism_syncStatus, op-geth and L1
+
+Checks above their threshold are warnings and above twice the threshold critical.
+The exit code is 0 (ok), 1 (warning), 2 (critical) or 3 (unknown).
+
+Examples:
+  # Check the chain in the current deployment directory
+  trh-sdk health
+
+  # Machine-readable output for cron and CI
+  trh-sdk health --json
+
+  # Stricter thresholds
+  trh-sdk health --max-unsafe-age 30s --max-batch-age 20m
+  `,
+			},
+			{
+				Name:   "balance-watcher",
+				Usage:  "Watch the L1 balances of the batcher, proposer and challenger and top them up",
+				Action: commands.ActionBalanceWatcher(),
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "l1-rpc-url", Usage: "L1 RPC URL (default: L1 RPC in settings.json)"},
+					&cli.StringFlag{Name: "threshold", Value: "0.5", Usage: "Balance in ETH below which an account is low"},
+					&cli.BoolFlag{Name: "top-up", Usage: "Top up low accounts from the funding wallet in $TRH_FUNDING_PRIVATE_KEY"},
+					&cli.StringFlag{Name: "top-up-amount", Value: "1", Usage: "ETH sent to a low account"},
+					&cli.StringFlag{Name: "daily-cap", Value: "3", Usage: "Maximum ETH sent by the funding wallet per UTC day"},
+					&cli.DurationFlag{Name: "interval", Value: time.Minute, Usage: "Balance check interval"},
+					&cli.StringFlag{Name: "listen", Value: thanos.DefaultBalanceWatcherListenAddr, Usage: "Address of the /metrics and /healthz endpoints (empty to disable)"},
+				},
+				Description: `Watch the L1 balances of the operator accounts in settings.json until interrupted
+
+Balances are exported as Prometheus metrics and the OperatorBalanceLow alert fires below the threshold.
+With --top-up, low accounts receive --top-up-amount from the funding wallet, up to --daily-cap per UTC day.
+The daily spend is kept in balance-watcher-state.json so restarts do not reset the cap.
+
+Examples:
+  # Only export balances and alert
+  trh-sdk balance-watcher
+
+  # Top up accounts below 1 ETH with 2 ETH, at most 6 ETH a day
+  TRH_FUNDING_PRIVATE_KEY=0x... trh-sdk balance-watcher --top-up --threshold 1 --top-up-amount 2 --daily-cap 6
   `,
 			},
 			{
 				Name:  "logs",
 				Usage: "Show logs of the running chain",
 				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:    "component",
+						Aliases: []string{"c"},
+						Usage:   fmt.Sprintf("Component name, repeatable (default: all running components; allowed: %s)", strings.Join(allowedComponentList(), ", ")),
+					},
+					&cli.BoolFlag{
+						Name:    "follow",
+						Aliases: []string{"f"},
+						Usage:   "Keep streaming new lines",
+					},
+					&cli.DurationFlag{
+						Name:  "since",
+						Usage: "Only show lines newer than this, e.g. 10m or 2h",
+					},
+					&cli.IntFlag{
+						Name:  "tail",
+						Value: 100,
+						Usage: "Lines per container or pod (-1 for all)",
+					},
 					&cli.StringFlag{
-						Name:     "component",
-						Aliases:  []string{"c"},
-						Required: true,
-						Usage:    fmt.Sprintf("Component name (allowed: %s)", strings.Join(allowedComponentList(), ", ")),
-						Value:    "",
+						Name:  "grep",
+						Usage: "Only show lines matching this regular expression",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print one JSON object per line",
 					},
 					&cli.BoolFlag{
 						Name:     "troubleshoot",
 						Aliases:  []string{"t"},
 						Required: false,
-						Usage:    "Show logs of the running chain with troubleshoot mode",
+						Usage:    "Only show errors, failures and panics (trh-sdk troubleshoot diagnoses them)",
 					},
 				},
 				Action: commands.ActionShowLogs(),
-				Description: `Show logs of the running chain
+				Description: `Show the merged logs of the running chain, for local and AWS deployments
+
+Lines of all components are prefixed with their component and sorted by timestamp.
+With --follow they are printed as they arrive.
+
+Examples:
+  # Follow all components
+  trh-sdk logs --follow
+
+  # The last hour of op-node and op-batcher
+  trh-sdk logs -c op-node -c op-batcher --since 1h
+
+  # Errors of the DRB and the bundler, as JSON
+  trh-sdk logs -c drb -c alto-bundler --troubleshoot --json
+  `,
+			},
+			{
+				Name:   "troubleshoot",
+				Usage:  "Diagnose known failures from the logs of the running chain",
+				Action: commands.ActionTroubleshoot(),
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:    "component",
+						Aliases: []string{"c"},
+						Usage:   fmt.Sprintf("Component to scan, repeatable (default: all; allowed: %s, %s, %s)", strings.Join(allowedComponentList(), ", "), thanos.TroubleshootSDKComponent, thanos.TroubleshootEventsComponent),
+					},
+					&cli.DurationFlag{Name: "since", Value: time.Hour, Usage: "Window of logs to scan"},
+					&cli.BoolFlag{Name: "json", Usage: "Print the report as JSON"},
+					&cli.BoolFlag{Name: "list-rules", Usage: "List the troubleshoot rules and exit"},
+				},
+				Description: `Match the component logs, the Kubernetes events and the trh-sdk logs against known failure
+signatures and print a diagnosis with the commands to fix it
+
+The rules are stored as data. Add your own, or override a built-in rule by its id, in
+troubleshoot-rules.yaml next to settings.json. Errors of other commands are matched against
+the same rules.
 
 Examples:
-  # Show logs of the running chain
-  trh-sdk logs --component op-node --troubleshoot
+  # Diagnose the last hour
+  trh-sdk troubleshoot
+
+  # The last day of op-node and op-batcher, as JSON
+  trh-sdk troubleshoot -c op-node -c op-batcher --since 24h --json
+
+  # Show the rules in use
+  trh-sdk troubleshoot --list-rules
+  `,
+			},
+			{
+				Name:   "support-bundle",
+				Usage:  "Collect a redacted diagnostics archive of the deployment",
+				Action: commands.ActionSupportBundle(),
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "Path of the tarball (default: support-bundle-<timestamp>.tar.gz in the current directory)"},
+					&cli.DurationFlag{Name: "log-since", Value: time.Hour, Usage: "Window of component logs to include (0 for the last 2000 lines per container or pod)"},
+				},
+				Description: `Collect a redacted diagnostics archive of the deployment
+
+The tarball contains settings.json, rollup.json, the genesis without its alloc, the docker compose
+file or the pods, events, Helm values and terraform outputs, recent component and trh-sdk logs,
+tool versions and the health report. Private keys, passwords, API keys and tokens are masked.
+manifest.json lists the files and whatever could not be collected.
+
+Examples:
+  # Bundle with the last hour of logs
+  trh-sdk support-bundle
+
+  # Bundle with the last day of logs to a given path
+  trh-sdk support-bundle --log-since 24h -o /tmp/bundle.tar.gz
   `,
 			},
 			{
@@ -241,8 +525,27 @@ Examples:
   # Configure telegram channel
   trh-sdk alert-config --channel telegram --configure
 
+  # Configure slack channel for critical and warning alerts
+  trh-sdk alert-config --channel slack --configure --url https://hooks.slack.com/services/... --severity critical,warning
+
+  # Page the on-call rotation for critical alerts only
+  trh-sdk alert-config --channel pagerduty --configure --routing-key <integration-key> --severity critical
+
   # Reset all alert rules to default values
-  trh-sdk alert-config --rule reset`,
+  trh-sdk alert-config --rule reset
+
+  # Export all alert rules and channels to a policy file
+  trh-sdk alert-config export -o alert-policy.yaml
+
+  # Show the diff against the cluster and apply the policy file
+  trh-sdk alert-config apply -f alert-policy.yaml
+
+  # Silence op-node alerts for two hours during maintenance
+  trh-sdk alert-config silence add --matcher 'component="op-node"' --duration 2h --comment "node migration"
+
+  # List and expire silences
+  trh-sdk alert-config silence list
+  trh-sdk alert-config silence expire <silence-id>`,
 				Flags: []cli.Flag{
 					&cli.BoolFlag{
 						Name:     "status",
@@ -254,7 +557,7 @@ Examples:
 						Name:     "channel",
 						Aliases:  []string{"c"},
 						Required: false,
-						Usage:    "Channel type (email, telegram)",
+						Usage:    "Channel type (email, telegram, slack, discord, pagerduty, webhook)",
 						Value:    "",
 					},
 					&cli.BoolFlag{
@@ -267,6 +570,26 @@ Examples:
 						Required: false,
 						Usage:    "Configure the specified channel",
 					},
+					&cli.StringFlag{
+						Name:  "url",
+						Usage: "Webhook URL for the slack, discord or webhook channel (skips prompts)",
+					},
+					&cli.StringFlag{
+						Name:  "slack-channel",
+						Usage: "Slack channel override, e.g. #alerts",
+					},
+					&cli.StringFlag{
+						Name:  "routing-key",
+						Usage: "PagerDuty Events API v2 integration key (skips prompts)",
+					},
+					&cli.StringFlag{
+						Name:  "bearer-token",
+						Usage: "Bearer token sent to the generic webhook",
+					},
+					&cli.StringFlag{
+						Name:  "severity",
+						Usage: "Comma-separated severities routed to the channel (critical, warning, info; default: all)",
+					},
 
 					&cli.StringFlag{
 						Name:     "rule",
@@ -276,6 +599,124 @@ Examples:
 					},
 				},
 				Action: commands.ActionAlertConfig(),
+				Commands: []*cli.Command{
+					{
+						Name:  "export",
+						Usage: "Export all alert rules and channels as a YAML policy file",
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "Output file (default: stdout)"},
+							&cli.BoolFlag{Name: "include-secrets", Usage: "Write channel secrets instead of environment placeholders"},
+						},
+						Action: commands.ActionAlertConfigExport(),
+					},
+					{
+						Name:  "apply",
+						Usage: "Diff a YAML policy file against the cluster and apply it",
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "file", Aliases: []string{"f"}, Usage: "Alert policy file", Required: true},
+							&cli.BoolFlag{Name: "dry-run", Usage: "Validate and show the diff without applying"},
+							&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "Apply without confirmation"},
+						},
+						Action: commands.ActionAlertConfigApply(),
+					},
+					{
+						Name:  "silence",
+						Usage: "Manage AlertManager silences",
+						Commands: []*cli.Command{
+							{
+								Name:  "add",
+								Usage: "Silence matching alerts for a duration (all alerts without --matcher)",
+								Flags: []cli.Flag{
+									&cli.StringSliceFlag{Name: "matcher", Aliases: []string{"m"}, Usage: `Label matcher, e.g. component="op-node" or severity=~"warning|info" (repeatable)`},
+									&cli.StringFlag{Name: "duration", Aliases: []string{"d"}, Value: "2h", Usage: "Silence duration"},
+									&cli.StringFlag{Name: "comment", Usage: "Reason for the silence"},
+									&cli.StringFlag{Name: "author", Usage: "Silence author (default: current user)"},
+								},
+								Action: commands.ActionAlertSilenceAdd(),
+							},
+							{
+								Name:  "list",
+								Usage: "List active and pending silences",
+								Flags: []cli.Flag{
+									&cli.BoolFlag{Name: "expired", Usage: "Include expired silences"},
+									&cli.BoolFlag{Name: "json", Usage: "Print silences as JSON"},
+								},
+								Action: commands.ActionAlertSilenceList(),
+							},
+							{
+								Name:      "expire",
+								Usage:     "Expire a silence",
+								ArgsUsage: "<silence-id>",
+								Action:    commands.ActionAlertSilenceExpire(),
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "monitoring",
+				Usage: "Manage the monitoring stack",
+				Commands: []*cli.Command{
+					{
+						Name:  "dashboards",
+						Usage: "List, export and import Grafana dashboards",
+						Description: `Manage Grafana dashboards through the Grafana HTTP API. Local deployments use the Grafana
+container on localhost:3002; AWS deployments use the ALB ingress of the monitoring plugin.
+
+Examples:
+  # List the dashboards in Grafana
+  trh-sdk monitoring dashboards list
+
+  # Export a dashboard, or all dashboards into a directory for version control
+  trh-sdk monitoring dashboards export thanos-stack-app-v9 -o application.json
+  trh-sdk monitoring dashboards export --all --dir dashboards
+
+  # Import dashboards from files or a directory, replacing existing ones
+  trh-sdk monitoring dashboards import -f dashboards --overwrite
+
+  # Import the built-in dashboards
+  trh-sdk monitoring dashboards list --builtin
+  trh-sdk monitoring dashboards import --builtin all --overwrite`,
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "url", Usage: "Grafana URL (default: resolved from the deployment)"},
+							&cli.StringFlag{Name: "user", Usage: "Grafana user (default: admin)"},
+							&cli.StringFlag{Name: "password", Usage: "Grafana password (default: resolved from the deployment)"},
+						},
+						Commands: []*cli.Command{
+							{
+								Name:  "list",
+								Usage: "List dashboards",
+								Flags: []cli.Flag{
+									&cli.BoolFlag{Name: "builtin", Usage: "List the built-in dashboards shipped with trh-sdk"},
+									&cli.BoolFlag{Name: "json", Usage: "Print dashboards as JSON"},
+								},
+								Action: commands.ActionMonitoringDashboardsList(),
+							},
+							{
+								Name:      "export",
+								Usage:     "Export a dashboard as JSON",
+								ArgsUsage: "<uid>",
+								Flags: []cli.Flag{
+									&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "Output file (default: stdout)"},
+									&cli.BoolFlag{Name: "all", Usage: "Export every dashboard"},
+									&cli.StringFlag{Name: "dir", Value: "dashboards", Usage: "Output directory for --all"},
+								},
+								Action: commands.ActionMonitoringDashboardsExport(),
+							},
+							{
+								Name:  "import",
+								Usage: "Import dashboards from JSON files or the built-in set",
+								Flags: []cli.Flag{
+									&cli.StringSliceFlag{Name: "file", Aliases: []string{"f"}, Usage: "Dashboard JSON file or directory (repeatable)"},
+									&cli.StringSliceFlag{Name: "builtin", Usage: "Built-in dashboard name, or 'all' (repeatable)"},
+									&cli.StringFlag{Name: "folder", Usage: "UID of the Grafana folder to import into"},
+									&cli.BoolFlag{Name: "overwrite", Usage: "Replace dashboards with the same uid or title"},
+								},
+								Action: commands.ActionMonitoringDashboardsImport(),
+							},
+						},
+					},
+				},
 			},
 			{
 				Name:  "log-collection",
@@ -379,48 +820,163 @@ Examples:
 Examples:
   # Display leader node information
   trh-sdk drb leader-info
+  `,
+					},
+					{
+						Name:   "exporter",
+						Usage:  "Export the DRB rounds of the CommitReveal2L2 contract as Prometheus metrics",
+						Action: commands.ActionDRBExporter(),
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "rpc-url", Usage: "RPC URL of the DRB chain (default: drb-leader-info.json, or the L2 RPC in settings.json)"},
+							&cli.StringFlag{Name: "contract", Usage: "CommitReveal2L2 address (default: drb-leader-info.json, or the predeploy)"},
+							&cli.DurationFlag{Name: "interval", Value: 15 * time.Second, Usage: "Contract read interval"},
+							&cli.StringFlag{Name: "listen", Value: thanos.DefaultDRBExporterListenAddr, Usage: "Address of the /metrics and /healthz endpoints"},
+						},
+						Description: `Read the current round, trial, request count and activated operators of the
+CommitReveal2L2 contract until interrupted and export them as drb_* metrics for the drb-rounds dashboard
+
+Examples:
+  # Export the rounds of the deployment in the current directory
+  trh-sdk drb exporter
   `,
 					},
 				},
 			},
+			{
+				Name:  "cross-trade",
+				Usage: "Monitor the cross-trade requests and token registrations",
+				Commands: []*cli.Command{
+					{
+						Name:   "status",
+						Usage:  "Report the cross-trade requests by status and token",
+						Action: commands.ActionCrossTradeStatus(),
+						Flags: []cli.Flag{
+							&cli.BoolFlag{Name: "json", Usage: "Print the report with every request as JSON"},
+							&cli.StringFlag{Name: "mode", Usage: fmt.Sprintf("Report only one deploy mode: %s or %s (default: every deployed mode)", constants.CrossTradeDeployModeL2ToL1, constants.CrossTradeDeployModeL2ToL2)},
+							&cli.UintFlag{Name: "from-block", Usage: "First L2 block searched for requests"},
+							&cli.DurationFlag{Name: "expire-after", Usage: "Age after which a request without provider is expired (default: 24h)"},
+							&cli.DurationFlag{Name: "stuck-after", Usage: "Time after which a request provided or cancelled on L1 and not completed on L2 is stuck (default: 1h)"},
+						},
+						Description: `Index the request, provide, cancel and claim events of the L2CrossTrade,
+L2toL2CrossTrade and L1CrossTrade contracts in settings.json and report the requests by status
+
+Requests are open, expired, relaying (provided or cancelled on L1, waiting for the L2 message),
+stuck, fulfilled or cancelled. The exit code is 1 when requests are stuck.
+
+Examples:
+  # Report the requests of every deployed mode
+  trh-sdk cross-trade status
+
+  # Only L2 to L2 requests, as JSON for cron and CI
+  trh-sdk cross-trade status --mode l2_to_l2 --json
+  `,
+					},
+					{
+						Name:  "tokens",
+						Usage: "Compare the token registrations of the cross-trade contracts with settings.json",
+						Description: `Check every token pair of settings.json with registerCheck on the L2 cross-trade contracts.
+The L2toL2 contracts also replay RegisterToken and DeleteToken to find the registrations that
+differ from settings.json. The L2CrossTrade contract emits no registration events, so only its
+missing registrations are found.
+
+Examples:
+  # Show the missing and incorrect registrations, the exit code is 1 when there are any
+  trh-sdk cross-trade tokens diff
+
+  # Show the registrations sync would submit
+  trh-sdk cross-trade tokens sync --dry-run
+
+  # Register the missing tokens of the L2 to L2 contracts
+  trh-sdk cross-trade tokens sync --mode l2_to_l2
+  `,
+						Commands: []*cli.Command{
+							{
+								Name:   "diff",
+								Usage:  "Show the token registrations that differ from settings.json",
+								Action: commands.ActionCrossTradeTokensDiff(),
+								Flags: []cli.Flag{
+									&cli.BoolFlag{Name: "json", Usage: "Print the comparison as JSON"},
+									&cli.StringFlag{Name: "mode", Usage: fmt.Sprintf("Compare only one deploy mode: %s or %s (default: every deployed mode)", constants.CrossTradeDeployModeL2ToL1, constants.CrossTradeDeployModeL2ToL2)},
+									&cli.UintFlag{Name: "from-block", Usage: "First L2 block searched for the registration events of the L2toL2 contracts"},
+								},
+							},
+							{
+								Name:   "sync",
+								Usage:  "Submit the missing and incorrect token registrations of settings.json",
+								Action: commands.ActionCrossTradeTokensSync(),
+								Flags: []cli.Flag{
+									&cli.BoolFlag{Name: "dry-run", Usage: "Show the registrations without submitting them"},
+									&cli.BoolFlag{Name: "yes", Usage: "Submit without confirmation"},
+									&cli.StringFlag{Name: "mode", Usage: fmt.Sprintf("Sync only one deploy mode: %s or %s (default: every deployed mode)", constants.CrossTradeDeployModeL2ToL1, constants.CrossTradeDeployModeL2ToL2)},
+									&cli.UintFlag{Name: "from-block", Usage: "First L2 block searched for the registration events of the L2toL2 contracts"},
+								},
+							},
+						},
+					},
+				},
+			},
 			{
 				Name:  "shutdown",
 				Usage: "Manage L2 shutdown and force withdrawal process",
 				Description: `Manage L2 chain shutdown and force withdrawal operations in 5 steps:
 1. block: Block L1 deposits and withdrawals
-2. fetch: Collect L2 asset information from explorer
+2. fetch: Index L2 asset information from the L2 RPC
 3. gen: Generate assets snapshot for force withdrawal
 4. activate: Prepare L1 bridge (upgrade and registration)
 5. withdraw: Execute liquidity sweep and withdrawal claims
 
 Examples:
   trh-sdk shutdown run --dry-run (Sequentially run all steps in simulation mode)
+  trh-sdk shutdown run (Resume at the first step that did not complete)
+  trh-sdk shutdown rehearse (Run all steps against a local fork of L1 and report)
   trh-sdk shutdown block
+  trh-sdk shutdown reconcile --format csv (Export the claim status of every address)
   trh-sdk shutdown gen --l2-start-block 0`,
 				Action: commands.ActionShutdown(),
 				Commands: []*cli.Command{
 					{
 						Name:  "run",
-						Usage: "Run the entire shutdown process sequentially (1->2->3->4->5)",
+						Usage: "Run the entire shutdown process sequentially (1->2->3->4->5), resuming at the first incomplete step",
 						Flags: []cli.Flag{
 							&cli.BoolFlag{Name: "dry-run", Usage: "Run the entire process in simulation mode"},
 							&cli.BoolFlag{Name: "skip-fetch", Usage: "Skip Step 2 (Fetch) if data already exists"},
+							&cli.BoolFlag{Name: "restart", Usage: "Discard the saved progress, including the fetch checkpoint, and run every step again"},
 							&cli.StringFlag{Name: "l2-start-block", Usage: "L2 start block (for Step 3)", Value: "0"},
 							&cli.StringFlag{Name: "storage-address", Usage: "GenFWStorage address to use for Step 5 (overrides auto-detection)"},
+							&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "Run a step interrupted after broadcasting again without confirmation"},
 						},
 						Action: commands.ActionShutdownRun(),
 					},
+					{
+						Name:  "rehearse",
+						Usage: "Run the entire shutdown process against a local anvil fork of L1 and report claimable assets, gas and reverts",
+						Flags: []cli.Flag{
+							&cli.UintFlag{Name: "fork-block", Usage: "L1 block to fork from (default: the current block)"},
+							&cli.BoolFlag{Name: "skip-fetch", Usage: "Skip Step 2 (Fetch) if data already exists"},
+							&cli.IntFlag{Name: "workers", Usage: "Number of block ranges indexed in parallel", Value: 4},
+							&cli.UintFlag{Name: "block-range", Usage: "Number of blocks indexed by a worker at a time", Value: 2000},
+							&cli.StringFlag{Name: "l2-start-block", Usage: "L2 start block (for Step 3)", Value: "0"},
+							&cli.StringFlag{Name: "l2-end-block", Usage: "L2 end block (for Step 3)", Value: "latest"},
+						},
+						Action: commands.ActionShutdownRehearse(),
+					},
 					{
 						Name:  "block",
 						Usage: "Step 1: Block L1 deposits and withdrawals",
 						Flags: []cli.Flag{
 							&cli.BoolFlag{Name: "dry-run", Usage: "Simulate without broadcasting"},
+							&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "Run a step interrupted after broadcasting again without confirmation"},
 						},
 						Action: commands.ActionShutdownBlock(),
 					},
 					{
-						Name:   "fetch",
-						Usage:  "Step 2: Collect L2 asset information via Python script",
+						Name:  "fetch",
+						Usage: "Step 2: Index L2 asset information from the L2 RPC",
+						Flags: []cli.Flag{
+							&cli.IntFlag{Name: "workers", Usage: "Number of block ranges indexed in parallel", Value: 4},
+							&cli.UintFlag{Name: "block-range", Usage: "Number of blocks indexed by a worker at a time", Value: 2000},
+							&cli.BoolFlag{Name: "restart", Usage: "Discard the checkpoint of a previous fetch and index from block 0"},
+						},
 						Action: commands.ActionShutdownFetch(),
 					},
 					{
@@ -430,6 +986,7 @@ Examples:
 							&cli.StringFlag{Name: "l2-start-block", Usage: "L2 start block number", Value: "0"},
 							&cli.StringFlag{Name: "l2-end-block", Usage: "L2 end block number", Value: "latest"},
 							&cli.BoolFlag{Name: "dry-run", Usage: "Simulate snapshot generation"},
+							&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "Run a step interrupted after broadcasting again without confirmation"},
 						},
 						Action: commands.ActionShutdownGen(),
 					},
@@ -439,6 +996,7 @@ Examples:
 						Flags: []cli.Flag{
 							&cli.StringFlag{Name: "input", Usage: "Path to assets snapshot file"},
 							&cli.BoolFlag{Name: "dry-run", Usage: "Simulate upgrades and registration"},
+							&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "Run a step interrupted after broadcasting again without confirmation"},
 						},
 						Action: commands.ActionShutdownActivate(),
 					},
@@ -449,9 +1007,22 @@ Examples:
 							&cli.StringFlag{Name: "input", Usage: "Path to assets snapshot file"},
 							&cli.BoolFlag{Name: "dry-run", Usage: "Simulate liquidity sweep and claims"},
 							&cli.StringFlag{Name: "storage-address", Usage: "GenFWStorage address (skip file lookup)"},
+							&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "Run a step interrupted after broadcasting again without confirmation"},
 						},
 						Action: commands.ActionShutdownWithdraw(),
 					},
+					{
+						Name:  "reconcile",
+						Usage: "Compare the assets snapshot with GenFWStorage and the L1 claims, and export the claim status of every address",
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "input", Usage: "Path to assets snapshot file"},
+							&cli.StringFlag{Name: "storage-address", Usage: "GenFWStorage address (default: the one deployed by activate)"},
+							&cli.UintFlag{Name: "from-block", Usage: "First L1 block searched for claims (default: the block of the activate transaction)"},
+							&cli.StringFlag{Name: "format", Usage: "Export format: csv, json or both", Value: "both"},
+							&cli.StringFlag{Name: "output", Usage: "Directory of the exported claim status (default: the deployment directory)"},
+						},
+						Action: commands.ActionShutdownReconcile(),
+					},
 					{
 						Name:   "status",
 						Usage:  "Show current shutdown status",
@@ -463,6 +1034,7 @@ Examples:
 	}
 
 	if err := cmd.Run(context.Background(), os.Args); err != nil {
+		commands.PrintErrorDiagnosis(err)
 		log.Fatal(err)
 	}
 }
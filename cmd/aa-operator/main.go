@@ -13,6 +13,7 @@
 //
 //	COINGECKO_API_KEY — CoinGecko Pro API key; falls back to free-tier endpoint if unset
 //	L2_RPC_URL        — L2 RPC URL; defaults to op-geth:8545 detection logic
+//	METRICS_ADDR      — listen address of /metrics and /healthz; defaults to :7311, "off" disables
 package main
 
 import (
@@ -42,5 +43,6 @@ func main() {
 	thanos.RunAAOperator(ctx, thanos.AAOperatorConfig{
 		FeeToken:        feeToken,
 		AdminPrivateKey: adminPrivKey,
+		MetricsAddr:     thanos.AAOperatorMetricsAddr(os.Getenv("METRICS_ADDR")),
 	})
 }
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/scanner"
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// routedChannelFlags holds the non-interactive settings of a Slack, Discord, PagerDuty or webhook channel.
+// Missing values are prompted for.
+type routedChannelFlags struct {
+	URL          string
+	SlackChannel string
+	RoutingKey   string
+	BearerToken  string
+	Severity     string
+}
+
+func routedChannelTitle(channelType string) string {
+	switch channelType {
+	case constants.ChannelSlack:
+		return "Slack"
+	case constants.ChannelDiscord:
+		return "Discord"
+	case constants.ChannelPagerDuty:
+		return "PagerDuty"
+	case constants.ChannelWebhook:
+		return "Webhook"
+	default:
+		return channelType
+	}
+}
+
+func disableRoutedChannel(ctx context.Context, ac *thanos.AlertCustomization, channelType string) error {
+	title := routedChannelTitle(channelType)
+	fmt.Printf("🔔 Disabling %s Channel...\n", title)
+
+	config, err := ac.GetAlertManagerConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get AlertManager config: %w", err)
+	}
+
+	if ac.GetChannelStatus(config, channelType) == "Disabled" {
+		fmt.Printf("ℹ️  %s channel is already disabled\n", title)
+		return nil
+	}
+
+	fmt.Printf("🔧 Removing %s configuration from AlertManager...\n", channelType)
+	if err := ac.RemoveRoutedChannelConfig(ctx, channelType); err != nil {
+		return fmt.Errorf("failed to disable %s channel: %w", channelType, err)
+	}
+
+	fmt.Printf("✅ %s channel disabled successfully\n", title)
+	return nil
+}
+
+func configureRoutedChannel(ctx context.Context, ac *thanos.AlertCustomization, channelType string, flags routedChannelFlags) error {
+	title := routedChannelTitle(channelType)
+	fmt.Printf("🔔 Configuring %s Channel...\n", title)
+
+	var config types.AlertManagerConfig
+	var err error
+	switch channelType {
+	case constants.ChannelSlack:
+		config.Slack.Enabled = true
+		if config.Slack.WebhookURL, err = flagOrPrompt(flags.URL, "Slack Incoming Webhook URL", true); err != nil {
+			return err
+		}
+		config.Slack.Channel = flags.SlackChannel
+		if flags.URL == "" && flags.SlackChannel == "" {
+			if config.Slack.Channel, err = flagOrPrompt("", "Slack channel override (e.g. #alerts, empty for the webhook default)", false); err != nil {
+				return err
+			}
+		}
+	case constants.ChannelDiscord:
+		config.Discord.Enabled = true
+		if config.Discord.WebhookURL, err = flagOrPrompt(flags.URL, "Discord Webhook URL", true); err != nil {
+			return err
+		}
+	case constants.ChannelPagerDuty:
+		config.PagerDuty.Enabled = true
+		if config.PagerDuty.RoutingKey, err = flagOrPrompt(flags.RoutingKey, "PagerDuty Events API v2 integration key", true); err != nil {
+			return err
+		}
+	case constants.ChannelWebhook:
+		config.Webhook.Enabled = true
+		if config.Webhook.URL, err = flagOrPrompt(flags.URL, "Webhook URL", true); err != nil {
+			return err
+		}
+		// Optional values are only prompted for when the channel is configured interactively
+		config.Webhook.BearerToken = flags.BearerToken
+		if flags.URL == "" && flags.BearerToken == "" {
+			if config.Webhook.BearerToken, err = flagOrPrompt("", "Bearer token (empty for none)", false); err != nil {
+				return err
+			}
+		}
+	}
+
+	severityInput := flags.Severity
+	if severityInput == "" && !hasRequiredRoutedChannelFlag(channelType, flags) {
+		fmt.Printf("Severities to route to %s (comma-separated: %s, empty for all): ", title, strings.Join(constants.AlertSeverities, ","))
+		if severityInput, err = scanner.ScanString(); err != nil {
+			return fmt.Errorf("failed to read severities: %w", err)
+		}
+	}
+	severities, err := thanos.ParseAlertSeverities(severityInput)
+	if err != nil {
+		return err
+	}
+	config.Slack.Severities = severities
+	config.Discord.Severities = severities
+	config.PagerDuty.Severities = severities
+	config.Webhook.Severities = severities
+
+	routed := "all severities"
+	if len(severities) > 0 {
+		routed = strings.Join(severities, ", ")
+	}
+	fmt.Printf("🔔 %s Configuration Summary:\n", title)
+	fmt.Printf("   Severities: %s\n", routed)
+
+	fmt.Printf("🔧 Applying %s configuration to AlertManager...\n", channelType)
+	if err := ac.UpdateRoutedChannelConfig(ctx, channelType, config); err != nil {
+		return fmt.Errorf("failed to update AlertManager configuration: %w", err)
+	}
+
+	fmt.Printf("✅ %s channel configured successfully\n", title)
+	return nil
+}
+
+// hasRequiredRoutedChannelFlag reports whether the channel was configured non-interactively
+func hasRequiredRoutedChannelFlag(channelType string, flags routedChannelFlags) bool {
+	if channelType == constants.ChannelPagerDuty {
+		return flags.RoutingKey != ""
+	}
+	return flags.URL != ""
+}
+
+func flagOrPrompt(value, prompt string, required bool) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	for {
+		fmt.Printf("%s: ", prompt)
+		input, err := scanner.ScanString()
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", prompt, err)
+		}
+		input = strings.TrimSpace(input)
+		if input != "" || !required {
+			return input, nil
+		}
+		fmt.Println("⚠️  This value cannot be empty")
+	}
+}
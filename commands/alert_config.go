@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
@@ -13,11 +14,21 @@ import (
 	"github.com/urfave/cli/v3"
 )
 
+// newAlertCustomization returns the alert manager for the deployment in the current directory
+func newAlertCustomization(ctx context.Context) (*thanos.AlertCustomization, error) {
+	deploymentPath, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	return thanos.NewAlertCustomization(ctx, deploymentPath)
+}
+
 // ActionAlertConfig handles alert configuration commands
 func ActionAlertConfig() cli.ActionFunc {
 	return func(ctx context.Context, cmd *cli.Command) error {
-		// Check if monitoring plugin is installed
-		if err := utils.CheckMonitoringPluginInstalled(ctx); err != nil {
+		// Check that monitoring is running in the cluster or the local deployment
+		ac, err := newAlertCustomization(ctx)
+		if err != nil {
 			return err
 		}
 
@@ -30,16 +41,22 @@ func ActionAlertConfig() cli.ActionFunc {
 
 		// Handle status command
 		if status {
-			return handleAlertStatus(ctx)
+			return handleAlertStatus(ctx, ac)
 		}
 
 		// Handle channel commands
 		if channel != "" {
 			if disable {
-				return handleChannelDisable(ctx, channel)
+				return handleChannelDisable(ctx, ac, channel)
 			}
 			if configure {
-				return handleChannelConfigure(ctx, channel)
+				return handleChannelConfigure(ctx, ac, channel, routedChannelFlags{
+					URL:          cmd.String("url"),
+					SlackChannel: cmd.String("slack-channel"),
+					RoutingKey:   cmd.String("routing-key"),
+					BearerToken:  cmd.String("bearer-token"),
+					Severity:     cmd.String("severity"),
+				})
 			}
 			// If no operation specified, show help
 			fmt.Println("⚠️  Please specify an operation: --disable or --configure")
@@ -48,7 +65,7 @@ func ActionAlertConfig() cli.ActionFunc {
 
 		// Handle rule command
 		if rule != "" {
-			return handleRuleCommand(ctx, rule)
+			return handleRuleCommand(ctx, ac, rule)
 		}
 
 		// Show help if no valid command
@@ -57,44 +74,44 @@ func ActionAlertConfig() cli.ActionFunc {
 }
 
 // handleChannelDisable disables the specified channel
-func handleChannelDisable(ctx context.Context, channelType string) error {
+func handleChannelDisable(ctx context.Context, ac *thanos.AlertCustomization, channelType string) error {
 	// Validate channel type
 	if !constants.IsValidChannelType(channelType) {
 		return fmt.Errorf("invalid channel type: %s (valid types: %v)",
 			channelType, constants.GetValidChannelTypes())
 	}
 
-	ac := &thanos.AlertCustomization{}
-
 	switch channelType {
 	case constants.ChannelEmail:
 		return disableEmailChannel(ctx, ac)
 	case constants.ChannelTelegram:
 		return disableTelegramChannel(ctx, ac)
+	case constants.ChannelSlack, constants.ChannelDiscord, constants.ChannelPagerDuty, constants.ChannelWebhook:
+		return disableRoutedChannel(ctx, ac, channelType)
 	default:
-		return fmt.Errorf("unknown channel type: %s (must be '%s' or '%s')",
-			channelType, constants.ChannelEmail, constants.ChannelTelegram)
+		return fmt.Errorf("unknown channel type: %s (valid types: %v)",
+			channelType, constants.GetValidChannelTypes())
 	}
 }
 
 // handleChannelConfigure configures the specified channel
-func handleChannelConfigure(ctx context.Context, channelType string) error {
+func handleChannelConfigure(ctx context.Context, ac *thanos.AlertCustomization, channelType string, flags routedChannelFlags) error {
 	// Validate channel type
 	if !constants.IsValidChannelType(channelType) {
 		return fmt.Errorf("invalid channel type: %s (valid types: %v)",
 			channelType, constants.GetValidChannelTypes())
 	}
 
-	ac := &thanos.AlertCustomization{}
-
 	switch channelType {
 	case constants.ChannelEmail:
 		return configureEmailChannel(ctx, ac)
 	case constants.ChannelTelegram:
 		return configureTelegramChannel(ctx, ac)
+	case constants.ChannelSlack, constants.ChannelDiscord, constants.ChannelPagerDuty, constants.ChannelWebhook:
+		return configureRoutedChannel(ctx, ac, channelType, flags)
 	default:
-		return fmt.Errorf("unknown channel type: %s (must be '%s' or '%s')",
-			channelType, constants.ChannelEmail, constants.ChannelTelegram)
+		return fmt.Errorf("unknown channel type: %s (valid types: %v)",
+			channelType, constants.GetValidChannelTypes())
 	}
 }
 
@@ -107,8 +124,8 @@ func showAlertConfigHelp() error {
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Printf("  --status                    - Show current alert status and rules\n")
-	fmt.Printf("  --channel <type> --disable  - Disable notification channel (%s/%s)\n", constants.ChannelEmail, constants.ChannelTelegram)
-	fmt.Printf("  --channel <type> --configure- Configure notification channel (%s/%s)\n", constants.ChannelEmail, constants.ChannelTelegram)
+	fmt.Printf("  --channel <type> --disable  - Disable notification channel (%s)\n", strings.Join(constants.GetValidChannelTypes(), "/"))
+	fmt.Printf("  --channel <type> --configure- Configure notification channel (%s)\n", strings.Join(constants.GetValidChannelTypes(), "/"))
 	fmt.Println("  --rule <action>             - Manage alert rules (reset/set)")
 	fmt.Println()
 	fmt.Println("Examples:")
@@ -127,21 +144,16 @@ func showAlertConfigHelp() error {
 	fmt.Printf("  # Configure %s channel\n", constants.ChannelTelegram)
 	fmt.Printf("  trh-sdk alert-config --channel %s --configure\n", constants.ChannelTelegram)
 	fmt.Println()
+	fmt.Printf("  # Configure %s channel for critical alerts without prompts\n", constants.ChannelPagerDuty)
+	fmt.Printf("  trh-sdk alert-config --channel %s --configure --routing-key <key> --severity critical\n", constants.ChannelPagerDuty)
+	fmt.Println()
 	fmt.Println("  # Interactive rule configuration")
 	fmt.Println("  trh-sdk alert-config --rule set")
 	return nil
 }
 
 // handleAlertStatus shows current alert configuration
-func handleAlertStatus(ctx context.Context) error {
-	ac := &thanos.AlertCustomization{}
-
-	// Check monitoring namespace
-	_, err := utils.CheckNamespaceExists(ctx, constants.MonitoringNamespace)
-	if err != nil {
-		return fmt.Errorf("failed to check alert namespace: %w", err)
-	}
-
+func handleAlertStatus(ctx context.Context, ac *thanos.AlertCustomization) error {
 	// Get AlertManager configuration
 	alertManagerConfig, err := ac.GetAlertManagerConfig(ctx)
 	if err != nil {
@@ -152,6 +164,9 @@ func handleAlertStatus(ctx context.Context) error {
 	fmt.Println("========================")
 	fmt.Printf("   📧 Email channel: %s\n", ac.GetChannelStatus(alertManagerConfig, constants.ChannelEmail))
 	fmt.Printf("   📱 Telegram channel: %s\n", ac.GetChannelStatus(alertManagerConfig, constants.ChannelTelegram))
+	for _, channelType := range constants.RoutedChannels {
+		fmt.Printf("   🔔 %s channel: %s\n", routedChannelTitle(channelType), ac.GetChannelStatus(alertManagerConfig, channelType))
+	}
 
 	// Display configuration details
 	if alertManagerConfig != "" {
@@ -175,6 +190,21 @@ func handleAlertStatus(ctx context.Context) error {
 			fmt.Printf("      Bot Token: %s\n", telegramConfig.BotToken)
 			fmt.Printf("      Chat ID: %s\n", telegramConfig.ChatID)
 		}
+
+		// Slack, Discord, PagerDuty and webhook configuration
+		for _, channelType := range constants.RoutedChannels {
+			routedConfig := ac.GetRoutedChannelConfiguration(alertManagerConfig, channelType)
+			if !routedConfig.Enabled {
+				continue
+			}
+			severities := "all"
+			if len(routedConfig.Severities) > 0 {
+				severities = strings.Join(routedConfig.Severities, ", ")
+			}
+			fmt.Printf("   🔔 %s Configuration:\n", routedChannelTitle(channelType))
+			fmt.Printf("      Target: %s\n", routedConfig.Target)
+			fmt.Printf("      Severities: %s\n", severities)
+		}
 	}
 
 	// Display detailed rule status
@@ -273,9 +303,7 @@ func handleAlertStatus(ctx context.Context) error {
 }
 
 // handleRuleCommand handles rule-related commands
-func handleRuleCommand(ctx context.Context, ruleAction string) error {
-	ac := &thanos.AlertCustomization{}
-
+func handleRuleCommand(ctx context.Context, ac *thanos.AlertCustomization, ruleAction string) error {
 	switch ruleAction {
 	case "reset":
 		return resetAlertRules(ctx, ac)
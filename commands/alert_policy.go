@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/scanner"
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// ActionAlertConfigExport writes all alert rules and channels to a YAML policy file
+func ActionAlertConfigExport() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		ac, err := newAlertCustomization(ctx)
+		if err != nil {
+			return err
+		}
+
+		policy, err := ac.ExportAlertPolicy(ctx, cmd.Bool("include-secrets"))
+		if err != nil {
+			return err
+		}
+
+		data, err := yaml.Marshal(policy)
+		if err != nil {
+			return fmt.Errorf("failed to marshal alert policy: %w", err)
+		}
+
+		output := cmd.String("output")
+		if output == "" {
+			fmt.Print(string(data))
+			return nil
+		}
+		if err := os.WriteFile(output, data, 0600); err != nil {
+			return fmt.Errorf("failed to write alert policy: %w", err)
+		}
+		fmt.Printf("✅ Alert policy exported to %s (%d rules)\n", output, len(policy.Rules))
+		if !cmd.Bool("include-secrets") {
+			fmt.Println("Secrets were replaced by ${TRH_ALERT_*} placeholders; set them in the environment before applying, or leave them unset to keep the current values.")
+		}
+		return nil
+	}
+}
+
+// ActionAlertConfigApply diffs a YAML policy file against the cluster and applies it
+func ActionAlertConfigApply() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		ac, err := newAlertCustomization(ctx)
+		if err != nil {
+			return err
+		}
+
+		policy, err := thanos.LoadAlertPolicyFile(cmd.String("file"))
+		if err != nil {
+			return err
+		}
+
+		// Always validate and compute the diff first
+		diff, err := ac.ApplyAlertPolicy(ctx, policy, true)
+		if err != nil {
+			return err
+		}
+		if !diff.HasChanges() {
+			fmt.Println("✅ Alert policy is already up to date")
+			return nil
+		}
+		printAlertPolicyDiff(diff)
+
+		if cmd.Bool("dry-run") {
+			fmt.Println("🔍 Dry run: no changes applied")
+			return nil
+		}
+
+		if !cmd.Bool("yes") {
+			fmt.Print("Apply these changes? (y/N): ")
+			confirm, err := scanner.ScanBool(false)
+			if err != nil {
+				return err
+			}
+			if !confirm {
+				fmt.Println("Alert policy apply cancelled")
+				return nil
+			}
+		}
+
+		if _, err := ac.ApplyAlertPolicy(ctx, policy, false); err != nil {
+			return err
+		}
+		fmt.Println("✅ Alert policy applied")
+		return nil
+	}
+}
+
+func printAlertPolicyDiff(diff *types.AlertPolicyDiff) {
+	fmt.Println("📋 Alert policy changes:")
+	for _, name := range diff.AddedRules {
+		fmt.Printf("   + rule %s\n", name)
+	}
+	for _, name := range diff.ChangedRules {
+		fmt.Printf("   ~ rule %s\n", name)
+	}
+	for _, name := range diff.RemovedRules {
+		fmt.Printf("   - rule %s\n", name)
+	}
+	for _, name := range diff.ChangedChannels {
+		fmt.Printf("   ~ channel %s\n", name)
+	}
+	if diff.RoutesChanged {
+		fmt.Println("   ~ routing tree")
+	}
+}
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// ActionAlertSilenceAdd silences the matching alerts for a duration
+func ActionAlertSilenceAdd() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		ac, err := newAlertCustomization(ctx)
+		if err != nil {
+			return err
+		}
+
+		duration, err := time.ParseDuration(cmd.String("duration"))
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", cmd.String("duration"), err)
+		}
+
+		matchers := cmd.StringSlice("matcher")
+		id, err := ac.AddSilence(ctx, matchers, duration, cmd.String("author"), cmd.String("comment"))
+		if err != nil {
+			return err
+		}
+
+		scope := "all alerts"
+		if len(matchers) > 0 {
+			scope = strings.Join(matchers, ", ")
+		}
+		fmt.Printf("✅ Silenced %s for %s (ID: %s)\n", scope, duration, id)
+		fmt.Printf("Run 'trh-sdk alert-config silence expire %s' to lift it early.\n", id)
+		return nil
+	}
+}
+
+// ActionAlertSilenceList prints the AlertManager silences
+func ActionAlertSilenceList() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		ac, err := newAlertCustomization(ctx)
+		if err != nil {
+			return err
+		}
+
+		silences, err := ac.ListSilences(ctx, cmd.Bool("expired"))
+		if err != nil {
+			return err
+		}
+
+		if cmd.Bool("json") {
+			data, err := json.MarshalIndent(silences, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal silences: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(silences) == 0 {
+			fmt.Println("No silences found")
+			return nil
+		}
+		fmt.Println("🔕 Alert Silences")
+		fmt.Println("==================")
+		for _, s := range silences {
+			fmt.Printf("\n%s [%s]\n", s.ID, s.State)
+			fmt.Printf("   Matchers: %s\n", strings.Join(s.Matchers, ", "))
+			fmt.Printf("   Window:   %s → %s\n", s.StartsAt.Local().Format(time.RFC3339), s.EndsAt.Local().Format(time.RFC3339))
+			fmt.Printf("   Created:  %s (%s)\n", s.CreatedBy, s.Comment)
+		}
+		return nil
+	}
+}
+
+// ActionAlertSilenceExpire expires a silence by ID
+func ActionAlertSilenceExpire() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		ac, err := newAlertCustomization(ctx)
+		if err != nil {
+			return err
+		}
+
+		id := cmd.Args().First()
+		if id == "" {
+			return fmt.Errorf("usage: trh-sdk alert-config silence expire <silence-id>")
+		}
+
+		if err := ac.ExpireSilence(ctx, id); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Silence %s expired\n", id)
+		return nil
+	}
+}
@@ -9,9 +9,11 @@ import (
 
 	"github.com/urfave/cli/v3"
 
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
 	"github.com/tokamak-network/trh-sdk/pkg/logging"
 	"github.com/tokamak-network/trh-sdk/pkg/scanner"
 	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
 	"github.com/tokamak-network/trh-sdk/pkg/utils"
 )
 
@@ -30,6 +32,7 @@ type BackupManagerFlags struct {
 
 	// Restore options
 	RestoreArn string
+	BackupID   string
 
 	// Post-restore attach options
 	AttachEfs  string
@@ -37,9 +40,13 @@ type BackupManagerFlags struct {
 	AttachSTSs string
 
 	// Configure options
-	ConfigDaily string
-	ConfigKeep  string
-	ConfigReset bool
+	ConfigDaily      string
+	ConfigKeep       string
+	ConfigReset      bool
+	ConfigCopyRegion string
+	ConfigCopyKeep   string
+	ConfigRules      []string
+	ConfigGFS        bool
 }
 
 // ActionBackupManager provides backup/restore operations for EFS
@@ -61,6 +68,9 @@ func ActionBackupManager() cli.ActionFunc {
 
 		// Load settings.json (needed before logger for stack/network naming)
 		config, err := utils.ReadConfigFromJSONFile(deploymentPath)
+		if err == nil && config != nil && config.AWS == nil && thanos.HasLocalDeployment(deploymentPath) {
+			return runLocalBackupManager(ctx, deploymentPath, config, flags)
+		}
 		if err != nil || config == nil || config.AWS == nil || config.K8s == nil {
 			return fmt.Errorf("failed to read settings.json (ensure the L2 has been deployed): %w", err)
 		}
@@ -107,7 +117,7 @@ func ActionBackupManager() cli.ActionFunc {
 			return handleRestore(ctx, thanosStack, flags)
 
 		case flags.DoConfigure:
-			_, err := thanosStack.BackupConfigure(ctx, &flags.ConfigDaily, &flags.ConfigKeep, &flags.ConfigReset)
+			_, err := thanosStack.BackupConfigure(ctx, &flags.ConfigDaily, &flags.ConfigKeep, &flags.ConfigReset, &flags.ConfigCopyRegion, &flags.ConfigCopyKeep, flags.ConfigRules, &flags.ConfigGFS)
 			return err
 
 		default:
@@ -116,6 +126,39 @@ func ActionBackupManager() cli.ActionFunc {
 	}
 }
 
+// runLocalBackupManager runs snapshot, list and restore against the docker volumes of a local deployment
+func runLocalBackupManager(ctx context.Context, deploymentPath string, config *types.Config, flags *BackupManagerFlags) error {
+	logFile := fmt.Sprintf("%s/logs/backup_manager_%s_%s_%d.log", deploymentPath, config.Network, config.Stack, time.Now().Unix())
+	l, err := logging.InitLogger(logFile)
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	thanosStack, err := thanos.NewThanosStack(ctx, l, config.Network, false, deploymentPath, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create ThanosStack instance: %w", err)
+	}
+
+	switch {
+	case flags.StartBackup:
+		_, err := thanosStack.LocalBackupSnapshot(ctx)
+		return err
+
+	case flags.ListPoints:
+		_, err := thanosStack.LocalBackupList(ctx, flags.Limit)
+		return err
+
+	case flags.DoRestore:
+		if flags.BackupID != "" {
+			_, err := thanosStack.LocalBackupRestore(ctx, flags.BackupID)
+			return err
+		}
+		return thanosStack.LocalBackupRestoreInteractive(ctx)
+
+	default:
+		return errors.New("local deployments support --snapshot, --list and --restore")
+	}
+}
+
 // handleRestore manages the restore process with interactive or direct mode
 func handleRestore(ctx context.Context, thanosStack *thanos.ThanosStack, flags *BackupManagerFlags) error {
 	// Ask user if they want to attach workloads after restore
@@ -127,7 +170,7 @@ func handleRestore(ctx context.Context, thanosStack *thanos.ThanosStack, flags *
 	}
 	// If ARN is provided, use direct restore mode
 	if flags.RestoreArn != "" {
-		_, err = thanosStack.BackupRestore(ctx, flags.RestoreArn, nil, nil, nil, nil)
+		_, err = thanosStack.BackupRestore(ctx, flags.RestoreArn, &attachWorkloads, nil, nil, nil)
 		return err
 	}
 
@@ -151,6 +194,7 @@ func parseBackupManagerFlags(cmd *cli.Command) *BackupManagerFlags {
 
 		// Restore options
 		RestoreArn: cmd.String("recovery-point-arn"),
+		BackupID:   cmd.String("backup-id"),
 
 		// Post-restore attach options
 		AttachEfs:  cmd.String("efs-id"),
@@ -158,9 +202,13 @@ func parseBackupManagerFlags(cmd *cli.Command) *BackupManagerFlags {
 		AttachSTSs: cmd.String("sts"),
 
 		// Configure options
-		ConfigDaily: cmd.String("daily"),
-		ConfigKeep:  cmd.String("keep"),
-		ConfigReset: cmd.Bool("reset"),
+		ConfigDaily:      cmd.String("daily"),
+		ConfigKeep:       cmd.String("keep"),
+		ConfigReset:      cmd.Bool("reset"),
+		ConfigCopyRegion: cmd.String("copy-region"),
+		ConfigCopyKeep:   cmd.String("copy-keep"),
+		ConfigRules:      cmd.StringSlice("rule"),
+		ConfigGFS:        cmd.Bool("gfs"),
 	}
 }
 
@@ -192,3 +240,77 @@ func validateBackupManagerFlags(flags *BackupManagerFlags) error {
 
 	return nil
 }
+
+// ActionBackupManagerVerify restores a recovery point into a scratch namespace and checks that the chain boots from it
+func ActionBackupManagerVerify() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		deploymentPath, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current working directory: %w", err)
+		}
+
+		config, err := utils.ReadConfigFromJSONFile(deploymentPath)
+		if err != nil || config == nil {
+			return fmt.Errorf("failed to read settings.json (ensure the L2 has been deployed): %w", err)
+		}
+		if config.Network == constants.LocalDevnet || config.AWS == nil || config.K8s == nil {
+			return errors.New("recovery point verification is only supported for AWS deployments")
+		}
+
+		logFile := fmt.Sprintf("%s/logs/backup_verify_%s_%s_%d.log", deploymentPath, config.Network, config.Stack, time.Now().Unix())
+		l, err := logging.InitLogger(logFile)
+		if err != nil {
+			return fmt.Errorf("failed to initialize logger: %w", err)
+		}
+
+		thanosStack, err := thanos.NewThanosStack(ctx, l, config.Network, false, deploymentPath, config.AWS)
+		if err != nil {
+			return fmt.Errorf("failed to create ThanosStack instance: %w", err)
+		}
+
+		_, err = thanosStack.BackupVerify(ctx, types.BackupVerifyOptions{
+			RecoveryPointARN:  cmd.String("recovery-point"),
+			DerivationTimeout: cmd.Duration("timeout"),
+			KeepResources:     cmd.Bool("keep-resources"),
+		})
+		return err
+	}
+}
+
+// ActionBackupManagerFailover brings the chain up in the secondary region from a copied recovery point
+func ActionBackupManagerFailover() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		deploymentPath, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current working directory: %w", err)
+		}
+
+		config, err := utils.ReadConfigFromJSONFile(deploymentPath)
+		if err != nil || config == nil {
+			return fmt.Errorf("failed to read settings.json (ensure the L2 has been deployed): %w", err)
+		}
+		if config.Network == constants.LocalDevnet || config.AWS == nil || config.K8s == nil {
+			return errors.New("failover is only supported for AWS deployments")
+		}
+
+		logFile := fmt.Sprintf("%s/logs/backup_failover_%s_%s_%d.log", deploymentPath, config.Network, config.Stack, time.Now().Unix())
+		l, err := logging.InitLogger(logFile)
+		if err != nil {
+			return fmt.Errorf("failed to initialize logger: %w", err)
+		}
+
+		thanosStack, err := thanos.NewThanosStack(ctx, l, config.Network, false, deploymentPath, config.AWS)
+		if err != nil {
+			return fmt.Errorf("failed to create ThanosStack instance: %w", err)
+		}
+
+		_, err = thanosStack.BackupFailover(ctx, types.BackupFailoverOptions{
+			Region:           cmd.String("region"),
+			RecoveryPointARN: cmd.String("recovery-point"),
+			ClusterName:      cmd.String("cluster"),
+			HostedZoneID:     cmd.String("hosted-zone-id"),
+			DNSName:          cmd.String("dns-name"),
+		})
+		return err
+	}
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+func runBackupManager(t *testing.T, args ...string) error {
+	cmd := &cli.Command{
+		Name: "backup-manager",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "status"},
+			&cli.BoolFlag{Name: "snapshot"},
+			&cli.BoolFlag{Name: "list"},
+			&cli.BoolFlag{Name: "restore"},
+			&cli.BoolFlag{Name: "config"},
+			&cli.BoolFlag{Name: "attach"},
+			&cli.StringFlag{Name: "limit"},
+			&cli.StringFlag{Name: "backup-id"},
+		},
+		Action: ActionBackupManager(),
+	}
+	return cmd.Run(context.Background(), append([]string{"backup-manager"}, args...))
+}
+
+func TestActionBackupManager_LocalComposeDeployment(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	// Local docker compose deployments are saved with the testnet network and no AWS config
+	settings, err := json.Marshal(types.Config{Network: constants.Testnet, Stack: "thanos", ChainName: "local", L2ChainID: 1001})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, types.ConfigFileName), settings, 0600))
+
+	require.ErrorContains(t, runBackupManager(t, "--list"), "failed to read settings.json", "no compose file, so not a local deployment")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker-compose.local.yml"), []byte("services: {}\n"), 0644))
+	backupDir := filepath.Join(dir, thanos.LocalBackupDirName, "other-chain")
+	require.NoError(t, os.MkdirAll(backupDir, 0700))
+	manifest, err := json.Marshal(types.LocalBackupManifest{ID: "other-chain", CreatedAt: time.Now().UTC(), L2ChainID: 2002})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(backupDir, "manifest.json"), manifest, 0600))
+
+	require.NoError(t, runBackupManager(t, "--list"))
+	// The restore reaches the local backups, which refuse a backup of another chain
+	require.ErrorContains(t, runBackupManager(t, "--restore", "--backup-id", "other-chain"), "belongs to chain ID 2002")
+	require.ErrorContains(t, runBackupManager(t, "--status"), "local deployments support --snapshot, --list and --restore")
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// fundingPrivateKeyEnv holds the funding wallet key so it never shows up in the shell history
+const fundingPrivateKeyEnv = "TRH_FUNDING_PRIVATE_KEY"
+
+// ActionBalanceWatcher watches the L1 balances of the batcher, proposer and challenger until interrupted
+func ActionBalanceWatcher() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		deploymentPath, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current working directory: %w", err)
+		}
+		config, err := utils.ReadConfigFromJSONFile(deploymentPath)
+		if err != nil {
+			return fmt.Errorf("failed to read settings.json: %w", err)
+		}
+		if config == nil {
+			return fmt.Errorf("settings.json not found, run the command in the deployment directory")
+		}
+
+		l, err := logging.InitLogger(fmt.Sprintf("%s/logs/balance_watcher_%s.log", deploymentPath, config.Network))
+		if err != nil {
+			return fmt.Errorf("failed to initialize logger: %w", err)
+		}
+
+		thanosStack, err := thanos.NewThanosStack(ctx, l, config.Network, false, deploymentPath, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create ThanosStack instance: %w", err)
+		}
+
+		wei := map[string]*big.Int{}
+		for _, name := range []string{"threshold", "top-up-amount", "daily-cap"} {
+			if wei[name], err = utils.EtherToWei(cmd.String(name)); err != nil {
+				return fmt.Errorf("invalid --%s: %w", name, err)
+			}
+		}
+
+		accounts, err := thanosStack.OperatorAccounts(wei["threshold"], wei["top-up-amount"])
+		if err != nil {
+			return err
+		}
+
+		watcherConfig := thanos.BalanceWatcherConfig{
+			L1RPCURL:     cmd.String("l1-rpc-url"),
+			Accounts:     accounts,
+			DailyCap:     wei["daily-cap"],
+			PollInterval: cmd.Duration("interval"),
+			ListenAddr:   cmd.String("listen"),
+			StateFile:    filepath.Join(deploymentPath, thanos.BalanceWatcherStateFileName),
+		}
+		if watcherConfig.L1RPCURL == "" {
+			watcherConfig.L1RPCURL = config.L1RPCURL
+		}
+		if cmd.Bool("top-up") {
+			watcherConfig.FundingPrivateKey = os.Getenv(fundingPrivateKeyEnv)
+			if watcherConfig.FundingPrivateKey == "" {
+				return fmt.Errorf("--top-up requires the funding wallet private key in %s", fundingPrivateKeyEnv)
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
+		defer stop()
+		return thanos.RunBalanceWatcher(ctx, l, watcherConfig)
+	}
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// ActionCostEstimate estimates the monthly AWS cost of a planned deployment
+func ActionCostEstimate() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		deploymentPath, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current working directory: %w", err)
+		}
+
+		config, err := utils.ReadConfigFromJSONFile(deploymentPath)
+		if err != nil {
+			return fmt.Errorf("failed to read settings.json: %w", err)
+		}
+
+		network := cmd.String("network")
+		stack := constants.ThanosStack
+		if config != nil {
+			if network == "" {
+				network = config.Network
+			}
+			stack = config.Stack
+		}
+		if network == "" {
+			network = constants.Mainnet
+		}
+
+		logFile := fmt.Sprintf("%s/logs/cost_estimate_%s_%s_%d.log", deploymentPath, stack, network, time.Now().Unix())
+		l, err := logging.InitLogger(logFile)
+		if err != nil {
+			return fmt.Errorf("failed to initialize logger: %w", err)
+		}
+
+		// Cost estimation only reads local files, so no AWS login is needed
+		thanosStack, err := thanos.NewThanosStack(ctx, l, network, false, deploymentPath, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create ThanosStack instance: %w", err)
+		}
+
+		input := &types.CostEstimateInput{
+			PlanPath:       cmd.String("plan"),
+			ValuesPath:     cmd.String("values"),
+			PriceTablePath: cmd.String("price-table"),
+			Preset:         cmd.String("preset"),
+		}
+		switch compare := strings.TrimSpace(cmd.String("compare")); compare {
+		case "":
+		case "all":
+			input.ComparePresets = constants.ValidPresets
+		default:
+			for _, preset := range strings.Split(compare, ",") {
+				input.ComparePresets = append(input.ComparePresets, strings.TrimSpace(preset))
+			}
+		}
+
+		report, err := thanosStack.EstimateCost(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Bool("json") {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal cost report: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+		thanos.DisplayCostReport(report)
+		return nil
+	}
+}
+
+// ActionCostExportPrices writes the bundled price table so it can be updated locally
+func ActionCostExportPrices() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		output := cmd.String("output")
+		if output == "" {
+			deploymentPath, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current working directory: %w", err)
+			}
+			output = fmt.Sprintf("%s/%s", deploymentPath, thanos.CostPriceTableFileName)
+		}
+		if err := thanos.ExportPriceTable(output); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Price table written to %s\n", output)
+		fmt.Println("Edit the prices and run 'trh-sdk cost estimate' from the deployment directory to use them.")
+		return nil
+	}
+}
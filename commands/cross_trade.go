@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
+	"github.com/tokamak-network/trh-sdk/pkg/scanner"
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// ActionCrossTradeStatus reports the cross-trade requests by status and token, and fails when requests are stuck
+func ActionCrossTradeStatus() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		thanosStack, err := crossTradeStack(ctx, cmd.Bool("json"))
+		if err != nil {
+			return err
+		}
+
+		report, err := thanosStack.CrossTradeStatus(ctx, &types.CrossTradeStatusInput{
+			Mode:        constants.CrossTradeDeployMode(strings.TrimSpace(strings.ToLower(cmd.String("mode")))),
+			FromBlock:   cmd.Uint("from-block"),
+			ExpireAfter: cmd.Duration("expire-after"),
+			StuckAfter:  cmd.Duration("stuck-after"),
+		})
+		if err != nil {
+			return err
+		}
+
+		if cmd.Bool("json") {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal cross-trade report: %w", err)
+			}
+			fmt.Println(string(data))
+		} else {
+			printCrossTradeStatus(report)
+		}
+
+		if report.Stuck > 0 {
+			return cli.Exit("", 1)
+		}
+		return nil
+	}
+}
+
+// crossTradeStack creates the stack of the deployment in the current directory
+func crossTradeStack(ctx context.Context, quiet bool) (*thanos.ThanosStack, error) {
+	deploymentPath, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	config, err := utils.ReadConfigFromJSONFile(deploymentPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings.json: %w", err)
+	}
+	if config == nil {
+		return nil, fmt.Errorf("settings.json not found in %s", deploymentPath)
+	}
+
+	logFile := fmt.Sprintf("%s/logs/cross_trade_%s.log", deploymentPath, config.Network)
+	initLogger := logging.InitLogger
+	if quiet {
+		initLogger = logging.InitFileLogger
+	}
+	l, err := initLogger(logFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	thanosStack, err := thanos.NewThanosStack(ctx, l, config.Network, false, deploymentPath, config.AWS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ThanosStack instance: %w", err)
+	}
+	return thanosStack, nil
+}
+
+func printCrossTradeStatus(report *types.CrossTradeStatusReport) {
+	fmt.Println("🔁 Cross-Trade Requests")
+	fmt.Println("======================")
+	if len(report.Tokens) == 0 {
+		fmt.Println("No requests")
+		return
+	}
+
+	fmt.Printf("%-9s %-14s %-10s", "MODE", "CHAIN", "TOKEN")
+	for _, status := range types.CrossTradeRequestStatuses {
+		fmt.Printf(" %20s", strings.ToUpper(string(status)))
+	}
+	fmt.Println()
+	for _, token := range report.Tokens {
+		symbol := token.Symbol
+		if symbol == "" {
+			symbol = token.L1Token[:10]
+		}
+		fmt.Printf("%-9s %-14d %-10s", token.Mode, token.SourceChainID, symbol)
+		for _, status := range types.CrossTradeRequestStatuses {
+			cell := "-"
+			if count := token.Counts[status]; count > 0 {
+				cell = fmt.Sprintf("%d (%s)", count, token.Amounts[status])
+			}
+			fmt.Printf(" %20s", cell)
+		}
+		fmt.Println()
+	}
+
+	if report.Stuck == 0 {
+		fmt.Println("\n✅ No stuck requests")
+		return
+	}
+	fmt.Printf("\n❌ %d stuck requests, provided or cancelled on L1 but not completed on L2:\n", report.Stuck)
+	for _, request := range report.Requests {
+		if request.Status != types.CrossTradeRequestStuck {
+			continue
+		}
+		fmt.Printf("  chain %d sale #%s %s\n", request.SourceChainID, request.SaleCount, request.Hash)
+		fmt.Printf("    requested %s in %s, L1 %s in %s\n", request.RequestedAt, request.RequestTx, request.L1At, request.L1Tx)
+	}
+}
+
+// ActionCrossTradeTokensDiff compares the token registrations in settings.json with the cross-trade contracts
+// and fails when registrations are missing or incorrect
+func ActionCrossTradeTokensDiff() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		thanosStack, err := crossTradeStack(ctx, cmd.Bool("json"))
+		if err != nil {
+			return err
+		}
+
+		report, err := thanosStack.CrossTradeTokensDiff(ctx, crossTradeTokensInput(cmd))
+		if err != nil {
+			return err
+		}
+
+		if cmd.Bool("json") {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal cross-trade tokens report: %w", err)
+			}
+			fmt.Println(string(data))
+		} else {
+			printCrossTradeTokensDiff(report)
+		}
+
+		if report.Missing+report.Incorrect > 0 {
+			return cli.Exit("", 1)
+		}
+		return nil
+	}
+}
+
+// ActionCrossTradeTokensSync submits the missing and incorrect token registrations of settings.json
+func ActionCrossTradeTokensSync() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		thanosStack, err := crossTradeStack(ctx, false)
+		if err != nil {
+			return err
+		}
+
+		// Always plan first, the registrations are only sent once confirmed
+		input := crossTradeTokensInput(cmd)
+		input.DryRun = true
+		plan, err := thanosStack.CrossTradeTokensSync(ctx, input)
+		if err != nil {
+			return err
+		}
+		printCrossTradeTokensDiff(plan)
+		if len(plan.Transactions) == 0 {
+			fmt.Println("✅ Token registrations are already up to date")
+			return nil
+		}
+		fmt.Println("\n📋 Registrations to submit:")
+		for _, tx := range plan.Transactions {
+			fmt.Printf("  %-13s %s\n", tx.Method, formatCrossTradeTokenPair(tx.Mode, tx.Pair))
+		}
+
+		if cmd.Bool("dry-run") {
+			fmt.Println("🔍 Dry run: no registrations submitted")
+			return nil
+		}
+
+		if !cmd.Bool("yes") {
+			fmt.Print("Submit these registrations? (y/N): ")
+			confirm, err := scanner.ScanBool(false)
+			if err != nil {
+				return err
+			}
+			if !confirm {
+				fmt.Println("Token registration sync cancelled")
+				return nil
+			}
+		}
+
+		input.DryRun = false
+		report, err := thanosStack.CrossTradeTokensSync(ctx, input)
+		if report != nil {
+			for _, tx := range report.Transactions {
+				fmt.Printf("✅ %s %s: %s\n", tx.Method, formatCrossTradeTokenPair(tx.Mode, tx.Pair), tx.TxHash)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println("✅ Token registrations synced")
+		return nil
+	}
+}
+
+func crossTradeTokensInput(cmd *cli.Command) *types.CrossTradeTokensInput {
+	return &types.CrossTradeTokensInput{
+		Mode:      constants.CrossTradeDeployMode(strings.TrimSpace(strings.ToLower(cmd.String("mode")))),
+		FromBlock: cmd.Uint("from-block"),
+	}
+}
+
+var crossTradeTokenStateIcons = map[types.CrossTradeTokenState]string{
+	types.CrossTradeTokenRegistered: "✅",
+	types.CrossTradeTokenMissing:    "❌",
+	types.CrossTradeTokenIncorrect:  "❌",
+	types.CrossTradeTokenUnexpected: "⚠️ ",
+}
+
+func printCrossTradeTokensDiff(report *types.CrossTradeTokensReport) {
+	fmt.Println("🪙 Cross-Trade Token Registrations")
+	fmt.Println("=================================")
+	if len(report.Diffs) == 0 {
+		fmt.Println("No tokens in settings.json or on the contracts")
+		return
+	}
+	for _, diff := range report.Diffs {
+		pair := diff.Expected
+		if pair == nil {
+			pair = diff.OnChain
+		}
+		fmt.Printf("%s %-10s %s\n", crossTradeTokenStateIcons[diff.State], diff.State, formatCrossTradeTokenPair(diff.Mode, *pair))
+		if diff.State == types.CrossTradeTokenIncorrect {
+			fmt.Printf("   registered as %s\n", formatCrossTradeTokenPair(diff.Mode, *diff.OnChain))
+		}
+	}
+	fmt.Printf("\n%d missing, %d incorrect, %d not in settings.json\n", report.Missing, report.Incorrect, report.Unexpected)
+}
+
+func formatCrossTradeTokenPair(mode constants.CrossTradeDeployMode, pair types.CrossTradeTokenPair) string {
+	name := pair.TokenName
+	if name == "" {
+		name = pair.L1Token
+	}
+	if mode == constants.CrossTradeDeployModeL2ToL2 {
+		return fmt.Sprintf("%s: chain %d %s -> chain %d %s (L1 %s)", name, pair.ChainID, pair.L2Token, pair.DestinationChainID, pair.DestinationToken, pair.L1Token)
+	}
+	return fmt.Sprintf("%s: chain %d %s (L1 %s)", name, pair.ChainID, pair.L2Token, pair.L1Token)
+}
@@ -5,9 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
 	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
 	"github.com/urfave/cli/v3"
 )
 
@@ -63,3 +70,70 @@ func ActionDisplayDRBLeaderInfo() cli.ActionFunc {
 		return nil
 	}
 }
+
+// ActionDRBExporter exports the DRB rounds as Prometheus metrics until interrupted. The contract is the one
+// in drb-leader-info.json for a DRB plugin deployment, and the CommitReveal2L2 predeploy otherwise.
+func ActionDRBExporter() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		deploymentPath, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+
+		exporterConfig := thanos.DRBExporterConfig{
+			RPCURL:       cmd.String("rpc-url"),
+			PollInterval: cmd.Duration("interval"),
+			ListenAddr:   cmd.String("listen"),
+		}
+		contract := cmd.String("contract")
+		logName := "drb_exporter"
+
+		infoJSON, err := os.ReadFile(filepath.Join(deploymentPath, "drb-leader-info.json"))
+		switch {
+		case err == nil:
+			var leaderInfo types.DRBLeaderInfo
+			if err := json.Unmarshal(infoJSON, &leaderInfo); err != nil {
+				return fmt.Errorf("failed to parse leader info file: %w", err)
+			}
+			if contract == "" {
+				contract = leaderInfo.CommitReveal2L2Address
+			}
+			if exporterConfig.RPCURL == "" {
+				exporterConfig.RPCURL = leaderInfo.RPCURL
+			}
+		case os.IsNotExist(err):
+			config, err := utils.ReadConfigFromJSONFile(deploymentPath)
+			if err != nil {
+				return fmt.Errorf("failed to read settings.json: %w", err)
+			}
+			if config == nil {
+				return fmt.Errorf("neither drb-leader-info.json nor settings.json found, run the command in the deployment directory")
+			}
+			if contract == "" {
+				contract = constants.Commit2RevealDRB
+			}
+			if exporterConfig.RPCURL == "" {
+				exporterConfig.RPCURL = config.L2RpcUrl
+			}
+			logName = fmt.Sprintf("drb_exporter_%s", config.Network)
+		default:
+			return fmt.Errorf("failed to read leader info file: %w", err)
+		}
+		if !common.IsHexAddress(contract) {
+			return fmt.Errorf("invalid CommitReveal2L2 address %q", contract)
+		}
+		if exporterConfig.RPCURL == "" {
+			return fmt.Errorf("no RPC URL found, pass --rpc-url")
+		}
+		exporterConfig.Contract = common.HexToAddress(contract)
+
+		l, err := logging.InitLogger(fmt.Sprintf("%s/logs/%s.log", deploymentPath, logName))
+		if err != nil {
+			return fmt.Errorf("failed to initialize logger: %w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
+		defer stop()
+		return thanos.RunDRBExporter(ctx, l, exporterConfig)
+	}
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+var healthStatusIcons = map[types.HealthStatus]string{
+	types.HealthOK:       "✅",
+	types.HealthWarning:  "⚠️ ",
+	types.HealthCritical: "❌",
+	types.HealthUnknown:  "❔",
+}
+
+// ActionHealth checks the sync status of the rollup and exits with 0 (ok), 1 (warning), 2 (critical) or 3 (unknown)
+func ActionHealth() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		deploymentPath, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current working directory: %w", err)
+		}
+		config, err := utils.ReadConfigFromJSONFile(deploymentPath)
+		if err != nil {
+			return fmt.Errorf("failed to read settings.json: %w", err)
+		}
+
+		network := constants.LocalDevnet
+		var awsConfig *types.AWSConfig
+		if config != nil {
+			network = config.Network
+			// The op-node RPC of AWS deployments is reached through the cluster
+			if cmd.String("op-node-url") == "" {
+				awsConfig = config.AWS
+			}
+		}
+
+		// One appended log file, since the command is meant to run from cron
+		logFile := fmt.Sprintf("%s/logs/health_%s.log", deploymentPath, network)
+		initLogger := logging.InitLogger
+		if cmd.Bool("json") {
+			initLogger = logging.InitFileLogger
+		}
+		l, err := initLogger(logFile)
+		if err != nil {
+			return fmt.Errorf("failed to initialize logger: %w", err)
+		}
+
+		thanosStack, err := thanos.NewThanosStack(ctx, l, network, false, deploymentPath, awsConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create ThanosStack instance: %w", err)
+		}
+
+		report, err := thanosStack.CheckHealth(ctx, &types.HealthCheckInput{
+			L1RPCURL:     cmd.String("l1-rpc-url"),
+			L2RPCURL:     cmd.String("l2-rpc-url"),
+			OpNodeRPCURL: cmd.String("op-node-url"),
+			Thresholds: types.HealthThresholds{
+				UnsafeHeadAge:    cmd.Duration("max-unsafe-age"),
+				SafeHeadLag:      cmd.Duration("max-safe-lag"),
+				FinalizedHeadLag: cmd.Duration("max-finalized-lag"),
+				BatchSubmission:  cmd.Duration("max-batch-age"),
+				OutputSubmission: cmd.Duration("max-output-age"),
+				L1HeadDrift:      cmd.Uint("max-l1-drift"),
+				L2EngineDrift:    cmd.Uint("max-l2-drift"),
+				MinPeers:         cmd.Uint("min-peers"),
+			},
+		})
+		if err != nil {
+			return cli.Exit(err.Error(), types.HealthUnknown.ExitCode())
+		}
+
+		if cmd.Bool("json") {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal health report: %w", err)
+			}
+			fmt.Println(string(data))
+		} else {
+			printHealthReport(report)
+		}
+
+		if code := report.Status.ExitCode(); code != 0 {
+			return cli.Exit("", code)
+		}
+		return nil
+	}
+}
+
+func printHealthReport(report *types.HealthReport) {
+	fmt.Println("🩺 Rollup Health")
+	fmt.Println("================")
+	if s := report.SyncStatus; s != nil {
+		fmt.Printf("L2 heads: unsafe #%d, safe #%d, finalized #%d\n", s.UnsafeL2.Number, s.SafeL2.Number, s.FinalizedL2.Number)
+		fmt.Printf("L1 heads: head #%d, derived up to #%d, finalized #%d\n\n", s.HeadL1.Number, s.CurrentL1.Number, s.FinalizedL1.Number)
+	}
+	for _, check := range report.Checks {
+		line := fmt.Sprintf("%s %-20s %s", healthStatusIcons[check.Status], check.Name, check.Value)
+		if check.Threshold != "" {
+			line += fmt.Sprintf(" (threshold %s)", check.Threshold)
+		}
+		fmt.Println(line)
+		if check.Message != "" {
+			fmt.Printf("   %s\n", check.Message)
+		}
+	}
+	fmt.Printf("\nStatus: %s %s\n", healthStatusIcons[report.Status], report.Status)
+}
@@ -4,68 +4,79 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
+
 	"github.com/tokamak-network/trh-sdk/pkg/constants"
 	"github.com/tokamak-network/trh-sdk/pkg/logging"
 	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
 	"github.com/tokamak-network/trh-sdk/pkg/types"
 	"github.com/tokamak-network/trh-sdk/pkg/utils"
-	"github.com/urfave/cli/v3"
 )
 
+// ActionShowLogs merges the logs of the selected components of a local or AWS deployment
 func ActionShowLogs() cli.ActionFunc {
 	return func(ctx context.Context, cmd *cli.Command) error {
-		var network, stack string
-
-		var config *types.Config
-
-		var awsConfig *types.AWSConfig
-
 		deploymentPath, err := os.Getwd()
 		if err != nil {
 			return err
 		}
-		config, err = utils.ReadConfigFromJSONFile(deploymentPath)
+		config, err := utils.ReadConfigFromJSONFile(deploymentPath)
 		if err != nil {
 			fmt.Println("Error reading settings.json")
 			return err
 		}
 
-		if config == nil {
-			network = constants.LocalDevnet
-			stack = constants.ThanosStack
-		} else {
+		network := constants.LocalDevnet
+		stack := constants.ThanosStack
+		var awsConfig *types.AWSConfig
+		if config != nil {
 			network = config.Network
 			stack = config.Stack
 			awsConfig = config.AWS
 		}
 
-		if awsConfig == nil {
-			awsConfig, err = thanos.InputAWSLogin()
-			if err != nil {
-				fmt.Printf("Failed to login AWS: %s \n", err)
-				return err
-			}
-		}
-
-		component := cmd.String("component")
-		isTroubleshoot := cmd.Bool("troubleshoot")
-
+		// The log file only, so the merged stream is the only output
 		fileName := fmt.Sprintf("%s/logs/show_logs_%s_%s_%d.log", deploymentPath, stack, network, time.Now().Unix())
-		l, err := logging.InitLogger(fileName)
+		l, err := logging.InitFileLogger(fileName)
 		if err != nil {
 			return fmt.Errorf("failed to initialize logger: %w", err)
 		}
 
+		opts := &types.LogStreamOptions{
+			Components: cmd.StringSlice("component"),
+			Follow:     cmd.Bool("follow"),
+			Since:      cmd.Duration("since"),
+			Tail:       int(cmd.Int("tail")),
+			Grep:       cmd.String("grep"),
+			JSON:       cmd.Bool("json"),
+		}
+		if cmd.Bool("troubleshoot") {
+			if opts.Grep != "" {
+				return fmt.Errorf("--troubleshoot and --grep cannot be combined")
+			}
+			opts.Grep = thanos.TroubleshootLogPattern
+		}
+		// --since shows the whole window unless --tail is given
+		if opts.Since > 0 && !cmd.IsSet("tail") {
+			opts.Tail = -1
+		}
+		opts.Color = !opts.JSON && os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd()))
+
 		switch stack {
 		case constants.ThanosStack:
-			thanosStack, err := thanos.NewThanosStack(ctx, l, network, true, deploymentPath, awsConfig)
+			thanosStack, err := thanos.NewThanosStack(ctx, l, network, false, deploymentPath, awsConfig)
 			if err != nil {
 				fmt.Println("Failed to initialize thanos stack", "err", err)
 				return err
 			}
-			return thanosStack.ShowLogs(ctx, config, component, isTroubleshoot)
+			ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			return thanosStack.StreamLogs(ctx, opts, os.Stdout)
 		}
 
 		return nil
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
+	"github.com/urfave/cli/v3"
+)
+
+// newGrafanaClient returns the Grafana client for the deployment in the current directory.
+// The --url, --user and --password flags of the dashboards command override the resolved endpoint.
+func newGrafanaClient(ctx context.Context, cmd *cli.Command) (*thanos.GrafanaClient, error) {
+	deploymentPath, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	return thanos.ResolveGrafanaClient(ctx, deploymentPath, cmd.String("url"), cmd.String("user"), cmd.String("password"))
+}
+
+// ActionMonitoringDashboardsList prints the dashboards in Grafana and the built-in dashboards
+func ActionMonitoringDashboardsList() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		if cmd.Bool("builtin") {
+			for _, name := range thanos.BuiltinGrafanaDashboards() {
+				fmt.Println(name)
+			}
+			return nil
+		}
+
+		client, err := newGrafanaClient(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		dashboards, err := client.ListDashboards(ctx)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Bool("json") {
+			data, err := json.MarshalIndent(dashboards, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal dashboards: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(dashboards) == 0 {
+			fmt.Println("No dashboards found")
+			return nil
+		}
+		fmt.Println("📊 Grafana Dashboards")
+		fmt.Println("=====================")
+		folder := ""
+		for _, d := range dashboards {
+			if d.Folder != folder {
+				folder = d.Folder
+				fmt.Printf("\n📁 %s\n", folder)
+			}
+			fmt.Printf("   %-32s %s\n", d.UID, d.Title)
+			fmt.Printf("   %-32s %s\n", "", d.URL)
+		}
+		return nil
+	}
+}
+
+// ActionMonitoringDashboardsExport writes dashboards as JSON files so they can be versioned
+func ActionMonitoringDashboardsExport() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		uid := cmd.Args().First()
+		if uid == "" && !cmd.Bool("all") {
+			return fmt.Errorf("usage: trh-sdk monitoring dashboards export <uid> [-o file] or --all [--dir dir]")
+		}
+
+		client, err := newGrafanaClient(ctx, cmd)
+		if err != nil {
+			return err
+		}
+
+		if !cmd.Bool("all") {
+			data, err := client.ExportDashboard(ctx, uid)
+			if err != nil {
+				return err
+			}
+			output := cmd.String("output")
+			if output == "" {
+				fmt.Println(string(data))
+				return nil
+			}
+			if err := os.WriteFile(output, append(data, '\n'), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+			fmt.Printf("✅ Exported %s to %s\n", uid, output)
+			return nil
+		}
+
+		dir := cmd.String("dir")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		dashboards, err := client.ListDashboards(ctx)
+		if err != nil {
+			return err
+		}
+		for _, d := range dashboards {
+			data, err := client.ExportDashboard(ctx, d.UID)
+			if err != nil {
+				return fmt.Errorf("failed to export %s: %w", d.UID, err)
+			}
+			path := filepath.Join(dir, dashboardFileName(d.UID))
+			if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			fmt.Printf("   %s → %s\n", d.Title, path)
+		}
+		fmt.Printf("✅ Exported %d dashboards to %s\n", len(dashboards), dir)
+		return nil
+	}
+}
+
+// ActionMonitoringDashboardsImport creates or updates dashboards from JSON files or the built-in set
+func ActionMonitoringDashboardsImport() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		models := map[string][]byte{}
+		for _, file := range cmd.StringSlice("file") {
+			paths := []string{file}
+			if info, err := os.Stat(file); err == nil && info.IsDir() {
+				paths, err = filepath.Glob(filepath.Join(file, "*.json"))
+				if err != nil {
+					return fmt.Errorf("failed to list %s: %w", file, err)
+				}
+			}
+			for _, path := range paths {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", path, err)
+				}
+				models[path] = data
+			}
+		}
+		for _, name := range cmd.StringSlice("builtin") {
+			names := []string{name}
+			if name == "all" {
+				names = thanos.BuiltinGrafanaDashboards()
+			}
+			for _, builtin := range names {
+				data, err := thanos.BuiltinGrafanaDashboard(builtin)
+				if err != nil {
+					return err
+				}
+				models["builtin:"+builtin] = data
+			}
+		}
+		if len(models) == 0 {
+			return fmt.Errorf("nothing to import: pass --file or --builtin")
+		}
+
+		client, err := newGrafanaClient(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		for source, data := range models {
+			dashboard, err := client.ImportDashboard(ctx, data, cmd.String("folder"), cmd.Bool("overwrite"))
+			if err != nil {
+				if strings.Contains(err.Error(), "HTTP 412") {
+					return fmt.Errorf("failed to import %s: a dashboard with the same uid or title exists, use --overwrite to replace it", source)
+				}
+				return fmt.Errorf("failed to import %s: %w", source, err)
+			}
+			fmt.Printf("✅ Imported %s: %s\n", dashboard.Title, dashboard.URL)
+		}
+		return nil
+	}
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func dashboardFileName(uid string) string {
+	return unsafeFileNameChars.ReplaceAllString(uid, "_") + ".json"
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/tokamak-network/trh-sdk/pkg/logging"
+	"github.com/tokamak-network/trh-sdk/pkg/scanner"
 	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
 	"github.com/tokamak-network/trh-sdk/pkg/types"
 	"github.com/tokamak-network/trh-sdk/pkg/utils"
@@ -23,6 +27,9 @@ type ShutdownContext struct {
 	SDKPath        string
 	Logger         *zap.SugaredLogger
 	State          *types.ShutdownState
+
+	// dryRunStorageAddress is the GenFWStorage simulated by a dry-run activate
+	dryRunStorageAddress string
 }
 
 // NewShutdownContext creates a new shutdown context by reading settings.json
@@ -59,11 +66,29 @@ func NewShutdownContext(ctx context.Context) (*ShutdownContext, error) {
 		return nil, fmt.Errorf("failed to initialize logger: %w", err)
 	}
 
-	// Load or create shutdown state
-	state, err := types.LoadShutdownState()
+	// Load or create the shutdown state of the chain
+	state, err := types.LoadShutdownState(deploymentPath, config.L2ChainID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load shutdown state: %w", err)
 	}
+	if state.UnattributedLegacy != "" {
+		fmt.Printf("⚠️  The legacy shutdown state %s has no L2 chain ID.\n", state.UnattributedLegacy)
+		fmt.Printf("Import its progress into the shutdown state of L2 chain %d? (y/N): ", config.L2ChainID)
+		confirm, err := scanner.ScanBool(false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		if confirm {
+			if err := state.ImportUnattributedLegacy(); err != nil {
+				return nil, fmt.Errorf("failed to import the legacy shutdown state: %w", err)
+			}
+		} else {
+			logger.Warnf("Ignoring the legacy shutdown state %s", state.UnattributedLegacy)
+		}
+	}
+	if state.ImportedFrom != "" {
+		logger.Infof("Imported the shutdown progress of %s", state.ImportedFrom)
+	}
 
 	// Update state with current context
 	state.ChainID = config.L1ChainID
@@ -71,6 +96,7 @@ func NewShutdownContext(ctx context.Context) (*ShutdownContext, error) {
 	// Always derive ThanosRoot from SDKPath to avoid dependency on settings.json
 	state.ThanosRoot = filepath.Dir(filepath.Dir(sdkPath))
 	state.DeploymentsPath = fmt.Sprintf("%d-deploy.json", config.L1ChainID)
+	state.DataDir = filepath.Join(deploymentPath, "tokamak-thanos", "packages", "tokamak", "contracts-bedrock", "data")
 
 	sc := &ShutdownContext{
 		DeploymentPath: deploymentPath,
@@ -231,48 +257,144 @@ func ActionShutdown() cli.ActionFunc {
 	}
 }
 
-// ActionShutdownBlock blocks deposits and withdrawals
-func ActionShutdownBlock() cli.ActionFunc {
-	return func(ctx context.Context, cmd *cli.Command) error {
-		fmt.Println("🚫 Blocking L1 Deposits and Withdrawals...")
-		sc, err := NewShutdownContext(ctx)
+// newThanosStack creates the stack the shutdown steps run on
+func (sc *ShutdownContext) newThanosStack(ctx context.Context) (*thanos.ThanosStack, error) {
+	client, err := thanos.NewThanosStack(ctx, sc.Logger, sc.Config.Network, false, sc.DeploymentPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create thanos stack: %w", err)
+	}
+	return client, nil
+}
+
+// assetsPath returns the assets snapshot the activate and withdraw steps run with
+func (sc *ShutdownContext) assetsPath(input string) string {
+	switch {
+	case input != "":
+		return input
+	case sc.Config.Shutdown != nil && sc.Config.Shutdown.AssetsDataPath != "":
+		return sc.Config.Shutdown.AssetsDataPath
+	case sc.State.SnapshotPath != "":
+		return sc.State.SnapshotPath
+	}
+	return fmt.Sprintf("data/generate-assets-%d.json", sc.Config.L2ChainID)
+}
+
+// confirmInterruptedStep shows the transactions an interrupted run of step already broadcast and asks whether
+// to run it again. Declining offers to mark the step completed with them instead; rerun is false then.
+func (sc *ShutdownContext) confirmInterruptedStep(step string, client *thanos.ThanosStack, yes bool) (rerun bool, err error) {
+	name := strings.ToUpper(step)
+	prev := sc.State.Step(step)
+	txHashes := prev.TxHashes
+	if len(txHashes) == 0 {
+		startedAt, err := time.Parse(time.RFC3339, prev.StartedAt)
 		if err != nil {
-			return err
+			return false, fmt.Errorf("step [%s] was interrupted at an unknown time, check its transactions on L1 before running it again", name)
 		}
-		client, err := thanos.NewThanosStack(ctx, sc.Logger, sc.Config.Network, false, sc.DeploymentPath, nil)
+		txHashes, err = client.ShutdownTxHashes(step, startedAt)
 		if err != nil {
-			return fmt.Errorf("failed to create thanos stack: %w", err)
+			return false, fmt.Errorf("step [%s] was interrupted and its transactions could not be read: %w", name, err)
 		}
-		return client.ShutdownBlock(ctx, cmd.Bool("dry-run"))
 	}
+	if len(txHashes) == 0 {
+		sc.Logger.Warnf("Step [%s] was interrupted before broadcasting any transaction, running it again", name)
+		return true, nil
+	}
+
+	fmt.Printf("⚠️  Step [%s] was interrupted after broadcasting %d transaction(s):\n", name, len(txHashes))
+	for _, hash := range txHashes {
+		fmt.Printf("   %s\n", hash)
+	}
+	if yes {
+		return true, nil
+	}
+	fmt.Print("Check them on L1 first. Run the step again and broadcast its transactions again? (y/N): ")
+	rerun, err = scanner.ScanBool(false)
+	if err != nil {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	if rerun {
+		return true, nil
+	}
+	fmt.Print("Mark the step as completed with these transactions instead? (y/N): ")
+	complete, err := scanner.ScanBool(false)
+	if err != nil {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	if !complete {
+		return false, fmt.Errorf("step [%s] was not run again", name)
+	}
+	sc.State.CompleteStep(step, txHashes)
+	if err := sc.State.Save(); err != nil {
+		return false, fmt.Errorf("failed to save shutdown state: %w", err)
+	}
+	return false, nil
 }
 
-// ActionShutdownFetch collects L2 asset information
-func ActionShutdownFetch() cli.ActionFunc {
-	return func(ctx context.Context, cmd *cli.Command) error {
-		fmt.Println("🔍 Collecting L2 Asset Information...")
-		sc, err := NewShutdownContext(ctx)
-		if err != nil {
+// runStep runs a shutdown step and records its status, and the transactions it sent, in the shutdown state.
+// A step interrupted after broadcasting is only run again once confirmed, unless yes is set.
+func (sc *ShutdownContext) runStep(step string, dryRun, yes bool, client *thanos.ThanosStack, run func() error) error {
+	if !dryRun && step != types.ShutdownStepFetch && sc.State.Step(step).Interrupted() {
+		rerun, err := sc.confirmInterruptedStep(step, client, yes)
+		if err != nil || !rerun {
 			return err
 		}
-		client, err := thanos.NewThanosStack(ctx, sc.Logger, sc.Config.Network, false, sc.DeploymentPath, nil)
-		if err != nil {
-			return fmt.Errorf("failed to create thanos stack: %w", err)
-		}
-		return client.ShutdownFetch(ctx, cmd.Bool("dry-run"))
 	}
-}
 
-// ActionShutdownGen generates force withdrawal assets snapshot
-func ActionShutdownGen() cli.ActionFunc {
-	return func(ctx context.Context, cmd *cli.Command) error {
-		fmt.Println("🚀 Generating L2 Asset Snapshot...")
+	startedAt := time.Now()
+	sc.State.StartStep(step, dryRun)
+	if err := sc.State.Save(); err != nil {
+		return fmt.Errorf("failed to save shutdown state: %w", err)
+	}
 
-		sc, err := NewShutdownContext(ctx)
+	if err := run(); err != nil {
+		var txHashes []string
+		if !dryRun {
+			txHashes, _ = client.ShutdownTxHashes(step, startedAt)
+		}
+		sc.State.FailStep(step, err, txHashes)
+		if saveErr := sc.State.Save(); saveErr != nil {
+			sc.Logger.Warnf("Failed to save shutdown state: %v", saveErr)
+		}
+		return err
+	}
+
+	var txHashes []string
+	if !dryRun {
+		hashes, err := client.ShutdownTxHashes(step, startedAt)
 		if err != nil {
-			return err
+			sc.Logger.Warnf("Failed to read the transactions of step %s: %v", step, err)
 		}
+		txHashes = hashes
+	}
+	sc.State.CompleteStep(step, txHashes)
+	if err := sc.State.Save(); err != nil {
+		return fmt.Errorf("failed to save shutdown state: %w", err)
+	}
+	return nil
+}
 
+// runShutdownStep runs one shutdown step with the flags of cmd
+func (sc *ShutdownContext) runShutdownStep(ctx context.Context, cmd *cli.Command, client *thanos.ThanosStack, step string) error {
+	dryRun := cmd.Bool("dry-run")
+	yes := cmd.Bool("yes")
+
+	switch step {
+	case types.ShutdownStepBlock:
+		return sc.runStep(step, dryRun, yes, client, func() error {
+			return client.ShutdownBlock(ctx, dryRun)
+		})
+
+	case types.ShutdownStepFetch:
+		// Fetch only reads the chains, a dry run fetches as well
+		return sc.runStep(step, false, yes, client, func() error {
+			return client.ShutdownFetch(ctx, types.ShutdownFetchOptions{
+				Workers:    int(cmd.Int("workers")),
+				BlockRange: cmd.Uint("block-range"),
+				Restart:    cmd.Bool("restart"),
+			})
+		})
+
+	case types.ShutdownStepGen:
 		l2StartBlock := cmd.String("l2-start-block")
 		if l2StartBlock == "" {
 			l2StartBlock = "0"
@@ -281,145 +403,363 @@ func ActionShutdownGen() cli.ActionFunc {
 		if l2EndBlock == "" {
 			l2EndBlock = "latest"
 		}
-
-		client, err := thanos.NewThanosStack(ctx, sc.Logger, sc.Config.Network, false, sc.DeploymentPath, nil)
-		if err != nil {
-			return fmt.Errorf("failed to create thanos stack: %w", err)
-		}
-
 		l2StartBlockInt, _ := strconv.ParseUint(l2StartBlock, 10, 64)
 		input := types.ShutdownConfig{
 			L2StartBlock: l2StartBlockInt,
 			L2EndBlock:   l2EndBlock,
 		}
 
-		return client.ShutdownGen(ctx, input, cmd.Bool("dry-run"))
+		return sc.runStep(step, dryRun, yes, client, func() error {
+			if err := client.ShutdownGen(ctx, input, dryRun); err != nil {
+				return err
+			}
+			assetsPath := fmt.Sprintf("data/generate-assets-%d.json", sc.Config.L2ChainID)
+			hash, err := client.ShutdownSnapshotHash(assetsPath)
+			if err != nil {
+				return fmt.Errorf("failed to read the assets snapshot %s: %w", assetsPath, err)
+			}
+			sc.State.SetSnapshot(assetsPath, hash)
+
+			// Persistence: Save the path to settings.json
+			if sc.Config.Shutdown == nil {
+				sc.Config.Shutdown = &types.ShutdownConfig{}
+			}
+			sc.Config.Shutdown.AssetsDataPath = assetsPath
+			return sc.Config.WriteToJSONFile(sc.DeploymentPath)
+		})
+
+	case types.ShutdownStepActivate:
+		assetsPath := sc.assetsPath(cmd.String("input"))
+		return sc.runStep(step, dryRun, yes, client, func() error {
+			hash, err := client.ShutdownSnapshotHash(assetsPath)
+			if err != nil {
+				return fmt.Errorf("failed to read the assets snapshot %s: %w", assetsPath, err)
+			}
+			sc.State.SetStepInput(step, hash)
+			storageAddr, err := client.ShutdownActivate(ctx, assetsPath, dryRun)
+			if err != nil {
+				return err
+			}
+			// A simulated storage is only used by the withdraw of the same dry run
+			if dryRun {
+				sc.dryRunStorageAddress = storageAddr
+			} else if storageAddr != "" {
+				sc.State.StorageAddress = storageAddr
+			}
+			return nil
+		})
+
+	case types.ShutdownStepWithdraw:
+		assetsPath := sc.assetsPath(cmd.String("input"))
+		storageAddr := cmd.String("storage-address")
+		if storageAddr == "" && dryRun {
+			storageAddr = sc.dryRunStorageAddress
+		}
+		if storageAddr == "" {
+			storageAddr = sc.State.StorageAddress
+		}
+		return sc.runStep(step, dryRun, yes, client, func() error {
+			hash, err := client.ShutdownSnapshotHash(assetsPath)
+			if err != nil {
+				return fmt.Errorf("failed to read the assets snapshot %s: %w", assetsPath, err)
+			}
+			if activated := sc.State.Step(types.ShutdownStepActivate).InputHash; activated != "" && activated != hash {
+				return fmt.Errorf("assets snapshot %s changed since it was activated, run 'trh-sdk shutdown activate' again", assetsPath)
+			}
+			sc.State.SetStepInput(step, hash)
+			return client.ShutdownWithdraw(ctx, assetsPath, dryRun, storageAddr)
+		})
 	}
+	return fmt.Errorf("unknown shutdown step %q", step)
 }
 
-// ActionShutdownActivate prepares L1 withdrawal (Phase 1)
-func ActionShutdownActivate() cli.ActionFunc {
+// actionShutdownStep returns the action of a single shutdown step
+func actionShutdownStep(step, banner string) cli.ActionFunc {
 	return func(ctx context.Context, cmd *cli.Command) error {
-		fmt.Println("⚙️ Preparing L1 Withdrawal (Phase 1)...")
+		fmt.Println(banner)
 		sc, err := NewShutdownContext(ctx)
 		if err != nil {
 			return err
 		}
-		assetsPath := cmd.String("input")
-		if assetsPath == "" {
-			if sc.Config.Shutdown != nil && sc.Config.Shutdown.AssetsDataPath != "" {
-				assetsPath = sc.Config.Shutdown.AssetsDataPath
-			} else {
-				assetsPath = fmt.Sprintf("data/generate-assets-%d.json", sc.Config.L2ChainID)
-			}
-		}
-		client, err := thanos.NewThanosStack(ctx, sc.Logger, sc.Config.Network, false, sc.DeploymentPath, nil)
+		client, err := sc.newThanosStack(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to create thanos stack: %w", err)
+			return err
 		}
-		_, err = client.ShutdownActivate(ctx, assetsPath, cmd.Bool("dry-run"))
-		return err
+		return sc.runShutdownStep(ctx, cmd, client, step)
 	}
 }
 
+// ActionShutdownBlock blocks deposits and withdrawals
+func ActionShutdownBlock() cli.ActionFunc {
+	return actionShutdownStep(types.ShutdownStepBlock, "🚫 Blocking L1 Deposits and Withdrawals...")
+}
+
+// ActionShutdownFetch collects L2 asset information
+func ActionShutdownFetch() cli.ActionFunc {
+	return actionShutdownStep(types.ShutdownStepFetch, "🔍 Collecting L2 Asset Information...")
+}
+
+// ActionShutdownGen generates force withdrawal assets snapshot
+func ActionShutdownGen() cli.ActionFunc {
+	return actionShutdownStep(types.ShutdownStepGen, "🚀 Generating L2 Asset Snapshot...")
+}
+
+// ActionShutdownActivate prepares L1 withdrawal (Phase 1)
+func ActionShutdownActivate() cli.ActionFunc {
+	return actionShutdownStep(types.ShutdownStepActivate, "⚙️ Preparing L1 Withdrawal (Phase 1)...")
+}
+
 // ActionShutdownWithdraw executes liquidity sweep and claims (Phase 2)
 func ActionShutdownWithdraw() cli.ActionFunc {
+	return actionShutdownStep(types.ShutdownStepWithdraw, "💰 Executing L1 Asset Withdrawal (Phase 2)...")
+}
+
+// ActionShutdownRun orchestrates the entire shutdown process sequentially, resuming at the first step
+// that did not complete
+func ActionShutdownRun() cli.ActionFunc {
 	return func(ctx context.Context, cmd *cli.Command) error {
-		fmt.Println("💰 Executing L1 Asset Withdrawal (Phase 2)...")
+		fmt.Println("🏁 Starting Integrated Shutdown Process (Sequential)...")
 		sc, err := NewShutdownContext(ctx)
 		if err != nil {
 			return err
 		}
-		assetsPath := cmd.String("input")
-		if assetsPath == "" {
-			if sc.Config.Shutdown != nil && sc.Config.Shutdown.AssetsDataPath != "" {
-				assetsPath = sc.Config.Shutdown.AssetsDataPath
-			} else {
-				assetsPath = fmt.Sprintf("data/generate-assets-%d.json", sc.Config.L2ChainID)
+		client, err := sc.newThanosStack(ctx)
+		if err != nil {
+			return err
+		}
+
+		dryRun := cmd.Bool("dry-run")
+		skipFetch := cmd.Bool("skip-fetch")
+		if cmd.Bool("restart") {
+			sc.State.ResetSteps()
+			if err := sc.State.Save(); err != nil {
+				return fmt.Errorf("failed to save shutdown state: %w", err)
 			}
 		}
-		client, err := thanos.NewThanosStack(ctx, sc.Logger, sc.Config.Network, false, sc.DeploymentPath, nil)
-		if err != nil {
-			return fmt.Errorf("failed to create thanos stack: %w", err)
+
+		first := len(types.ShutdownSteps)
+		for i, step := range types.ShutdownSteps {
+			if step == types.ShutdownStepFetch && skipFetch {
+				continue
+			}
+			if !sc.State.StepDone(step, dryRun) {
+				first = i
+				break
+			}
 		}
-		storageAddr := cmd.String("storage-address")
-		return client.ShutdownWithdraw(ctx, assetsPath, cmd.Bool("dry-run"), storageAddr)
+		if first == len(types.ShutdownSteps) {
+			fmt.Println("✅ All shutdown steps are already completed. Use --restart to run them again.")
+			return nil
+		}
+
+		for i, step := range types.ShutdownSteps {
+			name := strings.ToUpper(step)
+			if step == types.ShutdownStepFetch && skipFetch {
+				fmt.Println("⏭️ Skipping Step [FETCH] as requested (using existing data).")
+				continue
+			}
+			if i < first {
+				fmt.Printf("⏭️ Step [%s] already completed at %s.\n", name, sc.State.Step(step).CompletedAt)
+				continue
+			}
+			if err := sc.runShutdownStep(ctx, cmd, client, step); err != nil {
+				return fmt.Errorf("Step [%s] failed: %w", name, err)
+			}
+			fmt.Printf("✅ Step [%s] completed successfully.\n", name)
+		}
+
+		fmt.Println("\n🎉 Integrated Shutdown Process Completed Successfully!")
+		return nil
 	}
 }
 
-// ActionShutdownRun orchestrates the entire shutdown process sequentially
-func ActionShutdownRun() cli.ActionFunc {
+// ActionShutdownRehearse runs the shutdown end to end against a local fork of L1 and prints the report
+func ActionShutdownRehearse() cli.ActionFunc {
 	return func(ctx context.Context, cmd *cli.Command) error {
-		fmt.Println("🏁 Starting Integrated Shutdown Process (Sequential)...")
+		fmt.Println("🎭 Rehearsing the Shutdown Process on a Local Fork of L1...")
 		sc, err := NewShutdownContext(ctx)
 		if err != nil {
 			return err
 		}
+		client, err := sc.newThanosStack(ctx)
+		if err != nil {
+			return err
+		}
 
-		// 1. Block
-		if err := ActionShutdownBlock()(ctx, cmd); err != nil {
-			return fmt.Errorf("Step [BLOCK] failed: %w", err)
+		l2StartBlock, _ := strconv.ParseUint(cmd.String("l2-start-block"), 10, 64)
+		l2EndBlock := cmd.String("l2-end-block")
+		if l2EndBlock == "" {
+			l2EndBlock = "latest"
+		}
+		report, err := client.ShutdownRehearse(ctx, types.ShutdownRehearseOptions{
+			ForkBlock: cmd.Uint("fork-block"),
+			SkipFetch: cmd.Bool("skip-fetch"),
+			Fetch: types.ShutdownFetchOptions{
+				Workers:    int(cmd.Int("workers")),
+				BlockRange: cmd.Uint("block-range"),
+			},
+			Gen: types.ShutdownConfig{
+				L2StartBlock: l2StartBlock,
+				L2EndBlock:   l2EndBlock,
+			},
+		})
+		if err != nil {
+			return err
 		}
-		fmt.Println("✅ Step [BLOCK] completed successfully.")
 
-		// 2. Fetch
-		if cmd.Bool("skip-fetch") {
-			fmt.Println("⏭️ Skipping Step [FETCH] as requested (using existing data).")
-		} else {
-			if err := ActionShutdownFetch()(ctx, cmd); err != nil {
-				return fmt.Errorf("Step [FETCH] failed: %w", err)
+		fmt.Printf("\n📋 Rehearsal Report (L1 chain %d forked at block %d):\n", report.L1ChainID, report.ForkBlock)
+		for _, step := range report.Steps {
+			line := fmt.Sprintf("   %s %-9s %s", shutdownStepIcon(step.Status), step.Step, step.Status)
+			if step.Status != types.ShutdownStepPending {
+				line += fmt.Sprintf(" in %s, %d txs, %d gas", step.Duration, len(step.Transactions), step.GasUsed)
+			}
+			fmt.Println(line)
+			if step.Error != "" {
+				fmt.Printf("      Error: %s\n", step.Error)
+			}
+			for _, tx := range step.Transactions {
+				if tx.Reverted {
+					fmt.Printf("      ❌ Reverted: %s (from %s to %s)\n", tx.Hash, tx.From, tx.To)
+				}
 			}
-			fmt.Println("✅ Step [FETCH] completed successfully.")
 		}
 
-		// 3. Gen
-		err = ActionShutdownGen()(ctx, cmd)
-		if err != nil {
-			return fmt.Errorf("Step [GEN] failed: %w", err)
+		fmt.Printf("\n💰 Claimable Assets:\n")
+		if len(report.Claimable) == 0 {
+			fmt.Println("   (no fetched balances)")
+		}
+		for _, claimable := range report.Claimable {
+			name := claimable.Symbol
+			if name == "" {
+				name = "native"
+			}
+			fmt.Printf("   %-8s %s (%s) held by %d accounts, %s by contracts\n", name,
+				formatShutdownAmount(claimable.Total, claimable.Decimals), claimable.Token, claimable.Holders,
+				formatShutdownAmount(claimable.ContractTotal, claimable.Decimals))
+		}
+		if report.UnclaimedWithdrawals > 0 {
+			fmt.Printf("   Unclaimed withdrawals: %d (%s native)\n", report.UnclaimedWithdrawals, formatShutdownAmount(report.UnclaimedValue, 18))
+		}
+
+		fmt.Printf("\n⛽ Gas: %d used, %s ETH\n", report.GasUsed, formatShutdownAmount(report.GasCost, 18))
+		if report.StorageAddress != "" {
+			fmt.Printf("   GenFWStorage on the fork: %s\n", report.StorageAddress)
+		}
+		fmt.Printf("   Report: %s\n", report.Path)
+
+		for _, step := range report.Steps {
+			if step.Status == types.ShutdownStepFailed {
+				return fmt.Errorf("shutdown rehearsal failed at step [%s], see %s", strings.ToUpper(step.Step), report.Path)
+			}
+		}
+		if !report.Success {
+			return fmt.Errorf("shutdown rehearsal had %d reverted transactions, see %s", report.Reverts, report.Path)
 		}
+		fmt.Println("\n🎉 Shutdown Rehearsal Completed Successfully! The real L1 was not touched.")
+		return nil
+	}
+}
 
-		// Persistence: Save the path to settings.json
-		if sc.Config.Shutdown == nil {
-			sc.Config.Shutdown = &types.ShutdownConfig{}
+// ActionShutdownReconcile compares the assets snapshot with GenFWStorage and the L1 claims, and exports
+// the claim status of every address
+func ActionShutdownReconcile() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		fmt.Println("🧾 Reconciling Force Withdrawal Claims...")
+		sc, err := NewShutdownContext(ctx)
+		if err != nil {
+			return err
+		}
+		client, err := sc.newThanosStack(ctx)
+		if err != nil {
+			return err
 		}
-		sc.Config.Shutdown.AssetsDataPath = fmt.Sprintf("data/generate-assets-%d.json", sc.Config.L2ChainID)
-		sc.Config.WriteToJSONFile(sc.DeploymentPath)
 
-		fmt.Println("✅ Step [GEN] completed successfully (Path saved to settings.json).")
+		format := cmd.String("format")
+		var extensions []string
+		switch format {
+		case "csv", "json":
+			extensions = []string{format}
+		case "both":
+			extensions = []string{"csv", "json"}
+		default:
+			return fmt.Errorf("unsupported format %q, use csv, json or both", format)
+		}
 
-		// 4. Activate
 		storageAddr := cmd.String("storage-address")
 		if storageAddr == "" {
-			activateClient, err := thanos.NewThanosStack(ctx, sc.Logger, sc.Config.Network, false, sc.DeploymentPath, nil)
-			if err != nil {
-				return fmt.Errorf("failed to create thanos stack for activate: %w", err)
-			}
-			activateAddr, err := activateClient.ShutdownActivate(ctx, sc.Config.Shutdown.AssetsDataPath, cmd.Bool("dry-run"))
-			if err != nil {
-				return fmt.Errorf("Step [ACTIVATE] failed: %w", err)
-			}
-			storageAddr = activateAddr
-		} else {
-			if err := ActionShutdownActivate()(ctx, cmd); err != nil {
-				return fmt.Errorf("Step [ACTIVATE] failed: %w", err)
+			storageAddr = sc.State.StorageAddress
+		}
+		opts := types.ShutdownReconcileOptions{
+			AssetsPath:     sc.assetsPath(cmd.String("input")),
+			StorageAddress: storageAddr,
+			FromBlock:      cmd.Uint("from-block"),
+		}
+		if activate := sc.State.Step(types.ShutdownStepActivate); len(activate.TxHashes) > 0 {
+			opts.ActivateTxHash = activate.TxHashes[0]
+		}
+		report, err := client.ShutdownReconcile(ctx, opts)
+		if err != nil {
+			return err
+		}
+
+		outputDir := cmd.String("output")
+		if outputDir == "" {
+			outputDir = sc.DeploymentPath
+		}
+		var paths []string
+		for _, extension := range extensions {
+			path := filepath.Join(outputDir, fmt.Sprintf(thanos.ShutdownClaimsFile, sc.Config.L2ChainID, extension))
+			if err := thanos.WriteShutdownClaims(report, path); err != nil {
+				return err
 			}
+			paths = append(paths, path)
 		}
-		fmt.Println("✅ Step [ACTIVATE] completed successfully.")
 
-		// 5. Withdraw
-		withdrawClient, err := thanos.NewThanosStack(ctx, sc.Logger, sc.Config.Network, false, sc.DeploymentPath, nil)
-		if err != nil {
-			return fmt.Errorf("failed to create thanos stack for withdraw: %w", err)
+		fmt.Printf("\n📋 Claims (L1 blocks %d-%d, GenFWStorage %s):\n", report.FromBlock, report.ToBlock, report.StorageAddress)
+		for _, total := range report.Totals {
+			name := total.TokenName
+			if name == "" {
+				name = total.L1Token
+			}
+			fmt.Printf("   %-10s %d claims, %s owed, %s claimed, %d mismatches\n", name, total.Claims, total.Amount, total.Claimed, total.Mismatches)
+		}
+		if report.Mismatches > 0 {
+			fmt.Printf("\n⚠️  Mismatches:\n")
+			shown := 0
+			for _, record := range report.Records {
+				if !record.Status.Mismatch() {
+					continue
+				}
+				if shown == 20 {
+					fmt.Printf("   ... %d more, see the exported report\n", report.Mismatches-shown)
+					break
+				}
+				fmt.Printf("   %s %s %s: snapshot %s, storage %s, claimed %s\n", record.Status, record.Claimer, record.L1Token, record.Amount, record.StorageAmount, record.ClaimedAmount)
+				shown++
+			}
 		}
-		if err := withdrawClient.ShutdownWithdraw(ctx, sc.Config.Shutdown.AssetsDataPath, cmd.Bool("dry-run"), storageAddr); err != nil {
-			return fmt.Errorf("Step [WITHDRAW] failed: %w", err)
+		for _, path := range paths {
+			fmt.Printf("   Exported: %s\n", path)
 		}
 
-		fmt.Println("\n🎉 Integrated Shutdown Process Completed Successfully!")
+		if report.Mismatches > 0 {
+			return fmt.Errorf("%d claims do not match the assets snapshot", report.Mismatches)
+		}
+		fmt.Println("\n✅ Every claim matches the assets snapshot.")
 		return nil
 	}
 }
 
+// formatShutdownAmount formats a base-unit amount with the decimals of its token
+func formatShutdownAmount(amount string, decimals uint8) string {
+	value, ok := new(big.Float).SetString(amount)
+	if !ok {
+		return amount
+	}
+	scale := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	return value.Quo(value, scale).Text('f', 6)
+}
+
 // ActionShutdownStatus shows current shutdown status
 func ActionShutdownStatus() cli.ActionFunc {
 	return func(ctx context.Context, cmd *cli.Command) error {
@@ -446,41 +786,43 @@ func ActionShutdownStatus() cli.ActionFunc {
 		}
 
 		// Display execution history from state
-		fmt.Printf("\n📜 Execution History:\n")
-		if sc.State.LastCommand != "" {
-			fmt.Printf("   Last Command: %s\n", sc.State.LastCommand)
-		} else {
-			fmt.Printf("   Last Command: (none)\n")
-		}
-
-		if sc.State.LastGenAt != "" {
-			fmt.Printf("   Last Gen: %s\n", sc.State.LastGenAt)
-			if sc.State.LastSnapshotPath != "" {
-				fmt.Printf("   Last Snapshot: %s\n", sc.State.LastSnapshotPath)
+		fmt.Printf("\n📜 Shutdown Steps (%s):\n", types.ShutdownStateFilePath(sc.DeploymentPath, sc.Config.L2ChainID))
+		for _, step := range types.ShutdownSteps {
+			state := sc.State.Step(step)
+			line := fmt.Sprintf("   %s %-9s %s", shutdownStepIcon(state.Status), step, state.Status)
+			if state.DryRun {
+				line += " (dry-run)"
+			}
+			if state.CompletedAt != "" {
+				line += " at " + state.CompletedAt
+			} else if state.StartedAt != "" {
+				line += " since " + state.StartedAt
+			}
+			fmt.Println(line)
+			if state.Error != "" {
+				fmt.Printf("      Error: %s\n", state.Error)
+			}
+			for _, hash := range state.TxHashes {
+				fmt.Printf("      Tx: %s\n", hash)
+			}
+			if state.InputHash != "" {
+				fmt.Printf("      Snapshot sha256: %s\n", state.InputHash)
 			}
-		} else {
-			fmt.Printf("   Last Gen: (never)\n")
 		}
-
-		if sc.State.LastDryRunAt != "" {
-			fmt.Printf("   Last Dry-Run: %s\n", sc.State.LastDryRunAt)
-		} else {
-			fmt.Printf("   Last Dry-Run: (never)\n")
+		if sc.State.SnapshotPath != "" {
+			fmt.Printf("   Last Snapshot: %s (sha256 %s)\n", sc.State.SnapshotPath, sc.State.SnapshotHash)
 		}
-
-		if sc.State.LastSendAt != "" {
-			fmt.Printf("   Last Send: %s\n", sc.State.LastSendAt)
+		if sc.State.StorageAddress != "" {
+			fmt.Printf("   GenFWStorage: %s\n", sc.State.StorageAddress)
+		}
+		if next := sc.State.NextStep(false); next != "" {
+			fmt.Printf("   Next Step: %s\n", next)
 		} else {
-			fmt.Printf("   Last Send: (never)\n")
+			fmt.Printf("   Next Step: (none, shutdown completed)\n")
 		}
 
 		// Check for generated assets file
-		assetsPath := ""
-		if sc.Config.Shutdown != nil && sc.Config.Shutdown.AssetsDataPath != "" {
-			assetsPath = sc.Config.Shutdown.AssetsDataPath
-		} else {
-			assetsPath = fmt.Sprintf("data/generate-assets-%d.json", sc.Config.L2ChainID)
-		}
+		assetsPath := sc.assetsPath("")
 
 		fmt.Printf("\n📁 Deployment Contracts:\n")
 		if contracts, err := sc.readDeploymentContracts(); err != nil {
@@ -504,3 +846,15 @@ func ActionShutdownStatus() cli.ActionFunc {
 		return nil
 	}
 }
+
+func shutdownStepIcon(status types.ShutdownStepStatus) string {
+	switch status {
+	case types.ShutdownStepCompleted:
+		return "✅"
+	case types.ShutdownStepFailed:
+		return "❌"
+	case types.ShutdownStepRunning:
+		return "🔄"
+	}
+	return "⏳"
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// ActionSnapshotExport archives the op-geth datadir of the deployment with a manifest of its head
+func ActionSnapshotExport() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		thanosStack, err := snapshotThanosStack(ctx, "export")
+		if err != nil {
+			return err
+		}
+		_, err = thanosStack.ChainSnapshotExport(ctx, types.ChainSnapshotExportOptions{
+			Destination: cmd.String("to"),
+			EndpointURL: cmd.String("endpoint-url"),
+			Yes:         cmd.Bool("yes"),
+		})
+		return err
+	}
+}
+
+// ActionSnapshotImport seeds the op-geth datadir of the deployment, a docker volume or a claim from a snapshot
+func ActionSnapshotImport() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		thanosStack, err := snapshotThanosStack(ctx, "import")
+		if err != nil {
+			return err
+		}
+		_, err = thanosStack.ChainSnapshotImport(ctx, types.ChainSnapshotImportOptions{
+			Source:          cmd.String("from"),
+			EndpointURL:     cmd.String("endpoint-url"),
+			Volume:          cmd.String("volume"),
+			PVC:             cmd.String("pvc"),
+			Namespace:       cmd.String("namespace"),
+			SubPath:         cmd.String("sub-path"),
+			AllowOtherChain: cmd.Bool("allow-other-chain"),
+		})
+		return err
+	}
+}
+
+func snapshotThanosStack(ctx context.Context, action string) (*thanos.ThanosStack, error) {
+	deploymentPath, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	config, err := utils.ReadConfigFromJSONFile(deploymentPath)
+	if err != nil || config == nil {
+		return nil, fmt.Errorf("failed to read settings.json (ensure the L2 has been deployed): %w", err)
+	}
+	if config.Network == constants.LocalDevnet {
+		return nil, errors.New("snapshots are not supported for the devnet")
+	}
+
+	logFile := fmt.Sprintf("%s/logs/snapshot_%s_%s_%s_%d.log", deploymentPath, action, config.Network, config.Stack, time.Now().Unix())
+	l, err := logging.InitLogger(logFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	thanosStack, err := thanos.NewThanosStack(ctx, l, config.Network, false, deploymentPath, config.AWS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ThanosStack instance: %w", err)
+	}
+	return thanosStack, nil
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// ActionSupportBundle writes a redacted diagnostics tarball of the deployment in the current directory
+func ActionSupportBundle() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		deploymentPath, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current working directory: %w", err)
+		}
+		config, err := utils.ReadConfigFromJSONFile(deploymentPath)
+		if err != nil {
+			return fmt.Errorf("failed to read settings.json: %w", err)
+		}
+
+		network := constants.LocalDevnet
+		var awsConfig *types.AWSConfig
+		if config != nil {
+			network = config.Network
+			awsConfig = config.AWS
+		}
+
+		logFile := fmt.Sprintf("%s/logs/support_bundle_%s_%d.log", deploymentPath, network, time.Now().Unix())
+		l, err := logging.InitFileLogger(logFile)
+		if err != nil {
+			return fmt.Errorf("failed to initialize logger: %w", err)
+		}
+
+		thanosStack, err := thanos.NewThanosStack(ctx, l, network, false, deploymentPath, awsConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create ThanosStack instance: %w", err)
+		}
+
+		fmt.Println("📦 Collecting the support bundle, this can take a few minutes...")
+		path, err := thanosStack.CreateSupportBundle(ctx, &types.SupportBundleOptions{
+			OutputPath: cmd.String("output"),
+			LogSince:   cmd.Duration("log-since"),
+			SDKVersion: GetVersionInfo(),
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Support bundle written to %s\n", path)
+		fmt.Println("Secrets are redacted, but please review the bundle before sharing it.")
+		return nil
+	}
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+var troubleshootSeverityIcons = map[string]string{
+	"critical": "❌",
+	"warning":  "⚠️ ",
+	"info":     "ℹ️ ",
+}
+
+// ActionTroubleshoot diagnoses known failures from the recent component logs, namespace events and SDK logs
+func ActionTroubleshoot() cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		deploymentPath, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current working directory: %w", err)
+		}
+
+		if cmd.Bool("list-rules") {
+			rules, err := thanos.LoadTroubleshootRules(deploymentPath)
+			if err != nil {
+				return err
+			}
+			for _, rule := range rules {
+				fmt.Printf("%-28s %-8s %s\n", rule.ID, rule.Severity, rule.Title)
+			}
+			return nil
+		}
+
+		config, err := utils.ReadConfigFromJSONFile(deploymentPath)
+		if err != nil {
+			return fmt.Errorf("failed to read settings.json: %w", err)
+		}
+		network := constants.LocalDevnet
+		var awsConfig *types.AWSConfig
+		if config != nil {
+			network = config.Network
+			awsConfig = config.AWS
+		}
+
+		logFile := fmt.Sprintf("%s/logs/troubleshoot_%s_%d.log", deploymentPath, network, time.Now().Unix())
+		l, err := logging.InitFileLogger(logFile)
+		if err != nil {
+			return fmt.Errorf("failed to initialize logger: %w", err)
+		}
+		thanosStack, err := thanos.NewThanosStack(ctx, l, network, false, deploymentPath, awsConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create ThanosStack instance: %w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		report, err := thanosStack.Troubleshoot(ctx, &types.TroubleshootOptions{
+			Components: cmd.StringSlice("component"),
+			Since:      cmd.Duration("since"),
+		})
+		if err != nil {
+			return err
+		}
+
+		if cmd.Bool("json") {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal troubleshoot report: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("🔎 Scanned %d log lines of the %s deployment from the last %s\n", report.ScannedLines, report.Deployment, report.Since)
+		for _, e := range report.Errors {
+			fmt.Printf("   could not read %s\n", e)
+		}
+		if len(report.Findings) == 0 {
+			fmt.Println("✅ No known failure found")
+			return nil
+		}
+		fmt.Println()
+		printTroubleshootFindings(report.Findings)
+		return nil
+	}
+}
+
+// PrintErrorDiagnosis prints the diagnosis of a command error matching a troubleshoot rule
+func PrintErrorDiagnosis(err error) {
+	deploymentPath, wdErr := os.Getwd()
+	if wdErr != nil {
+		return
+	}
+	findings := thanos.DiagnoseError(deploymentPath, err)
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Println("\n🩹 This looks like a known problem:")
+	fmt.Println()
+	printTroubleshootFindings(findings)
+}
+
+func printTroubleshootFindings(findings []types.TroubleshootFinding) {
+	for _, finding := range findings {
+		fmt.Printf("%s %s [%s]\n", troubleshootSeverityIcons[finding.Severity], finding.Title, finding.RuleID)
+		if finding.Count > 1 || len(finding.Sources) > 1 || !finding.LastSeen.IsZero() {
+			line := fmt.Sprintf("   %d matches in %s", finding.Count, strings.Join(finding.Sources, ", "))
+			if !finding.LastSeen.IsZero() {
+				line += fmt.Sprintf(", last at %s", finding.LastSeen.Local().Format("2006-01-02 15:04:05"))
+			}
+			fmt.Println(line)
+		}
+		fmt.Printf("   > %s\n", finding.Example)
+		fmt.Printf("   %s\n", finding.Diagnosis)
+		if len(finding.Fix) > 0 {
+			fmt.Println("   Fix:")
+			for _, fix := range finding.Fix {
+				fmt.Printf("     %s\n", fix)
+			}
+		}
+		fmt.Println()
+	}
+}
@@ -71,12 +71,12 @@ var (
 	// BackupManager primary action flags
 	BackupStatusFlag = &cli.BoolFlag{
 		Name:  "status",
-		Usage: "Show backup protection status and latest points",
+		Usage: "Show backup protection status and latest points of EFS and the block explorer database",
 	}
 
 	BackupSnapshotFlag = &cli.BoolFlag{
 		Name:  "snapshot",
-		Usage: "Create an on-demand backup for EFS",
+		Usage: "Create an on-demand backup for EFS (docker volumes for local deployments)",
 	}
 
 	BackupListFlag = &cli.BoolFlag{
@@ -105,6 +105,17 @@ var (
 		Usage: "Limit number of entries when listing (default: 20)",
 	}
 
+	// BackupManager restore option flags
+	BackupRecoveryPointArnFlag = &cli.StringFlag{
+		Name:  "recovery-point-arn",
+		Usage: "Recovery point ARN to restore (EFS, or RDS for the block explorer database only; default: choose interactively)",
+	}
+
+	BackupIDFlag = &cli.StringFlag{
+		Name:  "backup-id",
+		Usage: "Local backup ID to restore (local deployments; default: choose interactively)",
+	}
+
 	// BackupManager attach option flags
 	BackupEfsIdFlag = &cli.StringFlag{
 		Name:  "efs-id",
@@ -136,6 +147,26 @@ var (
 		Name:  "reset",
 		Usage: "Reset to defaults (EFS daily 03:00, unlimited keep)",
 	}
+
+	BackupCopyRegionFlag = &cli.StringFlag{
+		Name:  "copy-region",
+		Usage: "Secondary region recovery points are copied to for failover (none = turn copies off)",
+	}
+
+	BackupCopyKeepFlag = &cli.StringFlag{
+		Name:  "copy-keep",
+		Usage: "Keep days of the cross-region copies (0 = unlimited)",
+	}
+
+	BackupGFSFlag = &cli.BoolFlag{
+		Name:  "gfs",
+		Usage: "Grandfather-father-son rules: hourly (2 days), daily (14), weekly (56) and monthly (365, cold after 30)",
+	}
+
+	BackupRuleFlag = &cli.StringSliceFlag{
+		Name:  "rule",
+		Usage: "Backup rule NAME[:keep=DAYS,cold=DAYS,at=HH:MM,day=D,tag.KEY=VALUE], NAME is hourly, daily, weekly or monthly",
+	}
 )
 
 var BackupManagerFlags = []cli.Flag{
@@ -150,6 +181,10 @@ var BackupManagerFlags = []cli.Flag{
 	// list options
 	BackupLimitFlag,
 
+	// restore options
+	BackupRecoveryPointArnFlag,
+	BackupIDFlag,
+
 	// attach options (EFS)
 	BackupEfsIdFlag,
 	BackupPvcFlag,
@@ -159,4 +194,8 @@ var BackupManagerFlags = []cli.Flag{
 	BackupDailyFlag,
 	BackupKeepFlag,
 	BackupResetFlag,
+	BackupCopyRegionFlag,
+	BackupCopyKeepFlag,
+	BackupGFSFlag,
+	BackupRuleFlag,
 }
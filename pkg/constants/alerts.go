@@ -12,6 +12,8 @@ const (
 	// Balance Alerts
 	AlertOpBatcherBalanceCritical  = "OpBatcherBalanceCritical"
 	AlertOpProposerBalanceCritical = "OpProposerBalanceCritical"
+	AlertOperatorBalanceLow        = "OperatorBalanceLow"
+	AlertOperatorTopUpCapReached   = "OperatorTopUpCapReached"
 
 	// System Alerts
 	AlertBlockProductionStalled   = "BlockProductionStalled"
@@ -20,6 +22,16 @@ const (
 	AlertPodCrashLooping          = "PodCrashLooping"
 )
 
+// Alert severities used in rule labels and channel routing
+const (
+	AlertSeverityCritical = "critical"
+	AlertSeverityWarning  = "warning"
+	AlertSeverityInfo     = "info"
+)
+
+// AlertSeverities lists the valid alert severities
+var AlertSeverities = []string{AlertSeverityCritical, AlertSeverityWarning, AlertSeverityInfo}
+
 // Alert descriptions for user-friendly display
 var AlertDescriptions = map[string]string{
 	AlertOpNodeDown:                "OP Node is down",
@@ -29,6 +41,8 @@ var AlertDescriptions = map[string]string{
 	AlertL1RpcDown:                 "L1 RPC connection failed",
 	AlertOpBatcherBalanceCritical:  "OP Batcher ETH balance critically low",
 	AlertOpProposerBalanceCritical: "OP Proposer ETH balance critically low",
+	AlertOperatorBalanceLow:        "L1 operator account balance is low",
+	AlertOperatorTopUpCapReached:   "Daily top-up cap reached",
 	AlertBlockProductionStalled:    "Block production has stalled",
 	AlertContainerCpuUsageHigh:     "High CPU usage in Thanos Stack pod",
 	AlertContainerMemoryUsageHigh:  "High memory usage in Thanos Stack pod",
@@ -44,3 +58,12 @@ var ConfigurableAlerts = map[string]string{
 	AlertContainerMemoryUsageHigh:  "Container memory usage threshold",
 	AlertPodCrashLooping:           "Pod crash loop detection",
 }
+
+// Core system alerts that must always be present in an alert policy
+var CoreAlerts = []string{
+	AlertOpNodeDown,
+	AlertOpBatcherDown,
+	AlertOpProposerDown,
+	AlertOpGethDown,
+	AlertL1RpcDown,
+}
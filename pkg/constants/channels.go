@@ -2,8 +2,12 @@ package constants
 
 // Channel types for alert notifications
 const (
-	ChannelEmail    = "email"
-	ChannelTelegram = "telegram"
+	ChannelEmail     = "email"
+	ChannelTelegram  = "telegram"
+	ChannelSlack     = "slack"
+	ChannelDiscord   = "discord"
+	ChannelPagerDuty = "pagerduty"
+	ChannelWebhook   = "webhook"
 )
 
 // ChannelType represents the type of notification channel
@@ -11,14 +15,18 @@ type ChannelType string
 
 // Valid channel types
 const (
-	EmailChannel    ChannelType = "email"
-	TelegramChannel ChannelType = "telegram"
+	EmailChannel     ChannelType = "email"
+	TelegramChannel  ChannelType = "telegram"
+	SlackChannel     ChannelType = "slack"
+	DiscordChannel   ChannelType = "discord"
+	PagerDutyChannel ChannelType = "pagerduty"
+	WebhookChannel   ChannelType = "webhook"
 )
 
 // IsValidChannelType checks if the given channel type is valid
 func IsValidChannelType(channelType string) bool {
 	switch channelType {
-	case ChannelEmail, ChannelTelegram:
+	case ChannelEmail, ChannelTelegram, ChannelSlack, ChannelDiscord, ChannelPagerDuty, ChannelWebhook:
 		return true
 	default:
 		return false
@@ -27,5 +35,19 @@ func IsValidChannelType(channelType string) bool {
 
 // GetValidChannelTypes returns a list of valid channel types
 func GetValidChannelTypes() []string {
-	return []string{ChannelEmail, ChannelTelegram}
+	return []string{ChannelEmail, ChannelTelegram, ChannelSlack, ChannelDiscord, ChannelPagerDuty, ChannelWebhook}
+}
+
+// RoutedChannels are the channels with their own AlertManager receiver and severity route.
+// Email and Telegram share the default receiver and get every alert.
+var RoutedChannels = []string{ChannelSlack, ChannelDiscord, ChannelPagerDuty, ChannelWebhook}
+
+// IsRoutedChannel checks if the channel has its own receiver and severity route
+func IsRoutedChannel(channelType string) bool {
+	for _, channel := range RoutedChannels {
+		if channel == channelType {
+			return true
+		}
+	}
+	return false
 }
@@ -2,6 +2,8 @@ package constants
 
 const (
 	L2CrossDomainMessenger = "0x4200000000000000000000000000000000000007"
+	L2StandardBridge       = "0x4200000000000000000000000000000000000010"
+	L2ToL1MessagePasser    = "0x4200000000000000000000000000000000000016"
 	NativeToken            = "0x0000000000000000000000000000000000000000"
 
 	// AA predeploys (Gaming/Full preset)
@@ -0,0 +1,247 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RedactedValue replaces secrets in redacted text
+const RedactedValue = "[REDACTED]"
+
+// secretNameParts mark a setting, env var or flag as secret. Names are compared lowercased
+// without '_', '-' and '.'.
+var secretNameParts = []string{
+	"privatekey", "privkey", "secret", "password", "passwd", "mnemonic", "seedphrase",
+	"apikey", "accesskey", "bottoken", "authtoken", "bearertoken", "apitoken", "routingkey", "webhook", "credential",
+}
+
+var (
+	// KEY=value, key: value and "key": "value" where the key names a secret
+	secretAssignmentPattern = regexp.MustCompile(`(?i)([\w.-]*(?:private[_.-]?key|priv[_.-]?key|secret|password|passwd|mnemonic|seed[_.-]?phrase|api[_.-]?key|access[_.-]?key|bot[_.-]?token|auth[_.-]?token|bearer[_.-]?token|routing[_.-]?key|webhook[_.-]?url)[\w.-]*["']?\s*[:=]\s*["']?)([^\s"',;&|)}]+)`)
+	// --private-key 0x... where the flag value is separated by a space
+	secretFlagPattern = regexp.MustCompile(`(?i)(--[a-z0-9-]*(?:private-key|secret|password|mnemonic)[a-z0-9-]*\s+)([^\s-][^\s]*)`)
+	// API keys in RPC provider URLs such as https://eth-sepolia.g.alchemy.com/v2/<key>
+	rpcURLKeyPattern = regexp.MustCompile(`(https?://[^\s"'/]+/v[0-9]+/)([A-Za-z0-9_-]{16,})`)
+	// AWS access key IDs
+	awsAccessKeyPattern = regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)
+	// 32-byte hex strings without 0x, the shape of private keys. File digests have the same shape,
+	// so they are only masked on lines with a key context.
+	bareKeyPattern = regexp.MustCompile(`(^|[^0-9A-Za-z:])[0-9a-fA-F]{64}($|[^0-9A-Za-z])`)
+	// keyContextPattern marks a line where bare hex may be a key: a key, private or secret word or
+	// an env assignment such as ADMIN_PK=
+	keyContextPattern = regexp.MustCompile(`(?i:key|private|secret)|\b[A-Z][A-Z0-9_]*=`)
+)
+
+// minSecretLength keeps short values such as "true" or a port from being masked everywhere
+const minSecretLength = 8
+
+// knownSecrets are the registered secret values, masked wherever they appear
+var knownSecrets = struct {
+	sync.RWMutex
+	values   map[string]struct{}
+	replacer *strings.Replacer
+}{values: map[string]struct{}{}}
+
+// IsSecretName reports whether a setting, env var or flag name holds a secret
+func IsSecretName(name string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(name))
+	for _, part := range secretNameParts {
+		if strings.Contains(normalized, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterSecret masks the values wherever they appear in redacted text. Hex keys are registered
+// with and without their 0x prefix.
+func RegisterSecret(values ...string) {
+	knownSecrets.Lock()
+	defer knownSecrets.Unlock()
+	added := false
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if len(value) < minSecretLength {
+			continue
+		}
+		variants := []string{value}
+		if trimmed := strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X"); trimmed != value && len(trimmed) >= minSecretLength {
+			variants = append(variants, trimmed)
+		}
+		for _, v := range variants {
+			if _, ok := knownSecrets.values[v]; !ok {
+				knownSecrets.values[v] = struct{}{}
+				added = true
+			}
+		}
+	}
+	if !added {
+		return
+	}
+
+	// Longest first, so a key is masked as a whole rather than after its 0x prefix
+	secrets := make([]string, 0, len(knownSecrets.values))
+	for v := range knownSecrets.values {
+		secrets = append(secrets, v)
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, v := range secrets {
+		pairs = append(pairs, v, RedactedValue)
+	}
+	knownSecrets.replacer = strings.NewReplacer(pairs...)
+}
+
+// RegisterSecretsIn registers the values of the secret assignments and flags of a command line or
+// env entry, so they stay masked when a tool echoes them on their own
+func RegisterSecretsIn(text string) {
+	for _, pattern := range []*regexp.Regexp{secretAssignmentPattern, secretFlagPattern} {
+		for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+			RegisterSecret(match[2])
+		}
+	}
+}
+
+// RegisterSecretsFromJSON registers the values of the secret fields of a JSON document, at any depth
+func RegisterSecretsFromJSON(data []byte) error {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	registerSecretFields(document)
+	return nil
+}
+
+func registerSecretFields(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if secret, ok := field.(string); ok && IsSecretName(key) {
+				RegisterSecret(secret)
+				continue
+			}
+			registerSecretFields(field)
+		}
+	case []interface{}:
+		for _, item := range v {
+			registerSecretFields(item)
+		}
+	}
+}
+
+// Redact masks registered secrets, secret assignments, secret flags, RPC URL API keys, AWS access
+// keys and bare 32-byte hex keys on lines with a key context in text
+func Redact(text string) string {
+	knownSecrets.RLock()
+	replacer := knownSecrets.replacer
+	knownSecrets.RUnlock()
+	if replacer != nil {
+		text = replacer.Replace(text)
+	}
+	text = secretAssignmentPattern.ReplaceAllString(text, "${1}"+RedactedValue)
+	text = secretFlagPattern.ReplaceAllString(text, "${1}"+RedactedValue)
+	text = rpcURLKeyPattern.ReplaceAllString(text, "${1}"+RedactedValue)
+	text = awsAccessKeyPattern.ReplaceAllString(text, RedactedValue)
+	return redactBareKeys(text)
+}
+
+// redactBareKeys masks the bare 32-byte hex of the lines with a key context, leaving hashes such as
+// a genesis digest readable
+func redactBareKeys(text string) string {
+	if !bareKeyPattern.MatchString(text) {
+		return text
+	}
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		if keyContextPattern.MatchString(line) {
+			lines[i] = bareKeyPattern.ReplaceAllString(line, "${1}"+RedactedValue+"${2}")
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// RedactJSON masks the values of secret fields of a JSON document, at any depth, and redacts the
+// remaining strings with Redact
+func RedactJSON(data []byte) ([]byte, error) {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(redactValue(document), "", "  ")
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if IsSecretName(key) {
+				if field != nil && field != "" {
+					v[key] = RedactedValue
+				}
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	case string:
+		return Redact(v)
+	default:
+		return v
+	}
+}
+
+// maxPendingLine is flushed by RedactingWriter even without a newline, so progress bars are not held back
+const maxPendingLine = 4096
+
+// RedactingWriter redacts what is written to it line by line before passing it on to w
+type RedactingWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	pending []byte
+}
+
+// NewRedactingWriter returns a writer that redacts each line before writing it to w. Call Flush
+// once the writer is done to pass on a last line without a newline.
+func NewRedactingWriter(w io.Writer) *RedactingWriter {
+	return &RedactingWriter{w: w}
+}
+
+func (r *RedactingWriter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending = append(r.pending, p...)
+	end := bytes.LastIndexByte(r.pending, '\n') + 1
+	if end == 0 && len(r.pending) < maxPendingLine {
+		return len(p), nil
+	}
+	if end == 0 {
+		end = len(r.pending)
+	}
+	if _, err := io.WriteString(r.w, Redact(string(r.pending[:end]))); err != nil {
+		return 0, err
+	}
+	r.pending = append(r.pending[:0], r.pending[end:]...)
+	return len(p), nil
+}
+
+// Flush writes the pending line without a newline
+func (r *RedactingWriter) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(r.w, Redact(string(r.pending)))
+	r.pending = r.pending[:0]
+	return err
+}
@@ -44,5 +44,74 @@ func InitLogger(logPath string) (*zap.SugaredLogger, error) {
 	core := zapcore.NewTee(consoleCore, fileCore)
 
 	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
-	return logger.Sugar(), nil
+	return WithRedaction(logger).Sugar(), nil
+}
+
+// InitFileLogger returns a logger that only writes to logPath, for commands whose stdout is machine-readable
+func InitFileLogger(logPath string) (*zap.SugaredLogger, error) {
+	if err := os.MkdirAll(filepath.Dir(logPath), 0744); err != nil {
+		return nil, err
+	}
+
+	logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	encoderConfig := zapcore.EncoderConfig{
+		TimeKey:      "timestamp",
+		MessageKey:   "msg",
+		LineEnding:   zapcore.DefaultLineEnding,
+		EncodeLevel:  zapcore.LowercaseLevelEncoder,
+		EncodeTime:   zapcore.ISO8601TimeEncoder,
+		EncodeCaller: zapcore.ShortCallerEncoder,
+	}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(logFile), zapcore.DebugLevel)
+	return WithRedaction(zap.New(core, zap.AddCaller())).Sugar(), nil
+}
+
+// WithRedaction returns a logger that redacts messages and string fields before they are written
+func WithRedaction(logger *zap.Logger) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &redactingCore{Core: core}
+	}))
+}
+
+// redactingCore masks secrets in the entries of the wrapped core
+type redactingCore struct {
+	zapcore.Core
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = Redact(entry.Message)
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		switch {
+		case IsSecretName(field.Key) && field.Type == zapcore.StringType:
+			field.String = RedactedValue
+		case field.Type == zapcore.StringType:
+			field.String = Redact(field.String)
+		case field.Type == zapcore.ErrorType:
+			if err, ok := field.Interface.(error); ok {
+				field = zap.String(field.Key, Redact(err.Error()))
+			}
+		}
+		redacted[i] = field
+	}
+	return redacted
 }
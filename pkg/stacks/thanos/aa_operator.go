@@ -2,7 +2,9 @@ package thanos
 
 import (
 	"context"
+	"time"
 
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
 	"github.com/tokamak-network/trh-sdk/pkg/types"
 	"go.uber.org/zap"
 )
@@ -17,13 +19,16 @@ type AAOperatorConfig struct {
 	// L2RpcUrl is the L2 RPC endpoint. When empty, localL2RPCURL() is used as a fallback
 	// (correct for local Docker deployments). AWS deployments must supply the EKS endpoint.
 	L2RpcUrl string
+	// MetricsAddr is the listen address of the /metrics and /healthz endpoints. Empty disables them.
+	MetricsAddr string
 }
 
 // RunAAOperatorFromConfig starts the AA operator background services using the ThanosStack's
 // already-loaded deployment configuration. It blocks until ctx is cancelled.
 //
 // This is the preferred entrypoint when running aa-operator as a goroutine inside trh-backend,
-// avoiding the need for a separate tokamaknetwork/trh-sdk Docker container.
+// avoiding the need for a separate tokamaknetwork/trh-sdk Docker container. The metrics are served
+// on aa_operator_metrics_addr of settings.json (DefaultAAOperatorMetricsAddr when unset).
 func (s *ThanosStack) RunAAOperatorFromConfig(ctx context.Context) {
 	if s.deployConfig == nil {
 		return
@@ -32,6 +37,7 @@ func (s *ThanosStack) RunAAOperatorFromConfig(ctx context.Context) {
 		FeeToken:        s.deployConfig.FeeToken,
 		AdminPrivateKey: s.deployConfig.AdminPrivateKey,
 		L2RpcUrl:        s.deployConfig.L2RpcUrl,
+		MetricsAddr:     AAOperatorMetricsAddr(s.deployConfig.AAOperatorMetricsAddr),
 	})
 }
 
@@ -45,12 +51,16 @@ func (s *ThanosStack) RunAAOperatorFromConfig(ctx context.Context) {
 //   - EntryPoint refill monitor: checks the EntryPoint deposit balance for MultiTokenPaymaster
 //     every 5 minutes and tops it up from the admin wallet when it falls below 0.5 TON.
 //
+// Both record their results as aa_operator_* metrics, served with /healthz on cfg.MetricsAddr.
+//
 // The L2 RPC URL is resolved via localL2RPCURL(), which checks the L2_RPC_URL env var first
 // so the Docker service can point directly at the op-geth container (http://op-geth:8545).
 func RunAAOperator(ctx context.Context, cfg AAOperatorConfig) {
 	logger, _ := zap.NewProduction()
+	logger = logging.WithRedaction(logger)
 	defer logger.Sync() //nolint:errcheck
 	sugar := logger.Sugar()
+	logging.RegisterSecret(cfg.AdminPrivateKey)
 
 	t := &ThanosStack{
 		deployConfig: &types.Config{
@@ -58,10 +68,14 @@ func RunAAOperator(ctx context.Context, cfg AAOperatorConfig) {
 			AdminPrivateKey: cfg.AdminPrivateKey,
 			L2RpcUrl:        cfg.L2RpcUrl,
 		},
-		logger: sugar,
+		logger:   sugar,
+		aaStatus: newAAOperatorStatus(time.Now()),
 	}
 
 	sugar.Infof("aa-operator starting (feeToken=%s)", cfg.FeeToken)
+	if cfg.MetricsAddr != "" {
+		serveOperatorMetrics(ctx, sugar, cfg.MetricsAddr, t.aaStatus.metrics, func() error { return t.aaStatus.healthy(time.Now()) })
+	}
 	t.startPriceUpdater(ctx)
 	t.startEntryPointRefillMonitor(ctx)
 
@@ -0,0 +1,132 @@
+package thanos
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAAOperatorMetricsAddr serves the aa-operator /metrics and /healthz endpoints
+	DefaultAAOperatorMetricsAddr = ":7311"
+
+	// aaOracleStaleAfter matches STALE_THRESHOLD of SimplePriceOracle
+	aaOracleStaleAfter = 24 * time.Hour
+)
+
+// AAOperatorMetricsAddr resolves a configured metrics listen address: empty uses
+// DefaultAAOperatorMetricsAddr and "off" disables the endpoints
+func AAOperatorMetricsAddr(addr string) string {
+	switch addr {
+	case "":
+		return DefaultAAOperatorMetricsAddr
+	case "off":
+		return ""
+	}
+	return addr
+}
+
+// aaOperatorStatus records what the price updater and refill monitor did, for the aa-operator
+// /metrics and /healthz endpoints. A nil status records nothing.
+type aaOperatorStatus struct {
+	metrics *operatorMetrics
+
+	mu              sync.Mutex
+	startedAt       time.Time
+	lastPriceUpdate time.Time
+	lastRefillCheck time.Time
+}
+
+func newAAOperatorStatus(now time.Time) *aaOperatorStatus {
+	s := &aaOperatorStatus{metrics: newOperatorMetrics(), startedAt: now}
+	s.metrics.onCollect = func() { s.collect(time.Now()) }
+	return s
+}
+
+// priceFetched records a CoinGecko request
+func (s *aaOperatorStatus) priceFetched(latency time.Duration, err error) {
+	if s == nil {
+		return
+	}
+	s.metrics.setGauge("aa_operator_price_fetch_duration_seconds", "Latency of the last price source request", latency.Seconds())
+	if err != nil {
+		s.metrics.addCounter("aa_operator_price_fetch_errors_total", "Failed price source requests", 1)
+	}
+}
+
+// pricePushed records an oracle update with result success, failed or skipped
+func (s *aaOperatorStatus) pricePushed(price *big.Int, result string, now time.Time) {
+	if s == nil {
+		return
+	}
+	s.metrics.addCounter("aa_operator_price_updates_total", "SimplePriceOracle updates by result", 1, "result", result)
+	switch result {
+	case "success":
+		s.metrics.setGauge("aa_operator_oracle_price_wei", "Last price pushed to SimplePriceOracle (1 TON in the fee token, 18 decimals)", weiToFloat(price))
+		s.metrics.setGauge("aa_operator_last_price_update_timestamp_seconds", "Time of the last SimplePriceOracle update", float64(now.Unix()))
+		s.mu.Lock()
+		s.lastPriceUpdate = now
+		s.mu.Unlock()
+	case "failed":
+		s.metrics.addCounter("aa_operator_tx_failures_total", "Failed L2 transactions of the aa-operator", 1, "method", "updatePrice")
+	}
+}
+
+// refillChecked records the EntryPoint deposit and admin balance of a refill check
+func (s *aaOperatorStatus) refillChecked(deposit, adminBalance *big.Int, now time.Time) {
+	if s == nil {
+		return
+	}
+	s.metrics.setGauge("aa_operator_entrypoint_deposit_wei", "EntryPoint deposit of MultiTokenPaymaster", weiToFloat(deposit))
+	if adminBalance != nil {
+		s.metrics.setGauge("aa_operator_admin_balance_wei", "L2 balance of the admin wallet", weiToFloat(adminBalance))
+	}
+	s.mu.Lock()
+	s.lastRefillCheck = now
+	s.mu.Unlock()
+}
+
+// refilled records an EntryPoint depositTo transaction
+func (s *aaOperatorStatus) refilled(err error) {
+	if s == nil {
+		return
+	}
+	if err != nil {
+		s.metrics.addCounter("aa_operator_tx_failures_total", "Failed L2 transactions of the aa-operator", 1, "method", "depositTo")
+		return
+	}
+	s.metrics.addCounter("aa_operator_entrypoint_refills_total", "EntryPoint deposits made by the refill monitor", 1)
+}
+
+// collect updates the oracle staleness right before a scrape
+func (s *aaOperatorStatus) collect(now time.Time) {
+	s.mu.Lock()
+	lastUpdate := s.lastPriceUpdate
+	s.mu.Unlock()
+	if lastUpdate.IsZero() {
+		return
+	}
+	s.metrics.setGauge("aa_operator_oracle_staleness_seconds", "Seconds since the last SimplePriceOracle update", now.Sub(lastUpdate).Seconds())
+}
+
+// healthy fails when the oracle went stale or the EntryPoint deposit has not been checked for
+// three refill intervals. Both are measured from the start of the operator.
+func (s *aaOperatorStatus) healthy(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	since := func(last time.Time) time.Duration {
+		if last.Before(s.startedAt) {
+			last = s.startedAt
+		}
+		return now.Sub(last)
+	}
+	if age := since(s.lastPriceUpdate); age > aaOracleStaleAfter {
+		return fmt.Errorf("no oracle price update for %s", age.Round(time.Second))
+	}
+	if age := since(s.lastRefillCheck); age > 3*refillPollInterval {
+		return fmt.Errorf("no EntryPoint deposit check for %s", age.Round(time.Second))
+	}
+	return nil
+}
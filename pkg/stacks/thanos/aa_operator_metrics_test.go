@@ -0,0 +1,79 @@
+package thanos
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAAOperatorStatus(t *testing.T) {
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	status := newAAOperatorStatus(start)
+
+	status.priceFetched(250*time.Millisecond, nil)
+	status.priceFetched(time.Second, fmt.Errorf("CoinGecko returned HTTP 429"))
+	status.pricePushed(big.NewInt(5e14), "success", start.Add(time.Minute))
+	status.pricePushed(big.NewInt(5e14), "skipped", start.Add(11*time.Minute))
+	status.pricePushed(big.NewInt(6e14), "failed", start.Add(21*time.Minute))
+	status.refillChecked(big.NewInt(4e17), big.NewInt(3e18), start.Add(time.Minute))
+	status.refilled(nil)
+	status.refilled(fmt.Errorf("insufficient funds"))
+
+	require.Equal(t, float64(1), status.metrics.value("aa_operator_price_fetch_errors_total"))
+	require.Equal(t, float64(1), status.metrics.value("aa_operator_price_fetch_duration_seconds"), "the latency of the last request")
+	require.Equal(t, float64(5e14), status.metrics.value("aa_operator_oracle_price_wei"), "failed pushes keep the last price")
+	require.Equal(t, float64(1), status.metrics.value("aa_operator_tx_failures_total", "method", "updatePrice"))
+	require.Equal(t, float64(1), status.metrics.value("aa_operator_tx_failures_total", "method", "depositTo"))
+	require.Equal(t, float64(1), status.metrics.value("aa_operator_entrypoint_refills_total"))
+
+	status.collect(start.Add(time.Hour))
+	require.Equal(t, float64(59*60), status.metrics.value("aa_operator_oracle_staleness_seconds"))
+
+	require.NoError(t, status.healthy(start.Add(10*time.Minute)))
+	require.ErrorContains(t, status.healthy(start.Add(time.Hour)), "no EntryPoint deposit check")
+	status.refillChecked(big.NewInt(4e17), nil, start.Add(25*time.Hour))
+	require.ErrorContains(t, status.healthy(start.Add(25*time.Hour)), "no oracle price update")
+
+	// A nil status is what every other ThanosStack has
+	var none *aaOperatorStatus
+	none.pricePushed(big.NewInt(1), "success", start)
+	none.refilled(nil)
+}
+
+func TestAAOperatorMetricsAddr(t *testing.T) {
+	require.Equal(t, DefaultAAOperatorMetricsAddr, AAOperatorMetricsAddr(""))
+	require.Equal(t, "", AAOperatorMetricsAddr("off"))
+	require.Equal(t, "127.0.0.1:9311", AAOperatorMetricsAddr("127.0.0.1:9311"))
+}
+
+func TestAAOperatorMetricsEndpoint(t *testing.T) {
+	status := newAAOperatorStatus(time.Now())
+	status.pricePushed(big.NewInt(5e14), "success", time.Now())
+	status.refillChecked(big.NewInt(4e17), big.NewInt(3e18), time.Now())
+	status.refilled(nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, status.metrics.writeTo(w))
+	}))
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	// Every aa_operator metric of the built-in dashboard is exported
+	dashboard, err := BuiltinGrafanaDashboard("aa-paymaster")
+	require.NoError(t, err)
+	for _, name := range regexp.MustCompile(`aa_operator_[a-z_]+`).FindAllString(string(dashboard), -1) {
+		require.Contains(t, string(body), "# TYPE "+name+" ", name)
+	}
+	require.Contains(t, string(body), `aa_operator_price_updates_total{result="success"} 1`)
+}
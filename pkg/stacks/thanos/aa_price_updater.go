@@ -87,9 +87,11 @@ func (t *ThanosStack) startPriceUpdater(ctx context.Context) {
 		} else {
 			if err := t.pushOraclePrice(ctx, price); err != nil {
 				t.logger.Warnf("PriceUpdater: initial push failed: %v", err)
+				t.aaStatus.pricePushed(price, "failed", time.Now())
 			} else {
 				lastPushed = price
 				lastPushTime = time.Now()
+				t.aaStatus.pricePushed(price, "success", lastPushTime)
 			}
 		}
 
@@ -118,15 +120,18 @@ func (t *ThanosStack) startPriceUpdater(ctx context.Context) {
 
 				if !needsUpdate {
 					t.logger.Infof("PriceUpdater: price stable (%s), skipping on-chain update", price.String())
+					t.aaStatus.pricePushed(price, "skipped", time.Now())
 					continue
 				}
 
 				if err := t.pushOraclePrice(ctx, price); err != nil {
 					t.logger.Warnf("PriceUpdater: push failed: %v", err)
+					t.aaStatus.pricePushed(price, "failed", time.Now())
 					continue
 				}
 				lastPushed = price
 				lastPushTime = time.Now()
+				t.aaStatus.pricePushed(price, "success", lastPushTime)
 			}
 		}
 	}()
@@ -143,7 +148,9 @@ func (t *ThanosStack) startPriceUpdater(ctx context.Context) {
 //	USDT (6 dec):  1.5 USDT/TON   → 1.5e18 (paymaster scales internally)
 //	USDC (6 dec):  1.5 USDC/TON   → 1.5e18
 func (t *ThanosStack) fetchOraclePrice(feeToken string) (*big.Int, error) {
+	start := time.Now()
 	data, err := fetchCoinGeckoPrice()
+	t.aaStatus.priceFetched(time.Since(start), err)
 	if err != nil {
 		return nil, fmt.Errorf("CoinGecko fetch: %w", err)
 	}
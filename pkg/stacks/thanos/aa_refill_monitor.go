@@ -91,21 +91,6 @@ func (t *ThanosStack) checkAndRefillEntryPoint(ctx context.Context) error {
 	refillValue := new(big.Int).SetUint64(refillAmountWei)
 	warnThreshold := new(big.Int).SetUint64(adminWarnThresholdWei)
 
-	if deposit.Cmp(threshold) >= 0 {
-		// Enough balance — no action needed.
-		t.logger.Infof("EntryPoint deposit OK: %s wei (threshold=%s)", deposit.String(), threshold.String())
-		return nil
-	}
-
-	t.logger.Infof("EntryPoint deposit low: %s wei — triggering refill (%s wei)", deposit.String(), refillValue.String())
-
-	// Guard against overlapping refill transactions.
-	if !refillMu.TryLock() {
-		t.logger.Infof("EntryPoint refill already in progress — skipping")
-		return nil
-	}
-	defer refillMu.Unlock()
-
 	// Derive admin address from private key.
 	privKey, err := crypto.HexToECDSA(strings.TrimPrefix(t.deployConfig.AdminPrivateKey, "0x"))
 	if err != nil {
@@ -119,7 +104,25 @@ func (t *ThanosStack) checkAndRefillEntryPoint(ctx context.Context) error {
 		if adminBalance.Cmp(warnThreshold) < 0 {
 			t.logger.Warnf("admin wallet balance is low: %s wei", adminBalance.String())
 		}
+	} else {
+		adminBalance = nil
 	}
+	t.aaStatus.refillChecked(deposit, adminBalance, time.Now())
+
+	if deposit.Cmp(threshold) >= 0 {
+		// Enough balance — no action needed.
+		t.logger.Infof("EntryPoint deposit OK: %s wei (threshold=%s)", deposit.String(), threshold.String())
+		return nil
+	}
+
+	t.logger.Infof("EntryPoint deposit low: %s wei — triggering refill (%s wei)", deposit.String(), refillValue.String())
+
+	// Guard against overlapping refill transactions.
+	if !refillMu.TryLock() {
+		t.logger.Infof("EntryPoint refill already in progress — skipping")
+		return nil
+	}
+	defer refillMu.Unlock()
 
 	l2ChainID, err := l2Client.ChainID(ctx)
 	if err != nil {
@@ -163,7 +166,9 @@ func (t *ThanosStack) checkAndRefillEntryPoint(ctx context.Context) error {
 	copy(calldata[:4], selector)
 	copy(calldata[16:36], paymaster.Bytes())
 
-	if _, err := sendTxAndWait(entryPoint, refillValue, calldata); err != nil {
+	_, err = sendTxAndWait(entryPoint, refillValue, calldata)
+	t.aaStatus.refilled(err)
+	if err != nil {
 		return fmt.Errorf("depositTo failed: %w", err)
 	}
 
@@ -0,0 +1,401 @@
+package thanos
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// Slack, Discord, PagerDuty and webhook channels each get their own receiver, named after the channel type,
+// and a subscription route with `continue: true` so they are notified in addition to the default receiver.
+// See alert_routing.go for the order of the routing tree.
+
+// routedChannelConfigKeys maps each routed channel to its AlertManager receiver config key
+var routedChannelConfigKeys = map[string]string{
+	constants.ChannelSlack:     "slack_configs",
+	constants.ChannelDiscord:   "discord_configs",
+	constants.ChannelPagerDuty: "pagerduty_configs",
+	constants.ChannelWebhook:   "webhook_configs",
+}
+
+// routedChannelReceiverConfig returns the AlertManager receiver config and severities of a routed channel,
+// or nil when the channel is disabled
+func routedChannelReceiverConfig(channelType string, config types.AlertManagerConfig, templates map[string]string) (map[string]interface{}, []string) {
+	switch channelType {
+	case constants.ChannelSlack:
+		if !config.Slack.Enabled {
+			return nil, nil
+		}
+		slackConfig := map[string]interface{}{
+			"api_url":       config.Slack.WebhookURL,
+			"send_resolved": true,
+			"title":         templates["chat_title"],
+			"text":          templates["chat_text"],
+		}
+		if config.Slack.Channel != "" {
+			slackConfig["channel"] = config.Slack.Channel
+		}
+		return slackConfig, config.Slack.Severities
+	case constants.ChannelDiscord:
+		if !config.Discord.Enabled {
+			return nil, nil
+		}
+		return map[string]interface{}{
+			"webhook_url":   config.Discord.WebhookURL,
+			"send_resolved": true,
+			"title":         templates["chat_title"],
+			"message":       templates["chat_text"],
+		}, config.Discord.Severities
+	case constants.ChannelPagerDuty:
+		if !config.PagerDuty.Enabled {
+			return nil, nil
+		}
+		return map[string]interface{}{
+			"routing_key":   config.PagerDuty.RoutingKey,
+			"send_resolved": true,
+			"severity":      "{{ .CommonLabels.severity }}",
+			"description":   templates["pagerduty_description"],
+		}, config.PagerDuty.Severities
+	case constants.ChannelWebhook:
+		if !config.Webhook.Enabled {
+			return nil, nil
+		}
+		webhookConfig := map[string]interface{}{
+			"url":           config.Webhook.URL,
+			"send_resolved": true,
+		}
+		if config.Webhook.BearerToken != "" {
+			webhookConfig["http_config"] = map[string]interface{}{
+				"authorization": map[string]interface{}{
+					"type":        "Bearer",
+					"credentials": config.Webhook.BearerToken,
+				},
+			}
+		}
+		return webhookConfig, config.Webhook.Severities
+	}
+	return nil, nil
+}
+
+// validateRoutedChannels checks the enabled Slack, Discord, PagerDuty and webhook channels
+func validateRoutedChannels(config types.AlertManagerConfig) error {
+	checks := []struct {
+		channel    string
+		enabled    bool
+		target     string
+		isURL      bool
+		severities []string
+	}{
+		{constants.ChannelSlack, config.Slack.Enabled, config.Slack.WebhookURL, true, config.Slack.Severities},
+		{constants.ChannelDiscord, config.Discord.Enabled, config.Discord.WebhookURL, true, config.Discord.Severities},
+		{constants.ChannelPagerDuty, config.PagerDuty.Enabled, config.PagerDuty.RoutingKey, false, config.PagerDuty.Severities},
+		{constants.ChannelWebhook, config.Webhook.Enabled, config.Webhook.URL, true, config.Webhook.Severities},
+	}
+	for _, check := range checks {
+		if !check.enabled {
+			continue
+		}
+		if check.target == "" {
+			return fmt.Errorf("%s channel requires a webhook URL or routing key", check.channel)
+		}
+		if check.isURL {
+			parsed, err := url.Parse(check.target)
+			if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+				return fmt.Errorf("invalid %s webhook URL: %s", check.channel, check.target)
+			}
+		}
+		if err := ValidateAlertSeverities(check.severities); err != nil {
+			return fmt.Errorf("%s channel: %w", check.channel, err)
+		}
+	}
+	return nil
+}
+
+// ValidateAlertSeverities checks that every severity is a known alert severity
+func ValidateAlertSeverities(severities []string) error {
+	for _, severity := range severities {
+		valid := false
+		for _, known := range constants.AlertSeverities {
+			if severity == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid severity %q (valid severities: %v)", severity, constants.AlertSeverities)
+		}
+	}
+	return nil
+}
+
+// ParseAlertSeverities parses a comma-separated severity list
+func ParseAlertSeverities(input string) ([]string, error) {
+	var severities []string
+	for _, severity := range strings.Split(input, ",") {
+		severity = strings.ToLower(strings.TrimSpace(severity))
+		if severity != "" {
+			severities = append(severities, severity)
+		}
+	}
+	if err := ValidateAlertSeverities(severities); err != nil {
+		return nil, err
+	}
+	return severities, nil
+}
+
+// setRoutedChannel adds or replaces the receiver and severity route of a routed channel in a generic AlertManager config
+func setRoutedChannel(amConfig map[string]interface{}, channelType string, receiverConfig map[string]interface{}, severities []string) {
+	removeRoutedChannelReceiver(amConfig, channelType)
+	receivers, _ := amConfig["receivers"].([]interface{})
+	amConfig["receivers"] = append(receivers, map[string]interface{}{
+		"name":                               channelType,
+		routedChannelConfigKeys[channelType]: []interface{}{receiverConfig},
+	})
+
+	matchers := []interface{}{channelSubscriptionMatcher}
+	if len(severities) > 0 {
+		matchers = append(matchers, fmt.Sprintf(`severity=~"%s"`, strings.Join(severities, "|")))
+	}
+	channelRoute := map[string]interface{}{
+		"receiver": channelType,
+		"matchers": matchers,
+		"continue": true,
+	}
+	rebuildAlertRoutes(amConfig, nil, func(channels []interface{}) []interface{} {
+		return append(withoutReceiverRoute(channels, channelType), channelRoute)
+	})
+}
+
+// removeRoutedChannel removes the receiver and route of a routed channel from a generic AlertManager config
+func removeRoutedChannel(amConfig map[string]interface{}, channelType string) {
+	removeRoutedChannelReceiver(amConfig, channelType)
+	rebuildAlertRoutes(amConfig, nil, func(channels []interface{}) []interface{} {
+		return withoutReceiverRoute(channels, channelType)
+	})
+}
+
+func removeRoutedChannelReceiver(amConfig map[string]interface{}, channelType string) {
+	receivers, _ := amConfig["receivers"].([]interface{})
+	updated := make([]interface{}, 0, len(receivers))
+	for _, r := range receivers {
+		if receiver, ok := r.(map[string]interface{}); ok && receiver["name"] == channelType {
+			continue
+		}
+		updated = append(updated, r)
+	}
+	amConfig["receivers"] = updated
+}
+
+func withoutReceiverRoute(routes []interface{}, receiver string) []interface{} {
+	out := make([]interface{}, 0, len(routes))
+	for _, r := range routes {
+		if child, ok := r.(map[string]interface{}); ok && child["receiver"] == receiver {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// routesOf returns the child routes of a route, whichever slice type they were built with
+func routesOf(route map[string]interface{}) []interface{} {
+	switch routes := route["routes"].(type) {
+	case []interface{}:
+		return routes
+	case []map[string]interface{}:
+		out := make([]interface{}, 0, len(routes))
+		for _, r := range routes {
+			out = append(out, r)
+		}
+		return out
+	}
+	return nil
+}
+
+func isCatchAllRoute(route map[string]interface{}, receiver string) bool {
+	if route["receiver"] != receiver {
+		return false
+	}
+	for _, key := range []string{"match", "match_re", "matchers"} {
+		if _, ok := route[key]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// addRoutedChannels adds every enabled routed channel of the config to a generic AlertManager config
+func addRoutedChannels(amConfig map[string]interface{}, config types.AlertManagerConfig, templates map[string]string) {
+	for _, channelType := range constants.RoutedChannels {
+		if receiverConfig, severities := routedChannelReceiverConfig(channelType, config, templates); receiverConfig != nil {
+			setRoutedChannel(amConfig, channelType, receiverConfig, severities)
+		}
+	}
+}
+
+// routedChannelSeverities returns the severities routed to a channel, empty meaning all severities
+func routedChannelSeverities(amConfig map[string]interface{}, channelType string) []string {
+	route, _ := amConfig["route"].(map[string]interface{})
+	for _, r := range routesOf(route) {
+		child, ok := r.(map[string]interface{})
+		if !ok || child["receiver"] != channelType {
+			continue
+		}
+		matchers, _ := child["matchers"].([]interface{})
+		for _, m := range matchers {
+			matcher, _ := m.(string)
+			if value, ok := strings.CutPrefix(matcher, `severity=~"`); ok {
+				return strings.Split(strings.TrimSuffix(value, `"`), "|")
+			}
+		}
+	}
+	return nil
+}
+
+// routedChannelsFromConfig reads the Slack, Discord, PagerDuty and webhook channels from an AlertManager config
+func routedChannelsFromConfig(config string) types.AlertManagerConfig {
+	var result types.AlertManagerConfig
+
+	var parsed types.AlertManagerParsedConfig
+	var generic map[string]interface{}
+	if err := yaml.Unmarshal([]byte(config), &parsed); err != nil {
+		return result
+	}
+	if err := yaml.Unmarshal([]byte(config), &generic); err != nil {
+		return result
+	}
+
+	for _, receiver := range parsed.Receivers {
+		switch {
+		case receiver.Name == constants.ChannelSlack && len(receiver.SlackConfigs) > 0:
+			result.Slack = types.SlackConfig{
+				Enabled:    true,
+				WebhookURL: receiver.SlackConfigs[0].APIURL,
+				Channel:    receiver.SlackConfigs[0].Channel,
+				Severities: routedChannelSeverities(generic, constants.ChannelSlack),
+			}
+		case receiver.Name == constants.ChannelDiscord && len(receiver.DiscordConfigs) > 0:
+			result.Discord = types.DiscordConfig{
+				Enabled:    true,
+				WebhookURL: receiver.DiscordConfigs[0].WebhookURL,
+				Severities: routedChannelSeverities(generic, constants.ChannelDiscord),
+			}
+		case receiver.Name == constants.ChannelPagerDuty && len(receiver.PagerdutyConfigs) > 0:
+			result.PagerDuty = types.PagerDutyConfig{
+				Enabled:    true,
+				RoutingKey: receiver.PagerdutyConfigs[0].RoutingKey,
+				Severities: routedChannelSeverities(generic, constants.ChannelPagerDuty),
+			}
+		case receiver.Name == constants.ChannelWebhook && len(receiver.WebhookConfigs) > 0:
+			result.Webhook = types.WebhookConfig{
+				Enabled:     true,
+				URL:         receiver.WebhookConfigs[0].URL,
+				BearerToken: receiver.WebhookConfigs[0].HTTPConfig.Authorization.Credentials,
+				Severities:  routedChannelSeverities(generic, constants.ChannelWebhook),
+			}
+		}
+	}
+	return result
+}
+
+// GetRoutedChannelConfiguration extracts the status of a Slack, Discord, PagerDuty or webhook channel
+func (a *AlertCustomization) GetRoutedChannelConfiguration(config string, channelType string) types.RoutedChannelConfiguration {
+	channels := routedChannelsFromConfig(config)
+	switch channelType {
+	case constants.ChannelSlack:
+		if channels.Slack.Enabled {
+			target := maskSecretURL(channels.Slack.WebhookURL)
+			if channels.Slack.Channel != "" {
+				target = fmt.Sprintf("%s (%s)", target, channels.Slack.Channel)
+			}
+			return types.RoutedChannelConfiguration{Enabled: true, Target: target, Severities: channels.Slack.Severities}
+		}
+	case constants.ChannelDiscord:
+		if channels.Discord.Enabled {
+			return types.RoutedChannelConfiguration{Enabled: true, Target: maskSecretURL(channels.Discord.WebhookURL), Severities: channels.Discord.Severities}
+		}
+	case constants.ChannelPagerDuty:
+		if channels.PagerDuty.Enabled {
+			key := channels.PagerDuty.RoutingKey
+			return types.RoutedChannelConfiguration{Enabled: true, Target: "routing key " + key[:min(len(key), 6)] + "...", Severities: channels.PagerDuty.Severities}
+		}
+	case constants.ChannelWebhook:
+		if channels.Webhook.Enabled {
+			return types.RoutedChannelConfiguration{Enabled: true, Target: channels.Webhook.URL, Severities: channels.Webhook.Severities}
+		}
+	}
+	return types.RoutedChannelConfiguration{Enabled: false}
+}
+
+// maskSecretURL hides the path of webhook URLs, which carries the secret for Slack and Discord
+func maskSecretURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return "***"
+	}
+	return fmt.Sprintf("%s://%s/***", parsed.Scheme, parsed.Host)
+}
+
+// UpdateRoutedChannelConfig adds or replaces a Slack, Discord, PagerDuty or webhook channel in AlertManager.
+// Only the section of config matching channelType is used.
+func (a *AlertCustomization) UpdateRoutedChannelConfig(ctx context.Context, channelType string, config types.AlertManagerConfig) error {
+	if !constants.IsRoutedChannel(channelType) {
+		return fmt.Errorf("channel %s does not support severity routing", channelType)
+	}
+	if err := validateRoutedChannels(config); err != nil {
+		return err
+	}
+
+	currentConfig, err := a.GetAlertManagerConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current AlertManager config: %w", err)
+	}
+	var amConfig map[string]interface{}
+	if err := yaml.Unmarshal([]byte(currentConfig), &amConfig); err != nil {
+		return fmt.Errorf("failed to parse current AlertManager config: %w", err)
+	}
+
+	receiverConfig, severities := routedChannelReceiverConfig(channelType, config, a.Stack.generateAlertTemplates(a.grafanaURL(ctx)))
+	if receiverConfig == nil {
+		return fmt.Errorf("%s channel is not enabled in the given configuration", channelType)
+	}
+	setRoutedChannel(amConfig, channelType, receiverConfig, severities)
+
+	updatedYAML, err := yaml.Marshal(amConfig)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated config: %w", err)
+	}
+	if err := a.applyAlertManagerConfig(ctx, string(updatedYAML)); err != nil {
+		return fmt.Errorf("failed to apply AlertManager config: %w", err)
+	}
+	return nil
+}
+
+// RemoveRoutedChannelConfig removes a Slack, Discord, PagerDuty or webhook channel from AlertManager
+func (a *AlertCustomization) RemoveRoutedChannelConfig(ctx context.Context, channelType string) error {
+	currentConfig, err := a.GetAlertManagerConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current AlertManager config: %w", err)
+	}
+	var amConfig map[string]interface{}
+	if err := yaml.Unmarshal([]byte(currentConfig), &amConfig); err != nil {
+		return fmt.Errorf("failed to parse current AlertManager config: %w", err)
+	}
+
+	removeRoutedChannel(amConfig, channelType)
+
+	updatedYAML, err := yaml.Marshal(amConfig)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated config: %w", err)
+	}
+	if err := a.applyAlertManagerConfig(ctx, string(updatedYAML)); err != nil {
+		return fmt.Errorf("failed to apply AlertManager config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package thanos
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+func TestGenerateAlertManagerSecretConfig_RoutedChannels(t *testing.T) {
+	stack := &ThanosStack{}
+	config := &types.MonitoringConfig{
+		AlertManager: types.AlertManagerConfig{
+			Slack:     types.SlackConfig{Enabled: true, WebhookURL: "https://hooks.slack.com/services/T/B/X", Channel: "#alerts"},
+			PagerDuty: types.PagerDutyConfig{Enabled: true, RoutingKey: "abcdef123456", Severities: []string{constants.AlertSeverityCritical}},
+		},
+	}
+
+	encoded, err := stack.generateAlertManagerSecretConfig(config, "http://grafana")
+	require.NoError(t, err)
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+
+	var amConfig map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(data, &amConfig))
+
+	routes := routesOf(amConfig["route"].(map[string]interface{}))
+	require.Len(t, routes, 4, "two channel routes, Watchdog and the catch-all")
+	last := routes[len(routes)-1].(map[string]interface{})
+	require.True(t, isCatchAllRoute(last, mainAlertReceiverName))
+
+	require.Equal(t, []string{constants.AlertSeverityCritical}, routedChannelSeverities(amConfig, constants.ChannelPagerDuty))
+	require.Nil(t, routedChannelSeverities(amConfig, constants.ChannelSlack))
+
+	routed := routedChannelsFromConfig(string(data))
+	require.True(t, routed.Slack.Enabled)
+	require.Equal(t, "#alerts", routed.Slack.Channel)
+	require.Equal(t, "abcdef123456", routed.PagerDuty.RoutingKey)
+	require.False(t, routed.Discord.Enabled)
+
+	ac := &AlertCustomization{}
+	require.Equal(t, "Enabled", ac.GetChannelStatus(string(data), constants.ChannelSlack))
+	require.Equal(t, "Disabled", ac.GetChannelStatus(string(data), constants.ChannelWebhook))
+	require.Equal(t, "https://hooks.slack.com/*** (#alerts)", ac.GetRoutedChannelConfiguration(string(data), constants.ChannelSlack).Target)
+}
+
+func TestSetAndRemoveRoutedChannel(t *testing.T) {
+	amConfig := map[string]interface{}{
+		"route": map[string]interface{}{
+			"receiver": mainAlertReceiverName,
+			"routes": []interface{}{
+				map[string]interface{}{"match": map[string]interface{}{"alertname": "Watchdog"}, "receiver": "null"},
+			},
+		},
+		"receivers": []interface{}{
+			map[string]interface{}{"name": mainAlertReceiverName},
+			map[string]interface{}{"name": "null"},
+		},
+	}
+
+	setRoutedChannel(amConfig, constants.ChannelWebhook, map[string]interface{}{"url": "https://a"}, []string{"critical", "warning"})
+	// Setting the channel again replaces it instead of duplicating the receiver and route
+	setRoutedChannel(amConfig, constants.ChannelWebhook, map[string]interface{}{"url": "https://b"}, nil)
+
+	route := amConfig["route"].(map[string]interface{})
+	routes := routesOf(route)
+	require.Len(t, routes, 3)
+	first := routes[0].(map[string]interface{})
+	require.Equal(t, constants.ChannelWebhook, first["receiver"])
+	require.Equal(t, true, first["continue"])
+	require.Equal(t, []interface{}{`alertname!="Watchdog"`}, first["matchers"])
+	require.Len(t, amConfig["receivers"], 3)
+
+	removeRoutedChannel(amConfig, constants.ChannelWebhook)
+	require.Len(t, routesOf(route), 1, "the catch-all is dropped with the last channel")
+	require.Len(t, amConfig["receivers"], 2)
+}
+
+func TestValidateRoutedChannels(t *testing.T) {
+	require.NoError(t, validateRoutedChannels(types.AlertManagerConfig{}))
+	require.Error(t, validateRoutedChannels(types.AlertManagerConfig{Discord: types.DiscordConfig{Enabled: true, WebhookURL: "discord.com/api"}}))
+	require.Error(t, validateRoutedChannels(types.AlertManagerConfig{PagerDuty: types.PagerDutyConfig{Enabled: true}}))
+	require.Error(t, validateRoutedChannels(types.AlertManagerConfig{
+		Webhook: types.WebhookConfig{Enabled: true, URL: "https://example.com/hook", Severities: []string{"page"}},
+	}))
+
+	severities, err := ParseAlertSeverities(" Critical, warning ,")
+	require.NoError(t, err)
+	require.Equal(t, []string{"critical", "warning"}, severities)
+}
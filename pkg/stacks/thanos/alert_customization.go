@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -20,10 +21,21 @@ import (
 // AlertCustomization provides alert/notification management for ThanosStack
 type AlertCustomization struct {
 	Stack *ThanosStack
+
+	// localPath is set for local deployments, whose monitoring runs in docker compose
+	localPath string
 }
 
 // GetAlertManagerConfig retrieves AlertManager config YAML (decompressed)
 func (a *AlertCustomization) GetAlertManagerConfig(ctx context.Context) (string, error) {
+	if a.isLocal() {
+		data, err := os.ReadFile(a.localMonitoringFile(localAlertManagerConfigFile))
+		if err != nil {
+			return "", fmt.Errorf("failed to read local AlertManager config: %w", err)
+		}
+		return string(data), nil
+	}
+
 	// Try multiple methods to find the correct AlertManager secret
 	var secretName string
 
@@ -90,6 +102,22 @@ func (a *AlertCustomization) GetChannelStatus(config string, channelType string)
 			if len(receiver.TelegramConfigs) > 0 {
 				return "Enabled"
 			}
+		case constants.ChannelSlack:
+			if len(receiver.SlackConfigs) > 0 {
+				return "Enabled"
+			}
+		case constants.ChannelDiscord:
+			if len(receiver.DiscordConfigs) > 0 {
+				return "Enabled"
+			}
+		case constants.ChannelPagerDuty:
+			if len(receiver.PagerdutyConfigs) > 0 {
+				return "Enabled"
+			}
+		case constants.ChannelWebhook:
+			if len(receiver.WebhookConfigs) > 0 {
+				return "Enabled"
+			}
 		default:
 			continue
 		}
@@ -99,14 +127,26 @@ func (a *AlertCustomization) GetChannelStatus(config string, channelType string)
 
 // GetPrometheusRules retrieves all PrometheusRule items in the monitoring namespace
 func (a *AlertCustomization) GetPrometheusRules(ctx context.Context) ([]types.AlertRule, error) {
-	output, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "yaml")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get PrometheusRules: %w", err)
-	}
-
 	var ruleList types.PrometheusRuleList
-	if err := yaml.Unmarshal([]byte(output), &ruleList); err != nil {
-		return nil, fmt.Errorf("failed to parse PrometheusRule YAML: %w", err)
+	if a.isLocal() {
+		data, err := os.ReadFile(a.localMonitoringFile(localAlertRulesFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read local alert rules: %w", err)
+		}
+		var rule types.PrometheusRule
+		if err := yaml.Unmarshal(data, &rule.Spec); err != nil {
+			return nil, fmt.Errorf("failed to parse local alert rules: %w", err)
+		}
+		ruleList.Items = append(ruleList.Items, rule)
+	} else {
+		output, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "yaml")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get PrometheusRules: %w", err)
+		}
+
+		if err := yaml.Unmarshal([]byte(output), &ruleList); err != nil {
+			return nil, fmt.Errorf("failed to parse PrometheusRule YAML: %w", err)
+		}
 	}
 
 	if len(ruleList.Items) == 0 {
@@ -126,7 +166,7 @@ func (a *AlertCustomization) GetPrometheusRules(ctx context.Context) ([]types.Al
 // EnableRule enables a specific alert rule by name
 func (a *AlertCustomization) EnableRule(ctx context.Context, ruleName string) error {
 	// Get the PrometheusRule name first
-	ruleNameOutput, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].metadata.name}")
+	ruleNameOutput, err := a.prometheusRuleName(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get PrometheusRule name: %w", err)
 	}
@@ -151,12 +191,10 @@ func (a *AlertCustomization) EnableRule(ctx context.Context, ruleName string) er
 	}
 
 	// Get all alert names to find the correct insertion index
-	output, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].spec.groups[0].rules[*].alert}")
+	alertNames, err := a.alertRuleNames(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get PrometheusRule alerts: %w", err)
 	}
-
-	alertNames := strings.Fields(output)
 	insertIndex := len(alertNames) // Insert at the end
 
 	// Create a JSON patch to add the rule
@@ -179,7 +217,7 @@ func (a *AlertCustomization) EnableRule(ctx context.Context, ruleName string) er
 	}
 
 	// Apply the JSON patch
-	if _, err := utils.ExecuteCommand(ctx, "kubectl", "patch", "prometheusrule", ruleNameOutput, "-n", constants.MonitoringNamespace, "--type=json", "-p", patchData); err != nil {
+	if err := a.patchPrometheusRule(ctx, ruleNameOutput, patchData); err != nil {
 		return fmt.Errorf("failed to apply updated PrometheusRule: %w", err)
 	}
 
@@ -189,7 +227,7 @@ func (a *AlertCustomization) EnableRule(ctx context.Context, ruleName string) er
 // DisableRule disables a specific alert rule by name
 func (a *AlertCustomization) DisableRule(ctx context.Context, ruleName string) error {
 	// Get the PrometheusRule name first
-	ruleNameOutput, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].metadata.name}")
+	ruleNameOutput, err := a.prometheusRuleName(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get PrometheusRule name: %w", err)
 	}
@@ -199,12 +237,10 @@ func (a *AlertCustomization) DisableRule(ctx context.Context, ruleName string) e
 	}
 
 	// Get all alert names and find the index of the target rule
-	output, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].spec.groups[0].rules[*].alert}")
+	alertNames, err := a.alertRuleNames(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get PrometheusRule alerts: %w", err)
 	}
-
-	alertNames := strings.Fields(output)
 	targetIndex := -1
 
 	for i, alertName := range alertNames {
@@ -222,7 +258,7 @@ func (a *AlertCustomization) DisableRule(ctx context.Context, ruleName string) e
 	patchData := fmt.Sprintf(`[{"op":"remove","path":"/spec/groups/0/rules/%d"}]`, targetIndex)
 
 	// Apply the JSON patch
-	if _, err := utils.ExecuteCommand(ctx, "kubectl", "patch", "prometheusrule", ruleNameOutput, "-n", constants.MonitoringNamespace, "--type=json", "-p", patchData); err != nil {
+	if err := a.patchPrometheusRule(ctx, ruleNameOutput, patchData); err != nil {
 		return fmt.Errorf("failed to apply updated PrometheusRule: %w", err)
 	}
 
@@ -232,7 +268,7 @@ func (a *AlertCustomization) DisableRule(ctx context.Context, ruleName string) e
 // ResetPrometheusRules resets all configurable alert rules to default values
 func (a *AlertCustomization) ResetPrometheusRules(ctx context.Context) error {
 	// Get the PrometheusRule name first
-	ruleNameOutput, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].metadata.name}")
+	ruleNameOutput, err := a.prometheusRuleName(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get PrometheusRule name: %w", err)
 	}
@@ -242,13 +278,11 @@ func (a *AlertCustomization) ResetPrometheusRules(ctx context.Context) error {
 	}
 
 	// Get all alert names to find the indices of configurable rules
-	output, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].spec.groups[0].rules[*].alert}")
+	alertNames, err := a.alertRuleNames(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get PrometheusRule alerts: %w", err)
 	}
 
-	alertNames := strings.Fields(output)
-
 	// Default values for configurable rules
 	defaultValues := map[string]string{
 		constants.AlertOpBatcherBalanceCritical:  "0.01",
@@ -341,7 +375,7 @@ func (a *AlertCustomization) ResetPrometheusRules(ctx context.Context) error {
 	patchData += `]`
 
 	// Apply the JSON patch
-	if _, err := utils.ExecuteCommand(ctx, "kubectl", "patch", "prometheusrule", ruleNameOutput, "-n", constants.MonitoringNamespace, "--type=json", "-p", patchData); err != nil {
+	if err := a.patchPrometheusRule(ctx, ruleNameOutput, patchData); err != nil {
 		return fmt.Errorf("failed to apply updated PrometheusRule: %w", err)
 	}
 
@@ -351,7 +385,7 @@ func (a *AlertCustomization) ResetPrometheusRules(ctx context.Context) error {
 // UpdatePrometheusRule updates a specific rule's expression value
 func (a *AlertCustomization) UpdatePrometheusRule(ctx context.Context, ruleName, newValue string) error {
 	// Get the PrometheusRule name first
-	ruleNameOutput, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].metadata.name}")
+	ruleNameOutput, err := a.prometheusRuleName(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get PrometheusRule name: %w", err)
 	}
@@ -361,12 +395,10 @@ func (a *AlertCustomization) UpdatePrometheusRule(ctx context.Context, ruleName,
 	}
 
 	// Get all alert names to find the index of the target rule
-	output, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].spec.groups[0].rules[*].alert}")
+	alertNames, err := a.alertRuleNames(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get PrometheusRule alerts: %w", err)
 	}
-
-	alertNames := strings.Fields(output)
 	targetIndex := -1
 
 	for i, alertName := range alertNames {
@@ -401,7 +433,7 @@ func (a *AlertCustomization) UpdatePrometheusRule(ctx context.Context, ruleName,
 	}
 
 	// Apply the JSON patch
-	if _, err := utils.ExecuteCommand(ctx, "kubectl", "patch", "prometheusrule", ruleNameOutput, "-n", constants.MonitoringNamespace, "--type=json", "-p", patchData); err != nil {
+	if err := a.patchPrometheusRule(ctx, ruleNameOutput, patchData); err != nil {
 		return fmt.Errorf("failed to apply updated PrometheusRule: %w", err)
 	}
 
@@ -507,15 +539,8 @@ func (a *AlertCustomization) UpdateEmailConfig(ctx context.Context, smtpServer,
 		receiversList = append(receiversList, mainReceiver)
 	}
 
-	// Get Grafana URL for templates by finding the actual Helm release
-	helmReleaseOutput, _ := utils.ExecuteCommand(ctx, "kubectl", "get", "ingress", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].metadata.name}")
-
-	// Extract Helm release name from ingress name (remove -grafana suffix)
-	helmReleaseName := strings.TrimSuffix(helmReleaseOutput, "-grafana")
-	grafanaURL := a.Stack.getGrafanaURL(ctx, &types.MonitoringConfig{
-		Namespace:       constants.MonitoringNamespace,
-		HelmReleaseName: helmReleaseName,
-	})
+	// Get Grafana URL for templates
+	grafanaURL := a.grafanaURL(ctx)
 
 	// Add email_configs to the receiver
 	emailConfigs := []interface{}{}
@@ -694,8 +719,7 @@ func (a *AlertCustomization) UpdateTelegramConfig(ctx context.Context, botToken,
 	}
 
 	// Get Grafana URL for templates
-	grafanaURL := a.Stack.getGrafanaURL(ctx, &types.MonitoringConfig{})
-	templates := a.Stack.generateAlertTemplates(grafanaURL)
+	templates := a.Stack.generateAlertTemplates(a.grafanaURL(ctx))
 
 	// Add telegram_configs to the receiver
 	telegramConfig := map[string]interface{}{
@@ -782,6 +806,10 @@ func (a *AlertCustomization) RemoveTelegramConfig(ctx context.Context) error {
 
 // applyAlertManagerConfig applies the updated AlertManager configuration
 func (a *AlertCustomization) applyAlertManagerConfig(ctx context.Context, configYAML string) error {
+	if a.isLocal() {
+		return a.applyLocalMonitoringFile(ctx, localAlertManagerConfigFile, []byte(configYAML), false)
+	}
+
 	// Base64 encode the configuration
 	encodedConfig := base64.StdEncoding.EncodeToString([]byte(configYAML))
 
@@ -878,13 +906,8 @@ func (a *AlertCustomization) GetTelegramConfiguration(config string) types.Teleg
 // GetCurrentRuleValue gets the current value of a rule from the configuration
 func (a *AlertCustomization) GetCurrentRuleValue(ctx context.Context, ruleName string) string {
 	// Get all alert names and expressions from the PrometheusRule
-	output, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].spec.groups[0].rules[*].alert}")
-	if err != nil {
-		return ""
-	}
-
-	alertNames := strings.Fields(output)
-	if len(alertNames) == 0 {
+	alertNames, err := a.alertRuleNames(ctx)
+	if err != nil || len(alertNames) == 0 {
 		return ""
 	}
 
@@ -902,7 +925,7 @@ func (a *AlertCustomization) GetCurrentRuleValue(ctx context.Context, ruleName s
 	}
 
 	// Get the expression for the target rule
-	exprOutput, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", fmt.Sprintf("jsonpath={.items[0].spec.groups[0].rules[%d].expr}", targetIndex))
+	exprOutput, err := a.alertRuleExpr(ctx, targetIndex)
 	if err != nil {
 		return ""
 	}
@@ -972,13 +995,12 @@ func (a *AlertCustomization) GetCurrentRuleValue(ctx context.Context, ruleName s
 // IsRuleEnabled checks if a specific rule is currently enabled
 func (a *AlertCustomization) IsRuleEnabled(ctx context.Context, ruleName string) (bool, error) {
 	// Get all alert names from the PrometheusRule
-	output, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].spec.groups[0].rules[*].alert}")
+	alertNames, err := a.alertRuleNames(ctx)
 	if err != nil {
 		return false, fmt.Errorf("failed to get PrometheusRule alerts: %w", err)
 	}
 
-	// Split the output and check if the rule name exists
-	alertNames := strings.Fields(output)
+	// Check if the rule name exists
 	for _, alertName := range alertNames {
 		if alertName == ruleName {
 			return true, nil
@@ -987,3 +1009,82 @@ func (a *AlertCustomization) IsRuleEnabled(ctx context.Context, ruleName string)
 
 	return false, nil
 }
+
+// prometheusRuleName returns the name of the PrometheusRule holding the alerts
+func (a *AlertCustomization) prometheusRuleName(ctx context.Context) (string, error) {
+	if a.isLocal() {
+		return localAlertRuleObjectName, nil
+	}
+	return utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].metadata.name}")
+}
+
+// alertRuleNames returns the alert names of the first rule group in order
+func (a *AlertCustomization) alertRuleNames(ctx context.Context) ([]string, error) {
+	if a.isLocal() {
+		obj, err := a.readLocalRuleObject()
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, r := range localFirstGroupRules(obj) {
+			if rule, ok := r.(map[string]interface{}); ok {
+				if name, _ := rule["alert"].(string); name != "" {
+					names = append(names, name)
+				}
+			}
+		}
+		return names, nil
+	}
+	output, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].spec.groups[0].rules[*].alert}")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+// alertRuleExpr returns the expression of the rule at index in the first rule group
+func (a *AlertCustomization) alertRuleExpr(ctx context.Context, index int) (string, error) {
+	if a.isLocal() {
+		obj, err := a.readLocalRuleObject()
+		if err != nil {
+			return "", err
+		}
+		rules := localFirstGroupRules(obj)
+		if index < 0 || index >= len(rules) {
+			return "", fmt.Errorf("rule index %d out of range", index)
+		}
+		rule, _ := rules[index].(map[string]interface{})
+		expr, _ := rule["expr"].(string)
+		return expr, nil
+	}
+	return utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", fmt.Sprintf("jsonpath={.items[0].spec.groups[0].rules[%d].expr}", index))
+}
+
+// patchPrometheusRule applies a JSON patch to the PrometheusRule holding the alerts
+func (a *AlertCustomization) patchPrometheusRule(ctx context.Context, name, patch string) error {
+	if a.isLocal() {
+		obj, err := a.readLocalRuleObject()
+		if err != nil {
+			return err
+		}
+		if err := applyJSONPatch(obj, patch); err != nil {
+			return err
+		}
+		return a.writeLocalRuleObject(ctx, obj, false)
+	}
+	_, err := utils.ExecuteCommand(ctx, "kubectl", "patch", "prometheusrule", name, "-n", constants.MonitoringNamespace, "--type=json", "-p", patch)
+	return err
+}
+
+// grafanaURL returns the dashboard URL linked from notifications
+func (a *AlertCustomization) grafanaURL(ctx context.Context) string {
+	if a.isLocal() {
+		return localGrafanaDashboardURL
+	}
+	// Extract Helm release name from ingress name (remove -grafana suffix)
+	helmReleaseOutput, _ := utils.ExecuteCommand(ctx, "kubectl", "get", "ingress", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[0].metadata.name}")
+	return a.Stack.getGrafanaURL(ctx, &types.MonitoringConfig{
+		Namespace:       constants.MonitoringNamespace,
+		HelmReleaseName: strings.TrimSuffix(helmReleaseOutput, "-grafana"),
+	})
+}
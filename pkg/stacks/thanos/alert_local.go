@@ -0,0 +1,253 @@
+package thanos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// localMonitoringService describes the container that loads a local monitoring file
+type localMonitoringService struct {
+	name    string
+	port    int
+	checker []string
+}
+
+var localMonitoringServices = map[string]localMonitoringService{
+	localAlertRulesFile:         {name: "prometheus", port: 9090, checker: []string{"promtool", "check", "rules"}},
+	localAlertManagerConfigFile: {name: "alertmanager", port: 9093, checker: []string{"amtool", "check-config"}},
+}
+
+// NewAlertCustomization returns the alert manager for the deployment in deploymentPath.
+// Local deployments use the Prometheus and AlertManager containers of the compose file;
+// otherwise the monitoring plugin in the cluster is used and must be installed.
+func NewAlertCustomization(ctx context.Context, deploymentPath string) (*AlertCustomization, error) {
+	if _, err := os.Stat(filepath.Join(deploymentPath, localComposeFileName)); err != nil {
+		if err := utils.CheckMonitoringPluginInstalled(ctx); err != nil {
+			return nil, err
+		}
+		return &AlertCustomization{}, nil
+	}
+
+	a := &AlertCustomization{
+		Stack:     &ThanosStack{deploymentPath: deploymentPath, logger: zap.NewNop().Sugar()},
+		localPath: deploymentPath,
+	}
+	for name := range localMonitoringServices {
+		if _, err := os.Stat(a.localMonitoringFile(name)); err != nil {
+			return nil, fmt.Errorf("local monitoring has no alerting configuration (%s is missing). Re-run 'trh-sdk deploy' to add the alert rules and AlertManager", name)
+		}
+	}
+	output, err := a.composeCommand(ctx, "ps", "--status", "running", "-q", "alertmanager")
+	if err != nil || strings.TrimSpace(output) == "" {
+		return nil, fmt.Errorf("local monitoring is not running. Start it with 'docker compose -f %s --profile monitoring up -d'", localComposeFileName)
+	}
+	return a, nil
+}
+
+func (a *AlertCustomization) isLocal() bool {
+	return a.localPath != ""
+}
+
+func (a *AlertCustomization) localMonitoringFile(name string) string {
+	return filepath.Join(a.localPath, "monitoring", name)
+}
+
+// composeCommand runs a docker compose command against the local deployment with the monitoring profile
+func (a *AlertCustomization) composeCommand(ctx context.Context, args ...string) (string, error) {
+	composeArgs := append([]string{"compose", "-f", filepath.Join(a.localPath, localComposeFileName), "--profile", "monitoring"}, args...)
+	return utils.ExecuteCommand(ctx, "docker", composeArgs...)
+}
+
+// applyLocalMonitoringFile checks a rule file or AlertManager config with promtool/amtool in its container,
+// then installs it in the monitoring volume and reloads the service. The previous file is restored if the
+// reload fails.
+func (a *AlertCustomization) applyLocalMonitoringFile(ctx context.Context, name string, content []byte, dryRun bool) error {
+	service, ok := localMonitoringServices[name]
+	if !ok {
+		return fmt.Errorf("unknown local monitoring file: %s", name)
+	}
+
+	tempFile, err := os.CreateTemp("", "trh-monitoring-*"+filepath.Ext(name))
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err := tempFile.Write(content); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	tempFile.Close()
+
+	candidate := ".candidate/" + filepath.Base(name)
+	if err := a.Stack.copyFilesToMonitoringVolume(ctx, map[string]string{candidate: tempFile.Name()}); err != nil {
+		return err
+	}
+	checkArgs := append([]string{"exec", "-T", service.name}, service.checker...)
+	checkArgs = append(checkArgs, "/monitoring/"+candidate)
+	if output, err := a.composeCommand(ctx, checkArgs...); err != nil {
+		return fmt.Errorf("%s validation failed: %w (%s)", name, err, strings.TrimSpace(output))
+	}
+	if dryRun {
+		return nil
+	}
+
+	path := a.localMonitoringFile(name)
+	previous, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if err := a.installLocalMonitoringFile(ctx, name, content); err != nil {
+		return err
+	}
+	if err := a.reloadLocalMonitoringService(ctx, service); err != nil {
+		if restoreErr := a.installLocalMonitoringFile(ctx, name, previous); restoreErr != nil {
+			return fmt.Errorf("%w (restoring the previous %s also failed: %v)", err, name, restoreErr)
+		}
+		_ = a.reloadLocalMonitoringService(ctx, service)
+		return fmt.Errorf("%w, the previous %s was restored", err, name)
+	}
+	return nil
+}
+
+func (a *AlertCustomization) installLocalMonitoringFile(ctx context.Context, name string, content []byte) error {
+	path := a.localMonitoringFile(name)
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return a.Stack.copyFilesToMonitoringVolume(ctx, map[string]string{name: path})
+}
+
+// reloadLocalMonitoringService asks Prometheus or AlertManager to reload its configuration.
+// The reload endpoint fails when the new configuration cannot be loaded.
+func (a *AlertCustomization) reloadLocalMonitoringService(ctx context.Context, service localMonitoringService) error {
+	output, err := a.composeCommand(ctx, "exec", "-T", service.name,
+		"wget", "-qO-", "--post-data=", fmt.Sprintf("http://localhost:%d/-/reload", service.port))
+	if err != nil {
+		return fmt.Errorf("failed to reload %s: %w (%s)", service.name, err, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// readLocalRuleObject wraps the local rule file in the shape of a PrometheusRule object
+func (a *AlertCustomization) readLocalRuleObject() (map[string]interface{}, error) {
+	data, err := os.ReadFile(a.localMonitoringFile(localAlertRulesFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local alert rules: %w", err)
+	}
+	var spec map[string]interface{}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse local alert rules: %w", err)
+	}
+	if spec == nil {
+		spec = map[string]interface{}{"groups": []interface{}{}}
+	}
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"name": localAlertRuleObjectName},
+		"spec":     spec,
+	}, nil
+}
+
+func (a *AlertCustomization) writeLocalRuleObject(ctx context.Context, obj map[string]interface{}, dryRun bool) error {
+	data, err := yaml.Marshal(obj["spec"])
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert rules: %w", err)
+	}
+	return a.applyLocalMonitoringFile(ctx, localAlertRulesFile, data, dryRun)
+}
+
+// localFirstGroupRules returns the rules of the first group, which holds the thanos-stack alerts
+func localFirstGroupRules(obj map[string]interface{}) []interface{} {
+	spec, _ := obj["spec"].(map[string]interface{})
+	groups, _ := spec["groups"].([]interface{})
+	if len(groups) == 0 {
+		return nil
+	}
+	group, _ := groups[0].(map[string]interface{})
+	rules, _ := group["rules"].([]interface{})
+	return rules
+}
+
+// applyJSONPatch applies the add, remove and replace operations of an RFC 6902 JSON patch to a generic object,
+// which is how the rule commands edit a PrometheusRule
+func applyJSONPatch(obj map[string]interface{}, patch string) error {
+	var ops []struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(patch), &ops); err != nil {
+		return fmt.Errorf("invalid JSON patch: %w", err)
+	}
+
+	for _, op := range ops {
+		segments := strings.Split(strings.TrimPrefix(op.Path, "/"), "/")
+		// Walk to the parent of the target, replacing slices in their parents as they change length
+		var parent interface{} = obj
+		var setParent func(interface{})
+		for _, segment := range segments[:len(segments)-1] {
+			switch node := parent.(type) {
+			case map[string]interface{}:
+				key := segment
+				setParent = func(v interface{}) { node[key] = v }
+				parent = node[key]
+			case []interface{}:
+				index, err := strconv.Atoi(segment)
+				if err != nil || index < 0 || index >= len(node) {
+					return fmt.Errorf("invalid path %s", op.Path)
+				}
+				setParent = func(v interface{}) { node[index] = v }
+				parent = node[index]
+			default:
+				return fmt.Errorf("invalid path %s", op.Path)
+			}
+		}
+
+		last := segments[len(segments)-1]
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			switch op.Op {
+			case "add", "replace":
+				node[last] = op.Value
+			case "remove":
+				delete(node, last)
+			default:
+				return fmt.Errorf("unsupported patch operation %q", op.Op)
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(last)
+			if last == "-" {
+				index, err = len(node), nil
+			}
+			if err != nil || index < 0 || index > len(node) || (op.Op != "add" && index == len(node)) {
+				return fmt.Errorf("invalid path %s", op.Path)
+			}
+			switch op.Op {
+			case "add":
+				node = append(node[:index], append([]interface{}{op.Value}, node[index:]...)...)
+			case "remove":
+				node = append(node[:index], node[index+1:]...)
+			case "replace":
+				node[index] = op.Value
+			default:
+				return fmt.Errorf("unsupported patch operation %q", op.Op)
+			}
+			if setParent == nil {
+				return fmt.Errorf("invalid path %s", op.Path)
+			}
+			setParent(node)
+		default:
+			return fmt.Errorf("invalid path %s", op.Path)
+		}
+	}
+	return nil
+}
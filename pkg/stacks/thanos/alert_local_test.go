@@ -0,0 +1,102 @@
+package thanos
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+func newLocalAlertTestStack(t *testing.T) (*ThanosStack, *AlertCustomization) {
+	dir := t.TempDir()
+	stack := &ThanosStack{deploymentPath: dir, deployConfig: &types.Config{ChainName: "local-chain"}}
+	config := stack.localMonitoringConfig()
+
+	rules, err := stack.renderLocalAlertRules(config)
+	require.NoError(t, err)
+	amConfig, err := stack.renderLocalAlertManagerConfig(config)
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "monitoring", "rules"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "monitoring", localAlertRulesFile), rules, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "monitoring", localAlertManagerConfigFile), amConfig, 0600))
+	return stack, &AlertCustomization{Stack: stack, localPath: dir}
+}
+
+func TestRenderLocalAlerting(t *testing.T) {
+	_, ac := newLocalAlertTestStack(t)
+	ctx := context.Background()
+
+	rules, err := ac.GetPrometheusRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 13, "the AWS PrometheusRule plus the balance-watcher rules")
+	require.Equal(t, "local-chain", rules[0].Labels["chain_name"])
+
+	obj, err := ac.getThanosStackPrometheusRule(ctx)
+	require.NoError(t, err)
+	policyRules := alertPolicyRulesFromPrometheusRule(obj)
+	for _, core := range constants.CoreAlerts {
+		found := false
+		for _, rule := range policyRules {
+			found = found || rule.Alert == core
+		}
+		require.True(t, found, "core alert %s", core)
+	}
+
+	config, err := ac.GetAlertManagerConfig(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Disabled", ac.GetChannelStatus(config, constants.ChannelTelegram))
+	var amConfig map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(config), &amConfig))
+	require.Equal(t, mainAlertReceiverName, amConfig["route"].(map[string]interface{})["receiver"])
+}
+
+func TestLocalAlertRuleLookups(t *testing.T) {
+	_, ac := newLocalAlertTestStack(t)
+	ctx := context.Background()
+
+	names, err := ac.alertRuleNames(ctx)
+	require.NoError(t, err)
+	require.Equal(t, constants.AlertOpNodeDown, names[0])
+
+	enabled, err := ac.IsRuleEnabled(ctx, constants.AlertBlockProductionStalled)
+	require.NoError(t, err)
+	require.True(t, enabled)
+	require.Equal(t, "5m", ac.GetCurrentRuleValue(ctx, constants.AlertBlockProductionStalled))
+	require.Equal(t, "0.01", ac.GetCurrentRuleValue(ctx, constants.AlertOpBatcherBalanceCritical))
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"groups": []interface{}{
+				map[string]interface{}{
+					"name": "g",
+					"rules": []interface{}{
+						map[string]interface{}{"alert": "A", "expr": "a > 1"},
+						map[string]interface{}{"alert": "B", "expr": "b > 1"},
+					},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, applyJSONPatch(obj, `[
+		{"op":"replace","path":"/spec/groups/0/rules/1/expr","value":"b > 2"},
+		{"op":"add","path":"/spec/groups/0/rules/2","value":{"alert":"C","expr":"c > 1"}},
+		{"op":"remove","path":"/spec/groups/0/rules/0"}
+	]`))
+	rules := localFirstGroupRules(obj)
+	require.Len(t, rules, 2)
+	require.Equal(t, "b > 2", rules[0].(map[string]interface{})["expr"])
+	require.Equal(t, "C", rules[1].(map[string]interface{})["alert"])
+
+	require.Error(t, applyJSONPatch(obj, `[{"op":"remove","path":"/spec/groups/0/rules/5"}]`))
+	require.Error(t, applyJSONPatch(obj, `[{"op":"move","path":"/spec/groups/0/rules/0"}]`))
+}
@@ -0,0 +1,826 @@
+package thanos
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+const (
+	defaultAlertRuleGroup         = "thanos-stack.critical"
+	defaultAlertRuleGroupInterval = "15s"
+	mainAlertReceiverName         = "telegram-critical"
+)
+
+// Placeholders written in place of secrets when a policy is exported without --include-secrets.
+// They are expanded from the environment on apply; an unset variable keeps the value in the cluster.
+const (
+	alertPolicySmtpPasswordEnv       = "TRH_ALERT_SMTP_PASSWORD"
+	alertPolicyTelegramBotTokenEnv   = "TRH_ALERT_TELEGRAM_BOT_TOKEN"
+	alertPolicySlackWebhookURLEnv    = "TRH_ALERT_SLACK_WEBHOOK_URL"
+	alertPolicyDiscordWebhookURLEnv  = "TRH_ALERT_DISCORD_WEBHOOK_URL"
+	alertPolicyPagerDutyRoutingEnv   = "TRH_ALERT_PAGERDUTY_ROUTING_KEY"
+	alertPolicyWebhookBearerTokenEnv = "TRH_ALERT_WEBHOOK_BEARER_TOKEN"
+)
+
+// promDurationPattern matches Prometheus durations such as 30s, 5m, 1h30m or 1d
+var promDurationPattern = regexp.MustCompile(`^([0-9]+(ms|s|m|h|d|w|y))+$`)
+
+// alertRuleLabelsInherited are filled into custom rules from the existing rules when missing,
+// since AlertManager groups and templates notifications by them
+var alertRuleLabelsInherited = []string{"chain_name", "namespace"}
+
+// getThanosStackPrometheusRule returns the PrometheusRule holding the thanos-stack alerts as a generic object
+func (a *AlertCustomization) getThanosStackPrometheusRule(ctx context.Context) (map[string]interface{}, error) {
+	if a.isLocal() {
+		return a.readLocalRuleObject()
+	}
+
+	output, err := utils.ExecuteCommand(ctx, "kubectl", "get", "prometheusrule", "-n", constants.MonitoringNamespace, "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PrometheusRules: %w", err)
+	}
+
+	var list struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, fmt.Errorf("failed to parse PrometheusRule list: %w", err)
+	}
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("no PrometheusRule found in monitoring namespace")
+	}
+
+	for _, item := range list.Items {
+		metadata, _ := item["metadata"].(map[string]interface{})
+		if name, _ := metadata["name"].(string); strings.Contains(name, "thanos-stack-alerts") {
+			return item, nil
+		}
+	}
+	return list.Items[0], nil
+}
+
+// alertPolicyRulesFromPrometheusRule flattens the rule groups of a PrometheusRule object
+func alertPolicyRulesFromPrometheusRule(obj map[string]interface{}) []types.AlertPolicyRule {
+	spec, _ := obj["spec"].(map[string]interface{})
+	groups, _ := spec["groups"].([]interface{})
+
+	var rules []types.AlertPolicyRule
+	for _, g := range groups {
+		group, ok := g.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		groupName, _ := group["name"].(string)
+		groupRules, _ := group["rules"].([]interface{})
+		for _, r := range groupRules {
+			raw, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			alert, _ := raw["alert"].(string)
+			if alert == "" {
+				// Recording rules are not managed by alert policies
+				continue
+			}
+			rule := types.AlertPolicyRule{
+				Group:       groupName,
+				Alert:       alert,
+				Expr:        strings.TrimSpace(fmt.Sprint(raw["expr"])),
+				Labels:      stringMap(raw["labels"]),
+				Annotations: stringMap(raw["annotations"]),
+			}
+			if forValue, ok := raw["for"].(string); ok {
+				rule.For = forValue
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func stringMap(v interface{}) map[string]string {
+	raw, ok := v.(map[string]interface{})
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(raw))
+	for k, val := range raw {
+		out[k] = fmt.Sprint(val)
+	}
+	return out
+}
+
+// alertPolicyChannelsFromConfig extracts the notification channels from an AlertManager config
+func alertPolicyChannelsFromConfig(config string) types.AlertPolicyChannels {
+	var amConfig struct {
+		Global struct {
+			SmtpSmarthost    string `yaml:"smtp_smarthost"`
+			SmtpFrom         string `yaml:"smtp_from"`
+			SmtpAuthPassword string `yaml:"smtp_auth_password"`
+		} `yaml:"global"`
+		Receivers []types.AlertManagerParsedReceiver `yaml:"receivers"`
+	}
+
+	email := &types.AlertPolicyEmailChannel{}
+	telegram := &types.AlertPolicyTelegramChannel{}
+	if err := yaml.Unmarshal([]byte(config), &amConfig); err == nil {
+		for _, receiver := range amConfig.Receivers {
+			for _, emailConfig := range receiver.EmailConfigs {
+				if emailConfig.To != "" {
+					email.Receivers = append(email.Receivers, emailConfig.To)
+				}
+			}
+			for _, telegramConfig := range receiver.TelegramConfigs {
+				if telegramConfig.BotToken != "" {
+					telegram.BotToken = telegramConfig.BotToken
+				}
+				if telegramConfig.ChatID != "" {
+					telegram.ChatIDs = append(telegram.ChatIDs, telegramConfig.ChatID)
+				}
+			}
+		}
+		if len(email.Receivers) > 0 {
+			email.Enabled = true
+			email.SmtpSmarthost = amConfig.Global.SmtpSmarthost
+			email.SmtpFrom = amConfig.Global.SmtpFrom
+			email.SmtpAuthPassword = amConfig.Global.SmtpAuthPassword
+		}
+		telegram.Enabled = len(telegram.ChatIDs) > 0
+	}
+
+	routed := routedChannelsFromConfig(config)
+	return types.AlertPolicyChannels{
+		Email:     email,
+		Telegram:  telegram,
+		Slack:     &routed.Slack,
+		Discord:   &routed.Discord,
+		PagerDuty: &routed.PagerDuty,
+		Webhook:   &routed.Webhook,
+	}
+}
+
+// alertPolicySecretFields returns pointers to every channel secret of a policy with its placeholder variable
+func alertPolicySecretFields(channels *types.AlertPolicyChannels) map[string]*string {
+	fields := make(map[string]*string)
+	if channels.Email != nil {
+		fields[alertPolicySmtpPasswordEnv] = &channels.Email.SmtpAuthPassword
+	}
+	if channels.Telegram != nil {
+		fields[alertPolicyTelegramBotTokenEnv] = &channels.Telegram.BotToken
+	}
+	if channels.Slack != nil {
+		fields[alertPolicySlackWebhookURLEnv] = &channels.Slack.WebhookURL
+	}
+	if channels.Discord != nil {
+		fields[alertPolicyDiscordWebhookURLEnv] = &channels.Discord.WebhookURL
+	}
+	if channels.PagerDuty != nil {
+		fields[alertPolicyPagerDutyRoutingEnv] = &channels.PagerDuty.RoutingKey
+	}
+	if channels.Webhook != nil {
+		fields[alertPolicyWebhookBearerTokenEnv] = &channels.Webhook.BearerToken
+	}
+	return fields
+}
+
+// routedPolicyChannels converts the routed channels of a policy, treating omitted channels as disabled
+func routedPolicyChannels(channels types.AlertPolicyChannels) types.AlertManagerConfig {
+	var config types.AlertManagerConfig
+	if channels.Slack != nil {
+		config.Slack = *channels.Slack
+	}
+	if channels.Discord != nil {
+		config.Discord = *channels.Discord
+	}
+	if channels.PagerDuty != nil {
+		config.PagerDuty = *channels.PagerDuty
+	}
+	if channels.Webhook != nil {
+		config.Webhook = *channels.Webhook
+	}
+	return config
+}
+
+// routedPolicyChannelSet reports which routed channels a policy sets, enabled or not
+func routedPolicyChannelSet(channels types.AlertPolicyChannels) map[string]bool {
+	return map[string]bool{
+		constants.ChannelSlack:     channels.Slack != nil,
+		constants.ChannelDiscord:   channels.Discord != nil,
+		constants.ChannelPagerDuty: channels.PagerDuty != nil,
+		constants.ChannelWebhook:   channels.Webhook != nil,
+	}
+}
+
+// ExportAlertPolicy reads all alert rules and channels from the cluster.
+// Secrets are replaced by environment placeholders unless includeSecrets is set.
+func (a *AlertCustomization) ExportAlertPolicy(ctx context.Context, includeSecrets bool) (*types.AlertPolicy, error) {
+	ruleObj, err := a.getThanosStackPrometheusRule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	amConfig, err := a.GetAlertManagerConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AlertManager config: %w", err)
+	}
+
+	policy := &types.AlertPolicy{
+		APIVersion: types.AlertPolicyAPIVersion,
+		Kind:       types.AlertPolicyKind,
+		Rules:      alertPolicyRulesFromPrometheusRule(ruleObj),
+		Channels:   alertPolicyChannelsFromConfig(amConfig),
+		Routes:     alertPolicyRoutesFromConfig(amConfig),
+	}
+	if !includeSecrets {
+		redactAlertPolicySecrets(policy)
+	}
+	return policy, nil
+}
+
+// alertPolicyRoutesFromConfig reads the custom routes from an AlertManager config
+func alertPolicyRoutesFromConfig(config string) []types.AlertRoute {
+	var amConfig map[string]interface{}
+	if err := yaml.Unmarshal([]byte(config), &amConfig); err != nil {
+		return nil
+	}
+	return customAlertRoutes(amConfig)
+}
+
+// redactAlertPolicySecrets replaces channel secrets with environment placeholders
+func redactAlertPolicySecrets(policy *types.AlertPolicy) {
+	for env, field := range alertPolicySecretFields(&policy.Channels) {
+		if *field != "" {
+			*field = "${" + env + "}"
+		}
+	}
+}
+
+// LoadAlertPolicyFile reads and validates an alert policy file, expanding secret placeholders from the environment
+func LoadAlertPolicyFile(path string) (*types.AlertPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert policy file: %w", err)
+	}
+
+	var policy types.AlertPolicy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse alert policy file: %w", err)
+	}
+
+	for _, field := range alertPolicySecretFields(&policy.Channels) {
+		*field = os.ExpandEnv(*field)
+	}
+
+	if err := ValidateAlertPolicy(&policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// ValidateAlertPolicy checks that an alert policy can be applied
+func ValidateAlertPolicy(policy *types.AlertPolicy) error {
+	if policy.APIVersion != "" && policy.APIVersion != types.AlertPolicyAPIVersion {
+		return fmt.Errorf("unsupported alert policy apiVersion %q (expected %s)", policy.APIVersion, types.AlertPolicyAPIVersion)
+	}
+	if policy.Kind != "" && policy.Kind != types.AlertPolicyKind {
+		return fmt.Errorf("unsupported alert policy kind %q (expected %s)", policy.Kind, types.AlertPolicyKind)
+	}
+
+	seen := make(map[string]bool)
+	for i, rule := range policy.Rules {
+		if strings.TrimSpace(rule.Alert) == "" {
+			return fmt.Errorf("rule #%d: alert name is required", i+1)
+		}
+		if seen[rule.Alert] {
+			return fmt.Errorf("rule %s: duplicate alert name", rule.Alert)
+		}
+		seen[rule.Alert] = true
+		if strings.TrimSpace(rule.Expr) == "" {
+			return fmt.Errorf("rule %s: expr is required", rule.Alert)
+		}
+		if rule.For != "" && !promDurationPattern.MatchString(rule.For) {
+			return fmt.Errorf("rule %s: invalid 'for' duration %q", rule.Alert, rule.For)
+		}
+	}
+	for _, core := range constants.CoreAlerts {
+		if !seen[core] {
+			return fmt.Errorf("core alert %s cannot be removed", core)
+		}
+	}
+
+	if email := policy.Channels.Email; email != nil && email.Enabled {
+		if email.SmtpSmarthost == "" || email.SmtpFrom == "" || len(email.Receivers) == 0 {
+			return fmt.Errorf("email channel requires smtp_smarthost, smtp_from and at least one receiver")
+		}
+	}
+	if telegram := policy.Channels.Telegram; telegram != nil && telegram.Enabled {
+		if len(telegram.ChatIDs) == 0 {
+			return fmt.Errorf("telegram channel requires at least one chat ID")
+		}
+		for _, chatID := range telegram.ChatIDs {
+			if _, err := strconv.ParseInt(chatID, 10, 64); err != nil {
+				return fmt.Errorf("invalid telegram chat ID %q", chatID)
+			}
+		}
+	}
+
+	// Routed channel secrets may still be filled from the cluster, so only the severities are checked here
+	routed := routedPolicyChannels(policy.Channels)
+	for _, severities := range [][]string{routed.Slack.Severities, routed.Discord.Severities, routed.PagerDuty.Severities, routed.Webhook.Severities} {
+		if err := ValidateAlertSeverities(severities); err != nil {
+			return err
+		}
+	}
+
+	// Route receivers are checked against the cluster on apply
+	anyReceiver := make(map[string]bool)
+	for _, channelType := range constants.RoutedChannels {
+		anyReceiver[channelType] = true
+	}
+	return validateAlertRoutes(policy.Routes, anyReceiver)
+}
+
+// normalizeAlertPolicyRule fills the defaults a rule gets when applied, so that diffs are stable
+func normalizeAlertPolicyRule(rule types.AlertPolicyRule) types.AlertPolicyRule {
+	if rule.Group == "" {
+		rule.Group = defaultAlertRuleGroup
+	}
+	rule.Expr = strings.TrimSpace(rule.Expr)
+	if len(rule.Labels) == 0 {
+		rule.Labels = nil
+	}
+	if len(rule.Annotations) == 0 {
+		rule.Annotations = nil
+	}
+	return rule
+}
+
+// inheritAlertRuleLabels copies chain_name/namespace labels from the current rules into desired rules missing them
+func inheritAlertRuleLabels(current, desired []types.AlertPolicyRule) {
+	inherited := make(map[string]string)
+	for _, rule := range current {
+		for _, key := range alertRuleLabelsInherited {
+			if v, ok := rule.Labels[key]; ok && inherited[key] == "" {
+				inherited[key] = v
+			}
+		}
+	}
+	for i := range desired {
+		for key, value := range inherited {
+			if _, ok := desired[i].Labels[key]; ok {
+				continue
+			}
+			if desired[i].Labels == nil {
+				desired[i].Labels = make(map[string]string)
+			}
+			desired[i].Labels[key] = value
+		}
+	}
+}
+
+// mergeAlertPolicyChannelSecrets keeps the cluster secret for enabled channels whose secret was left empty
+func mergeAlertPolicyChannelSecrets(current, desired *types.AlertPolicyChannels) {
+	if desired.Email != nil && desired.Email.Enabled && desired.Email.SmtpAuthPassword == "" && current.Email != nil {
+		desired.Email.SmtpAuthPassword = current.Email.SmtpAuthPassword
+	}
+	if desired.Telegram != nil && desired.Telegram.Enabled && desired.Telegram.BotToken == "" && current.Telegram != nil {
+		desired.Telegram.BotToken = current.Telegram.BotToken
+	}
+	if desired.Slack != nil && desired.Slack.Enabled && desired.Slack.WebhookURL == "" && current.Slack != nil {
+		desired.Slack.WebhookURL = current.Slack.WebhookURL
+	}
+	if desired.Discord != nil && desired.Discord.Enabled && desired.Discord.WebhookURL == "" && current.Discord != nil {
+		desired.Discord.WebhookURL = current.Discord.WebhookURL
+	}
+	if desired.PagerDuty != nil && desired.PagerDuty.Enabled && desired.PagerDuty.RoutingKey == "" && current.PagerDuty != nil {
+		desired.PagerDuty.RoutingKey = current.PagerDuty.RoutingKey
+	}
+	if desired.Webhook != nil && desired.Webhook.Enabled && desired.Webhook.BearerToken == "" && current.Webhook != nil {
+		desired.Webhook.BearerToken = current.Webhook.BearerToken
+	}
+}
+
+// DiffAlertPolicy compares the current and desired policies. Channels omitted from desired are left unchanged.
+func DiffAlertPolicy(current, desired *types.AlertPolicy) *types.AlertPolicyDiff {
+	diff := &types.AlertPolicyDiff{}
+
+	currentRules := make(map[string]types.AlertPolicyRule)
+	for _, rule := range current.Rules {
+		currentRules[rule.Alert] = normalizeAlertPolicyRule(rule)
+	}
+	desiredRules := make(map[string]bool)
+	for _, rule := range desired.Rules {
+		desiredRules[rule.Alert] = true
+		existing, ok := currentRules[rule.Alert]
+		switch {
+		case !ok:
+			diff.AddedRules = append(diff.AddedRules, rule.Alert)
+		case !reflect.DeepEqual(existing, normalizeAlertPolicyRule(rule)):
+			diff.ChangedRules = append(diff.ChangedRules, rule.Alert)
+		}
+	}
+	for name := range currentRules {
+		if !desiredRules[name] {
+			diff.RemovedRules = append(diff.RemovedRules, name)
+		}
+	}
+	sort.Strings(diff.RemovedRules)
+
+	if desired.Channels.Email != nil && !alertPolicyEmailEqual(current.Channels.Email, desired.Channels.Email) {
+		diff.ChangedChannels = append(diff.ChangedChannels, constants.ChannelEmail)
+	}
+	if desired.Channels.Telegram != nil && !alertPolicyTelegramEqual(current.Channels.Telegram, desired.Channels.Telegram) {
+		diff.ChangedChannels = append(diff.ChangedChannels, constants.ChannelTelegram)
+	}
+
+	currentRouted := routedPolicyChannels(current.Channels)
+	desiredRouted := routedPolicyChannels(desired.Channels)
+	set := routedPolicyChannelSet(desired.Channels)
+	for _, channelType := range constants.RoutedChannels {
+		if !set[channelType] {
+			continue
+		}
+		currentConfig, currentSeverities := routedChannelReceiverConfig(channelType, currentRouted, nil)
+		desiredConfig, desiredSeverities := routedChannelReceiverConfig(channelType, desiredRouted, nil)
+		if !reflect.DeepEqual(currentConfig, desiredConfig) || !equalStringSets(currentSeverities, desiredSeverities) {
+			diff.ChangedChannels = append(diff.ChangedChannels, channelType)
+		}
+	}
+
+	if desired.Routes != nil && !alertRoutesEqual(current.Routes, desired.Routes) {
+		diff.RoutesChanged = true
+	}
+	return diff
+}
+
+func alertRoutesEqual(a, b []types.AlertRoute) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !reflect.DeepEqual(alertRouteToMap(a[i]), alertRouteToMap(b[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalStringSets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
+
+func alertPolicyEmailEqual(a, b *types.AlertPolicyEmailChannel) bool {
+	if a == nil || !a.Enabled {
+		return b == nil || !b.Enabled
+	}
+	return b != nil && b.Enabled &&
+		a.SmtpSmarthost == b.SmtpSmarthost &&
+		a.SmtpFrom == b.SmtpFrom &&
+		a.SmtpAuthPassword == b.SmtpAuthPassword &&
+		reflect.DeepEqual(a.Receivers, b.Receivers)
+}
+
+func alertPolicyTelegramEqual(a, b *types.AlertPolicyTelegramChannel) bool {
+	if a == nil || !a.Enabled {
+		return b == nil || !b.Enabled
+	}
+	return b != nil && b.Enabled &&
+		a.BotToken == b.BotToken &&
+		reflect.DeepEqual(a.ChatIDs, b.ChatIDs)
+}
+
+// buildPrometheusRuleGroups groups the desired rules, keeping the evaluation interval of existing groups
+func buildPrometheusRuleGroups(ruleObj map[string]interface{}, rules []types.AlertPolicyRule) []interface{} {
+	intervals := make(map[string]interface{})
+	spec, _ := ruleObj["spec"].(map[string]interface{})
+	existingGroups, _ := spec["groups"].([]interface{})
+	var recordingRules = make(map[string][]interface{})
+	for _, g := range existingGroups {
+		group, ok := g.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := group["name"].(string)
+		if interval, ok := group["interval"]; ok {
+			intervals[name] = interval
+		}
+		groupRules, _ := group["rules"].([]interface{})
+		for _, r := range groupRules {
+			if raw, ok := r.(map[string]interface{}); ok && raw["alert"] == nil {
+				recordingRules[name] = append(recordingRules[name], raw)
+			}
+		}
+	}
+
+	var order []string
+	byGroup := make(map[string][]interface{})
+	for _, rule := range rules {
+		rule = normalizeAlertPolicyRule(rule)
+		if _, ok := byGroup[rule.Group]; !ok {
+			order = append(order, rule.Group)
+		}
+		raw := map[string]interface{}{
+			"alert": rule.Alert,
+			"expr":  rule.Expr,
+		}
+		if rule.For != "" {
+			raw["for"] = rule.For
+		}
+		if rule.Labels != nil {
+			raw["labels"] = rule.Labels
+		}
+		if rule.Annotations != nil {
+			raw["annotations"] = rule.Annotations
+		}
+		byGroup[rule.Group] = append(byGroup[rule.Group], raw)
+	}
+	for name := range recordingRules {
+		if _, ok := byGroup[name]; !ok {
+			order = append(order, name)
+		}
+	}
+
+	groups := make([]interface{}, 0, len(order))
+	for _, name := range order {
+		interval, ok := intervals[name]
+		if !ok {
+			interval = defaultAlertRuleGroupInterval
+		}
+		groups = append(groups, map[string]interface{}{
+			"name":     name,
+			"interval": interval,
+			"rules":    append(recordingRules[name], byGroup[name]...),
+		})
+	}
+	return groups
+}
+
+// applyAlertPolicyChannels updates a generic AlertManager config with the desired channels
+func applyAlertPolicyChannels(config map[string]interface{}, channels types.AlertPolicyChannels, templates map[string]string) error {
+	receiversList, _ := config["receivers"].([]interface{})
+	var mainReceiver map[string]interface{}
+	for _, r := range receiversList {
+		receiver, ok := r.(map[string]interface{})
+		if ok && receiver["name"] == mainAlertReceiverName {
+			mainReceiver = receiver
+			break
+		}
+	}
+	if mainReceiver == nil {
+		mainReceiver = map[string]interface{}{"name": mainAlertReceiverName}
+		receiversList = append(receiversList, mainReceiver)
+	}
+	config["receivers"] = receiversList
+
+	if email := channels.Email; email != nil {
+		global, ok := config["global"].(map[string]interface{})
+		if !ok {
+			global = make(map[string]interface{})
+			config["global"] = global
+		}
+		if email.Enabled {
+			global["smtp_smarthost"] = email.SmtpSmarthost
+			global["smtp_from"] = email.SmtpFrom
+			global["smtp_auth_username"] = email.SmtpFrom
+			global["smtp_auth_password"] = email.SmtpAuthPassword
+			global["smtp_require_tls"] = true
+			emailConfigs := make([]interface{}, 0, len(email.Receivers))
+			for _, to := range email.Receivers {
+				emailConfigs = append(emailConfigs, map[string]interface{}{
+					"to":      to,
+					"headers": map[string]string{"subject": templates["email_subject"]},
+					"html":    templates["email_html"],
+				})
+			}
+			mainReceiver["email_configs"] = emailConfigs
+		} else {
+			for _, key := range []string{"smtp_smarthost", "smtp_from", "smtp_auth_username", "smtp_auth_password"} {
+				delete(global, key)
+			}
+			delete(mainReceiver, "email_configs")
+		}
+	}
+
+	if telegram := channels.Telegram; telegram != nil {
+		if telegram.Enabled {
+			telegramConfigs := make([]interface{}, 0, len(telegram.ChatIDs))
+			for _, chatID := range telegram.ChatIDs {
+				chatIDInt, err := strconv.ParseInt(chatID, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid telegram chat ID %q", chatID)
+				}
+				telegramConfigs = append(telegramConfigs, map[string]interface{}{
+					"bot_token":  telegram.BotToken,
+					"chat_id":    chatIDInt,
+					"message":    templates["telegram_message"],
+					"parse_mode": "Markdown",
+				})
+			}
+			mainReceiver["telegram_configs"] = telegramConfigs
+		} else {
+			delete(mainReceiver, "telegram_configs")
+		}
+	}
+
+	routed := routedPolicyChannels(channels)
+	set := routedPolicyChannelSet(channels)
+	for _, channelType := range constants.RoutedChannels {
+		if !set[channelType] {
+			continue
+		}
+		if receiverConfig, severities := routedChannelReceiverConfig(channelType, routed, templates); receiverConfig != nil {
+			setRoutedChannel(config, channelType, receiverConfig, severities)
+		} else {
+			removeRoutedChannel(config, channelType)
+		}
+	}
+	return nil
+}
+
+// kubectlObject runs `kubectl <verb>` on a JSON object, optionally as a server-side dry run
+func kubectlObject(ctx context.Context, verb string, obj map[string]interface{}, dryRun bool) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("failed to marshal object: %w", err)
+	}
+	tempFile, err := os.CreateTemp("", "alert-policy-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	tempFile.Close()
+
+	args := []string{verb, "-f", tempFile.Name()}
+	if dryRun {
+		args = append(args, "--dry-run=server")
+	}
+	if output, err := utils.ExecuteCommand(ctx, "kubectl", args...); err != nil {
+		return fmt.Errorf("kubectl %s failed: %w (%s)", verb, err, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// replacePrometheusRule replaces the PrometheusRule holding the alerts, optionally validating only
+func (a *AlertCustomization) replacePrometheusRule(ctx context.Context, obj map[string]interface{}, dryRun bool) error {
+	if a.isLocal() {
+		return a.writeLocalRuleObject(ctx, obj, dryRun)
+	}
+	return kubectlObject(ctx, "replace", obj, dryRun)
+}
+
+// validateAlertManagerConfig validates an AlertManager config without applying it
+func (a *AlertCustomization) validateAlertManagerConfig(ctx context.Context, data []byte) error {
+	if a.isLocal() {
+		return a.applyLocalMonitoringFile(ctx, localAlertManagerConfigFile, data, true)
+	}
+	validation := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "alertmanager-config", "namespace": constants.MonitoringNamespace},
+		"data":       map[string]interface{}{"alertmanager.yaml": base64.StdEncoding.EncodeToString(data)},
+	}
+	return kubectlObject(ctx, "apply", validation, true)
+}
+
+// ApplyAlertPolicy diffs the desired policy against the cluster and applies it.
+// Both changes are validated server-side first; if the AlertManager update fails the rules are rolled back.
+func (a *AlertCustomization) ApplyAlertPolicy(ctx context.Context, desired *types.AlertPolicy, dryRun bool) (*types.AlertPolicyDiff, error) {
+	if err := ValidateAlertPolicy(desired); err != nil {
+		return nil, err
+	}
+
+	ruleObj, err := a.getThanosStackPrometheusRule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	amConfigYAML, err := a.GetAlertManagerConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AlertManager config: %w", err)
+	}
+
+	current := &types.AlertPolicy{
+		Rules:    alertPolicyRulesFromPrometheusRule(ruleObj),
+		Channels: alertPolicyChannelsFromConfig(amConfigYAML),
+		Routes:   alertPolicyRoutesFromConfig(amConfigYAML),
+	}
+	inheritAlertRuleLabels(current.Rules, desired.Rules)
+	mergeAlertPolicyChannelSecrets(&current.Channels, &desired.Channels)
+	if err := validateRoutedChannels(routedPolicyChannels(desired.Channels)); err != nil {
+		return nil, err
+	}
+
+	diff := DiffAlertPolicy(current, desired)
+	if !diff.HasChanges() {
+		return diff, nil
+	}
+
+	// Build the new PrometheusRule, keeping resourceVersion so a concurrent edit makes the replace fail
+	previousRuleObj, err := deepCopyJSONObject(ruleObj)
+	if err != nil {
+		return nil, err
+	}
+	newRuleObj, err := deepCopyJSONObject(ruleObj)
+	if err != nil {
+		return nil, err
+	}
+	spec, _ := newRuleObj["spec"].(map[string]interface{})
+	if spec == nil {
+		spec = make(map[string]interface{})
+		newRuleObj["spec"] = spec
+	}
+	spec["groups"] = buildPrometheusRuleGroups(ruleObj, desired.Rules)
+
+	rulesChanged := len(diff.AddedRules)+len(diff.RemovedRules)+len(diff.ChangedRules) > 0
+	if rulesChanged {
+		if err := a.replacePrometheusRule(ctx, newRuleObj, true); err != nil {
+			return nil, fmt.Errorf("PrometheusRule validation failed: %w", err)
+		}
+	}
+
+	var newAMConfigYAML string
+	if len(diff.ChangedChannels) > 0 || diff.RoutesChanged {
+		var amConfig map[string]interface{}
+		if err := yaml.Unmarshal([]byte(amConfigYAML), &amConfig); err != nil {
+			return nil, fmt.Errorf("failed to parse current AlertManager config: %w", err)
+		}
+		if err := applyAlertPolicyChannels(amConfig, desired.Channels, a.Stack.generateAlertTemplates(a.grafanaURL(ctx))); err != nil {
+			return nil, err
+		}
+		if desired.Routes != nil {
+			if err := validateAlertRoutes(desired.Routes, enabledRoutedReceivers(amConfig)); err != nil {
+				return nil, err
+			}
+			setCustomAlertRoutes(amConfig, desired.Routes)
+		}
+		data, err := yaml.Marshal(amConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal updated AlertManager config: %w", err)
+		}
+		newAMConfigYAML = string(data)
+
+		if err := a.validateAlertManagerConfig(ctx, data); err != nil {
+			return nil, fmt.Errorf("AlertManager config validation failed: %w", err)
+		}
+	}
+
+	if dryRun {
+		return diff, nil
+	}
+
+	if rulesChanged {
+		if err := a.replacePrometheusRule(ctx, newRuleObj, false); err != nil {
+			return nil, fmt.Errorf("failed to apply PrometheusRule: %w", err)
+		}
+	}
+	if newAMConfigYAML != "" {
+		if err := a.applyAlertManagerConfig(ctx, newAMConfigYAML); err != nil {
+			if rulesChanged {
+				// Restore the previous rules unconditionally so the cluster is left as it was
+				if metadata, ok := previousRuleObj["metadata"].(map[string]interface{}); ok {
+					delete(metadata, "resourceVersion")
+				}
+				if rollbackErr := a.replacePrometheusRule(ctx, previousRuleObj, false); rollbackErr != nil {
+					return nil, fmt.Errorf("failed to apply AlertManager config: %w (rule rollback also failed: %v)", err, rollbackErr)
+				}
+			}
+			return nil, fmt.Errorf("failed to apply AlertManager config, rules were rolled back: %w", err)
+		}
+	}
+	return diff, nil
+}
+
+func deepCopyJSONObject(obj map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy object: %w", err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to copy object: %w", err)
+	}
+	return out, nil
+}
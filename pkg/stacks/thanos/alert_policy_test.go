@@ -0,0 +1,187 @@
+package thanos
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+func coreAlertPolicyRules() []types.AlertPolicyRule {
+	rules := make([]types.AlertPolicyRule, 0, len(constants.CoreAlerts))
+	for _, name := range constants.CoreAlerts {
+		rules = append(rules, types.AlertPolicyRule{
+			Group:  defaultAlertRuleGroup,
+			Alert:  name,
+			Expr:   "up == 0",
+			For:    "1m",
+			Labels: map[string]string{"severity": "critical"},
+		})
+	}
+	return rules
+}
+
+func TestAlertPolicyRulesFromPrometheusRule(t *testing.T) {
+	var obj map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+spec:
+  groups:
+  - name: thanos-stack.critical
+    interval: 15s
+    rules:
+    - record: job:up:sum
+      expr: sum(up) by (job)
+    - alert: OpNodeDown
+      expr: |
+        up{job="op-node"} == 0
+      for: 1m
+      labels:
+        severity: critical
+`), &obj))
+
+	rules := alertPolicyRulesFromPrometheusRule(obj)
+	require.Len(t, rules, 1, "recording rules are skipped")
+	require.Equal(t, "OpNodeDown", rules[0].Alert)
+	require.Equal(t, `up{job="op-node"} == 0`, rules[0].Expr)
+	require.Equal(t, "1m", rules[0].For)
+	require.Equal(t, "critical", rules[0].Labels["severity"])
+	require.Nil(t, rules[0].Annotations)
+
+	groups := buildPrometheusRuleGroups(obj, rules)
+	require.Len(t, groups, 1)
+	group := groups[0].(map[string]interface{})
+	require.Equal(t, "15s", group["interval"])
+	require.Len(t, group["rules"], 2, "recording rules are preserved")
+}
+
+func TestDiffAlertPolicy(t *testing.T) {
+	current := &types.AlertPolicy{
+		Rules: append(coreAlertPolicyRules(), types.AlertPolicyRule{Alert: "Old", Expr: "vector(1)"}),
+		Channels: types.AlertPolicyChannels{
+			Telegram: &types.AlertPolicyTelegramChannel{Enabled: true, BotToken: "1:abc", ChatIDs: []string{"123"}},
+		},
+	}
+	desired := &types.AlertPolicy{
+		Rules: append(coreAlertPolicyRules(),
+			types.AlertPolicyRule{Alert: "Custom", Expr: "rate(x[5m]) > 1", For: "5m"}),
+		Channels: types.AlertPolicyChannels{
+			Email: &types.AlertPolicyEmailChannel{Enabled: false},
+		},
+	}
+	desired.Rules[0].For = "2m"
+
+	diff := DiffAlertPolicy(current, desired)
+	require.Equal(t, []string{"Custom"}, diff.AddedRules)
+	require.Equal(t, []string{"Old"}, diff.RemovedRules)
+	require.Equal(t, []string{constants.AlertOpNodeDown}, diff.ChangedRules)
+	require.Empty(t, diff.ChangedChannels, "omitted telegram and already disabled email are unchanged")
+	require.True(t, diff.HasChanges())
+
+	same := DiffAlertPolicy(current, &types.AlertPolicy{Rules: current.Rules})
+	require.False(t, same.HasChanges())
+}
+
+func TestMergeAlertPolicyChannelSecrets(t *testing.T) {
+	current := types.AlertPolicyChannels{
+		Telegram: &types.AlertPolicyTelegramChannel{Enabled: true, BotToken: "1:abc", ChatIDs: []string{"123"}},
+	}
+	desired := types.AlertPolicyChannels{
+		Telegram: &types.AlertPolicyTelegramChannel{Enabled: true, ChatIDs: []string{"123"}},
+	}
+	mergeAlertPolicyChannelSecrets(&current, &desired)
+	require.Equal(t, "1:abc", desired.Telegram.BotToken)
+	require.True(t, alertPolicyTelegramEqual(current.Telegram, desired.Telegram))
+}
+
+func TestValidateAlertPolicy(t *testing.T) {
+	valid := &types.AlertPolicy{APIVersion: types.AlertPolicyAPIVersion, Kind: types.AlertPolicyKind, Rules: coreAlertPolicyRules()}
+	require.NoError(t, ValidateAlertPolicy(valid))
+
+	badFor := &types.AlertPolicy{Rules: append(coreAlertPolicyRules(), types.AlertPolicyRule{Alert: "X", Expr: "vector(1)", For: "5 minutes"})}
+	require.ErrorContains(t, ValidateAlertPolicy(badFor), "invalid 'for' duration")
+
+	duplicate := &types.AlertPolicy{Rules: append(coreAlertPolicyRules(), coreAlertPolicyRules()[0])}
+	require.ErrorContains(t, ValidateAlertPolicy(duplicate), "duplicate")
+
+	missingCore := &types.AlertPolicy{Rules: coreAlertPolicyRules()[1:]}
+	require.ErrorContains(t, ValidateAlertPolicy(missingCore), "cannot be removed")
+
+	badChat := &types.AlertPolicy{
+		Rules:    coreAlertPolicyRules(),
+		Channels: types.AlertPolicyChannels{Telegram: &types.AlertPolicyTelegramChannel{Enabled: true, ChatIDs: []string{"@channel"}}},
+	}
+	require.ErrorContains(t, ValidateAlertPolicy(badChat), "invalid telegram chat ID")
+}
+
+func TestLoadAlertPolicyFile_ExpandsSecrets(t *testing.T) {
+	policy := &types.AlertPolicy{
+		APIVersion: types.AlertPolicyAPIVersion,
+		Kind:       types.AlertPolicyKind,
+		Rules:      coreAlertPolicyRules(),
+		Channels: types.AlertPolicyChannels{
+			Telegram: &types.AlertPolicyTelegramChannel{Enabled: true, BotToken: "1:secret", ChatIDs: []string{"-100"}},
+		},
+	}
+	redactAlertPolicySecrets(policy)
+	require.Equal(t, "${TRH_ALERT_TELEGRAM_BOT_TOKEN}", policy.Channels.Telegram.BotToken)
+
+	data, err := yaml.Marshal(policy)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "alert-policy.yaml")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	t.Setenv(alertPolicyTelegramBotTokenEnv, "1:fromenv")
+	loaded, err := LoadAlertPolicyFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "1:fromenv", loaded.Channels.Telegram.BotToken)
+}
+
+func TestApplyAlertPolicyChannels(t *testing.T) {
+	config := map[string]interface{}{
+		"global": map[string]interface{}{"smtp_smarthost": "smtp.gmail.com:587", "smtp_from": "a@b.c"},
+		"receivers": []interface{}{
+			map[string]interface{}{"name": "null"},
+			map[string]interface{}{"name": mainAlertReceiverName, "email_configs": []interface{}{map[string]interface{}{"to": "x@y.z"}}},
+		},
+	}
+	channels := types.AlertPolicyChannels{
+		Email:    &types.AlertPolicyEmailChannel{Enabled: false},
+		Telegram: &types.AlertPolicyTelegramChannel{Enabled: true, BotToken: "1:abc", ChatIDs: []string{"-100"}},
+	}
+	require.NoError(t, applyAlertPolicyChannels(config, channels, map[string]string{"telegram_message": "msg"}))
+
+	receiver := config["receivers"].([]interface{})[1].(map[string]interface{})
+	require.NotContains(t, receiver, "email_configs")
+	require.NotContains(t, config["global"], "smtp_smarthost")
+	telegram := receiver["telegram_configs"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, int64(-100), telegram["chat_id"])
+	require.Equal(t, "msg", telegram["message"])
+}
+
+func TestAlertPolicyRoutedChannels(t *testing.T) {
+	current := &types.AlertPolicy{
+		Rules:    coreAlertPolicyRules(),
+		Channels: types.AlertPolicyChannels{Slack: &types.SlackConfig{Enabled: true, WebhookURL: "https://hooks.slack.com/x"}},
+	}
+	desired := &types.AlertPolicy{
+		Rules: coreAlertPolicyRules(),
+		Channels: types.AlertPolicyChannels{
+			Slack:     &types.SlackConfig{Enabled: true, Severities: []string{constants.AlertSeverityCritical}},
+			PagerDuty: &types.PagerDutyConfig{Enabled: false},
+		},
+	}
+	mergeAlertPolicyChannelSecrets(&current.Channels, &desired.Channels)
+	require.Equal(t, "https://hooks.slack.com/x", desired.Channels.Slack.WebhookURL)
+
+	diff := DiffAlertPolicy(current, desired)
+	require.Equal(t, []string{constants.ChannelSlack}, diff.ChangedChannels, "disabled pagerduty is unchanged")
+
+	config := map[string]interface{}{"receivers": []interface{}{}}
+	require.NoError(t, applyAlertPolicyChannels(config, desired.Channels, nil))
+	require.Equal(t, []string{constants.AlertSeverityCritical}, routedChannelSeverities(config, constants.ChannelSlack))
+}
@@ -0,0 +1,248 @@
+package thanos
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// The AlertManager routing tree is kept in this order:
+//  1. custom routes (severity/component → receiver), first match wins unless `continue` is set
+//  2. channel subscriptions of Slack, Discord, PagerDuty and webhook, which always continue
+//  3. other routes such as Watchdog → null
+//  4. the catch-all route to the default receiver (email and Telegram)
+
+const (
+	// AlertRouteReceiverDefault routes to the default receiver holding the email and Telegram channels
+	AlertRouteReceiverDefault = "default"
+	// AlertRouteReceiverNull drops the matching alerts
+	AlertRouteReceiverNull = "null"
+)
+
+const channelSubscriptionMatcher = `alertname!="Watchdog"`
+
+var (
+	alertRouteLabelMatcher = regexp.MustCompile(`^(severity|component)=~"(.*)"$`)
+	alertManagerDuration   = regexp.MustCompile(`^([0-9]+(ms|s|m|h|d|w|y))+$`)
+)
+
+type alertRouteKind int
+
+const (
+	alertRouteCustom alertRouteKind = iota
+	alertRouteChannel
+	alertRouteOther
+	alertRouteCatchAll
+)
+
+func classifyAlertRoute(route map[string]interface{}, defaultReceiver string) alertRouteKind {
+	receiver, _ := route["receiver"].(string)
+	if isCatchAllRoute(route, defaultReceiver) {
+		return alertRouteCatchAll
+	}
+	if matchers, ok := route["matchers"].([]interface{}); ok && len(matchers) > 0 &&
+		matchers[0] == channelSubscriptionMatcher && route["continue"] == true && constants.IsRoutedChannel(receiver) {
+		return alertRouteChannel
+	}
+	if match, ok := route["match"].(map[string]interface{}); ok && match["alertname"] == "Watchdog" {
+		return alertRouteOther
+	}
+	if match, ok := route["match"].(map[string]string); ok && match["alertname"] == "Watchdog" {
+		return alertRouteOther
+	}
+	return alertRouteCustom
+}
+
+// rebuildAlertRoutes orders the child routes of the root route. A nil custom keeps the current custom routes.
+func rebuildAlertRoutes(amConfig map[string]interface{}, custom []interface{}, editChannels func(channels []interface{}) []interface{}) {
+	route, ok := amConfig["route"].(map[string]interface{})
+	if !ok {
+		route = map[string]interface{}{"receiver": mainAlertReceiverName}
+		amConfig["route"] = route
+	}
+	defaultReceiver, _ := route["receiver"].(string)
+	if defaultReceiver == "" {
+		defaultReceiver = mainAlertReceiverName
+	}
+
+	var currentCustom, channels, others []interface{}
+	for _, r := range routesOf(route) {
+		child, ok := r.(map[string]interface{})
+		if !ok {
+			others = append(others, r)
+			continue
+		}
+		switch classifyAlertRoute(child, defaultReceiver) {
+		case alertRouteCustom:
+			currentCustom = append(currentCustom, child)
+		case alertRouteChannel:
+			channels = append(channels, child)
+		case alertRouteOther:
+			others = append(others, child)
+		}
+	}
+	if custom == nil {
+		custom = currentCustom
+	}
+	if editChannels != nil {
+		channels = editChannels(channels)
+	}
+
+	routes := make([]interface{}, 0, len(custom)+len(channels)+len(others)+1)
+	routes = append(routes, custom...)
+	routes = append(routes, channels...)
+	routes = append(routes, others...)
+	if len(custom) > 0 || len(channels) > 0 {
+		routes = append(routes, map[string]interface{}{"receiver": defaultReceiver})
+	}
+	route["routes"] = routes
+}
+
+// alertRouteReceiverName maps a route receiver to its AlertManager receiver name
+func alertRouteReceiverName(receiver string) string {
+	if receiver == AlertRouteReceiverDefault {
+		return mainAlertReceiverName
+	}
+	return receiver
+}
+
+func alertRouteToMap(route types.AlertRoute) map[string]interface{} {
+	var matchers []interface{}
+	if len(route.Severities) > 0 {
+		matchers = append(matchers, fmt.Sprintf(`severity=~"%s"`, strings.Join(route.Severities, "|")))
+	}
+	if len(route.Components) > 0 {
+		matchers = append(matchers, fmt.Sprintf(`component=~"%s"`, strings.Join(route.Components, "|")))
+	}
+	out := map[string]interface{}{
+		"receiver": alertRouteReceiverName(route.Receiver),
+		"matchers": matchers,
+	}
+	if len(route.GroupBy) > 0 {
+		out["group_by"] = route.GroupBy
+	}
+	if route.GroupWait != "" {
+		out["group_wait"] = route.GroupWait
+	}
+	if route.GroupInterval != "" {
+		out["group_interval"] = route.GroupInterval
+	}
+	if route.RepeatInterval != "" {
+		out["repeat_interval"] = route.RepeatInterval
+	}
+	if route.Continue {
+		out["continue"] = true
+	}
+	return out
+}
+
+// setCustomAlertRoutes replaces the custom routes of a generic AlertManager config
+func setCustomAlertRoutes(amConfig map[string]interface{}, routes []types.AlertRoute) {
+	custom := make([]interface{}, 0, len(routes))
+	for _, route := range routes {
+		custom = append(custom, alertRouteToMap(route))
+	}
+	rebuildAlertRoutes(amConfig, custom, nil)
+}
+
+// customAlertRoutes reads the custom routes of a generic AlertManager config
+func customAlertRoutes(amConfig map[string]interface{}) []types.AlertRoute {
+	route, _ := amConfig["route"].(map[string]interface{})
+	defaultReceiver, _ := route["receiver"].(string)
+	if defaultReceiver == "" {
+		defaultReceiver = mainAlertReceiverName
+	}
+
+	var routes []types.AlertRoute
+	for _, r := range routesOf(route) {
+		child, ok := r.(map[string]interface{})
+		if !ok || classifyAlertRoute(child, defaultReceiver) != alertRouteCustom {
+			continue
+		}
+		receiver, _ := child["receiver"].(string)
+		if receiver == defaultReceiver {
+			receiver = AlertRouteReceiverDefault
+		}
+		alertRoute := types.AlertRoute{Receiver: receiver}
+		matchers, _ := child["matchers"].([]interface{})
+		for _, m := range matchers {
+			matcher, _ := m.(string)
+			parts := alertRouteLabelMatcher.FindStringSubmatch(matcher)
+			if parts == nil {
+				continue
+			}
+			values := strings.Split(parts[2], "|")
+			if parts[1] == "severity" {
+				alertRoute.Severities = values
+			} else {
+				alertRoute.Components = values
+			}
+		}
+		switch groupBy := child["group_by"].(type) {
+		case []string:
+			alertRoute.GroupBy = append(alertRoute.GroupBy, groupBy...)
+		case []interface{}:
+			for _, label := range groupBy {
+				alertRoute.GroupBy = append(alertRoute.GroupBy, fmt.Sprint(label))
+			}
+		}
+		alertRoute.GroupWait, _ = child["group_wait"].(string)
+		alertRoute.GroupInterval, _ = child["group_interval"].(string)
+		alertRoute.RepeatInterval, _ = child["repeat_interval"].(string)
+		alertRoute.Continue, _ = child["continue"].(bool)
+		routes = append(routes, alertRoute)
+	}
+	return routes
+}
+
+// validateAlertRoutes checks custom routes against the enabled receivers
+func validateAlertRoutes(routes []types.AlertRoute, receivers map[string]bool) error {
+	for i, route := range routes {
+		if route.Receiver == "" {
+			return fmt.Errorf("route #%d: receiver is required", i+1)
+		}
+		if route.Receiver != AlertRouteReceiverDefault && route.Receiver != AlertRouteReceiverNull && !receivers[route.Receiver] {
+			return fmt.Errorf("route #%d: receiver %q is not configured (use %s, %s or an enabled channel: %v)",
+				i+1, route.Receiver, AlertRouteReceiverDefault, AlertRouteReceiverNull, constants.RoutedChannels)
+		}
+		if len(route.Severities) == 0 && len(route.Components) == 0 {
+			return fmt.Errorf("route #%d: at least one severity or component is required", i+1)
+		}
+		if err := ValidateAlertSeverities(route.Severities); err != nil {
+			return fmt.Errorf("route #%d: %w", i+1, err)
+		}
+		for _, duration := range []string{route.GroupWait, route.GroupInterval, route.RepeatInterval} {
+			if duration != "" && !alertManagerDuration.MatchString(duration) {
+				return fmt.Errorf("route #%d: invalid duration %q", i+1, duration)
+			}
+		}
+	}
+	return nil
+}
+
+// enabledRoutedReceivers returns the routed channels that have a receiver in a generic AlertManager config
+func enabledRoutedReceivers(amConfig map[string]interface{}) map[string]bool {
+	enabled := make(map[string]bool)
+	receivers, _ := amConfig["receivers"].([]interface{})
+	for _, r := range receivers {
+		if receiver, ok := r.(map[string]interface{}); ok {
+			if name, _ := receiver["name"].(string); constants.IsRoutedChannel(name) {
+				enabled[name] = true
+			}
+		}
+	}
+	return enabled
+}
+
+// enabledRoutedChannels returns the routed channels enabled in an AlertManagerConfig
+func enabledRoutedChannels(config types.AlertManagerConfig) map[string]bool {
+	return map[string]bool{
+		constants.ChannelSlack:     config.Slack.Enabled,
+		constants.ChannelDiscord:   config.Discord.Enabled,
+		constants.ChannelPagerDuty: config.PagerDuty.Enabled,
+		constants.ChannelWebhook:   config.Webhook.Enabled,
+	}
+}
@@ -0,0 +1,77 @@
+package thanos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+func TestSetCustomAlertRoutes(t *testing.T) {
+	amConfig := map[string]interface{}{
+		"route": map[string]interface{}{
+			"receiver": mainAlertReceiverName,
+			"routes": []interface{}{
+				map[string]interface{}{"match": map[string]interface{}{"alertname": "Watchdog"}, "receiver": "null"},
+			},
+		},
+	}
+	setRoutedChannel(amConfig, constants.ChannelSlack, map[string]interface{}{"api_url": "https://hooks.slack.com/x"}, nil)
+
+	routes := []types.AlertRoute{
+		{Receiver: constants.ChannelPagerDuty, Severities: []string{"critical"}, RepeatInterval: "30m"},
+		{Receiver: AlertRouteReceiverNull, Components: []string{"block-explorer"}},
+		{Receiver: AlertRouteReceiverDefault, Severities: []string{"warning", "info"}, GroupBy: []string{"alertname"}, Continue: true},
+	}
+	setCustomAlertRoutes(amConfig, routes)
+
+	children := routesOf(amConfig["route"].(map[string]interface{}))
+	require.Len(t, children, 6, "three custom routes, slack, Watchdog and the catch-all")
+	require.Equal(t, constants.ChannelPagerDuty, children[0].(map[string]interface{})["receiver"])
+	require.Equal(t, []interface{}{`severity=~"critical"`}, children[0].(map[string]interface{})["matchers"])
+	require.Equal(t, mainAlertReceiverName, children[2].(map[string]interface{})["receiver"])
+	require.Equal(t, constants.ChannelSlack, children[3].(map[string]interface{})["receiver"])
+	require.True(t, isCatchAllRoute(children[5].(map[string]interface{}), mainAlertReceiverName))
+
+	// Channel updates keep the custom routes in front
+	setRoutedChannel(amConfig, constants.ChannelSlack, map[string]interface{}{"api_url": "https://hooks.slack.com/y"}, []string{"critical"})
+	require.Equal(t, routes, customAlertRoutes(amConfig))
+
+	setCustomAlertRoutes(amConfig, []types.AlertRoute{})
+	require.Empty(t, customAlertRoutes(amConfig))
+	require.Len(t, routesOf(amConfig["route"].(map[string]interface{})), 3)
+}
+
+func TestValidateAlertRoutes(t *testing.T) {
+	receivers := map[string]bool{constants.ChannelSlack: true}
+
+	require.NoError(t, validateAlertRoutes([]types.AlertRoute{
+		{Receiver: constants.ChannelSlack, Severities: []string{"warning"}, GroupWait: "30s", RepeatInterval: "1h30m"},
+		{Receiver: AlertRouteReceiverNull, Components: []string{"op-batcher"}},
+	}, receivers))
+
+	require.ErrorContains(t, validateAlertRoutes([]types.AlertRoute{{Receiver: constants.ChannelPagerDuty, Severities: []string{"critical"}}}, receivers), "not configured")
+	require.ErrorContains(t, validateAlertRoutes([]types.AlertRoute{{Receiver: AlertRouteReceiverDefault}}, receivers), "at least one severity or component")
+	require.Error(t, validateAlertRoutes([]types.AlertRoute{{Receiver: AlertRouteReceiverDefault, Severities: []string{"page"}}}, receivers))
+	require.ErrorContains(t, validateAlertRoutes([]types.AlertRoute{{Receiver: AlertRouteReceiverDefault, Components: []string{"op-node"}, RepeatInterval: "1 hour"}}, receivers), "invalid duration")
+}
+
+func TestParseAmtoolSilences(t *testing.T) {
+	silences, err := parseAmtoolSilences(`[
+  {"id":"a1","status":{"state":"active"},"matchers":[{"name":"component","value":"op-node","isRegex":false,"isEqual":true}],
+   "startsAt":"2026-01-01T00:00:00Z","endsAt":"2026-01-01T02:00:00Z","createdBy":"ops","comment":"migration"},
+  {"id":"b2","status":{"state":"pending"},"matchers":[{"name":"severity","value":"warning|info","isRegex":true},{"name":"chain_name","value":"x","isRegex":false,"isEqual":false}],
+   "startsAt":"2026-01-02T00:00:00Z","endsAt":"2026-01-02T01:00:00Z","createdBy":"trh-sdk","comment":""}
+]`)
+	require.NoError(t, err)
+	require.Len(t, silences, 2)
+	require.Equal(t, "b2", silences[0].ID, "most recent first")
+	require.Equal(t, []string{`severity=~"warning|info"`, `chain_name!="x"`}, silences[0].Matchers)
+	require.Equal(t, []string{`component="op-node"`}, silences[1].Matchers)
+	require.Equal(t, "active", silences[1].State)
+
+	require.NoError(t, ValidateSilenceMatchers([]string{`component="op-node"`, `severity=~"warning|info"`}))
+	require.Error(t, ValidateSilenceMatchers([]string{"op-node"}))
+}
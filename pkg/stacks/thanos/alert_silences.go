@@ -0,0 +1,223 @@
+package thanos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/user"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// Silences are managed with the amtool binary shipped in the AlertManager image
+const alertManagerLocalURL = "--alertmanager.url=http://localhost:9093"
+
+var silenceMatcherPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(=|!=|=~|!~).*$`)
+
+// alertManagerPod returns the name of a running AlertManager pod
+func alertManagerPod(ctx context.Context) (string, error) {
+	output, err := utils.ExecuteCommand(ctx, "kubectl", "get", "pods", "-n", constants.MonitoringNamespace,
+		"-l", "app.kubernetes.io/name=alertmanager", "--field-selector=status.phase=Running",
+		"-o", "jsonpath={.items[0].metadata.name}")
+	if err != nil || strings.TrimSpace(output) == "" {
+		return "", fmt.Errorf("no running AlertManager pod found in %s namespace", constants.MonitoringNamespace)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// runAmtool runs amtool inside the AlertManager container of the cluster or the local deployment
+func (a *AlertCustomization) runAmtool(ctx context.Context, args ...string) (string, error) {
+	var output string
+	var err error
+	if a.isLocal() {
+		output, err = a.composeCommand(ctx, append([]string{"exec", "-T", "alertmanager", "amtool", alertManagerLocalURL}, args...)...)
+	} else {
+		pod, podErr := alertManagerPod(ctx)
+		if podErr != nil {
+			return "", podErr
+		}
+		cmdArgs := append([]string{"exec", "-n", constants.MonitoringNamespace, pod, "-c", "alertmanager", "--", "amtool", alertManagerLocalURL}, args...)
+		output, err = utils.ExecuteCommand(ctx, "kubectl", cmdArgs...)
+	}
+	if err != nil {
+		return "", fmt.Errorf("amtool %s failed: %w (%s)", args[0], err, strings.TrimSpace(output))
+	}
+	return output, nil
+}
+
+// ValidateSilenceMatchers checks that matchers use the AlertManager label matcher syntax, e.g. component="op-node"
+func ValidateSilenceMatchers(matchers []string) error {
+	for _, matcher := range matchers {
+		if !silenceMatcherPattern.MatchString(matcher) {
+			return fmt.Errorf("invalid matcher %q (expected label=value, label!=value, label=~regex or label!~regex)", matcher)
+		}
+	}
+	return nil
+}
+
+// AddSilence creates an AlertManager silence and returns its ID. Without matchers every alert is silenced.
+func (a *AlertCustomization) AddSilence(ctx context.Context, matchers []string, duration time.Duration, author, comment string) (string, error) {
+	if duration <= 0 {
+		return "", fmt.Errorf("silence duration must be positive")
+	}
+	if err := ValidateSilenceMatchers(matchers); err != nil {
+		return "", err
+	}
+	if len(matchers) == 0 {
+		matchers = []string{`alertname=~".+"`}
+	}
+	if author == "" {
+		author = defaultSilenceAuthor()
+	}
+	if comment == "" {
+		comment = "created by trh-sdk"
+	}
+
+	args := []string{"silence", "add", "--quiet",
+		"--author=" + author,
+		"--comment=" + comment,
+		"--duration=" + duration.String(),
+	}
+	args = append(args, matchers...)
+	output, err := a.runAmtool(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// amtoolSilence is the JSON representation of a silence printed by `amtool silence query -o json`
+type amtoolSilence struct {
+	ID     string `json:"id"`
+	Status struct {
+		State string `json:"state"`
+	} `json:"status"`
+	Matchers []struct {
+		Name    string `json:"name"`
+		Value   string `json:"value"`
+		IsRegex bool   `json:"isRegex"`
+		IsEqual *bool  `json:"isEqual"`
+	} `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+}
+
+// parseAmtoolSilences converts amtool JSON output into silences, most recent first
+func parseAmtoolSilences(output string) ([]types.AlertSilence, error) {
+	var raw []amtoolSilence
+	if err := json.Unmarshal([]byte(output), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse silences: %w", err)
+	}
+
+	silences := make([]types.AlertSilence, 0, len(raw))
+	for _, s := range raw {
+		silence := types.AlertSilence{
+			ID:        s.ID,
+			State:     s.Status.State,
+			StartsAt:  s.StartsAt,
+			EndsAt:    s.EndsAt,
+			CreatedBy: s.CreatedBy,
+			Comment:   s.Comment,
+		}
+		for _, m := range s.Matchers {
+			op := "="
+			equal := m.IsEqual == nil || *m.IsEqual
+			switch {
+			case m.IsRegex && equal:
+				op = "=~"
+			case m.IsRegex:
+				op = "!~"
+			case !equal:
+				op = "!="
+			}
+			silence.Matchers = append(silence.Matchers, fmt.Sprintf("%s%s%q", m.Name, op, m.Value))
+		}
+		silences = append(silences, silence)
+	}
+	sort.Slice(silences, func(i, j int) bool { return silences[i].StartsAt.After(silences[j].StartsAt) })
+	return silences, nil
+}
+
+// ListSilences returns the active and pending silences, and the expired ones when includeExpired is set
+func (a *AlertCustomization) ListSilences(ctx context.Context, includeExpired bool) ([]types.AlertSilence, error) {
+	args := []string{"silence", "query", "-o", "json"}
+	if includeExpired {
+		args = append(args, "--expired")
+	}
+	output, err := a.runAmtool(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	silences, err := parseAmtoolSilences(output)
+	if err != nil {
+		return nil, err
+	}
+	if !includeExpired {
+		return silences, nil
+	}
+
+	// --expired only returns expired silences, so add the active ones
+	active, err := a.ListSilences(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	return append(active, silences...), nil
+}
+
+// ExpireSilence expires a silence by ID
+func (a *AlertCustomization) ExpireSilence(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return fmt.Errorf("silence ID is required")
+	}
+	_, err := a.runAmtool(ctx, "silence", "expire", id)
+	return err
+}
+
+func defaultSilenceAuthor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "trh-sdk"
+}
+
+// silenceAlertsDuring silences the chain's alerts for a maintenance operation and returns a function that
+// expires the silence. It is best-effort: failures are logged and never block the operation.
+// The silence also ends by itself after maxDuration if the process is interrupted.
+func (t *ThanosStack) silenceAlertsDuring(ctx context.Context, operation string, maxDuration time.Duration) func() {
+	noop := func() {}
+	if t.deployConfig == nil || t.deployConfig.K8s == nil || !t.isAWSDeployment() {
+		return noop
+	}
+	if exists, err := utils.CheckNamespaceExists(ctx, constants.MonitoringNamespace); err != nil || !exists {
+		return noop
+	}
+
+	ac := &AlertCustomization{Stack: t}
+	// Rules carry the chain name label; their namespace label is the monitoring namespace
+	matcher := fmt.Sprintf("chain_name=%q", t.deployConfig.ChainName)
+	id, err := ac.AddSilence(ctx, []string{matcher}, maxDuration, "trh-sdk", fmt.Sprintf("Automatic silence during %s", operation))
+	if err != nil {
+		t.logger.Warnw("Failed to silence alerts, alerts may fire during the operation", "operation", operation, "err", err)
+		return noop
+	}
+	t.logger.Infow("Silenced chain alerts during operation", "operation", operation, "silenceID", id, "maxDuration", maxDuration.String())
+
+	return func() {
+		// The operation context may already be cancelled, so expire with a fresh one
+		expireCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := ac.ExpireSilence(expireCtx, id); err != nil {
+			t.logger.Warnw("Failed to expire alert silence, it will end by itself", "silenceID", id, "err", err)
+			return
+		}
+		t.logger.Infow("Expired alert silence", "operation", operation, "silenceID", id)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/tokamak-network/trh-sdk/pkg/types"
 	"github.com/tokamak-network/trh-sdk/pkg/utils"
 )
 
@@ -18,6 +19,7 @@ func CleanupUnusedBackupResources(
 	l *zap.SugaredLogger,
 	region, namespace string,
 	retentionDays int,
+	rules []types.BackupRule,
 ) error {
 	l.Info("🧹 Cleaning up unused backup resources...")
 	if retentionDays <= 0 {
@@ -31,7 +33,7 @@ func CleanupUnusedBackupResources(
 	}
 
 	// 1. Cleanup old recovery points
-	if err := CleanupOldRecoveryPoints(ctx, l, region, namespace, accountID, retentionDays); err != nil {
+	if err := CleanupOldRecoveryPoints(ctx, l, region, namespace, accountID, retentionDays, rules); err != nil {
 		l.Warnf("Failed to cleanup old recovery points: %v", err)
 	}
 
@@ -49,8 +51,9 @@ func CleanupUnusedBackupResources(
 	return nil
 }
 
-// CleanupOldRecoveryPoints removes recovery points older than retentionDays
-func CleanupOldRecoveryPoints(ctx context.Context, l *zap.SugaredLogger, region, namespace, accountID string, retentionDays int) error {
+// CleanupOldRecoveryPoints removes recovery points past the retention of the backup rule that created them.
+// Points of on-demand backups and of rules that are not configured are kept retentionDays.
+func CleanupOldRecoveryPoints(ctx context.Context, l *zap.SugaredLogger, region, namespace, accountID string, retentionDays int, rules []types.BackupRule) error {
 	l.Info("🗑️ Cleaning up old recovery points...")
 
 	// Get current EFS ID to find its recovery points
@@ -61,52 +64,73 @@ func CleanupOldRecoveryPoints(ctx context.Context, l *zap.SugaredLogger, region,
 	}
 
 	arn := utils.BuildEFSArn(region, accountID, currentEfsID)
+	vaultName := fmt.Sprintf("%s-backup-vault", namespace)
 
-	// List recovery points older than retentionDays
 	if retentionDays <= 0 {
 		retentionDays = 14
 	}
-	cutoffDate := time.Now().AddDate(0, 0, -retentionDays).Format("2006-01-02T15:04:05.000000-07:00")
 
-	rpList, err := utils.ExecuteCommand(ctx, "aws", "backup", "list-recovery-points-by-resource",
+	rpList, err := utils.ExecuteCommand(ctx, "aws", "backup", "list-recovery-points-by-backup-vault",
 		"--region", region,
-		"--resource-arn", arn,
-		"--query", fmt.Sprintf("RecoveryPoints[?CreationDate<='%s'].{Arn:RecoveryPointArn,Created:CreationDate}", cutoffDate),
+		"--backup-vault-name", vaultName,
+		"--by-resource-arn", arn,
+		"--query", "RecoveryPoints[].{Arn:RecoveryPointArn,Created:CreationDate,RuleId:CreatedBy.BackupRuleId}",
 		"--output", "json")
 
 	if err != nil {
 		return fmt.Errorf("failed to list old recovery points: %w", err)
 	}
 
-	if strings.TrimSpace(rpList) == "[]" || strings.TrimSpace(rpList) == "" {
+	if strings.TrimSpace(rpList) == "[]" || strings.TrimSpace(rpList) == "" || strings.TrimSpace(rpList) == "null" {
 		l.Info("No old recovery points found to cleanup")
 		return nil
 	}
 
-	// Parse and delete old recovery points
-	var recoveryPoints []struct {
+	var listed []struct {
 		Arn     string `json:"Arn"`
 		Created string `json:"Created"`
+		RuleId  string `json:"RuleId"`
 	}
 
-	if err := json.Unmarshal([]byte(rpList), &recoveryPoints); err != nil {
+	if err := json.Unmarshal([]byte(rpList), &listed); err != nil {
 		return fmt.Errorf("failed to parse recovery points list: %w", err)
 	}
 
+	// Find the rule of each scheduled recovery point, by rule ID or, for rules replaced since, by its tag
+	ruleNames := backupPlanRuleNames(ctx, region, namespace)
+	points := make([]RuleRecoveryPoint, 0, len(listed))
+	for _, rp := range listed {
+		point := RuleRecoveryPoint{ARN: rp.Arn, Created: rp.Created}
+		if rp.RuleId != "" {
+			name, ok := ruleNames[rp.RuleId]
+			if !ok {
+				name = recoveryPointRuleTag(ctx, region, rp.Arn)
+			}
+			point.RuleName = name
+		}
+		points = append(points, point)
+	}
+
+	expired := ExpiredRecoveryPoints(points, rules, retentionDays, time.Now())
+	if len(expired) == 0 {
+		l.Info("No old recovery points found to cleanup")
+		return nil
+	}
+
 	deletedCount := 0
-	for _, rp := range recoveryPoints {
-		l.Infof("Deleting old recovery point: %s (created: %s)", rp.Arn, rp.Created)
+	for _, rp := range expired {
+		l.Infof("Deleting old recovery point: %s (created: %s)", rp.ARN, rp.Created)
 
 		_, err := utils.ExecuteCommand(ctx, "aws", "backup", "delete-recovery-point",
 			"--region", region,
-			"--backup-vault-name", fmt.Sprintf("%s-backup-vault", namespace),
-			"--recovery-point-arn", rp.Arn)
+			"--backup-vault-name", vaultName,
+			"--recovery-point-arn", rp.ARN)
 
 		if err != nil {
-			l.Warnf("Failed to delete recovery point %s: %v", rp.Arn, err)
+			l.Warnf("Failed to delete recovery point %s: %v", rp.ARN, err)
 		} else {
 			deletedCount++
-			l.Infof("✅ Deleted recovery point: %s", rp.Arn)
+			l.Infof("✅ Deleted recovery point: %s", rp.ARN)
 		}
 	}
 
@@ -117,6 +141,51 @@ func CleanupOldRecoveryPoints(ctx context.Context, l *zap.SugaredLogger, region,
 	return nil
 }
 
+// backupPlanRuleNames maps the rule IDs of the backup plan to the rule names, empty when the plan cannot be read
+func backupPlanRuleNames(ctx context.Context, region, namespace string) map[string]string {
+	names := map[string]string{}
+	planID, err := findBackupPlanID(ctx, region, namespace)
+	if err != nil {
+		return names
+	}
+	out, err := utils.ExecuteCommand(ctx, "aws", "backup", "get-backup-plan",
+		"--region", region,
+		"--backup-plan-id", planID,
+		"--query", "BackupPlan.Rules[].{Id:RuleId,Name:RuleName}",
+		"--output", "json")
+	if err != nil {
+		return names
+	}
+	var rules []struct {
+		Id   string `json:"Id"`
+		Name string `json:"Name"`
+	}
+	if err := json.Unmarshal([]byte(out), &rules); err != nil {
+		return names
+	}
+	for _, rule := range rules {
+		names[rule.Id] = rule.Name
+	}
+	return names
+}
+
+// recoveryPointRuleTag returns the rule a recovery point is tagged with, empty when it has no rule tag
+func recoveryPointRuleTag(ctx context.Context, region, recoveryPointArn string) string {
+	out, err := utils.ExecuteCommand(ctx, "aws", "backup", "list-tags",
+		"--region", region,
+		"--resource-arn", recoveryPointArn,
+		"--query", fmt.Sprintf("Tags.%s", ruleTagKey),
+		"--output", "text")
+	if err != nil {
+		return ""
+	}
+	name := strings.TrimSpace(out)
+	if name == "None" {
+		return ""
+	}
+	return name
+}
+
 // CleanupUnusedEFS removes EFS filesystems that are not currently in use
 func CleanupUnusedEFS(ctx context.Context, l *zap.SugaredLogger, region, namespace string, retentionDays int) error {
 	l.Info("🗑️ Cleaning up unused EFS filesystems...")
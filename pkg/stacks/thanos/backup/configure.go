@@ -14,11 +14,16 @@ import (
 func GatherBackupConfigInfo(
 	region, namespace string,
 	daily *string, keep *string, reset *bool,
+	copyRegion *string, copyKeep *string,
+	ruleSpecs []string, gfs *bool,
 	infof func(string, ...any),
 ) (*types.BackupConfigInfo, error) {
 	if (daily == nil || strings.TrimSpace(*daily) == "") &&
 		(keep == nil || strings.TrimSpace(*keep) == "") &&
-		(reset == nil || !*reset) {
+		(reset == nil || !*reset) &&
+		(copyRegion == nil || strings.TrimSpace(*copyRegion) == "") &&
+		(copyKeep == nil || strings.TrimSpace(*copyKeep) == "") &&
+		len(ruleSpecs) == 0 && (gfs == nil || !*gfs) {
 		if infof != nil {
 			infof("📋 Backup Configuration Usage")
 			infof("")
@@ -29,22 +34,55 @@ func GatherBackupConfigInfo(
 			infof("  --daily HH:MM     Daily backup time in UTC (e.g., 03:00)")
 			infof("  --keep DAYS       Retention days (0 = unlimited)")
 			infof("  --reset           Reset to defaults (03:00 UTC, unlimited)")
+			infof("  --copy-region R   Copy recovery points to region R for failover (none = off)")
+			infof("  --copy-keep DAYS  Retention days of the copies (0 = unlimited)")
+			infof("  --gfs             Hourly, daily, weekly and monthly rules (grandfather-father-son)")
+			infof("  --rule SPEC       Add or change a rule: NAME[:keep=DAYS,cold=DAYS,at=HH:MM,day=D,tag.KEY=VALUE]")
+			infof("                    NAME is hourly, daily, weekly or monthly, repeat for several rules")
 			infof("")
 			infof("EXAMPLES:")
 			infof("  trh-sdk backup-manager --config --daily 02:30")
 			infof("  trh-sdk backup-manager --config --keep 60")
 			infof("  trh-sdk backup-manager --config --daily 01:00 --keep 30")
 			infof("  trh-sdk backup-manager --config --reset")
+			infof("  trh-sdk backup-manager --config --copy-region us-west-2 --copy-keep 30")
+			infof("  trh-sdk backup-manager --config --gfs")
+			infof("  trh-sdk backup-manager --config --rule monthly:keep=730,cold=60,tag.Compliance=audit")
 		}
 		return nil, nil
 	}
 
+	var rules []types.BackupRule
+	if getBoolValue(gfs) {
+		rules = DefaultBackupRules()
+	}
+	var overrides []types.BackupRule
+	for _, spec := range ruleSpecs {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		rule, err := ParseBackupRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, rule)
+	}
+	if len(overrides) > 0 {
+		rules = MergeBackupRules(rules, overrides)
+	}
+	if len(rules) > 0 && getBoolValue(reset) {
+		return nil, fmt.Errorf("--reset removes the backup rules and cannot be combined with --gfs or --rule")
+	}
+
 	return &types.BackupConfigInfo{
-		Region:    region,
-		Namespace: namespace,
-		Daily:     getStringValue(daily),
-		Keep:      getStringValue(keep),
-		Reset:     getBoolValue(reset),
+		Region:     region,
+		Namespace:  namespace,
+		Daily:      getStringValue(daily),
+		Keep:       getStringValue(keep),
+		Reset:      getBoolValue(reset),
+		CopyRegion: getStringValue(copyRegion),
+		CopyKeep:   getStringValue(copyKeep),
+		Rules:      rules,
 	}, nil
 }
 
@@ -154,7 +192,8 @@ func ConfigureExecute(
 	return exec(ctx, info)
 }
 
-// ExecuteBackupConfiguration verifies terraform paths and runs terraform using injected helpers
+// ExecuteBackupConfiguration verifies terraform paths and runs terraform using injected helpers.
+// Copy settings alone do not need terraform and are skipped.
 func ExecuteBackupConfiguration(
 	ctx context.Context,
 	deploymentPath string,
@@ -162,7 +201,7 @@ func ExecuteBackupConfiguration(
 	buildArgs func(*types.BackupConfigInfo) []string,
 	execTerraform func(context.Context, string, []string) error,
 ) error {
-	if info == nil {
+	if info == nil || !NeedsTerraform(info) {
 		return nil
 	}
 	tfRoot := fmt.Sprintf("%s/tokamak-thanos-stack/terraform", deploymentPath)
@@ -178,6 +217,11 @@ func ExecuteBackupConfiguration(
 	return execTerraform(ctx, tfRoot, varArgs)
 }
 
+// NeedsTerraform reports whether the configuration changes the schedule or retention managed by terraform
+func NeedsTerraform(info *types.BackupConfigInfo) bool {
+	return info.Daily != "" || info.Keep != "" || info.Reset
+}
+
 // helpers
 func convertTimeToCron(timeStr string) string {
 	parts := strings.Split(timeStr, ":")
@@ -0,0 +1,230 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// CopyVaultARN is the vault recovery points are copied to. It has the same name as the vault of the chain,
+// in the copy region.
+func CopyVaultARN(copyRegion, accountID, namespace string) string {
+	return fmt.Sprintf("arn:aws:backup:%s:%s:backup-vault:%s-backup-vault", copyRegion, accountID, namespace)
+}
+
+// RegionFromARN returns the region of an ARN, empty when it has none
+func RegionFromARN(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 4 {
+		return ""
+	}
+	return parts[3]
+}
+
+// ensureCopyVault creates the vault of the copy region when it does not exist
+func ensureCopyVault(ctx context.Context, l *zap.SugaredLogger, copyRegion, namespace string) error {
+	vaultName := fmt.Sprintf("%s-backup-vault", namespace)
+	if _, err := utils.ExecuteCommand(ctx, "aws", "backup", "describe-backup-vault",
+		"--region", copyRegion,
+		"--backup-vault-name", vaultName); err == nil {
+		return nil
+	}
+	if _, err := utils.ExecuteCommand(ctx, "aws", "backup", "create-backup-vault",
+		"--region", copyRegion,
+		"--backup-vault-name", vaultName,
+		"--backup-vault-tags", fmt.Sprintf("Namespace=%s", namespace)); err != nil {
+		return fmt.Errorf("failed to create backup vault %s in %s: %w", vaultName, copyRegion, err)
+	}
+	l.Infof("Created backup vault %s in %s", vaultName, copyRegion)
+	return nil
+}
+
+// GetCopyDestination returns the vault the backup plan copies recovery points to, empty when it does not copy them
+func GetCopyDestination(ctx context.Context, region, namespace string) (string, error) {
+	planID, err := findBackupPlanID(ctx, region, namespace)
+	if err != nil {
+		return "", err
+	}
+	out, err := utils.ExecuteCommand(ctx, "aws", "backup", "get-backup-plan",
+		"--region", region,
+		"--backup-plan-id", planID,
+		"--query", "BackupPlan.Rules[0].CopyActions[0].DestinationBackupVaultArn",
+		"--output", "text")
+	if err != nil {
+		return "", fmt.Errorf("failed to get backup plan %s: %w", planID, err)
+	}
+	arn := strings.TrimSpace(out)
+	if arn == "None" {
+		return "", nil
+	}
+	return arn, nil
+}
+
+// CopySnapshotToRegion copies the recovery points created by the backup jobs to the copy region vault and
+// returns the copy job IDs. On-demand backups are not covered by the copy actions of the backup plan.
+func CopySnapshotToRegion(ctx context.Context, l *zap.SugaredLogger, region, namespace, copyRegion string, deleteAfterDays int, backupJobIDs ...string) ([]string, error) {
+	accountID, err := utils.DetectAWSAccountID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	iamRoleArn := fmt.Sprintf("arn:aws:iam::%s:role/%s-backup-service-role", accountID, namespace)
+
+	var copyJobIDs []string
+	for _, jobID := range backupJobIDs {
+		if jobID == "" {
+			continue
+		}
+		recoveryPointArn, err := utils.ExecuteCommand(ctx, "aws", "backup", "describe-backup-job",
+			"--region", region,
+			"--backup-job-id", jobID,
+			"--query", "RecoveryPointArn",
+			"--output", "text")
+		if err != nil {
+			return copyJobIDs, fmt.Errorf("failed to read the recovery point of backup job %s: %w", jobID, err)
+		}
+		args := []string{"backup", "start-copy-job",
+			"--region", region,
+			"--recovery-point-arn", strings.TrimSpace(recoveryPointArn),
+			"--source-backup-vault-name", fmt.Sprintf("%s-backup-vault", namespace),
+			"--destination-backup-vault-arn", CopyVaultARN(copyRegion, accountID, namespace),
+			"--iam-role-arn", iamRoleArn,
+			"--query", "CopyJobId",
+			"--output", "text",
+		}
+		if deleteAfterDays > 0 {
+			args = append(args, "--lifecycle", fmt.Sprintf("DeleteAfterDays=%d", deleteAfterDays))
+		}
+		copyJobID, err := utils.ExecuteCommand(ctx, "aws", args...)
+		if err != nil {
+			return copyJobIDs, fmt.Errorf("failed to copy %s to %s: %w", strings.TrimSpace(recoveryPointArn), copyRegion, err)
+		}
+		copyJobIDs = append(copyJobIDs, strings.TrimSpace(copyJobID))
+		l.Infof("🌐 Copy to %s started, job ID: %s", copyRegion, strings.TrimSpace(copyJobID))
+	}
+	return copyJobIDs, nil
+}
+
+// ListCopiedRecoveryPoints lists the EFS recovery points in the vault of the copy region, newest first
+func ListCopiedRecoveryPoints(ctx context.Context, copyRegion, namespace string) ([]types.RecoveryPoint, error) {
+	out, err := utils.ExecuteCommand(ctx, "aws", "backup", "list-recovery-points-by-backup-vault",
+		"--region", copyRegion,
+		"--backup-vault-name", fmt.Sprintf("%s-backup-vault", namespace),
+		"--by-resource-type", "EFS",
+		"--query", "RecoveryPoints[].{RecoveryPointARN:RecoveryPointArn,Vault:BackupVaultName,Created:CreationDate,Expiry:CalculatedLifecycle.DeleteAt,Status:Status}",
+		"--output", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recovery points in %s: %w", copyRegion, err)
+	}
+	out = strings.TrimSpace(out)
+	if out == "" || out == "null" || out == "[]" {
+		return nil, nil
+	}
+	var rps []types.RecoveryPoint
+	if err := json.Unmarshal([]byte(out), &rps); err != nil {
+		return nil, fmt.Errorf("failed to parse recovery points: %w", err)
+	}
+	sort.SliceStable(rps, func(i, j int) bool {
+		ti, _ := parseRecoveryPointTime(rps[i].Created)
+		tj, _ := parseRecoveryPointTime(rps[j].Created)
+		return ti.After(tj)
+	})
+	return rps, nil
+}
+
+// LatestCompletedRecoveryPoint returns the newest completed recovery point, nil when there is none
+func LatestCompletedRecoveryPoint(rps []types.RecoveryPoint) *types.RecoveryPoint {
+	var latest *types.RecoveryPoint
+	for i := range rps {
+		if rps[i].Status != "COMPLETED" {
+			continue
+		}
+		created, ok := parseRecoveryPointTime(rps[i].Created)
+		if !ok {
+			continue
+		}
+		if latest == nil {
+			latest = &rps[i]
+			continue
+		}
+		if best, _ := parseRecoveryPointTime(latest.Created); created.After(best) {
+			latest = &rps[i]
+		}
+	}
+	return latest
+}
+
+// CreateEFSMountTargetsForCluster creates a mount target for the file system in every subnet of the EKS cluster,
+// with the cluster security group. It is used when there is no EFS in the region to copy mount targets from.
+func CreateEFSMountTargetsForCluster(ctx context.Context, l *zap.SugaredLogger, region, clusterName, fsID string) error {
+	out, err := utils.ExecuteCommand(ctx, "aws", "eks", "describe-cluster",
+		"--region", region,
+		"--name", clusterName,
+		"--query", "cluster.resourcesVpcConfig.{SubnetIds:subnetIds,SecurityGroupId:clusterSecurityGroupId}",
+		"--output", "json")
+	if err != nil {
+		return fmt.Errorf("failed to describe cluster %s: %w", clusterName, err)
+	}
+	var vpcConfig struct {
+		SubnetIds       []string `json:"SubnetIds"`
+		SecurityGroupId string   `json:"SecurityGroupId"`
+	}
+	if err := json.Unmarshal([]byte(out), &vpcConfig); err != nil {
+		return fmt.Errorf("failed to parse the VPC config of cluster %s: %w", clusterName, err)
+	}
+	if len(vpcConfig.SubnetIds) == 0 || vpcConfig.SecurityGroupId == "" {
+		return fmt.Errorf("cluster %s has no subnets or security group", clusterName)
+	}
+
+	// EFS allows one mount target per availability zone
+	zones := map[string]bool{}
+	for _, subnetID := range vpcConfig.SubnetIds {
+		zone, err := utils.ExecuteCommand(ctx, "aws", "ec2", "describe-subnets",
+			"--region", region,
+			"--subnet-ids", subnetID,
+			"--query", "Subnets[0].AvailabilityZone",
+			"--output", "text")
+		if err != nil {
+			l.Warnf("Failed to read the availability zone of subnet %s: %v", subnetID, err)
+			continue
+		}
+		zone = strings.TrimSpace(zone)
+		if zones[zone] {
+			continue
+		}
+		if _, err := utils.ExecuteCommand(ctx, "aws", "efs", "create-mount-target",
+			"--region", region,
+			"--file-system-id", fsID,
+			"--subnet-id", subnetID,
+			"--security-groups", vpcConfig.SecurityGroupId); err != nil {
+			l.Infof("Note: create-mount-target may have failed/exists for subnet %s: %v", subnetID, err)
+			continue
+		}
+		zones[zone] = true
+		l.Infof("Created mount target on subnet %s (AZ %s) for %s", subnetID, zone, fsID)
+	}
+	if len(zones) == 0 {
+		return fmt.Errorf("no mount targets could be created for %s", fsID)
+	}
+	return nil
+}
+
+// ParseCopyKeep parses the --copy-keep days, empty or "0" keeps copies until deleted
+func ParseCopyKeep(keep string) (int, error) {
+	keep = strings.TrimSpace(keep)
+	if keep == "" {
+		return 0, nil
+	}
+	days, err := strconv.Atoi(keep)
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("invalid --copy-keep %q: expected a number of days", keep)
+	}
+	return days, nil
+}
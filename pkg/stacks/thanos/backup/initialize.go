@@ -49,8 +49,12 @@ func executeInitialBackup(ctx context.Context, l *zap.SugaredLogger, region, acc
 // startBackupJob starts a backup job for the specified EFS
 // Note: Backup vault is already created by Terraform
 func startBackupJob(ctx context.Context, region, accountID, efsID, namespace string) (string, error) {
+	return startBackupJobForResource(ctx, region, accountID, utils.BuildEFSArn(region, accountID, efsID), namespace)
+}
+
+// startBackupJobForResource starts a backup job for an EFS or RDS resource in the namespace backup vault
+func startBackupJobForResource(ctx context.Context, region, accountID, resourceArn, namespace string) (string, error) {
 	iamRoleArn := fmt.Sprintf("arn:aws:iam::%s:role/service-role/AWSBackupDefaultServiceRole", accountID)
-	efsArn := utils.BuildEFSArn(region, accountID, efsID)
 
 	// Use namespace-specific backup vault (created by Terraform)
 	vaultName := fmt.Sprintf("%s-backup-vault", namespace)
@@ -59,7 +63,7 @@ func startBackupJob(ctx context.Context, region, accountID, efsID, namespace str
 	jobID, err := utils.ExecuteCommand(ctx, "aws", "backup", "start-backup-job",
 		"--region", region,
 		"--backup-vault-name", vaultName,
-		"--resource-arn", efsArn,
+		"--resource-arn", resourceArn,
 		"--iam-role-arn", iamRoleArn,
 		"--query", "BackupJobId",
 		"--output", "text")
@@ -124,5 +128,10 @@ func InitializeBackupSystem(ctx context.Context, l *zap.SugaredLogger, region, n
 	if err := executeInitialBackup(ctx, l, region, accountID, efsID, namespace); err != nil {
 		return fmt.Errorf("failed to execute initial backup: %w", err)
 	}
+
+	// 4. Back up the block explorer database on the same schedule, when it is installed
+	if err := EnableRDSBackup(ctx, l, region, namespace); err != nil {
+		l.Warnf("Failed to enable block explorer database backup: %v", err)
+	}
 	return nil
 }
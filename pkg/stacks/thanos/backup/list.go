@@ -79,6 +79,9 @@ func DisplayRecoveryPoints(l *zap.SugaredLogger, rps []types.RecoveryPoint) {
 			expiryRelative := formatRelativeTime(rp.Expiry)
 			l.Infof("    ⏰ Expires  : %s %s", rp.Expiry, expiryRelative)
 		}
+		if rp.RDSRecoveryPointARN != "" {
+			l.Infof("    🗄️  Explorer : %s", rp.RDSRecoveryPointARN)
+		}
 
 		l.Infof("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 
@@ -0,0 +1,338 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// rdsPairingWindow is how far apart the EFS and RDS recovery points of one backup run may be created
+const rdsPairingWindow = 2 * time.Hour
+
+// rdsAvailableTimeout bounds the wait for a renamed or restored RDS instance
+const rdsAvailableTimeout = 60 * time.Minute
+
+// rdsInstanceInfo holds the settings of the current instance the restored one is created with
+type rdsInstanceInfo struct {
+	Class              string   `json:"Class"`
+	SubnetGroup        string   `json:"SubnetGroup"`
+	SecurityGroups     []string `json:"SecurityGroups"`
+	PubliclyAccessible bool     `json:"PubliclyAccessible"`
+}
+
+// IsRDSRecoveryPoint reports whether the recovery point ARN is an AWS Backup RDS snapshot
+func IsRDSRecoveryPoint(arn string) bool {
+	return strings.HasPrefix(arn, "arn:aws:rds:") && strings.Contains(arn, ":snapshot:")
+}
+
+// EnableRDSBackup adds the block explorer database to the namespace backup plan and starts its initial backup.
+// It does nothing when the explorer database does not exist.
+func EnableRDSBackup(ctx context.Context, l *zap.SugaredLogger, region, namespace string) error {
+	rdsArn, err := utils.DetectRDSArn(ctx, region, namespace)
+	if err != nil {
+		l.Infof("Block explorer database not found, skipping RDS backup: %v", err)
+		return nil
+	}
+
+	accountID, err := utils.DetectAWSAccountID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to detect AWS account ID: %w", err)
+	}
+
+	if err := ensureRDSBackupSelection(ctx, l, region, accountID, namespace, rdsArn); err != nil {
+		return fmt.Errorf("failed to schedule RDS backups: %w", err)
+	}
+
+	jobID, err := startBackupJobForResource(ctx, region, accountID, rdsArn, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to start RDS backup job: %w", err)
+	}
+	l.Infof("✅ Initial block explorer database backup job started: %s", jobID)
+	return nil
+}
+
+// ensureRDSBackupSelection assigns the RDS instance to the namespace backup plan, so it is backed up on the EFS schedule
+func ensureRDSBackupSelection(ctx context.Context, l *zap.SugaredLogger, region, accountID, namespace, rdsArn string) error {
+	planID, err := findBackupPlanID(ctx, region, namespace)
+	if err != nil {
+		return err
+	}
+
+	selectionName := fmt.Sprintf("%s-rds-selection", namespace)
+	count, err := utils.ExecuteCommand(ctx, "aws", "backup", "list-backup-selections",
+		"--region", region,
+		"--backup-plan-id", planID,
+		"--query", fmt.Sprintf("length(BackupSelectionsList[?SelectionName=='%s'])", selectionName),
+		"--output", "text")
+	if err != nil {
+		return fmt.Errorf("failed to list backup selections: %w", err)
+	}
+	if strings.TrimSpace(count) != "0" {
+		l.Infof("Block explorer database is already in backup plan (selection: %s)", selectionName)
+		return nil
+	}
+
+	selection, err := json.Marshal(map[string]interface{}{
+		"SelectionName": selectionName,
+		"IamRoleArn":    fmt.Sprintf("arn:aws:iam::%s:role/service-role/AWSBackupDefaultServiceRole", accountID),
+		"Resources":     []string{rdsArn},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := utils.ExecuteCommand(ctx, "aws", "backup", "create-backup-selection",
+		"--region", region,
+		"--backup-plan-id", planID,
+		"--backup-selection", string(selection)); err != nil {
+		return fmt.Errorf("failed to create backup selection %s: %w", selectionName, err)
+	}
+	l.Infof("✅ Block explorer database added to backup plan (selection: %s)", selectionName)
+	return nil
+}
+
+// findBackupPlanID returns the ID of the namespace backup plan created by Terraform
+func findBackupPlanID(ctx context.Context, region, namespace string) (string, error) {
+	planName := fmt.Sprintf("%s-backup-plan", namespace)
+	out, err := utils.ExecuteCommand(ctx, "aws", "backup", "list-backup-plans",
+		"--region", region,
+		"--query", fmt.Sprintf("BackupPlansList[?BackupPlanName=='%s'].BackupPlanId | [0]", planName),
+		"--output", "text")
+	if err != nil {
+		return "", fmt.Errorf("failed to list backup plans: %w", err)
+	}
+	planID := strings.TrimSpace(out)
+	if planID == "" || planID == "None" {
+		return "", fmt.Errorf("no backup plan found with name '%s'", planName)
+	}
+	return planID, nil
+}
+
+// PairRDSRecoveryPoints sets RDSRecoveryPointARN of each EFS recovery point to the completed RDS recovery
+// point created closest to it, within rdsPairingWindow
+func PairRDSRecoveryPoints(efsPoints, rdsPoints []types.RecoveryPoint) {
+	for i := range efsPoints {
+		efsPoints[i].RDSRecoveryPointARN = closestRDSRecoveryPoint(efsPoints[i].Created, rdsPoints)
+	}
+}
+
+func closestRDSRecoveryPoint(created string, rdsPoints []types.RecoveryPoint) string {
+	target, ok := parseRecoveryPointTime(created)
+	if !ok {
+		return ""
+	}
+	closest := ""
+	best := rdsPairingWindow
+	for _, rp := range rdsPoints {
+		if strings.ToUpper(rp.Status) != "COMPLETED" {
+			continue
+		}
+		t, ok := parseRecoveryPointTime(rp.Created)
+		if !ok {
+			continue
+		}
+		diff := t.Sub(target)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= best {
+			best = diff
+			closest = rp.RecoveryPointARN
+		}
+	}
+	return closest
+}
+
+// parseRecoveryPointTime parses a recovery point CreationDate as printed by the AWS CLI
+func parseRecoveryPointTime(timestamp string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(timestamp))
+	return t, err == nil
+}
+
+// ListRDSRecoveryPoints lists the recovery points of the block explorer database. It returns an empty
+// ARN when the explorer database does not exist.
+func ListRDSRecoveryPoints(ctx context.Context, region, namespace, limit string) (string, []types.RecoveryPoint, error) {
+	rdsArn, err := utils.DetectRDSArn(ctx, region, namespace)
+	if err != nil {
+		return "", nil, nil
+	}
+	rps, err := ListRecoveryPoints(ctx, region, rdsArn, limit)
+	if err != nil {
+		return rdsArn, nil, err
+	}
+	return rdsArn, rps, nil
+}
+
+// FindPairedRDSRecoveryPoint returns the block explorer database recovery point taken with the EFS recovery point,
+// empty when there is none
+func FindPairedRDSRecoveryPoint(ctx context.Context, region, namespace, efsRecoveryPointArn string) (string, error) {
+	created, err := DescribeRecoveryPointCreation(ctx, region, namespace, efsRecoveryPointArn)
+	if err != nil {
+		return "", err
+	}
+	_, rps, err := ListRDSRecoveryPoints(ctx, region, namespace, "100")
+	if err != nil {
+		return "", fmt.Errorf("failed to list RDS recovery points: %w", err)
+	}
+	return closestRDSRecoveryPoint(created, rps), nil
+}
+
+// DescribeRecoveryPointCreation returns the CreationDate of a recovery point in the namespace backup vault
+func DescribeRecoveryPointCreation(ctx context.Context, region, namespace, recoveryPointArn string) (string, error) {
+	created, err := utils.ExecuteCommand(ctx, "aws", "backup", "describe-recovery-point",
+		"--region", region,
+		"--backup-vault-name", fmt.Sprintf("%s-backup-vault", namespace),
+		"--recovery-point-arn", recoveryPointArn,
+		"--query", "CreationDate",
+		"--output", "text")
+	if err != nil {
+		return "", fmt.Errorf("failed to describe recovery point: %w", err)
+	}
+	return strings.TrimSpace(created), nil
+}
+
+// RestoreRDS replaces the block explorer database with an RDS recovery point. The current instance is renamed
+// to <identifier>-pre-restore-<unix time> and kept for rollback; the restored instance takes over its identifier,
+// so the endpoint in the explorer connection URL stays the same. It returns the name of the replaced instance.
+func RestoreRDS(ctx context.Context, l *zap.SugaredLogger, region, namespace, rdsRecoveryPointArn string) (string, error) {
+	if !IsRDSRecoveryPoint(rdsRecoveryPointArn) {
+		return "", fmt.Errorf("invalid RDS recovery point ARN: %s", rdsRecoveryPointArn)
+	}
+	identifier := utils.RDSIdentifierFromNamespace(namespace)
+
+	out, err := utils.ExecuteCommand(ctx, "aws", "rds", "describe-db-instances",
+		"--region", region,
+		"--db-instance-identifier", identifier,
+		"--query", "DBInstances[0].{Class:DBInstanceClass,SubnetGroup:DBSubnetGroup.DBSubnetGroupName,SecurityGroups:VpcSecurityGroups[].VpcSecurityGroupId,PubliclyAccessible:PubliclyAccessible}",
+		"--output", "json")
+	if err != nil {
+		return "", fmt.Errorf("failed to describe RDS instance %s: %w", identifier, err)
+	}
+	var current rdsInstanceInfo
+	if err := json.Unmarshal([]byte(out), &current); err != nil {
+		return "", fmt.Errorf("failed to parse RDS instance %s: %w", identifier, err)
+	}
+
+	previous := fmt.Sprintf("%s-pre-restore-%d", identifier, time.Now().Unix())
+	l.Infof("🗄️  Renaming block explorer database %s to %s...", identifier, previous)
+	if _, err := utils.ExecuteCommand(ctx, "aws", "rds", "modify-db-instance",
+		"--region", region,
+		"--db-instance-identifier", identifier,
+		"--new-db-instance-identifier", previous,
+		"--apply-immediately"); err != nil {
+		return "", fmt.Errorf("failed to rename RDS instance %s: %w", identifier, err)
+	}
+	if err := waitForRDSAvailable(ctx, l, region, previous); err != nil {
+		return previous, err
+	}
+
+	l.Infof("🗄️  Restoring block explorer database %s from %s...", identifier, rdsRecoveryPointArn)
+	args := []string{"rds", "restore-db-instance-from-db-snapshot",
+		"--region", region,
+		"--db-instance-identifier", identifier,
+		"--db-snapshot-identifier", rdsRecoveryPointArn,
+		"--db-instance-class", current.Class,
+		"--db-subnet-group-name", current.SubnetGroup,
+	}
+	if len(current.SecurityGroups) > 0 {
+		args = append(args, "--vpc-security-group-ids")
+		args = append(args, current.SecurityGroups...)
+	}
+	if current.PubliclyAccessible {
+		args = append(args, "--publicly-accessible")
+	} else {
+		args = append(args, "--no-publicly-accessible")
+	}
+	if _, err := utils.ExecuteCommand(ctx, "aws", args...); err != nil {
+		l.Errorf("❌ RDS restore failed. To put the previous database back, run:")
+		l.Errorf("   aws rds modify-db-instance --region %s --db-instance-identifier %s --new-db-instance-identifier %s --apply-immediately", region, previous, identifier)
+		return previous, fmt.Errorf("failed to restore RDS instance %s: %w", identifier, err)
+	}
+	if err := waitForRDSAvailable(ctx, l, region, identifier); err != nil {
+		return previous, err
+	}
+
+	l.Infof("✅ Block explorer database restored. Previous instance kept as %s; delete it once the explorer is verified.", previous)
+	restartBlockExplorerBackend(ctx, l, namespace)
+	return previous, nil
+}
+
+// waitForRDSAvailable polls the RDS instance until it is available. Lookups fail for a short while after a rename.
+func waitForRDSAvailable(ctx context.Context, l *zap.SugaredLogger, region, identifier string) error {
+	deadline := time.Now().Add(rdsAvailableTimeout)
+	for time.Now().Before(deadline) {
+		status, err := utils.ExecuteCommand(ctx, "aws", "rds", "describe-db-instances",
+			"--region", region,
+			"--db-instance-identifier", identifier,
+			"--query", "DBInstances[0].DBInstanceStatus",
+			"--output", "text")
+		if err == nil {
+			status = strings.TrimSpace(status)
+			if status == "available" {
+				return nil
+			}
+			l.Infof("RDS instance %s status: %s", identifier, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(15 * time.Second):
+		}
+	}
+	return fmt.Errorf("RDS instance %s did not become available within %s", identifier, rdsAvailableTimeout)
+}
+
+// restartBlockExplorerBackend restarts the explorer backend so it reconnects to the restored database
+func restartBlockExplorerBackend(ctx context.Context, l *zap.SugaredLogger, namespace string) {
+	releases, err := utils.FilterHelmReleases(ctx, namespace, "block-explorer-be")
+	if err != nil {
+		l.Warnf("Failed to list block explorer releases: %v", err)
+		return
+	}
+	for _, release := range releases {
+		if _, err := utils.ExecuteCommand(ctx, "kubectl", "rollout", "restart", "deployment",
+			"-n", namespace,
+			"-l", fmt.Sprintf("app.kubernetes.io/instance=%s", release)); err != nil {
+			l.Warnf("Failed to restart block explorer backend %s: %v", release, err)
+			continue
+		}
+		l.Infof("🔄 Restarted block explorer backend %s", release)
+	}
+}
+
+// RemoveRDSBackupSelection removes the block explorer database from the namespace backup plan. Terraform cannot
+// delete a backup plan that still has selections it did not create.
+func RemoveRDSBackupSelection(ctx context.Context, l *zap.SugaredLogger, region, namespace string) error {
+	planID, err := findBackupPlanID(ctx, region, namespace)
+	if err != nil {
+		return nil
+	}
+	selectionName := fmt.Sprintf("%s-rds-selection", namespace)
+	selectionID, err := utils.ExecuteCommand(ctx, "aws", "backup", "list-backup-selections",
+		"--region", region,
+		"--backup-plan-id", planID,
+		"--query", fmt.Sprintf("BackupSelectionsList[?SelectionName=='%s'].SelectionId | [0]", selectionName),
+		"--output", "text")
+	if err != nil {
+		return fmt.Errorf("failed to list backup selections: %w", err)
+	}
+	selectionID = strings.TrimSpace(selectionID)
+	if selectionID == "" || selectionID == "None" {
+		return nil
+	}
+	if _, err := utils.ExecuteCommand(ctx, "aws", "backup", "delete-backup-selection",
+		"--region", region,
+		"--backup-plan-id", planID,
+		"--selection-id", selectionID); err != nil {
+		return fmt.Errorf("failed to delete backup selection %s: %w", selectionName, err)
+	}
+	l.Infof("Removed block explorer database from backup plan (selection: %s)", selectionName)
+	return nil
+}
@@ -56,6 +56,11 @@ func InteractiveRestoreWithSelection(
 		return fmt.Errorf("no available recovery points found for EFS %s", efsID)
 	}
 
+	// Show the block explorer database recovery point restored with each EFS recovery point
+	if _, rdsPoints, err := ListRDSRecoveryPoints(ctx, region, namespace, "100"); err == nil {
+		PairRDSRecoveryPoints(availablePoints, rdsPoints)
+	}
+
 	// Display recovery points to user
 	l.Info("")
 	l.Infof("📦 Available Recovery Points (%d)", len(availablePoints))
@@ -76,6 +81,9 @@ func InteractiveRestoreWithSelection(
 		} else {
 			l.Infof("    ⏰ Expires  : Never")
 		}
+		if rp.RDSRecoveryPointARN != "" {
+			l.Infof("    🗄️  Explorer : %s", rp.RDSRecoveryPointARN)
+		}
 
 		l.Infof("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 		if i < len(availablePoints)-1 {
@@ -0,0 +1,474 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// BackupRuleNames are the grandfather-father-son rules, in schedule order
+var BackupRuleNames = []string{"hourly", "daily", "weekly", "monthly"}
+
+// ruleDefaults are the retention and cold storage transition of each rule when not given
+var ruleDefaults = map[string]types.BackupRule{
+	"hourly":  {DeleteAfterDays: 2},
+	"daily":   {DeleteAfterDays: 14},
+	"weekly":  {DeleteAfterDays: 56},
+	"monthly": {DeleteAfterDays: 365, ColdStorageAfterDays: 30},
+}
+
+const (
+	// AWS Backup keeps recovery points in cold storage for at least 90 days
+	minColdStorageDays = 90
+
+	// ruleTagKey tags each recovery point with the rule that created it
+	ruleTagKey = "BackupRule"
+
+	ruleStartWindowMinutes      = 60
+	ruleCompletionWindowMinutes = 180
+)
+
+var (
+	cronDayNames   = map[string]int{"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7}
+	cronMonthNames = map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6, "JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}
+)
+
+// DefaultBackupRules are the rules of --gfs: hourly for 2 days, daily for 2 weeks, weekly for 8 weeks and
+// monthly for a year, moved to cold storage after 30 days
+func DefaultBackupRules() []types.BackupRule {
+	rules := make([]types.BackupRule, 0, len(BackupRuleNames))
+	for _, name := range BackupRuleNames {
+		rule, _ := ParseBackupRule(name)
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// ParseBackupRule parses a --rule value, NAME[:key=value,...]. The keys are keep (days, 0 = unlimited),
+// cold (days until cold storage, 0 = never), at (HH:MM UTC, only the minute for hourly), day (weekday for
+// weekly, day of month for monthly) and tag.<Key> (recovery point tag).
+// Example: monthly:keep=730,cold=60,day=1,at=04:00,tag.Compliance=audit
+func ParseBackupRule(spec string) (types.BackupRule, error) {
+	name, options, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	name = strings.ToLower(strings.TrimSpace(name))
+	defaults, ok := ruleDefaults[name]
+	if !ok {
+		return types.BackupRule{}, fmt.Errorf("unknown backup rule %q, expected one of %s", name, strings.Join(BackupRuleNames, ", "))
+	}
+	rule := types.BackupRule{
+		Name:                 name,
+		DeleteAfterDays:      defaults.DeleteAfterDays,
+		ColdStorageAfterDays: defaults.ColdStorageAfterDays,
+	}
+	hour, minute := 3, 0
+	day := "SUN"
+	if name == "monthly" {
+		day = "1"
+	}
+
+	for _, option := range strings.Split(options, ",") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		key, value, found := strings.Cut(option, "=")
+		if !found {
+			return types.BackupRule{}, fmt.Errorf("invalid option %q of backup rule %s, expected key=value", option, name)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch {
+		case key == "keep" || key == "cold":
+			days, err := strconv.Atoi(value)
+			if err != nil || days < 0 {
+				return types.BackupRule{}, fmt.Errorf("invalid %s %q of backup rule %s: expected a number of days", key, value, name)
+			}
+			if key == "keep" {
+				rule.DeleteAfterDays = days
+			} else {
+				rule.ColdStorageAfterDays = days
+			}
+		case key == "at":
+			h, m, err := parseRuleTime(value)
+			if err != nil {
+				return types.BackupRule{}, fmt.Errorf("invalid at %q of backup rule %s: %w", value, name, err)
+			}
+			hour, minute = h, m
+		case key == "day":
+			day = strings.ToUpper(value)
+		case strings.HasPrefix(key, "tag.") && len(key) > len("tag."):
+			if rule.Tags == nil {
+				rule.Tags = map[string]string{}
+			}
+			rule.Tags[strings.TrimPrefix(key, "tag.")] = value
+		default:
+			return types.BackupRule{}, fmt.Errorf("unknown option %q of backup rule %s", key, name)
+		}
+	}
+
+	switch name {
+	case "hourly":
+		rule.ScheduleExpression = fmt.Sprintf("cron(%d * * * ? *)", minute)
+	case "daily":
+		rule.ScheduleExpression = fmt.Sprintf("cron(%d %d * * ? *)", minute, hour)
+	case "weekly":
+		if _, ok := cronDayNames[day]; !ok {
+			return types.BackupRule{}, fmt.Errorf("invalid day %q of backup rule weekly, expected SUN..SAT", day)
+		}
+		rule.ScheduleExpression = fmt.Sprintf("cron(%d %d ? * %s *)", minute, hour, day)
+	case "monthly":
+		dayOfMonth, err := strconv.Atoi(day)
+		// Later days do not exist in every month
+		if err != nil || dayOfMonth < 1 || dayOfMonth > 28 {
+			return types.BackupRule{}, fmt.Errorf("invalid day %q of backup rule monthly, expected 1..28", day)
+		}
+		rule.ScheduleExpression = fmt.Sprintf("cron(%d %d %d * ? *)", minute, hour, dayOfMonth)
+	}
+
+	if rule.ColdStorageAfterDays > 0 && rule.DeleteAfterDays > 0 && rule.DeleteAfterDays < rule.ColdStorageAfterDays+minColdStorageDays {
+		return types.BackupRule{}, fmt.Errorf("backup rule %s keeps recovery points %d days, cold storage needs at least %d (cold + %d)",
+			name, rule.DeleteAfterDays, rule.ColdStorageAfterDays+minColdStorageDays, minColdStorageDays)
+	}
+	return rule, nil
+}
+
+func parseRuleTime(value string) (int, int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("expected HH:MM")
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// MergeBackupRules returns the base rules with the overrides replacing the rules of the same name, in schedule order
+func MergeBackupRules(base, overrides []types.BackupRule) []types.BackupRule {
+	byName := map[string]types.BackupRule{}
+	for _, rule := range base {
+		byName[rule.Name] = rule
+	}
+	for _, rule := range overrides {
+		byName[rule.Name] = rule
+	}
+	merged := make([]types.BackupRule, 0, len(byName))
+	for _, name := range BackupRuleNames {
+		if rule, ok := byName[name]; ok {
+			merged = append(merged, rule)
+		}
+	}
+	return merged
+}
+
+// ApplyBackupPlan updates the backup plan with the rules and the copy action to the copy region. Nil rules
+// keep the rules of the plan, and an empty copy region removes the copy actions. Terraform manages the plan
+// with a single rule, so this is applied again after terraform changes it.
+func ApplyBackupPlan(ctx context.Context, l *zap.SugaredLogger, region, namespace string, rules []types.BackupRule, copyRegion string, copyDeleteAfterDays int) error {
+	copyVaultArn := ""
+	if copyRegion != "" {
+		if copyRegion == region {
+			return fmt.Errorf("the copy region must differ from the chain region %s", region)
+		}
+		accountID, err := utils.DetectAWSAccountID(ctx)
+		if err != nil {
+			return err
+		}
+		if err := ensureCopyVault(ctx, l, copyRegion, namespace); err != nil {
+			return err
+		}
+		copyVaultArn = CopyVaultARN(copyRegion, accountID, namespace)
+	}
+
+	planID, err := findBackupPlanID(ctx, region, namespace)
+	if err != nil {
+		return err
+	}
+	plan, err := utils.ExecuteCommand(ctx, "aws", "backup", "get-backup-plan",
+		"--region", region,
+		"--backup-plan-id", planID,
+		"--output", "json")
+	if err != nil {
+		return fmt.Errorf("failed to get backup plan %s: %w", planID, err)
+	}
+	input, err := BuildBackupPlanInput([]byte(plan), namespace, rules, copyVaultArn, copyDeleteAfterDays)
+	if err != nil {
+		return err
+	}
+	if _, err := utils.ExecuteCommand(ctx, "aws", "backup", "update-backup-plan",
+		"--region", region,
+		"--backup-plan-id", planID,
+		"--backup-plan", string(input)); err != nil {
+		return fmt.Errorf("failed to update backup plan %s: %w", planID, err)
+	}
+
+	for _, rule := range rules {
+		l.Infof("✅ Backup rule %s: %s, %s", rule.Name, parseCronToHuman(rule.ScheduleExpression), describeRetention(rule.DeleteAfterDays, rule.ColdStorageAfterDays))
+	}
+	switch {
+	case copyRegion == "":
+		l.Info("✅ Recovery points are not copied to another region")
+	case copyDeleteAfterDays > 0:
+		l.Infof("✅ Recovery points are copied to %s and kept there for %d days", copyRegion, copyDeleteAfterDays)
+	default:
+		l.Infof("✅ Recovery points are copied to %s and kept there until deleted", copyRegion)
+	}
+	return nil
+}
+
+// BuildBackupPlanInput turns get-backup-plan output into update-backup-plan input. Rules, when given, replace
+// the rules of the plan. Every rule gets a single copy action to copyVaultArn, or none when it is empty.
+func BuildBackupPlanInput(getBackupPlanOutput []byte, namespace string, rules []types.BackupRule, copyVaultArn string, copyDeleteAfterDays int) ([]byte, error) {
+	var out struct {
+		BackupPlan map[string]interface{} `json:"BackupPlan"`
+	}
+	if err := json.Unmarshal(getBackupPlanOutput, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse backup plan: %w", err)
+	}
+	planRules, _ := out.BackupPlan["Rules"].([]interface{})
+	if len(planRules) == 0 {
+		return nil, fmt.Errorf("backup plan has no rules")
+	}
+
+	if len(rules) > 0 {
+		vaultName := fmt.Sprintf("%s-backup-vault", namespace)
+		if first, ok := planRules[0].(map[string]interface{}); ok {
+			if name, ok := first["TargetBackupVaultName"].(string); ok && name != "" {
+				vaultName = name
+			}
+		}
+		planRules = make([]interface{}, 0, len(rules))
+		for _, rule := range rules {
+			planRules = append(planRules, backupRuleInput(rule, vaultName))
+		}
+	}
+
+	for _, r := range planRules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// RuleId is returned by get-backup-plan but not accepted by update-backup-plan
+		delete(rule, "RuleId")
+		if copyVaultArn == "" {
+			delete(rule, "CopyActions")
+			continue
+		}
+		action := map[string]interface{}{"DestinationBackupVaultArn": copyVaultArn}
+		if copyDeleteAfterDays > 0 {
+			action["Lifecycle"] = map[string]interface{}{"DeleteAfterDays": copyDeleteAfterDays}
+		}
+		rule["CopyActions"] = []interface{}{action}
+	}
+
+	input := map[string]interface{}{
+		"BackupPlanName": out.BackupPlan["BackupPlanName"],
+		"Rules":          planRules,
+	}
+	if settings, ok := out.BackupPlan["AdvancedBackupSettings"]; ok {
+		input["AdvancedBackupSettings"] = settings
+	}
+	return json.Marshal(input)
+}
+
+// backupRuleInput is the update-backup-plan rule of a backup rule. Its recovery points are tagged with the rule name.
+func backupRuleInput(rule types.BackupRule, vaultName string) map[string]interface{} {
+	tags := map[string]interface{}{ruleTagKey: rule.Name}
+	for key, value := range rule.Tags {
+		tags[key] = value
+	}
+	input := map[string]interface{}{
+		"RuleName":                rule.Name,
+		"TargetBackupVaultName":   vaultName,
+		"ScheduleExpression":      rule.ScheduleExpression,
+		"StartWindowMinutes":      ruleStartWindowMinutes,
+		"CompletionWindowMinutes": ruleCompletionWindowMinutes,
+		"RecoveryPointTags":       tags,
+	}
+	lifecycle := map[string]interface{}{}
+	if rule.DeleteAfterDays > 0 {
+		lifecycle["DeleteAfterDays"] = rule.DeleteAfterDays
+	}
+	if rule.ColdStorageAfterDays > 0 {
+		lifecycle["MoveToColdStorageAfterDays"] = rule.ColdStorageAfterDays
+	}
+	if len(lifecycle) > 0 {
+		input["Lifecycle"] = lifecycle
+	}
+	return input
+}
+
+func describeRetention(deleteAfterDays, coldStorageAfterDays int) string {
+	retention := "kept until deleted"
+	if deleteAfterDays > 0 {
+		retention = fmt.Sprintf("kept %d days", deleteAfterDays)
+	}
+	if coldStorageAfterDays > 0 {
+		retention += fmt.Sprintf(", cold storage after %d days", coldStorageAfterDays)
+	}
+	return retention
+}
+
+// RuleRecoveryPoint is a recovery point with the name of the backup rule that created it, empty for on-demand backups
+type RuleRecoveryPoint struct {
+	ARN      string
+	Created  string
+	RuleName string
+}
+
+// ExpiredRecoveryPoints returns the recovery points past the retention of their rule. Points of rules that keep
+// them until deleted are never returned, and points without a configured rule use defaultRetentionDays.
+func ExpiredRecoveryPoints(points []RuleRecoveryPoint, rules []types.BackupRule, defaultRetentionDays int, now time.Time) []RuleRecoveryPoint {
+	retention := map[string]int{}
+	for _, rule := range rules {
+		retention[rule.Name] = rule.DeleteAfterDays
+	}
+	var expired []RuleRecoveryPoint
+	for _, point := range points {
+		days, ok := retention[point.RuleName]
+		if !ok || point.RuleName == "" {
+			days = defaultRetentionDays
+		}
+		if days <= 0 {
+			continue
+		}
+		created, ok := parseRecoveryPointTime(point.Created)
+		if !ok {
+			continue
+		}
+		if !created.AddDate(0, 0, days).After(now) {
+			expired = append(expired, point)
+		}
+	}
+	return expired
+}
+
+// NextCronRun returns the first run of an AWS Backup cron expression after now. It supports numbers, names,
+// lists, ranges, steps, * and ?, but not L, W or #.
+func NextCronRun(expr string, now time.Time) (time.Time, bool) {
+	fields, ok := cronExpressionFields(expr)
+	if !ok || len(fields) != 6 {
+		return time.Time{}, false
+	}
+	minutes, err1 := parseCronField(fields[0], 0, 59, nil)
+	hours, err2 := parseCronField(fields[1], 0, 23, nil)
+	daysOfMonth, err3 := parseCronField(fields[2], 1, 31, nil)
+	months, err4 := parseCronField(fields[3], 1, 12, cronMonthNames)
+	daysOfWeek, err5 := parseCronField(fields[4], 1, 7, cronDayNames)
+	years, err6 := parseCronField(fields[5], 1970, 2199, nil)
+	for _, err := range []error{err1, err2, err3, err4, err5, err6} {
+		if err != nil {
+			return time.Time{}, false
+		}
+	}
+	// AWS cron matches the day of month when the day of week is ?, and the other way around
+	matchDayOfMonth := fields[2] != "?"
+	matchDayOfWeek := fields[4] != "?"
+
+	start := now.UTC().Truncate(time.Minute).Add(time.Minute)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 366*5; i, day = i+1, day.AddDate(0, 0, 1) {
+		if day.Year() > 2199 || !years[day.Year()-1970] || !months[int(day.Month())-1] {
+			continue
+		}
+		if matchDayOfMonth && !daysOfMonth[day.Day()-1] {
+			continue
+		}
+		if matchDayOfWeek && !daysOfWeek[int(day.Weekday())] {
+			continue
+		}
+		for hour := 0; hour < 24; hour++ {
+			if !hours[hour] {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if !minutes[minute] {
+					continue
+				}
+				run := day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+				if !run.Before(start) {
+					return run, true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func cronExpressionFields(expr string) ([]string, bool) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "cron(") || !strings.HasSuffix(expr, ")") {
+		return nil, false
+	}
+	return strings.Fields(strings.TrimSuffix(strings.TrimPrefix(expr, "cron("), ")")), true
+}
+
+// parseCronField returns which values from min to max the field matches, indexed from 0
+func parseCronField(field string, min, max int, names map[string]int) ([]bool, error) {
+	matches := make([]bool, max-min+1)
+	value := func(s string) (int, error) {
+		if n, ok := names[strings.ToUpper(s)]; ok {
+			return n, nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < min || n > max {
+			return 0, fmt.Errorf("invalid cron value %q", s)
+		}
+		return n, nil
+	}
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid cron step %q", part)
+			}
+			step = n
+		}
+		low, high := min, max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = value(from); err != nil {
+				return nil, err
+			}
+			if high, err = value(to); err != nil {
+				return nil, err
+			}
+		default:
+			n, err := value(rangePart)
+			if err != nil {
+				return nil, err
+			}
+			low = n
+			if !hasStep {
+				high = n
+			}
+		}
+		for n := low; n <= high; n += step {
+			matches[n-min] = true
+		}
+	}
+	return matches, nil
+}
+
+// sortRuleStatuses orders rules by schedule, known rule names first
+func sortRuleStatuses(rules []types.BackupRuleStatus) {
+	order := func(name string) int {
+		for i, known := range BackupRuleNames {
+			if name == known {
+				return i
+			}
+		}
+		return len(BackupRuleNames)
+	}
+	sort.SliceStable(rules, func(i, j int) bool { return order(rules[i].Name) < order(rules[j].Name) })
+}
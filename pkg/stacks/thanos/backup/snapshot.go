@@ -12,8 +12,8 @@ import (
 	"github.com/tokamak-network/trh-sdk/pkg/utils"
 )
 
-// SnapshotExecute triggers an on-demand EFS backup and returns snapshot information
-// SnapshotExecute triggers an on-demand EFS backup and returns snapshot information
+// SnapshotExecute triggers an on-demand EFS backup, and an RDS backup of the block explorer database when
+// it is installed, and returns snapshot information
 func SnapshotExecute(ctx context.Context, l *zap.SugaredLogger, region, namespace string, progressReporter func(string, float64)) (*types.BackupSnapshotInfo, error) {
 	accountID, err := utils.DetectAWSAccountID(ctx)
 	if err != nil {
@@ -53,6 +53,26 @@ func SnapshotExecute(ctx context.Context, l *zap.SugaredLogger, region, namespac
 	l.Infof("📁 EFS: ✅ On-demand backup started successfully")
 	l.Infof("   Job ID: %s", jobID)
 
+	// Back up the block explorer database with the chain data, so both can be restored together
+	rdsArn, rdsJobID := "", ""
+	if detected, err := utils.DetectRDSArn(ctx, region, namespace); err == nil {
+		out, err := utils.ExecuteCommand(ctx, "aws", "backup", "start-backup-job",
+			"--region", region,
+			"--backup-vault-name", backupVaultName,
+			"--resource-arn", detected,
+			"--iam-role-arn", iamRoleArn,
+			"--query", "BackupJobId",
+			"--output", "text",
+		)
+		if err != nil {
+			l.Warnf("🗄️  RDS: ⚠️  Failed to start block explorer database backup: %v", err)
+		} else {
+			rdsArn, rdsJobID = detected, strings.TrimSpace(out)
+			l.Infof("🗄️  RDS: ✅ On-demand block explorer database backup started")
+			l.Infof("   Job ID: %s", rdsJobID)
+		}
+	}
+
 	// Monitor the job until completion if a progress reporter is provided
 	// or we can just monitor it always to ensure we return only when done or failed?
 	// The original implementation returned immediately. The new requirement implies we should track it.
@@ -93,7 +113,29 @@ func SnapshotExecute(ctx context.Context, l *zap.SugaredLogger, region, namespac
 				progressReporter(fmt.Sprintf("Backup in progress: %s", status), percent)
 			}
 
-			if status == "COMPLETED" {
+			if status == "FAILED" || status == "ABORTED" || status == "EXPIRED" {
+				return nil, fmt.Errorf("backup job failed with status: %s", status)
+			}
+
+			rdsStatus := "COMPLETED"
+			if rdsJobID != "" {
+				rdsStatus, err = utils.ExecuteCommand(ctx, "aws", "backup", "describe-backup-job",
+					"--region", region,
+					"--backup-job-id", rdsJobID,
+					"--query", "State",
+					"--output", "text",
+				)
+				if err != nil {
+					l.Warnf("Failed to check RDS backup status: %v", err)
+					continue
+				}
+				rdsStatus = strings.TrimSpace(rdsStatus)
+				if rdsStatus == "FAILED" || rdsStatus == "ABORTED" || rdsStatus == "EXPIRED" {
+					return nil, fmt.Errorf("block explorer database backup job failed with status: %s", rdsStatus)
+				}
+			}
+
+			if status == "COMPLETED" && rdsStatus == "COMPLETED" {
 				if progressReporter != nil {
 					progressReporter("Backup completed successfully", 100.0)
 				}
@@ -105,9 +147,9 @@ func SnapshotExecute(ctx context.Context, l *zap.SugaredLogger, region, namespac
 					ARN:       arn,
 					JobID:     jobID,
 					Status:    "COMPLETED",
+					RDSARN:    rdsArn,
+					RDSJobID:  rdsJobID,
 				}, nil
-			} else if status == "FAILED" || status == "ABORTED" || status == "EXPIRED" {
-				return nil, fmt.Errorf("backup job failed with status: %s", status)
 			}
 		}
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -39,10 +40,28 @@ func GatherBackupStatusInfo(ctx context.Context, region, namespace string) (*typ
 
 	statusInfo.BackupVaults = getBackupVaults(ctx, region, statusInfo.ARN)
 
-	schedule, nextBackup, expiryDate, err := getBackupPlanInfo(ctx, region, namespace, statusInfo.LatestRecoveryPoint)
+	if rdsArn, err := utils.DetectRDSArn(ctx, region, namespace); err == nil {
+		statusInfo.RDSIdentifier = utils.RDSIdentifierFromNamespace(namespace)
+		statusInfo.RDSARN = rdsArn
+		statusInfo.RDSProtected = checkEFSProtectionStatus(ctx, region, rdsArn)
+		statusInfo.RDSLatestRecoveryPoint = getLatestRecoveryPoint(ctx, region, rdsArn)
+	}
+
+	if copyVaultArn, err := GetCopyDestination(ctx, region, namespace); err == nil && copyVaultArn != "" {
+		statusInfo.CopyVaultARN = copyVaultArn
+		statusInfo.CopyRegion = RegionFromARN(copyVaultArn)
+		if rps, err := ListCopiedRecoveryPoints(ctx, statusInfo.CopyRegion, namespace); err == nil {
+			if latest := LatestCompletedRecoveryPoint(rps); latest != nil {
+				statusInfo.CopyLatestRecoveryPoint = latest.Created
+			}
+		}
+	}
+
+	schedule, nextBackup, expiryDate, rules, err := getBackupPlanInfo(ctx, region, namespace, statusInfo.LatestRecoveryPoint)
 	if err != nil {
 		return statusInfo, fmt.Errorf("failed to get backup plan info: %w", err)
 	}
+	statusInfo.Rules = rules
 
 	statusInfo.BackupSchedule = schedule
 	statusInfo.NextBackupTime = nextBackup
@@ -87,8 +106,20 @@ func DisplayBackupStatus(l *zap.SugaredLogger, statusInfo *types.BackupStatusInf
 		l.Infof("   Vaults: %s", strings.Join(statusInfo.BackupVaults, ", "))
 	}
 
-	// Handle backup schedule display
-	if statusInfo.BackupSchedule != "" {
+	// Handle backup schedule display, one line per rule when the plan has several
+	if len(statusInfo.Rules) > 1 {
+		l.Info("   Rules:")
+		for _, rule := range statusInfo.Rules {
+			l.Infof("     • %s: 📅 %s, %s", rule.Name, rule.Schedule, describeRetention(rule.DeleteAfterDays, rule.ColdStorageAfterDays))
+			l.Infof("       Next run: ⏰ %s", rule.NextRun)
+			if tags := formatRuleTags(rule.Tags); tags != "" {
+				l.Infof("       Tags: %s", tags)
+			}
+		}
+		if statusInfo.NextBackupTime != "" {
+			l.Infof("   Next backup: ⏰ %s", statusInfo.NextBackupTime)
+		}
+	} else if statusInfo.BackupSchedule != "" {
 		l.Infof("   Schedule: 📅 %s", statusInfo.BackupSchedule)
 		if statusInfo.NextBackupTime != "" {
 			l.Infof("   Next backup: ⏰ %s", statusInfo.NextBackupTime)
@@ -96,6 +127,51 @@ func DisplayBackupStatus(l *zap.SugaredLogger, statusInfo *types.BackupStatusInf
 	}
 
 	l.Info("")
+	displayRDSBackupStatus(l, statusInfo)
+	displayCopyStatus(l, statusInfo)
+}
+
+// displayCopyStatus prints where recovery points are copied for disaster recovery
+func displayCopyStatus(l *zap.SugaredLogger, statusInfo *types.BackupStatusInfo) {
+	l.Info("🌐 Cross-Region Copies")
+	if statusInfo.CopyVaultARN == "" {
+		l.Warn("   Not configured (enable with: trh-sdk backup-manager --config --copy-region <region>)")
+		l.Info("")
+		return
+	}
+
+	l.Infof("   Region: %s", statusInfo.CopyRegion)
+	l.Infof("   Vault: %s", statusInfo.CopyVaultARN)
+	if statusInfo.CopyLatestRecoveryPoint == "" {
+		l.Warn("   Latest copy: ⚠️  None (no copies yet)")
+	} else {
+		l.Infof("   Latest copy: %s", statusInfo.CopyLatestRecoveryPoint)
+	}
+	l.Info("")
+}
+
+// displayRDSBackupStatus prints the backup status of the block explorer database
+func displayRDSBackupStatus(l *zap.SugaredLogger, statusInfo *types.BackupStatusInfo) {
+	l.Info("🗄️  Block Explorer Database (RDS) Backup Status")
+	if statusInfo.RDSARN == "" {
+		l.Info("   Not installed")
+		l.Info("")
+		return
+	}
+
+	l.Infof("   Instance: %s", statusInfo.RDSIdentifier)
+	l.Infof("   ARN: %s", statusInfo.RDSARN)
+	if statusInfo.RDSProtected {
+		l.Info("   Protected: ✅ true")
+	} else {
+		l.Warn("   Protected: ❌ false")
+	}
+	if statusInfo.RDSLatestRecoveryPoint == "" || statusInfo.RDSLatestRecoveryPoint == "None" {
+		l.Warn("   Latest recovery point: ⚠️  None (no backups found)")
+	} else {
+		l.Infof("   Latest recovery point: %s", statusInfo.RDSLatestRecoveryPoint)
+	}
+	l.Info("")
 }
 
 func checkEFSProtectionStatus(ctx context.Context, region, arn string) bool {
@@ -150,158 +226,150 @@ func getBackupVaults(ctx context.Context, region, arn string) []string {
 }
 
 // getBackupPlanInfo retrieves comprehensive backup plan information from AWS Backup
-// Returns: (schedule, nextBackupTime, expiryDate, error)
-func getBackupPlanInfo(ctx context.Context, region, namespace, latestRecoveryPoint string) (string, string, string, error) {
-	// Try to find backup plans associated with the namespace
-	planName := fmt.Sprintf("%s-backup-plan", namespace)
-
-	// Step 1: List backup plans to find the namespace-specific plan
-	plansJSON, err := utils.ExecuteCommand(ctx, "aws", "backup", "list-backup-plans",
-		"--region", region,
-		"--query", fmt.Sprintf("BackupPlansList[?BackupPlanName=='%s']", planName),
-		"--output", "json")
+// Returns: (schedule, nextBackupTime, expiryDate, rules, error). The schedule and expiry date are those of the
+// first rule, the next backup time is the earliest next run of all rules.
+func getBackupPlanInfo(ctx context.Context, region, namespace, latestRecoveryPoint string) (string, string, string, []types.BackupRuleStatus, error) {
+	// Step 1: Find the namespace-specific plan
+	planId, err := findBackupPlanID(ctx, region, namespace)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to list backup plans: %w", err)
-	}
-
-	plansJSON = strings.TrimSpace(plansJSON)
-	if plansJSON == "" || plansJSON == "null" || plansJSON == "[]" {
-		return "", "", "", fmt.Errorf("no backup plan found with name '%s'", planName)
-	}
-
-	// Step 2: Parse backup plan list to get plan ID
-	var plans []struct {
-		BackupPlanId   string `json:"BackupPlanId"`
-		BackupPlanName string `json:"BackupPlanName"`
-	}
-
-	if err := json.Unmarshal([]byte(plansJSON), &plans); err != nil {
-		return "", "", "", fmt.Errorf("failed to parse backup plans JSON: %w", err)
-	}
-
-	if len(plans) == 0 {
-		return "", "", "", fmt.Errorf("backup plan list is empty for '%s'", planName)
+		return "", "", "", nil, err
 	}
 
-	planId := plans[0].BackupPlanId
-
-	// Step 3: Get detailed backup plan information
+	// Step 2: Get detailed backup plan information
 	planDetails, err := utils.ExecuteCommand(ctx, "aws", "backup", "get-backup-plan",
 		"--region", region,
 		"--backup-plan-id", planId,
 		"--output", "json")
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to get backup plan details (plan ID: %s): %w", planId, err)
+		return "", "", "", nil, fmt.Errorf("failed to get backup plan details (plan ID: %s): %w", planId, err)
 	}
 
-	// Step 4: Parse backup plan details to extract schedule and lifecycle information
+	// Step 3: Parse backup plan details to extract schedule and lifecycle information
 	var planInfo struct {
 		BackupPlan struct {
 			Rules []struct {
+				RuleName           string `json:"RuleName"`
 				ScheduleExpression string `json:"ScheduleExpression"`
 				Lifecycle          *struct {
-					DeleteAfterDays int `json:"DeleteAfterDays"`
+					DeleteAfterDays            int `json:"DeleteAfterDays"`
+					MoveToColdStorageAfterDays int `json:"MoveToColdStorageAfterDays"`
 				} `json:"Lifecycle"`
+				RecoveryPointTags map[string]string `json:"RecoveryPointTags"`
 			} `json:"Rules"`
 		} `json:"BackupPlan"`
 	}
 
 	if err := json.Unmarshal([]byte(planDetails), &planInfo); err != nil {
-		return "", "", "", fmt.Errorf("failed to parse backup plan details JSON: %w", err)
+		return "", "", "", nil, fmt.Errorf("failed to parse backup plan details JSON: %w", err)
 	}
 
 	if len(planInfo.BackupPlan.Rules) == 0 {
-		return "", "", "", fmt.Errorf("backup plan '%s' has no rules configured", planName)
+		return "", "", "", nil, fmt.Errorf("backup plan '%s-backup-plan' has no rules configured", namespace)
+	}
+
+	// Step 4: Describe every rule with its next run
+	now := time.Now().UTC()
+	var rules []types.BackupRuleStatus
+	var earliest time.Time
+	for _, rule := range planInfo.BackupPlan.Rules {
+		status := types.BackupRuleStatus{
+			Name:     rule.RuleName,
+			Schedule: parseCronToHuman(rule.ScheduleExpression),
+			NextRun:  calculateNextBackupTime(rule.ScheduleExpression),
+		}
+		if rule.Lifecycle != nil {
+			status.DeleteAfterDays = rule.Lifecycle.DeleteAfterDays
+			status.ColdStorageAfterDays = rule.Lifecycle.MoveToColdStorageAfterDays
+		}
+		for key, value := range rule.RecoveryPointTags {
+			if key == ruleTagKey {
+				continue
+			}
+			if status.Tags == nil {
+				status.Tags = map[string]string{}
+			}
+			status.Tags[key] = value
+		}
+		rules = append(rules, status)
+		if next, ok := NextCronRun(rule.ScheduleExpression, now); ok && (earliest.IsZero() || next.Before(earliest)) {
+			earliest = next
+		}
 	}
+	sortRuleStatuses(rules)
 
-	rule := planInfo.BackupPlan.Rules[0]
+	first := planInfo.BackupPlan.Rules[0]
+	deleteAfterDays := 0
+	if first.Lifecycle != nil {
+		deleteAfterDays = first.Lifecycle.DeleteAfterDays
+	}
 
 	// Step 5: Extract and process information
 	// - Schedule: Convert cron expression to human-readable format
-	humanSchedule := parseCronToHuman(rule.ScheduleExpression)
+	humanSchedule := parseCronToHuman(first.ScheduleExpression)
 
-	// - Next Backup Time: Calculate next backup based on schedule
-	nextBackup := calculateNextBackupTime(rule.ScheduleExpression)
+	// - Next Backup Time: the earliest next run of all rules
+	nextBackup := calculateNextBackupTime(first.ScheduleExpression)
+	if !earliest.IsZero() {
+		nextBackup = earliest.Format("2006-01-02 15:04 UTC")
+	}
 
 	// - Expiry Date: Calculate based on lifecycle policy and latest recovery point
-	expiryDate := calculateExpiryDateFromPlan(rule.Lifecycle, latestRecoveryPoint)
+	expiryDate := calculateExpiryDateFromPlan(deleteAfterDays, latestRecoveryPoint)
 
-	return humanSchedule, nextBackup, expiryDate, nil
+	return humanSchedule, nextBackup, expiryDate, rules, nil
 }
 
 // parseCronToHuman converts AWS Backup cron expression to human-readable format
 // AWS Backup cron format: cron(minute hour day-of-month month day-of-week year)
 // Example: cron(0 3 * * ? *) = daily at 03:00 UTC
 func parseCronToHuman(cronExpr string) string {
-
-	if strings.HasPrefix(cronExpr, "cron(") && strings.HasSuffix(cronExpr, ")") {
-		// Remove cron() wrapper
-		cron := strings.TrimPrefix(strings.TrimSuffix(cronExpr, ")"), "cron(")
-		parts := strings.Fields(cron)
-
-		if len(parts) >= 2 {
-			minute := parts[0]
-			hour := parts[1]
-
-			// Convert to human-readable format
-			if minute == "0" {
-				return fmt.Sprintf("Daily at %s:00 UTC", hour)
-			} else {
-				return fmt.Sprintf("Daily at %s:%s UTC", hour, minute)
-			}
+	parts, ok := cronExpressionFields(cronExpr)
+	if !ok || len(parts) < 5 {
+		return "Custom schedule"
+	}
+	minute, hour, dayOfMonth, month, dayOfWeek := parts[0], parts[1], parts[2], parts[3], parts[4]
+	minuteValue, minuteErr := strconv.Atoi(minute)
+	hourValue, hourErr := strconv.Atoi(hour)
+	anyDay := func(field string) bool { return field == "*" || field == "?" }
+
+	switch {
+	case minuteErr != nil || month != "*":
+		return "Custom schedule"
+	case hour == "*" && anyDay(dayOfMonth) && anyDay(dayOfWeek):
+		return fmt.Sprintf("Hourly at :%02d", minuteValue)
+	case hourErr != nil:
+		return "Custom schedule"
+	case anyDay(dayOfMonth) && anyDay(dayOfWeek):
+		// Convert to human-readable format
+		if minute == "0" {
+			return fmt.Sprintf("Daily at %s:00 UTC", hour)
 		}
+		return fmt.Sprintf("Daily at %s:%s UTC", hour, minute)
+	case dayOfMonth == "?":
+		return fmt.Sprintf("Weekly on %s at %02d:%02d UTC", dayOfWeek, hourValue, minuteValue)
+	case dayOfWeek == "?":
+		return fmt.Sprintf("Monthly on day %s at %02d:%02d UTC", dayOfMonth, hourValue, minuteValue)
 	}
-
 	return "Custom schedule"
 }
 
 // calculateNextBackupTime calculates the next backup time based on cron expression
-// For simplicity, assumes daily backup and calculates next occurrence
-// Note: This is a basic implementation - for production, consider using a proper cron parser
 func calculateNextBackupTime(cronExpr string) string {
-
 	now := time.Now().UTC()
-	// Get today's date at 00:00:00 UTC
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-
-	// Extract hour and minute from cron expression
-	if strings.HasPrefix(cronExpr, "cron(") && strings.HasSuffix(cronExpr, ")") {
-		cron := strings.TrimPrefix(strings.TrimSuffix(cronExpr, ")"), "cron(")
-		parts := strings.Fields(cron)
-
-		if len(parts) >= 2 {
-			minute := parts[0]
-			hour := parts[1]
-
-			// Parse hour and minute
-			if h, err := strconv.Atoi(hour); err == nil {
-				if m, err := strconv.Atoi(minute); err == nil {
-					// Calculate today's backup time
-					nextBackup := time.Date(now.Year(), now.Month(), now.Day(), h, m, 0, 0, time.UTC)
-
-					// If the time has passed today, schedule for tomorrow
-					if nextBackup.Before(now) || nextBackup.Equal(now) {
-						nextBackup = nextBackup.AddDate(0, 0, 1)
-					}
-
-					return nextBackup.Format("2006-01-02 15:04 UTC")
-				}
-			}
-		}
+	if next, ok := NextCronRun(cronExpr, now); ok {
+		return next.Format("2006-01-02 15:04 UTC")
 	}
 
 	// Fallback to next day at 03:00 UTC
-	tomorrow := today.AddDate(0, 0, 1)
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
 	return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 3, 0, 0, 0, time.UTC).Format("2006-01-02 15:04 UTC")
 }
 
 // calculateExpiryDateFromPlan calculates expiry date based on backup plan lifecycle policy
-// Takes the lifecycle policy and latest recovery point creation date to calculate expiry
-func calculateExpiryDateFromPlan(lifecycle *struct {
-	DeleteAfterDays int `json:"DeleteAfterDays"`
-}, latestRecoveryPoint string) string {
-	// If no lifecycle policy or DeleteAfterDays is 0, retention is unlimited
-	if lifecycle == nil || lifecycle.DeleteAfterDays == 0 {
+// Takes the retention days and latest recovery point creation date to calculate expiry
+func calculateExpiryDateFromPlan(deleteAfterDays int, latestRecoveryPoint string) string {
+	// If DeleteAfterDays is 0, retention is unlimited
+	if deleteAfterDays == 0 {
 		return "None (unlimited retention)"
 	}
 
@@ -321,6 +389,20 @@ func calculateExpiryDateFromPlan(lifecycle *struct {
 	}
 
 	// Calculate expiry date by adding retention days
-	expiryTime := creationTime.AddDate(0, 0, lifecycle.DeleteAfterDays)
-	return fmt.Sprintf("%s (%d days from creation)", expiryTime.Format("2006-01-02 15:04 UTC"), lifecycle.DeleteAfterDays)
+	expiryTime := creationTime.AddDate(0, 0, deleteAfterDays)
+	return fmt.Sprintf("%s (%d days from creation)", expiryTime.Format("2006-01-02 15:04 UTC"), deleteAfterDays)
+}
+
+// formatRuleTags renders recovery point tags as key=value pairs in key order
+func formatRuleTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+tags[key])
+	}
+	return strings.Join(pairs, ", ")
 }
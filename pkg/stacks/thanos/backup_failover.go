@@ -0,0 +1,310 @@
+package thanos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos/backup"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+const (
+	// BackupFailoverDirName is the directory in the deployment path failover reports and values are written to
+	BackupFailoverDirName = "backup-failovers"
+
+	failoverIngressTimeout = 45 * time.Minute
+	failoverDNSRecordTTL   = 60
+)
+
+// BackupFailover brings the chain up in the secondary region from a copied recovery point. It restores the EFS
+// there, creates its mount targets in the standby cluster VPC, installs the Helm releases on the standby cluster
+// against the restored storage, points the optional DNS record at the new ingress and saves the new region and
+// L2 RPC URL in settings.json. The report is written to backup-failovers/.
+func (t *ThanosStack) BackupFailover(ctx context.Context, opts types.BackupFailoverOptions) (*types.BackupFailoverReport, error) {
+	if t.deployConfig == nil || t.deployConfig.AWS == nil || t.deployConfig.K8s == nil {
+		return nil, fmt.Errorf("AWS deployment configuration is not available. Run this command from the deployment directory")
+	}
+	opts.Region = strings.TrimSpace(opts.Region)
+	if opts.Region == "" {
+		return nil, fmt.Errorf("the secondary region is required")
+	}
+	if opts.Region == t.deployConfig.AWS.Region {
+		return nil, fmt.Errorf("the chain already runs in %s, choose the secondary region", opts.Region)
+	}
+	if (opts.HostedZoneID == "") != (opts.DNSName == "") {
+		return nil, fmt.Errorf("--hosted-zone-id and --dns-name must be given together")
+	}
+	if opts.ClusterName == "" {
+		opts.ClusterName = t.deployConfig.K8s.Namespace
+	}
+
+	started := time.Now()
+	report := &types.BackupFailoverReport{
+		Namespace:        t.deployConfig.K8s.Namespace,
+		PreviousRegion:   t.deployConfig.AWS.Region,
+		Region:           opts.Region,
+		ClusterName:      opts.ClusterName,
+		RecoveryPointARN: opts.RecoveryPointARN,
+		PreviousL2RpcURL: t.deployConfig.L2RpcUrl,
+		StartedAt:        started.UTC(),
+	}
+	runDir := filepath.Join(t.deploymentPath, BackupFailoverDirName, fmt.Sprintf("%s-%d", opts.Region, started.Unix()))
+
+	runErr := t.runBackupFailover(ctx, opts, report, runDir)
+	if runErr != nil {
+		report.Error = runErr.Error()
+	}
+	report.Completed = runErr == nil
+	report.FinishedAt = time.Now().UTC()
+
+	path, err := writeBackupFailoverReport(runDir, report)
+	if err != nil {
+		t.logger.Warnf("Failed to write the failover report: %v", err)
+	}
+	t.displayBackupFailover(report, path)
+	return report, runErr
+}
+
+// runBackupFailover runs the failover steps in order, recording their results in the report
+func (t *ThanosStack) runBackupFailover(ctx context.Context, opts types.BackupFailoverOptions, report *types.BackupFailoverReport, runDir string) error {
+	namespace := t.deployConfig.K8s.Namespace
+	region := opts.Region
+
+	// Step 1. Pick the recovery point copy
+	if report.RecoveryPointARN == "" {
+		rps, err := backup.ListCopiedRecoveryPoints(ctx, region, namespace)
+		if err != nil {
+			return err
+		}
+		latest := backup.LatestCompletedRecoveryPoint(rps)
+		if latest == nil {
+			return fmt.Errorf("no completed recovery point copies in %s. Configure copies with --config --copy-region %s", region, region)
+		}
+		report.RecoveryPointARN, report.RecoveryPointCreated = latest.RecoveryPointARN, latest.Created
+	} else {
+		if backup.RegionFromARN(report.RecoveryPointARN) != region {
+			return fmt.Errorf("recovery point %s is not in %s", report.RecoveryPointARN, region)
+		}
+		created, err := backup.DescribeRecoveryPointCreation(ctx, region, namespace, report.RecoveryPointARN)
+		if err != nil {
+			return err
+		}
+		report.RecoveryPointCreated = created
+	}
+	t.logger.Infof("🌐 Failing over to %s from recovery point %s (created %s)", region, report.RecoveryPointARN, report.RecoveryPointCreated)
+
+	// Step 2. Switch to the standby cluster before the restore, so a missing cluster fails fast
+	if err := utils.SwitchKubernetesContext(ctx, opts.ClusterName, region); err != nil {
+		return fmt.Errorf("failed to access standby cluster %s in %s: %w", opts.ClusterName, region, err)
+	}
+	if _, err := utils.ExecuteCommand(ctx, "kubectl", "get", "csidriver", "efs.csi.aws.com"); err != nil {
+		return fmt.Errorf("the EFS CSI driver is not installed on standby cluster %s: %w", opts.ClusterName, err)
+	}
+
+	// Step 3. Restore the EFS in the secondary region
+	jobID, err := backup.RestoreEFS(ctx, region, report.RecoveryPointARN, func(c context.Context) (string, error) {
+		acct, err := utils.DetectAWSAccountID(c)
+		if err != nil {
+			return "", err
+		}
+		return backup.GetRestoreIAMRole(c, t.logger, region, namespace, acct)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start restore in %s: %w", region, err)
+	}
+	report.RestoreJobID = jobID
+	efsID, err := backup.MonitorEFSRestoreJob(ctx, t.logger, region, jobID, nil)
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	report.EFSID = efsID
+	if err := backup.TagEFSWithName(ctx, region, efsID, namespace); err != nil {
+		t.logger.Warnf("Failed to tag EFS %s with Name=%s: %v", efsID, namespace, err)
+	}
+
+	// Step 4. Mount targets: copy them from the EFS already used on the standby cluster, or create them in its subnets
+	if standbyEfsID, err := utils.DetectEFSId(ctx, namespace); err == nil && standbyEfsID != efsID {
+		if err := backup.ReplicateEFSMountTargets(ctx, t.logger, region, standbyEfsID, efsID); err != nil {
+			return fmt.Errorf("failed to create mount targets for %s: %w", efsID, err)
+		}
+	} else if err := backup.CreateEFSMountTargetsForCluster(ctx, t.logger, region, opts.ClusterName, efsID); err != nil {
+		return err
+	}
+	if err := waitForEFSMountTargets(ctx, region, efsID); err != nil {
+		return err
+	}
+	if err := backup.ValidateEFSMountTargets(ctx, t.logger, region, efsID); err != nil {
+		return err
+	}
+
+	// Step 5. Install the Helm releases on the standby cluster against the restored storage
+	if exists, err := utils.CheckNamespaceExists(ctx, namespace); err != nil {
+		return err
+	} else if !exists {
+		if _, err := utils.ExecuteCommand(ctx, "kubectl", "create", "namespace", namespace); err != nil {
+			return fmt.Errorf("failed to create namespace %s: %w", namespace, err)
+		}
+	}
+	releaseName, err := t.installFailoverReleases(ctx, report, runDir)
+	if err != nil {
+		return err
+	}
+
+	// Step 6. Publish the new ingress
+	ingressAddr, err := utils.WaitForIngressAddress(ctx, namespace, releaseName, failoverIngressTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to get the L2 RPC ingress address: %w", err)
+	}
+	report.L2RpcURL = "http://" + ingressAddr
+	if opts.DNSName != "" {
+		if err := upsertFailoverDNSRecord(ctx, opts.HostedZoneID, opts.DNSName, ingressAddr); err != nil {
+			return err
+		}
+		report.DNSRecord = fmt.Sprintf("%s CNAME %s", opts.DNSName, ingressAddr)
+	}
+
+	t.deployConfig.AWS.Region = region
+	t.deployConfig.L2RpcUrl = report.L2RpcURL
+	if err := t.deployConfig.WriteToJSONFile(t.deploymentPath); err != nil {
+		return fmt.Errorf("failed to save the new region and L2 RPC URL to settings.json: %w", err)
+	}
+	return nil
+}
+
+// installFailoverReleases installs the chain release like deploy does, storage first, then points its volumes
+// at the restored EFS before the workloads start. The bridge is installed too when it was deployed. It returns
+// the chain release name.
+func (t *ThanosStack) installFailoverReleases(ctx context.Context, report *types.BackupFailoverReport, runDir string) (string, error) {
+	namespace := t.deployConfig.K8s.Namespace
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		return "", err
+	}
+
+	// The values of the primary deployment are copied, so the failover does not change them
+	valueFile := filepath.Join(runDir, "thanos-stack-values.yaml")
+	if err := utils.CopyFile(fmt.Sprintf("%s/tokamak-thanos-stack/terraform/thanos-stack/thanos-stack-values.yaml", t.deploymentPath), valueFile); err != nil {
+		return "", fmt.Errorf("failed to copy thanos-stack-values.yaml: %w", err)
+	}
+	chartFile := fmt.Sprintf("%s/tokamak-thanos-stack/charts/thanos-stack", t.deploymentPath)
+	releaseName := fmt.Sprintf("%s-%d", namespace, time.Now().Unix())
+
+	if err := utils.UpdateYAMLField(valueFile, "enable_vpc", true); err != nil {
+		return "", err
+	}
+	if err := utils.UpdateYAMLField(valueFile, "enable_deployment", false); err != nil {
+		return "", err
+	}
+	if err := utils.InstallHelmRelease(ctx, releaseName, chartFile, valueFile, namespace); err != nil {
+		return "", err
+	}
+	report.HelmReleases = append(report.HelmReleases, releaseName)
+	if err := utils.WaitPVCReady(ctx, namespace); err != nil {
+		return "", err
+	}
+	if err := backup.UpdatePVVolumeHandles(ctx, t.logger, namespace, report.EFSID, nil); err != nil {
+		return "", err
+	}
+
+	if err := utils.UpdateYAMLField(valueFile, "enable_deployment", true); err != nil {
+		return "", err
+	}
+	if err := utils.InstallHelmRelease(ctx, releaseName, chartFile, valueFile, namespace); err != nil {
+		return "", err
+	}
+	t.logger.Infof("✅ Chain release %s installed on the restored EFS %s", releaseName, report.EFSID)
+
+	bridgeValues := fmt.Sprintf("%s/tokamak-thanos-stack/terraform/thanos-stack/op-bridge-values.yaml", t.deploymentPath)
+	if utils.CheckFileExists(bridgeValues) {
+		bridgeRelease := fmt.Sprintf("op-bridge-%d", time.Now().Unix())
+		bridgeChart := fmt.Sprintf("%s/tokamak-thanos-stack/charts/op-bridge", t.deploymentPath)
+		if err := utils.InstallHelmRelease(ctx, bridgeRelease, bridgeChart, bridgeValues, namespace); err != nil {
+			t.logger.Warnf("Failed to install the bridge: %v", err)
+		} else {
+			report.HelmReleases = append(report.HelmReleases, bridgeRelease)
+		}
+	}
+	return releaseName, nil
+}
+
+// upsertFailoverDNSRecord points the record at the new ingress hostname
+func upsertFailoverDNSRecord(ctx context.Context, hostedZoneID, name, target string) error {
+	changeBatch, err := failoverDNSChangeBatch(name, target)
+	if err != nil {
+		return err
+	}
+	if _, err := utils.ExecuteCommand(ctx, "aws", "route53", "change-resource-record-sets",
+		"--hosted-zone-id", hostedZoneID,
+		"--change-batch", string(changeBatch)); err != nil {
+		return fmt.Errorf("failed to update DNS record %s: %w", name, err)
+	}
+	return nil
+}
+
+// failoverDNSChangeBatch is the Route 53 change batch pointing the CNAME name at target
+func failoverDNSChangeBatch(name, target string) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"Comment": "trh-sdk backup-manager failover",
+		"Changes": []interface{}{
+			map[string]interface{}{
+				"Action": "UPSERT",
+				"ResourceRecordSet": map[string]interface{}{
+					"Name":            strings.TrimSuffix(name, ".") + ".",
+					"Type":            "CNAME",
+					"TTL":             failoverDNSRecordTTL,
+					"ResourceRecords": []interface{}{map[string]interface{}{"Value": target}},
+				},
+			},
+		},
+	})
+}
+
+func (t *ThanosStack) displayBackupFailover(report *types.BackupFailoverReport, path string) {
+	t.logger.Info("")
+	t.logger.Infof("🌐 Failover from %s to %s", report.PreviousRegion, report.Region)
+	if report.RecoveryPointARN != "" {
+		t.logger.Infof("   Recovery point: %s (%s)", report.RecoveryPointARN, report.RecoveryPointCreated)
+	}
+	if report.EFSID != "" {
+		t.logger.Infof("   EFS: %s", report.EFSID)
+	}
+	if len(report.HelmReleases) > 0 {
+		t.logger.Infof("   Helm releases: %s", strings.Join(report.HelmReleases, ", "))
+	}
+	if report.L2RpcURL != "" {
+		t.logger.Infof("   L2 RPC: %s (was %s)", report.L2RpcURL, report.PreviousL2RpcURL)
+	}
+	if report.DNSRecord != "" {
+		t.logger.Infof("   DNS: %s", report.DNSRecord)
+	}
+	if report.Completed {
+		t.logger.Infof("✅ The chain runs in %s. settings.json now points at it.", report.Region)
+		t.logger.Info("   The block explorer was not moved, and backups of the new EFS need a backup plan in this region.")
+	} else {
+		t.logger.Errorf("❌ Failover did not complete: %s", report.Error)
+		if report.EFSID != "" {
+			t.logger.Infof("   The restored EFS %s in %s was kept for inspection. Delete it before running the failover again.", report.EFSID, report.Region)
+		}
+	}
+	if path != "" {
+		t.logger.Infof("Report: %s", path)
+	}
+}
+
+// writeBackupFailoverReport writes the report next to the values of the run, as backup-failovers/<region>-<unix>.json
+func writeBackupFailoverReport(runDir string, report *types.BackupFailoverReport) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(runDir), 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := runDir + ".json"
+	return path, os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,115 @@
+package thanos
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	backup "github.com/tokamak-network/trh-sdk/pkg/stacks/thanos/backup"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+const testGetBackupPlanOutput = `{
+  "BackupPlan": {
+    "BackupPlanName": "thanos-backup-plan",
+    "Rules": [
+      {
+        "RuleName": "daily",
+        "TargetBackupVaultName": "thanos-backup-vault",
+        "ScheduleExpression": "cron(0 3 * * ? *)",
+        "StartWindowMinutes": 60,
+        "Lifecycle": {"DeleteAfterDays": 35},
+        "RuleId": "0a1b2c3d"
+      }
+    ],
+    "AdvancedBackupSettings": [{"ResourceType": "EC2", "BackupOptions": {"WindowsVSS": "disabled"}}]
+  },
+  "BackupPlanId": "plan-1",
+  "VersionId": "v1"
+}`
+
+func TestBuildBackupPlanInputCopyActions(t *testing.T) {
+	destination := backup.CopyVaultARN("us-west-2", "123456789012", "thanos")
+	require.Equal(t, "arn:aws:backup:us-west-2:123456789012:backup-vault:thanos-backup-vault", destination)
+
+	input, err := backup.BuildBackupPlanInput([]byte(testGetBackupPlanOutput), "thanos", nil, destination, 30)
+	require.NoError(t, err)
+
+	var plan map[string]interface{}
+	require.NoError(t, json.Unmarshal(input, &plan))
+	require.Equal(t, "thanos-backup-plan", plan["BackupPlanName"])
+	require.Contains(t, plan, "AdvancedBackupSettings")
+	require.NotContains(t, plan, "BackupPlanId")
+
+	rule := plan["Rules"].([]interface{})[0].(map[string]interface{})
+	require.NotContains(t, rule, "RuleId", "update-backup-plan rejects RuleId")
+	require.Equal(t, "cron(0 3 * * ? *)", rule["ScheduleExpression"])
+	copyActions := rule["CopyActions"].([]interface{})
+	require.Len(t, copyActions, 1)
+	action := copyActions[0].(map[string]interface{})
+	require.Equal(t, destination, action["DestinationBackupVaultArn"])
+	require.Equal(t, float64(30), action["Lifecycle"].(map[string]interface{})["DeleteAfterDays"])
+
+	// Unlimited keep has no lifecycle, and an empty destination removes the copy actions
+	input, err = backup.BuildBackupPlanInput([]byte(testGetBackupPlanOutput), "thanos", nil, destination, 0)
+	require.NoError(t, err)
+	require.NotContains(t, string(input), `"Lifecycle":{"DeleteAfterDays":0}`)
+	require.NoError(t, json.Unmarshal(input, &plan))
+	action = plan["Rules"].([]interface{})[0].(map[string]interface{})["CopyActions"].([]interface{})[0].(map[string]interface{})
+	require.NotContains(t, action, "Lifecycle")
+
+	wrapped, err := json.Marshal(map[string]interface{}{"BackupPlan": plan})
+	require.NoError(t, err)
+	input, err = backup.BuildBackupPlanInput(wrapped, "thanos", nil, "", 0)
+	require.NoError(t, err)
+	require.NotContains(t, string(input), "CopyActions")
+
+	_, err = backup.BuildBackupPlanInput([]byte(`{"BackupPlan":{"Rules":[]}}`), "thanos", nil, destination, 0)
+	require.Error(t, err)
+}
+
+func TestLatestCompletedRecoveryPoint(t *testing.T) {
+	require.Nil(t, backup.LatestCompletedRecoveryPoint(nil))
+
+	rps := []types.RecoveryPoint{
+		{RecoveryPointARN: "older", Created: "2026-05-01T03:00:00.000000+00:00", Status: "COMPLETED"},
+		{RecoveryPointARN: "copying", Created: "2026-05-03T03:00:00.000000+00:00", Status: "CREATING"},
+		// Later in UTC than "older" although its local time is earlier
+		{RecoveryPointARN: "latest", Created: "2026-05-02T09:00:00.000000+09:00", Status: "COMPLETED"},
+		{RecoveryPointARN: "bad-date", Created: "today", Status: "COMPLETED"},
+	}
+	latest := backup.LatestCompletedRecoveryPoint(rps)
+	require.NotNil(t, latest)
+	require.Equal(t, "latest", latest.RecoveryPointARN)
+}
+
+func TestRegionFromARN(t *testing.T) {
+	require.Equal(t, "us-west-2", backup.RegionFromARN("arn:aws:backup:us-west-2:123456789012:recovery-point:0a1b2c3d"))
+	require.Empty(t, backup.RegionFromARN("fs-0123456789abcdef0"))
+}
+
+func TestFailoverDNSChangeBatch(t *testing.T) {
+	batch, err := failoverDNSChangeBatch("rpc.example.com", "k8s-thanos-abc.us-west-2.elb.amazonaws.com")
+	require.NoError(t, err)
+
+	var decoded struct {
+		Changes []struct {
+			Action            string
+			ResourceRecordSet struct {
+				Name            string
+				Type            string
+				TTL             int
+				ResourceRecords []struct{ Value string }
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(batch, &decoded))
+	require.Len(t, decoded.Changes, 1)
+	change := decoded.Changes[0]
+	require.Equal(t, "UPSERT", change.Action)
+	require.Equal(t, "rpc.example.com.", change.ResourceRecordSet.Name)
+	require.Equal(t, "CNAME", change.ResourceRecordSet.Type)
+	require.Equal(t, failoverDNSRecordTTL, change.ResourceRecordSet.TTL)
+	require.Equal(t, "k8s-thanos-abc.us-west-2.elb.amazonaws.com", change.ResourceRecordSet.ResourceRecords[0].Value)
+}
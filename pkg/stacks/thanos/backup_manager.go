@@ -7,13 +7,14 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	backup "github.com/tokamak-network/trh-sdk/pkg/stacks/thanos/backup"
 	"github.com/tokamak-network/trh-sdk/pkg/types"
 	"github.com/tokamak-network/trh-sdk/pkg/utils"
 )
 
-// BackupStatus prints EFS backup status
+// BackupStatus prints EFS and block explorer database backup status
 func (t *ThanosStack) BackupStatus(ctx context.Context) (*types.BackupStatusInfo, error) {
 	statusInfo, err := backup.GatherBackupStatusInfo(ctx, t.deployConfig.AWS.Region, t.deployConfig.K8s.Namespace)
 	if err != nil {
@@ -23,16 +24,25 @@ func (t *ThanosStack) BackupStatus(ctx context.Context) (*types.BackupStatusInfo
 	return statusInfo, nil
 }
 
-// BackupSnapshot triggers on-demand EFS backup and returns snapshot information
+// BackupSnapshot triggers on-demand EFS and block explorer database backups and returns snapshot information
 func (t *ThanosStack) BackupSnapshot(ctx context.Context, progressReporter func(string, float64)) (*types.BackupSnapshotInfo, error) {
 	snapshotInfo, err := backup.SnapshotExecute(ctx, t.logger, t.deployConfig.AWS.Region, t.deployConfig.K8s.Namespace, progressReporter)
 	if err != nil {
 		return nil, err
 	}
+
+	// The copy actions of the backup plan only cover scheduled backups
+	if cfg := t.deployConfig.BackupConfig; cfg != nil && cfg.CopyRegion != "" {
+		snapshotInfo.CopyJobIDs, err = backup.CopySnapshotToRegion(ctx, t.logger, t.deployConfig.AWS.Region, t.deployConfig.K8s.Namespace,
+			cfg.CopyRegion, cfg.CopyDeleteAfterDays, snapshotInfo.JobID, snapshotInfo.RDSJobID)
+		if err != nil {
+			t.logger.Warnf("Failed to copy the snapshot to %s: %v", cfg.CopyRegion, err)
+		}
+	}
 	return snapshotInfo, nil
 }
 
-// BackupList lists recent EFS recovery points and returns comprehensive information
+// BackupList lists recent EFS recovery points with their block explorer database recovery points
 func (t *ThanosStack) BackupList(ctx context.Context, limit string) (*types.BackupListInfo, error) {
 	region := t.deployConfig.AWS.Region
 	namespace := t.deployConfig.K8s.Namespace
@@ -58,6 +68,13 @@ func (t *ThanosStack) BackupList(ctx context.Context, limit string) (*types.Back
 		t.logger.Infof("   ❌ Error retrieving recovery points: %v", err)
 		return nil, err
 	}
+
+	// Pair each EFS recovery point with the block explorer database backup of the same run
+	rdsArn, rdsPoints, err := backup.ListRDSRecoveryPoints(ctx, region, namespace, "100")
+	if err != nil {
+		t.logger.Warnf("Failed to list block explorer database recovery points: %v", err)
+	}
+	backup.PairRDSRecoveryPoints(rps, rdsPoints)
 	backup.DisplayRecoveryPoints(t.logger, rps)
 
 	// Return comprehensive backup list information
@@ -68,16 +85,25 @@ func (t *ThanosStack) BackupList(ctx context.Context, limit string) (*types.Back
 		ResourceARN:    arn,
 		Limit:          limit,
 		RecoveryPoints: rps,
+		RDSARN:         rdsArn,
 	}, nil
 }
 
-// BackupRestore executes EFS restore from a recovery point ARN and returns restore information
+// BackupRestore executes EFS restore from a recovery point ARN and returns restore information.
+// When the restored EFS is attached, the block explorer database backed up with it is restored too.
+// An RDS recovery point ARN restores only the block explorer database.
 func (t *ThanosStack) BackupRestore(ctx context.Context, recoveryPointArn string, attach *bool, pvcs *string, stss *string, progressReporter func(string, float64)) (*types.BackupRestoreInfo, error) {
+	if backup.IsRDSRecoveryPoint(recoveryPointArn) {
+		return t.restoreExplorerDatabase(ctx, recoveryPointArn)
+	}
+
 	// Validate ARN
 	if !strings.Contains(recoveryPointArn, "arn:aws:backup:") {
 		return nil, fmt.Errorf("invalid recovery point ARN format: %s", recoveryPointArn)
 	}
 
+	defer t.silenceAlertsDuring(ctx, "backup restore", 3*time.Hour)()
+
 	// Get current EFS ID for tracking
 	currentEfsID, err := utils.DetectEFSId(ctx, t.deployConfig.K8s.Namespace)
 	if err != nil {
@@ -97,15 +123,7 @@ func (t *ThanosStack) BackupRestore(ctx context.Context, recoveryPointArn string
 		t.deployConfig.K8s.Namespace,
 		recoveryPointArn,
 		attachWorkloads,
-		func(c context.Context, arn string) (string, error) {
-			return backup.RestoreEFS(c, t.deployConfig.AWS.Region, arn, func(c2 context.Context) (string, error) {
-				acct, err := utils.DetectAWSAccountID(c2)
-				if err != nil {
-					return "", err
-				}
-				return backup.GetRestoreIAMRole(c2, t.logger, t.deployConfig.AWS.Region, t.deployConfig.K8s.Namespace, acct)
-			})
-		},
+		t.startEFSRestoreJob,
 		func(c context.Context, job string, reporter func(string, float64)) (string, error) {
 			return backup.MonitorEFSRestoreJob(c, t.logger, t.deployConfig.AWS.Region, job, reporter)
 		},
@@ -128,7 +146,7 @@ func (t *ThanosStack) BackupRestore(ctx context.Context, recoveryPointArn string
 	accountID, _ := utils.DetectAWSAccountID(ctx)
 	efsArn := utils.BuildEFSArn(t.deployConfig.AWS.Region, accountID, currentEfsID)
 
-	return &types.BackupRestoreInfo{
+	info := &types.BackupRestoreInfo{
 		Region:           t.deployConfig.AWS.Region,
 		Namespace:        t.deployConfig.K8s.Namespace,
 		EFSID:            currentEfsID,
@@ -140,7 +158,62 @@ func (t *ThanosStack) BackupRestore(ctx context.Context, recoveryPointArn string
 		SuggestedEFSID:   restoreInfo.SuggestedEFSID,
 		SuggestedPVCs:    restoreInfo.SuggestedPVCs,
 		SuggestedSTSs:    restoreInfo.SuggestedSTSs,
-	}, nil
+	}
+
+	// Restore the block explorer database of the same backup run, so the explorer matches the chain data
+	rdsPointArn, err := backup.FindPairedRDSRecoveryPoint(ctx, t.deployConfig.AWS.Region, t.deployConfig.K8s.Namespace, recoveryPointArn)
+	if err != nil {
+		t.logger.Warnf("Failed to find the block explorer database recovery point: %v", err)
+		return info, nil
+	}
+	if rdsPointArn == "" {
+		return info, nil
+	}
+	info.RDSRecoveryPointARN = rdsPointArn
+	if !attachWorkloads {
+		t.logger.Info("The block explorer database backed up with this recovery point can be restored after attaching with:")
+		t.logger.Infof("  ./trh-sdk backup-manager --restore --recovery-point-arn %s", rdsPointArn)
+		return info, nil
+	}
+	previous, err := backup.RestoreRDS(ctx, t.logger, t.deployConfig.AWS.Region, t.deployConfig.K8s.Namespace, rdsPointArn)
+	info.RDSPreviousInstance = previous
+	if err != nil {
+		info.RDSStatus = "FAILED"
+		return info, fmt.Errorf("chain data restored but block explorer database restore failed: %w", err)
+	}
+	info.RDSStatus = "COMPLETED"
+	return info, nil
+}
+
+// restoreExplorerDatabase restores only the block explorer database from an RDS recovery point
+func (t *ThanosStack) restoreExplorerDatabase(ctx context.Context, recoveryPointArn string) (*types.BackupRestoreInfo, error) {
+	defer t.silenceAlertsDuring(ctx, "block explorer database restore", 2*time.Hour)()
+
+	info := &types.BackupRestoreInfo{
+		Region:              t.deployConfig.AWS.Region,
+		Namespace:           t.deployConfig.K8s.Namespace,
+		RecoveryPointARN:    recoveryPointArn,
+		RDSRecoveryPointARN: recoveryPointArn,
+	}
+	previous, err := backup.RestoreRDS(ctx, t.logger, t.deployConfig.AWS.Region, t.deployConfig.K8s.Namespace, recoveryPointArn)
+	info.RDSPreviousInstance = previous
+	if err != nil {
+		info.Status, info.RDSStatus = "FAILED", "FAILED"
+		return info, err
+	}
+	info.Status, info.RDSStatus = "COMPLETED", "COMPLETED"
+	return info, nil
+}
+
+// startEFSRestoreJob starts an AWS Backup job restoring the recovery point into a new EFS and returns the job ID
+func (t *ThanosStack) startEFSRestoreJob(ctx context.Context, recoveryPointArn string) (string, error) {
+	return backup.RestoreEFS(ctx, t.deployConfig.AWS.Region, recoveryPointArn, func(c context.Context) (string, error) {
+		acct, err := utils.DetectAWSAccountID(c)
+		if err != nil {
+			return "", err
+		}
+		return backup.GetRestoreIAMRole(c, t.logger, t.deployConfig.AWS.Region, t.deployConfig.K8s.Namespace, acct)
+	})
 }
 
 // BackupRestoreInteractive provides interactive recovery point selection and restoration
@@ -208,17 +281,25 @@ func (t *ThanosStack) BackupPvPvcExport(ctx context.Context) (string, error) {
 	return backup.BackupPvPvcToDir(ctx, t.logger, t.deployConfig.K8s.Namespace, backupDir)
 }
 
-// BackupConfigure applies EFS backup configuration via Terraform and returns configuration info
-func (t *ThanosStack) BackupConfigure(ctx context.Context, daily *string, keep *string, reset *bool) (*types.BackupConfigInfo, error) {
+// BackupConfigure applies EFS backup configuration via Terraform, and the backup rules and cross-region copy
+// settings via the AWS Backup API, and returns configuration info
+func (t *ThanosStack) BackupConfigure(ctx context.Context, daily *string, keep *string, reset *bool, copyRegion *string, copyKeep *string, ruleSpecs []string, gfs *bool) (*types.BackupConfigInfo, error) {
 	info, err := backup.GatherBackupConfigInfo(
 		t.deployConfig.AWS.Region,
 		t.deployConfig.K8s.Namespace,
 		daily, keep, reset,
+		copyRegion, copyKeep,
+		ruleSpecs, gfs,
 		func(format string, args ...any) { t.logger.Infof(format, args...) },
 	)
-	if err != nil {
+	if err != nil || info == nil {
 		return nil, err
 	}
+	// Saved rules replace the terraform schedule, so a new schedule or retention would be overwritten
+	if (info.Daily != "" || info.Keep != "") && !info.Reset &&
+		(len(info.Rules) > 0 || (t.deployConfig.BackupConfig != nil && len(t.deployConfig.BackupConfig.Rules) > 0)) {
+		return nil, fmt.Errorf("--daily and --keep do not apply to backup rules, change them with --rule or remove them with --reset")
+	}
 	buildArgs := func(ci *types.BackupConfigInfo) []string {
 		return backup.BuildTerraformArgs(ci, func(format string, args ...any) { t.logger.Infof(format, args...) })
 	}
@@ -235,12 +316,80 @@ func (t *ThanosStack) BackupConfigure(ctx context.Context, daily *string, keep *
 	if err != nil {
 		return nil, err
 	}
+	if err := t.configureBackupPlan(ctx, info); err != nil {
+		return nil, err
+	}
 
 	// Update status after successful execution
 	info.Status = "applied"
 	return info, nil
 }
 
+// configureBackupPlan applies the backup rules and cross-region copies and saves them in settings.json.
+// Terraform rewrites the backup plan with a single rule and no copy actions, so the saved settings are
+// applied again after it runs.
+func (t *ThanosStack) configureBackupPlan(ctx context.Context, info *types.BackupConfigInfo) error {
+	if t.deployConfig.BackupConfig == nil {
+		t.deployConfig.BackupConfig = &types.BackupConfiguration{Enabled: true}
+	}
+	cfg := t.deployConfig.BackupConfig
+	changed := false
+
+	switch {
+	case strings.EqualFold(info.CopyRegion, "none"):
+		cfg.CopyRegion, cfg.CopyDeleteAfterDays = "", 0
+		changed = true
+
+	case info.CopyRegion != "" || info.CopyKeep != "":
+		copyRegion := info.CopyRegion
+		if copyRegion == "" {
+			copyRegion = cfg.CopyRegion
+		}
+		if copyRegion == "" {
+			return fmt.Errorf("--copy-keep needs --copy-region, cross-region copies are not configured")
+		}
+		days := cfg.CopyDeleteAfterDays
+		if info.CopyKeep != "" {
+			parsed, err := backup.ParseCopyKeep(info.CopyKeep)
+			if err != nil {
+				return err
+			}
+			days = parsed
+		}
+		cfg.CopyRegion, cfg.CopyDeleteAfterDays = copyRegion, days
+		changed = true
+	}
+
+	switch {
+	case info.Reset && len(cfg.Rules) > 0:
+		cfg.Rules = nil
+		changed = true
+	case len(info.Rules) > 0:
+		cfg.Rules = backup.MergeBackupRules(cfg.Rules, info.Rules)
+		changed = true
+	}
+
+	terraformRan := backup.NeedsTerraform(info)
+	if !changed && !terraformRan {
+		return nil
+	}
+	// After terraform, or with nothing to add to its plan, the plan only needs the saved settings again
+	if changed || cfg.CopyRegion != "" || len(cfg.Rules) > 0 {
+		if err := backup.ApplyBackupPlan(ctx, t.logger, t.deployConfig.AWS.Region, t.deployConfig.K8s.Namespace,
+			cfg.Rules, cfg.CopyRegion, cfg.CopyDeleteAfterDays); err != nil {
+			return err
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := t.deployConfig.WriteToJSONFile(t.deploymentPath); err != nil {
+		return fmt.Errorf("failed to save the backup settings to settings.json: %w", err)
+	}
+	return nil
+}
+
 // CleanupUnusedBackupResources removes unused EFS filesystems and old recovery points during deploy
 func (t *ThanosStack) CleanupUnusedBackupResources(ctx context.Context) error {
 	// Check if deployConfig is available
@@ -264,7 +413,12 @@ func (t *ThanosStack) CleanupUnusedBackupResources(ctx context.Context) error {
 		}
 	}
 
-	return backup.CleanupUnusedBackupResources(ctx, t.logger, region, namespace, retentionDays)
+	var rules []types.BackupRule
+	if t.deployConfig.BackupConfig != nil {
+		rules = t.deployConfig.BackupConfig.Rules
+	}
+
+	return backup.CleanupUnusedBackupResources(ctx, t.logger, region, namespace, retentionDays, rules)
 }
 
 // initializeBackupSystem initializes or reconciles the AWS Backup configuration for the current stack
@@ -0,0 +1,39 @@
+package thanos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	backup "github.com/tokamak-network/trh-sdk/pkg/stacks/thanos/backup"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+func TestPairRDSRecoveryPoints(t *testing.T) {
+	efsPoints := []types.RecoveryPoint{
+		{RecoveryPointARN: "efs-today", Created: "2026-05-02T03:00:12.123000+00:00", Status: "COMPLETED"},
+		{RecoveryPointARN: "efs-yesterday", Created: "2026-05-01T12:00:00.000000+09:00", Status: "COMPLETED"},
+		{RecoveryPointARN: "efs-old", Created: "2026-04-20T03:00:00.000000+00:00", Status: "COMPLETED"},
+		{RecoveryPointARN: "efs-bad-date", Created: "yesterday", Status: "COMPLETED"},
+	}
+	rdsPoints := []types.RecoveryPoint{
+		{RecoveryPointARN: "rds-today", Created: "2026-05-02T03:04:00.000000+00:00", Status: "COMPLETED"},
+		{RecoveryPointARN: "rds-today-late", Created: "2026-05-02T04:30:00.000000+00:00", Status: "COMPLETED"},
+		// The same instant as efs-yesterday in UTC
+		{RecoveryPointARN: "rds-yesterday", Created: "2026-05-01T03:10:00.000000+00:00", Status: "COMPLETED"},
+		{RecoveryPointARN: "rds-yesterday-partial", Created: "2026-05-01T03:00:00.000000+00:00", Status: "PARTIAL"},
+		{RecoveryPointARN: "rds-old", Created: "2026-04-20T06:00:00.000000+00:00", Status: "COMPLETED"},
+	}
+
+	backup.PairRDSRecoveryPoints(efsPoints, rdsPoints)
+	require.Equal(t, "rds-today", efsPoints[0].RDSRecoveryPointARN)
+	require.Equal(t, "rds-yesterday", efsPoints[1].RDSRecoveryPointARN)
+	require.Empty(t, efsPoints[2].RDSRecoveryPointARN, "a point outside the pairing window is not from the same run")
+	require.Empty(t, efsPoints[3].RDSRecoveryPointARN)
+}
+
+func TestIsRDSRecoveryPoint(t *testing.T) {
+	require.True(t, backup.IsRDSRecoveryPoint("arn:aws:rds:us-east-1:123456789012:snapshot:awsbackup:job-0a1b2c3d"))
+	require.False(t, backup.IsRDSRecoveryPoint("arn:aws:backup:us-east-1:123456789012:recovery-point:0a1b2c3d"))
+	require.False(t, backup.IsRDSRecoveryPoint("arn:aws:rds:us-east-1:123456789012:db:thanos-rds"))
+}
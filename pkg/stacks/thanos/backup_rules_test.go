@@ -0,0 +1,161 @@
+package thanos
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	backup "github.com/tokamak-network/trh-sdk/pkg/stacks/thanos/backup"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+func TestParseBackupRule(t *testing.T) {
+	rule, err := backup.ParseBackupRule("hourly:at=00:15")
+	require.NoError(t, err)
+	require.Equal(t, "cron(15 * * * ? *)", rule.ScheduleExpression)
+	require.Equal(t, 2, rule.DeleteAfterDays)
+
+	rule, err = backup.ParseBackupRule("weekly:day=sat,at=04:30,keep=90")
+	require.NoError(t, err)
+	require.Equal(t, "cron(30 4 ? * SAT *)", rule.ScheduleExpression)
+	require.Equal(t, 90, rule.DeleteAfterDays)
+
+	rule, err = backup.ParseBackupRule("monthly:keep=730,cold=60,day=15,tag.Compliance=audit")
+	require.NoError(t, err)
+	require.Equal(t, types.BackupRule{
+		Name:                 "monthly",
+		ScheduleExpression:   "cron(0 3 15 * ? *)",
+		DeleteAfterDays:      730,
+		ColdStorageAfterDays: 60,
+		Tags:                 map[string]string{"Compliance": "audit"},
+	}, rule)
+
+	for _, spec := range []string{
+		"yearly",
+		"daily:keep=-1",
+		"daily:at=25:00",
+		"daily:foo=bar",
+		"daily:keep",
+		"weekly:day=FUNDAY",
+		"monthly:day=31",
+		// Cold storage keeps recovery points at least 90 days
+		"monthly:keep=100,cold=30",
+	} {
+		_, err := backup.ParseBackupRule(spec)
+		require.Error(t, err, spec)
+	}
+
+	// Unlimited retention allows any cold storage transition
+	_, err = backup.ParseBackupRule("monthly:keep=0,cold=30")
+	require.NoError(t, err)
+
+	rules := backup.DefaultBackupRules()
+	require.Len(t, rules, 4)
+	require.Equal(t, "monthly", rules[3].Name)
+	require.Equal(t, 30, rules[3].ColdStorageAfterDays)
+}
+
+func TestMergeBackupRules(t *testing.T) {
+	override, err := backup.ParseBackupRule("daily:keep=30")
+	require.NoError(t, err)
+	merged := backup.MergeBackupRules([]types.BackupRule{{Name: "monthly"}, {Name: "daily", DeleteAfterDays: 14}}, []types.BackupRule{override})
+	require.Len(t, merged, 2)
+	require.Equal(t, "daily", merged[0].Name)
+	require.Equal(t, 30, merged[0].DeleteAfterDays)
+	require.Equal(t, "monthly", merged[1].Name)
+}
+
+func TestNextCronRun(t *testing.T) {
+	// Wednesday
+	now := time.Date(2026, 5, 13, 10, 20, 30, 0, time.UTC)
+	cases := map[string]time.Time{
+		"cron(0 3 * * ? *)":       time.Date(2026, 5, 14, 3, 0, 0, 0, time.UTC),
+		"cron(30 10 * * ? *)":     time.Date(2026, 5, 13, 10, 30, 0, 0, time.UTC),
+		"cron(15 * * * ? *)":      time.Date(2026, 5, 13, 11, 15, 0, 0, time.UTC),
+		"cron(0 3 ? * SUN *)":     time.Date(2026, 5, 17, 3, 0, 0, 0, time.UTC),
+		"cron(0 3 ? * 4 *)":       time.Date(2026, 5, 20, 3, 0, 0, 0, time.UTC),
+		"cron(0 3 1 * ? *)":       time.Date(2026, 6, 1, 3, 0, 0, 0, time.UTC),
+		"cron(0 0/6 * * ? *)":     time.Date(2026, 5, 13, 12, 0, 0, 0, time.UTC),
+		"cron(0 3 ? * MON-FRI *)": time.Date(2026, 5, 14, 3, 0, 0, 0, time.UTC),
+	}
+	for expr, expected := range cases {
+		next, ok := backup.NextCronRun(expr, now)
+		require.True(t, ok, expr)
+		require.Equal(t, expected, next, expr)
+	}
+
+	for _, expr := range []string{"rate(1 hour)", "cron(0 3 * *)", "cron(0 3 L * ? *)", "cron(61 3 * * ? *)"} {
+		_, ok := backup.NextCronRun(expr, now)
+		require.False(t, ok, expr)
+	}
+}
+
+func TestExpiredRecoveryPoints(t *testing.T) {
+	now := time.Date(2026, 5, 13, 0, 0, 0, 0, time.UTC)
+	rules := []types.BackupRule{
+		{Name: "daily", DeleteAfterDays: 14},
+		{Name: "monthly", DeleteAfterDays: 0},
+	}
+	points := []backup.RuleRecoveryPoint{
+		{ARN: "daily-old", Created: "2026-04-20T03:00:00.000000+00:00", RuleName: "daily"},
+		{ARN: "daily-new", Created: "2026-05-10T03:00:00.000000+00:00", RuleName: "daily"},
+		{ARN: "monthly-old", Created: "2025-01-01T03:00:00.000000+00:00", RuleName: "monthly"},
+		{ARN: "on-demand-old", Created: "2026-03-01T03:00:00.000000+00:00"},
+		{ARN: "on-demand-new", Created: "2026-05-01T03:00:00.000000+00:00"},
+		{ARN: "removed-rule", Created: "2026-03-01T03:00:00.000000+00:00", RuleName: "hourly"},
+		{ARN: "bad-date", Created: "yesterday", RuleName: "daily"},
+	}
+	var arns []string
+	for _, point := range backup.ExpiredRecoveryPoints(points, rules, 30, now) {
+		arns = append(arns, point.ARN)
+	}
+	require.Equal(t, []string{"daily-old", "on-demand-old", "removed-rule"}, arns)
+}
+
+func TestBuildBackupPlanInputRules(t *testing.T) {
+	rules := backup.DefaultBackupRules()
+	rules[1].Tags = map[string]string{"Team": "ops"}
+	destination := backup.CopyVaultARN("us-west-2", "123456789012", "thanos")
+
+	input, err := backup.BuildBackupPlanInput([]byte(testGetBackupPlanOutput), "thanos", rules, destination, 0)
+	require.NoError(t, err)
+
+	var plan struct {
+		BackupPlanName string
+		Rules          []struct {
+			RuleName              string
+			TargetBackupVaultName string
+			ScheduleExpression    string
+			RuleId                string
+			Lifecycle             *struct {
+				DeleteAfterDays            int
+				MoveToColdStorageAfterDays int
+			}
+			RecoveryPointTags map[string]string
+			CopyActions       []struct{ DestinationBackupVaultArn string }
+		}
+	}
+	require.NoError(t, json.Unmarshal(input, &plan))
+	require.Equal(t, "thanos-backup-plan", plan.BackupPlanName)
+	require.Len(t, plan.Rules, 4)
+	for i, rule := range plan.Rules {
+		require.Equal(t, rules[i].Name, rule.RuleName)
+		require.Equal(t, rules[i].ScheduleExpression, rule.ScheduleExpression)
+		require.Equal(t, "thanos-backup-vault", rule.TargetBackupVaultName)
+		require.Empty(t, rule.RuleId)
+		require.Equal(t, rules[i].Name, rule.RecoveryPointTags["BackupRule"])
+		require.Len(t, rule.CopyActions, 1)
+		require.Equal(t, destination, rule.CopyActions[0].DestinationBackupVaultArn)
+	}
+	require.Equal(t, "ops", plan.Rules[1].RecoveryPointTags["Team"])
+	require.Equal(t, 365, plan.Rules[3].Lifecycle.DeleteAfterDays)
+	require.Equal(t, 30, plan.Rules[3].Lifecycle.MoveToColdStorageAfterDays)
+
+	// A rule kept until deleted has no lifecycle
+	input, err = backup.BuildBackupPlanInput([]byte(testGetBackupPlanOutput), "thanos", []types.BackupRule{{Name: "daily", ScheduleExpression: "cron(0 3 * * ? *)"}}, "", 0)
+	require.NoError(t, err)
+	require.NotContains(t, string(input), "Lifecycle")
+	require.NotContains(t, string(input), "CopyActions")
+}
@@ -0,0 +1,861 @@
+package thanos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos/backup"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+const (
+	// BackupVerificationDirName is the directory in the deployment path verification reports are written to
+	BackupVerificationDirName = "backup-verifications"
+
+	// DefaultBackupVerifyDerivationTimeout bounds how long the restored op-node may take to advance its safe head
+	DefaultBackupVerifyDerivationTimeout = 30 * time.Minute
+
+	verifyRolloutTimeout      = "20m"
+	verifyMountTargetTimeout  = 10 * time.Minute
+	verifyRestoreJobTimeout   = 30 * time.Minute
+	verifySyncStatusInterval  = 15 * time.Second
+	verifyScratchLabel        = "trh-sdk/backup-verify"
+	verifyStateProbeAddress   = "0x4200000000000000000000000000000000000016" // L2ToL1MessagePasser
+	verifyHeadMaxAge          = time.Hour
+	verifyHeadMaxClockSkew    = 15 * time.Minute
+	verifyStorageRequest      = "500Gi"
+	verifyStorageClassName    = "efs-sc"
+	verifyManifestPermissions = 0600
+)
+
+// verifyComponents are the StatefulSets brought up on the restored EFS, in start order
+var verifyComponents = []string{"op-geth", "op-node"}
+
+// verifyIsolationEnv turns off p2p and sequencing of op-node and peering of op-geth in the scratch namespace
+var verifyIsolationEnv = map[string]string{
+	"OP_NODE_P2P_DISABLE":       "true",
+	"OP_NODE_SEQUENCER_ENABLED": "false",
+	"GETH_NODISCOVER":           "true",
+	"GETH_MAXPEERS":             "0",
+}
+
+var (
+	// Command line flags win over the environment, so these are rewritten in args and scripts too
+	verifySequencerFlagPattern = regexp.MustCompile(`--sequencer\.enabled(=\w+)?`)
+	verifyMaxPeersFlagPattern  = regexp.MustCompile(`--maxpeers(=|\s+)\d+`)
+)
+
+// BackupVerify restores a recovery point into a new EFS, runs op-geth and op-node on it in a scratch namespace,
+// checks the restored head against the live chain and that derivation resumes, then tears everything down and
+// writes a report to backup-verifications/
+func (t *ThanosStack) BackupVerify(ctx context.Context, opts types.BackupVerifyOptions) (*types.BackupVerificationReport, error) {
+	if t.deployConfig == nil || t.deployConfig.AWS == nil || t.deployConfig.K8s == nil {
+		return nil, fmt.Errorf("AWS deployment configuration is not available. Run this command from the deployment directory")
+	}
+	if backup.IsRDSRecoveryPoint(opts.RecoveryPointARN) {
+		return nil, fmt.Errorf("only EFS recovery points can be verified: %s", opts.RecoveryPointARN)
+	}
+	if !strings.Contains(opts.RecoveryPointARN, "arn:aws:backup:") {
+		return nil, fmt.Errorf("invalid recovery point ARN format: %s", opts.RecoveryPointARN)
+	}
+	if opts.DerivationTimeout <= 0 {
+		opts.DerivationTimeout = DefaultBackupVerifyDerivationTimeout
+	}
+
+	started := time.Now()
+	report := &types.BackupVerificationReport{
+		RecoveryPointARN: opts.RecoveryPointARN,
+		Namespace:        fmt.Sprintf("%s-verify-%d", t.deployConfig.K8s.Namespace, started.Unix()),
+		StartedAt:        started.UTC(),
+	}
+
+	runErr := t.runBackupVerification(ctx, opts, report)
+	if runErr != nil {
+		report.Error = runErr.Error()
+	}
+
+	if opts.KeepResources {
+		t.logger.Infof("Keeping namespace %s and EFS %s for inspection. Delete them when done.", report.Namespace, report.EFSID)
+		if report.EFSID == "" && report.RestoreJobID != "" {
+			t.logger.Infof("The EFS is created by restore job %s once it completes.", report.RestoreJobID)
+		}
+	} else {
+		// Tear down even when the verification was interrupted
+		report.TornDown = t.teardownBackupVerification(context.WithoutCancel(ctx), report)
+	}
+
+	report.Passed = runErr == nil && len(report.Checks) > 0
+	for _, check := range report.Checks {
+		report.Passed = report.Passed && check.Passed
+	}
+	report.FinishedAt = time.Now().UTC()
+
+	path, err := writeBackupVerificationReport(t.deploymentPath, report)
+	if err != nil {
+		t.logger.Warnf("Failed to write the verification report: %v", err)
+	}
+	t.displayBackupVerification(report, path)
+
+	if runErr != nil {
+		return report, runErr
+	}
+	if !report.Passed {
+		return report, fmt.Errorf("recovery point %s failed verification", opts.RecoveryPointARN)
+	}
+	return report, nil
+}
+
+// runBackupVerification restores the EFS, starts op-geth and checks its head, then starts op-node and checks
+// that derivation resumes. Checks are appended to the report as they complete.
+func (t *ThanosStack) runBackupVerification(ctx context.Context, opts types.BackupVerifyOptions, report *types.BackupVerificationReport) error {
+	region := t.deployConfig.AWS.Region
+	namespace := t.deployConfig.K8s.Namespace
+	scratch := report.Namespace
+
+	created, err := backup.DescribeRecoveryPointCreation(ctx, region, namespace, opts.RecoveryPointARN)
+	if err != nil {
+		t.logger.Warnf("Failed to read the recovery point creation date: %v", err)
+	}
+	report.RecoveryPointCreated = created
+
+	t.logger.Infof("🔄 Restoring %s into a new EFS...", opts.RecoveryPointARN)
+	jobID, err := t.startEFSRestoreJob(ctx, opts.RecoveryPointARN)
+	if err != nil {
+		return fmt.Errorf("failed to start restore: %w", err)
+	}
+	report.RestoreJobID = jobID
+	efsID, err := backup.MonitorEFSRestoreJob(ctx, t.logger, region, jobID, nil)
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	report.EFSID = efsID
+	if err := backup.TagEFSWithName(ctx, region, efsID, scratch); err != nil {
+		t.logger.Warnf("Failed to tag EFS %s with Name=%s: %v", efsID, scratch, err)
+	}
+
+	currentEfsID, err := utils.DetectEFSId(ctx, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to detect the EFS of namespace %s to copy its mount targets: %w", namespace, err)
+	}
+	if err := backup.ReplicateEFSMountTargets(ctx, t.logger, region, currentEfsID, efsID); err != nil {
+		return fmt.Errorf("failed to create mount targets for %s: %w", efsID, err)
+	}
+	if err := waitForEFSMountTargets(ctx, region, efsID); err != nil {
+		return err
+	}
+
+	manifestDir, err := os.MkdirTemp("", "trh-backup-verify-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(manifestDir)
+
+	statefulSets, err := t.writeVerificationManifests(ctx, scratch, efsID, manifestDir)
+	if err != nil {
+		return err
+	}
+	if _, err := utils.ExecuteCommand(ctx, "kubectl", "create", "namespace", scratch); err != nil {
+		return fmt.Errorf("failed to create namespace %s: %w", scratch, err)
+	}
+	if _, err := utils.ExecuteCommand(ctx, "kubectl", "label", "namespace", scratch, verifyScratchLabel+"=true"); err != nil {
+		t.logger.Warnf("Failed to label namespace %s: %v", scratch, err)
+	}
+	if _, err := utils.ExecuteCommand(ctx, "kubectl", "apply", "-f", manifestDir); err != nil {
+		return fmt.Errorf("failed to create the storage, config and services in %s: %w", scratch, err)
+	}
+
+	// op-geth runs alone first, so its head is the restored one and not one op-node already moved
+	if err := t.startVerificationWorkload(ctx, scratch, statefulSets["op-geth"]); err != nil {
+		return err
+	}
+	head, err := t.checkRestoredHead(ctx, report)
+	if err != nil {
+		return err
+	}
+
+	if err := t.startVerificationWorkload(ctx, scratch, statefulSets["op-node"]); err != nil {
+		return err
+	}
+	return t.checkDerivationResumed(ctx, report, head, opts.DerivationTimeout)
+}
+
+// writeVerificationManifests writes the restored EFS volume and claim, copies of the op-geth and op-node
+// services, config maps and secrets, and the rewritten StatefulSets into dir. The StatefulSets are written
+// to their own files, returned by component, so they can be started one after the other.
+func (t *ThanosStack) writeVerificationManifests(ctx context.Context, scratch, efsID, dir string) (map[string]string, error) {
+	namespace := t.deployConfig.K8s.Namespace
+	claim := scratch + "-efs"
+
+	objects := []map[string]interface{}{
+		verificationPersistentVolume(claim, efsID),
+		verificationPersistentVolumeClaim(claim, scratch),
+	}
+
+	stsList, err := kubectlList(ctx, namespace, "statefulsets")
+	if err != nil {
+		return nil, err
+	}
+	svcList, err := kubectlList(ctx, namespace, "services")
+	if err != nil {
+		return nil, err
+	}
+
+	statefulSets := map[string]string{}
+	var configMaps, secrets []string
+	for _, component := range verifyComponents {
+		sts := findObjectByComponent(stsList, component)
+		if sts == nil {
+			return nil, fmt.Errorf("%s StatefulSet not found in namespace %s", component, namespace)
+		}
+		podSpec := podSpecOf(sts)
+		cms, secs := referencedConfigObjects(podSpec)
+		configMaps = append(configMaps, cms...)
+		secrets = append(secrets, secs...)
+
+		if err := scratchStatefulSet(sts, namespace, scratch, claim); err != nil {
+			return nil, fmt.Errorf("failed to prepare the %s StatefulSet: %w", component, err)
+		}
+		path := filepath.Join(dir, "workloads", component+".json")
+		if err := writeManifest(path, sts); err != nil {
+			return nil, err
+		}
+		statefulSets[component] = path
+
+		for _, svc := range svcList {
+			if name, _ := objectName(svc); strings.Contains(name, component) {
+				scratchService(svc, scratch)
+				objects = append(objects, svc)
+			}
+		}
+	}
+
+	for kind, names := range map[string][]string{"configmap": configMaps, "secret": secrets} {
+		for _, name := range uniqueSorted(names) {
+			out, err := utils.ExecuteCommand(ctx, "kubectl", "-n", namespace, "get", kind, name, "-o", "json")
+			if err != nil {
+				t.logger.Warnf("Failed to read %s %s, the copied workload may not start: %v", kind, name, err)
+				continue
+			}
+			var obj map[string]interface{}
+			if err := json.Unmarshal([]byte(out), &obj); err != nil {
+				return nil, fmt.Errorf("failed to parse %s %s: %w", kind, name, err)
+			}
+			scratchMetadata(obj, scratch)
+			objects = append(objects, obj)
+		}
+	}
+
+	for i, obj := range objects {
+		name, _ := objectName(obj)
+		if err := writeManifest(filepath.Join(dir, fmt.Sprintf("%02d-%s.json", i, name)), obj); err != nil {
+			return nil, err
+		}
+	}
+	return statefulSets, nil
+}
+
+// startVerificationWorkload applies a rewritten StatefulSet and waits until its pod is ready
+func (t *ThanosStack) startVerificationWorkload(ctx context.Context, scratch, manifest string) error {
+	var sts map[string]interface{}
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &sts); err != nil {
+		return err
+	}
+	name, _ := objectName(sts)
+
+	t.logger.Infof("🚀 Starting %s in namespace %s...", name, scratch)
+	if _, err := utils.ExecuteCommand(ctx, "kubectl", "apply", "-f", manifest); err != nil {
+		return fmt.Errorf("failed to create StatefulSet %s: %w", name, err)
+	}
+	if _, err := utils.ExecuteCommand(ctx, "kubectl", "-n", scratch, "rollout", "status", "statefulset/"+name, "--timeout="+verifyRolloutTimeout); err != nil {
+		return fmt.Errorf("StatefulSet %s did not become ready on the restored EFS: %w", name, err)
+	}
+	return nil
+}
+
+// checkRestoredHead checks that the restored op-geth head is a block of the live chain with the same state root,
+// that its state can be read and that it is recent relative to the recovery point
+func (t *ThanosStack) checkRestoredHead(ctx context.Context, report *types.BackupVerificationReport) (*types.BlockRef, error) {
+	address, stop, err := forwardServiceRPC(ctx, report.Namespace, "op-geth")
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	restored, err := ethclient.DialContext(ctx, "http://"+address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the restored op-geth: %w", err)
+	}
+	defer restored.Close()
+
+	header, err := restored.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the restored op-geth head: %w", err)
+	}
+	head := &types.BlockRef{Number: header.Number.Uint64(), Hash: header.Hash().Hex(), Timestamp: header.Time}
+	report.Head = head
+	report.HeadStateRoot = header.Root.Hex()
+	t.logger.Infof("Restored head #%d %s (state root %s)", head.Number, head.Hash, report.HeadStateRoot)
+
+	live, err := ethclient.DialContext(ctx, t.deployConfig.L2RpcUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the L2 RPC %s: %w", t.deployConfig.L2RpcUrl, err)
+	}
+	defer live.Close()
+
+	canonical := types.BackupVerificationCheck{Name: "canonical_head"}
+	liveHeader, err := live.HeaderByNumber(ctx, header.Number)
+	switch {
+	case err != nil:
+		canonical.Message = fmt.Sprintf("failed to read block #%d from the L2 RPC: %v", head.Number, err)
+	case liveHeader.Hash() != header.Hash():
+		canonical.Message = fmt.Sprintf("block #%d is %s on the live chain, restored %s", head.Number, liveHeader.Hash().Hex(), head.Hash)
+	case liveHeader.Root != header.Root:
+		canonical.Message = fmt.Sprintf("state root of block #%d is %s on the live chain, restored %s", head.Number, liveHeader.Root.Hex(), report.HeadStateRoot)
+	default:
+		canonical.Passed = true
+		canonical.Message = fmt.Sprintf("block #%d hash and state root match the live chain", head.Number)
+	}
+	report.Checks = append(report.Checks, canonical)
+
+	state := types.BackupVerificationCheck{Name: "state_readable"}
+	if _, err := restored.BalanceAt(ctx, common.HexToAddress(verifyStateProbeAddress), header.Number); err != nil {
+		state.Message = fmt.Sprintf("state at block #%d cannot be read: %v", head.Number, err)
+	} else {
+		state.Passed = true
+		state.Message = fmt.Sprintf("state at block #%d is readable", head.Number)
+	}
+	report.Checks = append(report.Checks, state)
+
+	report.Checks = append(report.Checks, restoredHeadAgeCheck(time.Unix(int64(header.Time), 0), report.RecoveryPointCreated))
+	return head, nil
+}
+
+// restoredHeadAgeCheck checks that the restored head was produced shortly before the recovery point was created
+func restoredHeadAgeCheck(headTime time.Time, created string) types.BackupVerificationCheck {
+	check := types.BackupVerificationCheck{Name: "head_age"}
+	createdAt, err := time.Parse(time.RFC3339Nano, created)
+	if err != nil {
+		check.Passed = true
+		check.Message = "recovery point creation date unknown, head age not checked"
+		return check
+	}
+	age := createdAt.Sub(headTime)
+	switch {
+	case age < -verifyHeadMaxClockSkew:
+		check.Message = fmt.Sprintf("head is %s newer than the recovery point", (-age).Round(time.Second))
+	case age > verifyHeadMaxAge:
+		check.Message = fmt.Sprintf("head is %s older than the recovery point (max %s)", age.Round(time.Second), verifyHeadMaxAge)
+	case age < 0:
+		check.Passed = true
+		check.Message = "head was produced while the recovery point was being created"
+	default:
+		check.Passed = true
+		check.Message = fmt.Sprintf("head was produced %s before the recovery point", age.Round(time.Second))
+	}
+	return check
+}
+
+// checkDerivationResumed waits until the restored op-node advances its safe head and checks that the derived block
+// is the canonical one
+func (t *ThanosStack) checkDerivationResumed(ctx context.Context, report *types.BackupVerificationReport, head *types.BlockRef, timeout time.Duration) error {
+	address, stop, err := forwardServiceRPC(ctx, report.Namespace, "op-node")
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	opNode, err := rpc.DialContext(ctx, "http://"+address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the restored op-node: %w", err)
+	}
+	defer opNode.Close()
+
+	live, err := ethclient.DialContext(ctx, t.deployConfig.L2RpcUrl)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the L2 RPC %s: %w", t.deployConfig.L2RpcUrl, err)
+	}
+	defer live.Close()
+
+	check := types.BackupVerificationCheck{Name: "derivation_resumed"}
+	defer func() { report.Checks = append(report.Checks, check) }()
+
+	var start *types.RollupSyncStatus
+	deadline := time.Now().Add(timeout)
+	for {
+		var status types.RollupSyncStatus
+		if err := opNode.CallContext(ctx, &status, "optimism_syncStatus"); err != nil {
+			t.logger.Infof("Waiting for the restored op-node: %v", err)
+		} else if start == nil {
+			start = &status
+			t.logger.Infof("Restored op-node: unsafe head #%d, safe head #%d, deriving from L1 #%d", status.UnsafeL2.Number, status.SafeL2.Number, status.CurrentL1.Number)
+		} else if status.SafeL2.Number > start.SafeL2.Number {
+			liveHeader, err := live.HeaderByNumber(ctx, new(big.Int).SetUint64(status.SafeL2.Number))
+			switch {
+			case err != nil:
+				check.Message = fmt.Sprintf("safe head advanced to #%d but the live block cannot be read: %v", status.SafeL2.Number, err)
+			case !strings.EqualFold(liveHeader.Hash().Hex(), status.SafeL2.Hash):
+				check.Message = fmt.Sprintf("derived block #%d is %s, the live chain has %s", status.SafeL2.Number, status.SafeL2.Hash, liveHeader.Hash().Hex())
+			default:
+				check.Passed = true
+				check.Message = fmt.Sprintf("safe head advanced from #%d to #%d (restored head #%d) and matches the live chain", start.SafeL2.Number, status.SafeL2.Number, head.Number)
+			}
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			if start == nil {
+				check.Message = fmt.Sprintf("op-node did not report its sync status within %s", timeout)
+			} else {
+				check.Message = fmt.Sprintf("safe head did not advance from #%d within %s", start.SafeL2.Number, timeout)
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			check.Message = "interrupted"
+			return ctx.Err()
+		case <-time.After(verifySyncStatusInterval):
+		}
+	}
+}
+
+// teardownBackupVerification deletes the scratch namespace, its volume and the restored EFS. When the restore
+// was interrupted, the EFS is the one its job creates.
+func (t *ThanosStack) teardownBackupVerification(ctx context.Context, report *types.BackupVerificationReport) bool {
+	scratch := report.Namespace
+	ok := true
+	if report.EFSID == "" && report.RestoreJobID != "" {
+		efsID, err := t.restoreJobEFS(ctx, report.RestoreJobID)
+		if err != nil {
+			t.logger.Warnf("Failed to find the EFS of restore job %s, delete it once the job ends: %v", report.RestoreJobID, err)
+			ok = false
+		}
+		report.EFSID = efsID
+	}
+	efsID := report.EFSID
+	t.logger.Infof("🧹 Tearing down namespace %s and EFS %s...", scratch, efsID)
+	deleteCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	if err := t.tryToDeleteK8sNamespace(deleteCtx, scratch); err != nil {
+		t.logger.Warnf("Failed to delete namespace %s: %v", scratch, err)
+		ok = false
+	}
+	if _, err := utils.ExecuteCommand(ctx, "kubectl", "delete", "pv", scratch+"-efs", "--ignore-not-found=true"); err != nil {
+		t.logger.Warnf("Failed to delete PV %s-efs: %v", scratch, err)
+		ok = false
+	}
+	if efsID == "" {
+		return ok
+	}
+
+	region := t.deployConfig.AWS.Region
+	if err := backup.DeleteEFSMountTargets(ctx, t.logger, region, efsID); err != nil {
+		t.logger.Warnf("Failed to delete the mount targets of %s: %v", efsID, err)
+	}
+	// A file system with mount targets cannot be deleted
+	deadline := time.Now().Add(verifyMountTargetTimeout)
+	for time.Now().Before(deadline) {
+		out, err := utils.ExecuteCommand(ctx, "aws", "efs", "describe-mount-targets", "--region", region, "--file-system-id", efsID,
+			"--query", "length(MountTargets)", "--output", "text")
+		if err == nil && strings.TrimSpace(out) == "0" {
+			break
+		}
+		time.Sleep(10 * time.Second)
+	}
+	if err := utils.DeleteEFSFileSystem(ctx, region, efsID); err != nil {
+		t.logger.Warnf("Failed to delete EFS %s: %v", efsID, err)
+		ok = false
+	}
+	return ok
+}
+
+// restoreJobEFS waits for a restore job to end and returns the EFS it created, empty when it created none
+func (t *ThanosStack) restoreJobEFS(ctx context.Context, jobID string) (string, error) {
+	region := t.deployConfig.AWS.Region
+	deadline := time.Now().Add(verifyRestoreJobTimeout)
+	for {
+		out, err := utils.ExecuteCommand(ctx, "aws", "backup", "describe-restore-job", "--region", region, "--restore-job-id", jobID,
+			"--query", "[Status,CreatedResourceArn]", "--output", "text")
+		if err != nil {
+			return "", err
+		}
+		status, efsID := parseRestoreJobEFS(out)
+		switch status {
+		case "COMPLETED", "FAILED", "ABORTED":
+			return efsID, nil
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("restore job is still %s after %s", status, verifyRestoreJobTimeout)
+		}
+		t.logger.Infof("Waiting for restore job %s (%s) to end before deleting its EFS...", jobID, status)
+		time.Sleep(15 * time.Second)
+	}
+}
+
+// parseRestoreJobEFS parses the status and created resource ARN of describe-restore-job into the status and
+// the file system ID
+func parseRestoreJobEFS(output string) (status, efsID string) {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	status = fields[0]
+	if len(fields) > 1 && strings.Contains(fields[1], ":file-system/") {
+		efsID = fields[1][strings.LastIndex(fields[1], "/")+1:]
+	}
+	return status, efsID
+}
+
+// displayBackupVerification prints the checks of a verification report
+func (t *ThanosStack) displayBackupVerification(report *types.BackupVerificationReport, path string) {
+	t.logger.Info("")
+	t.logger.Infof("🔍 Recovery point verification: %s", report.RecoveryPointARN)
+	for _, check := range report.Checks {
+		if check.Passed {
+			t.logger.Infof("   ✅ %s: %s", check.Name, check.Message)
+		} else {
+			t.logger.Errorf("   ❌ %s: %s", check.Name, check.Message)
+		}
+	}
+	if report.Error != "" {
+		t.logger.Errorf("   ❌ %s", report.Error)
+	}
+	if report.Passed {
+		t.logger.Info("✅ The recovery point boots and resumes derivation")
+	} else {
+		t.logger.Error("❌ The recovery point failed verification")
+	}
+	if path != "" {
+		t.logger.Infof("Report: %s", path)
+	}
+}
+
+// writeBackupVerificationReport writes the report to backup-verifications/<scratch namespace>.json
+func writeBackupVerificationReport(deploymentPath string, report *types.BackupVerificationReport) (string, error) {
+	dir := filepath.Join(deploymentPath, BackupVerificationDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, report.Namespace+".json")
+	return path, os.WriteFile(path, data, 0644)
+}
+
+// waitForEFSMountTargets waits until every mount target of the file system is available
+func waitForEFSMountTargets(ctx context.Context, region, efsID string) error {
+	deadline := time.Now().Add(verifyMountTargetTimeout)
+	for {
+		out, err := utils.ExecuteCommand(ctx, "aws", "efs", "describe-mount-targets", "--region", region, "--file-system-id", efsID,
+			"--query", "MountTargets[].LifeCycleState", "--output", "text")
+		if err == nil {
+			states := strings.Fields(out)
+			ready := len(states) > 0
+			for _, state := range states {
+				ready = ready && state == "available"
+			}
+			if ready {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("mount targets of %s did not become available within %s", efsID, verifyMountTargetTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+func verificationPersistentVolume(name, efsID string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "PersistentVolume",
+		"metadata":   map[string]interface{}{"name": name, "labels": map[string]interface{}{"app": name, verifyScratchLabel: "true"}},
+		"spec": map[string]interface{}{
+			"capacity":                      map[string]interface{}{"storage": verifyStorageRequest},
+			"volumeMode":                    "Filesystem",
+			"accessModes":                   []interface{}{"ReadWriteMany"},
+			"persistentVolumeReclaimPolicy": "Retain",
+			"storageClassName":              verifyStorageClassName,
+			"csi":                           map[string]interface{}{"driver": "efs.csi.aws.com", "volumeHandle": efsID},
+		},
+	}
+}
+
+func verificationPersistentVolumeClaim(name, scratch string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "PersistentVolumeClaim",
+		"metadata":   map[string]interface{}{"name": name, "namespace": scratch},
+		"spec": map[string]interface{}{
+			"storageClassName": verifyStorageClassName,
+			"accessModes":      []interface{}{"ReadWriteMany"},
+			"resources":        map[string]interface{}{"requests": map[string]interface{}{"storage": verifyStorageRequest}},
+			"selector":         map[string]interface{}{"matchLabels": map[string]interface{}{"app": name}},
+			"volumeMode":       "Filesystem",
+			"volumeName":       name,
+		},
+	}
+}
+
+// scratchMetadata keeps only the name and labels of a copied object and moves it into the scratch namespace
+func scratchMetadata(obj map[string]interface{}, scratch string) {
+	meta, _ := obj["metadata"].(map[string]interface{})
+	clean := map[string]interface{}{"name": meta["name"], "namespace": scratch}
+	if labels, ok := meta["labels"]; ok {
+		clean["labels"] = labels
+	}
+	obj["metadata"] = clean
+	delete(obj, "status")
+}
+
+// scratchService copies a Service into the scratch namespace as a ClusterIP service without an external load balancer
+func scratchService(svc map[string]interface{}, scratch string) {
+	scratchMetadata(svc, scratch)
+	spec, _ := svc["spec"].(map[string]interface{})
+	if spec == nil {
+		return
+	}
+	for _, field := range []string{"clusterIP", "clusterIPs", "externalIPs", "healthCheckNodePort", "loadBalancerClass",
+		"loadBalancerIP", "loadBalancerSourceRanges", "externalTrafficPolicy", "allocateLoadBalancerNodePorts"} {
+		delete(spec, field)
+	}
+	spec["type"] = "ClusterIP"
+	ports, _ := spec["ports"].([]interface{})
+	for _, p := range ports {
+		if port, ok := p.(map[string]interface{}); ok {
+			delete(port, "nodePort")
+		}
+	}
+}
+
+// scratchStatefulSet rewrites a StatefulSet of the chain namespace to run one replica in the scratch namespace on
+// the restored EFS claim. p2p and sequencing are turned off and in-cluster addresses of the chain namespace point to
+// the scratch namespace, so the copy cannot reach or affect the running chain.
+func scratchStatefulSet(sts map[string]interface{}, namespace, scratch, claim string) error {
+	scratchMetadata(sts, scratch)
+	spec, _ := sts["spec"].(map[string]interface{})
+	if spec == nil {
+		return fmt.Errorf("StatefulSet has no spec")
+	}
+	podSpec := podSpecOf(sts)
+	if podSpec == nil {
+		return fmt.Errorf("StatefulSet has no pod template")
+	}
+	spec["replicas"] = 1
+
+	volumes, _ := podSpec["volumes"].([]interface{})
+	for _, v := range volumes {
+		volume, _ := v.(map[string]interface{})
+		if pvc, ok := volume["persistentVolumeClaim"].(map[string]interface{}); ok {
+			pvc["claimName"] = claim
+		}
+	}
+	// Claim templates would provision empty volumes; they are mounted from the restored claim instead
+	templates, _ := spec["volumeClaimTemplates"].([]interface{})
+	for _, tpl := range templates {
+		if name, ok := objectName(tpl); ok {
+			volumes = append(volumes, map[string]interface{}{
+				"name":                  name,
+				"persistentVolumeClaim": map[string]interface{}{"claimName": claim},
+			})
+		}
+	}
+	delete(spec, "volumeClaimTemplates")
+	podSpec["volumes"] = volumes
+
+	// The chain's service account may carry cloud permissions the copy does not need
+	delete(podSpec, "serviceAccountName")
+	delete(podSpec, "serviceAccount")
+
+	for _, key := range []string{"initContainers", "containers"} {
+		containers, _ := podSpec[key].([]interface{})
+		for _, c := range containers {
+			if container, ok := c.(map[string]interface{}); ok {
+				isolateContainer(container, namespace, scratch)
+			}
+		}
+	}
+	return nil
+}
+
+// isolateContainer turns off p2p and sequencing in the command, args and environment of a container and
+// points in-cluster addresses of the chain namespace to the scratch namespace
+func isolateContainer(container map[string]interface{}, namespace, scratch string) {
+	rewrite := func(value string) string {
+		value = strings.ReplaceAll(value, "."+namespace+".svc", "."+scratch+".svc")
+		value = verifySequencerFlagPattern.ReplaceAllString(value, "--sequencer.enabled=false")
+		return verifyMaxPeersFlagPattern.ReplaceAllString(value, "--maxpeers=0")
+	}
+	for _, key := range []string{"command", "args"} {
+		values, _ := container[key].([]interface{})
+		for i, v := range values {
+			value, ok := v.(string)
+			if !ok {
+				continue
+			}
+			// --maxpeers 50 passed as two arguments
+			if i > 0 && (values[i-1] == "--maxpeers" || values[i-1] == "-maxpeers") {
+				values[i] = "0"
+				continue
+			}
+			values[i] = rewrite(value)
+		}
+	}
+
+	env, _ := container["env"].([]interface{})
+	overridden := map[string]bool{}
+	for _, e := range env {
+		variable, _ := e.(map[string]interface{})
+		name, _ := variable["name"].(string)
+		if value, ok := verifyIsolationEnv[name]; ok {
+			variable["value"] = value
+			delete(variable, "valueFrom")
+			overridden[name] = true
+		} else if value, ok := variable["value"].(string); ok {
+			variable["value"] = rewrite(value)
+		}
+	}
+	names := make([]string, 0, len(verifyIsolationEnv))
+	for name := range verifyIsolationEnv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !overridden[name] {
+			env = append(env, map[string]interface{}{"name": name, "value": verifyIsolationEnv[name]})
+		}
+	}
+	container["env"] = env
+}
+
+// referencedConfigObjects returns the config maps and secrets the pod spec mounts or reads environment variables from
+func referencedConfigObjects(podSpec map[string]interface{}) ([]string, []string) {
+	var configMaps, secrets []string
+	add := func(list *[]string, obj interface{}, field string) {
+		if m, ok := obj.(map[string]interface{}); ok {
+			if name, ok := m[field].(string); ok && name != "" {
+				*list = append(*list, name)
+			}
+		}
+	}
+
+	volumes, _ := podSpec["volumes"].([]interface{})
+	for _, v := range volumes {
+		volume, _ := v.(map[string]interface{})
+		add(&configMaps, volume["configMap"], "name")
+		add(&secrets, volume["secret"], "secretName")
+		projected, _ := volume["projected"].(map[string]interface{})
+		sources, _ := projected["sources"].([]interface{})
+		for _, s := range sources {
+			source, _ := s.(map[string]interface{})
+			add(&configMaps, source["configMap"], "name")
+			add(&secrets, source["secret"], "name")
+		}
+	}
+	for _, key := range []string{"initContainers", "containers"} {
+		containers, _ := podSpec[key].([]interface{})
+		for _, c := range containers {
+			container, _ := c.(map[string]interface{})
+			env, _ := container["env"].([]interface{})
+			for _, e := range env {
+				variable, _ := e.(map[string]interface{})
+				valueFrom, _ := variable["valueFrom"].(map[string]interface{})
+				add(&configMaps, valueFrom["configMapKeyRef"], "name")
+				add(&secrets, valueFrom["secretKeyRef"], "name")
+			}
+			envFrom, _ := container["envFrom"].([]interface{})
+			for _, e := range envFrom {
+				source, _ := e.(map[string]interface{})
+				add(&configMaps, source["configMapRef"], "name")
+				add(&secrets, source["secretRef"], "name")
+			}
+		}
+	}
+	return uniqueSorted(configMaps), uniqueSorted(secrets)
+}
+
+// kubectlList returns the items of a kubectl get list
+func kubectlList(ctx context.Context, namespace, resource string) ([]map[string]interface{}, error) {
+	out, err := utils.ExecuteCommand(ctx, "kubectl", "-n", namespace, "get", resource, "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s in namespace %s: %w", resource, namespace, err)
+	}
+	var list struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", resource, err)
+	}
+	return list.Items, nil
+}
+
+// findObjectByComponent returns the first object whose name contains the component, as helm release names prefix them
+func findObjectByComponent(items []map[string]interface{}, component string) map[string]interface{} {
+	for _, item := range items {
+		if name, _ := objectName(item); strings.Contains(name, component) {
+			return item
+		}
+	}
+	return nil
+}
+
+func podSpecOf(workload map[string]interface{}) map[string]interface{} {
+	spec, _ := workload["spec"].(map[string]interface{})
+	template, _ := spec["template"].(map[string]interface{})
+	podSpec, _ := template["spec"].(map[string]interface{})
+	return podSpec
+}
+
+func objectName(obj interface{}) (string, bool) {
+	m, _ := obj.(map[string]interface{})
+	meta, _ := m["metadata"].(map[string]interface{})
+	name, ok := meta["name"].(string)
+	return name, ok && name != ""
+}
+
+func uniqueSorted(values []string) []string {
+	seen := map[string]bool{}
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+func writeManifest(path string, obj map[string]interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, verifyManifestPermissions)
+}
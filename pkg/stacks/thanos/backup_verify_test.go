@@ -0,0 +1,126 @@
+package thanos
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testOpNodeStatefulSet = `{
+  "apiVersion": "apps/v1",
+  "kind": "StatefulSet",
+  "metadata": {"name": "thanos-op-node", "namespace": "thanos", "uid": "1234", "resourceVersion": "99",
+    "labels": {"app": "op-node"}, "annotations": {"meta.helm.sh/release-name": "thanos"}},
+  "spec": {
+    "replicas": 2,
+    "serviceName": "thanos-op-node",
+    "template": {"spec": {
+      "serviceAccountName": "thanos-sa",
+      "volumes": [
+        {"name": "data", "persistentVolumeClaim": {"claimName": "thanos-op-node"}},
+        {"name": "rollup", "configMap": {"name": "thanos-rollup"}},
+        {"name": "jwt", "secret": {"secretName": "thanos-jwt"}}
+      ],
+      "containers": [{
+        "name": "op-node",
+        "command": ["sh", "-c", "op-node --l2=http://thanos-op-geth.thanos.svc.cluster.local:8551 --sequencer.enabled --p2p.listen.tcp=9003"],
+        "args": ["--maxpeers", "50", "--sequencer.enabled=true"],
+        "env": [
+          {"name": "OP_NODE_SEQUENCER_ENABLED", "value": "true"},
+          {"name": "OP_NODE_L1_ETH_RPC", "valueFrom": {"secretKeyRef": {"name": "thanos-l1", "key": "url"}}},
+          {"name": "OP_NODE_L2_ENGINE_RPC", "value": "http://thanos-op-geth.thanos.svc:8551"}
+        ],
+        "envFrom": [{"configMapRef": {"name": "thanos-env"}}]
+      }]
+    }},
+    "volumeClaimTemplates": [{"metadata": {"name": "cache"}}]
+  },
+  "status": {"readyReplicas": 2}
+}`
+
+func TestScratchStatefulSet(t *testing.T) {
+	var sts map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(testOpNodeStatefulSet), &sts))
+
+	configMaps, secrets := referencedConfigObjects(podSpecOf(sts))
+	require.Equal(t, []string{"thanos-env", "thanos-rollup"}, configMaps)
+	require.Equal(t, []string{"thanos-jwt", "thanos-l1"}, secrets)
+
+	require.NoError(t, scratchStatefulSet(sts, "thanos", "thanos-verify-1", "thanos-verify-1-efs"))
+
+	require.Equal(t, map[string]interface{}{
+		"name": "thanos-op-node", "namespace": "thanos-verify-1", "labels": map[string]interface{}{"app": "op-node"},
+	}, sts["metadata"])
+	require.NotContains(t, sts, "status")
+	spec := sts["spec"].(map[string]interface{})
+	require.Equal(t, 1, spec["replicas"])
+	require.NotContains(t, spec, "volumeClaimTemplates")
+
+	podSpec := podSpecOf(sts)
+	require.NotContains(t, podSpec, "serviceAccountName")
+	var claims []string
+	for _, v := range podSpec["volumes"].([]interface{}) {
+		if pvc, ok := v.(map[string]interface{})["persistentVolumeClaim"].(map[string]interface{}); ok {
+			claims = append(claims, pvc["claimName"].(string))
+		}
+	}
+	require.Equal(t, []string{"thanos-verify-1-efs", "thanos-verify-1-efs"}, claims)
+
+	container := podSpec["containers"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, []interface{}{"sh", "-c",
+		"op-node --l2=http://thanos-op-geth.thanos-verify-1.svc.cluster.local:8551 --sequencer.enabled=false --p2p.listen.tcp=9003"},
+		container["command"])
+	require.Equal(t, []interface{}{"--maxpeers", "0", "--sequencer.enabled=false"}, container["args"])
+
+	env := map[string]interface{}{}
+	for _, e := range container["env"].([]interface{}) {
+		variable := e.(map[string]interface{})
+		env[variable["name"].(string)] = variable["value"]
+	}
+	require.Equal(t, "false", env["OP_NODE_SEQUENCER_ENABLED"])
+	require.Equal(t, "true", env["OP_NODE_P2P_DISABLE"])
+	require.Equal(t, "0", env["GETH_MAXPEERS"])
+	require.Equal(t, "http://thanos-op-geth.thanos-verify-1.svc:8551", env["OP_NODE_L2_ENGINE_RPC"])
+	require.Nil(t, env["OP_NODE_L1_ETH_RPC"], "secret references are kept")
+}
+
+func TestScratchService(t *testing.T) {
+	var svc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"metadata": {"name": "thanos-op-geth", "namespace": "thanos"},
+		"spec": {"type": "LoadBalancer", "clusterIP": "10.0.0.1", "clusterIPs": ["10.0.0.1"], "externalTrafficPolicy": "Cluster",
+		"ports": [{"name": "rpc", "port": 8545, "nodePort": 30545}], "selector": {"app": "op-geth"}}}`), &svc))
+
+	scratchService(svc, "thanos-verify-1")
+	require.Equal(t, map[string]interface{}{
+		"type":     "ClusterIP",
+		"ports":    []interface{}{map[string]interface{}{"name": "rpc", "port": float64(8545)}},
+		"selector": map[string]interface{}{"app": "op-geth"},
+	}, svc["spec"])
+}
+
+func TestRestoredHeadAgeCheck(t *testing.T) {
+	created := "2026-05-02T03:00:00.000000+00:00"
+	createdAt, err := time.Parse(time.RFC3339Nano, created)
+	require.NoError(t, err)
+
+	require.True(t, restoredHeadAgeCheck(createdAt.Add(-2*time.Second), created).Passed)
+	require.True(t, restoredHeadAgeCheck(createdAt.Add(5*time.Minute), created).Passed, "blocks produced while the backup ran")
+	require.False(t, restoredHeadAgeCheck(createdAt.Add(-3*time.Hour), created).Passed)
+	require.False(t, restoredHeadAgeCheck(createdAt.Add(time.Hour), created).Passed)
+	require.True(t, restoredHeadAgeCheck(createdAt, "").Passed, "unknown creation dates are not checked")
+}
+
+func TestParseRestoreJobEFS(t *testing.T) {
+	status, efsID := parseRestoreJobEFS("COMPLETED\tarn:aws:elasticfilesystem:us-east-1:123456789012:file-system/fs-0abc\n")
+	require.Equal(t, "COMPLETED", status)
+	require.Equal(t, "fs-0abc", efsID)
+
+	status, efsID = parseRestoreJobEFS("RUNNING\tNone\n")
+	require.Equal(t, "RUNNING", status)
+	require.Empty(t, efsID)
+
+	status, _ = parseRestoreJobEFS("")
+	require.Empty(t, status)
+}
@@ -0,0 +1,374 @@
+package thanos
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go.uber.org/zap"
+
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+const (
+	// DefaultBalanceWatcherListenAddr serves the balance watcher /metrics and /healthz endpoints
+	DefaultBalanceWatcherListenAddr = ":7310"
+	// BalanceWatcherStateFileName keeps the daily top-up spend across restarts
+	BalanceWatcherStateFileName = "balance-watcher-state.json"
+
+	defaultBalanceWatcherPollInterval = time.Minute
+	balanceWatcherReceiptTimeout      = 5 * time.Minute
+	balanceWatcherTransferGas         = uint64(21_000)
+)
+
+// BalanceWatcherAccount is an L1 operator account watched by the balance watcher
+type BalanceWatcherAccount struct {
+	// Name is the role of the account (batcher, proposer, challenger), used as metric label
+	Name    string
+	Address common.Address
+	// Threshold is the balance below which the account is reported low and topped up
+	Threshold *big.Int
+	// TopUpAmount is sent from the funding wallet when the balance is below the threshold
+	TopUpAmount *big.Int
+}
+
+// BalanceWatcherConfig holds the runtime configuration of the balance watcher
+type BalanceWatcherConfig struct {
+	L1RPCURL string
+	Accounts []BalanceWatcherAccount
+	// FundingPrivateKey is the L1 wallet that tops up the accounts. When empty, the watcher only
+	// reports balances.
+	FundingPrivateKey string
+	// DailyCap is the maximum amount sent by the funding wallet per UTC day
+	DailyCap *big.Int
+	// PollInterval defaults to one minute
+	PollInterval time.Duration
+	// ListenAddr of the metrics server. Empty disables it.
+	ListenAddr string
+	// StateFile persists the daily spend. Empty keeps it in memory only.
+	StateFile string
+}
+
+// balanceWatcherClient is the subset of ethclient.Client used by the watcher
+type balanceWatcherClient interface {
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethTypes.Receipt, error)
+}
+
+type balanceWatcher struct {
+	cfg     BalanceWatcherConfig
+	client  balanceWatcherClient
+	logger  *zap.SugaredLogger
+	metrics *operatorMetrics
+
+	fundingKey     *ecdsa.PrivateKey
+	fundingAddress common.Address
+	chainID        *big.Int
+
+	mu        sync.Mutex
+	spend     dailySpend
+	lastCheck time.Time
+}
+
+// dailySpend is the amount sent by the funding wallet on a UTC day
+type dailySpend struct {
+	Day   string `json:"day"`
+	Spent string `json:"spentWei"`
+}
+
+// OperatorAccounts returns the batcher, proposer and challenger L1 accounts of the deployment
+func (t *ThanosStack) OperatorAccounts(threshold, topUpAmount *big.Int) ([]BalanceWatcherAccount, error) {
+	if t.deployConfig == nil {
+		return nil, fmt.Errorf("deploy configuration is not initialized")
+	}
+	keys := []struct{ name, key string }{
+		{"batcher", t.deployConfig.BatcherPrivateKey},
+		{"proposer", t.deployConfig.ProposerPrivateKey},
+		{"challenger", t.deployConfig.ChallengerPrivateKey},
+	}
+	var accounts []BalanceWatcherAccount
+	for _, k := range keys {
+		if k.key == "" {
+			continue
+		}
+		address, err := utils.GetAddressFromPrivateKey(k.key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s private key: %w", k.name, err)
+		}
+		accounts = append(accounts, BalanceWatcherAccount{Name: k.name, Address: address, Threshold: threshold, TopUpAmount: topUpAmount})
+	}
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no operator private keys in settings.json")
+	}
+	return accounts, nil
+}
+
+// RunBalanceWatcher watches the L1 balances of the operator accounts and blocks until ctx is cancelled.
+//
+// Every poll interval it exports the balances as metrics and, when a funding key is configured,
+// sends TopUpAmount to each account below its threshold as long as the daily cap allows it.
+func RunBalanceWatcher(ctx context.Context, logger *zap.SugaredLogger, cfg BalanceWatcherConfig) error {
+	client, err := ethclient.DialContext(ctx, cfg.L1RPCURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to L1 RPC: %w", err)
+	}
+	defer client.Close()
+
+	w, err := newBalanceWatcher(logger, client, cfg)
+	if err != nil {
+		return err
+	}
+	if w.fundingKey != nil {
+		if w.chainID, err = client.ChainID(ctx); err != nil {
+			return fmt.Errorf("failed to get L1 chain ID: %w", err)
+		}
+	}
+
+	if cfg.ListenAddr != "" {
+		serveOperatorMetrics(ctx, logger, cfg.ListenAddr, w.metrics, func() error { return w.healthy(time.Now()) })
+	}
+
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	logger.Infof("Balance watcher started (accounts=%d, poll=%s, top-up=%t)", len(cfg.Accounts), w.cfg.PollInterval, w.fundingKey != nil)
+	for {
+		if err := w.check(ctx, time.Now()); err != nil {
+			logger.Warnf("Balance check failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			logger.Infof("Balance watcher stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func newBalanceWatcher(logger *zap.SugaredLogger, client balanceWatcherClient, cfg BalanceWatcherConfig) (*balanceWatcher, error) {
+	if len(cfg.Accounts) == 0 {
+		return nil, fmt.Errorf("no accounts to watch")
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultBalanceWatcherPollInterval
+	}
+	w := &balanceWatcher{cfg: cfg, client: client, logger: logger, metrics: newOperatorMetrics()}
+
+	if cfg.FundingPrivateKey != "" {
+		if cfg.DailyCap == nil || cfg.DailyCap.Sign() <= 0 {
+			return nil, fmt.Errorf("a daily cap is required to top up accounts")
+		}
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(cfg.FundingPrivateKey, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid funding private key: %w", err)
+		}
+		w.fundingKey = key
+		w.fundingAddress = crypto.PubkeyToAddress(key.PublicKey)
+		for _, account := range cfg.Accounts {
+			if account.Address == w.fundingAddress {
+				return nil, fmt.Errorf("the funding wallet is the %s account", account.Name)
+			}
+		}
+		w.metrics.setGauge("balance_watcher_daily_cap_wei", "Maximum amount sent by the funding wallet per UTC day", weiToFloat(cfg.DailyCap))
+	}
+
+	if cfg.StateFile != "" {
+		if data, err := os.ReadFile(cfg.StateFile); err == nil {
+			if err := json.Unmarshal(data, &w.spend); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", cfg.StateFile, err)
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %w", cfg.StateFile, err)
+		}
+	}
+	return w, nil
+}
+
+// check updates the balance metrics and tops up the accounts below their threshold
+func (w *balanceWatcher) check(ctx context.Context, now time.Time) error {
+	var failed []string
+	for _, account := range w.cfg.Accounts {
+		balance, err := w.client.BalanceAt(ctx, account.Address, nil)
+		if err != nil {
+			w.metrics.addCounter("balance_watcher_check_errors_total", "Failed balance checks", 1, "account", account.Name)
+			failed = append(failed, fmt.Sprintf("%s: %v", account.Name, err))
+			continue
+		}
+		labels := []string{"account", account.Name, "address", account.Address.Hex()}
+		w.metrics.setGauge("balance_watcher_balance_wei", "L1 balance of the operator account", weiToFloat(balance), labels...)
+		w.metrics.setGauge("balance_watcher_threshold_wei", "Balance below which the operator account is low", weiToFloat(account.Threshold), labels...)
+
+		if balance.Cmp(account.Threshold) >= 0 {
+			continue
+		}
+		w.logger.Warnf("%s balance is low: %s ETH (threshold %s ETH)", account.Name, utils.WeiToEther(balance).Text('f', 6), utils.WeiToEther(account.Threshold).Text('f', 6))
+		if w.fundingKey == nil {
+			continue
+		}
+		if err := w.topUp(ctx, account, now); err != nil {
+			failed = append(failed, fmt.Sprintf("%s top-up: %v", account.Name, err))
+		}
+	}
+
+	if w.fundingKey != nil {
+		if balance, err := w.client.BalanceAt(ctx, w.fundingAddress, nil); err == nil {
+			w.metrics.setGauge("balance_watcher_funding_balance_wei", "L1 balance of the funding wallet", weiToFloat(balance), "address", w.fundingAddress.Hex())
+		}
+		w.metrics.setGauge("balance_watcher_spent_today_wei", "Amount sent by the funding wallet today (UTC)", weiToFloat(w.spentOn(now)))
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%s", strings.Join(failed, "; "))
+	}
+	w.mu.Lock()
+	w.lastCheck = now
+	w.mu.Unlock()
+	w.metrics.setGauge("balance_watcher_last_check_timestamp_seconds", "Time of the last successful balance check", float64(now.Unix()))
+	return nil
+}
+
+// topUp sends the top-up amount to the account if the daily cap allows it
+func (w *balanceWatcher) topUp(ctx context.Context, account BalanceWatcherAccount, now time.Time) error {
+	spent := w.spentOn(now)
+	if new(big.Int).Add(spent, account.TopUpAmount).Cmp(w.cfg.DailyCap) > 0 {
+		w.metrics.addCounter("balance_watcher_topups_total", "Top-ups of operator accounts by result", 1, "account", account.Name, "result", "capped")
+		w.logger.Errorf("Daily top-up cap reached: %s of %s ETH spent today, %s not topped up",
+			utils.WeiToEther(spent).Text('f', 6), utils.WeiToEther(w.cfg.DailyCap).Text('f', 6), account.Name)
+		return nil
+	}
+
+	// A top-up whose receipt wait timed out may still be mined; never send another one on top of it
+	pending, err := w.hasPendingTransfer(ctx)
+	if err != nil {
+		w.metrics.addCounter("balance_watcher_topups_total", "Top-ups of operator accounts by result", 1, "account", account.Name, "result", "failed")
+		return err
+	}
+	if pending {
+		w.metrics.addCounter("balance_watcher_topups_total", "Top-ups of operator accounts by result", 1, "account", account.Name, "result", "pending")
+		w.logger.Warnf("The funding wallet has a pending transaction, %s is topped up after it is mined", account.Name)
+		return nil
+	}
+
+	sent, err := w.sendTransfer(ctx, account.Address, account.TopUpAmount)
+	// A sent transfer counts against the cap even without a receipt, so a slow L1 never doubles a top-up
+	if sent {
+		if err := w.recordSpend(now, account.TopUpAmount); err != nil {
+			w.logger.Warnf("Failed to save the daily spend: %v", err)
+		}
+	}
+	if err != nil {
+		w.metrics.addCounter("balance_watcher_topups_total", "Top-ups of operator accounts by result", 1, "account", account.Name, "result", "failed")
+		return err
+	}
+	w.metrics.addCounter("balance_watcher_topups_total", "Top-ups of operator accounts by result", 1, "account", account.Name, "result", "success")
+	w.logger.Infof("✅ Topped up %s (%s) with %s ETH", account.Name, account.Address.Hex(), utils.WeiToEther(account.TopUpAmount).Text('f', 6))
+	return nil
+}
+
+// hasPendingTransfer reports whether a transaction of the funding wallet is not mined yet
+func (w *balanceWatcher) hasPendingTransfer(ctx context.Context) (bool, error) {
+	pending, err := w.client.PendingNonceAt(ctx, w.fundingAddress)
+	if err != nil {
+		return false, fmt.Errorf("get pending nonce: %w", err)
+	}
+	mined, err := w.client.NonceAt(ctx, w.fundingAddress, nil)
+	if err != nil {
+		return false, fmt.Errorf("get nonce: %w", err)
+	}
+	return pending > mined, nil
+}
+
+// sendTransfer sends value from the funding wallet and waits for the receipt. sent reports whether
+// the transaction reached the L1 RPC.
+func (w *balanceWatcher) sendTransfer(ctx context.Context, to common.Address, value *big.Int) (sent bool, err error) {
+	nonce, err := w.client.PendingNonceAt(ctx, w.fundingAddress)
+	if err != nil {
+		return false, fmt.Errorf("get nonce: %w", err)
+	}
+	gasPrice, err := w.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return false, fmt.Errorf("get gas price: %w", err)
+	}
+	gasPrice = new(big.Int).Mul(gasPrice, big.NewInt(2))
+
+	tx := ethTypes.NewTransaction(nonce, to, value, balanceWatcherTransferGas, gasPrice, nil)
+	signedTx, err := ethTypes.SignTx(tx, ethTypes.NewEIP155Signer(w.chainID), w.fundingKey)
+	if err != nil {
+		return false, fmt.Errorf("sign tx: %w", err)
+	}
+	if err := w.client.SendTransaction(ctx, signedTx); err != nil {
+		return false, fmt.Errorf("send tx: %w", err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, balanceWatcherReceiptTimeout)
+	defer cancel()
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+	for {
+		receipt, err := w.client.TransactionReceipt(waitCtx, signedTx.Hash())
+		if err == nil {
+			if receipt.Status != ethTypes.ReceiptStatusSuccessful {
+				return true, fmt.Errorf("tx %s reverted", signedTx.Hash().Hex())
+			}
+			return true, nil
+		}
+		select {
+		case <-waitCtx.Done():
+			return true, fmt.Errorf("tx %s not mined after %s", signedTx.Hash().Hex(), balanceWatcherReceiptTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// spentOn returns the amount sent on the UTC day of now
+func (w *balanceWatcher) spentOn(now time.Time) *big.Int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	spent, ok := new(big.Int).SetString(w.spend.Spent, 10)
+	if w.spend.Day != now.UTC().Format(time.DateOnly) || !ok {
+		return new(big.Int)
+	}
+	return spent
+}
+
+func (w *balanceWatcher) recordSpend(now time.Time, amount *big.Int) error {
+	total := new(big.Int).Add(w.spentOn(now), amount)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.spend = dailySpend{Day: now.UTC().Format(time.DateOnly), Spent: total.String()}
+	if w.cfg.StateFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(w.spend)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(w.cfg.StateFile, data, 0600)
+}
+
+// healthy fails when no balance check succeeded within three poll intervals
+func (w *balanceWatcher) healthy(now time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.lastCheck.IsZero() {
+		return fmt.Errorf("no successful balance check yet")
+	}
+	if age := now.Sub(w.lastCheck); age > 3*w.cfg.PollInterval {
+		return fmt.Errorf("last successful balance check was %s ago", age.Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package thanos
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// fakeL1 applies transfers to its balances as soon as they are sent. The last unmined transfers are
+// pending until they are mined.
+type fakeL1 struct {
+	balances map[common.Address]*big.Int
+	sent     []*ethTypes.Transaction
+	unmined  int
+	failAt   map[common.Address]bool
+}
+
+func (f *fakeL1) BalanceAt(_ context.Context, account common.Address, _ *big.Int) (*big.Int, error) {
+	if f.failAt[account] {
+		return nil, fmt.Errorf("rpc timeout")
+	}
+	if balance, ok := f.balances[account]; ok {
+		return new(big.Int).Set(balance), nil
+	}
+	return new(big.Int), nil
+}
+
+func (f *fakeL1) NonceAt(context.Context, common.Address, *big.Int) (uint64, error) {
+	return uint64(len(f.sent) - f.unmined), nil
+}
+
+func (f *fakeL1) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return uint64(len(f.sent)), nil
+}
+
+func (f *fakeL1) SuggestGasPrice(context.Context) (*big.Int, error) {
+	return big.NewInt(1e9), nil
+}
+
+func (f *fakeL1) SendTransaction(_ context.Context, tx *ethTypes.Transaction) error {
+	f.sent = append(f.sent, tx)
+	balance := f.balances[*tx.To()]
+	if balance == nil {
+		balance = new(big.Int)
+	}
+	f.balances[*tx.To()] = new(big.Int).Add(balance, tx.Value())
+	return nil
+}
+
+func (f *fakeL1) TransactionReceipt(context.Context, common.Hash) (*ethTypes.Receipt, error) {
+	return &ethTypes.Receipt{Status: ethTypes.ReceiptStatusSuccessful}, nil
+}
+
+func ether(t *testing.T, amount string) *big.Int {
+	wei, err := utils.EtherToWei(amount)
+	require.NoError(t, err)
+	return wei
+}
+
+func TestBalanceWatcherTopUp(t *testing.T) {
+	fundingKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	batcher := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	proposer := common.HexToAddress("0x00000000000000000000000000000000000000b2")
+
+	l1 := &fakeL1{balances: map[common.Address]*big.Int{
+		batcher:  ether(t, "0.1"),
+		proposer: ether(t, "2"),
+	}}
+	stateFile := filepath.Join(t.TempDir(), BalanceWatcherStateFileName)
+	cfg := BalanceWatcherConfig{
+		Accounts: []BalanceWatcherAccount{
+			{Name: "batcher", Address: batcher, Threshold: ether(t, "0.5"), TopUpAmount: ether(t, "1")},
+			{Name: "proposer", Address: proposer, Threshold: ether(t, "0.5"), TopUpAmount: ether(t, "1")},
+		},
+		FundingPrivateKey: fmt.Sprintf("%x", crypto.FromECDSA(fundingKey)),
+		DailyCap:          ether(t, "1.5"),
+		StateFile:         stateFile,
+	}
+	w, err := newBalanceWatcher(zap.NewNop().Sugar(), l1, cfg)
+	require.NoError(t, err)
+	w.chainID = big.NewInt(11155111)
+
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, w.check(context.Background(), now))
+	require.Len(t, l1.sent, 1, "only the batcher is below the threshold")
+	require.Equal(t, batcher, *l1.sent[0].To())
+	require.Equal(t, 0, ether(t, "1").Cmp(w.spentOn(now)))
+
+	// The batcher drains again; a second top-up would exceed the 1.5 ETH cap
+	l1.balances[batcher] = ether(t, "0.2")
+	require.NoError(t, w.check(context.Background(), now.Add(time.Hour)))
+	require.Len(t, l1.sent, 1)
+	require.Equal(t, float64(1), w.metrics.value("balance_watcher_topups_total", "account", "batcher", "result", "capped"))
+
+	// The spend survives a restart and resets on the next UTC day
+	restarted, err := newBalanceWatcher(zap.NewNop().Sugar(), l1, cfg)
+	require.NoError(t, err)
+	require.Equal(t, 0, ether(t, "1").Cmp(restarted.spentOn(now)))
+	restarted.chainID = w.chainID
+	require.NoError(t, restarted.check(context.Background(), now.Add(24*time.Hour)))
+	require.Len(t, l1.sent, 2)
+
+	var metrics strings.Builder
+	require.NoError(t, restarted.metrics.writeTo(&metrics))
+	require.Contains(t, metrics.String(), "# TYPE balance_watcher_balance_wei gauge")
+	require.Contains(t, metrics.String(), fmt.Sprintf(`balance_watcher_balance_wei{account="batcher",address="%s"} 2e+17`, batcher.Hex()))
+	require.Contains(t, metrics.String(), `balance_watcher_topups_total{account="batcher",result="success"} 1`)
+	require.NoError(t, restarted.healthy(now.Add(24*time.Hour+time.Minute)))
+	require.Error(t, restarted.healthy(now.Add(25*time.Hour)))
+
+	// A top-up that is still pending after its receipt wait is not sent again
+	l1.balances[batcher] = ether(t, "0.2")
+	l1.unmined = 1
+	require.NoError(t, restarted.check(context.Background(), now.Add(48*time.Hour)))
+	require.Len(t, l1.sent, 2)
+	require.Equal(t, float64(1), restarted.metrics.value("balance_watcher_topups_total", "account", "batcher", "result", "pending"))
+	l1.unmined = 0
+
+	// Failed balance checks are reported and keep the watcher unhealthy
+	l1.failAt = map[common.Address]bool{proposer: true}
+	watchOnly, err := newBalanceWatcher(zap.NewNop().Sugar(), l1, BalanceWatcherConfig{Accounts: cfg.Accounts})
+	require.NoError(t, err)
+	require.ErrorContains(t, watchOnly.check(context.Background(), now), "proposer: rpc timeout")
+	require.Error(t, watchOnly.healthy(now))
+
+	_, err = newBalanceWatcher(zap.NewNop().Sugar(), l1, BalanceWatcherConfig{Accounts: cfg.Accounts, FundingPrivateKey: cfg.FundingPrivateKey})
+	require.ErrorContains(t, err, "daily cap")
+}
+
+func TestOperatorAccounts(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	stack := &ThanosStack{deployConfig: &types.Config{
+		BatcherPrivateKey:  fmt.Sprintf("0x%x", crypto.FromECDSA(key)),
+		ProposerPrivateKey: fmt.Sprintf("%x", crypto.FromECDSA(key)),
+	}}
+	accounts, err := stack.OperatorAccounts(big.NewInt(1), big.NewInt(2))
+	require.NoError(t, err)
+	require.Len(t, accounts, 2, "the challenger key is optional")
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), accounts[0].Address)
+
+	_, err = (&ThanosStack{deployConfig: &types.Config{}}).OperatorAccounts(nil, nil)
+	require.Error(t, err)
+
+	wei, err := utils.EtherToWei("0.000000000000000001")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), wei.Int64())
+	_, err = utils.EtherToWei("0.0000000000000000001")
+	require.Error(t, err)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos/backup"
 	"github.com/tokamak-network/trh-sdk/pkg/types"
 	"github.com/tokamak-network/trh-sdk/pkg/utils"
 )
@@ -120,6 +121,12 @@ func (t *ThanosStack) InstallBlockExplorer(ctx context.Context, inputs *InstallB
 
 	rdsConnectionUrl = strings.Trim(rdsConnectionUrl, `"`)
 
+	if t.deployConfig.BackupConfig != nil && t.deployConfig.BackupConfig.Enabled {
+		if err := backup.EnableRDSBackup(ctx, t.logger, t.deployConfig.AWS.Region, namespace); err != nil {
+			t.logger.Warnf("Failed to enable block explorer database backup: %v", err)
+		}
+	}
+
 	opGethSVC, err := utils.WaitForServiceName(ctx, namespace, "op-geth", 45*time.Minute)
 	if err != nil {
 		t.logger.Error("Error retrieving op-geth service name", "err", err)
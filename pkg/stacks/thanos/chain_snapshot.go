@@ -0,0 +1,873 @@
+package thanos
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/trh-sdk/pkg/scanner"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+const (
+	// ChainSnapshotArchiveName is the gzipped tarball of the op-geth datadir in a snapshot directory
+	ChainSnapshotArchiveName  = "op-geth-datadir.tar.gz"
+	chainSnapshotManifestName = "manifest.json"
+
+	chainSnapshotSourceLocal      = "local"
+	chainSnapshotSourceKubernetes = "kubernetes"
+
+	chainSnapshotPodLabel        = "trh-sdk/chain-snapshot"
+	chainSnapshotPodReadyTimeout = "5m"
+	chainSnapshotRolloutTimeout  = "20m"
+	chainSnapshotScaleTimeout    = 10 * time.Minute
+	chainSnapshotHeadTimeout     = 5 * time.Minute
+
+	// chainSnapshotArchiveRate is a conservative rate in bytes per second of archiving and uploading the
+	// datadir, and chainSnapshotRestartTime the time to scale op-node and op-geth down and up again. Both
+	// only feed the downtime estimate of an export.
+	chainSnapshotArchiveRate = 50 << 20
+	chainSnapshotRestartTime = 3 * time.Minute
+)
+
+// chainSnapshotExcludes are the files of the datadir that belong to the node rather than the chain. A node
+// seeded from the snapshot generates its own p2p key.
+var chainSnapshotExcludes = []string{"./geth/nodekey", "./geth/LOCK", "./geth.ipc"}
+
+// ChainSnapshotExport stops the sequencer and op-geth, archives the op-geth datadir with a manifest of its head
+// into a new directory of the destination, a local directory or an s3:// URL, and starts them again
+func (t *ThanosStack) ChainSnapshotExport(ctx context.Context, opts types.ChainSnapshotExportOptions) (*types.ChainSnapshotManifest, error) {
+	if strings.TrimSpace(opts.Destination) == "" {
+		return nil, fmt.Errorf("a destination directory or s3:// URL is required")
+	}
+
+	now := time.Now().UTC()
+	manifest := &types.ChainSnapshotManifest{ID: now.Format("20060102-150405"), CreatedAt: now, File: ChainSnapshotArchiveName}
+	if t.deployConfig != nil {
+		manifest.ChainName = t.deployConfig.ChainName
+		manifest.L2ChainID = t.deployConfig.L2ChainID
+	}
+	store := chainSnapshotStore{location: joinChainSnapshotLocation(opts.Destination, manifest.ID), endpointURL: opts.EndpointURL}
+
+	var err error
+	switch {
+	case t.IsLocalDeployment():
+		err = t.exportLocalChainSnapshot(ctx, store, manifest)
+	case t.deployConfig != nil && t.deployConfig.K8s != nil:
+		err = t.exportKubernetesChainSnapshot(ctx, store, manifest, opts.Yes)
+	default:
+		err = fmt.Errorf("no local or Kubernetes deployment found in %s", t.deploymentPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := writeChainSnapshotManifest(ctx, store, manifest); err != nil {
+		return nil, err
+	}
+
+	t.logger.Infof("✅ Snapshot of block #%d %s exported (%s)", manifest.BlockNumber, manifest.BlockHash, formatBackupSize(manifest.Size))
+	t.logger.Infof("   Location: %s", store.location)
+	t.logger.Infof("   Import it with: trh-sdk snapshot import --from %s", store.location)
+	return manifest, nil
+}
+
+// exportLocalChainSnapshot archives the op-geth volume of the local deployment. op-node is stopped first so the
+// head read from op-geth is the head of the archived datadir.
+func (t *ThanosStack) exportLocalChainSnapshot(ctx context.Context, store chainSnapshotStore, manifest *types.ChainSnapshotManifest) error {
+	volume := filepath.Base(t.deploymentPath) + "_op-geth-data"
+	if !volumeExists(ctx, volume) {
+		return fmt.Errorf("op-geth volume %s not found, deploy the chain first", volume)
+	}
+	manifest.Source = chainSnapshotSourceLocal
+
+	opNode, err := t.stopLocalService(ctx, "op-node")
+	if err != nil {
+		return err
+	}
+	defer t.startLocalContainers(ctx, opNode)
+	if err := readChainSnapshotHead(ctx, localL2RPCURL(), manifest); err != nil {
+		return err
+	}
+
+	containers, err := t.stopLocalContainers(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.startLocalContainers(ctx, containers)
+
+	t.logger.Infof("📦 Archiving the op-geth datadir at block #%d...", manifest.BlockNumber)
+	return archiveChainSnapshot(ctx, store, manifest, dockerVolumeDatadir(volume))
+}
+
+// exportKubernetesChainSnapshot archives the op-geth datadir from its claim with a helper pod, while op-node
+// and op-geth are scaled down. Unless confirmed, it asks first with the expected downtime.
+func (t *ThanosStack) exportKubernetesChainSnapshot(ctx context.Context, store chainSnapshotStore, manifest *types.ChainSnapshotManifest, confirmed bool) error {
+	namespace := t.deployConfig.K8s.Namespace
+	opGeth, opNode, claim, err := opGethStatefulSets(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	manifest.Source = chainSnapshotSourceKubernetes
+
+	if !confirmed {
+		t.logger.Warnf("⚠️  op-node and op-geth (the sequencer) of %s are scaled to zero until the datadir is archived.", namespace)
+		t.logger.Warnf("   The chain produces no blocks and its RPC is unavailable meanwhile. Expected downtime: %s", t.chainSnapshotDowntimeEstimate(ctx, namespace, opGeth, claim))
+		fmt.Print("Scale down the sequencer and export the snapshot? (y/N): ")
+		confirm, err := scanner.ScanBool(false)
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			return fmt.Errorf("snapshot export cancelled")
+		}
+	}
+
+	restoreOpNode, err := t.scaleDownStatefulSet(ctx, namespace, opNode)
+	if err != nil {
+		return err
+	}
+	defer restoreOpNode()
+
+	address, stop, err := forwardServiceRPC(ctx, namespace, "op-geth")
+	if err != nil {
+		return err
+	}
+	err = readChainSnapshotHead(ctx, "http://"+address, manifest)
+	stop()
+	if err != nil {
+		return err
+	}
+
+	restoreOpGeth, err := t.scaleDownStatefulSet(ctx, namespace, opGeth)
+	if err != nil {
+		return err
+	}
+	defer restoreOpGeth()
+
+	datadir, cleanup, err := t.openClaimDatadir(ctx, claim)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	t.logger.Infof("📦 Archiving the op-geth datadir at block #%d from claim %s...", manifest.BlockNumber, claim.Claim)
+	return archiveChainSnapshot(ctx, store, manifest, datadir)
+}
+
+// chainSnapshotDowntimeEstimate describes the expected downtime of an export from the size of the datadir in
+// the running op-geth pod
+func (t *ThanosStack) chainSnapshotDowntimeEstimate(ctx context.Context, namespace, opGeth string, claim chainSnapshotClaim) string {
+	out, err := utils.ExecuteCommand(ctx, "kubectl", "-n", namespace, "exec", opGeth+"-0", "-c", claim.Container, "--", "du", "-sk", claim.MountPath)
+	if err == nil {
+		if fields := strings.Fields(out); len(fields) > 0 {
+			if kb, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+				size := kb * 1024
+				return fmt.Sprintf("about %s for a %s datadir", chainSnapshotDowntime(size), formatBackupSize(size))
+			}
+		}
+	}
+	t.logger.Debugf("Failed to read the op-geth datadir size: %v", err)
+	return fmt.Sprintf("at least %s, the datadir size could not be read", chainSnapshotRestartTime)
+}
+
+// chainSnapshotDowntime estimates how long the sequencer is down to export a datadir of size bytes
+func chainSnapshotDowntime(size int64) time.Duration {
+	archive := time.Duration(size/chainSnapshotArchiveRate) * time.Second
+	return (chainSnapshotRestartTime + archive).Round(time.Minute)
+}
+
+// ChainSnapshotImport seeds an op-geth datadir from a snapshot after checking it belongs to the chain of the
+// deployment. Without a volume or claim the datadir of the deployment is replaced while op-geth is stopped,
+// and its head is checked against the manifest when it is back.
+func (t *ThanosStack) ChainSnapshotImport(ctx context.Context, opts types.ChainSnapshotImportOptions) (*types.ChainSnapshotManifest, error) {
+	if strings.TrimSpace(opts.Source) == "" {
+		return nil, fmt.Errorf("a snapshot directory or s3:// URL is required")
+	}
+	if opts.Volume != "" && opts.PVC != "" {
+		return nil, fmt.Errorf("--volume and --pvc cannot be used together")
+	}
+
+	store := chainSnapshotStore{location: strings.TrimSuffix(opts.Source, "/"), endpointURL: opts.EndpointURL}
+	manifest, err := readChainSnapshotManifest(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.AllowOtherChain && t.deployConfig != nil && t.deployConfig.L2ChainID != 0 && manifest.L2ChainID != 0 &&
+		t.deployConfig.L2ChainID != manifest.L2ChainID {
+		return nil, fmt.Errorf("snapshot %s belongs to chain ID %d, the deployment is chain ID %d", manifest.ID, manifest.L2ChainID, t.deployConfig.L2ChainID)
+	}
+	t.logger.Infof("📦 Snapshot %s: chain ID %d, block #%d %s, %s", manifest.ID, manifest.L2ChainID, manifest.BlockNumber, manifest.BlockHash, manifest.ClientVersion)
+
+	switch {
+	case opts.Volume != "":
+		if err := ensureLocalVolume(ctx, "", "", opts.Volume); err != nil {
+			return nil, err
+		}
+		err = t.extractChainSnapshot(ctx, store, manifest, dockerVolumeDatadir(opts.Volume))
+
+	case opts.PVC != "":
+		claim := chainSnapshotClaim{Namespace: opts.Namespace, Claim: opts.PVC, SubPath: opts.SubPath}
+		if claim.Namespace == "" && t.deployConfig != nil && t.deployConfig.K8s != nil {
+			claim.Namespace = t.deployConfig.K8s.Namespace
+		}
+		if claim.Namespace == "" {
+			return nil, fmt.Errorf("--namespace is required to seed a claim outside of a deployment")
+		}
+		datadir, cleanup, openErr := t.openClaimDatadir(ctx, claim)
+		if openErr != nil {
+			return nil, openErr
+		}
+		err = t.extractChainSnapshot(ctx, store, manifest, datadir)
+		cleanup()
+
+	case t.IsLocalDeployment():
+		err = t.importLocalChainSnapshot(ctx, store, manifest)
+
+	case t.deployConfig != nil && t.deployConfig.K8s != nil:
+		err = t.importKubernetesChainSnapshot(ctx, store, manifest)
+
+	default:
+		err = fmt.Errorf("no local or Kubernetes deployment found in %s, pass --volume or --pvc", t.deploymentPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t.logger.Infof("✅ Snapshot %s imported", manifest.ID)
+	return manifest, nil
+}
+
+// importLocalChainSnapshot replaces the op-geth volume of the local deployment and marks it as initialized
+// with the current genesis.json, so the next deploy does not wipe it
+func (t *ThanosStack) importLocalChainSnapshot(ctx context.Context, store chainSnapshotStore, manifest *types.ChainSnapshotManifest) error {
+	project := filepath.Base(t.deploymentPath)
+	volume := project + "_op-geth-data"
+
+	containers, err := t.stopLocalContainers(ctx)
+	if err != nil {
+		return err
+	}
+	err = func() error {
+		defer t.startLocalContainers(ctx, containers)
+		if err := ensureLocalVolume(ctx, project, "op-geth-data", volume); err != nil {
+			return err
+		}
+		if err := t.extractChainSnapshot(ctx, store, manifest, dockerVolumeDatadir(volume)); err != nil {
+			return err
+		}
+		if genesisHash, err := hashFile(t.genesisConfigPath()); err == nil {
+			if err := writeGenesisHashToVolume(ctx, volume, genesisHash); err != nil {
+				return fmt.Errorf("failed to persist genesis hash in volume: %w", err)
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		return err
+	}
+
+	if len(containers) == 0 {
+		t.logger.Infof("The chain is not running, its head is checked against the snapshot once it is started")
+		return nil
+	}
+	return t.checkImportedHead(ctx, localL2RPCURL(), manifest)
+}
+
+// importKubernetesChainSnapshot replaces the op-geth datadir in its claim while op-node and op-geth are scaled down
+func (t *ThanosStack) importKubernetesChainSnapshot(ctx context.Context, store chainSnapshotStore, manifest *types.ChainSnapshotManifest) error {
+	namespace := t.deployConfig.K8s.Namespace
+	opGeth, opNode, claim, err := opGethStatefulSets(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	restoreOpNode, err := t.scaleDownStatefulSet(ctx, namespace, opNode)
+	if err != nil {
+		return err
+	}
+	defer restoreOpNode()
+
+	restoreOpGeth, err := t.scaleDownStatefulSet(ctx, namespace, opGeth)
+	if err != nil {
+		return err
+	}
+	err = func() error {
+		defer restoreOpGeth()
+		datadir, cleanup, err := t.openClaimDatadir(ctx, claim)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		return t.extractChainSnapshot(ctx, store, manifest, datadir)
+	}()
+	if err != nil {
+		return err
+	}
+
+	address, stop, err := forwardServiceRPC(ctx, namespace, "op-geth")
+	if err != nil {
+		return err
+	}
+	defer stop()
+	return t.checkImportedHead(ctx, "http://"+address, manifest)
+}
+
+// readChainSnapshotHead fills the head, genesis hash and client version of the manifest from op-geth
+func readChainSnapshotHead(ctx context.Context, rpcURL string, manifest *types.ChainSnapshotManifest) error {
+	client, err := rpc.DialContext(ctx, rpcURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to op-geth at %s: %w", rpcURL, err)
+	}
+	defer client.Close()
+	eth := ethclient.NewClient(client)
+
+	head, err := eth.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read the op-geth head: %w", err)
+	}
+	genesis, err := eth.HeaderByNumber(ctx, big.NewInt(0))
+	if err != nil {
+		return fmt.Errorf("failed to read the genesis block: %w", err)
+	}
+	if err := client.CallContext(ctx, &manifest.ClientVersion, "web3_clientVersion"); err != nil {
+		return fmt.Errorf("failed to read the op-geth client version: %w", err)
+	}
+	if manifest.L2ChainID == 0 {
+		if chainID, err := eth.ChainID(ctx); err == nil {
+			manifest.L2ChainID = chainID.Uint64()
+		}
+	}
+	manifest.BlockNumber = head.Number.Uint64()
+	manifest.BlockHash = head.Hash().Hex()
+	manifest.StateRoot = head.Root.Hex()
+	manifest.GenesisHash = genesis.Hash().Hex()
+	return nil
+}
+
+// checkImportedHead waits until op-geth serves the snapshot block and checks its hash and the genesis hash
+func (t *ThanosStack) checkImportedHead(ctx context.Context, rpcURL string, manifest *types.ChainSnapshotManifest) error {
+	deadline := time.Now().Add(chainSnapshotHeadTimeout)
+	var lastErr error
+	for time.Now().Before(deadline) {
+		client, err := ethclient.DialContext(ctx, rpcURL)
+		if err == nil {
+			var block, genesis *ethTypes.Header
+			block, err = client.HeaderByNumber(ctx, new(big.Int).SetUint64(manifest.BlockNumber))
+			if err == nil {
+				genesis, err = client.HeaderByNumber(ctx, big.NewInt(0))
+			}
+			client.Close()
+			if err == nil {
+				if manifest.GenesisHash != "" && genesis.Hash().Hex() != manifest.GenesisHash {
+					return fmt.Errorf("op-geth genesis is %s, the snapshot genesis is %s", genesis.Hash().Hex(), manifest.GenesisHash)
+				}
+				if block.Hash().Hex() != manifest.BlockHash {
+					return fmt.Errorf("op-geth block #%d is %s, the snapshot has %s", manifest.BlockNumber, block.Hash().Hex(), manifest.BlockHash)
+				}
+				t.logger.Infof("✅ op-geth serves block #%d %s of the snapshot", manifest.BlockNumber, manifest.BlockHash)
+				return nil
+			}
+		}
+		lastErr = err
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+	return fmt.Errorf("op-geth did not serve block #%d of the snapshot within %s: %w", manifest.BlockNumber, chainSnapshotHeadTimeout, lastErr)
+}
+
+// archiveChainSnapshot streams a gzipped tarball of the datadir to the store and records its size and sha256
+func archiveChainSnapshot(ctx context.Context, store chainSnapshotStore, manifest *types.ChainSnapshotManifest, datadir chainSnapshotDatadir) error {
+	// The uncompressed size bounds the archive size, which the S3 upload needs to pick its part size
+	var expectedSize int64
+	if out, err := datadir.output(ctx, "du", "-sk", "/data"); err == nil {
+		if fields := strings.Fields(out); len(fields) > 0 {
+			if kb, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+				expectedSize = kb * 1024
+			}
+		}
+	}
+
+	w, err := store.create(ctx, manifest.File, expectedSize)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	var size byteCounter
+	args := []string{"tar", "czf", "-"}
+	for _, exclude := range chainSnapshotExcludes {
+		args = append(args, "--exclude="+exclude)
+	}
+	args = append(args, "-C", "/data", ".")
+	runErr := datadir.run(ctx, nil, io.MultiWriter(w, hash, &size), args...)
+	closeErr := w.Close()
+	if runErr != nil {
+		return fmt.Errorf("failed to archive the op-geth datadir of %s: %w", datadir.name, runErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to write %s: %w", store.path(manifest.File), closeErr)
+	}
+	manifest.Size, manifest.SHA256 = int64(size), hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+// extractChainSnapshot replaces the content of the datadir with the archive of the snapshot. The checksum is
+// computed while the archive streams in, and the datadir is emptied again when it does not match.
+func (t *ThanosStack) extractChainSnapshot(ctx context.Context, store chainSnapshotStore, manifest *types.ChainSnapshotManifest, datadir chainSnapshotDatadir) error {
+	r, err := store.open(ctx, manifest.File)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	t.logger.Infof("♻️  Seeding %s from %s (%s)...", datadir.name, store.path(manifest.File), formatBackupSize(manifest.Size))
+	hash := sha256.New()
+	var size byteCounter
+	tee := io.TeeReader(r, io.MultiWriter(hash, &size))
+	if err := datadir.run(ctx, tee, nil, "sh", "-c", "find /data -mindepth 1 -delete && tar xzf - -C /data"); err != nil {
+		return fmt.Errorf("failed to extract the snapshot into %s: %w", datadir.name, err)
+	}
+	// tar stops at the end of the archive, trailing padding still counts for the checksum
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		return fmt.Errorf("failed to read %s: %w", store.path(manifest.File), err)
+	}
+
+	if int64(size) != manifest.Size || hex.EncodeToString(hash.Sum(nil)) != manifest.SHA256 {
+		if err := datadir.run(context.WithoutCancel(ctx), nil, nil, "find", "/data", "-mindepth", "1", "-delete"); err != nil {
+			t.logger.Warnf("Failed to empty %s after the checksum mismatch: %v", datadir.name, err)
+		}
+		return fmt.Errorf("snapshot archive %s is corrupted: checksum mismatch", store.path(manifest.File))
+	}
+	return nil
+}
+
+func writeChainSnapshotManifest(ctx context.Context, store chainSnapshotStore, manifest *types.ChainSnapshotManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	w, err := store.create(ctx, chainSnapshotManifestName, int64(len(data)))
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write the snapshot manifest: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write the snapshot manifest: %w", err)
+	}
+	return nil
+}
+
+func readChainSnapshotManifest(ctx context.Context, store chainSnapshotStore) (*types.ChainSnapshotManifest, error) {
+	r, err := store.open(ctx, chainSnapshotManifestName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the snapshot manifest: %w", err)
+	}
+	data, err := io.ReadAll(r)
+	closeErr := r.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the snapshot manifest: %w", err)
+	}
+	var manifest types.ChainSnapshotManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid snapshot manifest %s: %w", store.path(chainSnapshotManifestName), err)
+	}
+	// The archive must be in the snapshot directory
+	if manifest.File == "" || filepath.Base(manifest.File) != manifest.File || manifest.File == ".." || manifest.SHA256 == "" {
+		return nil, fmt.Errorf("invalid snapshot manifest %s: missing or invalid archive", store.path(chainSnapshotManifestName))
+	}
+	return &manifest, nil
+}
+
+// chainSnapshotStore is a snapshot directory on the local filesystem or in an S3-compatible store
+type chainSnapshotStore struct {
+	location    string
+	endpointURL string
+}
+
+func isS3Location(location string) bool {
+	return strings.HasPrefix(location, "s3://")
+}
+
+func joinChainSnapshotLocation(destination, id string) string {
+	if isS3Location(destination) {
+		return strings.TrimSuffix(destination, "/") + "/" + id
+	}
+	return filepath.Join(destination, id)
+}
+
+func (s chainSnapshotStore) path(name string) string {
+	if isS3Location(s.location) {
+		return strings.TrimSuffix(s.location, "/") + "/" + name
+	}
+	return filepath.Join(s.location, name)
+}
+
+func (s chainSnapshotStore) s3Args(args ...string) []string {
+	args = append([]string{"s3"}, args...)
+	if s.endpointURL != "" {
+		args = append(args, "--endpoint-url", s.endpointURL)
+	}
+	return args
+}
+
+// create returns a writer of a new file of the snapshot. Files in S3 are streamed with aws s3 cp, which
+// needs the expected size of streams above 50 GB.
+func (s chainSnapshotStore) create(ctx context.Context, name string, expectedSize int64) (io.WriteCloser, error) {
+	if !isS3Location(s.location) {
+		if err := os.MkdirAll(s.location, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+		}
+		return os.OpenFile(s.path(name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	}
+	args := s.s3Args("cp", "--only-show-errors", "-", s.path(name))
+	if expectedSize > 0 {
+		args = append(args, "--expected-size", strconv.FormatInt(expectedSize, 10))
+	}
+	p := &commandPipe{cmd: exec.CommandContext(ctx, "aws", args...)}
+	p.cmd.Stderr = &p.stderr
+	stdin, err := p.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	p.stdin = stdin
+	if err := p.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to upload %s: %w", s.path(name), err)
+	}
+	return p, nil
+}
+
+// open returns a reader of a file of the snapshot
+func (s chainSnapshotStore) open(ctx context.Context, name string) (io.ReadCloser, error) {
+	if !isS3Location(s.location) {
+		return os.Open(s.path(name))
+	}
+	p := &commandPipe{cmd: exec.CommandContext(ctx, "aws", s.s3Args("cp", "--only-show-errors", s.path(name), "-")...)}
+	p.cmd.Stderr = &p.stderr
+	stdout, err := p.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	p.stdout = stdout
+	if err := p.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", s.path(name), err)
+	}
+	return p, nil
+}
+
+// commandPipe is the stdin or stdout of a running command. Close waits for the command to exit, and stops a
+// command whose output was not read to the end.
+type commandPipe struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	eof    bool
+	stderr bytes.Buffer
+}
+
+func (p *commandPipe) Write(b []byte) (int, error) {
+	return p.stdin.Write(b)
+}
+
+func (p *commandPipe) Read(b []byte) (int, error) {
+	n, err := p.stdout.Read(b)
+	if err == io.EOF {
+		p.eof = true
+	}
+	return n, err
+}
+
+func (p *commandPipe) Close() error {
+	if p.stdin != nil {
+		p.stdin.Close()
+	}
+	if p.stdout != nil && !p.eof {
+		_ = p.cmd.Process.Kill()
+		_ = p.cmd.Wait()
+		return nil
+	}
+	if err := p.cmd.Wait(); err != nil {
+		return fmt.Errorf("%s: %w", strings.TrimSpace(p.stderr.String()), err)
+	}
+	return nil
+}
+
+type byteCounter int64
+
+func (c *byteCounter) Write(b []byte) (int, error) {
+	*c += byteCounter(len(b))
+	return len(b), nil
+}
+
+// chainSnapshotDatadir runs commands in a container that has an op-geth datadir mounted at /data
+type chainSnapshotDatadir struct {
+	name    string
+	command []string
+}
+
+func dockerVolumeDatadir(volume string) chainSnapshotDatadir {
+	return chainSnapshotDatadir{
+		name:    "volume " + volume,
+		command: []string{"docker", "run", "--rm", "-i", "-v", volume + ":/data", "alpine"},
+	}
+}
+
+func (d chainSnapshotDatadir) run(ctx context.Context, stdin io.Reader, stdout io.Writer, args ...string) error {
+	command := append(append([]string{}, d.command...), args...)
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+	return nil
+}
+
+func (d chainSnapshotDatadir) output(ctx context.Context, args ...string) (string, error) {
+	var out bytes.Buffer
+	err := d.run(ctx, nil, &out, args...)
+	return out.String(), err
+}
+
+// chainSnapshotClaim is the persistent volume claim, and the sub path in it, of an op-geth datadir. Container
+// and MountPath locate the datadir in the op-geth pod.
+type chainSnapshotClaim struct {
+	Namespace string
+	Claim     string
+	SubPath   string
+	Container string
+	MountPath string
+}
+
+// opGethStatefulSets returns the op-geth and op-node StatefulSets of the namespace and the claim op-geth keeps
+// its datadir in
+func opGethStatefulSets(ctx context.Context, namespace string) (string, string, chainSnapshotClaim, error) {
+	stsList, err := kubectlList(ctx, namespace, "statefulsets")
+	if err != nil {
+		return "", "", chainSnapshotClaim{}, err
+	}
+	opGeth := findObjectByComponent(stsList, "op-geth")
+	opNode := findObjectByComponent(stsList, "op-node")
+	if opGeth == nil || opNode == nil {
+		return "", "", chainSnapshotClaim{}, fmt.Errorf("op-geth or op-node StatefulSet not found in namespace %s", namespace)
+	}
+	claim, err := opGethDataClaim(opGeth, namespace)
+	if err != nil {
+		return "", "", chainSnapshotClaim{}, err
+	}
+	opGethName, _ := objectName(opGeth)
+	opNodeName, _ := objectName(opNode)
+	return opGethName, opNodeName, claim, nil
+}
+
+// opGethDataClaim returns the claim of the first persistent volume mounted by the op-geth container, which
+// holds its datadir. Claim templates are resolved to the claim of the first replica.
+func opGethDataClaim(sts map[string]interface{}, namespace string) (chainSnapshotClaim, error) {
+	name, _ := objectName(sts)
+	podSpec := podSpecOf(sts)
+	if podSpec == nil {
+		return chainSnapshotClaim{}, fmt.Errorf("StatefulSet %s has no pod template", name)
+	}
+
+	claims := map[string]string{}
+	volumes, _ := podSpec["volumes"].([]interface{})
+	for _, v := range volumes {
+		volume, _ := v.(map[string]interface{})
+		pvc, _ := volume["persistentVolumeClaim"].(map[string]interface{})
+		volumeName, _ := volume["name"].(string)
+		if claimName, _ := pvc["claimName"].(string); claimName != "" && volumeName != "" {
+			claims[volumeName] = claimName
+		}
+	}
+	spec, _ := sts["spec"].(map[string]interface{})
+	templates, _ := spec["volumeClaimTemplates"].([]interface{})
+	for _, tpl := range templates {
+		if templateName, ok := objectName(tpl); ok {
+			claims[templateName] = fmt.Sprintf("%s-%s-0", templateName, name)
+		}
+	}
+
+	containers, _ := podSpec["containers"].([]interface{})
+	var container map[string]interface{}
+	for _, c := range containers {
+		candidate, _ := c.(map[string]interface{})
+		if containerName, _ := candidate["name"].(string); container == nil || strings.Contains(containerName, "op-geth") {
+			container = candidate
+		}
+	}
+	mounts, _ := container["volumeMounts"].([]interface{})
+	for _, m := range mounts {
+		mount, _ := m.(map[string]interface{})
+		volumeName, _ := mount["name"].(string)
+		if claim, ok := claims[volumeName]; ok {
+			subPath, _ := mount["subPath"].(string)
+			containerName, _ := container["name"].(string)
+			mountPath, _ := mount["mountPath"].(string)
+			return chainSnapshotClaim{Namespace: namespace, Claim: claim, SubPath: subPath, Container: containerName, MountPath: mountPath}, nil
+		}
+	}
+	return chainSnapshotClaim{}, fmt.Errorf("StatefulSet %s mounts no persistent volume", name)
+}
+
+// openClaimDatadir starts a helper pod with the claim mounted at /data. cleanup deletes the pod.
+func (t *ThanosStack) openClaimDatadir(ctx context.Context, claim chainSnapshotClaim) (chainSnapshotDatadir, func(), error) {
+	name := fmt.Sprintf("trh-snapshot-%d", time.Now().Unix())
+	dir, err := os.MkdirTemp("", "trh-snapshot-")
+	if err != nil {
+		return chainSnapshotDatadir{}, nil, err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "pod.json")
+	if err := writeManifest(path, chainSnapshotPod(name, claim)); err != nil {
+		return chainSnapshotDatadir{}, nil, err
+	}
+
+	cleanup := func() {
+		if _, err := utils.ExecuteCommand(context.WithoutCancel(ctx), "kubectl", "-n", claim.Namespace, "delete", "pod", name, "--ignore-not-found=true", "--wait=false"); err != nil {
+			t.logger.Warnf("Failed to delete pod %s, delete it with `kubectl -n %s delete pod %s`: %v", name, claim.Namespace, name, err)
+		}
+	}
+	if _, err := utils.ExecuteCommand(ctx, "kubectl", "apply", "-f", path); err != nil {
+		return chainSnapshotDatadir{}, nil, fmt.Errorf("failed to create pod %s: %w", name, err)
+	}
+	if _, err := utils.ExecuteCommand(ctx, "kubectl", "-n", claim.Namespace, "wait", "--for=condition=Ready", "pod/"+name, "--timeout="+chainSnapshotPodReadyTimeout); err != nil {
+		cleanup()
+		return chainSnapshotDatadir{}, nil, fmt.Errorf("pod %s with claim %s did not start, is the claim still in use?: %w", name, claim.Claim, err)
+	}
+	return chainSnapshotDatadir{
+		name:    "claim " + claim.Claim,
+		command: []string{"kubectl", "-n", claim.Namespace, "exec", "-i", name, "--"},
+	}, cleanup, nil
+}
+
+// chainSnapshotPod is a pod that mounts the claim at /data and idles until it is deleted
+func chainSnapshotPod(name string, claim chainSnapshotClaim) map[string]interface{} {
+	mount := map[string]interface{}{"name": "datadir", "mountPath": "/data"}
+	if claim.SubPath != "" {
+		mount["subPath"] = claim.SubPath
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": claim.Namespace,
+			"labels":    map[string]interface{}{chainSnapshotPodLabel: "true"},
+		},
+		"spec": map[string]interface{}{
+			"restartPolicy": "Never",
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name":         "snapshot",
+					"image":        "alpine",
+					"command":      []interface{}{"sleep", "infinity"},
+					"volumeMounts": []interface{}{mount},
+				},
+			},
+			"volumes": []interface{}{
+				map[string]interface{}{
+					"name":                  "datadir",
+					"persistentVolumeClaim": map[string]interface{}{"claimName": claim.Claim},
+				},
+			},
+		},
+	}
+}
+
+// scaleDownStatefulSet scales a StatefulSet to zero and waits until its pods are gone. restore scales it back
+// to its replicas and waits until they are ready, also when ctx was cancelled.
+func (t *ThanosStack) scaleDownStatefulSet(ctx context.Context, namespace, name string) (func(), error) {
+	out, err := utils.ExecuteCommand(ctx, "kubectl", "-n", namespace, "get", "statefulset", name, "-o", "jsonpath={.spec.replicas}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the replicas of StatefulSet %s: %w", name, err)
+	}
+	replicas := strings.TrimSpace(out)
+	if replicas == "" {
+		replicas = "1"
+	}
+	restore := func() {
+		ctx := context.WithoutCancel(ctx)
+		t.logger.Infof("▶️  Scaling StatefulSet %s back to %s replicas...", name, replicas)
+		if _, err := utils.ExecuteCommand(ctx, "kubectl", "-n", namespace, "scale", "statefulset", name, "--replicas="+replicas); err != nil {
+			t.logger.Errorf("Failed to scale StatefulSet %s back, run `kubectl -n %s scale statefulset %s --replicas=%s`: %v", name, namespace, name, replicas, err)
+			return
+		}
+		if _, err := utils.ExecuteCommand(ctx, "kubectl", "-n", namespace, "rollout", "status", "statefulset/"+name, "--timeout="+chainSnapshotRolloutTimeout); err != nil {
+			t.logger.Warnf("StatefulSet %s is not ready yet: %v", name, err)
+		}
+	}
+	if replicas == "0" {
+		return func() {}, nil
+	}
+
+	t.logger.Infof("⏸️  Scaling StatefulSet %s down...", name)
+	if _, err := utils.ExecuteCommand(ctx, "kubectl", "-n", namespace, "scale", "statefulset", name, "--replicas=0"); err != nil {
+		return nil, fmt.Errorf("failed to scale StatefulSet %s down: %w", name, err)
+	}
+	deadline := time.Now().Add(chainSnapshotScaleTimeout)
+	for {
+		out, err := utils.ExecuteCommand(ctx, "kubectl", "-n", namespace, "get", "statefulset", name, "-o", "jsonpath={.status.replicas}")
+		if err == nil && (strings.TrimSpace(out) == "" || strings.TrimSpace(out) == "0") {
+			return restore, nil
+		}
+		if time.Now().After(deadline) {
+			restore()
+			return nil, fmt.Errorf("pods of StatefulSet %s did not stop within %s", name, chainSnapshotScaleTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			restore()
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// stopLocalService stops the running containers of a compose service of the deployment and returns their names
+func (t *ThanosStack) stopLocalService(ctx context.Context, service string) ([]string, error) {
+	deploymentPath, err := filepath.Abs(t.deploymentPath)
+	if err != nil {
+		return nil, err
+	}
+	output, err := utils.ExecuteCommand(ctx, "docker", "ps",
+		"--filter", "label=com.docker.compose.project.working_dir="+deploymentPath,
+		"--filter", "label=com.docker.compose.service="+service,
+		"--format", "{{.Names}}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %s: %w", output, err)
+	}
+	containers := strings.Fields(output)
+	if len(containers) == 0 {
+		return nil, nil
+	}
+	t.logger.Infof("⏸️  Stopping %s...", service)
+	args := append([]string{"stop", "--time", strconv.Itoa(localBackupStopTimeout)}, containers...)
+	if output, err := utils.ExecuteCommand(ctx, "docker", args...); err != nil {
+		t.startLocalContainers(ctx, containers)
+		return nil, fmt.Errorf("failed to stop %s: %s: %w", service, output, err)
+	}
+	return containers, nil
+}
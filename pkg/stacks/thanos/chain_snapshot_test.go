@@ -0,0 +1,147 @@
+package thanos
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// shellDatadir runs the arguments of a datadir command as the positional parameters of script
+func shellDatadir(script string) chainSnapshotDatadir {
+	return chainSnapshotDatadir{name: "test datadir", command: []string{"sh", "-c", script, "sh"}}
+}
+
+func TestChainSnapshotLocations(t *testing.T) {
+	require.Equal(t, "s3://bucket/snapshots/20260501-100000", joinChainSnapshotLocation("s3://bucket/snapshots/", "20260501-100000"))
+	require.Equal(t, filepath.Join("backups", "20260501-100000"), joinChainSnapshotLocation("backups", "20260501-100000"))
+
+	s3 := chainSnapshotStore{location: "s3://bucket/snapshots/20260501-100000", endpointURL: "https://minio.example.com"}
+	require.Equal(t, "s3://bucket/snapshots/20260501-100000/manifest.json", s3.path(chainSnapshotManifestName))
+	require.Equal(t, []string{"s3", "cp", "-", "s3://x", "--endpoint-url", "https://minio.example.com"}, s3.s3Args("cp", "-", "s3://x"))
+	require.Equal(t, []string{"s3", "ls"}, chainSnapshotStore{location: "s3://bucket"}.s3Args("ls"))
+}
+
+func TestChainSnapshotArchiveAndExtract(t *testing.T) {
+	ctx := context.Background()
+	store := chainSnapshotStore{location: filepath.Join(t.TempDir(), "20260501-100000")}
+	manifest := &types.ChainSnapshotManifest{ID: "20260501-100000", CreatedAt: time.Now().UTC(), L2ChainID: 1001, File: ChainSnapshotArchiveName}
+
+	// The archive command gets the tar arguments with the node files excluded
+	require.NoError(t, archiveChainSnapshot(ctx, store, manifest, shellDatadir(`[ "$1" = du ] && echo "4 /data" && exit 0; printf '%s\n' "$@"`)))
+	data, err := os.ReadFile(store.path(ChainSnapshotArchiveName))
+	require.NoError(t, err)
+	require.Contains(t, string(data), "--exclude=./geth/nodekey\n")
+	require.Contains(t, string(data), "-C\n/data\n.\n")
+	sum := sha256.Sum256(data)
+	require.Equal(t, int64(len(data)), manifest.Size)
+	require.Equal(t, hex.EncodeToString(sum[:]), manifest.SHA256)
+
+	require.NoError(t, writeChainSnapshotManifest(ctx, store, manifest))
+	read, err := readChainSnapshotManifest(ctx, store)
+	require.NoError(t, err)
+	require.Equal(t, manifest.SHA256, read.SHA256)
+	// Snapshots are never overwritten
+	require.Error(t, writeChainSnapshotManifest(ctx, store, manifest))
+
+	stack := &ThanosStack{logger: zap.NewNop().Sugar()}
+	target := filepath.Join(t.TempDir(), "extracted")
+	// The extract command only reads part of the archive, the rest still counts for the checksum
+	require.NoError(t, stack.extractChainSnapshot(ctx, store, read, shellDatadir(`[ "$1" = find ] && exit 0; head -c 10 > `+target)))
+	extracted, err := os.ReadFile(target)
+	require.NoError(t, err)
+	require.Equal(t, data[:10], extracted)
+
+	wiped := filepath.Join(t.TempDir(), "wiped")
+	require.NoError(t, os.WriteFile(store.path(ChainSnapshotArchiveName), append(data, 'x'), 0600))
+	err = stack.extractChainSnapshot(ctx, store, read, shellDatadir(`[ "$1" = find ] && touch `+wiped+` && exit 0; cat > /dev/null`))
+	require.ErrorContains(t, err, "checksum mismatch")
+	require.FileExists(t, wiped, "the datadir is emptied after a checksum mismatch")
+}
+
+func TestReadChainSnapshotManifest(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := chainSnapshotStore{location: dir}
+
+	_, err := readChainSnapshotManifest(ctx, store)
+	require.ErrorContains(t, err, "failed to read the snapshot manifest")
+
+	for _, file := range []string{"", "../op-geth-datadir.tar.gz", "/etc/passwd"} {
+		data, err := json.Marshal(types.ChainSnapshotManifest{File: file, SHA256: "abc"})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, chainSnapshotManifestName), data, 0600))
+		_, err = readChainSnapshotManifest(ctx, store)
+		require.ErrorContains(t, err, "missing or invalid archive", file)
+	}
+
+	// Snapshots of another chain are refused before anything is touched
+	data, err := json.Marshal(types.ChainSnapshotManifest{ID: "other", L2ChainID: 2002, File: ChainSnapshotArchiveName, SHA256: "abc"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, chainSnapshotManifestName), data, 0600))
+	stack := &ThanosStack{logger: zap.NewNop().Sugar(), deploymentPath: t.TempDir(), deployConfig: &types.Config{L2ChainID: 1001}}
+	_, err = stack.ChainSnapshotImport(ctx, types.ChainSnapshotImportOptions{Source: dir})
+	require.ErrorContains(t, err, "belongs to chain ID 2002, the deployment is chain ID 1001")
+	_, err = stack.ChainSnapshotImport(ctx, types.ChainSnapshotImportOptions{Source: dir, Volume: "v", PVC: "p"})
+	require.ErrorContains(t, err, "cannot be used together")
+}
+
+func TestChainSnapshotDowntime(t *testing.T) {
+	require.Equal(t, 3*time.Minute, chainSnapshotDowntime(1<<30), "the restart dominates a small datadir")
+	require.Equal(t, 37*time.Minute, chainSnapshotDowntime(100<<30))
+}
+
+func TestOpGethDataClaim(t *testing.T) {
+	var sts map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "metadata": {"name": "thanos-stack-op-geth"},
+  "spec": {
+    "template": {"spec": {
+      "containers": [
+        {"name": "exporter", "volumeMounts": [{"name": "config", "mountPath": "/config"}]},
+        {"name": "op-geth", "volumeMounts": [
+          {"name": "config", "mountPath": "/config"},
+          {"name": "db", "mountPath": "/db", "subPath": "thanos-op-geth"}
+        ]}
+      ],
+      "volumes": [{"name": "config", "configMap": {"name": "op-geth-config"}}]
+    }},
+    "volumeClaimTemplates": [{"metadata": {"name": "db"}}]
+  }
+}`), &sts))
+
+	claim, err := opGethDataClaim(sts, "thanos")
+	require.NoError(t, err)
+	require.Equal(t, chainSnapshotClaim{Namespace: "thanos", Claim: "db-thanos-stack-op-geth-0", SubPath: "thanos-op-geth", Container: "op-geth", MountPath: "/db"}, claim)
+
+	// A claim mounted directly is used as is
+	podSpec := podSpecOf(sts)
+	podSpec["volumes"] = append(podSpec["volumes"].([]interface{}), map[string]interface{}{
+		"name": "db", "persistentVolumeClaim": map[string]interface{}{"claimName": "thanos-efs"},
+	})
+	delete(sts["spec"].(map[string]interface{}), "volumeClaimTemplates")
+	claim, err = opGethDataClaim(sts, "thanos")
+	require.NoError(t, err)
+	require.Equal(t, "thanos-efs", claim.Claim)
+
+	podSpec["volumes"] = []interface{}{}
+	_, err = opGethDataClaim(sts, "thanos")
+	require.ErrorContains(t, err, "mounts no persistent volume")
+
+	pod := chainSnapshotPod("trh-snapshot-1", chainSnapshotClaim{Namespace: "thanos", Claim: "thanos-efs", SubPath: "thanos-op-geth"})
+	spec := pod["spec"].(map[string]interface{})
+	mount := spec["containers"].([]interface{})[0].(map[string]interface{})["volumeMounts"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "/data", mount["mountPath"])
+	require.Equal(t, "thanos-op-geth", mount["subPath"])
+	volume := spec["volumes"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "thanos-efs", volume["persistentVolumeClaim"].(map[string]interface{})["claimName"])
+}
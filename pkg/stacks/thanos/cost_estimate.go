@@ -0,0 +1,880 @@
+package thanos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	_ "embed"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+//go:embed templates/aws-price-table.json
+var bundledAWSPriceTable []byte
+
+// CostPriceTableFileName is the per-deployment price table that overrides the bundled one when present
+const CostPriceTableFileName = "cost-price-table.json"
+
+// testnetResourceOverrides are the reduced Fargate requests applied to testnet chain pods.
+// Testnet runs on the same public infrastructure as mainnet, so it cannot
+// be torn down on-demand — explicit smaller requests are the correct lever.
+var testnetResourceOverrides = map[string]string{
+	"op_geth.resources.cpu":        "500m",
+	"op_geth.resources.memory":     "1Gi",
+	"op_node.resources.cpu":        "500m",
+	"op_node.resources.memory":     "1Gi",
+	"op_batcher.resources.cpu":     "250m",
+	"op_batcher.resources.memory":  "512Mi",
+	"op_proposer.resources.cpu":    "250m",
+	"op_proposer.resources.memory": "512Mi",
+	"redis.resources.cpu":          "250m",
+	"redis.resources.memory":       "512Mi",
+}
+
+type awsRegionPrices struct {
+	EKSClusterHourly         float64            `json:"eks_cluster_hourly"`
+	FargateVCPUHourly        float64            `json:"fargate_vcpu_hourly"`
+	FargateGBHourly          float64            `json:"fargate_gb_hourly"`
+	EC2InstanceHourly        map[string]float64 `json:"ec2_instance_hourly"`
+	EBSGp3GBMonth            float64            `json:"ebs_gp3_gb_month"`
+	EFSStandardGBMonth       float64            `json:"efs_standard_gb_month"`
+	EFSProvisionedMiBpsMonth float64            `json:"efs_provisioned_mibps_month"`
+	EFSElasticTransferGB     float64            `json:"efs_elastic_transfer_gb"`
+	RDSInstanceHourly        map[string]float64 `json:"rds_instance_hourly"`
+	RDSStorageGBMonth        float64            `json:"rds_storage_gb_month"`
+	RDSBackupGBMonth         float64            `json:"rds_backup_gb_month"`
+	NATGatewayHourly         float64            `json:"nat_gateway_hourly"`
+	NATGatewayGB             float64            `json:"nat_gateway_gb"`
+	ALBHourly                float64            `json:"alb_hourly"`
+	ALBLCUHourly             float64            `json:"alb_lcu_hourly"`
+	CloudWatchIngestGB       float64            `json:"cloudwatch_ingest_gb"`
+	CloudWatchStorageGBMonth float64            `json:"cloudwatch_storage_gb_month"`
+	BackupEFSWarmGBMonth     float64            `json:"backup_efs_warm_gb_month"`
+}
+
+type costAssumptions struct {
+	EFSDataGB                  float64 `json:"efs_data_gb"`
+	EFSElasticTransferGBMonth  float64 `json:"efs_elastic_transfer_gb_month"`
+	NATDataGBMonth             float64 `json:"nat_data_gb_month"`
+	ALBLCU                     float64 `json:"alb_lcu"`
+	LogIngestGBPerDay          float64 `json:"log_ingest_gb_per_day"`
+	DefaultLogRetentionDays    int     `json:"default_log_retention_days"`
+	DefaultBackupRetentionDays int     `json:"default_backup_retention_days"`
+	BackupDailyChangeRatio     float64 `json:"backup_daily_change_ratio"`
+	RDSStorageGB               float64 `json:"rds_storage_gb"`
+}
+
+type costPodSpec struct {
+	VCPU     float64 `json:"vcpu"`
+	MemoryGB float64 `json:"memory_gb"`
+	Replicas int     `json:"replicas,omitempty"`
+}
+
+type costModuleSpec struct {
+	Pods             map[string]costPodSpec `json:"pods"`
+	LoadBalancers    int                    `json:"load_balancers"`
+	RDSInstanceClass string                 `json:"rds_instance_class,omitempty"`
+	CloudWatch       bool                   `json:"cloudwatch,omitempty"`
+}
+
+type costInfrastructure struct {
+	EKSClusters         int     `json:"eks_clusters"`
+	NATGateways         int     `json:"nat_gateways"`
+	LoadBalancers       int     `json:"load_balancers"`
+	EFSThroughputMode   string  `json:"efs_throughput_mode"`
+	EFSProvisionedMiBps float64 `json:"efs_provisioned_mibps"`
+}
+
+type awsPriceTable struct {
+	Version               string                     `json:"version"`
+	Currency              string                     `json:"currency"`
+	HoursPerMonth         float64                    `json:"hours_per_month"`
+	Regions               map[string]awsRegionPrices `json:"regions"`
+	DefaultRegion         string                     `json:"default_region"`
+	Assumptions           costAssumptions            `json:"assumptions"`
+	DefaultInfrastructure costInfrastructure         `json:"default_infrastructure"`
+	DefaultChainPods      map[string]costPodSpec     `json:"default_chain_pods"`
+	ChainLoadBalancers    int                        `json:"chain_load_balancers"`
+	Modules               map[string]costModuleSpec  `json:"modules"`
+}
+
+// regionPrices returns the prices for the region, falling back to the default region
+func (p *awsPriceTable) regionPrices(region string) (awsRegionPrices, string) {
+	if prices, ok := p.Regions[region]; ok {
+		return prices, region
+	}
+	return p.Regions[p.DefaultRegion], p.DefaultRegion
+}
+
+// loadAWSPriceTable loads the price table from path, the deployment override, or the bundled copy
+func loadAWSPriceTable(deploymentPath, path string) (*awsPriceTable, string, error) {
+	data := bundledAWSPriceTable
+	source := "bundled price table"
+	if path == "" {
+		override := filepath.Join(deploymentPath, CostPriceTableFileName)
+		if utils.CheckFileExists(override) {
+			path = override
+		}
+	}
+	if path != "" {
+		fileData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read price table %s: %w", path, err)
+		}
+		data = fileData
+		source = path
+	}
+
+	var table awsPriceTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, "", fmt.Errorf("failed to parse price table %s: %w", source, err)
+	}
+	if len(table.Regions) == 0 {
+		return nil, "", fmt.Errorf("price table %s has no regions", source)
+	}
+	if _, ok := table.Regions[table.DefaultRegion]; !ok {
+		return nil, "", fmt.Errorf("price table %s default region %q is not defined", source, table.DefaultRegion)
+	}
+	if table.HoursPerMonth <= 0 {
+		table.HoursPerMonth = 730
+	}
+	return &table, source, nil
+}
+
+// ExportPriceTable writes the bundled price table to path so it can be edited and passed back via --price-table
+func ExportPriceTable(path string) error {
+	if err := os.WriteFile(path, bundledAWSPriceTable, 0644); err != nil {
+		return fmt.Errorf("failed to write price table: %w", err)
+	}
+	return nil
+}
+
+// terraformPlanJSON is the subset of `terraform show -json` output used for cost estimation
+type terraformPlanJSON struct {
+	PlannedValues struct {
+		RootModule terraformPlanModule `json:"root_module"`
+	} `json:"planned_values"`
+}
+
+type terraformPlanModule struct {
+	Resources    []terraformPlanResource `json:"resources"`
+	ChildModules []terraformPlanModule   `json:"child_modules"`
+}
+
+type terraformPlanResource struct {
+	Address string                 `json:"address"`
+	Mode    string                 `json:"mode"`
+	Type    string                 `json:"type"`
+	Values  map[string]interface{} `json:"values"`
+}
+
+// plannedResources flattens all managed resources of the plan, including child modules
+func (p *terraformPlanJSON) plannedResources() []terraformPlanResource {
+	var resources []terraformPlanResource
+	var walk func(m terraformPlanModule)
+	walk = func(m terraformPlanModule) {
+		for _, r := range m.Resources {
+			if r.Mode == "" || r.Mode == "managed" {
+				resources = append(resources, r)
+			}
+		}
+		for _, child := range m.ChildModules {
+			walk(child)
+		}
+	}
+	walk(p.PlannedValues.RootModule)
+	return resources
+}
+
+// plannedNodeGroup represents an EKS managed node group found in the plan
+type plannedNodeGroup struct {
+	Name         string
+	InstanceType string
+	DesiredSize  int
+	DiskSizeGB   float64
+}
+
+// plannedRDSInstance represents an RDS instance found in the plan
+type plannedRDSInstance struct {
+	Name             string
+	InstanceClass    string
+	AllocatedStorage float64
+	MultiAZ          bool
+	BackupRetention  int
+}
+
+// plannedInfrastructure is the cost-relevant infrastructure extracted from a terraform plan
+type plannedInfrastructure struct {
+	costInfrastructure
+	NodeGroups           []plannedNodeGroup
+	RDSInstances         []plannedRDSInstance
+	LogRetentionDays     int
+	BackupRetentionDays  int
+	HasEFS               bool
+	HasBackupPlan        bool
+	CloudWatchLogGroups  int
+	FromTerraformPlan    bool
+	TerraformPlanSources []string
+}
+
+// readTerraformPlan reads a plan file, converting binary plans with `terraform show -json`
+func readTerraformPlan(ctx context.Context, planPath string) (*terraformPlanJSON, error) {
+	data, err := os.ReadFile(planPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terraform plan: %w", err)
+	}
+
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		planDir := filepath.Dir(planPath)
+		planFile := filepath.Base(planPath)
+		var output string
+		if utils.CheckFileExists(filepath.Join(planDir, "..", ".envrc")) {
+			// The plan file is passed as a positional argument so paths with spaces survive the shell
+			output, err = utils.ExecuteCommandInDir(ctx, planDir, "bash", "-c",
+				`source ../.envrc && terraform show -json "$1"`, "bash", planFile)
+		} else {
+			output, err = utils.ExecuteCommandInDir(ctx, planDir, "terraform", "show", "-json", planFile)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert terraform plan to JSON: %w", err)
+		}
+		data = []byte(output)
+	}
+
+	var plan terraformPlanJSON
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse terraform plan JSON: %w", err)
+	}
+	return &plan, nil
+}
+
+// extractPlannedInfrastructure walks the plan and collects the resources the estimator prices
+func extractPlannedInfrastructure(plan *terraformPlanJSON) *plannedInfrastructure {
+	infra := &plannedInfrastructure{FromTerraformPlan: true}
+	for _, r := range plan.plannedResources() {
+		switch r.Type {
+		case "aws_eks_cluster":
+			infra.EKSClusters++
+		case "aws_eks_node_group":
+			ng := plannedNodeGroup{Name: r.Address, DesiredSize: 1, DiskSizeGB: 20}
+			if instanceTypes := planStringList(r.Values["instance_types"]); len(instanceTypes) > 0 {
+				ng.InstanceType = instanceTypes[0]
+			}
+			if scaling := planFirstBlock(r.Values["scaling_config"]); scaling != nil {
+				if size, ok := planNumber(scaling["desired_size"]); ok {
+					ng.DesiredSize = int(size)
+				}
+			}
+			if disk, ok := planNumber(r.Values["disk_size"]); ok && disk > 0 {
+				ng.DiskSizeGB = disk
+			}
+			infra.NodeGroups = append(infra.NodeGroups, ng)
+		case "aws_efs_file_system":
+			infra.HasEFS = true
+			if mode, ok := r.Values["throughput_mode"].(string); ok && mode != "" {
+				infra.EFSThroughputMode = mode
+			}
+			if mibps, ok := planNumber(r.Values["provisioned_throughput_in_mibps"]); ok {
+				infra.EFSProvisionedMiBps += mibps
+			}
+		case "aws_db_instance":
+			db := plannedRDSInstance{Name: r.Address}
+			db.InstanceClass, _ = r.Values["instance_class"].(string)
+			if storage, ok := planNumber(r.Values["allocated_storage"]); ok {
+				db.AllocatedStorage = storage
+			}
+			db.MultiAZ, _ = r.Values["multi_az"].(bool)
+			if retention, ok := planNumber(r.Values["backup_retention_period"]); ok {
+				db.BackupRetention = int(retention)
+			}
+			infra.RDSInstances = append(infra.RDSInstances, db)
+		case "aws_nat_gateway":
+			infra.NATGateways++
+		case "aws_lb", "aws_alb":
+			infra.LoadBalancers++
+		case "aws_cloudwatch_log_group":
+			infra.CloudWatchLogGroups++
+			if days, ok := planNumber(r.Values["retention_in_days"]); ok && int(days) > infra.LogRetentionDays {
+				infra.LogRetentionDays = int(days)
+			}
+		case "aws_backup_plan":
+			infra.HasBackupPlan = true
+			for _, rule := range planBlocks(r.Values["rule"]) {
+				lifecycle := planFirstBlock(rule["lifecycle"])
+				if lifecycle == nil {
+					continue
+				}
+				if days, ok := planNumber(lifecycle["delete_after"]); ok && int(days) > infra.BackupRetentionDays {
+					infra.BackupRetentionDays = int(days)
+				}
+			}
+		}
+	}
+	return infra
+}
+
+func planNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func planStringList(v interface{}) []string {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func planBlocks(v interface{}) []map[string]interface{} {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	var out []map[string]interface{}
+	for _, item := range items {
+		if block, ok := item.(map[string]interface{}); ok {
+			out = append(out, block)
+		}
+	}
+	return out
+}
+
+func planFirstBlock(v interface{}) map[string]interface{} {
+	blocks := planBlocks(v)
+	if len(blocks) == 0 {
+		return nil
+	}
+	return blocks[0]
+}
+
+// readHelmValuePods reads Fargate pod sizes from the thanos-stack Helm values file.
+// Every top-level component with resources.cpu and resources.memory is treated as one pod.
+func readHelmValuePods(valuesPath string, overrides map[string]string) (map[string]costPodSpec, error) {
+	data, err := os.ReadFile(valuesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Helm values: %w", err)
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse Helm values: %w", err)
+	}
+	for field, value := range overrides {
+		if err := setNestedValue(values, strings.Split(field, "."), value); err != nil {
+			return nil, err
+		}
+	}
+
+	pods := make(map[string]costPodSpec)
+	for component, raw := range values {
+		section, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if enabled, ok := section["enabled"].(bool); ok && !enabled {
+			continue
+		}
+		resources, ok := section["resources"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		vcpu, cpuErr := parseCPUQuantity(fmt.Sprint(resources["cpu"]))
+		memory, memErr := parseMemoryQuantityGB(fmt.Sprint(resources["memory"]))
+		if cpuErr != nil || memErr != nil {
+			continue
+		}
+		replicas := 1
+		for _, key := range []string{"replicas", "replicaCount"} {
+			if n, ok := planNumber(section[key]); ok && n > 0 {
+				replicas = int(n)
+			}
+		}
+		pods[component] = costPodSpec{VCPU: vcpu, MemoryGB: memory, Replicas: replicas}
+	}
+	return pods, nil
+}
+
+// setNestedValue sets a dotted field path inside a generic YAML map
+func setNestedValue(data map[string]interface{}, keys []string, value interface{}) error {
+	current := data
+	for i, key := range keys {
+		if i == len(keys)-1 {
+			current[key] = value
+			return nil
+		}
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[key] = next
+		}
+		current = next
+	}
+	return fmt.Errorf("invalid field path")
+}
+
+// parseCPUQuantity converts a Kubernetes CPU quantity ("500m", "1", "2.5") to vCPUs
+func parseCPUQuantity(q string) (float64, error) {
+	q = strings.TrimSpace(q)
+	if strings.HasSuffix(q, "m") {
+		milli, err := strconv.ParseFloat(strings.TrimSuffix(q, "m"), 64)
+		if err != nil {
+			return 0, err
+		}
+		return milli / 1000, nil
+	}
+	return strconv.ParseFloat(q, 64)
+}
+
+// parseMemoryQuantityGB converts a Kubernetes memory quantity ("512Mi", "1Gi", "2G") to GB
+func parseMemoryQuantityGB(q string) (float64, error) {
+	q = strings.TrimSpace(q)
+	units := []struct {
+		suffix string
+		factor float64
+	}{
+		{"Ki", 1.0 / (1024 * 1024)}, {"Mi", 1.0 / 1024}, {"Gi", 1}, {"Ti", 1024},
+		{"K", 1.0 / (1000 * 1000)}, {"M", 1.0 / 1000}, {"G", 1}, {"T", 1000},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(q, u.suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSuffix(q, u.suffix), 64)
+			if err != nil {
+				return 0, err
+			}
+			return n * u.factor, nil
+		}
+	}
+	bytesValue, err := strconv.ParseFloat(q, 64)
+	if err != nil {
+		return 0, err
+	}
+	return bytesValue / (1024 * 1024 * 1024), nil
+}
+
+// fargateConfigurations lists the supported vCPU sizes with the memory sizes in GB Fargate accepts for each
+var fargateConfigurations = []struct {
+	vcpu     float64
+	memories []float64
+}{
+	{0.25, []float64{0.5, 1, 2}},
+	{0.5, fargateMemoryRange(1, 4, 1)},
+	{1, fargateMemoryRange(2, 8, 1)},
+	{2, fargateMemoryRange(4, 16, 1)},
+	{4, fargateMemoryRange(8, 30, 1)},
+	{8, fargateMemoryRange(16, 60, 4)},
+	{16, fargateMemoryRange(32, 120, 8)},
+}
+
+// fargateMemoryRange returns the memory sizes from min to max in increments of step
+func fargateMemoryRange(min, max, step float64) []float64 {
+	var memories []float64
+	for m := min; m <= max; m += step {
+		memories = append(memories, m)
+	}
+	return memories
+}
+
+// fargatePodSize rounds a pod request up to the Fargate configuration that is billed.
+// Fargate adds 256MB to the memory request for the Kubernetes components it runs per pod.
+func fargatePodSize(vcpu, memoryGB float64) (float64, float64) {
+	memoryGB += 0.25
+	for _, c := range fargateConfigurations {
+		if vcpu > c.vcpu {
+			continue
+		}
+		for _, memory := range c.memories {
+			if memory >= memoryGB {
+				return c.vcpu, memory
+			}
+		}
+	}
+	last := fargateConfigurations[len(fargateConfigurations)-1]
+	return last.vcpu, last.memories[len(last.memories)-1]
+}
+
+// costCalculator accumulates line items for one preset
+type costCalculator struct {
+	table    *awsPriceTable
+	prices   awsRegionPrices
+	estimate *types.CostEstimate
+}
+
+func (c *costCalculator) add(category, resource, detail string, quantity float64, unit string, monthly float64) {
+	c.estimate.Items = append(c.estimate.Items, types.CostLineItem{
+		Category:   category,
+		Resource:   resource,
+		Detail:     detail,
+		Quantity:   quantity,
+		Unit:       unit,
+		MonthlyUSD: math.Round(monthly*100) / 100,
+	})
+}
+
+func (c *costCalculator) addFargatePod(category, name string, pod costPodSpec) {
+	replicas := pod.Replicas
+	if replicas <= 0 {
+		replicas = 1
+	}
+	vcpu, memory := fargatePodSize(pod.VCPU, pod.MemoryGB)
+	hourly := vcpu*c.prices.FargateVCPUHourly + memory*c.prices.FargateGBHourly
+	c.add(category, name, fmt.Sprintf("Fargate %.2g vCPU / %.2g GB", vcpu, memory),
+		float64(replicas), "pod", hourly*c.table.HoursPerMonth*float64(replicas))
+}
+
+func (c *costCalculator) addRDS(category, name, instanceClass string, storageGB float64, multiAZ bool, backupDays int) {
+	hourly, ok := c.prices.RDSInstanceHourly[instanceClass]
+	detail := instanceClass
+	if !ok {
+		detail = fmt.Sprintf("%s (not in price table)", instanceClass)
+	}
+	if multiAZ {
+		hourly *= 2
+		detail += ", Multi-AZ"
+	}
+	c.add(category, name, detail, 1, "instance", hourly*c.table.HoursPerMonth)
+	if storageGB <= 0 {
+		storageGB = c.table.Assumptions.RDSStorageGB
+	}
+	storageFactor := 1.0
+	if multiAZ {
+		storageFactor = 2
+	}
+	c.add(category, name+" storage", "gp3", storageGB, "GB-month", storageGB*storageFactor*c.prices.RDSStorageGBMonth)
+	// Automated backups up to the size of the database are free; beyond that each retained day adds a delta
+	if backupDays > 1 {
+		backupGB := storageGB * c.table.Assumptions.BackupDailyChangeRatio * float64(backupDays-1)
+		c.add(category, name+" backups", fmt.Sprintf("%d days retention", backupDays), backupGB, "GB-month", backupGB*c.prices.RDSBackupGBMonth)
+	}
+}
+
+// estimatePresetCost prices the planned infrastructure, chain pods and the preset's modules
+func estimatePresetCost(
+	table *awsPriceTable,
+	region, network, preset string,
+	infra *plannedInfrastructure,
+	chainPods map[string]costPodSpec,
+	loggingConfig *types.LoggingConfig,
+) *types.CostEstimate {
+	prices, pricedRegion := table.regionPrices(region)
+	calc := &costCalculator{
+		table:  table,
+		prices: prices,
+		estimate: &types.CostEstimate{
+			Region:            pricedRegion,
+			Network:           network,
+			Preset:            preset,
+			PriceTableVersion: table.Version,
+		},
+	}
+	hours := table.HoursPerMonth
+	assumptions := table.Assumptions
+
+	// Kubernetes control plane and worker nodes
+	if infra.EKSClusters > 0 {
+		calc.add("compute", "EKS control plane", "", float64(infra.EKSClusters), "cluster",
+			float64(infra.EKSClusters)*prices.EKSClusterHourly*hours)
+	}
+	for _, ng := range infra.NodeGroups {
+		hourly, ok := prices.EC2InstanceHourly[ng.InstanceType]
+		detail := ng.InstanceType
+		if !ok {
+			detail = fmt.Sprintf("%s (not in price table)", ng.InstanceType)
+		}
+		calc.add("compute", ng.Name, detail, float64(ng.DesiredSize), "node", hourly*hours*float64(ng.DesiredSize))
+		calc.add("storage", ng.Name+" volumes", "gp3", ng.DiskSizeGB*float64(ng.DesiredSize), "GB-month",
+			ng.DiskSizeGB*float64(ng.DesiredSize)*prices.EBSGp3GBMonth)
+	}
+	for _, name := range sortedPodNames(chainPods) {
+		calc.addFargatePod("compute", name, chainPods[name])
+	}
+
+	// Chain data on EFS
+	calc.add("storage", "EFS chain data", "Standard", assumptions.EFSDataGB, "GB-month", assumptions.EFSDataGB*prices.EFSStandardGBMonth)
+	switch infra.EFSThroughputMode {
+	case "provisioned":
+		calc.add("storage", "EFS provisioned throughput", "", infra.EFSProvisionedMiBps, "MiBps-month",
+			infra.EFSProvisionedMiBps*prices.EFSProvisionedMiBpsMonth)
+	case "elastic":
+		calc.add("storage", "EFS elastic throughput", "estimated transfer", assumptions.EFSElasticTransferGBMonth, "GB",
+			assumptions.EFSElasticTransferGBMonth*prices.EFSElasticTransferGB)
+	}
+
+	// Networking
+	if infra.NATGateways > 0 {
+		calc.add("network", "NAT gateway", "", float64(infra.NATGateways), "gateway", float64(infra.NATGateways)*prices.NATGatewayHourly*hours)
+		calc.add("network", "NAT gateway data", "estimated processing", assumptions.NATDataGBMonth, "GB", assumptions.NATDataGBMonth*prices.NATGatewayGB)
+	}
+
+	modules := constants.PresetModules[preset]
+	loadBalancers := infra.LoadBalancers + table.ChainLoadBalancers
+	shipsLogs := false
+	for _, name := range sortedModuleNames(modules) {
+		module, ok := table.Modules[name]
+		if !ok {
+			continue
+		}
+		for _, podName := range sortedPodNames(module.Pods) {
+			calc.addFargatePod("modules", fmt.Sprintf("%s/%s", name, podName), module.Pods[podName])
+		}
+		loadBalancers += module.LoadBalancers
+		shipsLogs = shipsLogs || module.CloudWatch
+		if module.RDSInstanceClass != "" && len(infra.RDSInstances) == 0 {
+			calc.addRDS("modules", name+" database", module.RDSInstanceClass, 0, false, 1)
+		}
+	}
+	for _, db := range infra.RDSInstances {
+		calc.addRDS("database", db.Name, db.InstanceClass, db.AllocatedStorage, db.MultiAZ, db.BackupRetention)
+	}
+	if loadBalancers > 0 {
+		calc.add("network", "Application load balancers", "ingress", float64(loadBalancers), "ALB",
+			float64(loadBalancers)*(prices.ALBHourly+assumptions.ALBLCU*prices.ALBLCUHourly)*hours)
+	}
+
+	// CloudWatch logs: the monitoring module ships component logs when logging is enabled
+	loggingEnabled := shipsLogs && (loggingConfig == nil || loggingConfig.Enabled)
+	if loggingEnabled || infra.CloudWatchLogGroups > 0 {
+		retention := assumptions.DefaultLogRetentionDays
+		if infra.LogRetentionDays > 0 {
+			retention = infra.LogRetentionDays
+		}
+		if loggingConfig != nil && loggingConfig.CloudWatchRetention > 0 {
+			retention = loggingConfig.CloudWatchRetention
+		}
+		ingestGB := assumptions.LogIngestGBPerDay * 30
+		storedGB := assumptions.LogIngestGBPerDay * float64(retention)
+		calc.add("observability", "CloudWatch log ingestion", "", ingestGB, "GB", ingestGB*prices.CloudWatchIngestGB)
+		calc.add("observability", "CloudWatch log storage", fmt.Sprintf("%d days retention", retention), storedGB, "GB-month",
+			storedGB*prices.CloudWatchStorageGBMonth)
+	}
+
+	// AWS Backup of EFS: one full copy plus daily incremental changes for the retention window
+	if infra.HasEFS || infra.HasBackupPlan || !infra.FromTerraformPlan {
+		retention := infra.BackupRetentionDays
+		detail := fmt.Sprintf("%d days retention", retention)
+		if retention <= 0 {
+			retention = assumptions.DefaultBackupRetentionDays
+			detail = fmt.Sprintf("unlimited retention, priced at %d days", retention)
+		}
+		backupGB := assumptions.EFSDataGB * (1 + assumptions.BackupDailyChangeRatio*float64(retention))
+		calc.add("backup", "EFS recovery points", detail, backupGB, "GB-month", backupGB*prices.BackupEFSWarmGBMonth)
+	}
+
+	total := 0.0
+	for _, item := range calc.estimate.Items {
+		total += item.MonthlyUSD
+	}
+	calc.estimate.TotalMonthlyUSD = math.Round(total*100) / 100
+	return calc.estimate
+}
+
+func sortedPodNames(pods map[string]costPodSpec) []string {
+	names := make([]string, 0, len(pods))
+	for name := range pods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedModuleNames(modules map[string]bool) []string {
+	names := make([]string, 0, len(modules))
+	for name, enabled := range modules {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readTerraformBackupRetention reads TF_VAR_backup_delete_after_days from the generated .envrc
+func readTerraformBackupRetention(envrcPath string) int {
+	data, err := os.ReadFile(envrcPath)
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "export TF_VAR_backup_delete_after_days=") {
+			continue
+		}
+		value := strings.Trim(strings.TrimPrefix(line, "export TF_VAR_backup_delete_after_days="), "\"")
+		days, err := strconv.Atoi(value)
+		if err == nil {
+			return days
+		}
+	}
+	return 0
+}
+
+// EstimateCost produces a monthly AWS cost breakdown for the planned deployment and compares presets
+func (t *ThanosStack) EstimateCost(ctx context.Context, input *types.CostEstimateInput) (*types.CostReport, error) {
+	if input == nil {
+		input = &types.CostEstimateInput{}
+	}
+
+	table, tableSource, err := loadAWSPriceTable(t.deploymentPath, input.PriceTablePath)
+	if err != nil {
+		return nil, err
+	}
+	sources := []string{tableSource}
+
+	terraformDir := filepath.Join(t.deploymentPath, "tokamak-thanos-stack", "terraform")
+	planPath := input.PlanPath
+	if planPath == "" {
+		defaultPlan := filepath.Join(terraformDir, "thanos-stack", "tfplan")
+		if utils.CheckFileExists(defaultPlan) {
+			planPath = defaultPlan
+		}
+	}
+
+	var infra *plannedInfrastructure
+	if planPath != "" {
+		plan, err := readTerraformPlan(ctx, planPath)
+		if err != nil {
+			return nil, err
+		}
+		infra = extractPlannedInfrastructure(plan)
+		sources = append(sources, planPath)
+	} else {
+		t.logger.Warn("No terraform plan found, using the default infrastructure profile from the price table")
+		infra = &plannedInfrastructure{costInfrastructure: table.DefaultInfrastructure}
+		sources = append(sources, "default infrastructure profile")
+	}
+	if infra.BackupRetentionDays == 0 {
+		infra.BackupRetentionDays = readTerraformBackupRetention(filepath.Join(terraformDir, ".envrc"))
+	}
+
+	var (
+		network       = t.network
+		region        string
+		preset        = input.Preset
+		loggingConfig *types.LoggingConfig
+	)
+	if t.deployConfig != nil {
+		if network == "" {
+			network = t.deployConfig.Network
+		}
+		if preset == "" {
+			preset = t.deployConfig.Preset
+		}
+		if t.deployConfig.AWS != nil {
+			region = t.deployConfig.AWS.Region
+		}
+		loggingConfig = t.deployConfig.LoggingConfig
+	}
+	if preset == "" {
+		preset = constants.PresetGeneral
+	}
+	if _, ok := constants.PresetModules[preset]; !ok {
+		return nil, fmt.Errorf("unknown preset %q (allowed: %s)", preset, strings.Join(constants.ValidPresets, ", "))
+	}
+
+	overrides := map[string]string{}
+	if network == constants.Testnet {
+		overrides = testnetResourceOverrides
+	}
+	valuesPath := input.ValuesPath
+	if valuesPath == "" {
+		defaultValues := filepath.Join(terraformDir, "thanos-stack", "thanos-stack-values.yaml")
+		if utils.CheckFileExists(defaultValues) {
+			valuesPath = defaultValues
+		}
+	}
+	chainPods := table.DefaultChainPods
+	if valuesPath != "" {
+		pods, err := readHelmValuePods(valuesPath, overrides)
+		if err != nil {
+			return nil, err
+		}
+		if len(pods) > 0 {
+			chainPods = pods
+			sources = append(sources, valuesPath)
+		}
+	}
+
+	estimate := estimatePresetCost(table, region, network, preset, infra, chainPods, loggingConfig)
+	estimate.Sources = sources
+	if region != "" && estimate.Region != region {
+		t.logger.Warnf("Region %s is not in the price table, using %s prices", region, estimate.Region)
+	}
+
+	report := &types.CostReport{Estimate: estimate}
+	for _, other := range input.ComparePresets {
+		if _, ok := constants.PresetModules[other]; !ok {
+			return nil, fmt.Errorf("unknown preset %q (allowed: %s)", other, strings.Join(constants.ValidPresets, ", "))
+		}
+		otherEstimate := estimatePresetCost(table, region, network, other, infra, chainPods, loggingConfig)
+		report.Comparisons = append(report.Comparisons, types.PresetCostComparison{
+			Preset:          other,
+			TotalMonthlyUSD: otherEstimate.TotalMonthlyUSD,
+			DeltaUSD:        math.Round((otherEstimate.TotalMonthlyUSD-estimate.TotalMonthlyUSD)*100) / 100,
+		})
+	}
+	return report, nil
+}
+
+// DisplayCostReport prints the cost breakdown grouped by category followed by the preset comparison
+func DisplayCostReport(report *types.CostReport) {
+	estimate := report.Estimate
+	fmt.Printf("\n💰 Estimated monthly AWS cost (preset: %s, network: %s, region: %s)\n", estimate.Preset, estimate.Network, estimate.Region)
+	fmt.Printf("   Price table: %s\n", estimate.PriceTableVersion)
+	fmt.Printf("   Sources: %s\n\n", strings.Join(estimate.Sources, ", "))
+
+	categoryTotals := make(map[string]float64)
+	var categories []string
+	for _, item := range estimate.Items {
+		if _, ok := categoryTotals[item.Category]; !ok {
+			categories = append(categories, item.Category)
+		}
+		categoryTotals[item.Category] += item.MonthlyUSD
+	}
+
+	for _, category := range categories {
+		fmt.Printf("%s ($%.2f)\n", strings.ToUpper(category), categoryTotals[category])
+		for _, item := range estimate.Items {
+			if item.Category != category {
+				continue
+			}
+			detail := ""
+			if item.Detail != "" {
+				detail = fmt.Sprintf(" [%s]", item.Detail)
+			}
+			fmt.Printf("   %-45s %10.2f %-10s $%9.2f%s\n", item.Resource, item.Quantity, item.Unit, item.MonthlyUSD, detail)
+		}
+	}
+	fmt.Printf("\nTOTAL: $%.2f / month\n", estimate.TotalMonthlyUSD)
+
+	if len(report.Comparisons) == 0 {
+		return
+	}
+	fmt.Println("\n📊 Preset comparison")
+	for _, c := range report.Comparisons {
+		fmt.Printf("   %-10s $%9.2f / month (%+.2f)\n", c.Preset, c.TotalMonthlyUSD, c.DeltaUSD)
+	}
+}
@@ -0,0 +1,175 @@
+package thanos
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+const testTerraformPlanJSON = `{
+  "planned_values": {
+    "root_module": {
+      "resources": [
+        {"address": "aws_nat_gateway.this", "mode": "managed", "type": "aws_nat_gateway", "values": {}}
+      ],
+      "child_modules": [
+        {
+          "resources": [
+            {"address": "module.eks.aws_eks_cluster.this", "mode": "managed", "type": "aws_eks_cluster", "values": {}},
+            {"address": "module.eks.aws_eks_node_group.core", "mode": "managed", "type": "aws_eks_node_group",
+             "values": {"instance_types": ["t3.large"], "disk_size": 50, "scaling_config": [{"desired_size": 2}]}},
+            {"address": "module.efs.aws_efs_file_system.this", "mode": "managed", "type": "aws_efs_file_system",
+             "values": {"throughput_mode": "provisioned", "provisioned_throughput_in_mibps": 10}},
+            {"address": "module.logs.aws_cloudwatch_log_group.this", "mode": "managed", "type": "aws_cloudwatch_log_group",
+             "values": {"retention_in_days": 14}},
+            {"address": "module.backup.aws_backup_plan.this", "mode": "managed", "type": "aws_backup_plan",
+             "values": {"rule": [{"lifecycle": [{"delete_after": 35}]}]}},
+            {"address": "data.aws_caller_identity.current", "mode": "data", "type": "aws_caller_identity", "values": {}}
+          ]
+        }
+      ]
+    }
+  }
+}`
+
+func TestExtractPlannedInfrastructure(t *testing.T) {
+	var plan terraformPlanJSON
+	require.NoError(t, json.Unmarshal([]byte(testTerraformPlanJSON), &plan))
+
+	infra := extractPlannedInfrastructure(&plan)
+
+	require.Equal(t, 1, infra.EKSClusters)
+	require.Equal(t, 1, infra.NATGateways)
+	require.Len(t, infra.NodeGroups, 1)
+	require.Equal(t, "t3.large", infra.NodeGroups[0].InstanceType)
+	require.Equal(t, 2, infra.NodeGroups[0].DesiredSize)
+	require.Equal(t, float64(50), infra.NodeGroups[0].DiskSizeGB)
+	require.True(t, infra.HasEFS)
+	require.Equal(t, "provisioned", infra.EFSThroughputMode)
+	require.Equal(t, float64(10), infra.EFSProvisionedMiBps)
+	require.Equal(t, 14, infra.LogRetentionDays)
+	require.Equal(t, 35, infra.BackupRetentionDays)
+}
+
+func TestReadTerraformPlan_BinaryPlanInPathWithSpaces(t *testing.T) {
+	binDir := t.TempDir()
+	script := "#!/bin/sh\n[ \"$3\" = \"my plan.tfplan\" ] || exit 1\necho '{\"resource_changes\":[]}'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "terraform"), []byte(script), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	planDir := filepath.Join(t.TempDir(), "deploy path", "tokamak-thanos-stack")
+	require.NoError(t, os.MkdirAll(planDir, 0755))
+	planPath := filepath.Join(planDir, "my plan.tfplan")
+	require.NoError(t, os.WriteFile(planPath, []byte("binary"), 0644))
+
+	plan, err := readTerraformPlan(context.Background(), planPath)
+	require.NoError(t, err)
+	require.Empty(t, plan.plannedResources())
+}
+
+func TestFargatePodSize(t *testing.T) {
+	tests := []struct {
+		vcpu, memory         float64
+		wantVCPU, wantMemory float64
+	}{
+		{0.25, 0.5, 0.25, 1},
+		{0.25, 1.2, 0.25, 2},
+		{0.25, 2, 0.5, 3},
+		{0.5, 1, 0.5, 2},
+		{2, 4, 2, 5},
+		{1, 8, 2, 9},
+		{32, 256, 16, 120},
+	}
+	for _, tt := range tests {
+		vcpu, memory := fargatePodSize(tt.vcpu, tt.memory)
+		require.Equal(t, tt.wantVCPU, vcpu, "vcpu for %v/%v", tt.vcpu, tt.memory)
+		require.Equal(t, tt.wantMemory, memory, "memory for %v/%v", tt.vcpu, tt.memory)
+	}
+}
+
+func TestParseKubernetesQuantities(t *testing.T) {
+	cpu, err := parseCPUQuantity("250m")
+	require.NoError(t, err)
+	require.Equal(t, 0.25, cpu)
+
+	memory, err := parseMemoryQuantityGB("512Mi")
+	require.NoError(t, err)
+	require.Equal(t, 0.5, memory)
+
+	_, err = parseMemoryQuantityGB("lots")
+	require.Error(t, err)
+}
+
+func TestReadHelmValuePods_AppliesTestnetOverrides(t *testing.T) {
+	valuesPath := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(valuesPath, []byte(`
+op_geth:
+  resources:
+    cpu: "2"
+    memory: 4Gi
+op_node:
+  replicas: 2
+  resources:
+    cpu: "1"
+    memory: 2Gi
+disabled_component:
+  enabled: false
+  resources:
+    cpu: "1"
+    memory: 1Gi
+enable_vpc: true
+`), 0644))
+
+	pods, err := readHelmValuePods(valuesPath, testnetResourceOverrides)
+	require.NoError(t, err)
+
+	require.Equal(t, costPodSpec{VCPU: 0.5, MemoryGB: 1, Replicas: 1}, pods["op_geth"])
+	require.Equal(t, costPodSpec{VCPU: 0.5, MemoryGB: 1, Replicas: 2}, pods["op_node"])
+	require.NotContains(t, pods, "disabled_component")
+	// Overrides for components missing from the file still describe a pod
+	require.Contains(t, pods, "redis")
+}
+
+func TestEstimatePresetCost_PresetsAddModules(t *testing.T) {
+	table, _, err := loadAWSPriceTable(t.TempDir(), "")
+	require.NoError(t, err)
+
+	infra := &plannedInfrastructure{costInfrastructure: table.DefaultInfrastructure}
+	general := estimatePresetCost(table, "us-east-1", constants.Mainnet, constants.PresetGeneral, infra, table.DefaultChainPods, nil)
+	full := estimatePresetCost(table, "us-east-1", constants.Mainnet, constants.PresetFull, infra, table.DefaultChainPods, nil)
+
+	require.Greater(t, general.TotalMonthlyUSD, 0.0)
+	require.Greater(t, full.TotalMonthlyUSD, general.TotalMonthlyUSD)
+	require.False(t, hasCostItem(general, "CloudWatch log ingestion"), "general preset has no monitoring module")
+	require.True(t, hasCostItem(full, "CloudWatch log ingestion"))
+	require.True(t, hasCostItem(full, "blockExplorer database"))
+
+	disabled := estimatePresetCost(table, "us-east-1", constants.Mainnet, constants.PresetFull, infra, table.DefaultChainPods,
+		&types.LoggingConfig{Enabled: false})
+	require.False(t, hasCostItem(disabled, "CloudWatch log ingestion"), "logging disabled in LoggingConfig")
+}
+
+func TestEstimatePresetCost_UnknownRegionFallsBack(t *testing.T) {
+	table, _, err := loadAWSPriceTable(t.TempDir(), "")
+	require.NoError(t, err)
+
+	infra := &plannedInfrastructure{costInfrastructure: table.DefaultInfrastructure}
+	estimate := estimatePresetCost(table, "mars-north-1", constants.Mainnet, constants.PresetGeneral, infra, table.DefaultChainPods, nil)
+	require.Equal(t, table.DefaultRegion, estimate.Region)
+}
+
+func hasCostItem(estimate *types.CostEstimate, resource string) bool {
+	for _, item := range estimate.Items {
+		if item.Resource == resource {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,579 @@
+package thanos
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/tokamak-network/trh-sdk/abis"
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+const (
+	defaultCrossTradeExpireAfter = 24 * time.Hour
+	// defaultCrossTradeStuckAfter leaves the L1 to L2 message time to be relayed, which takes minutes
+	defaultCrossTradeStuckAfter = time.Hour
+	// crossTradeLogRange is the number of blocks searched for cross-trade events in one eth_getLogs
+	crossTradeLogRange = uint64(10000)
+)
+
+// crossTradeL1EventsABIJSON has the events of the L1 cross-trade contracts that complete a request on L2.
+// The abis package carries the L2 contracts only, so the events are declared here. The L2toL2 variants
+// overload the L2toL1 ones.
+const crossTradeL1EventsABIJSON = `[
+  {"type":"event","name":"ProvideCT","inputs":[
+    {"name":"_l1token","type":"address","indexed":false},
+    {"name":"_l2token","type":"address","indexed":false},
+    {"name":"_requester","type":"address","indexed":false},
+    {"name":"_receiver","type":"address","indexed":false},
+    {"name":"_provider","type":"address","indexed":false},
+    {"name":"_totalAmount","type":"uint256","indexed":false},
+    {"name":"_ctAmount","type":"uint256","indexed":false},
+    {"name":"_saleCount","type":"uint256","indexed":true},
+    {"name":"_l2chainId","type":"uint256","indexed":true},
+    {"name":"_hash","type":"bytes32","indexed":false}]},
+  {"type":"event","name":"ProvideCT","inputs":[
+    {"name":"_l1token","type":"address","indexed":false},
+    {"name":"_l2SourceToken","type":"address","indexed":false},
+    {"name":"_l2DestinationToken","type":"address","indexed":false},
+    {"name":"_requester","type":"address","indexed":false},
+    {"name":"_receiver","type":"address","indexed":false},
+    {"name":"_provider","type":"address","indexed":false},
+    {"name":"_totalAmount","type":"uint256","indexed":false},
+    {"name":"_ctAmount","type":"uint256","indexed":false},
+    {"name":"_saleCount","type":"uint256","indexed":true},
+    {"name":"_l2SourceChainId","type":"uint256","indexed":true},
+    {"name":"_l2DestinationChainId","type":"uint256","indexed":true},
+    {"name":"_hash","type":"bytes32","indexed":false}]},
+  {"type":"event","name":"L1CancelCT","inputs":[
+    {"name":"_requester","type":"address","indexed":false},
+    {"name":"_saleCount","type":"uint256","indexed":true},
+    {"name":"_l2chainId","type":"uint256","indexed":true},
+    {"name":"_hash","type":"bytes32","indexed":false}]},
+  {"type":"event","name":"L1CancelCT","inputs":[
+    {"name":"_requester","type":"address","indexed":false},
+    {"name":"_saleCount","type":"uint256","indexed":true},
+    {"name":"_l2SourceChainId","type":"uint256","indexed":true},
+    {"name":"_l2DestinationChainId","type":"uint256","indexed":true},
+    {"name":"_hash","type":"bytes32","indexed":false}]}
+]`
+
+var (
+	crossTradeL2ABI       = mustParseCrossTradeABI(abis.L2CrossTradeABI)
+	crossTradeL2toL2ABI   = mustParseCrossTradeABI(abis.L2toL2CrossTradeL2ABI)
+	crossTradeL1EventsABI = mustParseCrossTradeABI(crossTradeL1EventsABIJSON)
+)
+
+func mustParseCrossTradeABI(data string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// crossTradeLogClient is the RPC of a chain the cross-trade events are read from
+type crossTradeLogClient interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]ethTypes.Log, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethTypes.Header, error)
+}
+
+// crossTradeMarket is the L1 contract of a deploy mode and the L2 contracts requests are made on
+type crossTradeMarket struct {
+	mode         constants.CrossTradeDeployMode
+	l1ChainID    uint64
+	l1RPC        string
+	l1CrossTrade common.Address
+	chains       []crossTradeChain
+	// symbols are the token names by lowercase L1 token address
+	symbols map[string]string
+}
+
+type crossTradeChain struct {
+	chainID uint64
+	rpc     string
+	proxy   common.Address
+}
+
+// crossTradeMarkets returns the cross-trade deployments of settings.json, the plugin deployments first
+// and the local deployment for the modes the plugin did not deploy
+func (t *ThanosStack) crossTradeMarkets(mode constants.CrossTradeDeployMode) ([]*crossTradeMarket, error) {
+	if t.deployConfig == nil {
+		return nil, fmt.Errorf("deployConfig is nil")
+	}
+	modes := []constants.CrossTradeDeployMode{constants.CrossTradeDeployModeL2ToL1, constants.CrossTradeDeployModeL2ToL2}
+	if mode != "" {
+		if !constants.IsSupportedCrossTradeDeployMode(mode) {
+			return nil, fmt.Errorf("unsupported cross-trade mode %q, use %s or %s", mode, constants.CrossTradeDeployModeL2ToL1, constants.CrossTradeDeployModeL2ToL2)
+		}
+		modes = []constants.CrossTradeDeployMode{mode}
+	}
+
+	l1ChainID := t.deployConfig.L1ChainID
+	var markets []*crossTradeMarket
+	for _, mode := range modes {
+		market := &crossTradeMarket{mode: mode, l1ChainID: l1ChainID, symbols: crossTradeL1Symbols(l1ChainID)}
+
+		if input := t.deployConfig.CrossTrade[mode]; input != nil && input.L1ChainConfig != nil {
+			var contracts *types.DeployCrossTradeContractsOutput
+			if input.Output != nil {
+				contracts = input.Output.DeployCrossTradeContractsOutput
+			}
+			market.l1ChainID = input.L1ChainConfig.ChainID
+			market.l1RPC = input.L1ChainConfig.RPC
+			l1CrossTrade := input.L1ChainConfig.CrossTradeProxyAddress
+			if contracts != nil && contracts.L1CrossTradeProxyAddress != "" {
+				l1CrossTrade = contracts.L1CrossTradeProxyAddress
+			}
+			market.l1CrossTrade = common.HexToAddress(l1CrossTrade)
+			for _, chain := range input.L2ChainConfig {
+				proxy := chain.CrossTradeProxyAddress
+				if contracts != nil && contracts.L2CrossTradeProxyAddresses[chain.ChainID] != "" {
+					proxy = contracts.L2CrossTradeProxyAddresses[chain.ChainID]
+				}
+				if !common.IsHexAddress(proxy) {
+					continue
+				}
+				market.chains = append(market.chains, crossTradeChain{chainID: chain.ChainID, rpc: chain.RPC, proxy: common.HexToAddress(proxy)})
+			}
+			for _, token := range input.RegisterTokens {
+				market.symbols[strings.ToLower(token.L1TokenAddress)] = token.TokenName
+			}
+		} else if local := t.deployConfig.CrossTradeContracts; local != nil {
+			market.l1RPC = t.deployConfig.L1RPCURL
+			proxy := local.L2CrossTradeProxy
+			market.l1CrossTrade = common.HexToAddress(local.L1CrossTradeProxy)
+			if mode == constants.CrossTradeDeployModeL2ToL2 {
+				proxy = local.L2toL2CrossTradeProxy
+				market.l1CrossTrade = common.HexToAddress(crossTradeSepoliaL2toL2L1)
+			}
+			if common.IsHexAddress(proxy) {
+				market.chains = append(market.chains, crossTradeChain{chainID: t.deployConfig.L2ChainID, rpc: t.deployConfig.L2RpcUrl, proxy: common.HexToAddress(proxy)})
+			}
+		}
+
+		if len(market.chains) > 0 {
+			markets = append(markets, market)
+		}
+	}
+	if len(markets) == 0 {
+		return nil, fmt.Errorf("cross-trade is not deployed. Please install the cross-trade plugin first")
+	}
+	return markets, nil
+}
+
+// crossTradeL1Symbols returns the names of the well-known L1 tokens by lowercase address
+func crossTradeL1Symbols(l1ChainID uint64) map[string]string {
+	symbols := map[string]string{strings.ToLower(common.Address{}.Hex()): "ETH"}
+	config, ok := constants.L1ChainConfigurations[l1ChainID]
+	if !ok {
+		return symbols
+	}
+	for address, symbol := range map[string]string{config.USDCAddress: "USDC", config.USDTAddress: "USDT", config.TON: "TON"} {
+		if common.IsHexAddress(address) && common.HexToAddress(address) != (common.Address{}) {
+			symbols[strings.ToLower(address)] = symbol
+		}
+	}
+	return symbols
+}
+
+// CrossTradeStatus indexes the requests of the cross-trade contracts and reports them by status and token
+func (t *ThanosStack) CrossTradeStatus(ctx context.Context, input *types.CrossTradeStatusInput) (*types.CrossTradeStatusReport, error) {
+	markets, err := t.crossTradeMarkets(input.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	indexer := &crossTradeIndexer{
+		fromBlock:   input.FromBlock,
+		expireAfter: input.ExpireAfter,
+		stuckAfter:  input.StuckAfter,
+		now:         time.Now(),
+	}
+	if indexer.expireAfter <= 0 {
+		indexer.expireAfter = defaultCrossTradeExpireAfter
+	}
+	if indexer.stuckAfter <= 0 {
+		indexer.stuckAfter = defaultCrossTradeStuckAfter
+	}
+
+	var requests []types.CrossTradeRequest
+	for _, market := range markets {
+		l1, err := ethclient.DialContext(ctx, market.l1RPC)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to L1 RPC %s: %w", market.l1RPC, err)
+		}
+		l2 := make(map[uint64]crossTradeLogClient, len(market.chains))
+		var clients []*ethclient.Client
+		for _, chain := range market.chains {
+			client, err := ethclient.DialContext(ctx, chain.rpc)
+			if err != nil {
+				l1.Close()
+				for _, client := range clients {
+					client.Close()
+				}
+				return nil, fmt.Errorf("failed to connect to the RPC %s of chain %d: %w", chain.rpc, chain.chainID, err)
+			}
+			clients = append(clients, client)
+			l2[chain.chainID] = client
+		}
+
+		t.logger.Infof("🔎 Indexing %s cross-trade requests on %d chains", market.mode, len(market.chains))
+		marketRequests, err := indexer.index(ctx, market, l1, l2)
+		l1.Close()
+		for _, client := range clients {
+			client.Close()
+		}
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, marketRequests...)
+	}
+
+	report := summarizeCrossTradeRequests(requests)
+	report.GeneratedAt = indexer.now.UTC().Format(time.RFC3339)
+	return report, nil
+}
+
+// crossTradeIndexer matches the requests made on L2 with their provide or cancel on L1 and their
+// completion on L2
+type crossTradeIndexer struct {
+	fromBlock   uint64
+	expireAfter time.Duration
+	stuckAfter  time.Duration
+	now         time.Time
+}
+
+// index returns the requests of a market with their status
+func (x *crossTradeIndexer) index(ctx context.Context, market *crossTradeMarket, l1 crossTradeLogClient, l2 map[uint64]crossTradeLogClient) ([]types.CrossTradeRequest, error) {
+	l2ABI := crossTradeL2ABI
+	if market.mode == constants.CrossTradeDeployModeL2ToL2 {
+		l2ABI = crossTradeL2toL2ABI
+	}
+
+	var requests []*types.CrossTradeRequest
+	byHash := make(map[common.Hash]*types.CrossTradeRequest)
+	completions := make(map[common.Hash]crossTradeEvent)
+	for _, chain := range market.chains {
+		client := l2[chain.chainID]
+		events, err := crossTradeEvents(ctx, client, chain.proxy, l2ABI, []string{"RequestCT", "NonRequestCT", "ProviderClaimCT", "CancelCT"}, x.fromBlock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to index chain %d: %w", chain.chainID, err)
+		}
+		times := newCrossTradeBlockTimes(client)
+		for _, event := range events {
+			switch event.name {
+			case "RequestCT", "NonRequestCT":
+				requestedAt, err := times.at(ctx, event.log.BlockNumber)
+				if err != nil {
+					return nil, err
+				}
+				request := &types.CrossTradeRequest{
+					Mode:          market.mode,
+					Hash:          event.hash().Hex(),
+					SaleCount:     event.uint("_saleCount").String(),
+					SourceChainID: chain.chainID,
+					L1Token:       event.address("_l1token").Hex(),
+					L2Token:       event.address("_l2token", "_l2SourceToken").Hex(),
+					Requester:     event.address("_requester").Hex(),
+					Receiver:      event.address("_receiver").Hex(),
+					TotalAmount:   event.uint("_totalAmount").String(),
+					CtAmount:      event.uint("_ctAmount").String(),
+					Registered:    event.name == "RequestCT",
+					RequestTx:     event.log.TxHash.Hex(),
+					RequestedAt:   requestedAt.UTC().Format(time.RFC3339),
+				}
+				request.Symbol = market.symbols[strings.ToLower(request.L1Token)]
+				request.DestinationChainID = market.l1ChainID
+				if destination := event.uint("_l2DestinationChainId"); destination.Sign() > 0 {
+					request.DestinationChainID = destination.Uint64()
+				}
+				requests = append(requests, request)
+				byHash[event.hash()] = request
+			default:
+				completions[event.hash()] = event
+			}
+		}
+	}
+
+	// Only the requests L2 did not complete are searched on L1, from the oldest of them
+	var pendingSince time.Time
+	for _, request := range requests {
+		if event, ok := completions[common.HexToHash(request.Hash)]; ok {
+			request.CompleteTx = event.log.TxHash.Hex()
+			request.Status = types.CrossTradeRequestCancelled
+			if event.name == "ProviderClaimCT" {
+				request.Status = types.CrossTradeRequestFulfilled
+				request.Provider = event.address("_provider").Hex()
+			}
+			continue
+		}
+		requestedAt, _ := time.Parse(time.RFC3339, request.RequestedAt)
+		if pendingSince.IsZero() || requestedAt.Before(pendingSince) {
+			pendingSince = requestedAt
+		}
+	}
+
+	if !pendingSince.IsZero() {
+		l1Times := newCrossTradeBlockTimes(l1)
+		fromBlock, err := l1Times.blockAt(ctx, pendingSince)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the L1 block of %s: %w", pendingSince.Format(time.RFC3339), err)
+		}
+		events, err := crossTradeEvents(ctx, l1, market.l1CrossTrade, crossTradeL1EventsABI, []string{"ProvideCT", "ProvideCT0", "L1CancelCT", "L1CancelCT0"}, fromBlock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to index L1: %w", err)
+		}
+		for _, event := range events {
+			request := byHash[event.hash()]
+			if request == nil || request.Status != "" {
+				continue
+			}
+			l1At, err := l1Times.at(ctx, event.log.BlockNumber)
+			if err != nil {
+				return nil, err
+			}
+			request.L1Tx = event.log.TxHash.Hex()
+			request.L1At = l1At.UTC().Format(time.RFC3339)
+			if strings.HasPrefix(event.name, "ProvideCT") {
+				request.Provider = event.address("_provider").Hex()
+			}
+		}
+	}
+
+	result := make([]types.CrossTradeRequest, 0, len(requests))
+	for _, request := range requests {
+		if request.Status == "" {
+			request.Status = x.pendingStatus(request)
+		}
+		result = append(result, *request)
+	}
+	return result, nil
+}
+
+// pendingStatus returns the status of a request L2 did not complete
+func (x *crossTradeIndexer) pendingStatus(request *types.CrossTradeRequest) types.CrossTradeRequestStatus {
+	if request.L1At != "" {
+		l1At, _ := time.Parse(time.RFC3339, request.L1At)
+		if x.now.Sub(l1At) > x.stuckAfter {
+			return types.CrossTradeRequestStuck
+		}
+		return types.CrossTradeRequestRelaying
+	}
+	requestedAt, _ := time.Parse(time.RFC3339, request.RequestedAt)
+	if x.now.Sub(requestedAt) > x.expireAfter {
+		return types.CrossTradeRequestExpired
+	}
+	return types.CrossTradeRequestOpen
+}
+
+// crossTradeEvent is a decoded cross-trade event
+type crossTradeEvent struct {
+	name   string
+	log    ethTypes.Log
+	fields map[string]interface{}
+}
+
+// hash returns the request hash, named _hashValue on requests and _hash otherwise
+func (e crossTradeEvent) hash() common.Hash {
+	for _, name := range []string{"_hash", "_hashValue"} {
+		if value, ok := e.fields[name].([32]byte); ok {
+			return value
+		}
+	}
+	return common.Hash{}
+}
+
+// address returns the first of the named address fields the event has
+func (e crossTradeEvent) address(names ...string) common.Address {
+	for _, name := range names {
+		if value, ok := e.fields[name].(common.Address); ok {
+			return value
+		}
+	}
+	return common.Address{}
+}
+
+// uint returns the named uint256 field, zero when the event does not have it
+func (e crossTradeEvent) uint(name string) *big.Int {
+	if value, ok := e.fields[name].(*big.Int); ok {
+		return value
+	}
+	return new(big.Int)
+}
+
+// crossTradeEvents returns the named events emitted by contract from block from to the head, in chain order
+func crossTradeEvents(ctx context.Context, client crossTradeLogClient, contract common.Address, contractABI abi.ABI, names []string, from uint64) ([]crossTradeEvent, error) {
+	byID := make(map[common.Hash]abi.Event, len(names))
+	var topics []common.Hash
+	for _, name := range names {
+		event, ok := contractABI.Events[name]
+		if !ok {
+			return nil, fmt.Errorf("event %s is not in the cross-trade ABI", name)
+		}
+		byID[event.ID] = event
+		topics = append(topics, event.ID)
+	}
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the head: %w", err)
+	}
+	var events []crossTradeEvent
+	for start := from; start <= head; start += crossTradeLogRange {
+		end := min(start+crossTradeLogRange-1, head)
+		query := ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: []common.Address{contract},
+			Topics:    [][]common.Hash{topics},
+		}
+		var logs []ethTypes.Log
+		err := rpcCallWithRetry(ctx, func() error {
+			var err error
+			logs, err = client.FilterLogs(ctx, query)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the events of blocks %d-%d: %w", start, end, err)
+		}
+		for _, log := range logs {
+			if len(log.Topics) == 0 {
+				continue
+			}
+			event, ok := byID[log.Topics[0]]
+			if !ok {
+				continue
+			}
+			fields := make(map[string]interface{})
+			if err := contractABI.UnpackIntoMap(fields, event.Name, log.Data); err != nil {
+				return nil, fmt.Errorf("failed to decode %s in %s: %w", event.Name, log.TxHash.Hex(), err)
+			}
+			var indexed abi.Arguments
+			for _, input := range event.Inputs {
+				if input.Indexed {
+					indexed = append(indexed, input)
+				}
+			}
+			if err := abi.ParseTopicsIntoMap(fields, indexed, log.Topics[1:]); err != nil {
+				return nil, fmt.Errorf("failed to decode the topics of %s in %s: %w", event.Name, log.TxHash.Hex(), err)
+			}
+			events = append(events, crossTradeEvent{name: event.Name, log: log, fields: fields})
+		}
+	}
+	return events, nil
+}
+
+// crossTradeBlockTimes caches the timestamps of the blocks of a chain
+type crossTradeBlockTimes struct {
+	client crossTradeLogClient
+	times  map[uint64]time.Time
+}
+
+func newCrossTradeBlockTimes(client crossTradeLogClient) *crossTradeBlockTimes {
+	return &crossTradeBlockTimes{client: client, times: make(map[uint64]time.Time)}
+}
+
+// at returns the timestamp of a block
+func (b *crossTradeBlockTimes) at(ctx context.Context, number uint64) (time.Time, error) {
+	if at, ok := b.times[number]; ok {
+		return at, nil
+	}
+	header, err := b.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read block %d: %w", number, err)
+	}
+	at := time.Unix(int64(header.Time), 0)
+	b.times[number] = at
+	return at, nil
+}
+
+// blockAt returns the last block mined at or before a time, 0 when the chain is younger
+func (b *crossTradeBlockTimes) blockAt(ctx context.Context, at time.Time) (uint64, error) {
+	head, err := b.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	low, high := uint64(0), head
+	for low < high {
+		mid := low + (high-low+1)/2
+		midAt, err := b.at(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if midAt.After(at) {
+			high = mid - 1
+		} else {
+			low = mid
+		}
+	}
+	return low, nil
+}
+
+// summarizeCrossTradeRequests totals the requests by mode, source chain and token
+func summarizeCrossTradeRequests(requests []types.CrossTradeRequest) *types.CrossTradeStatusReport {
+	report := &types.CrossTradeStatusReport{Requests: requests}
+	type tokenKey struct {
+		mode    constants.CrossTradeDeployMode
+		chainID uint64
+		l1Token string
+	}
+	totals := make(map[tokenKey]*types.CrossTradeTokenStatus)
+	amounts := make(map[tokenKey]map[types.CrossTradeRequestStatus]*big.Int)
+	var keys []tokenKey
+	for _, request := range requests {
+		key := tokenKey{request.Mode, request.SourceChainID, request.L1Token}
+		total := totals[key]
+		if total == nil {
+			total = &types.CrossTradeTokenStatus{
+				Mode:          request.Mode,
+				SourceChainID: request.SourceChainID,
+				L1Token:       request.L1Token,
+				Symbol:        request.Symbol,
+				Counts:        make(map[types.CrossTradeRequestStatus]int),
+				Amounts:       make(map[types.CrossTradeRequestStatus]string),
+			}
+			totals[key] = total
+			amounts[key] = make(map[types.CrossTradeRequestStatus]*big.Int)
+			keys = append(keys, key)
+		}
+		total.Counts[request.Status]++
+		if amounts[key][request.Status] == nil {
+			amounts[key][request.Status] = new(big.Int)
+		}
+		if amount, ok := new(big.Int).SetString(request.TotalAmount, 10); ok {
+			amounts[key][request.Status].Add(amounts[key][request.Status], amount)
+		}
+		if request.Status == types.CrossTradeRequestStuck {
+			report.Stuck++
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].mode != keys[j].mode {
+			return keys[i].mode < keys[j].mode
+		}
+		if keys[i].chainID != keys[j].chainID {
+			return keys[i].chainID < keys[j].chainID
+		}
+		return keys[i].l1Token < keys[j].l1Token
+	})
+	for _, key := range keys {
+		for status, amount := range amounts[key] {
+			totals[key].Amounts[status] = amount.String()
+		}
+		report.Tokens = append(report.Tokens, *totals[key])
+	}
+	return report
+}
@@ -0,0 +1,171 @@
+package thanos
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// fakeCrossTradeLogClient is a chain mining one block every 12 seconds from genesis
+type fakeCrossTradeLogClient struct {
+	head    uint64
+	genesis time.Time
+	logs    []ethTypes.Log
+}
+
+func (c *fakeCrossTradeLogClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.head, nil
+}
+
+func (c *fakeCrossTradeLogClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]ethTypes.Log, error) {
+	var logs []ethTypes.Log
+	for _, log := range c.logs {
+		if log.BlockNumber >= q.FromBlock.Uint64() && log.BlockNumber <= q.ToBlock.Uint64() && log.Address == q.Addresses[0] {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func (c *fakeCrossTradeLogClient) HeaderByNumber(ctx context.Context, number *big.Int) (*ethTypes.Header, error) {
+	return &ethTypes.Header{Number: number, Time: uint64(c.blockTime(number.Uint64()).Unix())}, nil
+}
+
+func (c *fakeCrossTradeLogClient) blockTime(number uint64) time.Time {
+	return c.genesis.Add(time.Duration(number) * 12 * time.Second)
+}
+
+func (c *fakeCrossTradeLogClient) blockAt(at time.Time) uint64 {
+	return uint64(at.Sub(c.genesis) / (12 * time.Second))
+}
+
+func (c *fakeCrossTradeLogClient) emit(t *testing.T, contract common.Address, block uint64, event abi.Event, values map[string]interface{}) {
+	topics := []common.Hash{event.ID}
+	var data []interface{}
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			topics = append(topics, common.BigToHash(values[input.Name].(*big.Int)))
+			continue
+		}
+		data = append(data, values[input.Name])
+	}
+	packed, err := event.Inputs.NonIndexed().Pack(data...)
+	require.NoError(t, err)
+	c.logs = append(c.logs, ethTypes.Log{
+		Address:     contract,
+		BlockNumber: block,
+		TxHash:      common.BigToHash(big.NewInt(int64(len(c.logs) + 1))),
+		Topics:      topics,
+		Data:        packed,
+	})
+}
+
+func TestCrossTradeStatus(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	l2Proxy := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	l1CrossTrade := common.HexToAddress("0x1000000000000000000000000000000000000002")
+	usdc := common.HexToAddress("0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238")
+	l2Token := common.HexToAddress("0x4200000000000000000000000000000000000778")
+	requester := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	provider := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	l1 := &fakeCrossTradeLogClient{head: 30000, genesis: now.Add(-30000 * 12 * time.Second)}
+	l2 := &fakeCrossTradeLogClient{head: 30000, genesis: now.Add(-30000 * 12 * time.Second)}
+
+	request := func(saleCount int64, block uint64, amount int64) common.Hash {
+		hash := common.BigToHash(big.NewInt(1000 + saleCount))
+		l2.emit(t, l2Proxy, block, crossTradeL2ABI.Events["RequestCT"], map[string]interface{}{
+			"_l1token":     usdc,
+			"_l2token":     l2Token,
+			"_requester":   requester,
+			"_receiver":    requester,
+			"_totalAmount": big.NewInt(amount),
+			"_ctAmount":    big.NewInt(amount - 1),
+			"_saleCount":   big.NewInt(saleCount),
+			"_l2chainId":   big.NewInt(111551119090),
+			"_hashValue":   hash,
+		})
+		return hash
+	}
+	provide := func(hash common.Hash, saleCount int64, block uint64) {
+		l1.emit(t, l1CrossTrade, block, crossTradeL1EventsABI.Events["ProvideCT"], map[string]interface{}{
+			"_l1token":     usdc,
+			"_l2token":     l2Token,
+			"_requester":   requester,
+			"_receiver":    requester,
+			"_provider":    provider,
+			"_totalAmount": big.NewInt(10),
+			"_ctAmount":    big.NewInt(9),
+			"_saleCount":   big.NewInt(saleCount),
+			"_l2chainId":   big.NewInt(111551119090),
+			"_hash":        hash,
+		})
+	}
+
+	// 1 is claimed, 2 is cancelled, 3 was provided 2 hours ago, 4 was provided a minute ago,
+	// 5 waits for 2 days and 6 for 10 minutes
+	fulfilled := request(1, 100, 10)
+	l2.emit(t, l2Proxy, 200, crossTradeL2ABI.Events["ProviderClaimCT"], map[string]interface{}{
+		"_l1token": usdc, "_l2token": l2Token, "_requester": requester, "_receiver": requester,
+		"_provider": provider, "_totalAmount": big.NewInt(10), "_ctAmount": big.NewInt(9),
+		"_saleCount": big.NewInt(1), "_l2chainId": big.NewInt(111551119090), "_hash": fulfilled,
+	})
+	cancelled := request(2, 150, 20)
+	l2.emit(t, l2Proxy, 250, crossTradeL2ABI.Events["CancelCT"], map[string]interface{}{
+		"_requester": requester, "_totalAmount": big.NewInt(20), "_saleCount": big.NewInt(2),
+		"_l2chainId": big.NewInt(111551119090), "_hash": cancelled,
+	})
+	stuck := request(3, l2.blockAt(now.Add(-3*time.Hour)), 30)
+	provide(stuck, 3, l1.blockAt(now.Add(-2*time.Hour)))
+	relaying := request(4, l2.blockAt(now.Add(-5*time.Minute)), 40)
+	provide(relaying, 4, l1.blockAt(now.Add(-time.Minute)))
+	request(5, l2.blockAt(now.Add(-48*time.Hour)), 50)
+	request(6, 29950, 60)
+
+	market := &crossTradeMarket{
+		mode:         constants.CrossTradeDeployModeL2ToL1,
+		l1ChainID:    constants.EthereumSepoliaChainID,
+		l1CrossTrade: l1CrossTrade,
+		chains:       []crossTradeChain{{chainID: 111551119090, proxy: l2Proxy}},
+		symbols:      crossTradeL1Symbols(constants.EthereumSepoliaChainID),
+	}
+	indexer := &crossTradeIndexer{expireAfter: defaultCrossTradeExpireAfter, stuckAfter: defaultCrossTradeStuckAfter, now: now}
+	requests, err := indexer.index(context.Background(), market, l1, map[uint64]crossTradeLogClient{111551119090: l2})
+	require.NoError(t, err)
+
+	statuses := map[string]types.CrossTradeRequestStatus{}
+	providers := map[string]string{}
+	for _, request := range requests {
+		statuses[request.SaleCount] = request.Status
+		providers[request.SaleCount] = request.Provider
+		require.Equal(t, "USDC", request.Symbol)
+		require.True(t, request.Registered)
+	}
+	require.Equal(t, map[string]types.CrossTradeRequestStatus{
+		"1": types.CrossTradeRequestFulfilled,
+		"2": types.CrossTradeRequestCancelled,
+		"3": types.CrossTradeRequestStuck,
+		"4": types.CrossTradeRequestRelaying,
+		"5": types.CrossTradeRequestExpired,
+		"6": types.CrossTradeRequestOpen,
+	}, statuses)
+	require.Equal(t, provider.Hex(), providers["3"])
+	require.Empty(t, providers["5"])
+
+	report := summarizeCrossTradeRequests(requests)
+	require.Equal(t, 1, report.Stuck)
+	require.Len(t, report.Tokens, 1)
+	require.Equal(t, 1, report.Tokens[0].Counts[types.CrossTradeRequestStuck])
+	require.Equal(t, "30", report.Tokens[0].Amounts[types.CrossTradeRequestStuck])
+	require.Equal(t, "USDC", report.Tokens[0].Symbol)
+}
@@ -0,0 +1,414 @@
+package thanos
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// crossTradeTokensClient reads the token registrations of an L2 cross-trade contract
+type crossTradeTokensClient interface {
+	crossTradeLogClient
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// CrossTradeTokensDiff compares the token registrations in settings.json with the L2 cross-trade contracts
+func (t *ThanosStack) CrossTradeTokensDiff(ctx context.Context, input *types.CrossTradeTokensInput) (*types.CrossTradeTokensReport, error) {
+	return t.crossTradeTokens(ctx, input, false)
+}
+
+// CrossTradeTokensSync registers the tokens of settings.json that are missing or incorrect on the L2
+// cross-trade contracts. Registrations without an entry in settings.json are reported and left as they
+// are, since requests may still use them.
+func (t *ThanosStack) CrossTradeTokensSync(ctx context.Context, input *types.CrossTradeTokensInput) (*types.CrossTradeTokensReport, error) {
+	return t.crossTradeTokens(ctx, input, true)
+}
+
+func (t *ThanosStack) crossTradeTokens(ctx context.Context, input *types.CrossTradeTokensInput, sync bool) (*types.CrossTradeTokensReport, error) {
+	if t.deployConfig == nil {
+		return nil, fmt.Errorf("deployConfig is nil")
+	}
+	// Tokens are registered by the cross-trade plugin, the local deployment registers its fixed pairs itself
+	var modes []constants.CrossTradeDeployMode
+	for _, mode := range []constants.CrossTradeDeployMode{constants.CrossTradeDeployModeL2ToL1, constants.CrossTradeDeployModeL2ToL2} {
+		if t.deployConfig.CrossTrade[mode] != nil && (input.Mode == "" || input.Mode == mode) {
+			modes = append(modes, mode)
+		}
+	}
+	if len(modes) == 0 {
+		if input.Mode != "" {
+			return nil, fmt.Errorf("cross-trade %s is not deployed. Please install the cross-trade plugin first", input.Mode)
+		}
+		return nil, fmt.Errorf("cross-trade is not deployed. Please install the cross-trade plugin first")
+	}
+
+	report := &types.CrossTradeTokensReport{DryRun: sync && input.DryRun}
+	for _, mode := range modes {
+		markets, err := t.crossTradeMarkets(mode)
+		if err != nil {
+			return nil, err
+		}
+		market := markets[0]
+		crossTrade := t.deployConfig.CrossTrade[mode]
+		expected := crossTradeExpectedTokens(market, crossTrade.RegisterTokens)
+
+		clients := make(map[uint64]*ethclient.Client, len(market.chains))
+		tokenClients := make(map[uint64]crossTradeTokensClient, len(market.chains))
+		closeClients := func() {
+			for _, client := range clients {
+				client.Close()
+			}
+		}
+		for _, chain := range market.chains {
+			client, err := ethclient.DialContext(ctx, chain.rpc)
+			if err != nil {
+				closeClients()
+				return nil, fmt.Errorf("failed to connect to the RPC %s of chain %d: %w", chain.rpc, chain.chainID, err)
+			}
+			clients[chain.chainID] = client
+			tokenClients[chain.chainID] = client
+		}
+
+		t.logger.Infof("🔎 Comparing %d %s token registrations with %d chains", len(expected), mode, len(market.chains))
+		diffs, err := diffCrossTradeTokens(ctx, market, expected, tokenClients, input.FromBlock)
+		if err != nil {
+			closeClients()
+			return nil, err
+		}
+		report.Diffs = append(report.Diffs, diffs...)
+
+		if sync {
+			keys := make(map[uint64]string, len(crossTrade.L2ChainConfig))
+			for _, chain := range crossTrade.L2ChainConfig {
+				keys[chain.ChainID] = chain.PrivateKey
+			}
+			txs, err := t.submitCrossTradeTokenTxs(ctx, market, crossTradeTokenTxs(mode, diffs), clients, keys, input.DryRun)
+			report.Transactions = append(report.Transactions, txs...)
+			if err != nil {
+				closeClients()
+				return report, err
+			}
+		}
+		closeClients()
+	}
+
+	for _, diff := range report.Diffs {
+		switch diff.State {
+		case types.CrossTradeTokenMissing:
+			report.Missing++
+		case types.CrossTradeTokenIncorrect:
+			report.Incorrect++
+		case types.CrossTradeTokenUnexpected:
+			report.Unexpected++
+		}
+	}
+	return report, nil
+}
+
+// crossTradeExpectedTokens returns the registrations RegisterNewTokensOnExistingCrossTrade makes for the
+// tokens of settings.json: one per L2 token in the l2_to_l1 mode, and one per source and destination
+// chain in the l2_to_l2 mode
+func crossTradeExpectedTokens(market *crossTradeMarket, tokens []*types.RegisterTokenInput) []types.CrossTradeTokenPair {
+	var pairs []types.CrossTradeTokenPair
+	seen := make(map[string]bool)
+	for _, token := range tokens {
+		for _, source := range token.L2TokenInputs {
+			pair := types.CrossTradeTokenPair{
+				TokenName: token.TokenName,
+				L1ChainID: market.l1ChainID,
+				L1Token:   common.HexToAddress(token.L1TokenAddress).Hex(),
+				ChainID:   source.ChainID,
+				L2Token:   common.HexToAddress(source.TokenAddress).Hex(),
+			}
+			if market.mode == constants.CrossTradeDeployModeL2ToL1 {
+				if !seen[crossTradeTokenKey(pair)] {
+					seen[crossTradeTokenKey(pair)] = true
+					pairs = append(pairs, pair)
+				}
+				continue
+			}
+			for _, destination := range token.L2TokenInputs {
+				if destination.ChainID == source.ChainID {
+					continue
+				}
+				pair.DestinationChainID = destination.ChainID
+				pair.DestinationToken = common.HexToAddress(destination.TokenAddress).Hex()
+				if !seen[crossTradeTokenKey(pair)] {
+					seen[crossTradeTokenKey(pair)] = true
+					pairs = append(pairs, pair)
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// crossTradeTokenKey identifies a registration, the token name aside
+func crossTradeTokenKey(pair types.CrossTradeTokenPair) string {
+	return strings.ToLower(fmt.Sprintf("%d/%s/%d/%s/%d/%s", pair.L1ChainID, pair.L1Token, pair.ChainID, pair.L2Token, pair.DestinationChainID, pair.DestinationToken))
+}
+
+// diffCrossTradeTokens checks the expected registrations with registerCheck. The L2toL2 contracts also
+// emit RegisterToken and DeleteToken, which find the registrations that differ from settings.json; the
+// L2CrossTrade contract emits none, so only its missing registrations are found.
+func diffCrossTradeTokens(ctx context.Context, market *crossTradeMarket, expected []types.CrossTradeTokenPair, clients map[uint64]crossTradeTokensClient, fromBlock uint64) ([]types.CrossTradeTokenDiff, error) {
+	proxies := make(map[uint64]common.Address, len(market.chains))
+	for _, chain := range market.chains {
+		proxies[chain.chainID] = chain.proxy
+	}
+	expectedKeys := make(map[string]bool, len(expected))
+	for _, pair := range expected {
+		if _, ok := proxies[pair.ChainID]; !ok {
+			return nil, fmt.Errorf("%s token %s is set for chain %d, which has no %s cross-trade contract", pair.TokenName, pair.L2Token, pair.ChainID, market.mode)
+		}
+		expectedKeys[crossTradeTokenKey(pair)] = true
+	}
+
+	var diffs []types.CrossTradeTokenDiff
+	for _, chain := range market.chains {
+		client := clients[chain.chainID]
+
+		var onChain []types.CrossTradeTokenPair
+		if market.mode == constants.CrossTradeDeployModeL2ToL2 {
+			registered, err := crossTradeRegisteredTokens(ctx, client, chain.proxy, fromBlock)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the token registrations of chain %d: %w", chain.chainID, err)
+			}
+			onChain = registered
+		}
+		used := make(map[string]bool)
+
+		for _, pair := range expected {
+			if pair.ChainID != chain.chainID {
+				continue
+			}
+			registered, err := crossTradeRegisterCheck(ctx, client, market.mode, chain.proxy, pair)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check %s token %s on chain %d: %w", pair.TokenName, pair.L2Token, chain.chainID, err)
+			}
+			if registered {
+				used[crossTradeTokenKey(pair)] = true
+				diffs = append(diffs, types.CrossTradeTokenDiff{Mode: market.mode, State: types.CrossTradeTokenRegistered, Expected: &pair})
+				continue
+			}
+
+			diff := types.CrossTradeTokenDiff{Mode: market.mode, State: types.CrossTradeTokenMissing, Expected: &pair}
+			for _, other := range onChain {
+				key := crossTradeTokenKey(other)
+				if used[key] || expectedKeys[key] {
+					continue
+				}
+				if strings.EqualFold(other.L2Token, pair.L2Token) && other.DestinationChainID == pair.DestinationChainID {
+					used[key] = true
+					diff.State = types.CrossTradeTokenIncorrect
+					diff.OnChain = &other
+					break
+				}
+			}
+			diffs = append(diffs, diff)
+		}
+
+		for _, other := range onChain {
+			key := crossTradeTokenKey(other)
+			if used[key] || expectedKeys[key] {
+				continue
+			}
+			diffs = append(diffs, types.CrossTradeTokenDiff{Mode: market.mode, State: types.CrossTradeTokenUnexpected, OnChain: &other})
+		}
+	}
+	return diffs, nil
+}
+
+// crossTradeRegisteredTokens replays the RegisterToken and DeleteToken events of an L2toL2 contract and
+// returns the registrations still in place, in registration order
+func crossTradeRegisteredTokens(ctx context.Context, client crossTradeLogClient, proxy common.Address, fromBlock uint64) ([]types.CrossTradeTokenPair, error) {
+	events, err := crossTradeEvents(ctx, client, proxy, crossTradeL2toL2ABI, []string{"RegisterToken", "DeleteToken"}, fromBlock)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	registered := make(map[string]types.CrossTradeTokenPair)
+	for _, event := range events {
+		pair := types.CrossTradeTokenPair{
+			L1ChainID:          event.uint("_l1ChainId").Uint64(),
+			L1Token:            event.address("_l1token").Hex(),
+			ChainID:            event.uint("_l2SourceChainId").Uint64(),
+			L2Token:            event.address("_l2SourceToken").Hex(),
+			DestinationChainID: event.uint("_l2DestinationChainId").Uint64(),
+			DestinationToken:   event.address("_l2DestinationToken").Hex(),
+		}
+		key := crossTradeTokenKey(pair)
+		if event.name == "DeleteToken" {
+			delete(registered, key)
+			continue
+		}
+		if _, ok := registered[key]; !ok {
+			keys = append(keys, key)
+		}
+		registered[key] = pair
+	}
+
+	var pairs []types.CrossTradeTokenPair
+	for _, key := range keys {
+		if pair, ok := registered[key]; ok {
+			pairs = append(pairs, pair)
+			delete(registered, key)
+		}
+	}
+	return pairs, nil
+}
+
+// crossTradeRegisterCheck reads registerCheck of a registration
+func crossTradeRegisterCheck(ctx context.Context, client crossTradeTokensClient, mode constants.CrossTradeDeployMode, proxy common.Address, pair types.CrossTradeTokenPair) (bool, error) {
+	data, err := crossTradeRegisterCheckData(mode, pair)
+	if err != nil {
+		return false, err
+	}
+
+	var result []byte
+	err = rpcCallWithRetry(ctx, func() error {
+		var err error
+		result, err = client.CallContract(ctx, ethereum.CallMsg{To: &proxy, Data: data}, nil)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	values, err := crossTradeL2ABI.Unpack("registerCheck", result)
+	if err != nil {
+		return false, err
+	}
+	registered, ok := values[0].(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected registerCheck result %v", values[0])
+	}
+	return registered, nil
+}
+
+// crossTradeRegisterCheckData returns the registerCheck calldata of a registration. The L2toL2 contract
+// keys registrations by keccak256(abi.encode(l1ChainId, l2SourceChainId, l2DestinationChainId, l1Token,
+// l2SourceToken, l2DestinationToken)).
+func crossTradeRegisterCheckData(mode constants.CrossTradeDeployMode, pair types.CrossTradeTokenPair) ([]byte, error) {
+	if mode != constants.CrossTradeDeployModeL2ToL2 {
+		return crossTradeL2ABI.Pack("registerCheck", new(big.Int).SetUint64(pair.L1ChainID), common.HexToAddress(pair.L1Token), common.HexToAddress(pair.L2Token))
+	}
+	uint256Type, _ := abi.NewType("uint256", "", nil)
+	addressType, _ := abi.NewType("address", "", nil)
+	idArgs := abi.Arguments{
+		{Type: uint256Type}, {Type: uint256Type}, {Type: uint256Type},
+		{Type: addressType}, {Type: addressType}, {Type: addressType},
+	}
+	packed, err := idArgs.Pack(
+		new(big.Int).SetUint64(pair.L1ChainID),
+		new(big.Int).SetUint64(pair.ChainID),
+		new(big.Int).SetUint64(pair.DestinationChainID),
+		common.HexToAddress(pair.L1Token),
+		common.HexToAddress(pair.L2Token),
+		common.HexToAddress(pair.DestinationToken),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return crossTradeL2toL2ABI.Pack("registerCheck", crypto.Keccak256Hash(packed))
+}
+
+// crossTradeTokenTxs returns the transactions that make the contracts match settings.json: missing
+// registrations are registered, incorrect ones deleted and registered again
+func crossTradeTokenTxs(mode constants.CrossTradeDeployMode, diffs []types.CrossTradeTokenDiff) []types.CrossTradeTokenTx {
+	var txs []types.CrossTradeTokenTx
+	for _, diff := range diffs {
+		switch diff.State {
+		case types.CrossTradeTokenIncorrect:
+			txs = append(txs, types.CrossTradeTokenTx{Mode: mode, Method: "deleteToken", Pair: *diff.OnChain})
+			txs = append(txs, types.CrossTradeTokenTx{Mode: mode, Method: "registerToken", Pair: *diff.Expected})
+		case types.CrossTradeTokenMissing:
+			txs = append(txs, types.CrossTradeTokenTx{Mode: mode, Method: "registerToken", Pair: *diff.Expected})
+		}
+	}
+	return txs
+}
+
+// crossTradeTokenTxArgs returns the arguments of registerToken and deleteToken, which take the same ones
+func crossTradeTokenTxArgs(mode constants.CrossTradeDeployMode, pair types.CrossTradeTokenPair) []interface{} {
+	if mode == constants.CrossTradeDeployModeL2ToL2 {
+		return []interface{}{
+			common.HexToAddress(pair.L1Token),
+			common.HexToAddress(pair.L2Token),
+			common.HexToAddress(pair.DestinationToken),
+			new(big.Int).SetUint64(pair.L1ChainID),
+			new(big.Int).SetUint64(pair.ChainID),
+			new(big.Int).SetUint64(pair.DestinationChainID),
+		}
+	}
+	return []interface{}{
+		common.HexToAddress(pair.L1Token),
+		common.HexToAddress(pair.L2Token),
+		new(big.Int).SetUint64(pair.L1ChainID),
+	}
+}
+
+// submitCrossTradeTokenTxs sends the registrations with the key of each L2 chain in settings.json and
+// waits for them in order, since a deleteToken must land before the registerToken that replaces it
+func (t *ThanosStack) submitCrossTradeTokenTxs(ctx context.Context, market *crossTradeMarket, txs []types.CrossTradeTokenTx, clients map[uint64]*ethclient.Client, keys map[uint64]string, dryRun bool) ([]types.CrossTradeTokenTx, error) {
+	contractABI := crossTradeL2ABI
+	if market.mode == constants.CrossTradeDeployModeL2ToL2 {
+		contractABI = crossTradeL2toL2ABI
+	}
+	proxies := make(map[uint64]common.Address, len(market.chains))
+	for _, chain := range market.chains {
+		proxies[chain.chainID] = chain.proxy
+	}
+
+	var submitted []types.CrossTradeTokenTx
+	for _, tx := range txs {
+		args := crossTradeTokenTxArgs(market.mode, tx.Pair)
+		if dryRun {
+			submitted = append(submitted, tx)
+			continue
+		}
+
+		client := clients[tx.Pair.ChainID]
+		privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(keys[tx.Pair.ChainID], "0x"))
+		if err != nil {
+			return submitted, fmt.Errorf("invalid private key for chain %d: %w", tx.Pair.ChainID, err)
+		}
+		chainID, err := client.ChainID(ctx)
+		if err != nil {
+			return submitted, fmt.Errorf("failed to get the chain ID of chain %d: %w", tx.Pair.ChainID, err)
+		}
+		auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+		if err != nil {
+			return submitted, fmt.Errorf("failed to create transactor for chain %d: %w", tx.Pair.ChainID, err)
+		}
+		auth.Context = ctx
+
+		contract := bind.NewBoundContract(proxies[tx.Pair.ChainID], contractABI, client, client, client)
+		sent, err := contract.Transact(auth, tx.Method, args...)
+		if err != nil {
+			return submitted, fmt.Errorf("failed to send %s on chain %d: %w", tx.Method, tx.Pair.ChainID, err)
+		}
+		t.logger.Infof("%s on chain %d sent: %s", tx.Method, tx.Pair.ChainID, sent.Hash().Hex())
+		receipt, err := bind.WaitMined(ctx, client, sent)
+		if err != nil {
+			return submitted, fmt.Errorf("failed to wait for %s %s: %w", tx.Method, sent.Hash().Hex(), err)
+		}
+		if receipt.Status != ethTypes.ReceiptStatusSuccessful {
+			return submitted, fmt.Errorf("%s reverted (tx: %s)", tx.Method, sent.Hash().Hex())
+		}
+		tx.TxHash = sent.Hash().Hex()
+		submitted = append(submitted, tx)
+	}
+	return submitted, nil
+}
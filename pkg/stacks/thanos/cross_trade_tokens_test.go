@@ -0,0 +1,158 @@
+package thanos
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// fakeCrossTradeTokensClient answers registerCheck for the registrations it holds
+type fakeCrossTradeTokensClient struct {
+	fakeCrossTradeLogClient
+	mode       constants.CrossTradeDeployMode
+	proxy      common.Address
+	registered map[string]bool
+}
+
+func (c *fakeCrossTradeTokensClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if c.registered[common.Bytes2Hex(msg.Data)] {
+		return common.LeftPadBytes([]byte{1}, 32), nil
+	}
+	return make([]byte, 32), nil
+}
+
+// register marks a pair registered and emits its RegisterToken on L2toL2 contracts
+func (c *fakeCrossTradeTokensClient) register(t *testing.T, block uint64, pair types.CrossTradeTokenPair) {
+	data, err := crossTradeRegisterCheckData(c.mode, pair)
+	require.NoError(t, err)
+	c.registered[common.Bytes2Hex(data)] = true
+	if c.mode == constants.CrossTradeDeployModeL2ToL2 {
+		c.emitToken(t, block, "RegisterToken", pair)
+	}
+}
+
+// emitToken emits RegisterToken or DeleteToken without changing registerCheck
+func (c *fakeCrossTradeTokensClient) emitToken(t *testing.T, block uint64, name string, pair types.CrossTradeTokenPair) {
+	event := crossTradeL2toL2ABI.Events[name]
+	data, err := event.Inputs.NonIndexed().Pack(
+		new(big.Int).SetUint64(pair.L1ChainID),
+		new(big.Int).SetUint64(pair.ChainID),
+		new(big.Int).SetUint64(pair.DestinationChainID),
+	)
+	require.NoError(t, err)
+	c.logs = append(c.logs, ethTypes.Log{
+		Address:     c.proxy,
+		BlockNumber: block,
+		Topics: []common.Hash{
+			event.ID,
+			common.BytesToHash(common.HexToAddress(pair.L1Token).Bytes()),
+			common.BytesToHash(common.HexToAddress(pair.L2Token).Bytes()),
+			common.BytesToHash(common.HexToAddress(pair.DestinationToken).Bytes()),
+		},
+		Data: data,
+	})
+}
+
+func TestDiffCrossTradeTokensL2ToL2(t *testing.T) {
+	usdc := "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238"
+	ton := "0xa30fe40285B8f5c0457DbC3B7C8A280373c40044"
+	proxyA := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	proxyB := common.HexToAddress("0x1000000000000000000000000000000000000002")
+	usdcA := "0x4200000000000000000000000000000000000778"
+	usdcB := "0x4200000000000000000000000000000000000779"
+	tonA := "0x4200000000000000000000000000000000000486"
+	tonB := "0x4200000000000000000000000000000000000487"
+
+	market := &crossTradeMarket{
+		mode:      constants.CrossTradeDeployModeL2ToL2,
+		l1ChainID: constants.EthereumSepoliaChainID,
+		chains:    []crossTradeChain{{chainID: 1001, proxy: proxyA}, {chainID: 1002, proxy: proxyB}},
+	}
+	expected := crossTradeExpectedTokens(market, []*types.RegisterTokenInput{
+		{TokenName: "USDC", L1TokenAddress: usdc, L2TokenInputs: []*types.L2TokenInput{{ChainID: 1001, TokenAddress: usdcA}, {ChainID: 1002, TokenAddress: usdcB}}},
+		{TokenName: "TON", L1TokenAddress: ton, L2TokenInputs: []*types.L2TokenInput{{ChainID: 1001, TokenAddress: tonA}, {ChainID: 1002, TokenAddress: tonB}}},
+	})
+	require.Len(t, expected, 4)
+
+	a := &fakeCrossTradeTokensClient{fakeCrossTradeLogClient: fakeCrossTradeLogClient{head: 500}, mode: market.mode, proxy: proxyA, registered: map[string]bool{}}
+	b := &fakeCrossTradeTokensClient{fakeCrossTradeLogClient: fakeCrossTradeLogClient{head: 500}, mode: market.mode, proxy: proxyB, registered: map[string]bool{}}
+
+	// Chain A: USDC registered, TON registered to the wrong destination token, a deleted pair and a
+	// pair settings.json does not know. Chain B: nothing registered.
+	a.register(t, 10, expected[0])
+	wrongTON := expected[2]
+	wrongTON.DestinationToken = "0x9999999999999999999999999999999999999999"
+	a.register(t, 11, wrongTON)
+	deleted := types.CrossTradeTokenPair{L1ChainID: market.l1ChainID, L1Token: usdc, ChainID: 1001, L2Token: "0x8888888888888888888888888888888888888888", DestinationChainID: 1002, DestinationToken: usdcB}
+	a.register(t, 12, deleted)
+	a.emitToken(t, 13, "DeleteToken", deleted)
+	unexpected := types.CrossTradeTokenPair{L1ChainID: market.l1ChainID, L1Token: usdc, ChainID: 1001, L2Token: "0x7777777777777777777777777777777777777777", DestinationChainID: 1003, DestinationToken: usdcB}
+	a.register(t, 14, unexpected)
+
+	diffs, err := diffCrossTradeTokens(context.Background(), market, expected, map[uint64]crossTradeTokensClient{1001: a, 1002: b}, 0)
+	require.NoError(t, err)
+
+	var states []types.CrossTradeTokenState
+	for _, diff := range diffs {
+		states = append(states, diff.State)
+	}
+	require.Equal(t, []types.CrossTradeTokenState{
+		types.CrossTradeTokenRegistered, // USDC 1001 -> 1002
+		types.CrossTradeTokenIncorrect,  // TON 1001 -> 1002
+		types.CrossTradeTokenUnexpected, // 0x7777 1001 -> 1003
+		types.CrossTradeTokenMissing,    // USDC 1002 -> 1001
+		types.CrossTradeTokenMissing,    // TON 1002 -> 1001
+	}, states)
+	require.Equal(t, wrongTON.DestinationToken, diffs[1].OnChain.DestinationToken)
+	require.Equal(t, "0x7777777777777777777777777777777777777777", diffs[2].OnChain.L2Token)
+
+	txs := crossTradeTokenTxs(market.mode, diffs)
+	var methods []string
+	for _, tx := range txs {
+		methods = append(methods, tx.Method)
+	}
+	require.Equal(t, []string{"deleteToken", "registerToken", "registerToken", "registerToken"}, methods)
+	require.Equal(t, wrongTON.DestinationToken, txs[0].Pair.DestinationToken)
+	require.Equal(t, common.HexToAddress(tonB).Hex(), txs[1].Pair.DestinationToken)
+}
+
+func TestDiffCrossTradeTokensL2ToL1(t *testing.T) {
+	proxy := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	market := &crossTradeMarket{
+		mode:      constants.CrossTradeDeployModeL2ToL1,
+		l1ChainID: constants.EthereumSepoliaChainID,
+		chains:    []crossTradeChain{{chainID: 1001, proxy: proxy}},
+	}
+	expected := crossTradeExpectedTokens(market, []*types.RegisterTokenInput{
+		{TokenName: "ETH", L1TokenAddress: "0x0000000000000000000000000000000000000000", L2TokenInputs: []*types.L2TokenInput{{ChainID: 1001, TokenAddress: "0xDeadDeAddeAddEAddeadDEaDDEAdDeaDDeAD0000"}}},
+		{TokenName: "USDC", L1TokenAddress: "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238", L2TokenInputs: []*types.L2TokenInput{{ChainID: 1001, TokenAddress: "0x4200000000000000000000000000000000000778"}}},
+	})
+	require.Len(t, expected, 2)
+
+	client := &fakeCrossTradeTokensClient{mode: market.mode, proxy: proxy, registered: map[string]bool{}}
+	client.register(t, 0, expected[0])
+
+	diffs, err := diffCrossTradeTokens(context.Background(), market, expected, map[uint64]crossTradeTokensClient{1001: client}, 0)
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+	require.Equal(t, types.CrossTradeTokenRegistered, diffs[0].State)
+	require.Equal(t, types.CrossTradeTokenMissing, diffs[1].State)
+
+	txs := crossTradeTokenTxs(market.mode, diffs)
+	require.Len(t, txs, 1)
+	require.Len(t, crossTradeTokenTxArgs(market.mode, txs[0].Pair), 3)
+}
+
+func TestDiffCrossTradeTokensUnknownChain(t *testing.T) {
+	market := &crossTradeMarket{mode: constants.CrossTradeDeployModeL2ToL1, chains: []crossTradeChain{{chainID: 1001}}}
+	_, err := diffCrossTradeTokens(context.Background(), market, []types.CrossTradeTokenPair{{ChainID: 1002, TokenName: "USDC"}}, nil, 0)
+	require.ErrorContains(t, err, "chain 1002")
+}
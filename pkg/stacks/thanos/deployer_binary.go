@@ -12,6 +12,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
+
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
 )
 
 // TokamakDeployerVersion is the pinned version of the tokamak-deployer binary.
@@ -258,14 +261,19 @@ func runGenerateGenesis(ctx context.Context, binaryPath string, opts genesisOpts
 	return runBinaryCommand(ctx, binaryPath, args, w)
 }
 
+// runBinaryCommand runs tokamak-deployer with its output redacted, since the arguments include the admin key
 func runBinaryCommand(ctx context.Context, binaryPath string, args []string, w io.Writer) error {
+	logging.RegisterSecretsIn(strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, binaryPath, args...)
 	if w == nil {
 		w = os.Stdout
 	}
-	cmd.Stdout = w
-	cmd.Stderr = w
-	if err := cmd.Run(); err != nil {
+	out := logging.NewRedactingWriter(w)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err := cmd.Run()
+	_ = out.Flush()
+	if err != nil {
 		return fmt.Errorf("tokamak-deployer %s: %w", args[0], err)
 	}
 	return nil
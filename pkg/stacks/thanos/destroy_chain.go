@@ -87,6 +87,11 @@ func (t *ThanosStack) destroyInfraOnAWS(ctx context.Context) error {
 	if err := t.CleanupUnusedBackupResources(ctx); err != nil {
 		t.logger.Warnf("Failed to cleanup unused backup resources: %v", err)
 	}
+	if t.deployConfig.AWS != nil && namespace != "" {
+		if err := backup.RemoveRDSBackupSelection(ctx, t.logger, t.deployConfig.AWS.Region, namespace); err != nil {
+			t.logger.Warnf("Failed to remove the block explorer database from the backup plan: %v", err)
+		}
+	}
 
 	// Uninstall all optional features (best-effort: continues on error)
 	if err := t.uninstallFeatures(ctx); err != nil {
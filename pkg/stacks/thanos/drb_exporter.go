@@ -0,0 +1,152 @@
+package thanos
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go.uber.org/zap"
+
+	"github.com/tokamak-network/trh-sdk/pkg/stacks/thanos/bindings/commitreveal2"
+)
+
+const (
+	// DefaultDRBExporterListenAddr serves the DRB exporter /metrics and /healthz endpoints
+	DefaultDRBExporterListenAddr = ":7313"
+
+	defaultDRBExporterPollInterval = 15 * time.Second
+)
+
+// DRBExporterConfig holds the runtime configuration of the DRB exporter
+type DRBExporterConfig struct {
+	RPCURL string
+	// Contract is the CommitReveal2L2 contract the rounds are read from
+	Contract common.Address
+	// PollInterval defaults to 15 seconds
+	PollInterval time.Duration
+	// ListenAddr of the metrics server. Empty disables it.
+	ListenAddr string
+}
+
+// drbRoundsCaller is the subset of the CommitReveal2L2 bindings read by the exporter
+type drbRoundsCaller interface {
+	SCurrentRound(opts *bind.CallOpts) (*big.Int, error)
+	STrialNum(opts *bind.CallOpts, round *big.Int) (*big.Int, error)
+	SIsInProcess(opts *bind.CallOpts) (*big.Int, error)
+	SRequestCount(opts *bind.CallOpts) (*big.Int, error)
+	GetActivatedOperatorsLength(opts *bind.CallOpts) (*big.Int, error)
+}
+
+type drbExporter struct {
+	cfg     DRBExporterConfig
+	caller  drbRoundsCaller
+	metrics *operatorMetrics
+
+	mu        sync.Mutex
+	startedAt time.Time
+	lastRead  time.Time
+}
+
+// RunDRBExporter exports the round state of the CommitReveal2L2 contract as drb_* metrics until ctx is
+// cancelled. The DRB nodes serve no metrics of their own.
+func RunDRBExporter(ctx context.Context, logger *zap.SugaredLogger, cfg DRBExporterConfig) error {
+	client, err := ethclient.DialContext(ctx, cfg.RPCURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+	defer client.Close()
+
+	caller, err := commitreveal2.NewCommitReveal2L2Caller(cfg.Contract, client)
+	if err != nil {
+		return fmt.Errorf("load CommitReveal2L2 contract: %w", err)
+	}
+	e := newDRBExporter(caller, cfg, time.Now())
+
+	if cfg.ListenAddr != "" {
+		serveOperatorMetrics(ctx, logger, cfg.ListenAddr, e.metrics, func() error { return e.healthy(time.Now()) })
+	}
+
+	ticker := time.NewTicker(e.cfg.PollInterval)
+	defer ticker.Stop()
+
+	logger.Infof("DRB exporter started (contract=%s, poll=%s)", cfg.Contract.Hex(), e.cfg.PollInterval)
+	for {
+		if err := e.check(ctx, time.Now()); err != nil {
+			logger.Warnf("DRB round check failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			logger.Infof("DRB exporter stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func newDRBExporter(caller drbRoundsCaller, cfg DRBExporterConfig, now time.Time) *drbExporter {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultDRBExporterPollInterval
+	}
+	return &drbExporter{cfg: cfg, caller: caller, metrics: newOperatorMetrics(), startedAt: now}
+}
+
+// check reads the current round of the contract and updates the metrics
+func (e *drbExporter) check(ctx context.Context, now time.Time) error {
+	opts := &bind.CallOpts{Context: ctx}
+	failed := func(what string, err error) error {
+		e.metrics.addCounter("drb_read_errors_total", "Failed reads of the CommitReveal2L2 contract", 1)
+		return fmt.Errorf("failed to read the %s: %w", what, err)
+	}
+	round, err := e.caller.SCurrentRound(opts)
+	if err != nil {
+		return failed("current round", err)
+	}
+	trial, err := e.caller.STrialNum(opts, round)
+	if err != nil {
+		return failed("trial of the current round", err)
+	}
+	inProcess, err := e.caller.SIsInProcess(opts)
+	if err != nil {
+		return failed("round status", err)
+	}
+	requests, err := e.caller.SRequestCount(opts)
+	if err != nil {
+		return failed("request count", err)
+	}
+	operators, err := e.caller.GetActivatedOperatorsLength(opts)
+	if err != nil {
+		return failed("activated operators", err)
+	}
+
+	e.metrics.setGauge("drb_current_round", "Current round of CommitReveal2L2", weiToFloat(round))
+	e.metrics.setGauge("drb_trial_num", "Trial of the current round, above 0 after a restarted round", weiToFloat(trial))
+	e.metrics.setGauge("drb_round_in_process", "1 while the current round is in process", weiToFloat(inProcess))
+	e.metrics.setGauge("drb_request_count", "Randomness requests made to CommitReveal2L2", weiToFloat(requests))
+	e.metrics.setGauge("drb_activated_operators", "Activated DRB operators", weiToFloat(operators))
+	e.metrics.setGauge("drb_last_read_timestamp_seconds", "Time of the last successful read of CommitReveal2L2", float64(now.Unix()))
+
+	e.mu.Lock()
+	e.lastRead = now
+	e.mu.Unlock()
+	return nil
+}
+
+// healthy fails when the contract has not been read for three poll intervals, measured from the start
+// of the exporter
+func (e *drbExporter) healthy(now time.Time) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	last := e.lastRead
+	if last.Before(e.startedAt) {
+		last = e.startedAt
+	}
+	if age := now.Sub(last); age > 3*e.cfg.PollInterval {
+		return fmt.Errorf("no CommitReveal2L2 read for %s", age.Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package thanos
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDRBRounds returns fixed round state, or fails every read
+type fakeDRBRounds struct {
+	round, trial, inProcess, requests, operators int64
+	err                                          error
+}
+
+func (f *fakeDRBRounds) SCurrentRound(*bind.CallOpts) (*big.Int, error) {
+	return big.NewInt(f.round), f.err
+}
+
+func (f *fakeDRBRounds) STrialNum(_ *bind.CallOpts, round *big.Int) (*big.Int, error) {
+	if round.Int64() != f.round {
+		return nil, fmt.Errorf("unexpected round %s", round)
+	}
+	return big.NewInt(f.trial), f.err
+}
+
+func (f *fakeDRBRounds) SIsInProcess(*bind.CallOpts) (*big.Int, error) {
+	return big.NewInt(f.inProcess), f.err
+}
+
+func (f *fakeDRBRounds) SRequestCount(*bind.CallOpts) (*big.Int, error) {
+	return big.NewInt(f.requests), f.err
+}
+
+func (f *fakeDRBRounds) GetActivatedOperatorsLength(*bind.CallOpts) (*big.Int, error) {
+	return big.NewInt(f.operators), f.err
+}
+
+func TestDRBExporter(t *testing.T) {
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	rounds := &fakeDRBRounds{round: 7, trial: 1, inProcess: 1, requests: 8, operators: 3}
+	e := newDRBExporter(rounds, DRBExporterConfig{}, start)
+	require.Equal(t, defaultDRBExporterPollInterval, e.cfg.PollInterval)
+
+	require.NoError(t, e.check(context.Background(), start))
+	require.Equal(t, float64(7), e.metrics.value("drb_current_round"))
+	require.Equal(t, float64(1), e.metrics.value("drb_trial_num"))
+	require.Equal(t, float64(1), e.metrics.value("drb_round_in_process"))
+	require.Equal(t, float64(8), e.metrics.value("drb_request_count"))
+	require.Equal(t, float64(3), e.metrics.value("drb_activated_operators"))
+	require.NoError(t, e.healthy(start.Add(30*time.Second)))
+
+	// Failed reads keep the last values and the exporter turns unhealthy after three intervals
+	rounds.err = fmt.Errorf("rpc timeout")
+	require.ErrorContains(t, e.check(context.Background(), start.Add(time.Minute)), "current round")
+	require.Equal(t, float64(1), e.metrics.value("drb_read_errors_total"))
+	require.Equal(t, float64(7), e.metrics.value("drb_current_round"))
+	require.ErrorContains(t, e.healthy(start.Add(time.Minute)), "no CommitReveal2L2 read")
+}
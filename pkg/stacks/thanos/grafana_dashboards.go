@@ -0,0 +1,284 @@
+package thanos
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// builtinGrafanaDashboards are provisioned next to the application dashboard on local and AWS deployments
+//
+//go:embed templates/dashboards/*.json
+var builtinGrafanaDashboards embed.FS
+
+const (
+	localGrafanaURL      = "http://localhost:3002"
+	localGrafanaUser     = "admin"
+	localGrafanaPassword = "admin"
+	grafanaAPITimeout    = 30 * time.Second
+)
+
+// GrafanaClient manages dashboards through the Grafana HTTP API
+type GrafanaClient struct {
+	URL      string
+	Username string
+	Password string
+
+	httpClient *http.Client
+}
+
+// NewGrafanaClient returns a client for the Grafana instance at grafanaURL
+func NewGrafanaClient(grafanaURL, username, password string) *GrafanaClient {
+	return &GrafanaClient{
+		URL:        strings.TrimSuffix(grafanaURL, "/"),
+		Username:   username,
+		Password:   password,
+		httpClient: newHTTPClient(grafanaAPITimeout),
+	}
+}
+
+// ResolveGrafanaClient returns a client for the Grafana of the deployment in deploymentPath.
+// Local deployments use the Grafana container on localhost:3002; otherwise the ALB ingress of the
+// monitoring plugin is used with the admin credentials stored in the Grafana secret.
+// Non-empty arguments override the resolved URL and credentials.
+func ResolveGrafanaClient(ctx context.Context, deploymentPath, grafanaURL, username, password string) (*GrafanaClient, error) {
+	if grafanaURL != "" && password != "" {
+		return NewGrafanaClient(grafanaURL, defaultString(username, localGrafanaUser), password), nil
+	}
+
+	if _, err := os.Stat(filepath.Join(deploymentPath, localComposeFileName)); err == nil {
+		return NewGrafanaClient(defaultString(grafanaURL, localGrafanaURL), defaultString(username, localGrafanaUser), defaultString(password, localGrafanaPassword)), nil
+	}
+
+	if err := utils.CheckMonitoringPluginInstalled(ctx); err != nil {
+		return nil, err
+	}
+	ingressName, err := utils.ExecuteCommand(ctx, "kubectl", "get", "ingress", "-n", constants.MonitoringNamespace, "-o", "jsonpath={.items[*].metadata.name}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the Grafana ingress: %w", err)
+	}
+	for _, name := range strings.Fields(ingressName) {
+		if strings.HasSuffix(name, "-grafana") {
+			ingressName = name
+			break
+		}
+	}
+	if !strings.HasSuffix(ingressName, "-grafana") {
+		return nil, fmt.Errorf("no Grafana ingress found in namespace %s", constants.MonitoringNamespace)
+	}
+
+	if grafanaURL == "" {
+		hostname, err := utils.ExecuteCommand(ctx, "kubectl", "get", "ingress", ingressName, "-n", constants.MonitoringNamespace, "-o", "jsonpath={.status.loadBalancer.ingress[0].hostname}")
+		if err != nil || strings.TrimSpace(hostname) == "" {
+			return nil, fmt.Errorf("the Grafana ALB ingress %s has no hostname yet", ingressName)
+		}
+		grafanaURL = "http://" + strings.TrimSpace(hostname)
+	}
+
+	// The Grafana chart stores the admin credentials in a secret named after the release
+	if username == "" || password == "" {
+		secret, err := utils.ExecuteCommand(ctx, "kubectl", "get", "secret", ingressName, "-n", constants.MonitoringNamespace, "-o", "json")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the Grafana admin secret, pass --password instead: %w", err)
+		}
+		var secretData struct {
+			Data map[string]string `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(secret), &secretData); err != nil {
+			return nil, fmt.Errorf("failed to parse the Grafana admin secret: %w", err)
+		}
+		decode := func(key string) string {
+			value, _ := base64.StdEncoding.DecodeString(secretData.Data[key])
+			return string(value)
+		}
+		username = defaultString(username, decode("admin-user"))
+		password = defaultString(password, decode("admin-password"))
+	}
+	return NewGrafanaClient(grafanaURL, username, password), nil
+}
+
+// ListDashboards returns the dashboards in Grafana ordered by folder and title
+func (g *GrafanaClient) ListDashboards(ctx context.Context) ([]types.GrafanaDashboard, error) {
+	var results []struct {
+		UID         string   `json:"uid"`
+		Title       string   `json:"title"`
+		URL         string   `json:"url"`
+		FolderTitle string   `json:"folderTitle"`
+		Tags        []string `json:"tags"`
+	}
+	if err := g.do(ctx, http.MethodGet, "/api/search?type=dash-db&limit=5000", nil, &results); err != nil {
+		return nil, err
+	}
+
+	dashboards := make([]types.GrafanaDashboard, 0, len(results))
+	for _, r := range results {
+		folder := r.FolderTitle
+		if folder == "" {
+			folder = "General"
+		}
+		dashboards = append(dashboards, types.GrafanaDashboard{
+			UID:    r.UID,
+			Title:  r.Title,
+			Folder: folder,
+			URL:    g.URL + r.URL,
+			Tags:   r.Tags,
+		})
+	}
+	sort.Slice(dashboards, func(i, j int) bool {
+		if dashboards[i].Folder != dashboards[j].Folder {
+			return dashboards[i].Folder < dashboards[j].Folder
+		}
+		return dashboards[i].Title < dashboards[j].Title
+	})
+	return dashboards, nil
+}
+
+// ExportDashboard returns the JSON model of a dashboard. The instance-specific id is removed so the
+// file can be imported into another Grafana.
+func (g *GrafanaClient) ExportDashboard(ctx context.Context, uid string) ([]byte, error) {
+	var response struct {
+		Dashboard map[string]interface{} `json:"dashboard"`
+	}
+	if err := g.do(ctx, http.MethodGet, "/api/dashboards/uid/"+url.PathEscape(uid), nil, &response); err != nil {
+		return nil, err
+	}
+	delete(response.Dashboard, "id")
+	return json.MarshalIndent(response.Dashboard, "", "  ")
+}
+
+// ImportDashboard creates or updates a dashboard from its JSON model. An existing dashboard with the
+// same uid is only replaced when overwrite is set.
+func (g *GrafanaClient) ImportDashboard(ctx context.Context, model []byte, folderUID string, overwrite bool) (*types.GrafanaDashboard, error) {
+	dashboard, err := parseDashboardModel(model)
+	if err != nil {
+		return nil, err
+	}
+	dashboard["id"] = nil
+
+	request := map[string]interface{}{
+		"dashboard": dashboard,
+		"overwrite": overwrite,
+		"message":   "Imported by trh-sdk",
+	}
+	if folderUID != "" {
+		request["folderUid"] = folderUID
+	}
+	var response struct {
+		UID string `json:"uid"`
+		URL string `json:"url"`
+	}
+	if err := g.do(ctx, http.MethodPost, "/api/dashboards/db", request, &response); err != nil {
+		return nil, err
+	}
+	title, _ := dashboard["title"].(string)
+	return &types.GrafanaDashboard{UID: response.UID, Title: title, URL: g.URL + response.URL}, nil
+}
+
+func (g *GrafanaClient) do(ctx context.Context, method, apiPath string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.URL+apiPath, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(g.Username, g.Password)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Grafana at %s: %w", g.URL, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read Grafana response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("grafana returned HTTP %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("grafana returned HTTP %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse Grafana response: %w", err)
+	}
+	return nil
+}
+
+// parseDashboardModel accepts a dashboard JSON model or an export of the dashboard API, which wraps the
+// model in a "dashboard" field
+func parseDashboardModel(model []byte) (map[string]interface{}, error) {
+	var dashboard map[string]interface{}
+	if err := json.Unmarshal(model, &dashboard); err != nil {
+		return nil, fmt.Errorf("invalid dashboard JSON: %w", err)
+	}
+	if wrapped, ok := dashboard["dashboard"].(map[string]interface{}); ok {
+		dashboard = wrapped
+	}
+	if title, _ := dashboard["title"].(string); title == "" {
+		return nil, fmt.Errorf("dashboard has no title")
+	}
+	if _, ok := dashboard["panels"]; !ok {
+		return nil, fmt.Errorf("dashboard has no panels")
+	}
+	return dashboard, nil
+}
+
+// BuiltinGrafanaDashboards returns the names of the dashboards shipped with trh-sdk
+func BuiltinGrafanaDashboards() []string {
+	entries, _ := builtinGrafanaDashboards.ReadDir("templates/dashboards")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	return names
+}
+
+// BuiltinGrafanaDashboard returns the JSON model of a built-in dashboard
+func BuiltinGrafanaDashboard(name string) ([]byte, error) {
+	data, err := builtinGrafanaDashboards.ReadFile(path.Join("templates/dashboards", name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown built-in dashboard %q (available: %s)", name, strings.Join(BuiltinGrafanaDashboards(), ", "))
+	}
+	return data, nil
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,106 @@
+package thanos
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrafanaClient(t *testing.T) {
+	var imported map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"invalid username or password"}`))
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/search":
+			require.Equal(t, "dash-db", r.URL.Query().Get("type"))
+			_, _ = w.Write([]byte(`[
+				{"uid":"b","title":"Zeta","url":"/d/b/zeta","folderTitle":"Ops"},
+				{"uid":"a","title":"Alpha","url":"/d/a/alpha","tags":["thanos-stack"]}
+			]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/dashboards/uid/a":
+			_, _ = w.Write([]byte(`{"dashboard":{"id":7,"uid":"a","title":"Alpha","panels":[]},"meta":{"slug":"alpha"}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/dashboards/db":
+			body, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &imported))
+			if imported["overwrite"] != true {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				_, _ = w.Write([]byte(`{"message":"A dashboard with the same uid already exists","status":"name-exists"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"uid":"a","url":"/d/a/alpha","status":"success"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewGrafanaClient(server.URL+"/", "admin", "secret")
+
+	dashboards, err := client.ListDashboards(ctx)
+	require.NoError(t, err)
+	require.Len(t, dashboards, 2)
+	require.Equal(t, "a", dashboards[0].UID, "General sorts before Ops")
+	require.Equal(t, "General", dashboards[0].Folder)
+	require.Equal(t, server.URL+"/d/b/zeta", dashboards[1].URL)
+
+	exported, err := client.ExportDashboard(ctx, "a")
+	require.NoError(t, err)
+	require.NotContains(t, string(exported), `"id"`)
+
+	// The exported file and the raw dashboard API response both import
+	dashboard, err := client.ImportDashboard(ctx, exported, "ops", true)
+	require.NoError(t, err)
+	require.Equal(t, "Alpha", dashboard.Title)
+	require.Equal(t, "ops", imported["folderUid"])
+	_, err = client.ImportDashboard(ctx, []byte(`{"dashboard":{"title":"Alpha","panels":[]},"meta":{}}`), "", true)
+	require.NoError(t, err)
+	require.Nil(t, imported["dashboard"].(map[string]interface{})["id"])
+
+	_, err = client.ImportDashboard(ctx, exported, "", false)
+	require.ErrorContains(t, err, "HTTP 412")
+	_, err = client.ImportDashboard(ctx, []byte(`{"uid":"x"}`), "", true)
+	require.ErrorContains(t, err, "no title")
+
+	_, err = NewGrafanaClient(server.URL, "admin", "wrong").ListDashboards(ctx)
+	require.ErrorContains(t, err, "invalid username or password")
+}
+
+func TestBuiltinGrafanaDashboards(t *testing.T) {
+	names := BuiltinGrafanaDashboards()
+	require.ElementsMatch(t, []string{"aa-paymaster", "batcher-proposer-health", "drb-rounds", "rollup-economics"}, names)
+
+	uids := map[string]bool{"thanos-stack-app-v9": true}
+	for _, name := range names {
+		data, err := BuiltinGrafanaDashboard(name)
+		require.NoError(t, err)
+		dashboard, err := parseDashboardModel(data)
+		require.NoError(t, err, name)
+
+		uid, _ := dashboard["uid"].(string)
+		require.NotEmpty(t, uid, name)
+		require.False(t, uids[uid], "duplicate uid %s", uid)
+		uids[uid] = true
+
+		for _, p := range dashboard["panels"].([]interface{}) {
+			panel := p.(map[string]interface{})
+			if panel["type"] == "row" {
+				continue
+			}
+			require.NotEmpty(t, panel["targets"], "%s: panel %s has no query", name, panel["title"])
+		}
+	}
+
+	_, err := BuiltinGrafanaDashboard("missing")
+	require.ErrorContains(t, err, "rollup-economics")
+}
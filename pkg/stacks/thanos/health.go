@@ -0,0 +1,599 @@
+package thanos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+const (
+	healthRPCTimeout = 20 * time.Second
+	// recentStateBlocks is the L1 range whose state a non-archive node still serves
+	recentStateBlocks = 127
+	// HealthStateFileName keeps the batcher nonce observation across health checks
+	HealthStateFileName = "health-state.json"
+)
+
+// rollupHealthConfig is the part of the op-node rollup config used by the health checks
+type rollupHealthConfig struct {
+	BatchInboxAddress common.Address `json:"batch_inbox_address"`
+	Genesis           struct {
+		SystemConfig struct {
+			BatcherAddr common.Address `json:"batcherAddr"`
+		} `json:"system_config"`
+	} `json:"genesis"`
+}
+
+// DefaultHealthThresholds returns the thresholds for a chain on the given L1. Batches are expected within
+// the max channel duration and outputs within the output submission interval.
+func DefaultHealthThresholds(l1ChainID uint64) types.HealthThresholds {
+	l1BlockTime := uint64(12)
+	channelDuration := uint64(120)
+	outputInterval := uint64(120)
+	if chainConfig, ok := constants.L1ChainConfigurations[l1ChainID]; ok {
+		if chainConfig.BlockTimeInSeconds > 0 {
+			l1BlockTime = chainConfig.BlockTimeInSeconds
+		}
+		if chainConfig.MaxChannelDuration > 0 {
+			channelDuration = chainConfig.MaxChannelDuration
+		}
+		if chainConfig.L2OutputOracleSubmissionInterval > 0 {
+			outputInterval = chainConfig.L2OutputOracleSubmissionInterval
+		}
+	}
+
+	batchInterval := time.Duration(channelDuration*l1BlockTime)*time.Second + 10*time.Minute
+	// The local proposer submits every 30 minutes regardless of the L1 submission interval
+	outputSubmission := time.Duration(outputInterval*constants.DefaultL2BlockTimeInSeconds) * time.Second
+	if outputSubmission < 30*time.Minute {
+		outputSubmission = 30 * time.Minute
+	}
+	return types.HealthThresholds{
+		UnsafeHeadAge:    time.Minute,
+		SafeHeadLag:      batchInterval,
+		FinalizedHeadLag: batchInterval + 30*time.Minute,
+		BatchSubmission:  batchInterval,
+		OutputSubmission: outputSubmission + 10*time.Minute,
+		L1HeadDrift:      10,
+		L2EngineDrift:    10,
+	}
+}
+
+// withHealthDefaults fills the zero thresholds with the defaults
+func withHealthDefaults(thresholds, defaults types.HealthThresholds) types.HealthThresholds {
+	if thresholds.UnsafeHeadAge == 0 {
+		thresholds.UnsafeHeadAge = defaults.UnsafeHeadAge
+	}
+	if thresholds.SafeHeadLag == 0 {
+		thresholds.SafeHeadLag = defaults.SafeHeadLag
+	}
+	if thresholds.FinalizedHeadLag == 0 {
+		thresholds.FinalizedHeadLag = defaults.FinalizedHeadLag
+	}
+	if thresholds.BatchSubmission == 0 {
+		thresholds.BatchSubmission = defaults.BatchSubmission
+	}
+	if thresholds.OutputSubmission == 0 {
+		thresholds.OutputSubmission = defaults.OutputSubmission
+	}
+	if thresholds.L1HeadDrift == 0 {
+		thresholds.L1HeadDrift = defaults.L1HeadDrift
+	}
+	if thresholds.L2EngineDrift == 0 {
+		thresholds.L2EngineDrift = defaults.L2EngineDrift
+	}
+	return thresholds
+}
+
+// CheckHealth reports the sync status of the rollup: head lags, the last batch and output submissions,
+// peer counts and the drift between op-node and the L1 and L2 RPCs
+func (t *ThanosStack) CheckHealth(ctx context.Context, input *types.HealthCheckInput) (*types.HealthReport, error) {
+	if t.deployConfig == nil {
+		return nil, fmt.Errorf("settings.json not found. Run this command from the deployment directory")
+	}
+	thresholds := withHealthDefaults(input.Thresholds, DefaultHealthThresholds(t.deployConfig.L1ChainID))
+
+	l1URL, l2URL, opNodeURL, stop, err := t.resolveHealthEndpoints(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	now := time.Now()
+	report := &types.HealthReport{CheckedAt: now.UTC(), Status: types.HealthOK}
+	add := func(checks ...types.HealthCheck) {
+		for _, check := range checks {
+			report.Checks = append(report.Checks, check)
+			if check.Status.Severity() > report.Status.Severity() {
+				report.Status = check.Status
+			}
+		}
+	}
+
+	rpcCtx, cancel := context.WithTimeout(ctx, healthRPCTimeout)
+	defer cancel()
+
+	opNode, err := rpc.DialContext(rpcCtx, opNodeURL)
+	if err != nil {
+		add(types.HealthCheck{Name: "op_node", Status: types.HealthCritical, Value: "unreachable", Message: err.Error()})
+		return report, nil
+	}
+	defer opNode.Close()
+
+	var syncStatus types.RollupSyncStatus
+	if err := opNode.CallContext(rpcCtx, &syncStatus, "optimism_syncStatus"); err != nil {
+		add(types.HealthCheck{Name: "op_node", Status: types.HealthCritical, Value: "unreachable", Message: fmt.Sprintf("optimism_syncStatus failed: %v", err)})
+		return report, nil
+	}
+	report.SyncStatus = &syncStatus
+	add(types.HealthCheck{Name: "op_node", Status: types.HealthOK, Value: opNodeURL})
+	add(evaluateHeadLags(&syncStatus, now, thresholds)...)
+
+	// L1 head drift between the L1 RPC and the L1 head op-node follows
+	l1, err := ethclient.DialContext(rpcCtx, l1URL)
+	if err == nil {
+		defer l1.Close()
+	}
+	var l1Head uint64
+	if err == nil {
+		l1Head, err = l1.BlockNumber(rpcCtx)
+	}
+	if err != nil {
+		add(types.HealthCheck{Name: "l1_head_drift", Status: types.HealthCritical, Value: "L1 RPC unreachable", Message: err.Error()})
+	} else {
+		add(blockDriftCheck("l1_head_drift", l1Head, syncStatus.HeadL1.Number, thresholds.L1HeadDrift, "op-node L1 head behind the L1 RPC"))
+	}
+
+	// L2 engine drift between op-geth and the unsafe head of op-node
+	l2, err := ethclient.DialContext(rpcCtx, l2URL)
+	if err == nil {
+		defer l2.Close()
+	}
+	var l2Head uint64
+	if err == nil {
+		l2Head, err = l2.BlockNumber(rpcCtx)
+	}
+	if err != nil {
+		add(types.HealthCheck{Name: "l2_engine_drift", Status: types.HealthCritical, Value: "L2 RPC unreachable", Message: err.Error()})
+	} else {
+		add(blockDriftCheck("l2_engine_drift", syncStatus.UnsafeL2.Number, l2Head, thresholds.L2EngineDrift, "L2 RPC behind the op-node unsafe head"))
+	}
+
+	add(t.peerChecks(rpcCtx, opNode, l2, thresholds.MinPeers)...)
+
+	if l1 != nil && l1Head > 0 {
+		var rollupConfig rollupHealthConfig
+		if err := opNode.CallContext(rpcCtx, &rollupConfig, "optimism_rollupConfig"); err != nil {
+			add(types.HealthCheck{Name: "batch_submission", Status: types.HealthUnknown, Message: fmt.Sprintf("optimism_rollupConfig failed: %v", err)})
+		} else {
+			statePath := filepath.Join(t.deploymentPath, HealthStateFileName)
+			add(batchSubmissionCheck(ctx, l1, rollupConfig.Genesis.SystemConfig.BatcherAddr, rollupConfig.BatchInboxAddress, l1Head, now, thresholds.BatchSubmission, statePath))
+		}
+		add(t.outputSubmissionCheck(ctx, l1, now, thresholds.OutputSubmission))
+	}
+	return report, nil
+}
+
+// resolveHealthEndpoints returns the L1, L2 and op-node RPC URLs. On AWS the op-node RPC is reached
+// through a kubectl port-forward, which stop closes.
+func (t *ThanosStack) resolveHealthEndpoints(ctx context.Context, input *types.HealthCheckInput) (string, string, string, func(), error) {
+	l1URL, l2URL, opNodeURL := input.L1RPCURL, input.L2RPCURL, input.OpNodeRPCURL
+	stop := func() {}
+
+	var defaultL1, defaultL2, defaultOpNode string
+	switch {
+	case t.network == constants.LocalDevnet:
+		defaultL1, defaultL2, defaultOpNode = "http://localhost:8545", "http://localhost:9545", "http://localhost:7545"
+	case utils.CheckFileExists(filepath.Join(t.deploymentPath, localComposeFileName)):
+		defaultL1, defaultL2, defaultOpNode = t.deployConfig.L1RPCURL, localL2RPCURL(), "http://localhost:9545"
+	default:
+		defaultL1, defaultL2 = t.deployConfig.L1RPCURL, t.deployConfig.L2RpcUrl
+		if opNodeURL == "" {
+			if t.deployConfig.K8s == nil {
+				return "", "", "", nil, fmt.Errorf("K8s configuration is not set. Pass --op-node-url or run the deploy command first")
+			}
+			address, stopForward, err := t.forwardOpNodeRPC(ctx, t.deployConfig.K8s.Namespace)
+			if err != nil {
+				return "", "", "", nil, err
+			}
+			defaultOpNode, stop = "http://"+address, stopForward
+		}
+	}
+
+	if l1URL == "" {
+		l1URL = defaultL1
+	}
+	if l2URL == "" {
+		l2URL = defaultL2
+	}
+	if opNodeURL == "" {
+		opNodeURL = defaultOpNode
+	}
+	if l1URL == "" || l2URL == "" {
+		stop()
+		return "", "", "", nil, fmt.Errorf("the L1 or L2 RPC URL is unknown. Pass --l1-rpc-url and --l2-rpc-url")
+	}
+	return l1URL, l2URL, opNodeURL, stop, nil
+}
+
+// forwardOpNodeRPC port-forwards the RPC port of the op-node service in the chain namespace
+func (t *ThanosStack) forwardOpNodeRPC(ctx context.Context, namespace string) (string, func(), error) {
+	return forwardServiceRPC(ctx, namespace, "op-node")
+}
+
+// forwardServiceRPC port-forwards the port named rpc, or else the first port, of the component service
+func forwardServiceRPC(ctx context.Context, namespace, component string) (string, func(), error) {
+	services, err := utils.GetServiceNames(ctx, namespace, component)
+	if err != nil || len(services) == 0 {
+		return "", nil, fmt.Errorf("%s service not found in namespace %s", component, namespace)
+	}
+	portOutput, err := utils.ExecuteCommand(ctx, "kubectl", "-n", namespace, "get", "svc", services[0],
+		"-o", `jsonpath={.spec.ports[?(@.name=="rpc")].port} {.spec.ports[0].port}`)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read the %s service ports: %w", component, err)
+	}
+	fields := strings.Fields(portOutput)
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("%s service %s exposes no ports", component, services[0])
+	}
+	var port int
+	if _, err := fmt.Sscanf(fields[0], "%d", &port); err != nil {
+		return "", nil, fmt.Errorf("invalid %s service port %q", component, fields[0])
+	}
+	return utils.PortForward(ctx, namespace, "svc/"+services[0], port)
+}
+
+// evaluateHeadLags checks that the unsafe head is recent and that the safe and finalized heads follow it
+func evaluateHeadLags(status *types.RollupSyncStatus, now time.Time, thresholds types.HealthThresholds) []types.HealthCheck {
+	unsafeTime := time.Unix(int64(status.UnsafeL2.Timestamp), 0)
+	safeLag := time.Duration(status.UnsafeL2.Timestamp-min(status.SafeL2.Timestamp, status.UnsafeL2.Timestamp)) * time.Second
+	finalizedLag := time.Duration(status.UnsafeL2.Timestamp-min(status.FinalizedL2.Timestamp, status.UnsafeL2.Timestamp)) * time.Second
+
+	checks := []types.HealthCheck{
+		durationCheck("unsafe_head_age", now.Sub(unsafeTime), thresholds.UnsafeHeadAge,
+			fmt.Sprintf("unsafe head #%d", status.UnsafeL2.Number)),
+		durationCheck("safe_head_lag", safeLag, thresholds.SafeHeadLag,
+			fmt.Sprintf("safe head #%d, %d blocks behind", status.SafeL2.Number, status.UnsafeL2.Number-min(status.SafeL2.Number, status.UnsafeL2.Number))),
+		durationCheck("finalized_head_lag", finalizedLag, thresholds.FinalizedHeadLag,
+			fmt.Sprintf("finalized head #%d, %d blocks behind", status.FinalizedL2.Number, status.UnsafeL2.Number-min(status.FinalizedL2.Number, status.UnsafeL2.Number))),
+	}
+	// op-node derives from current_l1 and lags the L1 head while it catches up
+	return append(checks, blockDriftCheck("l1_derivation_lag", status.HeadL1.Number, status.CurrentL1.Number, 2*thresholds.L1HeadDrift, "op-node derivation behind its L1 head"))
+}
+
+// durationCheck is a warning above the threshold and critical above twice the threshold
+func durationCheck(name string, value, threshold time.Duration, message string) types.HealthCheck {
+	if value < 0 {
+		value = 0
+	}
+	return types.HealthCheck{
+		Name:      name,
+		Status:    thresholdStatus(float64(value), float64(threshold)),
+		Value:     value.Round(time.Second).String(),
+		Threshold: threshold.String(),
+		Message:   message,
+	}
+}
+
+func blockDriftCheck(name string, ahead, behind, threshold uint64, message string) types.HealthCheck {
+	var drift uint64
+	if ahead > behind {
+		drift = ahead - behind
+	}
+	return types.HealthCheck{
+		Name:      name,
+		Status:    thresholdStatus(float64(drift), float64(threshold)),
+		Value:     fmt.Sprintf("%d blocks", drift),
+		Threshold: fmt.Sprintf("%d blocks", threshold),
+		Message:   message,
+	}
+}
+
+func thresholdStatus(value, threshold float64) types.HealthStatus {
+	switch {
+	case threshold <= 0 || value <= threshold:
+		return types.HealthOK
+	case value <= 2*threshold:
+		return types.HealthWarning
+	default:
+		return types.HealthCritical
+	}
+}
+
+// peerChecks reports the op-node and op-geth peer counts. Single-sequencer chains run without P2P, so a
+// disabled P2P stack is only a problem when a minimum peer count is required.
+func (t *ThanosStack) peerChecks(ctx context.Context, opNode *rpc.Client, l2 *ethclient.Client, minPeers uint64) []types.HealthCheck {
+	peerCheck := func(name string, peers uint64, err error) types.HealthCheck {
+		check := types.HealthCheck{Name: name, Status: types.HealthOK, Threshold: fmt.Sprintf(">= %d", minPeers)}
+		switch {
+		case err != nil && minPeers == 0:
+			check.Value = "n/a"
+			check.Message = "P2P disabled or peer RPC not exposed"
+		case err != nil:
+			check.Status = types.HealthUnknown
+			check.Value = "n/a"
+			check.Message = err.Error()
+		default:
+			check.Value = fmt.Sprintf("%d", peers)
+			if peers < minPeers {
+				check.Status = types.HealthWarning
+				if peers == 0 {
+					check.Status = types.HealthCritical
+				}
+			}
+		}
+		return check
+	}
+
+	var peerStats struct {
+		Connected uint64 `json:"connected"`
+	}
+	err := opNode.CallContext(ctx, &peerStats, "opp2p_peerStats")
+	checks := []types.HealthCheck{peerCheck("op_node_peers", peerStats.Connected, err)}
+
+	var gethPeers uint64
+	if l2 == nil {
+		err = fmt.Errorf("L2 RPC unreachable")
+	} else {
+		gethPeers, err = l2.PeerCount(ctx)
+	}
+	return append(checks, peerCheck("op_geth_peers", gethPeers, err))
+}
+
+// batchSubmissionCheck reports the time since the last batcher transaction on L1. The block of a transaction
+// in the recent state window is found from the batcher nonce. An older transaction is only known to precede the
+// first time the current nonce was observed, which is kept in statePath across checks.
+func batchSubmissionCheck(ctx context.Context, l1 *ethclient.Client, batcher, inbox common.Address, l1Head uint64, now time.Time, threshold time.Duration, statePath string) types.HealthCheck {
+	check := types.HealthCheck{Name: "batch_submission", Threshold: threshold.String()}
+	if batcher == (common.Address{}) {
+		check.Status = types.HealthUnknown
+		check.Message = "batcher address missing from the rollup config"
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthRPCTimeout)
+	defer cancel()
+
+	nonce, err := l1.NonceAt(ctx, batcher, new(big.Int).SetUint64(l1Head))
+	if err != nil {
+		check.Status = types.HealthUnknown
+		check.Message = fmt.Sprintf("failed to read the batcher nonce: %v", err)
+		return check
+	}
+	if nonce == 0 {
+		check.Status = types.HealthCritical
+		check.Value = "none"
+		check.Message = fmt.Sprintf("no transaction from batcher %s", batcher.Hex())
+		return check
+	}
+
+	block, found, err := lastNonceChangeBlock(func(number uint64) (uint64, error) {
+		return l1.NonceAt(ctx, batcher, new(big.Int).SetUint64(number))
+	}, l1Head, recentStateBlocks)
+	if err != nil {
+		check.Status = types.HealthUnknown
+		check.Message = fmt.Sprintf("failed to read the recent batcher nonces: %v", err)
+		return check
+	}
+	if found {
+		l1Block, err := l1.BlockByNumber(ctx, new(big.Int).SetUint64(block))
+		if err != nil {
+			check.Status = types.HealthUnknown
+			check.Message = fmt.Sprintf("failed to read L1 block %d: %v", block, err)
+			return check
+		}
+		message := fmt.Sprintf("last batch in L1 block #%d", block)
+		toInbox := false
+		for _, tx := range l1Block.Transactions() {
+			toInbox = toInbox || (tx.To() != nil && *tx.To() == inbox)
+		}
+		if !toInbox {
+			message = fmt.Sprintf("last batcher transaction in L1 block #%d was not sent to the batch inbox %s", block, inbox.Hex())
+		}
+		return durationCheck(check.Name, now.Sub(time.Unix(int64(l1Block.Time()), 0)), threshold, message)
+	}
+
+	// The last transaction precedes the oldest block of the window
+	var oldest uint64
+	if l1Head > recentStateBlocks {
+		oldest = l1Head - recentStateBlocks
+	}
+	header, err := l1.HeaderByNumber(ctx, new(big.Int).SetUint64(oldest))
+	if err != nil {
+		check.Status = types.HealthUnknown
+		check.Message = fmt.Sprintf("failed to read L1 block %d: %v", oldest, err)
+		return check
+	}
+	observation, err := observeBatcherNonce(statePath, batcher, nonce, time.Unix(int64(header.Time), 0))
+	if err != nil {
+		check.Status = types.HealthUnknown
+		check.Message = err.Error()
+		return check
+	}
+	check = durationCheck(check.Name, now.Sub(observation.LastBatchBefore), threshold,
+		fmt.Sprintf("no batch in the last %d L1 blocks, the last one was sent before %s", recentStateBlocks, observation.LastBatchBefore.UTC().Format(time.RFC3339)))
+	check.Value = "> " + check.Value
+	return check
+}
+
+// observeBatcherNonce returns the stored observation of the batcher nonce, replacing it when the nonce changed.
+// before is a time the last batcher transaction is known to precede.
+func observeBatcherNonce(statePath string, batcher common.Address, nonce uint64, before time.Time) (*types.BatcherNonceObservation, error) {
+	var observation types.BatcherNonceObservation
+	if data, err := os.ReadFile(statePath); err == nil {
+		if err := json.Unmarshal(data, &observation); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", statePath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", statePath, err)
+	}
+	if observation.Batcher == batcher.Hex() && observation.Nonce == nonce && !observation.LastBatchBefore.After(before) {
+		return &observation, nil
+	}
+
+	observation = types.BatcherNonceObservation{Batcher: batcher.Hex(), Nonce: nonce, LastBatchBefore: before.UTC()}
+	data, err := json.MarshalIndent(observation, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(statePath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", statePath, err)
+	}
+	return &observation, nil
+}
+
+// lastNonceChangeBlock binary searches the last block in (head-window, head] where the account nonce
+// changed, which is the block of its last transaction
+func lastNonceChangeBlock(nonceAt func(uint64) (uint64, error), head, window uint64) (uint64, bool, error) {
+	current, err := nonceAt(head)
+	if err != nil {
+		return 0, false, err
+	}
+	low := uint64(0)
+	if head > window {
+		low = head - window
+	}
+	lowNonce, err := nonceAt(low)
+	if err != nil {
+		return 0, false, err
+	}
+	if lowNonce == current {
+		return 0, false, nil
+	}
+
+	// Invariant: nonceAt(low) < current and nonceAt(high) == current
+	high := head
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		nonce, err := nonceAt(mid)
+		if err != nil {
+			return 0, false, err
+		}
+		if nonce == current {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+	return high, true, nil
+}
+
+// outputSubmissionCheck reports the time since the last dispute game (fault proofs) or L2 output
+func (t *ThanosStack) outputSubmissionCheck(ctx context.Context, l1 *ethclient.Client, now time.Time, threshold time.Duration) types.HealthCheck {
+	check := types.HealthCheck{Name: "output_submission", Threshold: threshold.String()}
+	ctx, cancel := context.WithTimeout(ctx, healthRPCTimeout)
+	defer cancel()
+
+	contracts, err := utils.ReadDeployementConfigFromJSONFile(t.deploymentPath, t.deployConfig.L1ChainID)
+	if err != nil {
+		check.Status = types.HealthUnknown
+		check.Message = fmt.Sprintf("failed to read the deployed contracts: %v", err)
+		return check
+	}
+
+	var submitted uint64
+	var what string
+	if t.deployConfig.EnableFraudProof {
+		what = "dispute game"
+		submitted, err = lastDisputeGameTimestamp(ctx, l1, common.HexToAddress(contracts.DisputeGameFactoryProxy))
+	} else {
+		what = "L2 output"
+		submitted, err = lastL2OutputTimestamp(ctx, l1, common.HexToAddress(contracts.L2OutputOracleProxy))
+	}
+	if err != nil {
+		check.Status = types.HealthUnknown
+		check.Message = fmt.Sprintf("failed to read the last %s: %v", what, err)
+		return check
+	}
+	if submitted == 0 {
+		check.Status = types.HealthWarning
+		check.Value = "none"
+		check.Message = fmt.Sprintf("no %s submitted yet", what)
+		return check
+	}
+	return durationCheck(check.Name, now.Sub(time.Unix(int64(submitted), 0)), threshold, "last "+what)
+}
+
+// lastDisputeGameTimestamp returns the creation time of the newest game of the DisputeGameFactory
+func lastDisputeGameTimestamp(ctx context.Context, l1 *ethclient.Client, factory common.Address) (uint64, error) {
+	count, err := callUint256(ctx, l1, factory, "gameCount()")
+	if err != nil {
+		return 0, err
+	}
+	if count.Sign() == 0 {
+		return 0, nil
+	}
+	// gameAtIndex returns (GameType gameType, Timestamp timestamp, IDisputeGame proxy)
+	words, err := callWords(ctx, l1, factory, "gameAtIndex(uint256)", new(big.Int).Sub(count, big.NewInt(1)))
+	if err != nil {
+		return 0, err
+	}
+	if len(words) < 2 {
+		return 0, fmt.Errorf("unexpected gameAtIndex response")
+	}
+	return words[1].Uint64(), nil
+}
+
+// lastL2OutputTimestamp returns the L1 time of the newest output of the L2OutputOracle
+func lastL2OutputTimestamp(ctx context.Context, l1 *ethclient.Client, oracle common.Address) (uint64, error) {
+	// latestOutputIndex reverts while no output has been proposed
+	index, err := callUint256(ctx, l1, oracle, "latestOutputIndex()")
+	if err != nil {
+		if strings.Contains(err.Error(), "revert") {
+			return 0, nil
+		}
+		return 0, err
+	}
+	// getL2Output returns OutputProposal(bytes32 outputRoot, uint128 timestamp, uint128 l2BlockNumber)
+	words, err := callWords(ctx, l1, oracle, "getL2Output(uint256)", index)
+	if err != nil {
+		return 0, err
+	}
+	if len(words) < 2 {
+		return 0, fmt.Errorf("unexpected getL2Output response")
+	}
+	return words[1].Uint64(), nil
+}
+
+func callUint256(ctx context.Context, client *ethclient.Client, contract common.Address, signature string) (*big.Int, error) {
+	words, err := callWords(ctx, client, contract, signature)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty response from %s", signature)
+	}
+	return words[0], nil
+}
+
+// callWords calls a view function with uint256 arguments and splits the static return data into words
+func callWords(ctx context.Context, client *ethclient.Client, contract common.Address, signature string, args ...*big.Int) ([]*big.Int, error) {
+	data := crypto.Keccak256([]byte(signature))[:4]
+	for _, arg := range args {
+		data = append(data, common.LeftPadBytes(arg.Bytes(), 32)...)
+	}
+	output, err := client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	words := make([]*big.Int, 0, len(output)/32)
+	for i := 0; i+32 <= len(output); i += 32 {
+		words = append(words, new(big.Int).SetBytes(output[i:i+32]))
+	}
+	return words, nil
+}
@@ -0,0 +1,165 @@
+package thanos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+func TestEvaluateHeadLags(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	thresholds := types.HealthThresholds{UnsafeHeadAge: time.Minute, SafeHeadLag: 30 * time.Minute, FinalizedHeadLag: time.Hour, L1HeadDrift: 10}
+	status := &types.RollupSyncStatus{
+		HeadL1:      types.BlockRef{Number: 1000},
+		CurrentL1:   types.BlockRef{Number: 995},
+		UnsafeL2:    types.BlockRef{Number: 5000, Timestamp: uint64(now.Unix()) - 90},
+		SafeL2:      types.BlockRef{Number: 4000, Timestamp: uint64(now.Unix()) - 90 - 2000},
+		FinalizedL2: types.BlockRef{Number: 1000, Timestamp: uint64(now.Unix()) - 90 - 8000},
+	}
+
+	checks := evaluateHeadLags(status, now, thresholds)
+	byName := map[string]types.HealthCheck{}
+	for _, check := range checks {
+		byName[check.Name] = check
+	}
+	require.Equal(t, types.HealthWarning, byName["unsafe_head_age"].Status, "90s is above 1m and below 2m")
+	require.Equal(t, "1m30s", byName["unsafe_head_age"].Value)
+	require.Equal(t, types.HealthWarning, byName["safe_head_lag"].Status)
+	require.Contains(t, byName["safe_head_lag"].Message, "1000 blocks behind")
+	require.Equal(t, types.HealthCritical, byName["finalized_head_lag"].Status)
+	require.Equal(t, types.HealthOK, byName["l1_derivation_lag"].Status)
+
+	// A safe head ahead of the unsafe head timestamp never underflows
+	status.SafeL2.Timestamp = status.UnsafeL2.Timestamp + 10
+	require.Equal(t, "0s", evaluateHeadLags(status, now, thresholds)[1].Value)
+}
+
+func TestLastNonceChangeBlock(t *testing.T) {
+	// The account sent transactions in blocks 100, 250 and 731
+	nonceAt := func(block uint64) (uint64, error) {
+		nonce := uint64(0)
+		for _, txBlock := range []uint64{100, 250, 731} {
+			if block >= txBlock {
+				nonce++
+			}
+		}
+		return nonce, nil
+	}
+
+	block, found, err := lastNonceChangeBlock(nonceAt, 1000, 500)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(731), block)
+
+	_, found, err = lastNonceChangeBlock(nonceAt, 1000, 200)
+	require.NoError(t, err)
+	require.False(t, found, "no transaction in the last 200 blocks")
+
+	block, found, err = lastNonceChangeBlock(nonceAt, 300, 5000)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(250), block)
+
+	_, _, err = lastNonceChangeBlock(func(uint64) (uint64, error) { return 0, fmt.Errorf("missing trie node") }, 1000, 10)
+	require.ErrorContains(t, err, "missing trie node")
+}
+
+func TestObserveBatcherNonce(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), HealthStateFileName)
+	batcher := common.HexToAddress("0xba7c4e5")
+	first := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	observation, err := observeBatcherNonce(statePath, batcher, 7, first)
+	require.NoError(t, err)
+	require.Equal(t, first, observation.LastBatchBefore)
+
+	// The same nonce in a later window keeps the first observation, so the reported age grows
+	observation, err = observeBatcherNonce(statePath, batcher, 7, first.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, first, observation.LastBatchBefore)
+
+	// A new batch resets it
+	observation, err = observeBatcherNonce(statePath, batcher, 8, first.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), observation.Nonce)
+	require.Equal(t, first.Add(2*time.Hour), observation.LastBatchBefore)
+}
+
+func TestDefaultHealthThresholds(t *testing.T) {
+	sepolia := DefaultHealthThresholds(constants.EthereumSepoliaChainID)
+	require.Equal(t, 34*time.Minute, sepolia.BatchSubmission, "120 L1 blocks of 12s plus a 10m margin")
+	require.Equal(t, 40*time.Minute, sepolia.OutputSubmission)
+
+	mainnet := DefaultHealthThresholds(constants.EthereumMainnetChainID)
+	require.Equal(t, 5*time.Hour+10*time.Minute, mainnet.BatchSubmission)
+	require.Equal(t, 6*time.Hour+10*time.Minute, mainnet.OutputSubmission)
+
+	merged := withHealthDefaults(types.HealthThresholds{UnsafeHeadAge: 30 * time.Second}, sepolia)
+	require.Equal(t, 30*time.Second, merged.UnsafeHeadAge)
+	require.Equal(t, sepolia.SafeHeadLag, merged.SafeHeadLag)
+
+	require.Equal(t, 2, types.HealthCritical.ExitCode())
+	require.Greater(t, types.HealthWarning.Severity(), types.HealthUnknown.Severity())
+}
+
+func TestCheckHealth(t *testing.T) {
+	now := uint64(time.Now().Unix())
+	results := map[string]interface{}{
+		"optimism_syncStatus": map[string]interface{}{
+			"head_l1":      map[string]interface{}{"number": 500},
+			"current_l1":   map[string]interface{}{"number": 498},
+			"unsafe_l2":    map[string]interface{}{"number": 500, "timestamp": now},
+			"safe_l2":      map[string]interface{}{"number": 450, "timestamp": now - 100},
+			"finalized_l2": map[string]interface{}{"number": 400, "timestamp": now - 200},
+		},
+		"eth_blockNumber": "0x1f4",
+		"net_peerCount":   "0x0",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if result, ok := results[req.Method]; ok {
+			response["result"] = result
+		} else {
+			response["error"] = map[string]interface{}{"code": -32601, "message": "the method " + req.Method + " does not exist"}
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	stack := &ThanosStack{deploymentPath: t.TempDir(), deployConfig: &types.Config{L1ChainID: constants.EthereumSepoliaChainID}}
+	report, err := stack.CheckHealth(context.Background(), &types.HealthCheckInput{L1RPCURL: server.URL, L2RPCURL: server.URL, OpNodeRPCURL: server.URL})
+	require.NoError(t, err)
+	require.Equal(t, uint64(450), report.SyncStatus.SafeL2.Number)
+
+	statuses := map[string]types.HealthStatus{}
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	require.Equal(t, types.HealthOK, statuses["unsafe_head_age"])
+	require.Equal(t, types.HealthOK, statuses["l2_engine_drift"])
+	require.Equal(t, types.HealthOK, statuses["op_node_peers"], "P2P is optional without --min-peers")
+	require.Equal(t, types.HealthUnknown, statuses["batch_submission"], "the fake op-node has no rollup config")
+	require.Equal(t, types.HealthUnknown, statuses["output_submission"], "no deployed contracts")
+	require.Equal(t, types.HealthUnknown, report.Status)
+
+	// An unreachable op-node is critical
+	report, err = stack.CheckHealth(context.Background(), &types.HealthCheckInput{L1RPCURL: server.URL, L2RPCURL: server.URL, OpNodeRPCURL: "http://127.0.0.1:1"})
+	require.NoError(t, err)
+	require.Equal(t, types.HealthCritical, report.Status)
+}
@@ -237,7 +237,10 @@ func (i *InstallMonitoringInput) Validate() error {
 		}
 	}
 
-	return nil
+	if err := validateRoutedChannels(i.AlertManager); err != nil {
+		return err
+	}
+	return validateAlertRoutes(i.AlertManager.Routes, enabledRoutedChannels(i.AlertManager))
 }
 
 func (c *InstallBlockExplorerInput) Validate(ctx context.Context) error {
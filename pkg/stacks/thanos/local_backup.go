@@ -0,0 +1,394 @@
+package thanos
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tokamak-network/trh-sdk/pkg/scanner"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+const (
+	// LocalBackupDirName is the directory of the local backups in the deployment path
+	LocalBackupDirName      = "backups"
+	localBackupManifestName = "manifest.json"
+	// localBackupStopTimeout is the time op-geth gets to flush its database before it is killed
+	localBackupStopTimeout = 120
+)
+
+// IsLocalDeployment reports whether the deployment path holds a local docker compose deployment
+func (t *ThanosStack) IsLocalDeployment() bool {
+	return HasLocalDeployment(t.deploymentPath)
+}
+
+// HasLocalDeployment reports whether deploymentPath holds a local docker compose deployment. Local
+// deployments are saved with the testnet or mainnet network, so the compose file identifies them.
+func HasLocalDeployment(deploymentPath string) bool {
+	return utils.CheckFileExists(filepath.Join(deploymentPath, localComposeFileName))
+}
+
+// LocalBackupSnapshot archives the docker volumes of the local deployment into backups/<id>.
+// The containers are stopped while the volumes are archived, so op-geth and the databases are consistent.
+func (t *ThanosStack) LocalBackupSnapshot(ctx context.Context) (*types.LocalBackupManifest, error) {
+	volumes, err := t.localBackupVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(volumes) == 0 {
+		return nil, fmt.Errorf("no docker volumes found for the local deployment in %s", t.deploymentPath)
+	}
+
+	now := time.Now().UTC()
+	manifest := &types.LocalBackupManifest{ID: now.Format("20060102-150405"), CreatedAt: now}
+	if t.deployConfig != nil {
+		manifest.ChainName = t.deployConfig.ChainName
+		manifest.L2ChainID = t.deployConfig.L2ChainID
+	}
+	manifest.GenesisHash, _ = readGenesisHashFromVolume(ctx, filepath.Base(t.deploymentPath)+"_op-geth-data")
+	if manifest.GenesisHash == "" {
+		manifest.GenesisHash, _ = hashFile(t.genesisConfigPath())
+	}
+
+	dir := filepath.Join(t.deploymentPath, LocalBackupDirName, manifest.ID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	containers, err := t.stopLocalContainers(ctx)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	manifest.Quiesced = true
+	defer t.startLocalContainers(ctx, containers)
+
+	for _, volume := range volumes {
+		volume.File = volume.Name + ".tar.gz"
+		t.logger.Infof("📦 Archiving volume %s...", volume.Name)
+		volume.Size, volume.SHA256, err = archiveLocalVolume(ctx, volume.Name, filepath.Join(dir, volume.File))
+		if err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, err
+		}
+		manifest.Volumes = append(manifest.Volumes, volume)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, localBackupManifestName), data, 0600); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+
+	t.logger.Infof("✅ Local backup %s created with %d volumes (%s)", manifest.ID, len(manifest.Volumes), formatBackupSize(localBackupSize(manifest)))
+	t.logger.Infof("   Location: %s", dir)
+	return manifest, nil
+}
+
+// LocalBackupList prints the local backups, newest first
+func (t *ThanosStack) LocalBackupList(ctx context.Context, limit string) ([]types.LocalBackupManifest, error) {
+	backups, err := listLocalBackups(filepath.Join(t.deploymentPath, LocalBackupDirName))
+	if err != nil {
+		return nil, err
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(limit)); err == nil && n > 0 && n < len(backups) {
+		backups = backups[:n]
+	}
+
+	if len(backups) == 0 {
+		t.logger.Infof("")
+		t.logger.Infof("⚠️  No local backups found. Create one with: trh-sdk backup-manager --snapshot")
+		t.logger.Infof("")
+		return nil, nil
+	}
+	t.logger.Infof("")
+	t.logger.Infof("📦 Local Backups (%d)", len(backups))
+	t.logger.Infof("")
+	for idx, b := range backups {
+		t.logger.Infof("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		t.logger.Infof("#%-2d", idx+1)
+		t.logger.Infof("    🔑 ID       : %s", b.ID)
+		t.logger.Infof("    📅 Created  : %s", b.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+		t.logger.Infof("    ⛓️  Chain ID : %d", b.L2ChainID)
+		t.logger.Infof("    🧬 Genesis  : %s", b.GenesisHash)
+		t.logger.Infof("    💾 Volumes  : %d (%s)", len(b.Volumes), formatBackupSize(localBackupSize(&b)))
+	}
+	t.logger.Infof("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	return backups, nil
+}
+
+// LocalBackupRestore restores the docker volumes of a local backup after checking its checksums and
+// that it belongs to the chain ID and genesis of the deployment
+func (t *ThanosStack) LocalBackupRestore(ctx context.Context, id string) (*types.LocalBackupManifest, error) {
+	dir := filepath.Join(t.deploymentPath, LocalBackupDirName, id)
+	manifest, err := readLocalBackupManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.deployConfig != nil && t.deployConfig.L2ChainID != 0 && manifest.L2ChainID != 0 && t.deployConfig.L2ChainID != manifest.L2ChainID {
+		return nil, fmt.Errorf("backup %s belongs to chain ID %d, the deployment is chain ID %d", id, manifest.L2ChainID, t.deployConfig.L2ChainID)
+	}
+	if current, err := hashFile(t.genesisConfigPath()); err == nil && manifest.GenesisHash != "" && current != manifest.GenesisHash {
+		return nil, fmt.Errorf("backup %s was taken with a different genesis.json (%s, current %s)", id, manifest.GenesisHash, current)
+	}
+
+	t.logger.Infof("🔍 Verifying the checksums of backup %s...", id)
+	if err := verifyLocalBackup(dir, manifest); err != nil {
+		return nil, err
+	}
+
+	containers, err := t.stopLocalContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.startLocalContainers(ctx, containers)
+
+	project := filepath.Base(t.deploymentPath)
+	for _, volume := range manifest.Volumes {
+		t.logger.Infof("♻️  Restoring volume %s...", volume.Name)
+		if err := restoreLocalVolume(ctx, project, volume, filepath.Join(dir, volume.File)); err != nil {
+			return nil, err
+		}
+	}
+	t.logger.Infof("✅ Local backup %s restored", id)
+	return manifest, nil
+}
+
+// LocalBackupRestoreInteractive lets the user pick the local backup to restore
+func (t *ThanosStack) LocalBackupRestoreInteractive(ctx context.Context) error {
+	backups, err := t.LocalBackupList(ctx, "")
+	if err != nil || len(backups) == 0 {
+		return err
+	}
+	fmt.Printf("Enter the number of the backup to restore (1-%d): ", len(backups))
+	n, err := scanner.ScanInt()
+	if err != nil {
+		return err
+	}
+	if n < 1 || n > len(backups) {
+		return fmt.Errorf("invalid selection: %d", n)
+	}
+	fmt.Printf("The chain will be stopped and its volumes replaced by backup %s. Continue? (y/N): ", backups[n-1].ID)
+	ok, err := scanner.ScanBool(false)
+	if err != nil || !ok {
+		return err
+	}
+	_, err = t.LocalBackupRestore(ctx, backups[n-1].ID)
+	return err
+}
+
+// localBackupVolumes lists the volumes of the compose project and the external config volumes
+func (t *ThanosStack) localBackupVolumes(ctx context.Context) ([]types.LocalBackupVolume, error) {
+	output, err := utils.ExecuteCommand(ctx, "docker", "volume", "ls",
+		"--filter", "label=com.docker.compose.project="+filepath.Base(t.deploymentPath),
+		"--format", `{{.Name}}	{{.Label "com.docker.compose.volume"}}`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list docker volumes: %s: %w", output, err)
+	}
+	var volumes []types.LocalBackupVolume
+	for _, line := range strings.Split(output, "\n") {
+		name, composeVolume, _ := strings.Cut(strings.TrimSpace(line), "\t")
+		if name != "" {
+			volumes = append(volumes, types.LocalBackupVolume{Name: name, ComposeVolume: composeVolume})
+		}
+	}
+	for _, name := range []string{localConfigVolume, localMonitoringVolume} {
+		if volumeExists(ctx, name) {
+			volumes = append(volumes, types.LocalBackupVolume{Name: name})
+		}
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
+	return volumes, nil
+}
+
+// stopLocalContainers stops the running containers of the deployment and returns their names
+func (t *ThanosStack) stopLocalContainers(ctx context.Context) ([]string, error) {
+	deploymentPath, err := filepath.Abs(t.deploymentPath)
+	if err != nil {
+		return nil, err
+	}
+	output, err := utils.ExecuteCommand(ctx, "docker", "ps",
+		"--filter", "label=com.docker.compose.project.working_dir="+deploymentPath,
+		"--format", "{{.Names}}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %s: %w", output, err)
+	}
+	containers := strings.Fields(output)
+	if len(containers) == 0 {
+		return nil, nil
+	}
+	t.logger.Infof("⏸️  Stopping %d containers for a consistent copy...", len(containers))
+	args := append([]string{"stop", "--time", strconv.Itoa(localBackupStopTimeout)}, containers...)
+	if output, err := utils.ExecuteCommand(ctx, "docker", args...); err != nil {
+		t.startLocalContainers(ctx, containers)
+		return nil, fmt.Errorf("failed to stop containers: %s: %w", output, err)
+	}
+	return containers, nil
+}
+
+// startLocalContainers starts the containers stopped by stopLocalContainers, also when ctx was cancelled
+func (t *ThanosStack) startLocalContainers(ctx context.Context, containers []string) {
+	if len(containers) == 0 {
+		return
+	}
+	t.logger.Infof("▶️  Starting %d containers...", len(containers))
+	args := append([]string{"start"}, containers...)
+	if output, err := utils.ExecuteCommand(context.WithoutCancel(ctx), "docker", args...); err != nil {
+		t.logger.Errorf("Failed to start containers, start them with `docker start %s`: %s: %v", strings.Join(containers, " "), output, err)
+	}
+}
+
+// archiveLocalVolume writes a gzipped tarball of a docker volume to path and returns its size and sha256
+func archiveLocalVolume(ctx context.Context, volume, path string) (int64, string, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", "run", "--rm", "-v", volume+":/data:ro", "alpine", "tar", "czf", "-", "-C", "/data", ".")
+	cmd.Stdout = io.MultiWriter(file, hash)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return 0, "", fmt.Errorf("failed to archive volume %s: %s: %w", volume, strings.TrimSpace(stderr.String()), err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return 0, "", err
+	}
+	return info.Size(), hex.EncodeToString(hash.Sum(nil)), file.Close()
+}
+
+// restoreLocalVolume replaces the content of a docker volume with an archive, creating the volume if needed
+func restoreLocalVolume(ctx context.Context, project string, volume types.LocalBackupVolume, path string) error {
+	if err := ensureLocalVolume(ctx, project, volume.ComposeVolume, volume.Name); err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", "run", "--rm", "-i", "-v", volume.Name+":/data", "alpine",
+		"sh", "-c", "find /data -mindepth 1 -delete && tar xzf - -C /data")
+	cmd.Stdin = file
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to restore volume %s: %s: %w", volume.Name, strings.TrimSpace(stderr.String()), err)
+	}
+	return nil
+}
+
+// ensureLocalVolume creates a docker volume when it does not exist, labelled as a volume of the compose
+// project unless composeVolume is empty
+func ensureLocalVolume(ctx context.Context, project, composeVolume, name string) error {
+	if volumeExists(ctx, name) {
+		return nil
+	}
+	args := []string{"volume", "create"}
+	if composeVolume != "" {
+		args = append(args, "--label", "com.docker.compose.project="+project, "--label", "com.docker.compose.volume="+composeVolume)
+	}
+	if output, err := utils.ExecuteCommand(ctx, "docker", append(args, name)...); err != nil {
+		return fmt.Errorf("failed to create volume %s: %s: %w", name, output, err)
+	}
+	return nil
+}
+
+// listLocalBackups reads the manifests of the backups in dir, newest first. Directories without a
+// manifest are incomplete backups and skipped.
+func listLocalBackups(dir string) ([]types.LocalBackupManifest, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var backups []types.LocalBackupManifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		manifest, err := readLocalBackupManifest(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, *manifest)
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, nil
+}
+
+func readLocalBackupManifest(dir string) (*types.LocalBackupManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, localBackupManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup manifest: %w", err)
+	}
+	var manifest types.LocalBackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest %s: %w", dir, err)
+	}
+	return &manifest, nil
+}
+
+// verifyLocalBackup checks the size and sha256 of every volume archive against the manifest
+func verifyLocalBackup(dir string, manifest *types.LocalBackupManifest) error {
+	for _, volume := range manifest.Volumes {
+		file, err := os.Open(filepath.Join(dir, volume.File))
+		if err != nil {
+			return fmt.Errorf("archive of volume %s is missing: %w", volume.Name, err)
+		}
+		hash := sha256.New()
+		size, err := io.Copy(hash, file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		if size != volume.Size || hex.EncodeToString(hash.Sum(nil)) != volume.SHA256 {
+			return fmt.Errorf("archive of volume %s is corrupted: checksum mismatch", volume.Name)
+		}
+	}
+	return nil
+}
+
+func localBackupSize(manifest *types.LocalBackupManifest) int64 {
+	var size int64
+	for _, volume := range manifest.Volumes {
+		size += volume.Size
+	}
+	return size
+}
+
+func formatBackupSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,71 @@
+package thanos
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+func writeTestLocalBackup(t *testing.T, dir string, manifest types.LocalBackupManifest, archives map[string]string) {
+	backupDir := filepath.Join(dir, LocalBackupDirName, manifest.ID)
+	require.NoError(t, os.MkdirAll(backupDir, 0700))
+	for name, content := range archives {
+		require.NoError(t, os.WriteFile(filepath.Join(backupDir, name+".tar.gz"), []byte(content), 0600))
+		sum := sha256.Sum256([]byte(content))
+		manifest.Volumes = append(manifest.Volumes, types.LocalBackupVolume{
+			Name: name, File: name + ".tar.gz", Size: int64(len(content)), SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(backupDir, localBackupManifestName), data, 0600))
+}
+
+func TestLocalBackups(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "genesis.json"), []byte(`{"config":{}}`), 0644))
+	genesisHash, err := hashFile(filepath.Join(dir, "genesis.json"))
+	require.NoError(t, err)
+
+	created := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	writeTestLocalBackup(t, dir, types.LocalBackupManifest{ID: "older", CreatedAt: created, L2ChainID: 1001, GenesisHash: genesisHash},
+		map[string]string{"chain_op-geth-data": "geth", "chain_blockscout-db-data": "db"})
+	writeTestLocalBackup(t, dir, types.LocalBackupManifest{ID: "newer", CreatedAt: created.Add(time.Hour), L2ChainID: 2002, GenesisHash: genesisHash},
+		map[string]string{"chain_op-geth-data": "geth"})
+	writeTestLocalBackup(t, dir, types.LocalBackupManifest{ID: "other-genesis", CreatedAt: created, L2ChainID: 1001, GenesisHash: "abc"}, nil)
+	// An interrupted snapshot has no manifest
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, LocalBackupDirName, "interrupted"), 0700))
+
+	backups, err := listLocalBackups(filepath.Join(dir, LocalBackupDirName))
+	require.NoError(t, err)
+	require.Len(t, backups, 3)
+	require.Equal(t, "newer", backups[0].ID)
+
+	older, err := readLocalBackupManifest(filepath.Join(dir, LocalBackupDirName, "older"))
+	require.NoError(t, err)
+	require.NoError(t, verifyLocalBackup(filepath.Join(dir, LocalBackupDirName, "older"), older))
+	require.Equal(t, int64(6), localBackupSize(older))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, LocalBackupDirName, "older", "chain_blockscout-db-data.tar.gz"), []byte("dB"), 0600))
+	require.ErrorContains(t, verifyLocalBackup(filepath.Join(dir, LocalBackupDirName, "older"), older), "chain_blockscout-db-data is corrupted")
+
+	// Restores of another chain or genesis are refused before the chain is stopped
+	stack := &ThanosStack{deploymentPath: dir, deployConfig: &types.Config{L2ChainID: 1001}}
+	_, err = stack.LocalBackupRestore(context.Background(), "newer")
+	require.ErrorContains(t, err, "belongs to chain ID 2002, the deployment is chain ID 1001")
+	_, err = stack.LocalBackupRestore(context.Background(), "other-genesis")
+	require.ErrorContains(t, err, "different genesis.json")
+	_, err = stack.LocalBackupRestore(context.Background(), "missing")
+	require.ErrorContains(t, err, "failed to read backup manifest")
+
+	require.Equal(t, "512 B", formatBackupSize(512))
+	require.Equal(t, "1.5 GiB", formatBackupSize(3<<29))
+}
@@ -0,0 +1,159 @@
+package thanos
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// The local monitoring volume holds the same alert rules and AlertManager config as the AWS
+// monitoring plugin, rendered into plain Prometheus and AlertManager files.
+const (
+	localComposeFileName        = "docker-compose.local.yml"
+	localAlertManagerConfigFile = "alertmanager.yml"
+	localAlertRulesFile         = "rules/thanos-stack-alerts.yml"
+	localBlackboxConfigFile     = "blackbox.yml"
+	localAlertRuleObjectName    = "local-thanos-stack-alerts"
+	localGrafanaDashboardURL    = "http://localhost:3002/d/thanos-stack-app-v9/thanos-stack-application-monitoring-dashboard?orgId=1&refresh=30s"
+)
+
+// localAlertingPrometheusConfig loads the alert rules and sends alerts to the AlertManager container
+const localAlertingPrometheusConfig = `
+rule_files:
+  - /monitoring/rules/*.yml
+
+alerting:
+  alertmanagers:
+    - static_configs:
+        - targets: ['alertmanager:9093']
+`
+
+// localL1ProbeScrapeConfig probes the L1 RPC through the blackbox exporter, like the blackbox-eth
+// probes of the AWS monitoring plugin. The target label is fixed so the RPC URL and its API key
+// never end up in notifications.
+const localL1ProbeScrapeConfig = `
+  - job_name: blackbox-eth-l1
+    metrics_path: /probe
+    params:
+      module: [eth_rpc]
+    static_configs:
+      - targets: ['%s']
+        labels:
+          target: l1-rpc
+    relabel_configs:
+      - source_labels: [__address__]
+        target_label: __param_target
+      - target_label: __address__
+        replacement: blackbox-exporter:9115
+`
+
+// localAAOperatorScrapeConfig scrapes cmd/aa-operator running on the host with its default METRICS_ADDR
+const localAAOperatorScrapeConfig = `
+  - job_name: aa-operator
+    static_configs:
+      - targets: ['host.docker.internal:7311']
+        labels:
+          service: aa-operator
+`
+
+// localDRBScrapeConfig scrapes `trh-sdk drb exporter` running on the host with its default --listen
+const localDRBScrapeConfig = `
+  - job_name: drb-exporter
+    static_configs:
+      - targets: ['host.docker.internal:7313']
+        labels:
+          service: drb-exporter
+`
+
+const localBlackboxConfig = `modules:
+  eth_rpc:
+    prober: http
+    timeout: 10s
+    http:
+      method: POST
+      headers:
+        Content-Type: application/json
+      body: '{"jsonrpc":"2.0","method":"eth_blockNumber","params":[],"id":1}'
+      valid_status_codes: [200]
+      fail_if_body_not_matches_regexp:
+        - '"result"'
+`
+
+// localMonitoringConfig is the MonitoringConfig used to render the alerting defaults of a local deployment.
+// No channel is enabled; they are configured afterwards with `alert-config`.
+func (t *ThanosStack) localMonitoringConfig() *types.MonitoringConfig {
+	return &types.MonitoringConfig{
+		Namespace:       constants.MonitoringNamespace,
+		HelmReleaseName: "local",
+		ChainName:       t.deployConfig.ChainName,
+	}
+}
+
+// localBalanceWatcherRules alert on the balance-watcher metrics, which only the local Prometheus scrapes
+const localBalanceWatcherRules = `
+- alert: OperatorBalanceLow
+  expr: balance_watcher_balance_wei < balance_watcher_threshold_wei
+  for: 5m
+  labels:
+    severity: warning
+    component: balance-watcher
+    chain_name: "%s"
+    namespace: "%s"
+  annotations:
+    summary: "L1 operator account balance is low"
+    description: "The {{ $labels.account }} account {{ $labels.address }} is below its balance threshold"
+- alert: OperatorTopUpCapReached
+  expr: increase(balance_watcher_topups_total{result="capped"}[1h]) > 0
+  for: 0s
+  labels:
+    severity: warning
+    component: balance-watcher
+    chain_name: "%s"
+    namespace: "%s"
+  annotations:
+    summary: "Daily top-up cap reached"
+    description: "The {{ $labels.account }} account was not topped up because the daily cap of the funding wallet is reached"
+`
+
+// renderLocalAlertRules renders the thanos-stack alert rules as a Prometheus rule file, with the
+// balance-watcher rules added to the first group
+func (t *ThanosStack) renderLocalAlertRules(config *types.MonitoringConfig) ([]byte, error) {
+	var manifest map[string]interface{}
+	if err := yaml.Unmarshal([]byte(t.generatePrometheusRuleManifest(config)), &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse alert rules: %w", err)
+	}
+	spec, ok := manifest["spec"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("alert rules have no spec")
+	}
+
+	var watcherRules []interface{}
+	rendered := fmt.Sprintf(localBalanceWatcherRules, config.ChainName, config.Namespace, config.ChainName, config.Namespace)
+	if err := yaml.Unmarshal([]byte(rendered), &watcherRules); err != nil {
+		return nil, fmt.Errorf("failed to parse balance-watcher alert rules: %w", err)
+	}
+	groups, _ := spec["groups"].([]interface{})
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("alert rules have no groups")
+	}
+	group, ok := groups[0].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("alert rules have an invalid group")
+	}
+	rules, _ := group["rules"].([]interface{})
+	group["rules"] = append(rules, watcherRules...)
+	return yaml.Marshal(spec)
+}
+
+// renderLocalAlertManagerConfig renders the AlertManager config used by the AWS monitoring plugin
+func (t *ThanosStack) renderLocalAlertManagerConfig(config *types.MonitoringConfig) ([]byte, error) {
+	encoded, err := t.generateAlertManagerSecretConfig(config, localGrafanaDashboardURL)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
@@ -676,11 +676,23 @@ scrape_configs:
         labels:
           service: op-batcher
 
+  - job_name: op-proposer
+    static_configs:
+      - targets: ['op-proposer:7303']
+        labels:
+          service: op-proposer
+
   - job_name: op-challenger
     static_configs:
       - targets: ['op-challenger:7304']
         labels:
           service: op-challenger
+
+  - job_name: balance-watcher
+    static_configs:
+      - targets: ['host.docker.internal:7310']
+        labels:
+          service: balance-watcher
 `
 
 	const datasourceConfig = `apiVersion: 1
@@ -710,6 +722,7 @@ providers:
 		filepath.Join(monitoringDir, "provisioning", "datasources"),
 		filepath.Join(monitoringDir, "provisioning", "dashboards"),
 		filepath.Join(monitoringDir, "dashboards"),
+		filepath.Join(monitoringDir, "rules"),
 	}
 	for _, d := range dirs {
 		if err := os.MkdirAll(d, 0755); err != nil {
@@ -717,11 +730,28 @@ providers:
 		}
 	}
 
+	prometheusConfig := promConfig + fmt.Sprintf(localL1ProbeScrapeConfig, t.deployConfig.L1RPCURL)
+	if constants.NeedsAASetup(t.deployConfig.Preset, t.deployConfig.FeeToken) {
+		prometheusConfig += localAAOperatorScrapeConfig
+	}
+	if constants.PresetModules[t.deployConfig.Preset]["drb"] {
+		prometheusConfig += localDRBScrapeConfig
+	}
+	prometheusConfig += localAlertingPrometheusConfig
+
 	filesToWrite := map[string]string{
-		filepath.Join(monitoringDir, "prometheus.yml"):                                 promConfig,
+		filepath.Join(monitoringDir, "prometheus.yml"):                                 prometheusConfig,
 		filepath.Join(monitoringDir, "provisioning", "datasources", "prometheus.yaml"): datasourceConfig,
 		filepath.Join(monitoringDir, "provisioning", "dashboards", "default.yaml"):     dashboardProviderConfig,
 		filepath.Join(monitoringDir, "dashboards", "thanos-stack-application.json"):    grafanaDashboardApplication,
+		filepath.Join(monitoringDir, localBlackboxConfigFile):                          localBlackboxConfig,
+	}
+	for _, name := range BuiltinGrafanaDashboards() {
+		content, err := BuiltinGrafanaDashboard(name)
+		if err != nil {
+			return err
+		}
+		filesToWrite[filepath.Join(monitoringDir, "dashboards", name+".json")] = string(content)
 	}
 	for path, content := range filesToWrite {
 		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
@@ -729,12 +759,40 @@ providers:
 		}
 	}
 
+	// Alert rules and AlertManager config are only rendered once so changes made with
+	// `alert-config` survive a redeploy
+	alertingConfig := t.localMonitoringConfig()
+	alertingFiles := map[string]func(*types.MonitoringConfig) ([]byte, error){
+		localAlertRulesFile:         t.renderLocalAlertRules,
+		localAlertManagerConfigFile: t.renderLocalAlertManagerConfig,
+	}
+	for name, render := range alertingFiles {
+		path := filepath.Join(monitoringDir, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		content, err := render(alertingConfig)
+		if err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		// The AlertManager config holds channel secrets once channels are configured
+		if err := os.WriteFile(path, content, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
 	// Map host paths → destination names inside the monitoring volume
 	monitoringFiles := map[string]string{
 		"prometheus.yml": filepath.Join(monitoringDir, "prometheus.yml"),
 		"provisioning/datasources/prometheus.yaml": filepath.Join(monitoringDir, "provisioning", "datasources", "prometheus.yaml"),
 		"provisioning/dashboards/default.yaml":     filepath.Join(monitoringDir, "provisioning", "dashboards", "default.yaml"),
 		"dashboards/thanos-stack-application.json": filepath.Join(monitoringDir, "dashboards", "thanos-stack-application.json"),
+		localBlackboxConfigFile:                    filepath.Join(monitoringDir, localBlackboxConfigFile),
+		localAlertRulesFile:                        filepath.Join(monitoringDir, localAlertRulesFile),
+		localAlertManagerConfigFile:                filepath.Join(monitoringDir, localAlertManagerConfigFile),
+	}
+	for _, name := range BuiltinGrafanaDashboards() {
+		monitoringFiles["dashboards/"+name+".json"] = filepath.Join(monitoringDir, "dashboards", name+".json")
 	}
 	return t.copyFilesToMonitoringVolume(ctx, monitoringFiles)
 }
@@ -774,6 +832,10 @@ func (t *ThanosStack) copyFilesToMonitoringVolume(ctx context.Context, files map
 			return fmt.Errorf("failed to copy %s into monitoring volume: %w", destName, err)
 		}
 	}
+	// Files keep their host mode (0600 for the AlertManager config); Prometheus and AlertManager run as nobody
+	if _, err := utils.ExecuteCommand(ctx, "docker", "exec", containerID, "chmod", "-R", "a+rX", "/monitoring"); err != nil {
+		return fmt.Errorf("failed to set permissions in monitoring volume: %w", err)
+	}
 	return nil
 }
 
@@ -1034,6 +1096,7 @@ func (t *ThanosStack) printLocalServiceURLs(modules map[string]bool) {
 	if modules["monitoring"] {
 		t.logger.Infof("  Grafana:       http://localhost:3002  (admin/admin)")
 		t.logger.Infof("  Prometheus:    http://localhost:9090")
+		t.logger.Infof("  AlertManager:  http://localhost:9093")
 	}
 	if modules["uptimeService"] {
 		t.logger.Infof("  Uptime Kuma:   http://localhost:3003")
@@ -0,0 +1,346 @@
+package thanos
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// TroubleshootLogPattern is the --grep of `trh-sdk logs --troubleshoot`
+const TroubleshootLogPattern = `(?i)error|fail|panic|critical`
+
+// localLogServices maps the log components to the services of the local docker compose file
+var localLogServices = map[string][]string{
+	"op-geth":           {"op-geth"},
+	"op-node":           {"op-node"},
+	"op-batcher":        {"op-batcher"},
+	"op-proposer":       {"op-proposer"},
+	"op-challenger":     {"op-challenger"},
+	"bridge":            {"op-bridge"},
+	"block-explorer-be": {"blockscout", "blockscout-db"},
+	"block-explorer-fe": {"blockscout-frontend", "blockscout-gateway"},
+	"drb":               {"drb-leader", "drb-postgres"},
+	"alto-bundler":      {"alto-bundler"},
+	"cross-trade":       {"cross-trade-dapp"},
+}
+
+var logPrefixColors = []string{"\033[36m", "\033[33m", "\033[32m", "\033[35m", "\033[34m", "\033[31m", "\033[96m", "\033[93m"}
+
+// logSource is a container or pod whose logs are streamed by a docker or kubectl command
+type logSource struct {
+	component string
+	name      string
+	command   []string
+}
+
+// StreamLogs merges the logs of the selected components of a local or AWS deployment into out.
+// Without Follow the lines are sorted by timestamp; with Follow they are printed as they arrive.
+func (t *ThanosStack) StreamLogs(ctx context.Context, opts *types.LogStreamOptions, out io.Writer) error {
+	for _, component := range opts.Components {
+		if !SupportedLogsComponents[component] {
+			return fmt.Errorf("unsupported component: %s", component)
+		}
+	}
+	var grep *regexp.Regexp
+	if opts.Grep != "" {
+		var err error
+		if grep, err = regexp.Compile(opts.Grep); err != nil {
+			return fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+	}
+
+	var (
+		sources []logSource
+		err     error
+	)
+	switch {
+	case utils.CheckFileExists(filepath.Join(t.deploymentPath, localComposeFileName)):
+		sources, err = t.localLogSources(ctx, opts)
+	case t.deployConfig != nil && t.deployConfig.K8s != nil:
+		sources, err = t.k8sLogSources(ctx, opts)
+	default:
+		return fmt.Errorf("no local or AWS deployment found. Please run the deploy command first")
+	}
+	if err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		if len(opts.Components) > 0 {
+			return fmt.Errorf("no running containers for %s", strings.Join(opts.Components, ", "))
+		}
+		return fmt.Errorf("no running containers found")
+	}
+	return streamLogSources(ctx, sources, opts, grep, out)
+}
+
+// ShowLogs follows the logs of a single component
+func (t *ThanosStack) ShowLogs(ctx context.Context, config *types.Config, component string, isTroubleshoot bool) error {
+	opts := &types.LogStreamOptions{Components: []string{component}, Follow: true, Tail: -1}
+	if isTroubleshoot {
+		opts.Grep = TroubleshootLogPattern
+	}
+	return t.StreamLogs(ctx, opts, os.Stdout)
+}
+
+// localLogSources lists the running containers of the docker compose project in the deployment path
+func (t *ThanosStack) localLogSources(ctx context.Context, opts *types.LogStreamOptions) ([]logSource, error) {
+	deploymentPath, err := filepath.Abs(t.deploymentPath)
+	if err != nil {
+		return nil, err
+	}
+	output, err := utils.ExecuteCommand(ctx, "docker", "ps",
+		"--filter", "label=com.docker.compose.project.working_dir="+deploymentPath,
+		"--format", `{{.Names}}	{{.Label "com.docker.compose.service"}}`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %s: %w", output, err)
+	}
+
+	var sources []logSource
+	for _, line := range strings.Split(output, "\n") {
+		container, service, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		component := localLogComponent(service)
+		if !logComponentSelected(opts, component) {
+			continue
+		}
+		args := []string{"docker", "logs", "--timestamps"}
+		if opts.Since > 0 {
+			args = append(args, "--since", opts.Since.String())
+		}
+		if opts.Tail >= 0 {
+			args = append(args, "--tail", strconv.Itoa(opts.Tail))
+		}
+		if opts.Follow {
+			args = append(args, "--follow")
+		}
+		sources = append(sources, logSource{component: component, name: service, command: append(args, container)})
+	}
+	sortLogSources(sources)
+	return sources, nil
+}
+
+// k8sLogSources lists the running pods of the chain namespace, including DRB, alto-bundler and cross-trade
+func (t *ThanosStack) k8sLogSources(ctx context.Context, opts *types.LogStreamOptions) ([]logSource, error) {
+	namespace := t.deployConfig.K8s.Namespace
+	pods, err := utils.GetK8sPods(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pods: %w", err)
+	}
+
+	var sources []logSource
+	for _, pod := range pods {
+		component := k8sLogComponent(pod)
+		if !logComponentSelected(opts, component) {
+			continue
+		}
+		args := []string{"kubectl", "-n", namespace, "logs", pod, "--all-containers", "--timestamps"}
+		if opts.Since > 0 {
+			args = append(args, "--since="+opts.Since.String())
+		}
+		args = append(args, "--tail="+strconv.Itoa(opts.Tail))
+		if opts.Follow {
+			args = append(args, "--follow")
+		}
+		sources = append(sources, logSource{component: component, name: pod, command: args})
+	}
+	sortLogSources(sources)
+	return sources, nil
+}
+
+func localLogComponent(service string) string {
+	for component, services := range localLogServices {
+		for _, s := range services {
+			if s == service {
+				return component
+			}
+		}
+	}
+	return service
+}
+
+// k8sLogComponent returns the longest component name contained in the pod name
+func k8sLogComponent(pod string) string {
+	match := ""
+	for component := range SupportedLogsComponents {
+		if strings.Contains(pod, component) && len(component) > len(match) {
+			match = component
+		}
+	}
+	if match == "" {
+		return pod
+	}
+	return match
+}
+
+func logComponentSelected(opts *types.LogStreamOptions, component string) bool {
+	if len(opts.Components) == 0 {
+		return true
+	}
+	for _, c := range opts.Components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+func sortLogSources(sources []logSource) {
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].component != sources[j].component {
+			return sources[i].component < sources[j].component
+		}
+		return sources[i].name < sources[j].name
+	})
+}
+
+// streamLogSources runs the log command of every source and prints the lines matching grep
+func streamLogSources(ctx context.Context, sources []logSource, opts *types.LogStreamOptions, grep *regexp.Regexp, out io.Writer) error {
+	printer := newLogPrinter(out, sources, opts)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		collected []types.LogLine
+		errs      []error
+	)
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source logSource) {
+			defer wg.Done()
+			err := readLogSource(ctx, source, func(line types.LogLine) {
+				if grep != nil && !grep.MatchString(line.Message) {
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if opts.Follow {
+					printer.print(line)
+				} else {
+					collected = append(collected, line)
+				}
+			})
+			if err != nil && ctx.Err() == nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", source.name, err))
+				mu.Unlock()
+			}
+		}(source)
+	}
+	wg.Wait()
+
+	sort.SliceStable(collected, func(i, j int) bool { return collected[i].Time.Before(collected[j].Time) })
+	for _, line := range collected {
+		printer.print(line)
+	}
+	return errors.Join(errs...)
+}
+
+// readLogSource runs the log command of a source and calls emit for each line of its stdout and stderr
+func readLogSource(ctx context.Context, source logSource, emit func(types.LogLine)) error {
+	cmd := exec.CommandContext(ctx, source.command[0], source.command[1:]...)
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	if err := cmd.Start(); err != nil {
+		writer.Close()
+		return err
+	}
+	writer.Close()
+
+	var last string
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		timestamp, message := parseLogTimestamp(scanner.Text())
+		last = message
+		emit(types.LogLine{Time: timestamp, Component: source.component, Source: source.name, Message: message})
+	}
+	if err := cmd.Wait(); err != nil {
+		if last != "" {
+			return fmt.Errorf("%w: %s", err, last)
+		}
+		return err
+	}
+	return nil
+}
+
+// parseLogTimestamp splits the RFC 3339 timestamp added by --timestamps from the message
+func parseLogTimestamp(line string) (time.Time, string) {
+	prefix, message, ok := strings.Cut(line, " ")
+	if !ok {
+		return time.Time{}, line
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, prefix)
+	if err != nil {
+		return time.Time{}, line
+	}
+	return timestamp, message
+}
+
+// logPrinter writes log lines as JSON or with a padded, optionally colored, prefix per source
+type logPrinter struct {
+	out     io.Writer
+	opts    *types.LogStreamOptions
+	labels  map[string]string
+	colors  map[string]string
+	width   int
+	encoder *json.Encoder
+}
+
+func newLogPrinter(out io.Writer, sources []logSource, opts *types.LogStreamOptions) *logPrinter {
+	p := &logPrinter{out: out, opts: opts, labels: map[string]string{}, colors: map[string]string{}, encoder: json.NewEncoder(out)}
+	perComponent := map[string]int{}
+	for _, source := range sources {
+		perComponent[source.component]++
+	}
+	for i, source := range sources {
+		// The component is enough unless it has several containers or pods
+		label := source.component
+		if perComponent[source.component] > 1 {
+			label = source.name
+		}
+		p.labels[source.name] = label
+		p.colors[source.name] = logPrefixColors[i%len(logPrefixColors)]
+		if len(label) > p.width {
+			p.width = len(label)
+		}
+	}
+	return p
+}
+
+func (p *logPrinter) print(line types.LogLine) {
+	if p.opts.JSON {
+		_ = p.encoder.Encode(line)
+		return
+	}
+	prefix := fmt.Sprintf("%-*s |", p.width, p.labels[line.Source])
+	if p.opts.Color {
+		prefix = p.colors[line.Source] + prefix + "\033[0m"
+	}
+	if line.Time.IsZero() {
+		fmt.Fprintf(p.out, "%s %s\n", prefix, line.Message)
+		return
+	}
+	fmt.Fprintf(p.out, "%s %s %s\n", prefix, line.Time.Local().Format("2006-01-02 15:04:05.000"), line.Message)
+}
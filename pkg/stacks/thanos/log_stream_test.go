@@ -0,0 +1,79 @@
+package thanos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+func TestStreamLogSources(t *testing.T) {
+	printLines := func(lines ...string) []string {
+		return []string{"sh", "-c", "printf '" + strings.Join(lines, `\n`) + `\n'`}
+	}
+	sources := []logSource{
+		{component: "op-node", name: "op-node", command: printLines(
+			"2026-05-01T10:00:01.000000000Z started op-node",
+			"2026-05-01T10:00:03.000000000Z ERROR lost peer",
+		)},
+		{component: "drb", name: "drb-leader", command: printLines("2026-05-01T10:00:02.000000000Z round 7 committed")},
+		{component: "drb", name: "drb-postgres", command: printLines("plain line without timestamp")},
+	}
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	require.NoError(t, streamLogSources(ctx, sources, &types.LogStreamOptions{}, nil, &out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	require.True(t, strings.HasPrefix(lines[0], "drb-postgres |"), "lines without timestamp sort first")
+	require.Contains(t, lines[1], "started op-node")
+	require.True(t, strings.HasPrefix(lines[2], "drb-leader   |"), "drb has two containers, so they are told apart")
+	require.True(t, strings.HasPrefix(lines[3], "op-node      |"))
+
+	out.Reset()
+	opts := &types.LogStreamOptions{JSON: true}
+	require.NoError(t, streamLogSources(ctx, sources, opts, regexp.MustCompile(TroubleshootLogPattern), &out))
+	var line types.LogLine
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	require.Equal(t, "op-node", line.Component)
+	require.Equal(t, "ERROR lost peer", line.Message)
+	require.Equal(t, time.Date(2026, 5, 1, 10, 0, 3, 0, time.UTC), line.Time)
+
+	// A failing source is reported with its last line while the others are still printed
+	out.Reset()
+	failing := append(sources[:1:1], logSource{component: "op-geth", name: "op-geth", command: []string{"sh", "-c", "echo 'Error: No such container: op-geth' >&2; exit 1"}})
+	err := streamLogSources(ctx, failing, &types.LogStreamOptions{}, nil, &out)
+	require.ErrorContains(t, err, "op-geth: exit status 1: Error: No such container")
+	require.Contains(t, out.String(), "started op-node")
+}
+
+func TestLogComponents(t *testing.T) {
+	require.Equal(t, "block-explorer-be", k8sLogComponent("thanos-block-explorer-be-7d9f8c-xkq2p"))
+	require.Equal(t, "bridge", k8sLogComponent("thanos-op-bridge-5c6d7-abcde"))
+	require.Equal(t, "coredns-5d78c9869d-abcde", k8sLogComponent("coredns-5d78c9869d-abcde"))
+
+	require.Equal(t, "block-explorer-fe", localLogComponent("blockscout-frontend"))
+	require.Equal(t, "drb", localLogComponent("drb-postgres"))
+	require.Equal(t, "grafana", localLogComponent("grafana"))
+	for component := range localLogServices {
+		require.True(t, SupportedLogsComponents[component], component)
+	}
+
+	selected := &types.LogStreamOptions{Components: []string{"op-node", "drb"}}
+	require.True(t, logComponentSelected(selected, "drb"))
+	require.False(t, logComponentSelected(selected, "op-geth"))
+	require.True(t, logComponentSelected(&types.LogStreamOptions{}, "grafana"))
+
+	timestamp, message := parseLogTimestamp("2026-05-01T10:00:01.123456789+09:00 t=2026 lvl=info msg=hello")
+	require.Equal(t, "t=2026 lvl=info msg=hello", message)
+	require.Equal(t, 123456789, timestamp.Nanosecond())
+	_, message = parseLogTimestamp("INFO [05-01|10:00:01] hello")
+	require.Equal(t, "INFO [05-01|10:00:01] hello", message)
+}
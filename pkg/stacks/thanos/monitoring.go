@@ -1042,7 +1042,10 @@ func (t *ThanosStack) generateAlertTemplates(grafanaURL string) map[string]strin
     </div>
 </body>
 </html>`,
-		"telegram_message": "🚨 Critical Alert - {{ .GroupLabels.chain_name }}\n\nAlert Name: {{ .GroupLabels.alertname }}\nSeverity: {{ .GroupLabels.severity }}\nComponent: {{ .GroupLabels.component }}\n\nSummary: {{ .CommonAnnotations.summary }}\nDescription: {{ .CommonAnnotations.description }}\n\n⏰ Alert Time: {{ range .Alerts }}{{ .StartsAt }}{{ end }}\n\nDashboard: [View Details](" + grafanaURL + ")",
+		"telegram_message":      "🚨 Critical Alert - {{ .GroupLabels.chain_name }}\n\nAlert Name: {{ .GroupLabels.alertname }}\nSeverity: {{ .GroupLabels.severity }}\nComponent: {{ .GroupLabels.component }}\n\nSummary: {{ .CommonAnnotations.summary }}\nDescription: {{ .CommonAnnotations.description }}\n\n⏰ Alert Time: {{ range .Alerts }}{{ .StartsAt }}{{ end }}\n\nDashboard: [View Details](" + grafanaURL + ")",
+		"chat_title":            "{{ if eq .Status \"firing\" }}🚨{{ else }}✅{{ end }} [{{ .Status | toUpper }}] {{ .GroupLabels.alertname }} - {{ .GroupLabels.chain_name }}",
+		"chat_text":             "*Severity:* {{ .GroupLabels.severity }}\n*Component:* {{ .GroupLabels.component }}\n*Summary:* {{ .CommonAnnotations.summary }}\n*Description:* {{ .CommonAnnotations.description }}\n*Dashboard:* " + grafanaURL,
+		"pagerduty_description": "[{{ .GroupLabels.chain_name }}] {{ .GroupLabels.alertname }}: {{ .CommonAnnotations.summary }}",
 	}
 }
 
@@ -1455,6 +1458,15 @@ func (t *ThanosStack) cleanupExistingDashboardConfigMaps(ctx context.Context, co
 
 // createDashboardConfigMaps creates ConfigMaps for Grafana dashboards
 func (t *ThanosStack) createDashboardConfigMaps(ctx context.Context, config *types.MonitoringConfig) error {
+	// Built-in dashboards shipped with trh-sdk
+	for _, name := range BuiltinGrafanaDashboards() {
+		dashboardContent, err := BuiltinGrafanaDashboard(name)
+		if err != nil {
+			continue
+		}
+		t.applyDashboardConfigMap(ctx, config, name+".json", dashboardContent)
+	}
+
 	dashboardsPath := filepath.Join(config.ChartsPath, "dashboards")
 	if _, err := os.Stat(dashboardsPath); os.IsNotExist(err) {
 		return nil
@@ -1475,11 +1487,17 @@ func (t *ThanosStack) createDashboardConfigMaps(ctx context.Context, config *typ
 		if err != nil {
 			continue
 		}
+		t.applyDashboardConfigMap(ctx, config, file.Name(), dashboardContent)
+	}
+	return nil
+}
 
-		configMapName := fmt.Sprintf("dashboard-%s", strings.TrimSuffix(file.Name(), ".json"))
-		indentedContent := strings.ReplaceAll(string(dashboardContent), "\n", "\n    ")
+// applyDashboardConfigMap creates a ConfigMap that the Grafana sidecar loads as a dashboard
+func (t *ThanosStack) applyDashboardConfigMap(ctx context.Context, config *types.MonitoringConfig, fileName string, dashboardContent []byte) {
+	configMapName := fmt.Sprintf("dashboard-%s", strings.TrimSuffix(fileName, ".json"))
+	indentedContent := strings.ReplaceAll(string(dashboardContent), "\n", "\n    ")
 
-		configMapYAML := fmt.Sprintf(`apiVersion: v1
+	configMapYAML := fmt.Sprintf(`apiVersion: v1
 kind: ConfigMap
 metadata:
   name: %s
@@ -1488,20 +1506,18 @@ metadata:
     grafana_dashboard: "1"
 data:
   %s: |
-    %s`, configMapName, config.Namespace, file.Name(), indentedContent)
+    %s`, configMapName, config.Namespace, fileName, indentedContent)
 
-		tempFile := filepath.Join(os.TempDir(), fmt.Sprintf("dashboard-%s.yaml", configMapName))
-		if err := os.WriteFile(tempFile, []byte(configMapYAML), 0644); err != nil {
-			continue
-		}
+	tempFile := filepath.Join(os.TempDir(), fmt.Sprintf("dashboard-%s.yaml", configMapName))
+	if err := os.WriteFile(tempFile, []byte(configMapYAML), 0644); err != nil {
+		return
+	}
 
-		_, err = utils.ExecuteCommand(ctx, "kubectl", "apply", "-f", tempFile)
-		if err != nil {
-			continue
-		}
-		os.Remove(tempFile)
+	_, err := utils.ExecuteCommand(ctx, "kubectl", "apply", "-f", tempFile)
+	if err != nil {
+		return
 	}
-	return nil
+	os.Remove(tempFile)
 }
 
 // createAlertManagerSecret creates AlertManager configuration secret
@@ -1584,6 +1600,11 @@ func (t *ThanosStack) generateAlertManagerSecretConfig(config *types.MonitoringC
 		}
 	}
 
+	receiverList := make([]interface{}, 0, len(receivers))
+	for _, receiver := range receivers {
+		receiverList = append(receiverList, receiver)
+	}
+
 	// Only add global SMTP config if email is enabled
 	alertManagerConfig := map[string]interface{}{
 		"route": map[string]interface{}{
@@ -1592,14 +1613,20 @@ func (t *ThanosStack) generateAlertManagerSecretConfig(config *types.MonitoringC
 			"group_interval":  "1m",
 			"repeat_interval": "10m",
 			"receiver":        "telegram-critical",
-			"routes": []map[string]interface{}{
-				{
+			"routes": []interface{}{
+				map[string]interface{}{
 					"match":    map[string]string{"alertname": "Watchdog"},
 					"receiver": "null",
 				},
 			},
 		},
-		"receivers": receivers,
+		"receivers": receiverList,
+	}
+
+	// Add Slack, Discord, PagerDuty and webhook receivers with their severity routes
+	addRoutedChannels(alertManagerConfig, config.AlertManager, t.generateAlertTemplates(grafanaURL))
+	if len(config.AlertManager.Routes) > 0 {
+		setCustomAlertRoutes(alertManagerConfig, config.AlertManager.Routes)
 	}
 
 	// Add global SMTP config only if email is enabled
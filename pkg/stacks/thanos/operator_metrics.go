@@ -0,0 +1,154 @@
+package thanos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// operatorMetrics is a minimal Prometheus registry for the long-running operator services.
+// It renders the text exposition format so the services do not pull in the Prometheus client.
+type operatorMetrics struct {
+	mu       sync.Mutex
+	families map[string]*metricFamily
+	// onCollect updates time-dependent series right before they are rendered
+	onCollect func()
+}
+
+type metricFamily struct {
+	help   string
+	kind   string
+	series map[string]float64
+}
+
+func newOperatorMetrics() *operatorMetrics {
+	return &operatorMetrics{families: map[string]*metricFamily{}}
+}
+
+// setGauge sets a gauge series. labels are name/value pairs.
+func (m *operatorMetrics) setGauge(name, help string, value float64, labels ...string) {
+	m.update(name, help, "gauge", labels, func(float64) float64 { return value })
+}
+
+// addCounter increments a counter series. labels are name/value pairs.
+func (m *operatorMetrics) addCounter(name, help string, delta float64, labels ...string) {
+	m.update(name, help, "counter", labels, func(current float64) float64 { return current + delta })
+}
+
+// value returns the current value of a series, for tests and health checks
+func (m *operatorMetrics) value(name string, labels ...string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	family, ok := m.families[name]
+	if !ok {
+		return 0
+	}
+	return family.series[formatMetricLabels(labels)]
+}
+
+func (m *operatorMetrics) update(name, help, kind string, labels []string, fn func(float64) float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	family, ok := m.families[name]
+	if !ok {
+		family = &metricFamily{help: help, kind: kind, series: map[string]float64{}}
+		m.families[name] = family
+	}
+	key := formatMetricLabels(labels)
+	family.series[key] = fn(family.series[key])
+}
+
+// writeTo renders all metrics in the Prometheus text exposition format
+func (m *operatorMetrics) writeTo(w io.Writer) error {
+	if m.onCollect != nil {
+		m.onCollect()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.families))
+	for name := range m.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		family := m.families[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, family.help, name, family.kind)
+		keys := make([]string, 0, len(family.series))
+		for key := range family.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, "%s%s %s\n", name, key, strconv.FormatFloat(family.series[key], 'g', -1, 64))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatMetricLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[i+1])
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], value))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// weiToFloat converts a wei amount to a float64 sample. Precision loss above 2^53 wei is fine for metrics.
+func weiToFloat(wei *big.Int) float64 {
+	if wei == nil {
+		return 0
+	}
+	f, _ := new(big.Float).SetInt(wei).Float64()
+	return f
+}
+
+// serveOperatorMetrics serves /metrics and /healthz on addr until ctx is cancelled.
+// healthy returns nil while the service is working; its error is reported with HTTP 503.
+func serveOperatorMetrics(ctx context.Context, logger *zap.SugaredLogger, addr string, metrics *operatorMetrics, healthy func() error) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := metrics.writeTo(w); err != nil {
+			logger.Warnf("Failed to write metrics: %v", err)
+		}
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if err := healthy(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok\n"))
+	})
+
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	go func() {
+		logger.Infof("Serving metrics on %s/metrics", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("Metrics server stopped: %v", err)
+		}
+	}()
+}
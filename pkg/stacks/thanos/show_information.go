@@ -18,6 +18,10 @@ var SupportedLogsComponents = map[string]bool{
 	"block-explorer-be": true,
 	"block-explorer-fe": true,
 	"bridge":            true,
+	"op-challenger":     true,
+	"drb":               true,
+	"alto-bundler":      true,
+	"cross-trade":       true,
 }
 
 func (t *ThanosStack) ShowInformation(ctx context.Context) (*types.ChainInformation, error) {
@@ -143,54 +147,6 @@ func (t *ThanosStack) ShowInformation(ctx context.Context) (*types.ChainInformat
 	}, nil
 }
 
-func (t *ThanosStack) ShowLogs(ctx context.Context, config *types.Config, component string, isTroubleshoot bool) error {
-	if config.K8s == nil {
-		t.logger.Error("K8s configuration is not set. Please run the deploy command first")
-		return fmt.Errorf("K8s configuration is not set. Please run the deploy command first")
-	}
-
-	var (
-		namespace = config.K8s.Namespace
-	)
-
-	if !SupportedLogsComponents[component] {
-		t.logger.Error("unsupported component", "component", component)
-		return fmt.Errorf("unsupported component: %s", component)
-	}
-
-	runningPods, err := t.getRunningPods(ctx)
-	if err != nil {
-		t.logger.Error("failed to get running pods", "err", err)
-		return err
-	}
-
-	var (
-		runningPodName string
-	)
-	for _, pod := range runningPods {
-		if !strings.Contains(pod, component) {
-			continue
-		}
-		runningPodName = pod
-	}
-
-	if isTroubleshoot {
-		err = utils.ExecuteCommandStream(ctx, t.logger, "bash", "-c", fmt.Sprintf("kubectl -n %s logs %s -f | grep -iE 'error|fail|panic|critical'", namespace, runningPodName))
-		if err != nil {
-			t.logger.Error("failed to show logs", "err", err)
-			return err
-		}
-	} else {
-		err = utils.ExecuteCommandStream(ctx, t.logger, "bash", "-c", fmt.Sprintf("kubectl -n %s logs %s -f", namespace, runningPodName))
-		if err != nil {
-			t.logger.Error("failed to show logs", "err", err)
-			return err
-		}
-	}
-
-	return nil
-}
-
 func (t *ThanosStack) getRunningPods(ctx context.Context) ([]string, error) {
 	if t.deployConfig.K8s == nil {
 		t.logger.Error("K8s configuration is not set. Please run the deploy command first")
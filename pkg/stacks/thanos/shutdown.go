@@ -8,11 +8,27 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/tokamak-network/trh-sdk/pkg/types"
 	"github.com/tokamak-network/trh-sdk/pkg/utils"
 )
 
+// Forge scripts of the shutdown steps, relative to contracts-bedrock
+const (
+	shutdownBlockScript    = "scripts/shutdown/BlockDepositsWithdrawals.s.sol"
+	shutdownGenScript      = "scripts/shutdown/GenerateAssetSnapshot.s.sol"
+	shutdownActivateScript = "scripts/shutdown/PrepareL1Withdrawal.s.sol"
+	shutdownWithdrawScript = "scripts/shutdown/ExecuteL1Withdrawal.s.sol"
+)
+
+// shutdownProxyAdminOwner is the observed ProxyAdmin owner impersonated when activate is simulated
+const shutdownProxyAdminOwner = "0xC8442F4521bc6C1D39f9A93CC05B548E2cBf4952"
+
 // ShutdownBlock blocks L1 deposits and withdrawals (Step 1).
 func (s *ThanosStack) ShutdownBlock(ctx context.Context, dryRun bool) error {
 	s.logger.Info("Starting ForceWithdraw Shutdown (Step 1: Block) via Forge...")
@@ -30,12 +46,13 @@ func (s *ThanosStack) ShutdownBlock(ctx context.Context, dryRun bool) error {
 		fmt.Sprintf("CONTRACTS_L2BRIDGE_ADDRESS=%s", "0x4200000000000000000000000000000000000010"),
 	}
 
-	return s.runForgeScript(ctx, "scripts/shutdown/BlockDepositsWithdrawals.s.sol", "run()", nil, false, envVars, dryRun)
+	return s.runForgeScript(ctx, shutdownBlockScript, "run()", nil, false, envVars, dryRun)
 }
 
-// ShutdownFetch fetches L2 asset and burn data (Step 2).
-func (s *ThanosStack) ShutdownFetch(ctx context.Context, dryRun bool) error {
-	s.logger.Info("Starting ForceWithdraw Asset Fetching (Step 2: Fetch) via Python...")
+// ShutdownFetch indexes the L2 assets and withdrawals into the data directory (Step 2). The L2 is scanned
+// over RPC, resuming from the checkpoint of a previous fetch.
+func (s *ThanosStack) ShutdownFetch(ctx context.Context, opts types.ShutdownFetchOptions) error {
+	s.logger.Info("Starting ForceWithdraw Asset Fetching (Step 2: Fetch)...")
 
 	// Safeguard against nil deployConfig
 	if s.deployConfig == nil {
@@ -46,9 +63,14 @@ func (s *ThanosStack) ShutdownFetch(ctx context.Context, dryRun bool) error {
 		s.deployConfig = config
 	}
 
-	contracts, _ := s.readDeploymentContracts()
-	bedrockPath, _ := s.getBedrockPath()
-	scriptsDir := filepath.Join(bedrockPath, "scripts", "shutdown")
+	contracts, err := s.readDeploymentContracts()
+	if err != nil {
+		return fmt.Errorf("failed to read deployment contracts: %w", err)
+	}
+	bedrockPath, err := s.getBedrockPath()
+	if err != nil {
+		return err
+	}
 	dataDir := filepath.Join(bedrockPath, "data")
 
 	// Ensure data directory exists
@@ -56,73 +78,64 @@ func (s *ThanosStack) ShutdownFetch(ctx context.Context, dryRun bool) error {
 		return fmt.Errorf("failed to create data directory: %v", err)
 	}
 
-	// Determine Explorer URL
-	// Try to get dynamic URL from K8s ingress first
-	explorerUrl, err := s.GetBlockExplorerURL(ctx)
+	raw, err := rpc.DialContext(ctx, s.deployConfig.L2RpcUrl)
 	if err != nil {
-		return fmt.Errorf("failed to get block explorer URL: %v", err)
+		return fmt.Errorf("failed to connect to L2 RPC %s: %w", s.deployConfig.L2RpcUrl, err)
 	}
-
-	// Ensure API path
-	if !strings.HasSuffix(explorerUrl, "/api/v2") {
-		explorerUrl = explorerUrl + "/api/v2"
+	defer raw.Close()
+	l1, err := ethclient.DialContext(ctx, s.deployConfig.L1RPCURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to L1 RPC %s: %w", s.deployConfig.L1RPCURL, err)
 	}
+	defer l1.Close()
 
-	envVars := []string{
-		fmt.Sprintf("L1_RPC_URL=%s", s.deployConfig.L1RPCURL),
-		fmt.Sprintf("L2_RPC_URL=%s", s.deployConfig.L2RpcUrl),
-		fmt.Sprintf("L2_CHAIN_ID=%d", s.deployConfig.L2ChainID),
-		fmt.Sprintf("DATA_DIR=%s", dataDir),
-		fmt.Sprintf("EXPLORER_URL=%s", explorerUrl),
+	fetcher := &shutdownFetcher{
+		logger:         s.logger,
+		l2:             &rpcShutdownFetchClient{Client: ethclient.NewClient(raw), raw: raw},
+		l1:             l1,
+		portal:         common.HexToAddress(contracts.OptimismPortalProxy),
+		l2ChainID:      s.deployConfig.L2ChainID,
+		workers:        opts.Workers,
+		blockRange:     opts.BlockRange,
+		checkpointPath: filepath.Join(dataDir, fmt.Sprintf(ShutdownFetchCheckpointFile, s.deployConfig.L2ChainID)),
 	}
-
-	// Essential contract addresses for fetching withdrawals
-	if contracts != nil {
-		// Map SDK contract names to Python script requirements
-		envVars = append(envVars, fmt.Sprintf("BRIDGE_PROXY=%s", contracts.L1StandardBridgeProxy))
-		envVars = append(envVars, fmt.Sprintf("OPTIMISM_PORTAL_PROXY=%s", contracts.OptimismPortalProxy))
+	if fetcher.workers <= 0 {
+		fetcher.workers = defaultShutdownFetchWorkers
 	}
-
-	// 1. Fetch Explorer Assets
-	s.logger.Info(" -> Running fetch_explorer_assets.py")
-	fetchArgs := []string{fmt.Sprintf("%d", s.deployConfig.L2ChainID)}
-	if err := s.runPythonScript(ctx, filepath.Join(scriptsDir, "fetch_explorer_assets.py"), fetchArgs, envVars, false); err != nil {
-		return fmt.Errorf("fetch_explorer_assets.py failed: %w", err)
+	if fetcher.blockRange == 0 {
+		fetcher.blockRange = defaultShutdownFetchBlockRange
 	}
-
-	// 2. Compute L2 Burns
-	s.logger.Info(" -> Running compute_l2_burns.py")
-	l2BurnsArgs := []string{s.deployConfig.L2RpcUrl, fmt.Sprintf("%d", s.deployConfig.L2ChainID)}
-	if err := s.runPythonScript(ctx, filepath.Join(scriptsDir, "compute_l2_burns.py"), l2BurnsArgs, envVars, false); err != nil {
-		return fmt.Errorf("compute_l2_burns.py failed: %w", err)
+	if opts.Restart {
+		if err := os.Remove(fetcher.checkpointPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove the fetch checkpoint: %w", err)
+		}
 	}
-
-	// 3. Compute Finalized Native Withdrawals
-	s.logger.Info(" -> Running compute_finalized_native_withdrawals.py")
-	// Pass RPC URL and L1 Bridge Address as arguments
-	scriptArgs := []string{s.deployConfig.L1RPCURL, contracts.L1StandardBridgeProxy}
-
-	if err := s.runPythonScript(ctx, filepath.Join(scriptsDir, "compute_finalized_native_withdrawals.py"), scriptArgs, envVars, false); err != nil {
-		return fmt.Errorf("compute_finalized_native_withdrawals.py failed: %w", err)
+	if err := fetcher.loadCheckpoint(); err != nil {
+		return err
 	}
-
-	return nil
-}
-
-// runPythonScript executes a python script with given environment variables and arguments
-func (s *ThanosStack) runPythonScript(ctx context.Context, scriptPath string, args []string, envVars []string, dryRun bool) error {
-	argStr := ""
-	if len(args) > 0 {
-		argStr = strings.Join(args, " ")
+	if err := fetcher.addGenesisAccounts(s.genesisConfigPath()); err != nil {
+		return err
 	}
 
-	if dryRun {
-		s.logger.Infof("[DryRun] python3 %s %s", scriptPath, argStr)
-		return nil
+	head, err := fetcher.l2.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read the L2 head: %w", err)
+	}
+	if err := fetcher.scan(ctx, head); err != nil {
+		return err
+	}
+	result, err := fetcher.collect(ctx, head)
+	if err != nil {
+		return err
+	}
+	if err := writeShutdownFetchResult(dataDir, s.deployConfig.L2ChainID, result); err != nil {
+		return err
 	}
 
-	cmdStr := fmt.Sprintf("%s python3 %s %s", strings.Join(envVars, " "), scriptPath, argStr)
-	return utils.ExecuteCommandStream(ctx, s.logger, "bash", "-c", cmdStr)
+	s.logger.Infof("✅ Fetched L2 assets at block %d into %s", head, dataDir)
+	s.logger.Infof("   %d tokens, %d balances, %d contracts, %d burns, %d unclaimed withdrawals",
+		len(result.Tokens), len(result.Holders), len(result.Contracts), len(result.Burns), len(result.Unclaimed))
+	return nil
 }
 
 // ShutdownGen generates asset snapshots (Step 3).
@@ -154,10 +167,13 @@ func (s *ThanosStack) ShutdownGen(ctx context.Context, input types.ShutdownConfi
 		envVars = append(envVars, fmt.Sprintf("L1_NATIVE_TOKEN=%s", bedrockConfig.NativeTokenAddress))
 	}
 
-	return s.runForgeScript(ctx, "scripts/shutdown/GenerateAssetSnapshot.s.sol", "run()", nil, true, envVars, dryRun)
+	return s.runForgeScript(ctx, shutdownGenScript, "run()", nil, true, envVars, dryRun)
 }
 
 func (s *ThanosStack) getBedrockPath() (string, error) {
+	if s.shutdownBedrockPath != "" {
+		return s.shutdownBedrockPath, nil
+	}
 	// Directly look in deploymentPath/tokamak-thanos/...
 	p := filepath.Join(s.deploymentPath, "tokamak-thanos", "packages", "tokamak", "contracts-bedrock")
 
@@ -168,6 +184,80 @@ func (s *ThanosStack) getBedrockPath() (string, error) {
 	return "", fmt.Errorf("contracts-bedrock directory not found at: %s", p)
 }
 
+// ShutdownAssetsFile resolves the path of an assets snapshot, relative paths are in contracts-bedrock
+func (s *ThanosStack) ShutdownAssetsFile(assetsPath string) string {
+	if filepath.IsAbs(assetsPath) {
+		return assetsPath
+	}
+	bedrockPath, err := s.getBedrockPath()
+	if err != nil {
+		bedrockPath = filepath.Join(s.deploymentPath, "tokamak-thanos", "packages", "tokamak", "contracts-bedrock")
+	}
+	return filepath.Join(bedrockPath, assetsPath)
+}
+
+// ShutdownSnapshotHash returns the sha256 of an assets snapshot
+func (s *ThanosStack) ShutdownSnapshotHash(assetsPath string) (string, error) {
+	return hashFile(s.ShutdownAssetsFile(assetsPath))
+}
+
+// ShutdownTxHashes returns the transactions forge broadcast for a shutdown step since the given time,
+// read from the broadcast log of the step script
+func (s *ThanosStack) ShutdownTxHashes(step string, since time.Time) ([]string, error) {
+	if s.deployConfig == nil {
+		return nil, fmt.Errorf("deployConfig is nil")
+	}
+	script, chainID := "", s.deployConfig.L1ChainID
+	switch step {
+	case types.ShutdownStepBlock:
+		script = shutdownBlockScript
+	case types.ShutdownStepGen:
+		script, chainID = shutdownGenScript, s.deployConfig.L2ChainID
+	case types.ShutdownStepActivate:
+		script = shutdownActivateScript
+	case types.ShutdownStepWithdraw:
+		script = shutdownWithdrawScript
+	default:
+		return nil, nil
+	}
+	bedrockPath, err := s.getBedrockPath()
+	if err != nil {
+		return nil, err
+	}
+
+	logPath := filepath.Join(bedrockPath, "broadcast", filepath.Base(script), fmt.Sprintf("%d", chainID), "run-latest.json")
+	info, err := os.Stat(logPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// An older log is from a previous run, nothing was broadcast this time
+	if info.ModTime().Before(since) {
+		return nil, nil
+	}
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		return nil, err
+	}
+	var broadcast struct {
+		Transactions []struct {
+			Hash string `json:"hash"`
+		} `json:"transactions"`
+	}
+	if err := json.Unmarshal(data, &broadcast); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", logPath, err)
+	}
+	var hashes []string
+	for _, tx := range broadcast.Transactions {
+		if tx.Hash != "" {
+			hashes = append(hashes, tx.Hash)
+		}
+	}
+	return hashes, nil
+}
+
 // forgeScriptParams holds common parameters for forge script execution
 type forgeScriptParams struct {
 	bedrockPath  string
@@ -210,8 +300,12 @@ func (s *ThanosStack) buildForgeScriptParams(scriptPath string, sig string, args
 
 	senderFlag := ""
 	for _, env := range envVars {
-		if strings.HasPrefix(env, "IMPERSONATE_SENDER=") && dryRun {
+		if strings.HasPrefix(env, "IMPERSONATE_SENDER=") && (dryRun || s.shutdownRehearsal) {
 			senderFlag = fmt.Sprintf("--sender %s", strings.TrimPrefix(env, "IMPERSONATE_SENDER="))
+			// The fork accepts the transactions of the impersonated owner unsigned
+			if !dryRun {
+				senderFlag = "--unlocked " + senderFlag
+			}
 			continue
 		}
 		filteredEnv = append(filteredEnv, env)
@@ -356,17 +450,11 @@ func (s *ThanosStack) readBedrockDeployConfigTemplate() (*types.DeployConfigTemp
 // ShutdownActivate prepares L1 withdrawal (Step 1~7) and returns the storage address if found.
 func (s *ThanosStack) ShutdownActivate(ctx context.Context, assetsPath string, dryRun bool) (string, error) {
 	s.logger.Info("Starting L1 Withdrawal Preparation (Phase 1) via Forge...")
-	bedrockPath, _ := s.getBedrockPath()
 
 	contracts, _ := s.readDeploymentContracts()
 
-	dataPath := assetsPath
-	if !filepath.IsAbs(dataPath) {
-		dataPath = filepath.Join(bedrockPath, dataPath)
-	}
-
 	extraEnv := []string{
-		fmt.Sprintf("DATA_PATH=%s", dataPath),
+		fmt.Sprintf("DATA_PATH=%s", s.ShutdownAssetsFile(assetsPath)),
 		fmt.Sprintf("DRY_RUN=%v", dryRun),
 	}
 
@@ -378,12 +466,12 @@ func (s *ThanosStack) ShutdownActivate(ctx context.Context, assetsPath string, d
 		extraEnv = append(extraEnv, fmt.Sprintf("SYSTEM_OWNER_SAFE=%s", contracts.SystemOwnerSafe))
 	}
 
-	if dryRun {
+	if dryRun || s.shutdownRehearsal {
 		// Impersonate the actual observed ProxyAdmin owner during simulation
-		extraEnv = append(extraEnv, fmt.Sprintf("IMPERSONATE_SENDER=%s", "0xC8442F4521bc6C1D39f9A93CC05B548E2cBf4952"))
+		extraEnv = append(extraEnv, fmt.Sprintf("IMPERSONATE_SENDER=%s", shutdownProxyAdminOwner))
 	}
 
-	output, err := s.runForgeScriptCapture(ctx, "scripts/shutdown/PrepareL1Withdrawal.s.sol", "run()", nil, false, extraEnv, dryRun)
+	output, err := s.runForgeScriptCapture(ctx, shutdownActivateScript, "run()", nil, false, extraEnv, dryRun)
 	if err != nil {
 		return "", err
 	}
@@ -404,18 +492,12 @@ func (s *ThanosStack) ShutdownActivate(ctx context.Context, assetsPath string, d
 // ShutdownWithdraw executes liquidity sweep and claims (Step 8~10).
 func (s *ThanosStack) ShutdownWithdraw(ctx context.Context, assetsPath string, dryRun bool, storageAddr string) error {
 	s.logger.Info("Starting L1 Asset Withdrawal Execution (Phase 2) via Forge...")
-	bedrockPath, _ := s.getBedrockPath()
 
 	contracts, _ := s.readDeploymentContracts()
 	bedrockConfig, _ := s.readBedrockDeployConfigTemplate()
 
-	dataPath := assetsPath
-	if !filepath.IsAbs(dataPath) {
-		dataPath = filepath.Join(bedrockPath, dataPath)
-	}
-
 	extraEnv := []string{
-		fmt.Sprintf("DATA_PATH=%s", dataPath),
+		fmt.Sprintf("DATA_PATH=%s", s.ShutdownAssetsFile(assetsPath)),
 		fmt.Sprintf("DRY_RUN=%v", dryRun),
 	}
 
@@ -428,7 +510,7 @@ func (s *ThanosStack) ShutdownWithdraw(ctx context.Context, assetsPath string, d
 
 	if bedrockConfig != nil {
 		extraEnv = append(extraEnv, fmt.Sprintf("L1_NATIVE_TOKEN=%s", bedrockConfig.NativeTokenAddress))
-		if dryRun {
+		if dryRun || s.shutdownRehearsal {
 			extraEnv = append(extraEnv, fmt.Sprintf("IMPERSONATE_SENDER=%s", bedrockConfig.FinalSystemOwner))
 		}
 	}
@@ -438,7 +520,7 @@ func (s *ThanosStack) ShutdownWithdraw(ctx context.Context, assetsPath string, d
 	}
 	extraEnv = append(extraEnv, fmt.Sprintf("STORAGE_ADDRESS=%s", storageAddr))
 
-	return s.runForgeScript(ctx, "scripts/shutdown/ExecuteL1Withdrawal.s.sol", "run()", nil, false, extraEnv, dryRun)
+	return s.runForgeScript(ctx, shutdownWithdrawScript, "run()", nil, false, extraEnv, dryRun)
 }
 
 // runForgeScriptCapture executes a Forge script and returns its combined output.
@@ -0,0 +1,858 @@
+package thanos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// Files written by the fetch step in the data directory of contracts-bedrock, formatted with the L2 chain ID
+const (
+	ShutdownHoldersFile         = "l2-holders-%d.json"
+	ShutdownContractsFile       = "l2-contracts-%d.json"
+	ShutdownTokensFile          = "l2-tokens-%d.json"
+	ShutdownBurnsFile           = "l2-burns-%d.json"
+	ShutdownUnclaimedFile       = "unclaimed-withdrawals-%d.json"
+	ShutdownFetchCheckpointFile = "fetch-checkpoint-%d.json"
+)
+
+const (
+	defaultShutdownFetchWorkers    = 4
+	defaultShutdownFetchBlockRange = uint64(2000)
+	// shutdownFetchBlockBatch is the number of blocks read in one JSON-RPC batch
+	shutdownFetchBlockBatch         = uint64(100)
+	shutdownFetchCheckpointInterval = 10 * time.Second
+)
+
+// shutdownFetchABIJSON has the events indexed by the fetch step and the views it reads
+const shutdownFetchABIJSON = `[
+  {"type":"event","name":"Transfer","inputs":[
+    {"name":"from","type":"address","indexed":true},
+    {"name":"to","type":"address","indexed":true},
+    {"name":"value","type":"uint256","indexed":false}]},
+  {"type":"event","name":"DepositFinalized","inputs":[
+    {"name":"l1Token","type":"address","indexed":true},
+    {"name":"l2Token","type":"address","indexed":true},
+    {"name":"from","type":"address","indexed":true},
+    {"name":"to","type":"address","indexed":false},
+    {"name":"amount","type":"uint256","indexed":false},
+    {"name":"extraData","type":"bytes","indexed":false}]},
+  {"type":"event","name":"WithdrawalInitiated","inputs":[
+    {"name":"l1Token","type":"address","indexed":true},
+    {"name":"l2Token","type":"address","indexed":true},
+    {"name":"from","type":"address","indexed":true},
+    {"name":"to","type":"address","indexed":false},
+    {"name":"amount","type":"uint256","indexed":false},
+    {"name":"extraData","type":"bytes","indexed":false}]},
+  {"type":"event","name":"MessagePassed","inputs":[
+    {"name":"nonce","type":"uint256","indexed":true},
+    {"name":"sender","type":"address","indexed":true},
+    {"name":"target","type":"address","indexed":true},
+    {"name":"value","type":"uint256","indexed":false},
+    {"name":"gasLimit","type":"uint256","indexed":false},
+    {"name":"data","type":"bytes","indexed":false},
+    {"name":"withdrawalHash","type":"bytes32","indexed":false}]},
+  {"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+  {"type":"function","name":"totalSupply","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+  {"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]},
+  {"type":"function","name":"symbol","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+  {"type":"function","name":"remoteToken","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+  {"type":"function","name":"l1Token","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+  {"type":"function","name":"finalizedWithdrawals","stateMutability":"view","inputs":[{"name":"","type":"bytes32"}],"outputs":[{"name":"","type":"bool"}]}
+]`
+
+var shutdownFetchABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(shutdownFetchABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// shutdownFetchClient is the L2 RPC used by the fetch step
+type shutdownFetchClient interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]ethTypes.Log, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	// blockAccounts returns the senders and recipients of the transactions in blocks from to to, and the
+	// contracts they created
+	blockAccounts(ctx context.Context, from, to uint64) ([]common.Address, error)
+}
+
+// rpcShutdownFetchClient reads blocks with raw JSON-RPC, go-ethereum does not decode the deposit
+// transactions of op-geth
+type rpcShutdownFetchClient struct {
+	*ethclient.Client
+	raw *rpc.Client
+}
+
+type shutdownFetchRPCBlock struct {
+	Transactions []struct {
+		Hash common.Hash     `json:"hash"`
+		From common.Address  `json:"from"`
+		To   *common.Address `json:"to"`
+	} `json:"transactions"`
+}
+
+func (c *rpcShutdownFetchClient) blockAccounts(ctx context.Context, from, to uint64) ([]common.Address, error) {
+	var accounts []common.Address
+	for start := from; start <= to; start += shutdownFetchBlockBatch {
+		end := min(start+shutdownFetchBlockBatch-1, to)
+		blocks := make([]shutdownFetchRPCBlock, end-start+1)
+		batch := make([]rpc.BatchElem, len(blocks))
+		for i := range batch {
+			batch[i] = rpc.BatchElem{
+				Method: "eth_getBlockByNumber",
+				Args:   []interface{}{hexutil.EncodeUint64(start + uint64(i)), true},
+				Result: &blocks[i],
+			}
+		}
+		if err := rpcCallWithRetry(ctx, func() error { return c.raw.BatchCallContext(ctx, batch) }); err != nil {
+			return nil, fmt.Errorf("failed to read blocks %d-%d: %w", start, end, err)
+		}
+		for i, elem := range batch {
+			if elem.Error != nil {
+				return nil, fmt.Errorf("failed to read block %d: %w", start+uint64(i), elem.Error)
+			}
+			for _, tx := range blocks[i].Transactions {
+				accounts = append(accounts, tx.From)
+				if tx.To != nil {
+					accounts = append(accounts, *tx.To)
+					continue
+				}
+				var receipt struct {
+					ContractAddress *common.Address `json:"contractAddress"`
+				}
+				if err := rpcCallWithRetry(ctx, func() error {
+					return c.raw.CallContext(ctx, &receipt, "eth_getTransactionReceipt", tx.Hash)
+				}); err != nil {
+					return nil, fmt.Errorf("failed to read the receipt of %s: %w", tx.Hash.Hex(), err)
+				}
+				if receipt.ContractAddress != nil {
+					accounts = append(accounts, *receipt.ContractAddress)
+				}
+			}
+		}
+	}
+	return accounts, nil
+}
+
+// shutdownBlockRange is an inclusive range of L2 blocks
+type shutdownBlockRange struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+// shutdownFetchCheckpoint is the progress of the fetch step, saved in the data directory so an interrupted
+// fetch resumes where it stopped and a later fetch only scans the new blocks
+type shutdownFetchCheckpoint struct {
+	L2ChainID uint64 `json:"l2ChainId"`
+	// Scanned are the block ranges already indexed, merged when adjacent
+	Scanned []shutdownBlockRange `json:"scanned"`
+	// Accounts may hold native tokens, they sent or received a transaction or a bridge deposit
+	Accounts []string `json:"accounts"`
+	// Tokens maps the L2 tokens to the accounts they were transferred from or to
+	Tokens map[string][]string `json:"tokens"`
+	// L1Tokens maps the L2 tokens deposited through the standard bridge to their L1 token
+	L1Tokens    map[string]string          `json:"l1Tokens"`
+	Burns       []types.ShutdownBurn       `json:"burns"`
+	Withdrawals []types.ShutdownWithdrawal `json:"withdrawals"`
+	UpdatedAt   time.Time                  `json:"updatedAt"`
+}
+
+// shutdownIndex is what the scan found so far. Merging is idempotent, a range scanned twice does not
+// duplicate entries.
+type shutdownIndex struct {
+	scanned     []shutdownBlockRange
+	accounts    map[common.Address]bool
+	tokens      map[common.Address]map[common.Address]bool
+	l1Tokens    map[common.Address]common.Address
+	burns       map[string]types.ShutdownBurn
+	withdrawals map[string]types.ShutdownWithdrawal
+}
+
+func newShutdownIndex() *shutdownIndex {
+	return &shutdownIndex{
+		accounts:    map[common.Address]bool{},
+		tokens:      map[common.Address]map[common.Address]bool{},
+		l1Tokens:    map[common.Address]common.Address{},
+		burns:       map[string]types.ShutdownBurn{},
+		withdrawals: map[string]types.ShutdownWithdrawal{},
+	}
+}
+
+func (x *shutdownIndex) addAccount(account common.Address) {
+	if account != (common.Address{}) {
+		x.accounts[account] = true
+	}
+}
+
+func (x *shutdownIndex) addHolder(token, account common.Address) {
+	if account == (common.Address{}) {
+		return
+	}
+	if x.tokens[token] == nil {
+		x.tokens[token] = map[common.Address]bool{}
+	}
+	x.tokens[token][account] = true
+	x.accounts[account] = true
+}
+
+func (x *shutdownIndex) merge(other *shutdownIndex) {
+	for account := range other.accounts {
+		x.addAccount(account)
+	}
+	for token, holders := range other.tokens {
+		for holder := range holders {
+			x.addHolder(token, holder)
+		}
+	}
+	for l2Token, l1Token := range other.l1Tokens {
+		x.l1Tokens[l2Token] = l1Token
+	}
+	for key, burn := range other.burns {
+		x.burns[key] = burn
+	}
+	for key, withdrawal := range other.withdrawals {
+		x.withdrawals[key] = withdrawal
+	}
+	for _, r := range other.scanned {
+		x.scanned = addScannedRange(x.scanned, r)
+	}
+}
+
+func (x *shutdownIndex) checkpoint(l2ChainID uint64) *shutdownFetchCheckpoint {
+	cp := &shutdownFetchCheckpoint{
+		L2ChainID: l2ChainID,
+		Scanned:   x.scanned,
+		Accounts:  sortedAddresses(x.accounts),
+		Tokens:    map[string][]string{},
+		L1Tokens:  map[string]string{},
+		UpdatedAt: time.Now().UTC(),
+	}
+	for token, holders := range x.tokens {
+		cp.Tokens[token.Hex()] = sortedAddresses(holders)
+	}
+	for l2Token, l1Token := range x.l1Tokens {
+		cp.L1Tokens[l2Token.Hex()] = l1Token.Hex()
+	}
+	cp.Burns = sortedShutdownBurns(x.burns)
+	cp.Withdrawals = sortedShutdownWithdrawals(x.withdrawals)
+	return cp
+}
+
+func shutdownIndexFromCheckpoint(cp *shutdownFetchCheckpoint) *shutdownIndex {
+	x := newShutdownIndex()
+	x.scanned = cp.Scanned
+	for _, account := range cp.Accounts {
+		x.addAccount(common.HexToAddress(account))
+	}
+	for token, holders := range cp.Tokens {
+		for _, holder := range holders {
+			x.addHolder(common.HexToAddress(token), common.HexToAddress(holder))
+		}
+	}
+	for l2Token, l1Token := range cp.L1Tokens {
+		x.l1Tokens[common.HexToAddress(l2Token)] = common.HexToAddress(l1Token)
+	}
+	for _, burn := range cp.Burns {
+		x.burns[shutdownBurnKey(burn)] = burn
+	}
+	for _, withdrawal := range cp.Withdrawals {
+		x.withdrawals[withdrawal.WithdrawalHash] = withdrawal
+	}
+	return x
+}
+
+// addScannedRange records r as scanned, merged with the ranges it overlaps or touches
+func addScannedRange(scanned []shutdownBlockRange, r shutdownBlockRange) []shutdownBlockRange {
+	ranges := append(append([]shutdownBlockRange{}, scanned...), r)
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].From < ranges[j].From })
+	merged := []shutdownBlockRange{ranges[0]}
+	for _, next := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if next.From <= last.To+1 {
+			last.To = max(last.To, next.To)
+			continue
+		}
+		merged = append(merged, next)
+	}
+	return merged
+}
+
+// pendingBlockRanges splits the blocks up to head that are not scanned yet into ranges of size blocks
+func pendingBlockRanges(scanned []shutdownBlockRange, head, size uint64) []shutdownBlockRange {
+	var pending []shutdownBlockRange
+	split := func(from, to uint64) {
+		for from <= to {
+			end := min(from+size-1, to)
+			pending = append(pending, shutdownBlockRange{From: from, To: end})
+			from = end + 1
+		}
+	}
+	next := uint64(0)
+	for _, r := range scanned {
+		if r.From > head {
+			break
+		}
+		if r.From > next {
+			split(next, r.From-1)
+		}
+		next = max(next, r.To+1)
+	}
+	if next <= head {
+		split(next, head)
+	}
+	return pending
+}
+
+func shutdownBurnKey(burn types.ShutdownBurn) string {
+	return fmt.Sprintf("%s:%d", burn.TxHash, burn.LogIndex)
+}
+
+func sortedAddresses(set map[common.Address]bool) []string {
+	addresses := make([]string, 0, len(set))
+	for address := range set {
+		addresses = append(addresses, address.Hex())
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+func sortedShutdownBurns(burns map[string]types.ShutdownBurn) []types.ShutdownBurn {
+	sorted := make([]types.ShutdownBurn, 0, len(burns))
+	for _, burn := range burns {
+		sorted = append(sorted, burn)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].BlockNumber != sorted[j].BlockNumber {
+			return sorted[i].BlockNumber < sorted[j].BlockNumber
+		}
+		return sorted[i].LogIndex < sorted[j].LogIndex
+	})
+	return sorted
+}
+
+func sortedShutdownWithdrawals(withdrawals map[string]types.ShutdownWithdrawal) []types.ShutdownWithdrawal {
+	sorted := make([]types.ShutdownWithdrawal, 0, len(withdrawals))
+	for _, withdrawal := range withdrawals {
+		sorted = append(sorted, withdrawal)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].BlockNumber != sorted[j].BlockNumber {
+			return sorted[i].BlockNumber < sorted[j].BlockNumber
+		}
+		return sorted[i].WithdrawalHash < sorted[j].WithdrawalHash
+	})
+	return sorted
+}
+
+// shutdownFetcher indexes the assets of the L2 for the force withdrawal snapshot
+type shutdownFetcher struct {
+	logger     *zap.SugaredLogger
+	l2         shutdownFetchClient
+	l1         ethereum.ContractCaller
+	portal     common.Address
+	l2ChainID  uint64
+	workers    int
+	blockRange uint64
+	// checkpointPath is where the progress is saved, empty keeps it in memory only
+	checkpointPath string
+
+	mu        sync.Mutex
+	index     *shutdownIndex
+	lastSaved time.Time
+}
+
+// loadCheckpoint resumes from the saved progress of the same chain
+func (f *shutdownFetcher) loadCheckpoint() error {
+	f.index = newShutdownIndex()
+	if f.checkpointPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(f.checkpointPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the fetch checkpoint: %w", err)
+	}
+	var cp shutdownFetchCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return fmt.Errorf("failed to parse the fetch checkpoint %s: %w", f.checkpointPath, err)
+	}
+	if cp.L2ChainID != f.l2ChainID {
+		f.logger.Warnf("Ignoring the fetch checkpoint of chain %d", cp.L2ChainID)
+		return nil
+	}
+	f.index = shutdownIndexFromCheckpoint(&cp)
+	return nil
+}
+
+// saveCheckpoint writes the progress, the caller holds f.mu
+func (f *shutdownFetcher) saveCheckpoint() error {
+	if f.checkpointPath == "" {
+		return nil
+	}
+	data, err := json.Marshal(f.index.checkpoint(f.l2ChainID))
+	if err != nil {
+		return fmt.Errorf("failed to marshal the fetch checkpoint: %w", err)
+	}
+	tempPath := f.checkpointPath + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write the fetch checkpoint: %w", err)
+	}
+	if err := os.Rename(tempPath, f.checkpointPath); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write the fetch checkpoint: %w", err)
+	}
+	f.lastSaved = time.Now()
+	return nil
+}
+
+// addGenesisAccounts adds the accounts funded in genesis.json, they hold native tokens without a transaction
+func (f *shutdownFetcher) addGenesisAccounts(genesisPath string) error {
+	data, err := os.ReadFile(genesisPath)
+	if os.IsNotExist(err) {
+		f.logger.Infof("%s not found, only accounts seen in transactions are indexed", genesisPath)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", genesisPath, err)
+	}
+	var genesis struct {
+		Alloc map[string]struct {
+			Balance *hexutil.Big `json:"balance"`
+		} `json:"alloc"`
+	}
+	if err := json.Unmarshal(data, &genesis); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", genesisPath, err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for account, alloc := range genesis.Alloc {
+		if alloc.Balance != nil && alloc.Balance.ToInt().Sign() > 0 {
+			f.index.addAccount(common.HexToAddress(account))
+		}
+	}
+	return nil
+}
+
+// scan indexes the blocks up to head that are not in the checkpoint, f.workers ranges at a time
+func (f *shutdownFetcher) scan(ctx context.Context, head uint64) error {
+	pending := pendingBlockRanges(f.index.scanned, head, f.blockRange)
+	if len(pending) == 0 {
+		f.logger.Infof("Blocks 0-%d are already indexed", head)
+		return nil
+	}
+	total := uint64(0)
+	for _, r := range pending {
+		total += r.To - r.From + 1
+	}
+	f.logger.Infof("Indexing %d blocks up to %d with %d workers", total, head, f.workers)
+
+	done := uint64(0)
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(f.workers)
+	for _, r := range pending {
+		if gctx.Err() != nil {
+			break
+		}
+		g.Go(func() error {
+			result, err := f.scanRange(gctx, r)
+			if err != nil {
+				return err
+			}
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.index.merge(result)
+			done += r.To - r.From + 1
+			if time.Since(f.lastSaved) < shutdownFetchCheckpointInterval {
+				return nil
+			}
+			f.logger.Infof("Indexed %d/%d blocks", done, total)
+			return f.saveCheckpoint()
+		})
+	}
+	err := g.Wait()
+
+	// Whatever was indexed is kept, a failed or interrupted fetch resumes from it
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if saveErr := f.saveCheckpoint(); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to index the L2 blocks: %w", err)
+	}
+	f.logger.Infof("Indexed %d/%d blocks", done, total)
+	return nil
+}
+
+// scanRange indexes the transactions, ERC20 transfers, bridge deposits and withdrawals of the blocks in r
+func (f *shutdownFetcher) scanRange(ctx context.Context, r shutdownBlockRange) (*shutdownIndex, error) {
+	result := newShutdownIndex()
+	accounts, err := f.l2.blockAccounts(ctx, r.From, r.To)
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		result.addAccount(account)
+	}
+
+	events := shutdownFetchABI.Events
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(r.From),
+		ToBlock:   new(big.Int).SetUint64(r.To),
+		Topics: [][]common.Hash{{
+			events["Transfer"].ID,
+			events["DepositFinalized"].ID,
+			events["WithdrawalInitiated"].ID,
+			events["MessagePassed"].ID,
+		}},
+	}
+	var logs []ethTypes.Log
+	if err := rpcCallWithRetry(ctx, func() error {
+		logs, err = f.l2.FilterLogs(ctx, query)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read the logs of blocks %d-%d: %w", r.From, r.To, err)
+	}
+
+	bridge := common.HexToAddress(constants.L2StandardBridge)
+	messagePasser := common.HexToAddress(constants.L2ToL1MessagePasser)
+	for _, log := range logs {
+		if log.Removed || len(log.Topics) == 0 {
+			continue
+		}
+		switch log.Topics[0] {
+		case events["Transfer"].ID:
+			// ERC721 transfers have the token ID as third indexed argument and no data
+			if len(log.Topics) != 3 || len(log.Data) != 32 {
+				continue
+			}
+			result.addHolder(log.Address, common.BytesToAddress(log.Topics[1].Bytes()))
+			result.addHolder(log.Address, common.BytesToAddress(log.Topics[2].Bytes()))
+		case events["DepositFinalized"].ID:
+			if log.Address != bridge || len(log.Topics) != 4 {
+				continue
+			}
+			values, err := shutdownFetchABI.Unpack("DepositFinalized", log.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode DepositFinalized in %s: %w", log.TxHash.Hex(), err)
+			}
+			result.l1Tokens[common.BytesToAddress(log.Topics[2].Bytes())] = common.BytesToAddress(log.Topics[1].Bytes())
+			// Native deposits are sent to the recipient by the bridge, not by a transaction
+			result.addAccount(values[0].(common.Address))
+		case events["WithdrawalInitiated"].ID:
+			if log.Address != bridge || len(log.Topics) != 4 {
+				continue
+			}
+			values, err := shutdownFetchABI.Unpack("WithdrawalInitiated", log.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode WithdrawalInitiated in %s: %w", log.TxHash.Hex(), err)
+			}
+			burn := types.ShutdownBurn{
+				BlockNumber: log.BlockNumber,
+				TxHash:      log.TxHash.Hex(),
+				LogIndex:    log.Index,
+				L1Token:     common.BytesToAddress(log.Topics[1].Bytes()).Hex(),
+				L2Token:     common.BytesToAddress(log.Topics[2].Bytes()).Hex(),
+				From:        common.BytesToAddress(log.Topics[3].Bytes()).Hex(),
+				To:          values[0].(common.Address).Hex(),
+				Amount:      values[1].(*big.Int).String(),
+			}
+			result.burns[shutdownBurnKey(burn)] = burn
+		case events["MessagePassed"].ID:
+			if log.Address != messagePasser || len(log.Topics) != 4 {
+				continue
+			}
+			values, err := shutdownFetchABI.Unpack("MessagePassed", log.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode MessagePassed in %s: %w", log.TxHash.Hex(), err)
+			}
+			hash := common.Hash(values[3].([32]byte)).Hex()
+			result.withdrawals[hash] = types.ShutdownWithdrawal{
+				WithdrawalHash: hash,
+				Nonce:          log.Topics[1].Big().String(),
+				Sender:         common.BytesToAddress(log.Topics[2].Bytes()).Hex(),
+				Target:         common.BytesToAddress(log.Topics[3].Bytes()).Hex(),
+				Value:          values[0].(*big.Int).String(),
+				GasLimit:       values[1].(*big.Int).String(),
+				Data:           hexutil.Encode(values[2].([]byte)),
+				BlockNumber:    log.BlockNumber,
+				TxHash:         log.TxHash.Hex(),
+			}
+		}
+	}
+	result.scanned = []shutdownBlockRange{r}
+	return result, nil
+}
+
+// shutdownFetchResult is the content of the data files of the fetch step
+type shutdownFetchResult struct {
+	Holders   []types.ShutdownHolder
+	Contracts []types.ShutdownContract
+	Tokens    []types.ShutdownToken
+	Burns     []types.ShutdownBurn
+	Unclaimed []types.ShutdownWithdrawal
+}
+
+// collect reads the balances of the indexed accounts at block head and the withdrawals not finalized on L1
+func (f *shutdownFetcher) collect(ctx context.Context, head uint64) (*shutdownFetchResult, error) {
+	block := new(big.Int).SetUint64(head)
+	result := &shutdownFetchResult{
+		Holders:   []types.ShutdownHolder{},
+		Contracts: []types.ShutdownContract{},
+		Tokens:    []types.ShutdownToken{},
+		Burns:     sortedShutdownBurns(f.index.burns),
+		Unclaimed: []types.ShutdownWithdrawal{},
+	}
+	var mu sync.Mutex
+
+	// Tokens, skipping the contracts that emit Transfer but are not ERC20
+	tokens := make([]common.Address, 0, len(f.index.tokens))
+	for token := range f.index.tokens {
+		tokens = append(tokens, token)
+	}
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(f.workers)
+	for _, token := range tokens {
+		g.Go(func() error {
+			entry, err := f.readToken(gctx, token, block)
+			if err != nil {
+				f.logger.Warnf("Skipping token %s: %v", token.Hex(), err)
+				return nil
+			}
+			mu.Lock()
+			result.Tokens = append(result.Tokens, entry)
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	sort.Slice(result.Tokens, func(i, j int) bool { return result.Tokens[i].Address < result.Tokens[j].Address })
+
+	// Balances, the native token first
+	type balanceQuery struct {
+		token, account common.Address
+	}
+	native := common.HexToAddress(constants.NativeToken)
+	var queries []balanceQuery
+	for _, account := range sortedAddresses(f.index.accounts) {
+		queries = append(queries, balanceQuery{token: native, account: common.HexToAddress(account)})
+	}
+	for _, token := range result.Tokens {
+		address := common.HexToAddress(token.Address)
+		for _, account := range sortedAddresses(f.index.tokens[address]) {
+			queries = append(queries, balanceQuery{token: address, account: common.HexToAddress(account)})
+		}
+	}
+	f.logger.Infof("Reading %d balances at block %d", len(queries), head)
+	holders := map[string]int{}
+	g, gctx = errgroup.WithContext(ctx)
+	g.SetLimit(f.workers)
+	for _, q := range queries {
+		g.Go(func() error {
+			var balance *big.Int
+			err := rpcCallWithRetry(gctx, func() error {
+				var err error
+				if q.token == native {
+					balance, err = f.l2.BalanceAt(gctx, q.account, block)
+					return err
+				}
+				values, err := callShutdownFetchABI(gctx, f.l2, q.token, block, "balanceOf", q.account)
+				if err == nil {
+					balance = values[0].(*big.Int)
+				}
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to read the balance of %s in %s: %w", q.account.Hex(), q.token.Hex(), err)
+			}
+			if balance.Sign() == 0 {
+				return nil
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			result.Holders = append(result.Holders, types.ShutdownHolder{Address: q.account.Hex(), Token: q.token.Hex(), Balance: balance.String()})
+			holders[q.token.Hex()]++
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	for i := range result.Tokens {
+		result.Tokens[i].Holders = holders[result.Tokens[i].Address]
+	}
+
+	// Contracts among the holders
+	codeHashes := map[string]string{}
+	for _, holder := range result.Holders {
+		codeHashes[holder.Address] = ""
+	}
+	g, gctx = errgroup.WithContext(ctx)
+	g.SetLimit(f.workers)
+	for account := range codeHashes {
+		g.Go(func() error {
+			var code []byte
+			if err := rpcCallWithRetry(gctx, func() error {
+				var err error
+				code, err = f.l2.CodeAt(gctx, common.HexToAddress(account), block)
+				return err
+			}); err != nil {
+				return fmt.Errorf("failed to read the code of %s: %w", account, err)
+			}
+			if len(code) == 0 {
+				return nil
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			result.Contracts = append(result.Contracts, types.ShutdownContract{Address: account, CodeHash: crypto.Keccak256Hash(code).Hex()})
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	sort.Slice(result.Contracts, func(i, j int) bool { return result.Contracts[i].Address < result.Contracts[j].Address })
+	contracts := map[string]bool{}
+	for _, contract := range result.Contracts {
+		contracts[contract.Address] = true
+	}
+	for i := range result.Holders {
+		result.Holders[i].IsContract = contracts[result.Holders[i].Address]
+	}
+	sort.Slice(result.Holders, func(i, j int) bool {
+		if result.Holders[i].Token != result.Holders[j].Token {
+			return result.Holders[i].Token < result.Holders[j].Token
+		}
+		return result.Holders[i].Address < result.Holders[j].Address
+	})
+
+	// Withdrawals not finalized on the OptimismPortal
+	withdrawals := sortedShutdownWithdrawals(f.index.withdrawals)
+	finalized := make([]bool, len(withdrawals))
+	g, gctx = errgroup.WithContext(ctx)
+	g.SetLimit(f.workers)
+	for i, withdrawal := range withdrawals {
+		hash := common.HexToHash(withdrawal.WithdrawalHash)
+		g.Go(func() error {
+			return rpcCallWithRetry(gctx, func() error {
+				values, err := callShutdownFetchABI(gctx, f.l1, f.portal, nil, "finalizedWithdrawals", hash)
+				if err != nil {
+					return fmt.Errorf("failed to check withdrawal %s on L1: %w", hash.Hex(), err)
+				}
+				finalized[i] = values[0].(bool)
+				return nil
+			})
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	for i, withdrawal := range withdrawals {
+		if !finalized[i] {
+			result.Unclaimed = append(result.Unclaimed, withdrawal)
+		}
+	}
+	return result, nil
+}
+
+// readToken reads the ERC20 metadata of token, an error means it is not an ERC20
+func (f *shutdownFetcher) readToken(ctx context.Context, token common.Address, block *big.Int) (types.ShutdownToken, error) {
+	entry := types.ShutdownToken{Address: token.Hex()}
+	var supply []interface{}
+	if err := rpcCallWithRetry(ctx, func() error {
+		var err error
+		supply, err = callShutdownFetchABI(ctx, f.l2, token, block, "totalSupply")
+		return err
+	}); err != nil {
+		return entry, fmt.Errorf("totalSupply failed: %w", err)
+	}
+	entry.TotalSupply = supply[0].(*big.Int).String()
+	if values, err := callShutdownFetchABI(ctx, f.l2, token, block, "decimals"); err == nil {
+		entry.Decimals = values[0].(uint8)
+	}
+	if values, err := callShutdownFetchABI(ctx, f.l2, token, block, "symbol"); err == nil {
+		entry.Symbol = values[0].(string)
+	}
+
+	// Tokens deposited through the bridge are known from DepositFinalized, the others are asked for it
+	l1Token := f.index.l1Tokens[token]
+	for _, method := range []string{"remoteToken", "l1Token"} {
+		if l1Token != (common.Address{}) {
+			break
+		}
+		if values, err := callShutdownFetchABI(ctx, f.l2, token, block, method); err == nil {
+			l1Token = values[0].(common.Address)
+		}
+	}
+	if l1Token != (common.Address{}) {
+		entry.L1Token = l1Token.Hex()
+	}
+	return entry, nil
+}
+
+func callShutdownFetchABI(ctx context.Context, client ethereum.ContractCaller, contract common.Address, block *big.Int, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := shutdownFetchABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	output, err := client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, block)
+	if err != nil {
+		return nil, err
+	}
+	return shutdownFetchABI.Unpack(method, output)
+}
+
+// writeShutdownFetchResult writes the data files read by GenerateAssetSnapshot.s.sol
+func writeShutdownFetchResult(dataDir string, l2ChainID uint64, result *shutdownFetchResult) error {
+	files := []struct {
+		name    string
+		entries interface{}
+	}{
+		{ShutdownHoldersFile, result.Holders},
+		{ShutdownContractsFile, result.Contracts},
+		{ShutdownTokensFile, result.Tokens},
+		{ShutdownBurnsFile, result.Burns},
+		{ShutdownUnclaimedFile, result.Unclaimed},
+	}
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", file.name, err)
+		}
+		path := filepath.Join(dataDir, fmt.Sprintf(file.name, l2ChainID))
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package thanos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// fakeShutdownFetchClient serves the L2 and L1 calls of the fetch step from memory
+type fakeShutdownFetchClient struct {
+	mu       sync.Mutex
+	accounts map[uint64][]common.Address
+	logs     []ethTypes.Log
+	balances map[common.Address]*big.Int
+	tokens   map[common.Address]map[common.Address]*big.Int
+	code     map[common.Address][]byte
+	// finalized are the withdrawal hashes finalized on L1
+	finalized map[common.Hash]bool
+	// failFrom makes FilterLogs fail for ranges starting at or after it, 0 never fails
+	failFrom uint64
+	scanned  []uint64
+}
+
+func (c *fakeShutdownFetchClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return 0, nil
+}
+
+func (c *fakeShutdownFetchClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]ethTypes.Log, error) {
+	from, to := q.FromBlock.Uint64(), q.ToBlock.Uint64()
+	if c.failFrom != 0 && from >= c.failFrom {
+		return nil, errors.New("connection refused")
+	}
+	c.mu.Lock()
+	c.scanned = append(c.scanned, from)
+	c.mu.Unlock()
+	var logs []ethTypes.Log
+	for _, log := range c.logs {
+		if log.BlockNumber >= from && log.BlockNumber <= to {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func (c *fakeShutdownFetchClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	if balance, ok := c.balances[account]; ok {
+		return balance, nil
+	}
+	return big.NewInt(0), nil
+}
+
+func (c *fakeShutdownFetchClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return c.code[account], nil
+}
+
+func (c *fakeShutdownFetchClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	for name, method := range shutdownFetchABI.Methods {
+		if !bytes.Equal(msg.Data[:4], method.ID) {
+			continue
+		}
+		args, err := method.Inputs.Unpack(msg.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+		holders, isToken := c.tokens[*msg.To]
+		switch {
+		case name == "finalizedWithdrawals":
+			return method.Outputs.Pack(c.finalized[common.Hash(args[0].([32]byte))])
+		case !isToken:
+			return nil, errors.New("execution reverted")
+		case name == "balanceOf":
+			balance, ok := holders[args[0].(common.Address)]
+			if !ok {
+				balance = big.NewInt(0)
+			}
+			return method.Outputs.Pack(balance)
+		case name == "totalSupply":
+			total := big.NewInt(0)
+			for _, balance := range holders {
+				total.Add(total, balance)
+			}
+			return method.Outputs.Pack(total)
+		case name == "decimals":
+			return method.Outputs.Pack(uint8(18))
+		case name == "symbol":
+			return method.Outputs.Pack("TKN")
+		}
+		return nil, errors.New("execution reverted")
+	}
+	return nil, fmt.Errorf("unknown call %x", msg.Data)
+}
+
+func (c *fakeShutdownFetchClient) blockAccounts(ctx context.Context, from, to uint64) ([]common.Address, error) {
+	var accounts []common.Address
+	for block := from; block <= to; block++ {
+		accounts = append(accounts, c.accounts[block]...)
+	}
+	return accounts, nil
+}
+
+func shutdownFetchLog(t *testing.T, block uint64, index uint, contract common.Address, event string, topics []common.Hash, args ...interface{}) ethTypes.Log {
+	data, err := shutdownFetchABI.Events[event].Inputs.NonIndexed().Pack(args...)
+	require.NoError(t, err)
+	return ethTypes.Log{
+		Address:     contract,
+		Topics:      append([]common.Hash{shutdownFetchABI.Events[event].ID}, topics...),
+		Data:        data,
+		BlockNumber: block,
+		TxHash:      common.BigToHash(big.NewInt(int64(block))),
+		Index:       index,
+	}
+}
+
+func TestPendingBlockRanges(t *testing.T) {
+	require.Equal(t, []shutdownBlockRange{{0, 9}, {10, 19}, {20, 24}}, pendingBlockRanges(nil, 24, 10))
+
+	scanned := addScannedRange(nil, shutdownBlockRange{10, 19})
+	scanned = addScannedRange(scanned, shutdownBlockRange{30, 39})
+	scanned = addScannedRange(scanned, shutdownBlockRange{20, 29})
+	require.Equal(t, []shutdownBlockRange{{10, 39}}, scanned)
+	require.Equal(t, []shutdownBlockRange{{0, 9}, {40, 44}}, pendingBlockRanges(scanned, 44, 10))
+	require.Equal(t, []shutdownBlockRange{{0, 9}}, pendingBlockRanges(scanned, 39, 10))
+
+	scanned = addScannedRange(scanned, shutdownBlockRange{0, 4})
+	require.Equal(t, []shutdownBlockRange{{0, 4}, {10, 39}}, scanned)
+	require.Equal(t, []shutdownBlockRange{{5, 9}}, pendingBlockRanges(scanned, 39, 10))
+}
+
+func TestShutdownFetchResumesFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	alice := common.HexToAddress("0xa1")
+	bob := common.HexToAddress("0xb0")
+	client := &fakeShutdownFetchClient{
+		accounts: map[uint64][]common.Address{5: {alice}, 25: {bob}},
+		failFrom: 20,
+	}
+	checkpointPath := filepath.Join(t.TempDir(), fmt.Sprintf(ShutdownFetchCheckpointFile, 1001))
+	newFetcher := func() *shutdownFetcher {
+		f := &shutdownFetcher{logger: zap.NewNop().Sugar(), l2: client, l1: client, l2ChainID: 1001, workers: 1, blockRange: 10, checkpointPath: checkpointPath}
+		require.NoError(t, f.loadCheckpoint())
+		return f
+	}
+
+	f := newFetcher()
+	require.ErrorContains(t, f.scan(ctx, 29), "connection refused")
+	require.FileExists(t, checkpointPath, "the progress is kept when the scan fails")
+
+	client.failFrom = 0
+	client.scanned = nil
+	f = newFetcher()
+	require.Equal(t, []shutdownBlockRange{{0, 19}}, f.index.scanned)
+	require.True(t, f.index.accounts[alice])
+	require.NoError(t, f.scan(ctx, 29))
+	require.Equal(t, []uint64{20}, client.scanned, "only the blocks missing from the checkpoint are scanned")
+	require.True(t, f.index.accounts[bob])
+
+	// The checkpoint of another chain is ignored
+	f = &shutdownFetcher{logger: zap.NewNop().Sugar(), l2ChainID: 2002, checkpointPath: checkpointPath}
+	require.NoError(t, f.loadCheckpoint())
+	require.Empty(t, f.index.scanned)
+}
+
+func TestShutdownFetchCollect(t *testing.T) {
+	ctx := context.Background()
+	alice := common.HexToAddress("0xa1")
+	bob := common.HexToAddress("0xb0")
+	vault := common.HexToAddress("0xc0")
+	token := common.HexToAddress("0x70")
+	nft := common.HexToAddress("0x71")
+	l1Token := common.HexToAddress("0x1170")
+	bridge := common.HexToAddress(constants.L2StandardBridge)
+	messagePasser := common.HexToAddress(constants.L2ToL1MessagePasser)
+	topic := func(address common.Address) common.Hash { return common.BytesToHash(address.Bytes()) }
+	claimed, unclaimed := common.HexToHash("0x01"), common.HexToHash("0x02")
+
+	client := &fakeShutdownFetchClient{
+		accounts: map[uint64][]common.Address{1: {alice, token}},
+		logs: []ethTypes.Log{
+			shutdownFetchLog(t, 2, 0, token, "Transfer", []common.Hash{topic(common.Address{}), topic(alice)}, big.NewInt(100)),
+			shutdownFetchLog(t, 2, 1, bridge, "DepositFinalized", []common.Hash{topic(l1Token), topic(token), topic(alice)}, alice, big.NewInt(100), []byte{}),
+			shutdownFetchLog(t, 3, 0, token, "Transfer", []common.Hash{topic(alice), topic(vault)}, big.NewInt(40)),
+			// An ERC721 transfer has the token ID as third topic
+			{Address: nft, Topics: []common.Hash{shutdownFetchABI.Events["Transfer"].ID, topic(common.Address{}), topic(bob), common.BigToHash(big.NewInt(1))}, BlockNumber: 3},
+			// Native deposits reach bob through the bridge
+			shutdownFetchLog(t, 4, 0, bridge, "DepositFinalized", []common.Hash{topic(common.Address{}), topic(common.Address{}), topic(bob)}, bob, big.NewInt(7), []byte{}),
+			shutdownFetchLog(t, 5, 0, bridge, "WithdrawalInitiated", []common.Hash{topic(l1Token), topic(token), topic(alice)}, alice, big.NewInt(10), []byte{}),
+			shutdownFetchLog(t, 5, 1, messagePasser, "MessagePassed", []common.Hash{common.BigToHash(big.NewInt(1)), topic(bridge), topic(bridge)}, big.NewInt(0), big.NewInt(200000), []byte{0xab}, [32]byte(claimed)),
+			shutdownFetchLog(t, 6, 0, messagePasser, "MessagePassed", []common.Hash{common.BigToHash(big.NewInt(2)), topic(alice), topic(alice)}, big.NewInt(5), big.NewInt(100000), []byte{}, [32]byte(unclaimed)),
+		},
+		balances: map[common.Address]*big.Int{alice: big.NewInt(3), bob: big.NewInt(7)},
+		tokens: map[common.Address]map[common.Address]*big.Int{
+			token: {alice: big.NewInt(50), vault: big.NewInt(40)},
+		},
+		code:      map[common.Address][]byte{vault: {0x60, 0x80}, token: {0x60, 0x80}},
+		finalized: map[common.Hash]bool{claimed: true},
+	}
+	f := &shutdownFetcher{logger: zap.NewNop().Sugar(), l2: client, l1: client, l2ChainID: 1001, workers: 2, blockRange: 2}
+	require.NoError(t, f.loadCheckpoint())
+	require.NoError(t, f.scan(ctx, 6))
+	result, err := f.collect(ctx, 6)
+	require.NoError(t, err)
+
+	require.Equal(t, []types.ShutdownToken{{
+		Address: token.Hex(), L1Token: l1Token.Hex(), Symbol: "TKN", Decimals: 18, TotalSupply: "90", Holders: 2,
+	}}, result.Tokens, "the ERC721 is not a token")
+	native := common.Address{}.Hex()
+	require.ElementsMatch(t, []types.ShutdownHolder{
+		{Address: alice.Hex(), Token: native, Balance: "3"},
+		{Address: bob.Hex(), Token: native, Balance: "7"},
+		{Address: alice.Hex(), Token: token.Hex(), Balance: "50"},
+		{Address: vault.Hex(), Token: token.Hex(), Balance: "40", IsContract: true},
+	}, result.Holders)
+	require.Len(t, result.Contracts, 1)
+	require.Equal(t, vault.Hex(), result.Contracts[0].Address)
+	require.Equal(t, []types.ShutdownBurn{{
+		BlockNumber: 5, TxHash: common.BigToHash(big.NewInt(5)).Hex(), L1Token: l1Token.Hex(), L2Token: token.Hex(),
+		From: alice.Hex(), To: alice.Hex(), Amount: "10",
+	}}, result.Burns)
+	require.Len(t, result.Unclaimed, 1)
+	require.Equal(t, unclaimed.Hex(), result.Unclaimed[0].WithdrawalHash)
+	require.Equal(t, "5", result.Unclaimed[0].Value)
+	require.Equal(t, "2", result.Unclaimed[0].Nonce)
+
+	dir := t.TempDir()
+	require.NoError(t, writeShutdownFetchResult(dir, 1001, result))
+	for _, name := range []string{ShutdownHoldersFile, ShutdownContractsFile, ShutdownTokensFile, ShutdownBurnsFile, ShutdownUnclaimedFile} {
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf(name, 1001)))
+		require.NoError(t, err)
+		var entries []json.RawMessage
+		require.NoError(t, json.Unmarshal(data, &entries), name)
+		require.NotEmpty(t, entries, name)
+	}
+}
+
+func TestShutdownFetchGenesisAccounts(t *testing.T) {
+	genesisPath := filepath.Join(t.TempDir(), "genesis.json")
+	require.NoError(t, os.WriteFile(genesisPath, []byte(`{"alloc": {
+  "00000000000000000000000000000000000000a1": {"balance": "0x64"},
+  "0x00000000000000000000000000000000000000b0": {"balance": "0x0"},
+  "0x4200000000000000000000000000000000000016": {"code": "0x60"}
+}}`), 0644))
+
+	f := &shutdownFetcher{logger: zap.NewNop().Sugar()}
+	require.NoError(t, f.loadCheckpoint())
+	require.NoError(t, f.addGenesisAccounts(genesisPath))
+	require.Equal(t, []string{common.HexToAddress("0xa1").Hex()}, sortedAddresses(f.index.accounts))
+	require.NoError(t, f.addGenesisAccounts(filepath.Join(t.TempDir(), "missing.json")))
+}
@@ -0,0 +1,412 @@
+package thanos
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// ShutdownClaimsFile is the claim report of `shutdown reconcile`, by L2 chain ID and extension (csv or json)
+const ShutdownClaimsFile = "shutdown-claims-%d.%s"
+
+const (
+	defaultShutdownReconcileWorkers = 8
+	// shutdownReconcileLogRange is the number of L1 blocks searched for claims in one eth_getLogs
+	shutdownReconcileLogRange = uint64(10000)
+)
+
+// shutdownClaimABIJSON has the claim event emitted by the L1StandardBridge in force withdrawal mode
+const shutdownClaimABIJSON = `[
+  {"type":"event","name":"ForceWithdraw","inputs":[
+    {"name":"_index","type":"bytes32","indexed":true},
+    {"name":"_token","type":"address","indexed":true},
+    {"name":"_amount","type":"uint256","indexed":false},
+    {"name":"_claimer","type":"address","indexed":true}]}
+]`
+
+var shutdownClaimABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(shutdownClaimABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// shutdownReconcileClient is the L1 RPC used by the reconciliation
+type shutdownReconcileClient interface {
+	ethereum.ContractCaller
+	ethereum.LogFilterer
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// shutdownClaimEvent is a ForceWithdraw emitted by the bridge
+type shutdownClaimEvent struct {
+	claimer     common.Address
+	token       common.Address
+	amount      *big.Int
+	txHash      common.Hash
+	blockNumber uint64
+}
+
+// ShutdownReconcile compares the assets snapshot with the amounts registered in GenFWStorage and the
+// claims made on the L1StandardBridge, and returns the claim status of every address
+func (s *ThanosStack) ShutdownReconcile(ctx context.Context, opts types.ShutdownReconcileOptions) (*types.ShutdownReconcileReport, error) {
+	if s.deployConfig == nil {
+		return nil, fmt.Errorf("deployConfig is nil")
+	}
+	if !common.IsHexAddress(opts.StorageAddress) {
+		return nil, fmt.Errorf("a GenFWStorage address is required (run 'shutdown activate' first or pass --storage-address)")
+	}
+	// Without a start the claims would be searched from the L1 genesis
+	if opts.FromBlock == 0 && opts.ActivateTxHash == "" {
+		return nil, fmt.Errorf("the activate transaction is not recorded in the shutdown state, pass --from-block with the L1 block of the activate transaction")
+	}
+	contracts, err := s.readDeploymentContracts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deployment contracts: %w", err)
+	}
+
+	snapshotPath := s.ShutdownAssetsFile(opts.AssetsPath)
+	data, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the assets snapshot: %w", err)
+	}
+	var snapshot []types.ShutdownAssetToken
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse the assets snapshot %s: %w", snapshotPath, err)
+	}
+	snapshotHash, err := hashFile(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+
+	l1, err := ethclient.DialContext(ctx, s.deployConfig.L1RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to L1 RPC %s: %w", s.deployConfig.L1RPCURL, err)
+	}
+	defer l1.Close()
+
+	fromBlock := opts.FromBlock
+	if fromBlock == 0 && opts.ActivateTxHash != "" {
+		receipt, err := l1.TransactionReceipt(ctx, common.HexToHash(opts.ActivateTxHash))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the activate transaction %s: %w", opts.ActivateTxHash, err)
+		}
+		fromBlock = receipt.BlockNumber.Uint64()
+	}
+
+	reconciler := &shutdownReconciler{
+		client:  l1,
+		storage: common.HexToAddress(opts.StorageAddress),
+		bridge:  common.HexToAddress(contracts.L1StandardBridgeProxy),
+		workers: opts.Workers,
+	}
+	if reconciler.workers <= 0 {
+		reconciler.workers = defaultShutdownReconcileWorkers
+	}
+	s.logger.Infof("🔎 Reconciling %d tokens of %s against GenFWStorage %s and bridge %s from L1 block %d",
+		len(snapshot), snapshotPath, reconciler.storage.Hex(), reconciler.bridge.Hex(), fromBlock)
+	report, err := reconciler.reconcile(ctx, snapshot, fromBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	report.L1ChainID = s.deployConfig.L1ChainID
+	report.L2ChainID = s.deployConfig.L2ChainID
+	report.SnapshotPath = snapshotPath
+	report.SnapshotHash = snapshotHash
+	report.StorageAddress = reconciler.storage.Hex()
+	report.Bridge = reconciler.bridge.Hex()
+	report.GeneratedAt = time.Now().UTC().Format(time.RFC3339)
+	return report, nil
+}
+
+// shutdownReconciler matches the snapshot with GenFWStorage and the claim events of the bridge
+type shutdownReconciler struct {
+	client  shutdownReconcileClient
+	storage common.Address
+	bridge  common.Address
+	workers int
+}
+
+// reconcile returns the claim status of the snapshot entries and of the claims without an entry
+func (r *shutdownReconciler) reconcile(ctx context.Context, snapshot []types.ShutdownAssetToken, fromBlock uint64) (*types.ShutdownReconcileReport, error) {
+	var records []types.ShutdownClaimRecord
+	for _, token := range snapshot {
+		for _, claim := range token.Data {
+			amount, ok := new(big.Int).SetString(claim.Amount.String(), 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid amount %q for %s in the snapshot", claim.Amount, claim.Claimer)
+			}
+			records = append(records, types.ShutdownClaimRecord{
+				Claimer:   common.HexToAddress(claim.Claimer).Hex(),
+				L1Token:   common.HexToAddress(token.L1Token).Hex(),
+				L2Token:   token.L2Token,
+				TokenName: token.TokenName,
+				Hash:      claim.Hash,
+				Amount:    amount.String(),
+			})
+		}
+	}
+
+	// Read the amount GenFWStorage registered for every entry
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(r.workers)
+	for i := range records {
+		record := &records[i]
+		group.Go(func() error {
+			amount, err := r.storageAmount(groupCtx, record.Hash)
+			if err != nil {
+				return fmt.Errorf("failed to read the GenFWStorage amount of %s: %w", record.Claimer, err)
+			}
+			record.StorageAmount = amount.String()
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	toBlock, err := r.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the L1 head: %w", err)
+	}
+	claims, err := r.claimEvents(ctx, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	// Attribute every claim to an entry of the claimer for the token
+	claimsByKey := make(map[string][]shutdownClaimEvent)
+	for _, claim := range claims {
+		key := claim.claimer.Hex() + "/" + claim.token.Hex()
+		claimsByKey[key] = append(claimsByKey[key], claim)
+	}
+	for i := range records {
+		record := &records[i]
+		key := record.Claimer + "/" + record.L1Token
+		if pending := claimsByKey[key]; len(pending) > 0 {
+			claim := pending[0]
+			claimsByKey[key] = pending[1:]
+			record.ClaimedAmount = claim.amount.String()
+			record.ClaimTxHash = claim.txHash.Hex()
+			record.ClaimBlock = claim.blockNumber
+		} else {
+			record.ClaimedAmount = "0"
+		}
+		record.Status = shutdownClaimStatus(record)
+	}
+	// The claims left have no entry in the snapshot
+	for _, pending := range claimsByKey {
+		for _, claim := range pending {
+			records = append(records, types.ShutdownClaimRecord{
+				Claimer:       claim.claimer.Hex(),
+				L1Token:       claim.token.Hex(),
+				Amount:        "0",
+				StorageAmount: "0",
+				ClaimedAmount: claim.amount.String(),
+				ClaimTxHash:   claim.txHash.Hex(),
+				ClaimBlock:    claim.blockNumber,
+				Status:        types.ShutdownClaimUnexpected,
+			})
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.L1Token != b.L1Token {
+			return a.L1Token < b.L1Token
+		}
+		if a.Claimer != b.Claimer {
+			return a.Claimer < b.Claimer
+		}
+		return a.ClaimBlock < b.ClaimBlock
+	})
+
+	report := &types.ShutdownReconcileReport{
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+		Records:   records,
+	}
+	type tokenTotal struct {
+		total           *types.ShutdownClaimTotal
+		amount, claimed *big.Int
+	}
+	totals := make(map[string]*tokenTotal)
+	for _, record := range records {
+		total := totals[record.L1Token]
+		if total == nil {
+			total = &tokenTotal{
+				total:   &types.ShutdownClaimTotal{L1Token: record.L1Token, TokenName: record.TokenName},
+				amount:  new(big.Int),
+				claimed: new(big.Int),
+			}
+			totals[record.L1Token] = total
+		}
+		if total.total.TokenName == "" {
+			total.total.TokenName = record.TokenName
+		}
+		total.total.Claims++
+		if amount, ok := new(big.Int).SetString(record.Amount, 10); ok {
+			total.amount.Add(total.amount, amount)
+		}
+		if claimed, ok := new(big.Int).SetString(record.ClaimedAmount, 10); ok {
+			total.claimed.Add(total.claimed, claimed)
+		}
+		if record.Status.Mismatch() {
+			total.total.Mismatches++
+			report.Mismatches++
+		}
+	}
+	for _, total := range totals {
+		total.total.Amount = total.amount.String()
+		total.total.Claimed = total.claimed.String()
+		report.Totals = append(report.Totals, *total.total)
+	}
+	sort.Slice(report.Totals, func(i, j int) bool { return report.Totals[i].L1Token < report.Totals[j].L1Token })
+	return report, nil
+}
+
+// storageAmount calls the GenFWStorage getter of a snapshot hash, zero when the getter does not exist
+func (r *shutdownReconciler) storageAmount(ctx context.Context, hash string) (*big.Int, error) {
+	if hash == "" {
+		return new(big.Int), nil
+	}
+	var output []byte
+	err := rpcCallWithRetry(ctx, func() error {
+		var err error
+		output, err = r.client.CallContract(ctx, ethereum.CallMsg{To: &r.storage, Data: shutdownClaimGetter(hash)}, nil)
+		return err
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "revert") {
+			return new(big.Int), nil
+		}
+		return nil, err
+	}
+	if len(output) < 32 {
+		return new(big.Int), nil
+	}
+	return new(big.Int).SetBytes(output[:32]), nil
+}
+
+// claimEvents returns the ForceWithdraw events of the bridge in blocks from to to
+func (r *shutdownReconciler) claimEvents(ctx context.Context, from, to uint64) ([]shutdownClaimEvent, error) {
+	event := shutdownClaimABI.Events["ForceWithdraw"]
+	var claims []shutdownClaimEvent
+	for start := from; start <= to; start += shutdownReconcileLogRange {
+		end := min(start+shutdownReconcileLogRange-1, to)
+		query := ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: []common.Address{r.bridge},
+			Topics:    [][]common.Hash{{event.ID}},
+		}
+		var logs []ethTypes.Log
+		err := rpcCallWithRetry(ctx, func() error {
+			var err error
+			logs, err = r.client.FilterLogs(ctx, query)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read claims in blocks %d-%d: %w", start, end, err)
+		}
+		for _, log := range logs {
+			if len(log.Topics) < 4 {
+				continue
+			}
+			values, err := event.Inputs.NonIndexed().Unpack(log.Data)
+			if err != nil || len(values) == 0 {
+				return nil, fmt.Errorf("failed to decode claim %s: %v", log.TxHash.Hex(), err)
+			}
+			claims = append(claims, shutdownClaimEvent{
+				token:       common.BytesToAddress(log.Topics[2].Bytes()),
+				claimer:     common.BytesToAddress(log.Topics[3].Bytes()),
+				amount:      values[0].(*big.Int),
+				txHash:      log.TxHash,
+				blockNumber: log.BlockNumber,
+			})
+		}
+	}
+	return claims, nil
+}
+
+// shutdownClaimGetter returns the call data of the GenFWStorage getter of a snapshot hash. A hash
+// without a signature names the getter _<hash without 0x>().
+func shutdownClaimGetter(hash string) []byte {
+	signature := hash
+	if !strings.Contains(signature, "(") {
+		signature = "_" + strings.TrimPrefix(hash, "0x") + "()"
+	}
+	return crypto.Keccak256([]byte(signature))[:4]
+}
+
+func shutdownClaimStatus(record *types.ShutdownClaimRecord) types.ShutdownClaimStatus {
+	if record.ClaimTxHash != "" {
+		if record.ClaimedAmount != record.Amount {
+			return types.ShutdownClaimClaimMismatch
+		}
+		return types.ShutdownClaimClaimed
+	}
+	switch {
+	case record.StorageAmount == "0" && record.Amount != "0":
+		return types.ShutdownClaimMissing
+	case record.StorageAmount != record.Amount:
+		return types.ShutdownClaimAmountMismatch
+	}
+	return types.ShutdownClaimClaimable
+}
+
+// WriteShutdownClaims writes the claim records of a reconciliation to path, as CSV when the path ends
+// with .csv and as JSON otherwise
+func WriteShutdownClaims(report *types.ShutdownReconcileReport, path string) error {
+	if strings.HasSuffix(path, ".csv") {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		rows := [][]string{{"claimer", "l1_token", "l2_token", "token_name", "amount", "storage_amount", "claimed_amount", "claim_tx_hash", "claim_block", "status"}}
+		for _, record := range report.Records {
+			claimBlock := ""
+			if record.ClaimBlock > 0 {
+				claimBlock = fmt.Sprintf("%d", record.ClaimBlock)
+			}
+			rows = append(rows, []string{
+				record.Claimer, record.L1Token, record.L2Token, record.TokenName, record.Amount,
+				record.StorageAmount, record.ClaimedAmount, record.ClaimTxHash, claimBlock, string(record.Status),
+			})
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the claim report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
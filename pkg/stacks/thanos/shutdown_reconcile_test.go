@@ -0,0 +1,158 @@
+package thanos
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// fakeShutdownReconcileClient serves GenFWStorage amounts by getter and bridge claims
+type fakeShutdownReconcileClient struct {
+	head    uint64
+	amounts map[string]*big.Int
+	logs    []ethTypes.Log
+}
+
+func (c *fakeShutdownReconcileClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	amount, ok := c.amounts[common.Bytes2Hex(msg.Data)]
+	if !ok {
+		return nil, fmt.Errorf("execution reverted")
+	}
+	return common.LeftPadBytes(amount.Bytes(), 32), nil
+}
+
+func (c *fakeShutdownReconcileClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]ethTypes.Log, error) {
+	var logs []ethTypes.Log
+	for _, log := range c.logs {
+		if log.BlockNumber >= q.FromBlock.Uint64() && log.BlockNumber <= q.ToBlock.Uint64() {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func (c *fakeShutdownReconcileClient) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- ethTypes.Log) (ethereum.Subscription, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (c *fakeShutdownReconcileClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.head, nil
+}
+
+func (c *fakeShutdownReconcileClient) setAmount(hash string, amount int64) {
+	c.amounts[common.Bytes2Hex(shutdownClaimGetter(hash))] = big.NewInt(amount)
+}
+
+func (c *fakeShutdownReconcileClient) addClaim(block uint64, token, claimer common.Address, amount int64) {
+	c.logs = append(c.logs, ethTypes.Log{
+		BlockNumber: block,
+		TxHash:      common.BigToHash(big.NewInt(int64(len(c.logs) + 1))),
+		Topics: []common.Hash{
+			shutdownClaimABI.Events["ForceWithdraw"].ID,
+			{},
+			common.BytesToHash(token.Bytes()),
+			common.BytesToHash(claimer.Bytes()),
+		},
+		Data: common.LeftPadBytes(big.NewInt(amount).Bytes(), 32),
+	})
+}
+
+func TestShutdownReconcile(t *testing.T) {
+	token := common.HexToAddress("0x4444444444444444444444444444444444444444")
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	carol := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	dave := common.HexToAddress("0x5555555555555555555555555555555555555555")
+	erin := common.HexToAddress("0x6666666666666666666666666666666666666666")
+
+	var snapshot []types.ShutdownAssetToken
+	require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`[{
+		"l1Token": %q, "l2Token": "0x7777777777777777777777777777777777777777", "tokenName": "USDC",
+		"data": [
+			{"claimer": %q, "amount": "100", "hash": "0xaa"},
+			{"claimer": %q, "amount": 200, "hash": "0xbb"},
+			{"claimer": %q, "amount": "300", "hash": "0xcc"},
+			{"claimer": %q, "amount": "400", "hash": "0xdd"},
+			{"claimer": %q, "amount": "500", "hash": "0xee"}
+		]}]`, token.Hex(), alice.Hex(), bob.Hex(), carol.Hex(), dave.Hex(), erin.Hex())), &snapshot))
+
+	client := &fakeShutdownReconcileClient{head: 25000, amounts: map[string]*big.Int{}}
+	client.setAmount("0xaa", 100)
+	client.setAmount("0xbb", 200)
+	client.setAmount("0xcc", 300)
+	client.setAmount("0xdd", 450)
+	client.addClaim(5, token, alice, 100)     // before the activation, not searched
+	client.addClaim(12000, token, alice, 100) // claimed
+	client.addClaim(24000, token, bob, 150)   // claimed for less than the snapshot
+	client.addClaim(24001, token, common.HexToAddress("0x8888888888888888888888888888888888888888"), 10)
+
+	reconciler := &shutdownReconciler{client: client, workers: 2}
+	report, err := reconciler.reconcile(context.Background(), snapshot, 100)
+	require.NoError(t, err)
+	require.Equal(t, uint64(25000), report.ToBlock)
+
+	statuses := map[string]types.ShutdownClaimStatus{}
+	for _, record := range report.Records {
+		statuses[record.Claimer] = record.Status
+	}
+	require.Equal(t, map[string]types.ShutdownClaimStatus{
+		alice.Hex(): types.ShutdownClaimClaimed,
+		bob.Hex():   types.ShutdownClaimClaimMismatch,
+		carol.Hex(): types.ShutdownClaimClaimable,
+		dave.Hex():  types.ShutdownClaimAmountMismatch,
+		erin.Hex():  types.ShutdownClaimMissing,
+		"0x8888888888888888888888888888888888888888": types.ShutdownClaimUnexpected,
+	}, statuses)
+
+	require.Equal(t, 4, report.Mismatches)
+	require.Equal(t, []types.ShutdownClaimTotal{{
+		L1Token:    token.Hex(),
+		TokenName:  "USDC",
+		Claims:     6,
+		Amount:     "1500",
+		Claimed:    "260",
+		Mismatches: 4,
+	}}, report.Totals)
+
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, fmt.Sprintf(ShutdownClaimsFile, 1001, "csv"))
+	require.NoError(t, WriteShutdownClaims(report, csvPath))
+	file, err := os.Open(csvPath)
+	require.NoError(t, err)
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 7)
+	require.Equal(t, "claimer", rows[0][0])
+	require.Equal(t, []string{alice.Hex(), "100", "100", "100", "12000", "claimed"},
+		[]string{rows[1][0], rows[1][4], rows[1][5], rows[1][6], rows[1][8], rows[1][9]})
+
+	jsonPath := filepath.Join(dir, fmt.Sprintf(ShutdownClaimsFile, 1001, "json"))
+	require.NoError(t, WriteShutdownClaims(report, jsonPath))
+	data, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	var exported types.ShutdownReconcileReport
+	require.NoError(t, json.Unmarshal(data, &exported))
+	require.Len(t, exported.Records, 6)
+}
+
+func TestShutdownReconcileRequiresStartBlock(t *testing.T) {
+	stack := &ThanosStack{logger: zap.NewNop().Sugar(), deploymentPath: t.TempDir(), deployConfig: &types.Config{L1ChainID: 1}}
+	_, err := stack.ShutdownReconcile(context.Background(), types.ShutdownReconcileOptions{
+		StorageAddress: "0x1111111111111111111111111111111111111111",
+	})
+	require.ErrorContains(t, err, "pass --from-block", "claims are never searched from the L1 genesis")
+}
@@ -0,0 +1,463 @@
+package thanos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+// ShutdownRehearsalFile is the report of a rehearsal in the deployment directory, by L2 chain ID and time
+const ShutdownRehearsalFile = "shutdown-rehearsal-%d-%s.json"
+
+const (
+	// anvilForkReadyTimeout bounds the time anvil takes to fetch the fork block and serve requests
+	anvilForkReadyTimeout = 60 * time.Second
+	// shutdownRehearsalFunding is the balance the owners get on the fork to pay for the shutdown
+	shutdownRehearsalFunding = "1000000000000000000000"
+)
+
+// shutdownForkClient is the RPC of the L1 fork a rehearsal reads transactions from
+type shutdownForkClient interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// forkReceipt is the part of an eth_getBlockReceipts entry the rehearsal reports. Receipts are read raw
+// because they carry the sender, which impersonated transactions do not sign.
+type forkReceipt struct {
+	TransactionHash   common.Hash     `json:"transactionHash"`
+	From              common.Address  `json:"from"`
+	To                *common.Address `json:"to"`
+	BlockNumber       hexutil.Uint64  `json:"blockNumber"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
+	Status            hexutil.Uint64  `json:"status"`
+}
+
+// ShutdownRehearse runs the shutdown steps end to end against a local anvil fork of L1, with the owners
+// impersonated, and reports the claimable assets, the gas spent and the reverted transactions. The real
+// L1 and L2 receive no transaction: gen is simulated, the other steps broadcast to the fork only.
+// The steps run in a scratch copy of contracts-bedrock, the data files and broadcast logs of the real
+// one are not touched.
+func (s *ThanosStack) ShutdownRehearse(ctx context.Context, opts types.ShutdownRehearseOptions) (*types.ShutdownRehearsalReport, error) {
+	if s.deployConfig == nil {
+		return nil, fmt.Errorf("deployConfig is nil")
+	}
+	if _, err := exec.LookPath("anvil"); err != nil {
+		return nil, fmt.Errorf("anvil is required to rehearse the shutdown, install Foundry: %w", err)
+	}
+	contracts, err := s.readDeploymentContracts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deployment contracts: %w", err)
+	}
+	bedrockConfig, err := s.readBedrockDeployConfigTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deploy config: %w", err)
+	}
+	bedrockPath, err := s.getBedrockPath()
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := newShutdownRehearsalWorkspace(bedrockPath)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workspace)
+
+	forkURL, stop, err := startAnvilFork(ctx, s.deployConfig.L1RPCURL, opts.ForkBlock, s.deployConfig.L1ChainID)
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+	fork, err := rpc.DialContext(ctx, forkURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the L1 fork: %w", err)
+	}
+	defer fork.Close()
+
+	var forkBlock hexutil.Uint64
+	if err := fork.CallContext(ctx, &forkBlock, "eth_blockNumber"); err != nil {
+		return nil, fmt.Errorf("failed to read the fork block: %w", err)
+	}
+	s.logger.Infof("🍴 Forked L1 chain %d at block %d on %s", s.deployConfig.L1ChainID, forkBlock, forkURL)
+
+	owners := []string{
+		contracts.SystemOwnerSafe,
+		bedrockConfig.FinalSystemOwner,
+		bedrockConfig.SuperchainConfigGuardian,
+		shutdownProxyAdminOwner,
+	}
+	if key, err := crypto.HexToECDSA(strings.TrimPrefix(s.deployConfig.AdminPrivateKey, "0x")); err == nil {
+		owners = append(owners, crypto.PubkeyToAddress(key.PublicKey).Hex())
+	}
+	funding, _ := new(big.Int).SetString(shutdownRehearsalFunding, 10)
+	for _, owner := range owners {
+		if !common.IsHexAddress(owner) {
+			continue
+		}
+		if err := fork.CallContext(ctx, nil, "anvil_setBalance", owner, hexutil.EncodeBig(funding)); err != nil {
+			return nil, fmt.Errorf("failed to fund %s on the fork: %w", owner, err)
+		}
+	}
+
+	// The rehearsal runs the steps on a copy of the stack pointed at the fork
+	rehearsal := *s
+	config := *s.deployConfig
+	config.L1RPCURL = forkURL
+	rehearsal.deployConfig = &config
+	rehearsal.shutdownRehearsal = true
+	rehearsal.shutdownBedrockPath = workspace
+
+	report := &types.ShutdownRehearsalReport{
+		L1ChainID: s.deployConfig.L1ChainID,
+		L2ChainID: s.deployConfig.L2ChainID,
+		ForkBlock: uint64(forkBlock),
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	assetsPath := fmt.Sprintf("data/generate-assets-%d.json", s.deployConfig.L2ChainID)
+	steps := map[string]func() error{
+		types.ShutdownStepBlock: func() error {
+			return rehearsal.ShutdownBlock(ctx, false)
+		},
+		types.ShutdownStepFetch: func() error {
+			return rehearsal.ShutdownFetch(ctx, opts.Fetch)
+		},
+		types.ShutdownStepGen: func() error {
+			// Gen broadcasts to L2, which is not forked
+			return rehearsal.ShutdownGen(ctx, opts.Gen, true)
+		},
+		types.ShutdownStepActivate: func() error {
+			storageAddr, err := rehearsal.ShutdownActivate(ctx, assetsPath, false)
+			if err != nil {
+				return err
+			}
+			if storageAddr == "" {
+				return fmt.Errorf("activate did not report the GenFWStorage address")
+			}
+			report.StorageAddress = storageAddr
+			return nil
+		},
+		types.ShutdownStepWithdraw: func() error {
+			return rehearsal.ShutdownWithdraw(ctx, assetsPath, false, report.StorageAddress)
+		},
+	}
+
+	failed := false
+	for _, step := range types.ShutdownSteps {
+		result := types.ShutdownRehearsalStep{Step: step, Status: types.ShutdownStepPending}
+		if failed || (step == types.ShutdownStepFetch && opts.SkipFetch) {
+			report.Steps = append(report.Steps, result)
+			continue
+		}
+
+		s.logger.Infof("🎭 Rehearsing step [%s] on the L1 fork...", strings.ToUpper(step))
+		var head hexutil.Uint64
+		if err := fork.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+			return nil, fmt.Errorf("failed to read the fork head: %w", err)
+		}
+		startedAt := time.Now()
+		err := steps[step]()
+		result.Duration = time.Since(startedAt).Round(time.Second).String()
+
+		txs, txErr := forkTransactions(ctx, fork, uint64(head)+1)
+		if txErr != nil {
+			s.logger.Warnf("Failed to read the transactions of step %s: %v", step, txErr)
+		}
+		result.Transactions = txs
+		for _, tx := range txs {
+			result.GasUsed += tx.GasUsed
+		}
+
+		if err != nil {
+			result.Status = types.ShutdownStepFailed
+			result.Error = err.Error()
+			failed = true
+		} else {
+			result.Status = types.ShutdownStepCompleted
+		}
+		report.Steps = append(report.Steps, result)
+	}
+
+	if err := summarizeShutdownRehearsal(report, filepath.Join(workspace, "data")); err != nil {
+		s.logger.Warnf("Failed to total the claimable assets: %v", err)
+	}
+	report.Success = !failed && report.Reverts == 0
+	report.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+
+	report.Path = filepath.Join(s.deploymentPath, fmt.Sprintf(ShutdownRehearsalFile, s.deployConfig.L2ChainID, time.Now().UTC().Format("20060102-150405")))
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the rehearsal report: %w", err)
+	}
+	if err := os.WriteFile(report.Path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write the rehearsal report: %w", err)
+	}
+	return report, nil
+}
+
+// newShutdownRehearsalWorkspace creates the scratch contracts-bedrock a rehearsal runs in: the data
+// directory is copied, so fetch resumes from the real checkpoint and gen reads the real fetched data,
+// broadcast starts empty and every other entry links to the real one
+func newShutdownRehearsalWorkspace(bedrockPath string) (string, error) {
+	entries, err := os.ReadDir(bedrockPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", bedrockPath, err)
+	}
+	workspace, err := os.MkdirTemp("", "trh-shutdown-rehearsal-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create the rehearsal workspace: %w", err)
+	}
+
+	for _, entry := range entries {
+		src := filepath.Join(bedrockPath, entry.Name())
+		dst := filepath.Join(workspace, entry.Name())
+		switch entry.Name() {
+		case "data":
+			err = copyShutdownDataDir(src, dst)
+		case "broadcast":
+			continue
+		default:
+			err = os.Symlink(src, dst)
+		}
+		if err != nil {
+			os.RemoveAll(workspace)
+			return "", fmt.Errorf("failed to prepare the rehearsal workspace: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(workspace, "data"), 0755); err != nil {
+		os.RemoveAll(workspace)
+		return "", fmt.Errorf("failed to prepare the rehearsal workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+// copyShutdownDataDir copies the files of the data directory src to dst
+func copyShutdownDataDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		return utils.CopyFile(path, filepath.Join(dst, rel))
+	})
+}
+
+// startAnvilFork starts anvil forking the L1 at block, the latest block when 0, and returns its RPC URL
+// and a func stopping it
+func startAnvilFork(ctx context.Context, l1RPCURL string, block uint64, chainID uint64) (string, func(), error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to find a free local port: %w", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	args := []string{
+		"--fork-url", l1RPCURL,
+		"--chain-id", fmt.Sprintf("%d", chainID),
+		"--host", "127.0.0.1",
+		"--port", fmt.Sprintf("%d", port),
+		"--auto-impersonate",
+		"--silent",
+	}
+	if block > 0 {
+		args = append(args, "--fork-block-number", fmt.Sprintf("%d", block))
+	}
+
+	forkCtx, cancel := context.WithCancel(ctx)
+	cmd := exec.CommandContext(forkCtx, "anvil", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return "", nil, fmt.Errorf("failed to start anvil: %w", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+	stop := func() {
+		cancel()
+		<-exited
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d", port)
+	deadline := time.Now().Add(anvilForkReadyTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-exited:
+			cancel()
+			return "", nil, fmt.Errorf("anvil exited while forking %s: %s", l1RPCURL, strings.TrimSpace(stderr.String()))
+		case <-time.After(500 * time.Millisecond):
+		}
+		client, err := rpc.DialContext(ctx, url)
+		if err != nil {
+			continue
+		}
+		var id hexutil.Uint64
+		err = client.CallContext(ctx, &id, "eth_chainId")
+		client.Close()
+		if err == nil {
+			return url, stop, nil
+		}
+	}
+	stop()
+	return "", nil, fmt.Errorf("anvil fork of %s did not become ready in %s", l1RPCURL, anvilForkReadyTimeout)
+}
+
+// forkTransactions returns the transactions mined on the fork from block from to the head
+func forkTransactions(ctx context.Context, client shutdownForkClient, from uint64) ([]types.ShutdownRehearsalTx, error) {
+	var head hexutil.Uint64
+	if err := client.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		return nil, fmt.Errorf("failed to read the fork head: %w", err)
+	}
+
+	var txs []types.ShutdownRehearsalTx
+	for number := from; number <= uint64(head); number++ {
+		var receipts []forkReceipt
+		if err := client.CallContext(ctx, &receipts, "eth_getBlockReceipts", hexutil.Uint64(number)); err != nil {
+			return txs, fmt.Errorf("failed to read the receipts of block %d: %w", number, err)
+		}
+		for _, receipt := range receipts {
+			cost := new(big.Int)
+			if receipt.EffectiveGasPrice != nil {
+				cost.Mul(receipt.EffectiveGasPrice.ToInt(), new(big.Int).SetUint64(uint64(receipt.GasUsed)))
+			}
+			tx := types.ShutdownRehearsalTx{
+				Hash:        receipt.TransactionHash.Hex(),
+				From:        receipt.From.Hex(),
+				BlockNumber: uint64(receipt.BlockNumber),
+				GasUsed:     uint64(receipt.GasUsed),
+				GasCost:     cost.String(),
+				Reverted:    receipt.Status == 0,
+			}
+			if receipt.To != nil {
+				tx.To = receipt.To.Hex()
+			}
+			txs = append(txs, tx)
+		}
+	}
+	return txs, nil
+}
+
+// summarizeShutdownRehearsal totals the gas and the reverts of the steps, and the assets claimable
+// according to the fetched data
+func summarizeShutdownRehearsal(report *types.ShutdownRehearsalReport, dataDir string) error {
+	gasCost := new(big.Int)
+	for _, step := range report.Steps {
+		report.GasUsed += step.GasUsed
+		for _, tx := range step.Transactions {
+			if cost, ok := new(big.Int).SetString(tx.GasCost, 10); ok {
+				gasCost.Add(gasCost, cost)
+			}
+			if tx.Reverted {
+				report.Reverts++
+			}
+		}
+	}
+	report.GasCost = gasCost.String()
+
+	var holders []types.ShutdownHolder
+	var tokens []types.ShutdownToken
+	var unclaimed []types.ShutdownWithdrawal
+	files := []struct {
+		name    string
+		entries interface{}
+	}{
+		{ShutdownHoldersFile, &holders},
+		{ShutdownTokensFile, &tokens},
+		{ShutdownUnclaimedFile, &unclaimed},
+	}
+	for _, file := range files {
+		path := filepath.Join(dataDir, fmt.Sprintf(file.name, report.L2ChainID))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if err := json.Unmarshal(data, file.entries); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+
+	tokenInfo := make(map[common.Address]types.ShutdownToken, len(tokens))
+	for _, token := range tokens {
+		tokenInfo[common.HexToAddress(token.Address)] = token
+	}
+	type claimableTotal struct {
+		total, contracts *big.Int
+		holders          int
+	}
+	totals := make(map[common.Address]*claimableTotal)
+	for _, holder := range holders {
+		balance, ok := new(big.Int).SetString(holder.Balance, 10)
+		if !ok || balance.Sign() <= 0 {
+			continue
+		}
+		token := common.HexToAddress(holder.Token)
+		total := totals[token]
+		if total == nil {
+			total = &claimableTotal{total: new(big.Int), contracts: new(big.Int)}
+			totals[token] = total
+		}
+		total.total.Add(total.total, balance)
+		total.holders++
+		if holder.IsContract {
+			total.contracts.Add(total.contracts, balance)
+		}
+	}
+
+	report.Claimable = report.Claimable[:0]
+	for token, total := range totals {
+		claimable := types.ShutdownClaimable{
+			Token:         token.Hex(),
+			Decimals:      18,
+			Total:         total.total.String(),
+			Holders:       total.holders,
+			ContractTotal: total.contracts.String(),
+		}
+		if info, ok := tokenInfo[token]; ok {
+			claimable.L1Token = info.L1Token
+			claimable.Symbol = info.Symbol
+			claimable.Decimals = info.Decimals
+		}
+		report.Claimable = append(report.Claimable, claimable)
+	}
+	// The native token first, then by token address
+	sort.Slice(report.Claimable, func(i, j int) bool {
+		return strings.ToLower(report.Claimable[i].Token) < strings.ToLower(report.Claimable[j].Token)
+	})
+
+	unclaimedValue := new(big.Int)
+	for _, withdrawal := range unclaimed {
+		if value, ok := new(big.Int).SetString(withdrawal.Value, 10); ok {
+			unclaimedValue.Add(unclaimedValue, value)
+		}
+	}
+	report.UnclaimedWithdrawals = len(unclaimed)
+	report.UnclaimedValue = unclaimedValue.String()
+	return nil
+}
@@ -0,0 +1,174 @@
+package thanos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+// fakeShutdownFork answers the RPC calls of a rehearsal with canned receipts by block
+type fakeShutdownFork struct {
+	head     uint64
+	receipts map[uint64]string
+}
+
+func (f *fakeShutdownFork) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	var data string
+	switch method {
+	case "eth_blockNumber":
+		data = fmt.Sprintf("%q", hexutil.Uint64(f.head).String())
+	case "eth_getBlockReceipts":
+		data = f.receipts[uint64(args[0].(hexutil.Uint64))]
+		if data == "" {
+			data = "[]"
+		}
+	default:
+		return fmt.Errorf("unexpected method %s", method)
+	}
+	return json.Unmarshal([]byte(data), result)
+}
+
+func TestForkTransactions(t *testing.T) {
+	fork := &fakeShutdownFork{
+		head: 12,
+		receipts: map[uint64]string{
+			10: `[{"transactionHash": "0x0000000000000000000000000000000000000000000000000000000000000001", "from": "0x1111111111111111111111111111111111111111", "to": null, "blockNumber": "0xa", "gasUsed": "0x5208", "effectiveGasPrice": "0x3b9aca00", "status": "0x1"}]`,
+			12: `[{"transactionHash": "0x0000000000000000000000000000000000000000000000000000000000000002", "from": "0x2222222222222222222222222222222222222222", "to": "0x3333333333333333333333333333333333333333", "blockNumber": "0xc", "gasUsed": "0x7530", "effectiveGasPrice": "0x3b9aca00", "status": "0x0"}]`,
+		},
+	}
+
+	txs, err := forkTransactions(context.Background(), fork, 11)
+	require.NoError(t, err)
+	require.Len(t, txs, 1, "blocks before the step are not counted")
+	require.Equal(t, uint64(30000), txs[0].GasUsed)
+	require.Equal(t, "30000000000000", txs[0].GasCost)
+	require.Equal(t, "0x3333333333333333333333333333333333333333", txs[0].To)
+	require.True(t, txs[0].Reverted)
+
+	txs, err = forkTransactions(context.Background(), fork, 10)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	require.Empty(t, txs[0].To, "a contract creation has no recipient")
+	require.False(t, txs[0].Reverted)
+}
+
+func TestSummarizeShutdownRehearsal(t *testing.T) {
+	dataDir := t.TempDir()
+	token := "0x4444444444444444444444444444444444444444"
+	files := map[string]interface{}{
+		ShutdownHoldersFile: []types.ShutdownHolder{
+			{Address: "0x1111111111111111111111111111111111111111", Token: token, Balance: "300"},
+			{Address: "0x2222222222222222222222222222222222222222", Token: token, Balance: "200", IsContract: true},
+			{Address: "0x1111111111111111111111111111111111111111", Token: "0x0000000000000000000000000000000000000000", Balance: "1000"},
+			{Address: "0x3333333333333333333333333333333333333333", Token: token, Balance: "0"},
+		},
+		ShutdownTokensFile: []types.ShutdownToken{
+			{Address: token, L1Token: "0x5555555555555555555555555555555555555555", Symbol: "USDC", Decimals: 6},
+		},
+		ShutdownUnclaimedFile: []types.ShutdownWithdrawal{{Value: "7"}, {Value: "8"}},
+	}
+	for name, entries := range files {
+		data, err := json.Marshal(entries)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dataDir, fmt.Sprintf(name, 1001)), data, 0644))
+	}
+
+	report := &types.ShutdownRehearsalReport{
+		L2ChainID: 1001,
+		Steps: []types.ShutdownRehearsalStep{
+			{Step: types.ShutdownStepBlock, GasUsed: 100, Transactions: []types.ShutdownRehearsalTx{{GasUsed: 100, GasCost: "1000"}}},
+			{Step: types.ShutdownStepActivate, GasUsed: 50, Transactions: []types.ShutdownRehearsalTx{{GasUsed: 50, GasCost: "500", Reverted: true}}},
+		},
+	}
+	require.NoError(t, summarizeShutdownRehearsal(report, dataDir))
+
+	require.Equal(t, uint64(150), report.GasUsed)
+	require.Equal(t, "1500", report.GasCost)
+	require.Equal(t, 1, report.Reverts)
+	require.Equal(t, 2, report.UnclaimedWithdrawals)
+	require.Equal(t, "15", report.UnclaimedValue)
+
+	require.Len(t, report.Claimable, 2)
+	require.Equal(t, types.ShutdownClaimable{
+		Token:         "0x0000000000000000000000000000000000000000",
+		Decimals:      18,
+		Total:         "1000",
+		Holders:       1,
+		ContractTotal: "0",
+	}, report.Claimable[0], "the native token comes first")
+	require.Equal(t, types.ShutdownClaimable{
+		Token:         token,
+		L1Token:       "0x5555555555555555555555555555555555555555",
+		Symbol:        "USDC",
+		Decimals:      6,
+		Total:         "500",
+		Holders:       2,
+		ContractTotal: "200",
+	}, report.Claimable[1])
+
+	require.Error(t, summarizeShutdownRehearsal(&types.ShutdownRehearsalReport{L2ChainID: 2002}, dataDir), "nothing was fetched")
+}
+
+func TestShutdownRehearsalSenderFlag(t *testing.T) {
+	stack := &ThanosStack{
+		logger:         zap.NewNop().Sugar(),
+		deploymentPath: t.TempDir(),
+		deployConfig:   &types.Config{L1RPCURL: "http://127.0.0.1:8545", AdminPrivateKey: "01"},
+	}
+	env := []string{"IMPERSONATE_SENDER=0x1111111111111111111111111111111111111111", "DRY_RUN=false"}
+
+	params, err := stack.buildForgeScriptParams(shutdownWithdrawScript, "run()", nil, false, env, false)
+	require.NoError(t, err)
+	require.Empty(t, params.senderFlag, "a real run signs with the admin key")
+	require.Equal(t, "--broadcast", params.broadcast)
+
+	params, err = stack.buildForgeScriptParams(shutdownWithdrawScript, "run()", nil, false, env, true)
+	require.NoError(t, err)
+	require.Equal(t, "--sender 0x1111111111111111111111111111111111111111", params.senderFlag)
+
+	// A rehearsal broadcasts the impersonated transactions to the fork unsigned
+	stack.shutdownRehearsal = true
+	params, err = stack.buildForgeScriptParams(shutdownWithdrawScript, "run()", nil, false, env, false)
+	require.NoError(t, err)
+	require.Equal(t, "--unlocked --sender 0x1111111111111111111111111111111111111111", params.senderFlag)
+	require.Equal(t, "--broadcast", params.broadcast)
+	require.NotContains(t, params.filteredEnv, env[0])
+}
+
+func TestShutdownRehearsalWorkspace(t *testing.T) {
+	bedrockPath := t.TempDir()
+	for name, content := range map[string]string{
+		"foundry.toml":                          "[profile.default]\n",
+		"data/l2-holders-1001.json":             "[]",
+		"data/generate-assets-1001.json":        "real",
+		"broadcast/Run.s.sol/1/run-latest.json": "{}",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(bedrockPath, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(bedrockPath, name), []byte(content), 0644))
+	}
+
+	workspace, err := newShutdownRehearsalWorkspace(bedrockPath)
+	require.NoError(t, err)
+	defer os.RemoveAll(workspace)
+
+	stack := &ThanosStack{shutdownBedrockPath: workspace}
+	require.Equal(t, filepath.Join(workspace, "data", "generate-assets-1001.json"), stack.ShutdownAssetsFile("data/generate-assets-1001.json"))
+	require.FileExists(t, filepath.Join(workspace, "foundry.toml"))
+	require.NoDirExists(t, filepath.Join(workspace, "broadcast"), "the fork broadcasts do not replace the real logs")
+
+	// Gen rewrites the copy of the data, the real snapshot is kept
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "data", "generate-assets-1001.json"), []byte("rehearsal"), 0644))
+	data, err := os.ReadFile(filepath.Join(bedrockPath, "data", "generate-assets-1001.json"))
+	require.NoError(t, err)
+	require.Equal(t, "real", string(data))
+	require.FileExists(t, filepath.Join(workspace, "data", "l2-holders-1001.json"))
+}
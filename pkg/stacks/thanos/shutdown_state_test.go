@@ -0,0 +1,168 @@
+package thanos
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+func TestShutdownStateSteps(t *testing.T) {
+	dir := t.TempDir()
+	state, err := types.LoadShutdownState(dir, 1001)
+	require.NoError(t, err)
+	require.Equal(t, types.ShutdownStepBlock, state.NextStep(false))
+
+	state.StartStep(types.ShutdownStepBlock, false)
+	state.CompleteStep(types.ShutdownStepBlock, []string{"0xabc"})
+	state.StartStep(types.ShutdownStepFetch, false)
+	state.FailStep(types.ShutdownStepFetch, errors.New("rpc unavailable"), nil)
+	require.False(t, state.Step(types.ShutdownStepFetch).Interrupted(), "nothing was broadcast")
+	require.NoError(t, state.Save())
+
+	// The state is kept per chain in the deployment directory
+	require.FileExists(t, filepath.Join(dir, "shutdown-state-1001.json"))
+	other, err := types.LoadShutdownState(dir, 2002)
+	require.NoError(t, err)
+	require.Equal(t, types.ShutdownStepBlock, other.NextStep(false))
+
+	state, err = types.LoadShutdownState(dir, 1001)
+	require.NoError(t, err)
+	require.Equal(t, []string{"0xabc"}, state.Step(types.ShutdownStepBlock).TxHashes)
+	require.Equal(t, "rpc unavailable", state.Step(types.ShutdownStepFetch).Error)
+	require.Equal(t, types.ShutdownStepFetch, state.NextStep(false))
+
+	// A dry run does not complete a step for a real run
+	state.StartStep(types.ShutdownStepFetch, false)
+	state.CompleteStep(types.ShutdownStepFetch, nil)
+	state.StartStep(types.ShutdownStepGen, true)
+	state.CompleteStep(types.ShutdownStepGen, nil)
+	require.Equal(t, types.ShutdownStepActivate, state.NextStep(true))
+	require.Equal(t, types.ShutdownStepGen, state.NextStep(false))
+
+	require.NoError(t, os.WriteFile(types.ShutdownStateFilePath(dir, 3003), []byte(`{"l2ChainId": 1001}`), 0644))
+	_, err = types.LoadShutdownState(dir, 3003)
+	require.ErrorContains(t, err, "belongs to L2 chain 1001")
+}
+
+func TestShutdownStateInterrupted(t *testing.T) {
+	state, err := types.LoadShutdownState(t.TempDir(), 1001)
+	require.NoError(t, err)
+
+	// A step left running by a crash may have broadcast, a dry run never did
+	state.StartStep(types.ShutdownStepActivate, false)
+	require.True(t, state.Step(types.ShutdownStepActivate).Interrupted())
+	state.StartStep(types.ShutdownStepBlock, true)
+	require.False(t, state.Step(types.ShutdownStepBlock).Interrupted())
+
+	state.FailStep(types.ShutdownStepActivate, errors.New("nonce too low"), []string{"0xabc"})
+	require.True(t, state.Step(types.ShutdownStepActivate).Interrupted())
+	require.Equal(t, []string{"0xabc"}, state.Step(types.ShutdownStepActivate).TxHashes)
+	state.CompleteStep(types.ShutdownStepActivate, []string{"0xabc"})
+	require.False(t, state.Step(types.ShutdownStepActivate).Interrupted())
+}
+
+func TestShutdownStateLegacyImport(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	legacyPath, err := types.LegacyShutdownStateFilePath()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(home, ".trh", "thanos_shutdown_state.json"), legacyPath)
+	require.NoError(t, os.MkdirAll(filepath.Dir(legacyPath), 0755))
+
+	// Earlier releases kept one state for every chain, it is only imported by its own chain
+	require.NoError(t, os.WriteFile(legacyPath, []byte(`{"chainId": 11155111, "l2ChainId": 1001, "dataDir": "/old/data",
+		"lastGenAt": "2026-05-01T10:00:00Z", "lastSnapshotPath": "data/generate-assets-1001.json", "lastCommand": "gen"}`), 0644))
+	dir := t.TempDir()
+	other, err := types.LoadShutdownState(dir, 2002)
+	require.NoError(t, err)
+	require.Empty(t, other.ImportedFrom)
+	require.Equal(t, types.ShutdownStepBlock, other.NextStep(false))
+
+	state, err := types.LoadShutdownState(dir, 1001)
+	require.NoError(t, err)
+	require.Equal(t, legacyPath, state.ImportedFrom)
+	require.Equal(t, "data/generate-assets-1001.json", state.SnapshotPath)
+	require.Equal(t, "2026-05-01T10:00:00Z", state.Step(types.ShutdownStepFetch).CompletedAt)
+	require.Equal(t, types.ShutdownStepActivate, state.NextStep(false))
+	require.FileExists(t, types.ShutdownStateFilePath(dir, 1001), "the import is saved to the state of the chain")
+
+	// Once imported, the state of the chain is used
+	state, err = types.LoadShutdownState(dir, 1001)
+	require.NoError(t, err)
+	require.Empty(t, state.ImportedFrom)
+	require.Equal(t, types.ShutdownStepActivate, state.NextStep(false))
+
+	// A legacy state that cannot be attributed to a chain is only imported once confirmed
+	require.NoError(t, os.WriteFile(legacyPath, []byte(`{"lastGenAt": "2026-05-01T10:00:00Z"}`), 0644))
+	dir = t.TempDir()
+	state, err = types.LoadShutdownState(dir, 1001)
+	require.NoError(t, err)
+	require.Equal(t, legacyPath, state.UnattributedLegacy)
+	require.Equal(t, types.ShutdownStepBlock, state.NextStep(false))
+	require.NoFileExists(t, types.ShutdownStateFilePath(dir, 1001))
+
+	require.NoError(t, state.ImportUnattributedLegacy())
+	require.Empty(t, state.UnattributedLegacy)
+	require.Equal(t, legacyPath, state.ImportedFrom)
+	require.Equal(t, uint64(1001), state.L2ChainID)
+	require.Equal(t, types.ShutdownStepActivate, state.NextStep(false))
+	require.FileExists(t, types.ShutdownStateFilePath(dir, 1001))
+}
+
+func TestShutdownStateSnapshot(t *testing.T) {
+	state, err := types.LoadShutdownState(t.TempDir(), 1001)
+	require.NoError(t, err)
+	state.SetSnapshot("data/generate-assets-1001.json", "aaa")
+	state.StartStep(types.ShutdownStepActivate, false)
+	state.SetStepInput(types.ShutdownStepActivate, "aaa")
+	state.CompleteStep(types.ShutdownStepActivate, nil)
+	state.StorageAddress = "0x1234"
+
+	// The same snapshot keeps the activation
+	state.SetSnapshot("data/generate-assets-1001.json", "aaa")
+	require.True(t, state.StepDone(types.ShutdownStepActivate, false))
+	require.Equal(t, "aaa", state.Step(types.ShutdownStepActivate).InputHash)
+
+	// A new snapshot has to be activated again
+	state.SetSnapshot("data/generate-assets-1001.json", "bbb")
+	require.False(t, state.StepDone(types.ShutdownStepActivate, false))
+	require.Empty(t, state.StorageAddress)
+}
+
+func TestShutdownTxHashes(t *testing.T) {
+	dir := t.TempDir()
+	bedrockPath := filepath.Join(dir, "tokamak-thanos", "packages", "tokamak", "contracts-bedrock")
+	logDir := filepath.Join(bedrockPath, "broadcast", "PrepareL1Withdrawal.s.sol", "11155111")
+	require.NoError(t, os.MkdirAll(logDir, 0755))
+	stack := &ThanosStack{
+		logger:         zap.NewNop().Sugar(),
+		deploymentPath: dir,
+		deployConfig:   &types.Config{L1ChainID: 11155111, L2ChainID: 1001},
+	}
+
+	hashes, err := stack.ShutdownTxHashes(types.ShutdownStepActivate, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, hashes, "nothing was broadcast")
+
+	startedAt := time.Now().Add(-time.Minute)
+	require.NoError(t, os.WriteFile(filepath.Join(logDir, "run-latest.json"),
+		[]byte(`{"transactions": [{"hash": "0x01"}, {"hash": "0x02"}], "receipts": []}`), 0644))
+	hashes, err = stack.ShutdownTxHashes(types.ShutdownStepActivate, startedAt)
+	require.NoError(t, err)
+	require.Equal(t, []string{"0x01", "0x02"}, hashes)
+
+	// The log of a previous run is not attributed to this one
+	hashes, err = stack.ShutdownTxHashes(types.ShutdownStepActivate, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, hashes)
+
+	require.Equal(t, filepath.Join(bedrockPath, "data", "generate-assets-1001.json"), stack.ShutdownAssetsFile("data/generate-assets-1001.json"))
+	require.Equal(t, "/tmp/assets.json", stack.ShutdownAssetsFile("/tmp/assets.json"))
+}
@@ -0,0 +1,382 @@
+package thanos
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+const (
+	supportBundleCommandTimeout = time.Minute
+	// supportBundleLogTail is the number of lines per container or pod without a log window
+	supportBundleLogTail = 2000
+	// supportBundleSDKLogFiles is the number of most recent trh-sdk log files included
+	supportBundleSDKLogFiles = 5
+	// supportBundleMaxFileSize keeps the end of larger trh-sdk log files
+	supportBundleMaxFileSize = 1 << 20
+)
+
+// supportBundleVersionCommands are the tools whose versions are recorded in versions.txt
+var supportBundleVersionCommands = [][]string{
+	{"docker", "--version"},
+	{"docker", "compose", "version"},
+	{"kubectl", "version", "--client"},
+	{"helm", "version", "--short"},
+	{"terraform", "version"},
+	{"aws", "--version"},
+}
+
+// supportBundle is the content of a support bundle. Every file is redacted before it is added.
+type supportBundle struct {
+	files    map[string][]byte
+	manifest types.SupportBundleManifest
+}
+
+// CreateSupportBundle collects the redacted configuration, container or pod state, logs, Helm values,
+// terraform outputs, tool versions and health of the deployment into a tarball and returns its path.
+// Collection failures are recorded in manifest.json instead of aborting the bundle.
+func (t *ThanosStack) CreateSupportBundle(ctx context.Context, opts *types.SupportBundleOptions) (string, error) {
+	now := time.Now().UTC()
+	b := &supportBundle{
+		files: map[string][]byte{},
+		manifest: types.SupportBundleManifest{
+			CreatedAt:  now,
+			SDKVersion: opts.SDKVersion,
+			Network:    t.network,
+			Errors:     map[string]string{},
+		},
+	}
+	if t.deployConfig != nil {
+		b.manifest.Stack = t.deployConfig.Stack
+		b.manifest.ChainName = t.deployConfig.ChainName
+	}
+
+	t.collectSupportBundleConfig(b)
+	b.addVersions(ctx)
+	switch {
+	case utils.CheckFileExists(filepath.Join(t.deploymentPath, localComposeFileName)):
+		t.collectLocalSupportBundle(ctx, b)
+	case t.deployConfig != nil && t.deployConfig.K8s != nil:
+		t.collectK8sSupportBundle(ctx, b)
+	}
+	t.collectSupportBundleLogs(ctx, b, opts.LogSince)
+	t.collectSupportBundleHealth(ctx, b)
+	t.collectSupportBundleSDKLogs(b)
+
+	outputPath := opts.OutputPath
+	if outputPath == "" {
+		outputPath = filepath.Join(t.deploymentPath, fmt.Sprintf("support-bundle-%s.tar.gz", now.Format("20060102-150405")))
+	}
+	if err := b.write(outputPath, strings.TrimSuffix(filepath.Base(outputPath), ".tar.gz")); err != nil {
+		return "", fmt.Errorf("failed to write support bundle: %w", err)
+	}
+	return outputPath, nil
+}
+
+// collectSupportBundleConfig adds settings.json, rollup.json, the genesis without its alloc and the
+// deployed contract addresses
+func (t *ThanosStack) collectSupportBundleConfig(b *supportBundle) {
+	jsonFiles := map[string]string{
+		"settings.json": filepath.Join(t.deploymentPath, "settings.json"),
+		"rollup.json":   t.rollupConfigPath(),
+	}
+	if t.deployConfig != nil && t.deployConfig.DeploymentFilePath != "" {
+		jsonFiles["deployment.json"] = t.deployConfig.DeploymentFilePath
+	}
+	for name, path := range jsonFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			b.fail(name, err)
+			continue
+		}
+		b.addJSON(name, data)
+	}
+
+	data, err := os.ReadFile(t.genesisConfigPath())
+	if err != nil {
+		b.fail("genesis-metadata.json", err)
+		return
+	}
+	var genesis map[string]json.RawMessage
+	if err := json.Unmarshal(data, &genesis); err != nil {
+		b.fail("genesis-metadata.json", err)
+		return
+	}
+	delete(genesis, "alloc")
+	metadata, _ := json.Marshal(genesis)
+	b.addJSON("genesis-metadata.json", metadata)
+}
+
+// collectLocalSupportBundle adds the compose file and the containers of a local deployment
+func (t *ThanosStack) collectLocalSupportBundle(ctx context.Context, b *supportBundle) {
+	for _, name := range []string{localComposeFileName, ".env"} {
+		// Dot files are not listed by default once extracted
+		bundleName := strings.TrimPrefix(name, ".")
+		data, err := os.ReadFile(filepath.Join(t.deploymentPath, name))
+		if err != nil {
+			b.fail(bundleName, err)
+			continue
+		}
+		b.add(bundleName, []byte(logging.Redact(string(data))))
+	}
+	deploymentPath, _ := filepath.Abs(t.deploymentPath)
+	b.addCommand(ctx, "containers.txt", "docker", "ps", "--all",
+		"--filter", "label=com.docker.compose.project.working_dir="+deploymentPath,
+		"--format", "table {{.Names}}\t{{.Image}}\t{{.Status}}\t{{.Ports}}")
+}
+
+// collectK8sSupportBundle adds the pods, events, Helm releases and terraform outputs of an AWS deployment
+func (t *ThanosStack) collectK8sSupportBundle(ctx context.Context, b *supportBundle) {
+	namespace := t.deployConfig.K8s.Namespace
+	b.addCommand(ctx, "k8s/nodes.txt", "kubectl", "get", "nodes", "-o", "wide")
+	b.addCommand(ctx, "k8s/pods.txt", "kubectl", "get", "pods", "-n", namespace, "-o", "wide")
+	b.addCommand(ctx, "k8s/events.txt", "kubectl", "get", "events", "-n", namespace, "--sort-by=.lastTimestamp")
+	b.addCommand(ctx, "k8s/pvc.txt", "kubectl", "get", "pvc", "-n", namespace)
+	b.addCommand(ctx, "helm/releases.txt", "helm", "list", "-n", namespace)
+
+	releases, err := utils.GetHelmReleases(ctx, namespace)
+	if err != nil {
+		b.fail("helm", err)
+	}
+	for _, release := range releases {
+		b.addCommand(ctx, fmt.Sprintf("helm/%s-values.yaml", release), "helm", "get", "values", release, "-n", namespace, "-o", "yaml")
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, supportBundleCommandTimeout)
+	defer cancel()
+	output, err := utils.ExecuteCommand(cmdCtx, "bash", "-c", fmt.Sprintf(`cd %s/tokamak-thanos-stack/terraform &&
+		source .envrc &&
+		cd thanos-stack &&
+		terraform output -json`, t.deploymentPath))
+	if err != nil {
+		b.fail("terraform-outputs.json", fmt.Errorf("%w: %s", err, output))
+		return
+	}
+	outputs, err := redactTerraformOutputs([]byte(output))
+	if err != nil {
+		b.fail("terraform-outputs.json", err)
+		return
+	}
+	b.addJSON("terraform-outputs.json", outputs)
+}
+
+// collectSupportBundleLogs adds the logs of every running container or pod
+func (t *ThanosStack) collectSupportBundleLogs(ctx context.Context, b *supportBundle, since time.Duration) {
+	opts := &types.LogStreamOptions{Since: since, Tail: -1}
+	if since == 0 {
+		opts.Tail = supportBundleLogTail
+	}
+
+	var (
+		sources []logSource
+		err     error
+	)
+	switch {
+	case utils.CheckFileExists(filepath.Join(t.deploymentPath, localComposeFileName)):
+		sources, err = t.localLogSources(ctx, opts)
+	case t.deployConfig != nil && t.deployConfig.K8s != nil:
+		sources, err = t.k8sLogSources(ctx, opts)
+	default:
+		return
+	}
+	if err != nil {
+		b.fail("logs", err)
+		return
+	}
+
+	for _, source := range sources {
+		name := fmt.Sprintf("logs/%s.log", source.name)
+		cmdCtx, cancel := context.WithTimeout(ctx, supportBundleCommandTimeout)
+		var out bytes.Buffer
+		if err := streamLogSources(cmdCtx, []logSource{source}, opts, nil, &out); err != nil {
+			b.fail(name, err)
+		}
+		cancel()
+		b.add(name, []byte(logging.Redact(out.String())))
+	}
+}
+
+// collectSupportBundleHealth adds the `trh-sdk health --json` report
+func (t *ThanosStack) collectSupportBundleHealth(ctx context.Context, b *supportBundle) {
+	if t.deployConfig == nil {
+		return
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, supportBundleCommandTimeout)
+	defer cancel()
+	report, err := t.CheckHealth(cmdCtx, &types.HealthCheckInput{})
+	if err != nil {
+		b.fail("health.json", err)
+		return
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		b.fail("health.json", err)
+		return
+	}
+	b.addJSON("health.json", data)
+}
+
+// collectSupportBundleSDKLogs adds the end of the most recent trh-sdk log files
+func (t *ThanosStack) collectSupportBundleSDKLogs(b *supportBundle) {
+	paths, err := filepath.Glob(filepath.Join(t.deploymentPath, "logs", "*.log"))
+	if err != nil || len(paths) == 0 {
+		return
+	}
+	modTimes := map[string]time.Time{}
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime()
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool { return modTimes[paths[i]].After(modTimes[paths[j]]) })
+	if len(paths) > supportBundleSDKLogFiles {
+		paths = paths[:supportBundleSDKLogFiles]
+	}
+
+	for _, path := range paths {
+		name := "sdk-logs/" + filepath.Base(path)
+		data, err := readFileTail(path, supportBundleMaxFileSize)
+		if err != nil {
+			b.fail(name, err)
+			continue
+		}
+		b.add(name, []byte(logging.Redact(string(data))))
+	}
+}
+
+// redactTerraformOutputs masks the outputs marked sensitive
+func redactTerraformOutputs(data []byte) ([]byte, error) {
+	var outputs map[string]map[string]interface{}
+	if err := json.Unmarshal(data, &outputs); err != nil {
+		return nil, fmt.Errorf("failed to parse terraform outputs: %w", err)
+	}
+	for _, output := range outputs {
+		if sensitive, _ := output["sensitive"].(bool); sensitive {
+			output["value"] = logging.RedactedValue
+		}
+	}
+	return json.Marshal(outputs)
+}
+
+func readFileTail(path string, maxSize int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxSize {
+		if _, err := file.Seek(info.Size()-maxSize, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(file)
+}
+
+func (b *supportBundle) add(name string, data []byte) {
+	b.files[name] = data
+}
+
+// addJSON adds a JSON document with its secret fields masked
+func (b *supportBundle) addJSON(name string, data []byte) {
+	redacted, err := logging.RedactJSON(data)
+	if err != nil {
+		b.fail(name, fmt.Errorf("not valid JSON, left out: %w", err))
+		return
+	}
+	b.add(name, redacted)
+}
+
+// addCommand adds the redacted output of a command, also when it fails
+func (b *supportBundle) addCommand(ctx context.Context, name string, command string, args ...string) {
+	cmdCtx, cancel := context.WithTimeout(ctx, supportBundleCommandTimeout)
+	defer cancel()
+	output, err := utils.ExecuteCommand(cmdCtx, command, args...)
+	if err != nil {
+		b.fail(name, err)
+	}
+	if output != "" {
+		b.add(name, []byte(logging.Redact(output)+"\n"))
+	}
+}
+
+// addVersions records the versions of the deployment tools in versions.txt
+func (b *supportBundle) addVersions(ctx context.Context) {
+	var out strings.Builder
+	fmt.Fprintf(&out, "trh-sdk %s\n", b.manifest.SDKVersion)
+	for _, command := range supportBundleVersionCommands {
+		cmdCtx, cancel := context.WithTimeout(ctx, supportBundleCommandTimeout)
+		output, err := utils.ExecuteCommand(cmdCtx, command[0], command[1:]...)
+		cancel()
+		fmt.Fprintf(&out, "\n$ %s\n", strings.Join(command, " "))
+		if err != nil && output == "" {
+			output = err.Error()
+		}
+		out.WriteString(output + "\n")
+	}
+	b.add("versions.txt", []byte(out.String()))
+}
+
+func (b *supportBundle) fail(name string, err error) {
+	b.manifest.Errors[name] = logging.Redact(err.Error())
+}
+
+// write stores the files and manifest.json in a gzipped tarball under the root directory
+func (b *supportBundle) write(path, root string) error {
+	names := make([]string, 0, len(b.files))
+	for name := range b.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b.manifest.Files = names
+	manifest, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	entries := append([]string{"manifest.json"}, names...)
+	for _, name := range entries {
+		data := manifest
+		if name != "manifest.json" {
+			data = b.files[name]
+		}
+		header := &tar.Header{Name: root + "/" + name, Mode: 0600, Size: int64(len(data)), ModTime: b.manifest.CreatedAt}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -21,6 +21,12 @@ type ThanosStack struct {
 	deploymentPath    string
 	registerCandidate bool
 	output            io.Writer
+	// aaStatus is set while the stack runs as aa-operator
+	aaStatus *aaOperatorStatus
+	// shutdownRehearsal is set while the shutdown steps run against a local fork of L1
+	shutdownRehearsal bool
+	// shutdownBedrockPath replaces contracts-bedrock with the scratch copy a rehearsal runs in
+	shutdownBedrockPath string
 }
 
 func NewThanosStack(
@@ -90,7 +96,7 @@ func (t *ThanosStack) isAWSDeployment() bool {
 }
 
 // SetOutput sets the writer for binary subprocess stdout/stderr.
-// If not set, os.Stdout is used as default.
+// If not set, os.Stdout is used as default. Secrets are redacted before they reach the writer.
 func (t *ThanosStack) SetOutput(w io.Writer) {
 	t.output = w
 }
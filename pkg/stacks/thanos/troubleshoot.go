@@ -0,0 +1,377 @@
+package thanos
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	_ "embed"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+	"github.com/tokamak-network/trh-sdk/pkg/utils"
+)
+
+//go:embed templates/troubleshoot-rules.yaml
+var bundledTroubleshootRules []byte
+
+// TroubleshootRulesFileName is the per-deployment rules file that adds to or overrides the bundled rules
+const TroubleshootRulesFileName = "troubleshoot-rules.yaml"
+
+const (
+	// TroubleshootSDKComponent is the component of the SDK logs and errors
+	TroubleshootSDKComponent = "trh-sdk"
+	// TroubleshootEventsComponent is the component of the events of the chain namespace
+	TroubleshootEventsComponent = "k8s-events"
+)
+
+var troubleshootSeverityOrder = map[string]int{"critical": 0, "warning": 1, "info": 2}
+
+// LoadTroubleshootRules returns the bundled rules merged with troubleshoot-rules.yaml of the deployment.
+// A deployment rule with the id of a bundled rule replaces it.
+func LoadTroubleshootRules(deploymentPath string) ([]types.TroubleshootRule, error) {
+	rules, err := parseTroubleshootRules(bundledTroubleshootRules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundled troubleshoot rules: %w", err)
+	}
+
+	path := filepath.Join(deploymentPath, TroubleshootRulesFileName)
+	if !utils.CheckFileExists(path) {
+		return rules, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", TroubleshootRulesFileName, err)
+	}
+	custom, err := parseTroubleshootRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", TroubleshootRulesFileName, err)
+	}
+
+	index := make(map[string]int, len(rules))
+	for i, rule := range rules {
+		index[rule.ID] = i
+	}
+	for _, rule := range custom {
+		if i, ok := index[rule.ID]; ok {
+			rules[i] = rule
+			continue
+		}
+		index[rule.ID] = len(rules)
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseTroubleshootRules(data []byte) ([]types.TroubleshootRule, error) {
+	var rules []types.TroubleshootRule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for i, rule := range rules {
+		if rule.ID == "" {
+			return nil, fmt.Errorf("rule %d has no id", i+1)
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("rule %s is defined twice", rule.ID)
+		}
+		seen[rule.ID] = true
+		if len(rule.Patterns) == 0 {
+			return nil, fmt.Errorf("rule %s has no patterns", rule.ID)
+		}
+		for _, pattern := range rule.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("rule %s: invalid pattern %q: %w", rule.ID, pattern, err)
+			}
+		}
+		if rule.Severity == "" {
+			rules[i].Severity = "warning"
+		}
+		if _, ok := troubleshootSeverityOrder[rules[i].Severity]; !ok {
+			return nil, fmt.Errorf("rule %s: severity must be critical, warning or info", rule.ID)
+		}
+	}
+	return rules, nil
+}
+
+// Troubleshoot matches the recent component logs, the namespace events and the SDK logs against the
+// troubleshoot rules and returns the diagnosis and fix commands of every rule that matched
+func (t *ThanosStack) Troubleshoot(ctx context.Context, opts *types.TroubleshootOptions) (*types.TroubleshootReport, error) {
+	rules, err := LoadTroubleshootRules(t.deploymentPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, component := range opts.Components {
+		if !SupportedLogsComponents[component] && component != TroubleshootSDKComponent && component != TroubleshootEventsComponent {
+			return nil, fmt.Errorf("unsupported component: %s", component)
+		}
+	}
+
+	report := &types.TroubleshootReport{Since: opts.Since.String()}
+	logOpts := &types.LogStreamOptions{Components: opts.Components, Since: opts.Since, Tail: -1}
+	var (
+		sources []logSource
+		aws     bool
+	)
+	switch {
+	case utils.CheckFileExists(filepath.Join(t.deploymentPath, localComposeFileName)):
+		report.Deployment = "local"
+		sources, err = t.localLogSources(ctx, logOpts)
+	case t.deployConfig != nil && t.deployConfig.K8s != nil:
+		report.Deployment, aws = "aws", true
+		sources, err = t.k8sLogSources(ctx, logOpts)
+		if logComponentSelected(logOpts, TroubleshootEventsComponent) {
+			sources = append(sources, logSource{
+				component: TroubleshootEventsComponent,
+				name:      TroubleshootEventsComponent,
+				command:   []string{"kubectl", "get", "events", "-n", t.deployConfig.K8s.Namespace, "--sort-by=.lastTimestamp", "--no-headers"},
+			})
+		}
+	default:
+		report.Deployment = "none"
+	}
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+
+	engine := newTroubleshootEngine(rules, aws, t.troubleshootVars())
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source logSource) {
+			defer wg.Done()
+			err := readLogSource(ctx, source, func(line types.LogLine) {
+				mu.Lock()
+				defer mu.Unlock()
+				report.ScannedLines++
+				engine.observe(line)
+			})
+			if err != nil && ctx.Err() == nil {
+				mu.Lock()
+				report.Errors = append(report.Errors, logging.Redact(fmt.Sprintf("%s: %v", source.name, err)))
+				mu.Unlock()
+			}
+		}(source)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if logComponentSelected(logOpts, TroubleshootSDKComponent) {
+		scanned, err := t.scanSDKLogs(opts.Since, engine.observe)
+		report.ScannedLines += scanned
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+
+	sort.Strings(report.Errors)
+	report.Findings = engine.findings()
+	return report, nil
+}
+
+// DiagnoseError matches an error returned by a command against the troubleshoot rules of the SDK
+func DiagnoseError(deploymentPath string, err error) []types.TroubleshootFinding {
+	rules, loadErr := LoadTroubleshootRules(deploymentPath)
+	if loadErr != nil {
+		// A broken deployment rules file must not hide the diagnosis of the bundled rules
+		if rules, loadErr = parseTroubleshootRules(bundledTroubleshootRules); loadErr != nil {
+			return nil
+		}
+	}
+	// settings.json is read as is, ReadConfigFromJSONFile prints and may dial the L2 RPC
+	var config *types.Config
+	if data, err := os.ReadFile(filepath.Join(deploymentPath, types.ConfigFileName)); err == nil {
+		_ = json.Unmarshal(data, &config)
+	}
+	t := &ThanosStack{deploymentPath: deploymentPath, deployConfig: config}
+	aws := config != nil && config.K8s != nil && !utils.CheckFileExists(filepath.Join(deploymentPath, localComposeFileName))
+
+	engine := newTroubleshootEngine(rules, aws, t.troubleshootVars())
+	for _, line := range strings.Split(err.Error(), "\n") {
+		engine.observe(types.LogLine{Component: TroubleshootSDKComponent, Source: TroubleshootSDKComponent, Message: line})
+	}
+	return engine.findings()
+}
+
+// troubleshootVars are the placeholders of the fix commands
+func (t *ThanosStack) troubleshootVars() map[string]string {
+	deploymentPath, _ := filepath.Abs(t.deploymentPath)
+	vars := map[string]string{"deployment_path": deploymentPath, "namespace": "<namespace>", "chain_name": "<chain name>"}
+	if t.deployConfig != nil {
+		if t.deployConfig.ChainName != "" {
+			vars["chain_name"] = t.deployConfig.ChainName
+		}
+		if t.deployConfig.K8s != nil && t.deployConfig.K8s.Namespace != "" {
+			vars["namespace"] = t.deployConfig.K8s.Namespace
+		}
+	}
+	return vars
+}
+
+// scanSDKLogs passes the messages of the SDK log files written within since to observe
+func (t *ThanosStack) scanSDKLogs(since time.Duration, observe func(types.LogLine)) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(t.deploymentPath, "logs", "*.log"))
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-since)
+	scanned := 0
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || (since > 0 && info.ModTime().Before(cutoff)) {
+			continue
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return scanned, err
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var entry struct {
+				Timestamp string `json:"timestamp"`
+				Msg       string `json:"msg"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				continue
+			}
+			timestamp, _ := time.Parse("2006-01-02T15:04:05.000Z0700", entry.Timestamp)
+			if since > 0 && !timestamp.IsZero() && timestamp.Before(cutoff) {
+				continue
+			}
+			scanned++
+			observe(types.LogLine{Time: timestamp, Component: TroubleshootSDKComponent, Source: filepath.Base(path), Message: entry.Msg})
+		}
+		file.Close()
+	}
+	return scanned, nil
+}
+
+type troubleshootMatcher struct {
+	rule       types.TroubleshootRule
+	components map[string]bool
+	patterns   []*regexp.Regexp
+}
+
+// troubleshootEngine matches log lines against the rules and aggregates the matches per rule
+type troubleshootEngine struct {
+	matchers []troubleshootMatcher
+	aws      bool
+	vars     map[string]string
+	matches  map[string]*types.TroubleshootFinding
+	// matchVars are the component and source of the first match of each rule
+	matchVars map[string]map[string]string
+}
+
+func newTroubleshootEngine(rules []types.TroubleshootRule, aws bool, vars map[string]string) *troubleshootEngine {
+	e := &troubleshootEngine{aws: aws, vars: vars, matches: map[string]*types.TroubleshootFinding{}, matchVars: map[string]map[string]string{}}
+	for _, rule := range rules {
+		m := troubleshootMatcher{rule: rule, components: map[string]bool{}}
+		for _, component := range rule.Components {
+			m.components[component] = true
+		}
+		for _, pattern := range rule.Patterns {
+			m.patterns = append(m.patterns, regexp.MustCompile(pattern))
+		}
+		e.matchers = append(e.matchers, m)
+	}
+	return e
+}
+
+func (e *troubleshootEngine) observe(line types.LogLine) {
+	for _, m := range e.matchers {
+		if len(m.components) > 0 && !m.components[line.Component] {
+			continue
+		}
+		if !m.matches(line.Message) {
+			continue
+		}
+		finding, ok := e.matches[m.rule.ID]
+		if !ok {
+			finding = &types.TroubleshootFinding{
+				RuleID:    m.rule.ID,
+				Title:     m.rule.Title,
+				Severity:  m.rule.Severity,
+				Diagnosis: strings.TrimSpace(m.rule.Diagnosis),
+				Example:   logging.Redact(strings.TrimSpace(line.Message)),
+			}
+			e.matches[m.rule.ID] = finding
+			e.matchVars[m.rule.ID] = map[string]string{"component": line.Component, "source": line.Source}
+		}
+		finding.Count++
+		if !slices.Contains(finding.Sources, line.Source) {
+			finding.Sources = append(finding.Sources, line.Source)
+		}
+		if !line.Time.IsZero() {
+			if finding.FirstSeen.IsZero() || line.Time.Before(finding.FirstSeen) {
+				finding.FirstSeen = line.Time
+			}
+			if line.Time.After(finding.LastSeen) {
+				finding.LastSeen = line.Time
+			}
+		}
+	}
+}
+
+func (m troubleshootMatcher) matches(message string) bool {
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(message) {
+			return true
+		}
+	}
+	return false
+}
+
+// findings returns the matched rules, most severe and most frequent first
+func (e *troubleshootEngine) findings() []types.TroubleshootFinding {
+	findings := make([]types.TroubleshootFinding, 0, len(e.matches))
+	for _, m := range e.matchers {
+		finding, ok := e.matches[m.rule.ID]
+		if !ok {
+			continue
+		}
+		fix := append([]string{}, m.rule.Fix...)
+		if e.aws {
+			fix = append(fix, m.rule.AWSFix...)
+		} else {
+			fix = append(fix, m.rule.LocalFix...)
+		}
+		pairs := []string{}
+		for name, value := range e.vars {
+			pairs = append(pairs, "{"+name+"}", value)
+		}
+		for name, value := range e.matchVars[m.rule.ID] {
+			pairs = append(pairs, "{"+name+"}", value)
+		}
+		replacer := strings.NewReplacer(pairs...)
+		for _, command := range fix {
+			finding.Fix = append(finding.Fix, replacer.Replace(command))
+		}
+		sort.Strings(finding.Sources)
+		findings = append(findings, *finding)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return troubleshootSeverityOrder[findings[i].Severity] < troubleshootSeverityOrder[findings[j].Severity]
+		}
+		return findings[i].Count > findings[j].Count
+	})
+	return findings
+}
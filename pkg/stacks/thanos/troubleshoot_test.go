@@ -0,0 +1,110 @@
+package thanos
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/trh-sdk/pkg/types"
+)
+
+func TestLoadTroubleshootRules(t *testing.T) {
+	dir := t.TempDir()
+	rules, err := LoadTroubleshootRules(dir)
+	require.NoError(t, err)
+	bundled := len(rules)
+	require.Greater(t, bundled, 10)
+
+	custom := `
+- id: batcher-nonce-too-low
+  title: Our own nonce rule
+  patterns: ['nonce too low']
+  diagnosis: Ask the on-call.
+- id: sequencer-drift
+  title: Sequencer drift
+  severity: info
+  components: [op-node]
+  patterns: ['(?i)sequencer drift']
+  diagnosis: The sequencer is ahead of L1.
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, TroubleshootRulesFileName), []byte(custom), 0644))
+	rules, err = LoadTroubleshootRules(dir)
+	require.NoError(t, err)
+	require.Len(t, rules, bundled+2, "an unknown id is added")
+	require.Equal(t, "sequencer-drift", rules[len(rules)-1].ID)
+	require.Equal(t, "warning", rules[len(rules)-2].Severity, "severity defaults to warning")
+
+	custom = "- id: tx-nonce-too-low\n  title: Our own nonce rule\n  patterns: ['nonce too low']\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, TroubleshootRulesFileName), []byte(custom), 0644))
+	rules, err = LoadTroubleshootRules(dir)
+	require.NoError(t, err)
+	require.Len(t, rules, bundled, "a bundled id is replaced")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, TroubleshootRulesFileName), []byte("- id: broken\n  patterns: ['(']\n"), 0644))
+	_, err = LoadTroubleshootRules(dir)
+	require.ErrorContains(t, err, "rule broken: invalid pattern")
+}
+
+func TestTroubleshootEngine(t *testing.T) {
+	rules, err := parseTroubleshootRules(bundledTroubleshootRules)
+	require.NoError(t, err)
+	at := func(minute int) time.Time { return time.Date(2026, 5, 1, 10, minute, 0, 0, time.UTC) }
+
+	engine := newTroubleshootEngine(rules, true, map[string]string{"namespace": "thanos-ns", "deployment_path": "/srv/chain"})
+	for _, line := range []types.LogLine{
+		{Time: at(1), Component: "op-batcher", Source: "thanos-op-batcher-7d9f-x2", Message: "lvl=warn msg=\"Transaction failed\" err=\"nonce too low\""},
+		{Time: at(3), Component: "op-batcher", Source: "thanos-op-batcher-7d9f-x2", Message: "lvl=warn msg=\"Transaction failed\" err=\"nonce too low\""},
+		{Time: at(2), Component: "op-node", Source: "thanos-op-node-0", Message: "failed to fetch blobs: 404 Not Found"},
+		{Time: at(2), Component: "op-geth", Source: "thanos-op-geth-0", Message: "nonce too low"},
+		{Component: "k8s-events", Source: "k8s-events", Message: "Warning FailedScheduling pod has unbound immediate PersistentVolumeClaims"},
+	} {
+		engine.observe(line)
+	}
+
+	findings := engine.findings()
+	require.Len(t, findings, 3, "op-geth is not a component of the nonce rule")
+	require.Equal(t, "l1-beacon-blob-not-found", findings[0].RuleID, "critical before warning, in rule order")
+	require.Equal(t, "pvc-not-bound", findings[1].RuleID)
+	require.Contains(t, findings[1].Fix, "kubectl -n thanos-ns get pvc")
+
+	nonce := findings[2]
+	require.Equal(t, "tx-nonce-too-low", nonce.RuleID)
+	require.Equal(t, 2, nonce.Count)
+	require.Equal(t, at(1), nonce.FirstSeen)
+	require.Equal(t, at(3), nonce.LastSeen)
+	require.Contains(t, nonce.Fix, "kubectl -n thanos-ns delete pod thanos-op-batcher-7d9f-x2")
+	require.NotContains(t, nonce.Fix, "docker compose -f /srv/chain/docker-compose.local.yml restart op-batcher")
+}
+
+func TestTroubleshootSDKLogs(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "logs"), 0755))
+	now := time.Now().UTC()
+	sdkLog := `{"level":"info","timestamp":"` + now.Add(-2*time.Hour).Format("2006-01-02T15:04:05.000Z0700") + `","msg":"Error acquiring the state lock (old)"}
+{"level":"error","timestamp":"` + now.Format("2006-01-02T15:04:05.000Z0700") + `","msg":"Error: Error acquiring the state lock"}
+not json
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "logs", "deploy_1.log"), []byte(sdkLog), 0644))
+
+	stack := &ThanosStack{deploymentPath: dir}
+	report, err := stack.Troubleshoot(context.Background(), &types.TroubleshootOptions{Since: time.Hour})
+	require.NoError(t, err)
+	require.Equal(t, "none", report.Deployment)
+	require.Equal(t, 1, report.ScannedLines, "lines older than the window are skipped")
+	require.Len(t, report.Findings, 1)
+	require.Equal(t, "terraform-state-locked", report.Findings[0].RuleID)
+	require.Equal(t, []string{"deploy_1.log"}, report.Findings[0].Sources)
+
+	_, err = stack.Troubleshoot(context.Background(), &types.TroubleshootOptions{Components: []string{"op-nod"}})
+	require.ErrorContains(t, err, "unsupported component: op-nod")
+
+	findings := DiagnoseError(dir, errors.New("failed to deploy: exit status 1\nAn error occurred (ExpiredToken) when calling the GetCallerIdentity operation"))
+	require.Len(t, findings, 1)
+	require.Equal(t, "aws-credentials-invalid", findings[0].RuleID)
+	require.Empty(t, DiagnoseError(dir, errors.New("chain name is required")))
+}
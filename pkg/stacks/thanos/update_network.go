@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/tokamak-network/trh-sdk/pkg/constants"
 	"github.com/tokamak-network/trh-sdk/pkg/types"
@@ -32,6 +33,8 @@ func (t *ThanosStack) UpdateNetwork(ctx context.Context, inputs *UpdateNetworkIn
 		return err
 	}
 
+	defer t.silenceAlertsDuring(ctx, "network update", time.Hour)()
+
 	if inputs.L1RPC != "" {
 		t.deployConfig.L1RPCURL = inputs.L1RPC
 		t.deployConfig.L1RPCProvider = utils.DetectRPCKind(inputs.L1RPC)
@@ -0,0 +1,67 @@
+package types
+
+// AlertPolicyAPIVersion and AlertPolicyKind identify alert policy files managed by alert-config export/apply
+const (
+	AlertPolicyAPIVersion = "trh-sdk/v1"
+	AlertPolicyKind       = "AlertPolicy"
+)
+
+// AlertPolicy is the file representation of all alert rules and notification channels
+type AlertPolicy struct {
+	APIVersion string              `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string              `json:"kind" yaml:"kind"`
+	Rules      []AlertPolicyRule   `json:"rules" yaml:"rules"`
+	Channels   AlertPolicyChannels `json:"channels" yaml:"channels"`
+	// Routes replaces the routing tree when set; omit it to keep the current routes
+	Routes []AlertRoute `json:"routes,omitempty" yaml:"routes,omitempty"`
+}
+
+// AlertPolicyRule is a single Prometheus alerting rule with arbitrary PromQL
+type AlertPolicyRule struct {
+	Group       string            `json:"group,omitempty" yaml:"group,omitempty"`
+	Alert       string            `json:"alert" yaml:"alert"`
+	Expr        string            `json:"expr" yaml:"expr"`
+	For         string            `json:"for,omitempty" yaml:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// AlertPolicyChannels holds the notification channels of an alert policy
+type AlertPolicyChannels struct {
+	Email     *AlertPolicyEmailChannel    `json:"email,omitempty" yaml:"email,omitempty"`
+	Telegram  *AlertPolicyTelegramChannel `json:"telegram,omitempty" yaml:"telegram,omitempty"`
+	Slack     *SlackConfig                `json:"slack,omitempty" yaml:"slack,omitempty"`
+	Discord   *DiscordConfig              `json:"discord,omitempty" yaml:"discord,omitempty"`
+	PagerDuty *PagerDutyConfig            `json:"pagerduty,omitempty" yaml:"pagerduty,omitempty"`
+	Webhook   *WebhookConfig              `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+}
+
+// AlertPolicyEmailChannel is the email channel of an alert policy
+type AlertPolicyEmailChannel struct {
+	Enabled          bool     `json:"enabled" yaml:"enabled"`
+	SmtpSmarthost    string   `json:"smtp_smarthost,omitempty" yaml:"smtp_smarthost,omitempty"`
+	SmtpFrom         string   `json:"smtp_from,omitempty" yaml:"smtp_from,omitempty"`
+	SmtpAuthPassword string   `json:"smtp_auth_password,omitempty" yaml:"smtp_auth_password,omitempty"`
+	Receivers        []string `json:"receivers,omitempty" yaml:"receivers,omitempty"`
+}
+
+// AlertPolicyTelegramChannel is the Telegram channel of an alert policy
+type AlertPolicyTelegramChannel struct {
+	Enabled  bool     `json:"enabled" yaml:"enabled"`
+	BotToken string   `json:"bot_token,omitempty" yaml:"bot_token,omitempty"`
+	ChatIDs  []string `json:"chat_ids,omitempty" yaml:"chat_ids,omitempty"`
+}
+
+// AlertPolicyDiff describes the changes needed to move the cluster to a desired alert policy
+type AlertPolicyDiff struct {
+	AddedRules      []string `json:"addedRules,omitempty"`
+	RemovedRules    []string `json:"removedRules,omitempty"`
+	ChangedRules    []string `json:"changedRules,omitempty"`
+	ChangedChannels []string `json:"changedChannels,omitempty"`
+	RoutesChanged   bool     `json:"routesChanged,omitempty"`
+}
+
+// HasChanges reports whether applying the policy would change anything
+func (d *AlertPolicyDiff) HasChanges() bool {
+	return len(d.AddedRules)+len(d.RemovedRules)+len(d.ChangedRules)+len(d.ChangedChannels) > 0 || d.RoutesChanged
+}
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // Backup related constants
 const (
 	DefaultBackupRetentionDays = 0
@@ -20,6 +22,19 @@ type BackupStatusInfo struct {
 	BackupVaults        []string
 	BackupSchedule      string
 	NextBackupTime      string
+	// Rules are the rules of the backup plan with their next run, one per schedule
+	Rules []BackupRuleStatus
+
+	// Block explorer database, empty when the explorer is not installed
+	RDSIdentifier          string
+	RDSARN                 string
+	RDSProtected           bool
+	RDSLatestRecoveryPoint string
+
+	// Cross-region copies, empty when the backup plan does not copy recovery points
+	CopyRegion              string
+	CopyVaultARN            string
+	CopyLatestRecoveryPoint string
 }
 
 // BackupSnapshotInfo represents backup snapshot information
@@ -30,6 +45,10 @@ type BackupSnapshotInfo struct {
 	ARN       string
 	JobID     string
 	Status    string
+	RDSARN    string // Block explorer database, empty when the explorer is not installed
+	RDSJobID  string
+	// CopyJobIDs are the jobs copying the new recovery points to the copy region
+	CopyJobIDs []string
 }
 
 // BackupListInfo represents backup list information
@@ -40,6 +59,7 @@ type BackupListInfo struct {
 	ResourceARN    string // EFS file system ARN
 	Limit          string
 	RecoveryPoints []RecoveryPoint
+	RDSARN         string // Block explorer database ARN, empty when the explorer is not installed
 }
 
 // RecoveryPoint represents a single recovery point
@@ -49,6 +69,8 @@ type RecoveryPoint struct {
 	Created          string
 	Expiry           string
 	Status           string
+	// RDSRecoveryPointARN is the block explorer database recovery point taken with this one
+	RDSRecoveryPointARN string `json:",omitempty"`
 }
 
 // BackupRestoreInfo represents backup restore information
@@ -64,6 +86,11 @@ type BackupRestoreInfo struct {
 	SuggestedEFSID   string
 	SuggestedPVCs    string
 	SuggestedSTSs    string
+
+	// Block explorer database restored together with the EFS
+	RDSRecoveryPointARN string
+	RDSPreviousInstance string // The replaced instance, kept for rollback
+	RDSStatus           string
 }
 
 // BackupAttachInfo represents backup attach information
@@ -84,4 +111,125 @@ type BackupConfigInfo struct {
 	Keep      string
 	Reset     bool
 	Status    string
+
+	// CopyRegion is the region recovery points are copied to, "none" turns copies off
+	CopyRegion string
+	CopyKeep   string
+
+	// Rules replace the single schedule of the backup plan, e.g. hourly, daily, weekly and monthly
+	Rules []BackupRule
+}
+
+// BackupRule is a rule of the backup plan: a schedule with its own retention, cold storage transition and
+// recovery point tags
+type BackupRule struct {
+	Name               string `json:"name"` // hourly, daily, weekly or monthly
+	ScheduleExpression string `json:"schedule_expression"`
+	// DeleteAfterDays is how long recovery points of the rule are kept, 0 keeps them until deleted
+	DeleteAfterDays int `json:"delete_after_days,omitempty"`
+	// ColdStorageAfterDays moves recovery points to cold storage after this many days, 0 never does
+	ColdStorageAfterDays int               `json:"cold_storage_after_days,omitempty"`
+	Tags                 map[string]string `json:"tags,omitempty"`
+}
+
+// BackupRuleStatus is a rule of the backup plan as shown by backup-manager --status
+type BackupRuleStatus struct {
+	Name                 string
+	Schedule             string
+	NextRun              string
+	DeleteAfterDays      int
+	ColdStorageAfterDays int
+	Tags                 map[string]string
+}
+
+// LocalBackupManifest is manifest.json of a backup of the docker volumes of a local deployment
+type LocalBackupManifest struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	ChainName string    `json:"chainName,omitempty"`
+	L2ChainID uint64    `json:"l2ChainId"`
+	// GenesisHash is the sha256 of the genesis.json op-geth was initialized with
+	GenesisHash string `json:"genesisHash"`
+	// Quiesced is true when the containers were stopped while the volumes were archived
+	Quiesced bool                `json:"quiesced"`
+	Volumes  []LocalBackupVolume `json:"volumes"`
+}
+
+// LocalBackupVolume is the archive of a docker volume in a local backup
+type LocalBackupVolume struct {
+	Name string `json:"name"`
+	// ComposeVolume is the volume name in the compose file, empty for external volumes
+	ComposeVolume string `json:"composeVolume,omitempty"`
+	File          string `json:"file"`
+	Size          int64  `json:"size"`
+	SHA256        string `json:"sha256"`
+}
+
+// BackupVerifyOptions configures `backup-manager verify`
+type BackupVerifyOptions struct {
+	RecoveryPointARN string
+	// DerivationTimeout bounds how long the restored op-node may take to advance its safe head
+	DerivationTimeout time.Duration
+	// KeepResources leaves the scratch namespace and the restored EFS in place for inspection
+	KeepResources bool
+}
+
+// BackupVerificationCheck is one check of a restore verification
+type BackupVerificationCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// BackupVerificationReport is the result of `backup-manager verify`
+type BackupVerificationReport struct {
+	RecoveryPointARN     string `json:"recoveryPointArn"`
+	RecoveryPointCreated string `json:"recoveryPointCreated,omitempty"`
+	// Namespace is the scratch namespace the restored op-geth and op-node ran in
+	Namespace string `json:"namespace"`
+	// RestoreJobID is the AWS Backup job restoring the EFS, used to find the EFS when the restore was interrupted
+	RestoreJobID string    `json:"restoreJobId,omitempty"`
+	EFSID        string    `json:"efsId,omitempty"`
+	StartedAt    time.Time `json:"startedAt"`
+	FinishedAt   time.Time `json:"finishedAt"`
+	Passed       bool      `json:"passed"`
+	// Head is the restored op-geth head before op-node was started
+	Head          *BlockRef                 `json:"head,omitempty"`
+	HeadStateRoot string                    `json:"headStateRoot,omitempty"`
+	Checks        []BackupVerificationCheck `json:"checks"`
+	Error         string                    `json:"error,omitempty"`
+	TornDown      bool                      `json:"tornDown"`
+}
+
+// BackupFailoverOptions configures `backup-manager failover`
+type BackupFailoverOptions struct {
+	// Region is the secondary region holding the recovery point copies and the standby cluster
+	Region string
+	// RecoveryPointARN is a recovery point in the secondary region vault, the latest copy when empty
+	RecoveryPointARN string
+	// ClusterName is the standby EKS cluster, the chain namespace when empty
+	ClusterName string
+	// HostedZoneID and DNSName name a Route 53 CNAME pointed at the new L2 RPC ingress
+	HostedZoneID string
+	DNSName      string
+}
+
+// BackupFailoverReport is the result of `backup-manager failover`
+type BackupFailoverReport struct {
+	Namespace            string    `json:"namespace"`
+	PreviousRegion       string    `json:"previousRegion"`
+	Region               string    `json:"region"`
+	ClusterName          string    `json:"clusterName"`
+	RecoveryPointARN     string    `json:"recoveryPointArn,omitempty"`
+	RecoveryPointCreated string    `json:"recoveryPointCreated,omitempty"`
+	RestoreJobID         string    `json:"restoreJobId,omitempty"`
+	EFSID                string    `json:"efsId,omitempty"`
+	HelmReleases         []string  `json:"helmReleases,omitempty"`
+	PreviousL2RpcURL     string    `json:"previousL2RpcUrl,omitempty"`
+	L2RpcURL             string    `json:"l2RpcUrl,omitempty"`
+	DNSRecord            string    `json:"dnsRecord,omitempty"`
+	StartedAt            time.Time `json:"startedAt"`
+	FinishedAt           time.Time `json:"finishedAt"`
+	Completed            bool      `json:"completed"`
+	Error                string    `json:"error,omitempty"`
 }
@@ -0,0 +1,49 @@
+package types
+
+import "time"
+
+// ChainSnapshotManifest is manifest.json of an op-geth datadir snapshot, written next to the archive
+type ChainSnapshotManifest struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	ChainName string    `json:"chainName,omitempty"`
+	L2ChainID uint64    `json:"l2ChainId"`
+	// BlockNumber and BlockHash are the op-geth head when it was stopped for the snapshot
+	BlockNumber uint64 `json:"blockNumber"`
+	BlockHash   string `json:"blockHash"`
+	StateRoot   string `json:"stateRoot"`
+	// GenesisHash is the hash of block 0, which identifies the chain the datadir belongs to
+	GenesisHash   string `json:"genesisHash"`
+	ClientVersion string `json:"clientVersion"`
+	// Source is "local" for the docker volume of a local deployment, "kubernetes" for the op-geth PVC
+	Source string `json:"source"`
+	File   string `json:"file"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ChainSnapshotExportOptions are the options of `trh-sdk snapshot export`
+type ChainSnapshotExportOptions struct {
+	// Destination is a directory or an s3://bucket/prefix URL the snapshot directory is created in
+	Destination string
+	// EndpointURL is the endpoint of an S3-compatible store, empty for AWS S3
+	EndpointURL string
+	// Yes skips the confirmation before the sequencer of a Kubernetes deployment is scaled down
+	Yes bool
+}
+
+// ChainSnapshotImportOptions are the options of `trh-sdk snapshot import`. Without Volume or PVC the
+// op-geth datadir of the deployment is replaced.
+type ChainSnapshotImportOptions struct {
+	// Source is the snapshot directory or s3:// URL printed by the export
+	Source      string
+	EndpointURL string
+	// Volume is a docker volume to seed instead of the op-geth volume of the local deployment
+	Volume string
+	// PVC, Namespace and SubPath are a persistent volume claim to seed instead of the op-geth claim
+	PVC       string
+	Namespace string
+	SubPath   string
+	// AllowOtherChain skips the check that the snapshot belongs to the chain ID of the deployment
+	AllowOtherChain bool
+}
@@ -165,6 +165,10 @@ type Config struct {
 	Preset   string `json:"preset,omitempty"`
 	FeeToken string `json:"fee_token,omitempty"` // "TON", "ETH", "USDT", "USDC"
 
+	// AAOperatorMetricsAddr is the listen address of the aa-operator /metrics and /healthz endpoints
+	// when it runs from the deployment config. Empty uses :7311, "off" disables them.
+	AAOperatorMetricsAddr string `json:"aa_operator_metrics_addr,omitempty"`
+
 	// Mnemonic for deterministic key derivation (used for DRB operator key generation in Gaming/Full presets)
 	Mnemonic string `json:"mnemonic,omitempty"`
 
@@ -182,6 +186,11 @@ type CrossTradeLocalContracts struct {
 
 type BackupConfiguration struct {
 	Enabled bool `json:"enabled"` // Whether automatic backup is enabled
+	// CopyRegion is the secondary region recovery points are copied to for disaster recovery
+	CopyRegion          string `json:"copy_region,omitempty"`
+	CopyDeleteAfterDays int    `json:"copy_delete_after_days,omitempty"` // 0 keeps copies until deleted
+	// Rules replace the single terraform schedule of the backup plan when set
+	Rules []BackupRule `json:"rules,omitempty"`
 }
 
 type ShutdownConfig struct {
@@ -0,0 +1,44 @@
+package types
+
+// CostEstimateInput holds the sources used to estimate the monthly AWS cost of a deployment
+type CostEstimateInput struct {
+	PlanPath       string   // terraform plan (binary or `terraform show -json` output)
+	ValuesPath     string   // thanos-stack Helm values file
+	PriceTablePath string   // optional price table overriding the bundled one
+	Preset         string   // preset to estimate (defaults to settings.json preset)
+	ComparePresets []string // presets to compare against the estimated one
+}
+
+// CostLineItem represents a single priced resource in a cost estimate
+type CostLineItem struct {
+	Category   string  `json:"category"`
+	Resource   string  `json:"resource"`
+	Detail     string  `json:"detail,omitempty"`
+	Quantity   float64 `json:"quantity"`
+	Unit       string  `json:"unit"`
+	MonthlyUSD float64 `json:"monthly_usd"`
+}
+
+// CostEstimate represents the monthly cost breakdown for a single preset
+type CostEstimate struct {
+	Region            string         `json:"region"`
+	Network           string         `json:"network"`
+	Preset            string         `json:"preset"`
+	PriceTableVersion string         `json:"price_table_version"`
+	Sources           []string       `json:"sources"`
+	Items             []CostLineItem `json:"items"`
+	TotalMonthlyUSD   float64        `json:"total_monthly_usd"`
+}
+
+// PresetCostComparison represents the total monthly cost of a preset relative to the estimated one
+type PresetCostComparison struct {
+	Preset          string  `json:"preset"`
+	TotalMonthlyUSD float64 `json:"total_monthly_usd"`
+	DeltaUSD        float64 `json:"delta_usd"`
+}
+
+// CostReport bundles the estimate for the selected preset and the preset comparison
+type CostReport struct {
+	Estimate    *CostEstimate          `json:"estimate"`
+	Comparisons []PresetCostComparison `json:"comparisons,omitempty"`
+}
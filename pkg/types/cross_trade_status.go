@@ -0,0 +1,93 @@
+package types
+
+import (
+	"time"
+
+	"github.com/tokamak-network/trh-sdk/pkg/constants"
+)
+
+// CrossTradeRequestStatus is the state of a cross-trade request
+type CrossTradeRequestStatus string
+
+const (
+	// CrossTradeRequestOpen waits for a provider
+	CrossTradeRequestOpen CrossTradeRequestStatus = "open"
+	// CrossTradeRequestExpired waited for a provider longer than the expiry
+	CrossTradeRequestExpired CrossTradeRequestStatus = "expired"
+	// CrossTradeRequestRelaying was provided or cancelled on L1, the message to L2 is being relayed
+	CrossTradeRequestRelaying CrossTradeRequestStatus = "relaying"
+	// CrossTradeRequestStuck was provided or cancelled on L1 but the message never completed it on L2
+	CrossTradeRequestStuck CrossTradeRequestStatus = "stuck"
+	// CrossTradeRequestFulfilled was claimed by its provider on L2
+	CrossTradeRequestFulfilled CrossTradeRequestStatus = "fulfilled"
+	// CrossTradeRequestCancelled was cancelled and refunded on L2
+	CrossTradeRequestCancelled CrossTradeRequestStatus = "cancelled"
+)
+
+// CrossTradeRequestStatuses lists the request statuses in report order
+var CrossTradeRequestStatuses = []CrossTradeRequestStatus{
+	CrossTradeRequestOpen,
+	CrossTradeRequestExpired,
+	CrossTradeRequestRelaying,
+	CrossTradeRequestStuck,
+	CrossTradeRequestFulfilled,
+	CrossTradeRequestCancelled,
+}
+
+// CrossTradeStatusInput selects the requests of `trh-sdk cross-trade status`
+type CrossTradeStatusInput struct {
+	// Mode limits the report to one deploy mode, empty reports every configured mode
+	Mode constants.CrossTradeDeployMode
+	// FromBlock is the first L2 block searched for requests
+	FromBlock uint64
+	// ExpireAfter is the age after which an open request is expired, 0 uses the default
+	ExpireAfter time.Duration
+	// StuckAfter is the time after which a request provided or cancelled on L1 is stuck when L2 did
+	// not complete it, 0 uses the default
+	StuckAfter time.Duration
+}
+
+// CrossTradeRequest is a request made on an L2 cross-trade contract
+type CrossTradeRequest struct {
+	Mode               constants.CrossTradeDeployMode `json:"mode"`
+	Hash               string                         `json:"hash"`
+	SaleCount          string                         `json:"saleCount"`
+	SourceChainID      uint64                         `json:"sourceChainId"`
+	DestinationChainID uint64                         `json:"destinationChainId"`
+	L1Token            string                         `json:"l1Token"`
+	L2Token            string                         `json:"l2Token"`
+	Symbol             string                         `json:"symbol,omitempty"`
+	Requester          string                         `json:"requester"`
+	Receiver           string                         `json:"receiver"`
+	Provider           string                         `json:"provider,omitempty"`
+	// TotalAmount is paid by the requester on L2, CtAmount is asked from the provider on L1
+	TotalAmount string `json:"totalAmount"`
+	CtAmount    string `json:"ctAmount"`
+	// Registered is unset for requests of tokens not registered on the contract
+	Registered  bool                    `json:"registered"`
+	RequestTx   string                  `json:"requestTx"`
+	RequestedAt string                  `json:"requestedAt"`
+	L1Tx        string                  `json:"l1Tx,omitempty"`
+	L1At        string                  `json:"l1At,omitempty"`
+	CompleteTx  string                  `json:"completeTx,omitempty"`
+	Status      CrossTradeRequestStatus `json:"status"`
+}
+
+// CrossTradeTokenStatus totals the requests of a token on a source chain by status
+type CrossTradeTokenStatus struct {
+	Mode          constants.CrossTradeDeployMode `json:"mode"`
+	SourceChainID uint64                         `json:"sourceChainId"`
+	L1Token       string                         `json:"l1Token"`
+	Symbol        string                         `json:"symbol,omitempty"`
+	// Counts and Amounts are by status, amounts are the total amounts of the requests
+	Counts  map[CrossTradeRequestStatus]int    `json:"counts"`
+	Amounts map[CrossTradeRequestStatus]string `json:"amounts"`
+}
+
+// CrossTradeStatusReport is the result of `trh-sdk cross-trade status`
+type CrossTradeStatusReport struct {
+	GeneratedAt string                  `json:"generatedAt"`
+	Tokens      []CrossTradeTokenStatus `json:"tokens"`
+	Requests    []CrossTradeRequest     `json:"requests"`
+	Stuck       int                     `json:"stuck"`
+}
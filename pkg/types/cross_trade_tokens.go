@@ -0,0 +1,70 @@
+package types
+
+import "github.com/tokamak-network/trh-sdk/pkg/constants"
+
+// CrossTradeTokenState is the outcome of comparing a token registration with the contracts
+type CrossTradeTokenState string
+
+const (
+	// CrossTradeTokenRegistered is in settings.json and registered on the contract
+	CrossTradeTokenRegistered CrossTradeTokenState = "registered"
+	// CrossTradeTokenMissing is in settings.json and not registered on the contract
+	CrossTradeTokenMissing CrossTradeTokenState = "missing"
+	// CrossTradeTokenIncorrect is in settings.json while the contract registers the source token to the
+	// destination chain with another L1 or destination token
+	CrossTradeTokenIncorrect CrossTradeTokenState = "incorrect"
+	// CrossTradeTokenUnexpected is registered on the contract without an entry in settings.json
+	CrossTradeTokenUnexpected CrossTradeTokenState = "unexpected"
+)
+
+// CrossTradeTokensInput selects the registrations of `trh-sdk cross-trade tokens diff|sync`
+type CrossTradeTokensInput struct {
+	// Mode limits the registrations to one deploy mode, empty uses every deployed mode
+	Mode constants.CrossTradeDeployMode
+	// FromBlock is the first L2 block searched for the registration events of the L2toL2 contracts
+	FromBlock uint64
+	// DryRun prints the registrations sync would submit without sending them
+	DryRun bool
+}
+
+// CrossTradeTokenPair is a token pair registered on an L2 cross-trade contract. DestinationChainID and
+// DestinationToken are only set in the l2_to_l2 mode.
+type CrossTradeTokenPair struct {
+	TokenName          string `json:"tokenName,omitempty"`
+	L1ChainID          uint64 `json:"l1ChainId"`
+	L1Token            string `json:"l1Token"`
+	ChainID            uint64 `json:"chainId"`
+	L2Token            string `json:"l2Token"`
+	DestinationChainID uint64 `json:"destinationChainId,omitempty"`
+	DestinationToken   string `json:"destinationToken,omitempty"`
+}
+
+// CrossTradeTokenDiff is a registration in settings.json or on a contract and how they compare
+type CrossTradeTokenDiff struct {
+	Mode  constants.CrossTradeDeployMode `json:"mode"`
+	State CrossTradeTokenState           `json:"state"`
+	// Expected is the registration in settings.json, unset for unexpected registrations
+	Expected *CrossTradeTokenPair `json:"expected,omitempty"`
+	// OnChain is the registration that differs from settings.json, set for incorrect and unexpected ones
+	OnChain *CrossTradeTokenPair `json:"onChain,omitempty"`
+}
+
+// CrossTradeTokenTx is a registration submitted by sync
+type CrossTradeTokenTx struct {
+	Mode constants.CrossTradeDeployMode `json:"mode"`
+	// Method is registerToken or deleteToken
+	Method string              `json:"method"`
+	Pair   CrossTradeTokenPair `json:"pair"`
+	TxHash string              `json:"txHash,omitempty"`
+}
+
+// CrossTradeTokensReport is the result of `trh-sdk cross-trade tokens diff|sync`
+type CrossTradeTokensReport struct {
+	Diffs      []CrossTradeTokenDiff `json:"diffs"`
+	Missing    int                   `json:"missing"`
+	Incorrect  int                   `json:"incorrect"`
+	Unexpected int                   `json:"unexpected"`
+	// Transactions are the registrations sync submitted, or would submit in a dry run
+	Transactions []CrossTradeTokenTx `json:"transactions,omitempty"`
+	DryRun       bool                `json:"dryRun,omitempty"`
+}
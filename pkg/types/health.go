@@ -0,0 +1,107 @@
+package types
+
+import "time"
+
+// HealthStatus is the result of a health check. The worst status of a report is also the exit code
+// of `trh-sdk health`.
+type HealthStatus string
+
+const (
+	HealthOK       HealthStatus = "ok"
+	HealthWarning  HealthStatus = "warning"
+	HealthCritical HealthStatus = "critical"
+	HealthUnknown  HealthStatus = "unknown"
+)
+
+// ExitCode follows the Nagios plugin convention so the command can be used in cron jobs and CI
+func (s HealthStatus) ExitCode() int {
+	switch s {
+	case HealthOK:
+		return 0
+	case HealthWarning:
+		return 1
+	case HealthCritical:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// Severity orders statuses from ok to critical
+func (s HealthStatus) Severity() int {
+	switch s {
+	case HealthOK:
+		return 0
+	case HealthUnknown:
+		return 1
+	case HealthWarning:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// HealthThresholds are the warning thresholds of the health checks. A check is critical at twice its threshold.
+// Zero values use the defaults for the L1 chain.
+type HealthThresholds struct {
+	UnsafeHeadAge    time.Duration
+	SafeHeadLag      time.Duration
+	FinalizedHeadLag time.Duration
+	BatchSubmission  time.Duration
+	OutputSubmission time.Duration
+	L1HeadDrift      uint64
+	L2EngineDrift    uint64
+	MinPeers         uint64
+}
+
+// HealthCheckInput selects the endpoints and thresholds of a health check. Empty endpoints are
+// resolved from the deployment.
+type HealthCheckInput struct {
+	L1RPCURL     string
+	L2RPCURL     string
+	OpNodeRPCURL string
+	Thresholds   HealthThresholds
+}
+
+// HealthCheck is the result of a single check
+type HealthCheck struct {
+	Name      string       `json:"name"`
+	Status    HealthStatus `json:"status"`
+	Value     string       `json:"value"`
+	Threshold string       `json:"threshold,omitempty"`
+	Message   string       `json:"message,omitempty"`
+}
+
+// BlockRef is a block reported by op-node
+type BlockRef struct {
+	Number    uint64 `json:"number"`
+	Hash      string `json:"hash"`
+	Timestamp uint64 `json:"timestamp"`
+}
+
+// RollupSyncStatus is the op-node optimism_syncStatus response
+type RollupSyncStatus struct {
+	CurrentL1   BlockRef `json:"current_l1"`
+	HeadL1      BlockRef `json:"head_l1"`
+	SafeL1      BlockRef `json:"safe_l1"`
+	FinalizedL1 BlockRef `json:"finalized_l1"`
+	UnsafeL2    BlockRef `json:"unsafe_l2"`
+	SafeL2      BlockRef `json:"safe_l2"`
+	FinalizedL2 BlockRef `json:"finalized_l2"`
+}
+
+// HealthReport is the result of `trh-sdk health`
+type HealthReport struct {
+	CheckedAt  time.Time         `json:"checkedAt"`
+	Status     HealthStatus      `json:"status"`
+	Checks     []HealthCheck     `json:"checks"`
+	SyncStatus *RollupSyncStatus `json:"syncStatus,omitempty"`
+}
+
+// BatcherNonceObservation is the batcher nonce seen by the health check. Without an archive L1 RPC, a batch
+// older than the recent state window is only known to precede LastBatchBefore.
+type BatcherNonceObservation struct {
+	Batcher         string    `json:"batcher"`
+	Nonce           uint64    `json:"nonce"`
+	LastBatchBefore time.Time `json:"lastBatchBefore"`
+}
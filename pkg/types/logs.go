@@ -0,0 +1,26 @@
+package types
+
+import "time"
+
+// LogStreamOptions selects the logs shown by `trh-sdk logs`
+type LogStreamOptions struct {
+	// Components to show. Empty shows every running component.
+	Components []string
+	Follow     bool
+	// Since only shows lines newer than this duration. Zero shows the last Tail lines.
+	Since time.Duration
+	// Tail is the number of lines per component. Negative shows all lines.
+	Tail int
+	// Grep is a regular expression lines must match
+	Grep  string
+	JSON  bool
+	Color bool
+}
+
+// LogLine is a log line of a component, printed by `trh-sdk logs --json`
+type LogLine struct {
+	Time      time.Time `json:"time,omitempty"`
+	Component string    `json:"component"`
+	Source    string    `json:"source"`
+	Message   string    `json:"message"`
+}
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // MonitoringConfig holds all configuration needed for monitoring installation
 type MonitoringConfig struct {
 	// Basic configuration
@@ -27,8 +29,28 @@ type MonitoringConfig struct {
 
 // AlertManagerConfig holds alertmanager-specific configuration
 type AlertManagerConfig struct {
-	Telegram TelegramConfig
-	Email    EmailConfig
+	Telegram  TelegramConfig
+	Email     EmailConfig
+	Slack     SlackConfig
+	Discord   DiscordConfig
+	PagerDuty PagerDutyConfig
+	Webhook   WebhookConfig
+
+	// Routes are evaluated in order before the channel subscriptions
+	Routes []AlertRoute
+}
+
+// AlertRoute sends alerts matching severities and components to one receiver.
+// Receiver is "default" (email and Telegram), "null" (drop) or a Slack, Discord, PagerDuty or webhook channel.
+type AlertRoute struct {
+	Receiver       string   `json:"receiver" yaml:"receiver"`
+	Severities     []string `json:"severities,omitempty" yaml:"severities,omitempty"`
+	Components     []string `json:"components,omitempty" yaml:"components,omitempty"`
+	GroupBy        []string `json:"group_by,omitempty" yaml:"group_by,omitempty"`
+	GroupWait      string   `json:"group_wait,omitempty" yaml:"group_wait,omitempty"`
+	GroupInterval  string   `json:"group_interval,omitempty" yaml:"group_interval,omitempty"`
+	RepeatInterval string   `json:"repeat_interval,omitempty" yaml:"repeat_interval,omitempty"`
+	Continue       bool     `json:"continue,omitempty" yaml:"continue,omitempty"`
 }
 
 // TelegramConfig holds Telegram notification configuration
@@ -52,6 +74,37 @@ type EmailConfig struct {
 	AlertReceivers   []string
 }
 
+// SlackConfig holds Slack incoming webhook notification configuration.
+// Severities limits the alert severities routed to the channel; empty means all.
+type SlackConfig struct {
+	Enabled    bool     `json:"enabled" yaml:"enabled"`
+	WebhookURL string   `json:"webhook_url,omitempty" yaml:"webhook_url,omitempty"`
+	Channel    string   `json:"channel,omitempty" yaml:"channel,omitempty"`
+	Severities []string `json:"severities,omitempty" yaml:"severities,omitempty"`
+}
+
+// DiscordConfig holds Discord webhook notification configuration
+type DiscordConfig struct {
+	Enabled    bool     `json:"enabled" yaml:"enabled"`
+	WebhookURL string   `json:"webhook_url,omitempty" yaml:"webhook_url,omitempty"`
+	Severities []string `json:"severities,omitempty" yaml:"severities,omitempty"`
+}
+
+// PagerDutyConfig holds PagerDuty Events API v2 notification configuration
+type PagerDutyConfig struct {
+	Enabled    bool     `json:"enabled" yaml:"enabled"`
+	RoutingKey string   `json:"routing_key,omitempty" yaml:"routing_key,omitempty"`
+	Severities []string `json:"severities,omitempty" yaml:"severities,omitempty"`
+}
+
+// WebhookConfig holds generic webhook notification configuration
+type WebhookConfig struct {
+	Enabled     bool     `json:"enabled" yaml:"enabled"`
+	URL         string   `json:"url,omitempty" yaml:"url,omitempty"`
+	BearerToken string   `json:"bearer_token,omitempty" yaml:"bearer_token,omitempty"`
+	Severities  []string `json:"severities,omitempty" yaml:"severities,omitempty"`
+}
+
 // EmailReceiver represents an email recipient configuration
 type EmailReceiver struct {
 	To string `json:"to" yaml:"to"`
@@ -86,6 +139,24 @@ type AlertManagerParsedReceiver struct {
 		BotToken string `yaml:"bot_token"`
 		ChatID   string `yaml:"chat_id"`
 	} `yaml:"telegram_configs"`
+	SlackConfigs []struct {
+		APIURL  string `yaml:"api_url"`
+		Channel string `yaml:"channel"`
+	} `yaml:"slack_configs"`
+	DiscordConfigs []struct {
+		WebhookURL string `yaml:"webhook_url"`
+	} `yaml:"discord_configs"`
+	PagerdutyConfigs []struct {
+		RoutingKey string `yaml:"routing_key"`
+	} `yaml:"pagerduty_configs"`
+	WebhookConfigs []struct {
+		URL        string `yaml:"url"`
+		HTTPConfig struct {
+			Authorization struct {
+				Credentials string `yaml:"credentials"`
+			} `yaml:"authorization"`
+		} `yaml:"http_config"`
+	} `yaml:"webhook_configs"`
 }
 
 // MonitoringInfo holds information about the installed monitoring stack
@@ -174,3 +245,30 @@ type TelegramConfiguration struct {
 	BotToken string `json:"bot_token" yaml:"bot_token"`
 	ChatID   string `json:"chat_id" yaml:"chat_id"`
 }
+
+// RoutedChannelConfiguration represents the status of a Slack, Discord, PagerDuty or webhook channel
+type RoutedChannelConfiguration struct {
+	Enabled    bool     `json:"enabled" yaml:"enabled"`
+	Target     string   `json:"target" yaml:"target"`
+	Severities []string `json:"severities" yaml:"severities"`
+}
+
+// AlertSilence is an AlertManager silence
+type AlertSilence struct {
+	ID        string    `json:"id"`
+	State     string    `json:"state"`
+	Matchers  []string  `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+}
+
+// GrafanaDashboard is a dashboard in the monitoring Grafana
+type GrafanaDashboard struct {
+	UID    string   `json:"uid"`
+	Title  string   `json:"title"`
+	Folder string   `json:"folder,omitempty"`
+	URL    string   `json:"url"`
+	Tags   []string `json:"tags,omitempty"`
+}
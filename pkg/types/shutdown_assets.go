@@ -0,0 +1,64 @@
+package types
+
+// ShutdownFetchOptions are the options of `trh-sdk shutdown fetch`
+type ShutdownFetchOptions struct {
+	// Workers is the number of block ranges scanned in parallel, 0 uses the default
+	Workers int
+	// BlockRange is the number of blocks scanned by a worker at a time, 0 uses the default
+	BlockRange uint64
+	// Restart discards the checkpoint of a previous fetch and scans the chain from block 0
+	Restart bool
+}
+
+// ShutdownHolder is an entry of l2-holders-<l2ChainId>.json, the balance of an account at the fetched block
+type ShutdownHolder struct {
+	Address string `json:"address"`
+	// Token is the L2 token, the zero address for the native token
+	Token      string `json:"token"`
+	Balance    string `json:"balance"`
+	IsContract bool   `json:"isContract"`
+}
+
+// ShutdownContract is an entry of l2-contracts-<l2ChainId>.json, a contract holding assets. Contracts
+// have no key on L1, their assets are claimed by the operator.
+type ShutdownContract struct {
+	Address  string `json:"address"`
+	CodeHash string `json:"codeHash"`
+}
+
+// ShutdownToken is an entry of l2-tokens-<l2ChainId>.json, an ERC20 token that emitted transfers on L2
+type ShutdownToken struct {
+	Address string `json:"address"`
+	// L1Token is the token the L2 token is bridged from, empty when it was not bridged
+	L1Token     string `json:"l1Token,omitempty"`
+	Symbol      string `json:"symbol"`
+	Decimals    uint8  `json:"decimals"`
+	TotalSupply string `json:"totalSupply"`
+	Holders     int    `json:"holders"`
+}
+
+// ShutdownBurn is an entry of l2-burns-<l2ChainId>.json, a withdrawal initiated on the L2 standard bridge
+type ShutdownBurn struct {
+	BlockNumber uint64 `json:"blockNumber"`
+	TxHash      string `json:"txHash"`
+	LogIndex    uint   `json:"logIndex"`
+	L1Token     string `json:"l1Token"`
+	L2Token     string `json:"l2Token"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Amount      string `json:"amount"`
+}
+
+// ShutdownWithdrawal is an entry of unclaimed-withdrawals-<l2ChainId>.json, a message passed to L1 that
+// was not finalized on the OptimismPortal
+type ShutdownWithdrawal struct {
+	WithdrawalHash string `json:"withdrawalHash"`
+	Nonce          string `json:"nonce"`
+	Sender         string `json:"sender"`
+	Target         string `json:"target"`
+	Value          string `json:"value"`
+	GasLimit       string `json:"gasLimit"`
+	Data           string `json:"data"`
+	BlockNumber    uint64 `json:"blockNumber"`
+	TxHash         string `json:"txHash"`
+}
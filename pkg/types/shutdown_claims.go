@@ -0,0 +1,99 @@
+package types
+
+import "encoding/json"
+
+// ShutdownAssetToken is an entry of the assets snapshot generate-assets-<l2ChainId>.json, the claims
+// of one token
+type ShutdownAssetToken struct {
+	L1Token   string               `json:"l1Token"`
+	L2Token   string               `json:"l2Token"`
+	TokenName string               `json:"tokenName"`
+	Data      []ShutdownAssetClaim `json:"data"`
+}
+
+// ShutdownAssetClaim is the amount of a token a claimer is owed on L1
+type ShutdownAssetClaim struct {
+	Claimer string      `json:"claimer"`
+	Amount  json.Number `json:"amount"`
+	// Hash names the GenFWStorage getter that returns the amount registered for the claim
+	Hash string `json:"hash"`
+}
+
+// ShutdownClaimStatus is the reconciliation outcome of a claim
+type ShutdownClaimStatus string
+
+const (
+	// ShutdownClaimClaimed was claimed on L1 for the snapshot amount
+	ShutdownClaimClaimed ShutdownClaimStatus = "claimed"
+	// ShutdownClaimClaimable is registered in GenFWStorage for the snapshot amount and not claimed yet
+	ShutdownClaimClaimable ShutdownClaimStatus = "claimable"
+	// ShutdownClaimMissing is in the snapshot but not registered in GenFWStorage
+	ShutdownClaimMissing ShutdownClaimStatus = "missing"
+	// ShutdownClaimAmountMismatch is registered in GenFWStorage for another amount than the snapshot
+	ShutdownClaimAmountMismatch ShutdownClaimStatus = "amount-mismatch"
+	// ShutdownClaimClaimMismatch was claimed on L1 for another amount than the snapshot
+	ShutdownClaimClaimMismatch ShutdownClaimStatus = "claim-mismatch"
+	// ShutdownClaimUnexpected was claimed on L1 without an entry in the snapshot
+	ShutdownClaimUnexpected ShutdownClaimStatus = "unexpected-claim"
+)
+
+// Mismatch reports whether the status needs the attention of the operator
+func (s ShutdownClaimStatus) Mismatch() bool {
+	return s != ShutdownClaimClaimed && s != ShutdownClaimClaimable
+}
+
+// ShutdownReconcileOptions are the options of `trh-sdk shutdown reconcile`
+type ShutdownReconcileOptions struct {
+	// AssetsPath is the assets snapshot, relative to contracts-bedrock unless absolute
+	AssetsPath     string
+	StorageAddress string
+	// FromBlock is the first L1 block searched for claims. When 0 the search starts at the block of
+	// ActivateTxHash, or at block 0 without it.
+	FromBlock      uint64
+	ActivateTxHash string
+	// Workers is the number of GenFWStorage reads in flight, 0 uses the default
+	Workers int
+}
+
+// ShutdownClaimRecord is the claim status of an address for one token
+type ShutdownClaimRecord struct {
+	Claimer   string `json:"claimer"`
+	L1Token   string `json:"l1Token"`
+	L2Token   string `json:"l2Token,omitempty"`
+	TokenName string `json:"tokenName,omitempty"`
+	Hash      string `json:"hash,omitempty"`
+	// Amount is the amount in the snapshot, StorageAmount the one registered in GenFWStorage and
+	// ClaimedAmount the one claimed on L1
+	Amount        string              `json:"amount"`
+	StorageAmount string              `json:"storageAmount"`
+	ClaimedAmount string              `json:"claimedAmount"`
+	ClaimTxHash   string              `json:"claimTxHash,omitempty"`
+	ClaimBlock    uint64              `json:"claimBlock,omitempty"`
+	Status        ShutdownClaimStatus `json:"status"`
+}
+
+// ShutdownClaimTotal totals the claims of a token
+type ShutdownClaimTotal struct {
+	L1Token    string `json:"l1Token"`
+	TokenName  string `json:"tokenName,omitempty"`
+	Claims     int    `json:"claims"`
+	Amount     string `json:"amount"`
+	Claimed    string `json:"claimed"`
+	Mismatches int    `json:"mismatches"`
+}
+
+// ShutdownReconcileReport is the result of `trh-sdk shutdown reconcile`
+type ShutdownReconcileReport struct {
+	L1ChainID      uint64                `json:"l1ChainId"`
+	L2ChainID      uint64                `json:"l2ChainId"`
+	SnapshotPath   string                `json:"snapshotPath"`
+	SnapshotHash   string                `json:"snapshotHash"`
+	StorageAddress string                `json:"storageAddress"`
+	Bridge         string                `json:"bridge"`
+	FromBlock      uint64                `json:"fromBlock"`
+	ToBlock        uint64                `json:"toBlock"`
+	GeneratedAt    string                `json:"generatedAt"`
+	Totals         []ShutdownClaimTotal  `json:"totals"`
+	Mismatches     int                   `json:"mismatches"`
+	Records        []ShutdownClaimRecord `json:"records"`
+}
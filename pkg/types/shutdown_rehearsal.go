@@ -0,0 +1,72 @@
+package types
+
+// ShutdownRehearseOptions are the options of `trh-sdk shutdown rehearse`
+type ShutdownRehearseOptions struct {
+	// ForkBlock is the L1 block the fork starts from, 0 forks the current block
+	ForkBlock uint64
+	// SkipFetch rehearses with the data of a previous fetch
+	SkipFetch bool
+	Fetch     ShutdownFetchOptions
+	Gen       ShutdownConfig
+}
+
+// ShutdownRehearsalTx is a transaction a shutdown step sent to the L1 fork
+type ShutdownRehearsalTx struct {
+	Hash        string `json:"hash"`
+	From        string `json:"from"`
+	To          string `json:"to,omitempty"`
+	BlockNumber uint64 `json:"blockNumber"`
+	GasUsed     uint64 `json:"gasUsed"`
+	// GasCost is the gas used times the effective gas price, in wei
+	GasCost  string `json:"gasCost"`
+	Reverted bool   `json:"reverted"`
+}
+
+// ShutdownRehearsalStep is the outcome of a shutdown step on the L1 fork
+type ShutdownRehearsalStep struct {
+	Step         string                `json:"step"`
+	Status       ShutdownStepStatus    `json:"status"`
+	Error        string                `json:"error,omitempty"`
+	Duration     string                `json:"duration,omitempty"`
+	GasUsed      uint64                `json:"gasUsed"`
+	Transactions []ShutdownRehearsalTx `json:"transactions,omitempty"`
+}
+
+// ShutdownClaimable is the total balance of a token the L2 holders can claim on L1
+type ShutdownClaimable struct {
+	// Token is the L2 token, the zero address for the native token
+	Token   string `json:"token"`
+	L1Token string `json:"l1Token,omitempty"`
+	Symbol  string `json:"symbol,omitempty"`
+	// Decimals is the precision of Total, 18 for the native token
+	Decimals uint8  `json:"decimals"`
+	Total    string `json:"total"`
+	Holders  int    `json:"holders"`
+	// ContractTotal is the part of Total held by contracts, which is claimed by the operator
+	ContractTotal string `json:"contractTotal"`
+}
+
+// ShutdownRehearsalReport is the result of `trh-sdk shutdown rehearse`, stored as
+// shutdown-rehearsal-<l2ChainId>-<timestamp>.json in the deployment directory
+type ShutdownRehearsalReport struct {
+	L1ChainID   uint64                  `json:"l1ChainId"`
+	L2ChainID   uint64                  `json:"l2ChainId"`
+	ForkBlock   uint64                  `json:"forkBlock"`
+	StartedAt   string                  `json:"startedAt"`
+	CompletedAt string                  `json:"completedAt"`
+	Steps       []ShutdownRehearsalStep `json:"steps"`
+	Claimable   []ShutdownClaimable     `json:"claimable"`
+	// UnclaimedWithdrawals and UnclaimedValue are the L2 withdrawals not finalized on L1
+	UnclaimedWithdrawals int    `json:"unclaimedWithdrawals"`
+	UnclaimedValue       string `json:"unclaimedValue"`
+	// StorageAddress is the GenFWStorage deployed on the fork
+	StorageAddress string `json:"storageAddress,omitempty"`
+	GasUsed        uint64 `json:"gasUsed"`
+	GasCost        string `json:"gasCost"`
+	Reverts        int    `json:"reverts"`
+	// Success is set when every step completed without a reverted transaction
+	Success bool `json:"success"`
+
+	// Path is the file the report was written to
+	Path string `json:"-"`
+}
@@ -8,66 +8,196 @@ import (
 	"time"
 )
 
-// ShutdownState represents the state of shutdown operations
+// Shutdown steps, in the order `shutdown run` executes them
+const (
+	ShutdownStepBlock    = "block"
+	ShutdownStepFetch    = "fetch"
+	ShutdownStepGen      = "gen"
+	ShutdownStepActivate = "activate"
+	ShutdownStepWithdraw = "withdraw"
+)
+
+// ShutdownSteps lists the shutdown steps in execution order
+var ShutdownSteps = []string{
+	ShutdownStepBlock,
+	ShutdownStepFetch,
+	ShutdownStepGen,
+	ShutdownStepActivate,
+	ShutdownStepWithdraw,
+}
+
+// ShutdownStepStatus is the outcome of the last run of a shutdown step
+type ShutdownStepStatus string
+
+const (
+	ShutdownStepPending   ShutdownStepStatus = "pending"
+	ShutdownStepRunning   ShutdownStepStatus = "running"
+	ShutdownStepCompleted ShutdownStepStatus = "completed"
+	ShutdownStepFailed    ShutdownStepStatus = "failed"
+)
+
+// ShutdownStepState records the last run of a shutdown step
+type ShutdownStepState struct {
+	Status      ShutdownStepStatus `json:"status"`
+	DryRun      bool               `json:"dryRun,omitempty"`
+	StartedAt   string             `json:"startedAt,omitempty"`
+	CompletedAt string             `json:"completedAt,omitempty"`
+	Error       string             `json:"error,omitempty"`
+	// TxHashes are the transactions forge broadcast during the step
+	TxHashes []string `json:"txHashes,omitempty"`
+	// InputHash is the sha256 of the assets snapshot the step ran with
+	InputHash string `json:"inputHash,omitempty"`
+}
+
+// ShutdownState is the progress of the shutdown of one L2 chain, stored in its deployment directory
 type ShutdownState struct {
+	ChainID         uint64                        `json:"chainId"`
+	L2ChainID       uint64                        `json:"l2ChainId"`
+	ThanosRoot      string                        `json:"thanosRoot"`
+	DeploymentsPath string                        `json:"deploymentsPath"`
+	DataDir         string                        `json:"dataDir"`
+	Steps           map[string]*ShutdownStepState `json:"steps"`
+	// SnapshotPath and SnapshotHash are the assets snapshot written by the last gen
+	SnapshotPath string `json:"snapshotPath,omitempty"`
+	SnapshotHash string `json:"snapshotHash,omitempty"`
+	// StorageAddress is the GenFWStorage deployed by activate
+	StorageAddress string `json:"storageAddress,omitempty"`
+	LastCommand    string `json:"lastCommand,omitempty"`
+	UpdatedAt      string `json:"updatedAt,omitempty"`
+
+	// ImportedFrom is the legacy state file the progress was imported from by this load
+	ImportedFrom string `json:"-"`
+	// UnattributedLegacy is a legacy state file without an L2 chain ID. It is only imported once the
+	// operator confirms it belongs to the chain, with ImportUnattributedLegacy.
+	UnattributedLegacy string `json:"-"`
+
+	path string
+}
+
+// legacyShutdownState is the single shutdown state that earlier releases kept in ~/.trh for every chain
+type legacyShutdownState struct {
 	ChainID          uint64 `json:"chainId"`
 	L2ChainID        uint64 `json:"l2ChainId"`
 	ThanosRoot       string `json:"thanosRoot"`
 	DeploymentsPath  string `json:"deploymentsPath"`
 	DataDir          string `json:"dataDir"`
 	LastGenAt        string `json:"lastGenAt,omitempty"`
-	LastDryRunAt     string `json:"lastDryRunAt,omitempty"`
 	LastSendAt       string `json:"lastSendAt,omitempty"`
 	LastSnapshotPath string `json:"lastSnapshotPath,omitempty"`
 	LastCommand      string `json:"lastCommand,omitempty"`
 }
 
-// GetShutdownStateFilePath returns the path to the shutdown state file
-func GetShutdownStateFilePath() (string, error) {
+// LegacyShutdownStateFilePath returns the state file of earlier releases, ~/.trh/thanos_shutdown_state.json
+func LegacyShutdownStateFilePath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get user home directory: %w", err)
 	}
-
-	trhDir := filepath.Join(homeDir, ".trh")
-	if err := os.MkdirAll(trhDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create .trh directory: %w", err)
-	}
-
-	return filepath.Join(trhDir, "thanos_shutdown_state.json"), nil
+	return filepath.Join(homeDir, ".trh", "thanos_shutdown_state.json"), nil
 }
 
-// LoadShutdownState loads the shutdown state from file
-func LoadShutdownState() (*ShutdownState, error) {
-	filePath, err := GetShutdownStateFilePath()
-	if err != nil {
-		return nil, err
-	}
+// ShutdownStateFilePath returns the shutdown state file of an L2 chain in the deployment directory
+func ShutdownStateFilePath(deploymentPath string, l2ChainID uint64) string {
+	return filepath.Join(deploymentPath, fmt.Sprintf("shutdown-state-%d.json", l2ChainID))
+}
 
-	// If file doesn't exist, return empty state
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return &ShutdownState{}, nil
-	}
+// LoadShutdownState loads the shutdown state of an L2 chain, an empty state when there is none
+func LoadShutdownState(deploymentPath string, l2ChainID uint64) (*ShutdownState, error) {
+	filePath := ShutdownStateFilePath(deploymentPath, l2ChainID)
+	state := &ShutdownState{L2ChainID: l2ChainID, path: filePath}
 
 	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		state.Steps = map[string]*ShutdownStepState{}
+		if err := state.importLegacy(false); err != nil {
+			return nil, err
+		}
+		return state, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read state file: %w", err)
 	}
-
-	var state ShutdownState
-	if err := json.Unmarshal(data, &state); err != nil {
+	if err := json.Unmarshal(data, state); err != nil {
 		return nil, fmt.Errorf("failed to parse state file: %w", err)
 	}
+	if state.L2ChainID != l2ChainID {
+		return nil, fmt.Errorf("state file %s belongs to L2 chain %d, not %d", filePath, state.L2ChainID, l2ChainID)
+	}
+	if state.Steps == nil {
+		state.Steps = map[string]*ShutdownStepState{}
+	}
+	return state, nil
+}
 
-	return &state, nil
+// ImportUnattributedLegacy imports the legacy state file without an L2 chain ID into the state of the chain
+func (s *ShutdownState) ImportUnattributedLegacy() error {
+	if s.UnattributedLegacy == "" {
+		return nil
+	}
+	if err := s.importLegacy(true); err != nil {
+		return err
+	}
+	s.UnattributedLegacy = ""
+	return nil
 }
 
-// SaveShutdownState saves the shutdown state to file
-func (s *ShutdownState) Save() error {
-	filePath, err := GetShutdownStateFilePath()
+// importLegacy imports the progress of the legacy state file when it belongs to the chain, and saves it to
+// the state file of the chain. A legacy file without an L2 chain ID is only recorded in UnattributedLegacy,
+// unless attributed is set.
+func (s *ShutdownState) importLegacy(attributed bool) error {
+	legacyPath, err := LegacyShutdownStateFilePath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(legacyPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
 	if err != nil {
+		return fmt.Errorf("failed to read legacy state file: %w", err)
+	}
+	var legacy legacyShutdownState
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return fmt.Errorf("failed to parse legacy state file %s: %w", legacyPath, err)
+	}
+	if legacy.L2ChainID == 0 && !attributed {
+		s.UnattributedLegacy = legacyPath
+		return nil
+	}
+	if legacy.L2ChainID != 0 && legacy.L2ChainID != s.L2ChainID {
+		return nil
+	}
+
+	s.ChainID = legacy.ChainID
+	s.ThanosRoot = legacy.ThanosRoot
+	s.DeploymentsPath = legacy.DeploymentsPath
+	s.DataDir = legacy.DataDir
+	s.SnapshotPath = legacy.LastSnapshotPath
+	s.LastCommand = legacy.LastCommand
+	// gen ran after block and fetch, and send ran activate and withdraw
+	if legacy.LastGenAt != "" {
+		for _, step := range []string{ShutdownStepBlock, ShutdownStepFetch, ShutdownStepGen} {
+			s.Steps[step] = &ShutdownStepState{Status: ShutdownStepCompleted, CompletedAt: legacy.LastGenAt}
+		}
+	}
+	if legacy.LastSendAt != "" {
+		for _, step := range []string{ShutdownStepActivate, ShutdownStepWithdraw} {
+			s.Steps[step] = &ShutdownStepState{Status: ShutdownStepCompleted, CompletedAt: legacy.LastSendAt}
+		}
+	}
+	if err := s.Save(); err != nil {
 		return err
 	}
+	s.ImportedFrom = legacyPath
+	return nil
+}
+
+// Save saves the shutdown state to its file
+func (s *ShutdownState) Save() error {
+	if s.path == "" {
+		return fmt.Errorf("shutdown state was not loaded from a deployment")
+	}
+	s.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
 
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
@@ -75,12 +205,12 @@ func (s *ShutdownState) Save() error {
 	}
 
 	// Write atomically by writing to temp file first
-	tempPath := filePath + ".tmp"
+	tempPath := s.path + ".tmp"
 	if err := os.WriteFile(tempPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write temp state file: %w", err)
 	}
 
-	if err := os.Rename(tempPath, filePath); err != nil {
+	if err := os.Rename(tempPath, s.path); err != nil {
 		os.Remove(tempPath) // Clean up temp file
 		return fmt.Errorf("failed to rename temp state file: %w", err)
 	}
@@ -88,21 +218,92 @@ func (s *ShutdownState) Save() error {
 	return nil
 }
 
-// UpdateAfterGen updates state after successful gen command
-func (s *ShutdownState) UpdateAfterGen(snapshotPath string) {
-	s.LastGenAt = time.Now().UTC().Format(time.RFC3339)
-	s.LastSnapshotPath = snapshotPath
-	s.LastCommand = "gen"
+// Step returns the state of a step, pending when it never ran
+func (s *ShutdownState) Step(step string) *ShutdownStepState {
+	if state, ok := s.Steps[step]; ok {
+		return state
+	}
+	return &ShutdownStepState{Status: ShutdownStepPending}
+}
+
+// StepDone reports whether a step completed. A dry run only counts for another dry run.
+func (s *ShutdownState) StepDone(step string, dryRun bool) bool {
+	state := s.Step(step)
+	return state.Status == ShutdownStepCompleted && (!state.DryRun || dryRun)
+}
+
+// NextStep returns the first step that is not done, empty when the shutdown is complete
+func (s *ShutdownState) NextStep(dryRun bool) string {
+	for _, step := range ShutdownSteps {
+		if !s.StepDone(step, dryRun) {
+			return step
+		}
+	}
+	return ""
+}
+
+// StartStep marks a step as running
+func (s *ShutdownState) StartStep(step string, dryRun bool) {
+	s.Steps[step] = &ShutdownStepState{
+		Status:    ShutdownStepRunning,
+		DryRun:    dryRun,
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	s.LastCommand = step
+}
+
+// Interrupted reports whether a real run of the step did not complete after it may have broadcast
+// transactions: it was left running by a crash, or failed after sending some
+func (s *ShutdownStepState) Interrupted() bool {
+	if s.DryRun {
+		return false
+	}
+	return s.Status == ShutdownStepRunning || (s.Status == ShutdownStepFailed && len(s.TxHashes) > 0)
+}
+
+// CompleteStep marks a running step as completed with the transactions it sent
+func (s *ShutdownState) CompleteStep(step string, txHashes []string) {
+	state := s.stepForUpdate(step)
+	state.Status = ShutdownStepCompleted
+	state.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+	state.Error = ""
+	state.TxHashes = txHashes
 }
 
-// UpdateAfterDryRun updates state after successful dry-run command
-func (s *ShutdownState) UpdateAfterDryRun() {
-	s.LastDryRunAt = time.Now().UTC().Format(time.RFC3339)
-	s.LastCommand = "dry-run"
+// FailStep marks a running step as failed with the transactions it sent before failing
+func (s *ShutdownState) FailStep(step string, err error, txHashes []string) {
+	state := s.stepForUpdate(step)
+	state.Status = ShutdownStepFailed
+	state.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+	state.Error = err.Error()
+	state.TxHashes = txHashes
 }
 
-// UpdateAfterSend updates state after successful send command
-func (s *ShutdownState) UpdateAfterSend() {
-	s.LastSendAt = time.Now().UTC().Format(time.RFC3339)
-	s.LastCommand = "send"
+// SetStepInput records the hash of the assets snapshot a step runs with
+func (s *ShutdownState) SetStepInput(step, inputHash string) {
+	s.stepForUpdate(step).InputHash = inputHash
+}
+
+// SetSnapshot records the assets snapshot written by gen. A different snapshot resets the steps that
+// ran with the previous one.
+func (s *ShutdownState) SetSnapshot(path, hash string) {
+	if s.SnapshotHash != "" && s.SnapshotHash != hash {
+		delete(s.Steps, ShutdownStepActivate)
+		delete(s.Steps, ShutdownStepWithdraw)
+		s.StorageAddress = ""
+	}
+	s.SnapshotPath = path
+	s.SnapshotHash = hash
+}
+
+// ResetSteps forgets the progress of every step
+func (s *ShutdownState) ResetSteps() {
+	s.Steps = map[string]*ShutdownStepState{}
+}
+
+func (s *ShutdownState) stepForUpdate(step string) *ShutdownStepState {
+	if s.Steps[step] == nil {
+		s.Steps[step] = &ShutdownStepState{Status: ShutdownStepPending}
+	}
+	return s.Steps[step]
 }
@@ -0,0 +1,24 @@
+package types
+
+import "time"
+
+// SupportBundleOptions configures `trh-sdk support-bundle`
+type SupportBundleOptions struct {
+	// OutputPath of the tarball. Empty writes support-bundle-<timestamp>.tar.gz to the deployment path.
+	OutputPath string
+	// LogSince is the window of component logs included in the bundle
+	LogSince time.Duration
+	// SDKVersion is recorded in the manifest
+	SDKVersion string
+}
+
+// SupportBundleManifest is manifest.json of a support bundle
+type SupportBundleManifest struct {
+	CreatedAt  time.Time         `json:"createdAt"`
+	SDKVersion string            `json:"sdkVersion"`
+	Network    string            `json:"network"`
+	Stack      string            `json:"stack"`
+	ChainName  string            `json:"chainName,omitempty"`
+	Files      []string          `json:"files"`
+	Errors     map[string]string `json:"errors,omitempty"`
+}
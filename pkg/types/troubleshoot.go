@@ -0,0 +1,51 @@
+package types
+
+import "time"
+
+// TroubleshootRule is a known failure signature with its diagnosis and fix commands
+type TroubleshootRule struct {
+	ID       string `yaml:"id" json:"id"`
+	Title    string `yaml:"title" json:"title"`
+	Severity string `yaml:"severity" json:"severity"`
+	// Components whose lines are matched, all when empty. "trh-sdk" are the SDK logs and errors and
+	// "k8s-events" the events of the chain namespace.
+	Components []string `yaml:"components" json:"components,omitempty"`
+	// Patterns are regular expressions, a line matching any of them matches the rule
+	Patterns  []string `yaml:"patterns" json:"patterns"`
+	Diagnosis string   `yaml:"diagnosis" json:"diagnosis"`
+	Fix       []string `yaml:"fix" json:"fix,omitempty"`
+	LocalFix  []string `yaml:"local_fix" json:"localFix,omitempty"`
+	AWSFix    []string `yaml:"aws_fix" json:"awsFix,omitempty"`
+}
+
+// TroubleshootOptions configures `trh-sdk troubleshoot`
+type TroubleshootOptions struct {
+	Components []string
+	// Since is the window of component and SDK logs that is scanned
+	Since time.Duration
+}
+
+// TroubleshootFinding is a rule that matched, with the fix commands of the deployment
+type TroubleshootFinding struct {
+	RuleID    string    `json:"ruleId"`
+	Title     string    `json:"title"`
+	Severity  string    `json:"severity"`
+	Diagnosis string    `json:"diagnosis"`
+	Fix       []string  `json:"fix,omitempty"`
+	Sources   []string  `json:"sources"`
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"firstSeen,omitempty"`
+	LastSeen  time.Time `json:"lastSeen,omitempty"`
+	// Example is the first matching line
+	Example string `json:"example"`
+}
+
+// TroubleshootReport is the result of `trh-sdk troubleshoot`
+type TroubleshootReport struct {
+	Deployment   string                `json:"deployment"`
+	Since        string                `json:"since"`
+	ScannedLines int                   `json:"scannedLines"`
+	Findings     []TroubleshootFinding `json:"findings"`
+	// Errors are the sources that could not be read
+	Errors []string `json:"errors,omitempty"`
+}
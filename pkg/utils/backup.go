@@ -48,3 +48,20 @@ func DetectAWSAccountID(ctx context.Context) (string, error) {
 func RDSIdentifierFromNamespace(namespace string) string {
 	return fmt.Sprintf("%s-rds", namespace)
 }
+
+// DetectRDSArn returns the ARN of the block explorer RDS instance of the namespace.
+func DetectRDSArn(ctx context.Context, region, namespace string) (string, error) {
+	out, err := ExecuteCommand(ctx, "aws", "rds", "describe-db-instances",
+		"--region", region,
+		"--db-instance-identifier", RDSIdentifierFromNamespace(namespace),
+		"--query", "DBInstances[0].DBInstanceArn",
+		"--output", "text")
+	if err != nil {
+		return "", err
+	}
+	arn := strings.TrimSpace(out)
+	if arn == "" || arn == "None" {
+		return "", fmt.Errorf("RDS instance %s not found", RDSIdentifierFromNamespace(namespace))
+	}
+	return arn, nil
+}
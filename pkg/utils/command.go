@@ -12,6 +12,8 @@ import (
 
 	"github.com/creack/pty"
 	"go.uber.org/zap"
+
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
 )
 
 var (
@@ -19,6 +21,7 @@ var (
 )
 
 func ExecuteCommand(ctx context.Context, command string, args ...string) (string, error) {
+	registerCommandSecrets(nil, args)
 	cmd := exec.CommandContext(ctx, command, args...)
 	output, err := cmd.CombinedOutput()
 
@@ -34,6 +37,7 @@ func ExecuteCommand(ctx context.Context, command string, args ...string) (string
 
 // ExecuteCommandWithEnv executes a command with additional environment variables
 func ExecuteCommandWithEnv(ctx context.Context, env []string, command string, args ...string) (string, error) {
+	registerCommandSecrets(env, args)
 	cmd := exec.CommandContext(ctx, command, args...)
 	cmd.Env = append(os.Environ(), env...)
 	output, err := cmd.CombinedOutput()
@@ -51,6 +55,7 @@ func ExecuteCommandWithEnv(ctx context.Context, env []string, command string, ar
 // ExecuteCommandInDir executes a command in a specific directory.
 // This avoids shell injection vulnerabilities by not using "bash -c" with string interpolation.
 func ExecuteCommandInDir(ctx context.Context, dir string, command string, args ...string) (string, error) {
+	registerCommandSecrets(nil, args)
 	cmd := exec.CommandContext(ctx, command, args...)
 	cmd.Dir = dir
 	output, err := cmd.CombinedOutput()
@@ -72,6 +77,7 @@ func ExecuteCommandStream(ctx context.Context, l *zap.SugaredLogger, command str
 // ExecuteCommandStreamInDir executes a command in a specific directory with streaming output.
 // This avoids shell injection vulnerabilities by not using "bash -c" with string interpolation.
 func ExecuteCommandStreamInDir(ctx context.Context, l *zap.SugaredLogger, dir string, command string, args ...string) error {
+	registerCommandSecrets(nil, args)
 	cmd := exec.CommandContext(ctx, command, args...)
 	if dir != "" {
 		cmd.Dir = dir
@@ -137,7 +143,7 @@ func ExecuteCommandStreamInDir(ctx context.Context, l *zap.SugaredLogger, dir st
 	}
 
 	if err != nil {
-		l.Errorf("Command execution failed: %v", err)
+		l.Errorf("Command execution failed: %s", logging.Redact(err.Error()))
 		return err
 	}
 
@@ -149,13 +155,25 @@ func ExecuteCommandStreamInDir(ctx context.Context, l *zap.SugaredLogger, dir st
 	return nil
 }
 
-// streamOutput reads and prints the command output line by line
+// registerCommandSecrets registers the private keys, passwords and tokens passed to a command as
+// arguments (--private-key 0x..., --set-string leader.privateKey=0x..., PRIVATE_KEY=0x... forge script)
+// or env vars, so the log redaction also masks them when the command echoes them back
+func registerCommandSecrets(env []string, args []string) {
+	for _, entry := range env {
+		if name, value, ok := strings.Cut(entry, "="); ok && logging.IsSecretName(name) {
+			logging.RegisterSecret(value)
+		}
+	}
+	logging.RegisterSecretsIn(strings.Join(args, " "))
+}
+
+// streamOutput reads and prints the command output line by line, with secrets redacted
 func streamOutput(r io.Reader, l *zap.SugaredLogger) error {
 	reader := bufio.NewReader(r)
 	for {
 		line, err := reader.ReadString('\n') // or use a custom delimiter
 		if line != "" {
-			l.Info(strings.TrimSuffix(line, "\n"))
+			l.Info(logging.Redact(strings.TrimSuffix(line, "\n")))
 		}
 		if err != nil {
 			if err == io.EOF || err.Error() == "EOF" {
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
+)
+
+func TestExecuteCommandStreamRedactsSecrets(t *testing.T) {
+	const key = "0x8b3a350cf5c34c9194ca85829a2df0ec3153be0318b5e2d3348e872092edffba"
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := logging.WithRedaction(zap.New(core)).Sugar()
+
+	// The key is only passed as an assignment, then echoed on its own
+	err := ExecuteCommandStream(context.Background(), l, "sh", "-c", "PRIVATE_KEY="+key+"; echo signer $PRIVATE_KEY; echo done")
+	require.NoError(t, err)
+	var lines []string
+	for _, entry := range logs.All() {
+		// Lines read through the pseudo terminal end with \r
+		lines = append(lines, strings.TrimSpace(entry.Message))
+	}
+	require.Contains(t, lines, "signer "+logging.RedactedValue)
+	require.Contains(t, lines, "done")
+
+	l.Infow("helm values", "leaderPrivateKey", "0x1234567890abcdef", "error", "upgrade --set-string leader.privateKey="+key)
+	fields := logs.All()[logs.Len()-1].ContextMap()
+	require.Equal(t, logging.RedactedValue, fields["leaderPrivateKey"])
+	require.Equal(t, "upgrade --set-string leader.privateKey="+logging.RedactedValue, fields["error"])
+}
+
+func TestRedactingWriter(t *testing.T) {
+	logging.RegisterSecret("hunter2-password")
+	var out bytes.Buffer
+	w := logging.NewRedactingWriter(&out)
+	_, err := w.Write([]byte("login with hunter2-pa"))
+	require.NoError(t, err)
+	require.Empty(t, out.String(), "an incomplete line is held back")
+	_, err = w.Write([]byte("ssword\ndigest sha256:a5f1ca2e44b7d0c13fcd2bbc0d41ffd1de64d3bb42e0e1e8e69cb2fc73ef5fb4\nlast"))
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+	require.Equal(t, "login with "+logging.RedactedValue+"\ndigest sha256:a5f1ca2e44b7d0c13fcd2bbc0d41ffd1de64d3bb42e0e1e8e69cb2fc73ef5fb4\nlast", out.String())
+}
+
+func TestRedactBareHex(t *testing.T) {
+	const digest = "a5f1ca2e44b7d0c13fcd2bbc0d41ffd1de64d3bb42e0e1e8e69cb2fc73ef5fb4"
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := logging.WithRedaction(zap.New(core)).Sugar()
+
+	// The genesis digest of local backups is bare hex and stays readable
+	l.Infof("    🧬 Genesis  : %s", digest)
+	require.Equal(t, "    🧬 Genesis  : "+digest, logs.All()[0].Message)
+	l.Errorf("backup 20260101 was taken with a different genesis.json (%s, current %s)", digest, digest)
+	require.Contains(t, logs.All()[1].Message, "("+digest+", current "+digest+")")
+
+	// The same shape next to a key or in an env assignment is masked
+	require.Equal(t, "signer key "+logging.RedactedValue, logging.Redact("signer key "+digest))
+	require.Equal(t, "ADMIN_PK="+logging.RedactedValue, logging.Redact("ADMIN_PK="+digest))
+}
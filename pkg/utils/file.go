@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/tokamak-network/trh-sdk/pkg/logging"
 	"github.com/tokamak-network/trh-sdk/pkg/types"
 )
 
@@ -95,6 +96,8 @@ func ReadConfigFromJSONFile(deploymentPath string) (*types.Config, error) {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
+	// The keys in settings.json are masked in every log and subprocess output of the command
+	_ = logging.RegisterSecretsFromJSON(data)
 
 	// If L2ChainId doesn't exist, fetch it from the L2 RPC
 	// Only attempt if L2RpcUrl is provided (skip if empty to avoid errors)
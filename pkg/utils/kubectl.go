@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"os/exec"
 	"strings"
 	"time"
 )
@@ -454,3 +456,37 @@ func EnsureNamespaceExists(ctx context.Context, namespace string) error {
 
 	return nil
 }
+
+// PortForward forwards a free local port to a port of a Kubernetes resource (e.g. svc/op-node) and
+// returns the local address. The forward runs until stop is called or ctx is cancelled.
+func PortForward(ctx context.Context, namespace, resource string, remotePort int) (string, func(), error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to find a free local port: %w", err)
+	}
+	localPort := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	forwardCtx, cancel := context.WithCancel(ctx)
+	cmd := exec.CommandContext(forwardCtx, "kubectl", "-n", namespace, "port-forward", resource, fmt.Sprintf("%d:%d", localPort, remotePort))
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return "", nil, fmt.Errorf("failed to start port-forward to %s: %w", resource, err)
+	}
+	stop := func() {
+		cancel()
+		_ = cmd.Wait()
+	}
+
+	address := fmt.Sprintf("127.0.0.1:%d", localPort)
+	deadline := time.Now().Add(15 * time.Second)
+	for time.Now().Before(deadline) {
+		if conn, err := net.DialTimeout("tcp", address, time.Second); err == nil {
+			conn.Close()
+			return address, stop, nil
+		}
+		time.Sleep(300 * time.Millisecond)
+	}
+	stop()
+	return "", nil, fmt.Errorf("port-forward to %s in namespace %s did not become ready", resource, namespace)
+}
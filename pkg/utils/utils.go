@@ -65,6 +65,19 @@ func GWeiToEther(gwei *big.Int) *big.Float {
 func GWeiToWei(gwei *big.Int) *big.Int {
 	return new(big.Int).Mul(gwei, new(big.Int).SetUint64(params.GWei))
 }
+
+// EtherToWei parses a decimal ETH amount such as "0.5" into wei
+func EtherToWei(ether string) (*big.Int, error) {
+	amount, ok := new(big.Rat).SetString(strings.TrimSpace(ether))
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid ETH amount %q", ether)
+	}
+	amount.Mul(amount, new(big.Rat).SetInt(big.NewInt(params.Ether)))
+	if !amount.IsInt() {
+		return nil, fmt.Errorf("ETH amount %q has more than 18 decimals", ether)
+	}
+	return amount.Num(), nil
+}
 func GenerateBatchInboxAddress(l2ChainId uint64) string {
 	return fmt.Sprintf("%s%d", constants.BaseBatchInboxAddress[:len(constants.BaseBatchInboxAddress)-len(fmt.Sprintf("%d", l2ChainId))], l2ChainId)
 }